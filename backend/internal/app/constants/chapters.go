@@ -0,0 +1,39 @@
+package constants
+
+// Chapter 描述一个 Constants 子主题，ID 同时用作 HTTP 路由参数与测验章节标识。
+type Chapter struct {
+	ID          string
+	Title       string
+	ContentFunc func() string
+}
+
+// chapters 按菜单顺序排列的全部子主题。
+var chapters = []Chapter{
+	{"boolean", "Boolean Constants (布尔常量)", GetBooleanContent},
+	{"rune", "Rune Constants (符文常量)", GetRuneContent},
+	{"integer", "Integer Constants (整数常量)", GetIntegerContent},
+	{"floating_point", "Floating-point Constants (浮点常量)", GetFloatingPointContent},
+	{"complex", "Complex Constants (复数常量)", GetComplexContent},
+	{"string", "String Constants (字符串常量)", GetStringContent},
+	{"expressions", "Constant Expressions (常量表达式)", GetExpressionsContent},
+	{"typed_untyped", "Typed and Untyped Constants (类型化/无类型化常量)", GetTypedUntypedContent},
+	{"conversions", "Conversions (类型转换)", GetConversionsContent},
+	{"builtin_functions", "Built-in Functions (内置函数)", GetBuiltinFunctionsContent},
+	{"iota", "Iota (iota 特性)", GetIotaContent},
+	{"implementation_restrictions", "Implementation Restrictions (实现限制)", GetImplementationRestrictionsContent},
+}
+
+// Chapters 返回全部子主题的副本，顺序与菜单一致。
+func Chapters() []Chapter {
+	return append([]Chapter(nil), chapters...)
+}
+
+// IsSupportedChapter 判断 id 是否为已有子主题。
+func IsSupportedChapter(id string) bool {
+	for _, c := range chapters {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
+}
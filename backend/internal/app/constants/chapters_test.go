@@ -0,0 +1,22 @@
+package constants
+
+import "testing"
+
+// TestChapters 验证子主题目录与菜单一致且每个子主题都有内容。
+func TestChapters(t *testing.T) {
+	list := Chapters()
+	if len(list) != 12 {
+		t.Fatalf("期望 12 个子主题，得到 %d", len(list))
+	}
+	for _, c := range list {
+		if !IsSupportedChapter(c.ID) {
+			t.Errorf("子主题 %s 应被识别", c.ID)
+		}
+		if c.Title == "" || c.ContentFunc() == "" {
+			t.Errorf("子主题 %s 缺少标题或内容", c.ID)
+		}
+	}
+	if IsSupportedChapter("comments") || IsSupportedChapter("") {
+		t.Errorf("不存在的子主题不应被识别")
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type createAccessTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresIn 有效期（秒），为 0 时永不过期。
+	ExpiresIn int64 `json:"expiresIn"`
+}
+
+type createAccessTokenResponse struct {
+	Token       string            `json:"token"`
+	AccessToken *user.AccessToken `json:"accessToken"`
+}
+
+// ListAccessTokens 列出当前用户的个人访问令牌。
+func (h *Handler) ListAccessTokens(r *ghttp.Request) {
+	svc, userID, ok := h.currentUser(r)
+	if !ok {
+		return
+	}
+	tokens, err := svc.ListAccessTokens(r.GetCtx(), userID)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "success", tokens)
+}
+
+// CreateAccessToken 创建个人访问令牌，明文令牌仅在响应中返回一次。
+func (h *Handler) CreateAccessToken(r *ghttp.Request) {
+	svc, userID, ok := h.currentUser(r)
+	if !ok {
+		return
+	}
+
+	var req createAccessTokenRequest
+	if err := r.Parse(&req); err != nil || req.ExpiresIn < 0 {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+
+	token, raw, err := svc.CreateAccessToken(r.GetCtx(), userID, req.Name, req.Scopes, time.Duration(req.ExpiresIn)*time.Second)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "访问令牌已创建，请妥善保存", createAccessTokenResponse{Token: raw, AccessToken: token})
+}
+
+// RevokeAccessToken 撤销当前用户的指定令牌。
+func (h *Handler) RevokeAccessToken(r *ghttp.Request) {
+	svc, userID, ok := h.currentUser(r)
+	if !ok {
+		return
+	}
+	if err := svc.RevokeAccessToken(r.GetCtx(), userID, r.Get("id").Int64()); err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "访问令牌已撤销", nil)
+}
+
+// currentUser 获取用户服务与当前登录用户 ID。
+func (h *Handler) currentUser(r *ghttp.Request) (*user.Service, int64, bool) {
+	svc, err := h.ensureUserService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "认证服务不可用")
+		return nil, 0, false
+	}
+	userID := r.GetCtxVar("user_id").Int64()
+	if userID <= 0 {
+		writeError(r, http.StatusUnauthorized, 40001, "认证信息缺失")
+		return nil, 0, false
+	}
+	return svc, userID, true
+}
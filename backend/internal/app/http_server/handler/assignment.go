@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-study2/internal/domain/classroom"
+	"go-study2/internal/infrastructure/audit"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type createAssignmentRequest struct {
+	Title    string `json:"title"`
+	Topic    string `json:"topic"`
+	Chapter  string `json:"chapter"`
+	MinScore int    `json:"minScore"`
+	// OpensAt 与 DueAt 为 RFC3339 时间或 YYYY-MM-DD 日期，OpensAt 为空时立即开放。
+	OpensAt string `json:"opensAt"`
+	DueAt   string `json:"dueAt"`
+}
+
+// ListClassAssignments 返回班级作业列表。
+func (h *Handler) ListClassAssignments(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	items, err := svc.ListClassAssignments(r.GetCtx(), actorID, r.Get("id").Int64())
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "success", items)
+}
+
+// CreateAssignment 由班级教师布置作业。
+func (h *Handler) CreateAssignment(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	var req createAssignmentRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	opensAt, ok := parseAuditTime(req.OpensAt)
+	if !ok {
+		writeError(r, http.StatusBadRequest, 40004, "开放时间格式无效")
+		return
+	}
+	dueAt, ok := parseAuditTime(req.DueAt)
+	if !ok || dueAt.IsZero() {
+		writeError(r, http.StatusBadRequest, 40004, "截止时间格式无效")
+		return
+	}
+	assignment, err := svc.CreateAssignment(r.GetCtx(), actorID, r.Get("id").Int64(), classroom.AssignmentInput{
+		Title:    req.Title,
+		Topic:    req.Topic,
+		Chapter:  req.Chapter,
+		MinScore: req.MinScore,
+		OpensAt:  opensAt,
+		DueAt:    dueAt,
+	})
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "作业已布置", assignment)
+}
+
+// DeleteAssignment 删除班级作业。
+func (h *Handler) DeleteAssignment(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	if err := svc.DeleteAssignment(r.GetCtx(), actorID, r.Get("id").Int64(), r.Get("assignmentId").Int64()); err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "作业已删除", nil)
+}
+
+// GetAssignmentReport 返回作业完成报告，export=csv 时导出成绩表。
+func (h *Handler) GetAssignmentReport(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	export := r.Get("export").String()
+	if export != "" && export != "csv" {
+		writeError(r, http.StatusBadRequest, 40004, "导出格式仅支持 csv")
+		return
+	}
+	report, err := svc.AssignmentReport(r.GetCtx(), actorID, r.Get("id").Int64(), r.Get("assignmentId").Int64())
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	if export == "csv" {
+		exportAssignmentReport(r, report)
+		audit.Record(r.GetCtx(), "assignment_report_exported", actorID, "ok", fmt.Sprintf("assignment_id=%d", report.Assignment.ID))
+		return
+	}
+	writeSuccess(r, "success", report)
+}
+
+// ListMyAssignments 返回当前学生的未完成、已逾期与已完成作业。
+func (h *Handler) ListMyAssignments(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	items, err := svc.MyAssignments(r.GetCtx(), actorID)
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "success", items)
+}
+
+var assignmentCSVHeader = []string{"userId", "username", "status", "completedAt", "attempts", "bestScore", "dueAt"}
+
+func exportAssignmentReport(r *ghttp.Request, report *classroom.AssignmentReport) {
+	filename := fmt.Sprintf("assignment-%d-%s.csv", report.Assignment.ID, report.GeneratedAt.UTC().Format("20060102T150405Z"))
+	r.Response.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	r.Response.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	w := csv.NewWriter(r.Response.Writer)
+	_ = w.Write(assignmentCSVHeader)
+	dueAt := report.Assignment.DueAt.UTC().Format(time.RFC3339)
+	for _, s := range report.Students {
+		completedAt, bestScore := "", ""
+		if s.CompletedAt != nil {
+			completedAt = s.CompletedAt.UTC().Format(time.RFC3339)
+		}
+		if s.BestScore != nil {
+			bestScore = strconv.FormatFloat(*s.BestScore, 'f', 1, 64)
+		}
+		if err := w.Write([]string{
+			strconv.FormatInt(s.UserID, 10),
+			csvSafe(s.Username),
+			s.Status,
+			completedAt,
+			strconv.Itoa(s.Attempts),
+			bestScore,
+			dueAt,
+		}); err != nil {
+			g.Log().Error(r.GetCtx(), err)
+			break
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-study2/internal/infrastructure/audit"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// 导出格式，通过 export 参数指定。
+const (
+	auditExportCSV   = "csv"
+	auditExportJSONL = "jsonl"
+)
+
+// ListAuditEvents 查询审计事件，支持过滤、游标分页与 CSV/JSONL 导出（管理员）。
+func (h *Handler) ListAuditEvents(r *ghttp.Request) {
+	filter, ok := parseAuditFilter(r)
+	if !ok {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+
+	switch export := r.Get("export").String(); export {
+	case "":
+	case auditExportCSV, auditExportJSONL:
+		exportAuditEvents(r, filter, export)
+		return
+	default:
+		writeError(r, http.StatusBadRequest, 40004, "导出格式仅支持 csv、jsonl")
+		return
+	}
+
+	page, err := audit.Query(r.GetCtx(), filter)
+	if err != nil {
+		writeAuditError(r, err)
+		return
+	}
+	writeSuccess(r, "success", page)
+}
+
+// VerifyAuditChain 校验审计哈希链是否完整（管理员）。
+func (h *Handler) VerifyAuditChain(r *ghttp.Request) {
+	report, err := audit.Verify(r.GetCtx())
+	if err != nil {
+		writeAuditError(r, err)
+		return
+	}
+	writeSuccess(r, "success", report)
+}
+
+func parseAuditFilter(r *ghttp.Request) (audit.Filter, bool) {
+	filter := audit.Filter{
+		EventType: strings.TrimSpace(r.Get("eventType").String()),
+		Result:    strings.TrimSpace(r.Get("result").String()),
+		Cursor:    r.Get("cursor").String(),
+	}
+	if raw := r.Get("userId").String(); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			return filter, false
+		}
+		filter.UserID = id
+	}
+	if raw := r.Get("limit").String(); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return filter, false
+		}
+		filter.Limit = limit
+	}
+	var ok bool
+	if filter.From, ok = parseAuditTime(r.Get("from").String()); !ok {
+		return filter, false
+	}
+	if filter.To, ok = parseAuditTime(r.Get("to").String()); !ok {
+		return filter, false
+	}
+	return filter, true
+}
+
+// parseAuditTime 接受 RFC3339 时间或 YYYY-MM-DD 日期（按 UTC 零点）。
+func parseAuditTime(raw string) (time.Time, bool) {
+	if raw == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+var auditCSVHeader = []string{"id", "createdAt", "eventType", "userId", "result", "requestId", "ip", "userAgent", "detail", "fields", "hash"}
+
+func exportAuditEvents(r *ghttp.Request, filter audit.Filter, format string) {
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	contentType := "text/csv; charset=utf-8"
+	if format == auditExportJSONL {
+		contentType = "application/x-ndjson"
+	}
+	r.Response.Header().Set("Content-Type", contentType)
+	r.Response.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	var err error
+	if format == auditExportCSV {
+		w := csv.NewWriter(r.Response.Writer)
+		_ = w.Write(auditCSVHeader)
+		err = audit.Each(r.GetCtx(), filter, func(ev audit.Event) error {
+			fields := ""
+			if len(ev.Metadata.Fields) > 0 {
+				raw, _ := json.Marshal(ev.Metadata.Fields)
+				fields = string(raw)
+			}
+			return w.Write([]string{
+				strconv.FormatInt(ev.ID, 10),
+				ev.CreatedAt.UTC().Format(time.RFC3339),
+				csvSafe(ev.EventType),
+				strconv.FormatInt(ev.UserID, 10),
+				csvSafe(ev.Result),
+				ev.Metadata.RequestID,
+				ev.Metadata.IP,
+				csvSafe(ev.Metadata.UserAgent),
+				csvSafe(ev.Metadata.Detail),
+				csvSafe(fields),
+				ev.Hash,
+			})
+		})
+		w.Flush()
+	} else {
+		enc := json.NewEncoder(r.Response.Writer)
+		err = audit.Each(r.GetCtx(), filter, func(ev audit.Event) error {
+			return enc.Encode(ev)
+		})
+	}
+	if err != nil {
+		g.Log().Error(r.GetCtx(), err)
+	}
+	audit.Record(r.GetCtx(), "audit_exported", r.GetCtxVar("user_id").Int64(), "ok", format)
+}
+
+// csvSafe 防止以公式字符开头的单元格在表格软件中被执行。
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func writeAuditError(r *ghttp.Request, err error) {
+	switch err {
+	case audit.ErrInvalidCursor:
+		writeError(r, http.StatusBadRequest, 40004, "分页游标无效")
+	case audit.ErrUnavailable:
+		writeError(r, http.StatusInternalServerError, 50001, "审计存储不可用")
+	default:
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
+	}
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"go-study2/internal/app/http_server/handler/internal"
 	"go-study2/internal/domain/user"
+	"go-study2/internal/pkg/password"
+	"go-study2/internal/pkg/requestid"
 
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
@@ -22,6 +25,7 @@ type authResponse struct {
 	AccessToken        string `json:"accessToken"`
 	ExpiresIn          int64  `json:"expiresIn"`
 	NeedPasswordChange bool   `json:"needPasswordChange"`
+	NeedMfaSetup       bool   `json:"needMfaSetup"`
 	IsAdmin            bool   `json:"isAdmin"`
 }
 
@@ -29,7 +33,9 @@ type profileResponse struct {
 	ID                 int64  `json:"id"`
 	Username           string `json:"username"`
 	IsAdmin            bool   `json:"isAdmin"`
+	IsTeacher          bool   `json:"isTeacher"`
 	MustChangePassword bool   `json:"mustChangePassword"`
+	MfaEnabled         bool   `json:"mfaEnabled"`
 }
 
 type changePasswordRequest struct {
@@ -87,16 +93,27 @@ func (h *Handler) Login(r *ghttp.Request) {
 	}
 
 	result, err := svc.Login(r.GetCtx(), req.Username, req.Password)
+	recordLogin(loginMethodPassword, result, err)
 	if err != nil {
 		writeAuthError(r, err)
 		return
 	}
 
+	if result.MFAChallenge != nil {
+		writeSuccess(r, "需要两步验证", mfaChallengeResponse{
+			MfaRequired: true,
+			MfaToken:    result.MFAChallenge.Token,
+			ExpiresAt:   result.MFAChallenge.ExpiresAt.Format(time.RFC3339),
+		})
+		return
+	}
+
 	h.setRefreshCookie(r, svc, result.Tokens.RefreshToken, req.isRemember())
 	writeSuccess(r, "登录成功", authResponse{
 		AccessToken:        result.Tokens.AccessToken,
 		ExpiresIn:          result.Tokens.AccessExpiresIn,
 		NeedPasswordChange: result.User.MustChangePassword,
+		NeedMfaSetup:       result.MFASetupRequired,
 		IsAdmin:            result.User.IsAdmin,
 	})
 }
@@ -202,11 +219,19 @@ func (h *Handler) GetProfile(r *ghttp.Request) {
 		return
 	}
 
+	mfaEnabled, _, err := svc.MFAStatus(r.GetCtx(), userID)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+
 	writeSuccess(r, "success", profileResponse{
 		ID:                 info.ID,
 		Username:           info.Username,
 		IsAdmin:            info.IsAdmin,
+		IsTeacher:          info.IsTeacher,
 		MustChangePassword: info.MustChangePassword,
+		MfaEnabled:         mfaEnabled,
 	})
 }
 
@@ -232,16 +257,32 @@ func writeSuccess(r *ghttp.Request, message string, data interface{}) {
 }
 
 func writeError(r *ghttp.Request, status int, code int, message string) {
+	writeErrorData(r, status, code, message, nil)
+}
+
+// writeErrorData 返回附带明细数据的错误响应。
+func writeErrorData(r *ghttp.Request, status int, code int, message string, data interface{}) {
 	r.Response.WriteStatus(status)
 	r.Response.ClearBuffer()
 	r.Response.WriteJson(Response{
-		Code:    code,
-		Message: message,
-		Data:    nil,
+		Code:      code,
+		Message:   message,
+		Data:      data,
+		RequestID: requestid.FromContext(r.Context()),
 	})
 }
 
+type passwordPolicyResponse struct {
+	Violations []password.Violation `json:"violations"`
+}
+
 func writeAuthError(r *ghttp.Request, err error) {
+	var policyErr *password.PolicyError
+	if errors.As(err, &policyErr) {
+		writeErrorData(r, http.StatusBadRequest, 40023, "密码不符合安全策略", passwordPolicyResponse{Violations: policyErr.Violations})
+		return
+	}
+
 	switch err {
 	case user.ErrInvalidInput:
 		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
@@ -257,6 +298,26 @@ func writeAuthError(r *ghttp.Request, err error) {
 		writeError(r, http.StatusUnauthorized, 40002, "刷新令牌无效或已过期")
 	case user.ErrUserNotFound:
 		writeError(r, http.StatusNotFound, 40001, "用户不存在")
+	case user.ErrMFASetupRequired:
+		writeError(r, http.StatusForbidden, 40012, "需要先启用两步验证")
+	case user.ErrMFACodeInvalid:
+		writeError(r, http.StatusUnauthorized, 40013, "两步验证码无效")
+	case user.ErrMFAChallengeInvalid:
+		writeError(r, http.StatusUnauthorized, 40014, "两步验证已过期，请重新登录")
+	case user.ErrMFANotEnrolled, user.ErrMFANotEnabled:
+		writeError(r, http.StatusBadRequest, 40015, "两步验证未启用")
+	case user.ErrMFAAlreadyEnabled:
+		writeError(r, http.StatusConflict, 40016, "两步验证已启用")
+	case user.ErrInviteInvalid:
+		writeError(r, http.StatusBadRequest, 40017, "邀请码无效、已过期或已用尽")
+	case user.ErrRegistrationClosed:
+		writeError(r, http.StatusForbidden, 40018, "当前未开放注册，请使用邀请码")
+	case user.ErrExternalLoginState:
+		writeError(r, http.StatusUnauthorized, 40019, "外部登录已过期，请重新登录")
+	case user.ErrExternalNotLinked:
+		writeError(r, http.StatusForbidden, 40020, "外部账号未关联本地用户，请联系管理员")
+	case user.ErrAccessTokenNotFound:
+		writeError(r, http.StatusNotFound, 40022, "访问令牌不存在")
 	default:
 		g.Log().Error(r.GetCtx(), err)
 		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-study2/internal/pkg/epub"
+	"go-study2/src/learning/types"
+	"go-study2/src/learning/variables"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+const (
+	bookTitle      = "Go 语言规范学习"
+	bookIdentifier = "urn:x-go-study2:course"
+	bookLanguage   = "zh-CN"
+	bookFileName   = "go-study"
+)
+
+// bookContentTypes 课程电子书支持的导出格式及其 Content-Type
+var bookContentTypes = map[string]string{
+	"epub": epub.MediaType,
+	"html": "text/html; charset=utf-8",
+}
+
+// ErrUnsupportedBookFormat 表示导出格式不受支持。
+var ErrUnsupportedBookFormat = errors.New("导出格式仅支持 epub、html")
+
+// BookOptions 课程电子书导出选项。
+type BookOptions struct {
+	Quiz     bool      // 附带各章测验，答案与解析集中在附录
+	Modified time.Time // EPUB 的修改时间，零值时取 Unix 纪元，保证相同内容输出相同文件
+}
+
+// bookPart 电子书的一部分：一个学习主题或附录，Page 为该部分的导读页。
+type bookPart struct {
+	ID       string
+	Page     *page
+	Chapters []bookChapter
+}
+
+// bookChapter 电子书中的一章，ID 在全书唯一，用作 EPUB 文件名与 HTML 锚点。
+type bookChapter struct {
+	ID   string
+	Page *page
+}
+
+// bookQuiz 收集各章测验：题目与选项写入章节末尾，答案与解析按章汇总到附录。
+type bookQuiz struct {
+	enabled bool
+	answers []block
+}
+
+func (q *bookQuiz) add(p *page, items []listItem) {
+	if !q.enabled || len(items) == 0 {
+		return
+	}
+	questions := make([]listItem, len(items))
+	keys := make([]listItem, len(items))
+	for i, item := range items {
+		// quizListItem 的第一条说明为答案，其余为解析
+		questions[i] = listItem{Text: item.Text, Sub: item.Sub}
+		keys[i] = listItem{Text: item.Notes[0], Notes: item.Notes[1:]}
+	}
+	p.Blocks = append(p.Blocks, headingBlock(2, "测验"), orderedListBlock(questions...))
+	q.answers = append(q.answers, headingBlock(2, p.Title), orderedListBlock(keys...))
+}
+
+// WriteBook 按教学顺序汇编全部课程内容，以 epub 或 html 格式写入 w。
+func WriteBook(w io.Writer, format string, opts BookOptions) error {
+	if _, ok := bookContentTypes[format]; !ok {
+		return ErrUnsupportedBookFormat
+	}
+	parts, err := courseBook(opts.Quiz)
+	if err != nil {
+		return err
+	}
+	if format == "html" {
+		_, err = io.WriteString(w, htmlBook(parts))
+		return err
+	}
+	modified := opts.Modified
+	if modified.IsZero() {
+		modified = time.Unix(0, 0)
+	}
+	return epubBook(parts, modified).Write(w)
+}
+
+// ExportBook 导出课程电子书：format 为 epub（默认）或 html，quiz=true 时附带测验与答案附录。
+func (h *Handler) ExportBook(r *ghttp.Request) {
+	format := r.GetQuery("format", "epub").String()
+	contentType, ok := bookContentTypes[format]
+	if !ok {
+		writeError(r, http.StatusBadRequest, 40004, ErrUnsupportedBookFormat.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBook(&buf, format, BookOptions{Quiz: r.GetQuery("quiz").Bool()}); err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "电子书生成失败")
+		return
+	}
+	r.Response.Header().Set("Content-Type", contentType)
+	r.Response.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, bookFileName, format))
+	r.Response.Write(buf.Bytes())
+}
+
+// courseBook 按 courseTopics 的教学顺序汇编全书，quiz 为 true 时追加测验答案附录。
+func courseBook(quiz bool) ([]bookPart, error) {
+	q := &bookQuiz{enabled: quiz}
+	var parts []bookPart
+	for _, topic := range courseTopics {
+		var (
+			chapters []bookChapter
+			err      error
+		)
+		switch topic.ID {
+		case "lexical_elements":
+			chapters = chapterDefChapters(topic.ID, lexicalChapters)
+		case "constants":
+			chapters = chapterDefChapters(topic.ID, constantsChapters)
+		case "variables":
+			chapters, err = variablesBookChapters(q)
+		case "types":
+			chapters, err = typesBookChapters(q)
+		default:
+			err = fmt.Errorf("主题 %s 没有可导出的内容", topic.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, bookPart{ID: topic.ID, Page: partPage(topic, chapters), Chapters: chapters})
+	}
+	if len(q.answers) > 0 {
+		parts = append(parts, bookPart{ID: "answers", Page: &page{Title: "附录：测验答案", Blocks: q.answers}})
+	}
+	return parts, nil
+}
+
+// partPage 生成主题导读页：主题简介与本部分的章节目录。
+func partPage(topic Topic, chapters []bookChapter) *page {
+	items := make([]listItem, len(chapters))
+	for i, c := range chapters {
+		items[i] = listItem{Text: c.Page.Title, Link: c.ID}
+	}
+	return &page{Title: topic.Title, Blocks: []block{paragraphBlock(topic.Description), listBlock(items...)}}
+}
+
+func chapterDefChapters(partID string, defs []chapterDef) []bookChapter {
+	chapters := make([]bookChapter, len(defs))
+	for i, c := range defs {
+		chapters[i] = bookChapter{ID: partID + "-" + c.ID, Page: &page{Title: c.Title, Blocks: []block{codeBlock("text", c.ContentFunc())}}}
+	}
+	return chapters
+}
+
+func variablesBookChapters(q *bookQuiz) ([]bookChapter, error) {
+	var chapters []bookChapter
+	for _, v := range variableTopics {
+		content, err := variables.LoadContent(v.Topic)
+		if err != nil {
+			return nil, err
+		}
+		p := variableLessonPage(content)
+		p.Back = nil
+		quiz, err := variables.LoadQuiz(v.Topic)
+		if err != nil && err != variables.ErrQuizUnavailable {
+			return nil, err
+		}
+		items := make([]listItem, len(quiz))
+		for i, item := range quiz {
+			items[i] = quizListItem(item.Stem, item.Options, item.Answer, item.Explanation)
+		}
+		q.add(p, items)
+		chapters = append(chapters, bookChapter{ID: "variables-" + v.ID, Page: p})
+	}
+	return chapters, nil
+}
+
+// typesBookChapters 以可打印提纲开篇，随后按 types.AllTopics 的顺序输出各子主题。
+func typesBookChapters(q *bookQuiz) ([]bookChapter, error) {
+	outline := typesOutlinePage(types.GetOverview())
+	outline.Back = nil
+	chapters := []bookChapter{{ID: "types-outline", Page: outline}}
+	for _, topic := range types.AllTopics() {
+		content, err := types.LoadContent(topic)
+		if err != nil {
+			return nil, err
+		}
+		p := typesLessonPage(content)
+		p.Back = nil
+		quiz, err := types.LoadQuiz(topic)
+		if err != nil && err != types.ErrQuizUnavailable {
+			return nil, err
+		}
+		items := make([]listItem, len(quiz))
+		for i, item := range quiz {
+			items[i] = quizListItem(item.Stem, item.Options, item.Answer, item.Explanation)
+		}
+		q.add(p, items)
+		chapters = append(chapters, bookChapter{ID: "types-" + string(topic), Page: p})
+	}
+	return chapters, nil
+}
+
+// bookStyle 电子书样式：浅色背景、衬线正文，适合电子阅读器。
+const bookStyle = `body { font-family: serif; line-height: 1.6; margin: 0 auto; max-width: 46em; padding: 0 1em; color: #222; background: #fff; }
+h1, h2, h3, h4, h5, h6 { font-family: sans-serif; line-height: 1.3; page-break-after: avoid; }
+h1 { border-bottom: 1px solid #ccc; padding-bottom: 0.3em; }
+pre { font-family: monospace; font-size: 0.85em; background: #f6f6f6; border: 1px solid #ddd; padding: 0.6em; white-space: pre-wrap; word-wrap: break-word; }
+a { color: #0b5394; }
+.note { color: #555; font-size: 0.9em; }
+nav ol { list-style: none; padding-left: 1em; }
+section { page-break-before: always; }
+`
+
+// epubBook 把全书转换为 EPUB：每个部分与章节各占一个文档，导读页链接到本部分的章节文件。
+func epubBook(parts []bookPart, modified time.Time) *epub.Book {
+	book := &epub.Book{
+		Identifier: bookIdentifier,
+		Title:      bookTitle,
+		Language:   bookLanguage,
+		Modified:   modified,
+		TOCTitle:   "目录",
+		CSS:        bookStyle,
+	}
+	for _, part := range parts {
+		section := epub.Section{ID: part.ID, Title: part.Page.Title, Body: htmlBody(part.Page, 1, epub.SectionHref)}
+		for _, c := range part.Chapters {
+			section.Children = append(section.Children, epub.Section{ID: c.ID, Title: c.Page.Title, Body: htmlBody(c.Page, 1, epub.SectionHref)})
+		}
+		book.Sections = append(book.Sections, section)
+	}
+	return book
+}
+
+// htmlBook 把全书输出为内嵌样式的单个 HTML 文件，目录与导读页通过锚点跳转。
+func htmlBook(parts []bookPart) string {
+	href := func(id string) string { return "#" + id }
+	var toc, body strings.Builder
+	toc.WriteString("<nav id=\"toc\">\n<h2>目录</h2>\n<ol>\n")
+	for _, part := range parts {
+		fmt.Fprintf(&toc, "<li><a href=\"#%s\">%s</a>", part.ID, htmlText.Replace(part.Page.Title))
+		fmt.Fprintf(&body, "<section id=\"%s\">\n%s\n</section>\n", part.ID, htmlBody(part.Page, 1, href))
+		if len(part.Chapters) > 0 {
+			toc.WriteString("\n<ol>\n")
+			for _, c := range part.Chapters {
+				fmt.Fprintf(&toc, "<li><a href=\"#%s\">%s</a></li>\n", c.ID, htmlText.Replace(c.Page.Title))
+				fmt.Fprintf(&body, "<section id=\"%s\">\n%s\n</section>\n", c.ID, htmlBody(c.Page, 2, href))
+			}
+			toc.WriteString("</ol>\n")
+		}
+		toc.WriteString("</li>\n")
+	}
+	toc.WriteString("</ol>\n</nav>\n")
+
+	return "<!DOCTYPE html>\n<html lang=\"" + bookLanguage + "\">\n<head>\n<meta charset=\"utf-8\"/>\n<title>" + htmlText.Replace(bookTitle) + "</title>\n<style>\n" + bookStyle + "</style>\n</head>\n<body>\n<h1>" + htmlText.Replace(bookTitle) + "</h1>\n" +
+		toc.String() + body.String() +
+		"</body>\n</html>\n"
+}
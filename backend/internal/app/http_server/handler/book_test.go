@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestWriteBook_EPUB(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var buf bytes.Buffer
+		t.AssertNil(WriteBook(&buf, "epub", BookOptions{Quiz: true}))
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		t.AssertNil(err)
+		files := make(map[string]string)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			t.AssertNil(err)
+			content, _ := io.ReadAll(rc)
+			rc.Close()
+			files[f.Name] = string(content)
+		}
+
+		// 目录按教学顺序排列，Types 以提纲开篇，答案附录在最后
+		nav := files["OEBPS/nav.xhtml"]
+		order := []string{"lexical_elements.xhtml", "lexical_elements-comments.xhtml", "constants-iota.xhtml", "variables-storage.xhtml",
+			"types-outline.xhtml", "types-boolean.xhtml", "types-channel.xhtml", "answers.xhtml"}
+		for i := 1; i < len(order); i++ {
+			t.Assert(strings.Index(nav, order[i-1]) < strings.Index(nav, order[i]), true)
+		}
+
+		slice := files["OEBPS/text/types-slice.xhtml"]
+		t.AssertIN("<h3>规则</h3>", slice)
+		t.AssertIN("<h3>测验</h3>", slice)
+		t.AssertNI("答案: ", slice)
+		t.AssertIN("答案: ", files["OEBPS/text/answers.xhtml"])
+		t.AssertIN(`<a href="types-slice.xhtml">`, files["OEBPS/text/types.xhtml"])
+
+		// 章节文档由 htmlBody 生成，必须是格式良好的 XHTML
+		for name, content := range files {
+			if !strings.HasSuffix(name, ".xhtml") {
+				continue
+			}
+			dec := xml.NewDecoder(strings.NewReader(content))
+			dec.Strict = true
+			for {
+				_, err := dec.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s 不是格式良好的 XHTML: %v", name, err)
+				}
+			}
+		}
+
+		// 输出可重复，便于版本间比对
+		var again bytes.Buffer
+		t.AssertNil(WriteBook(&again, "epub", BookOptions{Quiz: true}))
+		t.Assert(bytes.Equal(buf.Bytes(), again.Bytes()), true)
+	})
+}
+
+func TestWriteBook_HTML(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var buf bytes.Buffer
+		t.AssertNil(WriteBook(&buf, "html", BookOptions{}))
+		book := buf.String()
+
+		t.AssertIN("<style>\n", book)
+		t.AssertNI("<link", book)
+		t.AssertNI("<script", book)
+		t.AssertNI("附录：测验答案", book)
+		t.AssertNI("<h4>测验</h4>", book)
+
+		// 所有站内链接都指向书内存在的锚点
+		ids := make(map[string]bool)
+		for _, m := range regexp.MustCompile(`<section id="([^"]+)">`).FindAllStringSubmatch(book, -1) {
+			ids[m[1]] = true
+		}
+		t.Assert(ids["types-outline"], true)
+		for _, m := range regexp.MustCompile(`href="([^"]*)"`).FindAllStringSubmatch(book, -1) {
+			if !strings.HasPrefix(m[1], "#") || !ids[m[1][1:]] {
+				t.Fatalf("链接 %s 没有对应的章节", m[1])
+			}
+		}
+
+		t.Assert(WriteBook(io.Discard, "pdf", BookOptions{}), ErrUnsupportedBookFormat)
+	})
+}
+
+func TestExportBook(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		s := g.Server("test-export-book")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+		s.Group("/api/v1/export", func(group *ghttp.RouterGroup) {
+			group.GET("/book", New().ExportBook)
+		})
+		s.Start()
+		defer s.Shutdown()
+
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+
+		resp, err := client.Get(nil, "/api/v1/export/book")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		t.Assert(resp.Header.Get("Content-Type"), "application/epub+zip")
+		t.Assert(resp.Header.Get("Content-Disposition"), `attachment; filename="go-study.epub"`)
+		t.Assert(strings.HasPrefix(resp.ReadAllString(), "PK"), true)
+		resp.Close()
+
+		resp, err = client.Get(nil, "/api/v1/export/book?format=html&quiz=true")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		t.AssertIN("text/html", resp.Header.Get("Content-Type"))
+		t.AssertIN("附录：测验答案", resp.ReadAllString())
+		resp.Close()
+
+		resp, err = client.Get(nil, "/api/v1/export/book?format=pdf")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 400)
+		t.AssertIN("40004", resp.ReadAllString())
+		resp.Close()
+	})
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-study2/internal/app/http_server/handler/internal"
+	"go-study2/internal/domain/classroom"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type createClassRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type joinClassRequest struct {
+	Code string `json:"code"`
+}
+
+type addClassMemberRequest struct {
+	Username string `json:"username"`
+	// Role 为空时按学生加入。
+	Role string `json:"role"`
+}
+
+type setTeacherRequest struct {
+	Teacher bool `json:"teacher"`
+}
+
+// ListClasses 返回当前用户所属的班级，管理员可传 all=true 查看全部班级。
+func (h *Handler) ListClasses(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	classes, err := svc.ListClasses(r.GetCtx(), actorID, r.Get("all").Bool())
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "success", classes)
+}
+
+// CreateClass 由教师或管理员创建班级。
+func (h *Handler) CreateClass(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	var req createClassRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	class, err := svc.CreateClass(r.GetCtx(), actorID, req.Name, req.Description)
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "班级已创建", class)
+}
+
+// JoinClass 学生通过加入码加入班级。
+func (h *Handler) JoinClass(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	var req joinClassRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	class, err := svc.Join(r.GetCtx(), actorID, req.Code)
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "已加入班级", class)
+}
+
+// GetClass 返回班级详情与成员。
+func (h *Handler) GetClass(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	detail, err := svc.GetClass(r.GetCtx(), actorID, r.Get("id").Int64())
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "success", detail)
+}
+
+// DeleteClass 删除班级。
+func (h *Handler) DeleteClass(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	if err := svc.DeleteClass(r.GetCtx(), actorID, r.Get("id").Int64()); err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "班级已删除", nil)
+}
+
+// RegenerateClassJoinCode 重新生成班级加入码。
+func (h *Handler) RegenerateClassJoinCode(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	class, err := svc.RegenerateJoinCode(r.GetCtx(), actorID, r.Get("id").Int64())
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "加入码已更新", class)
+}
+
+// AddClassMember 由班级教师按用户名添加成员。
+func (h *Handler) AddClassMember(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	var req addClassMemberRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	member, err := svc.AddMember(r.GetCtx(), actorID, r.Get("id").Int64(), req.Username, req.Role)
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "成员已添加", member)
+}
+
+// RemoveClassMember 移除班级成员，学生可移除自己以退出班级。
+func (h *Handler) RemoveClassMember(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	if err := svc.RemoveMember(r.GetCtx(), actorID, r.Get("id").Int64(), r.Get("userId").Int64()); err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "成员已移除", nil)
+}
+
+// GetClassDashboard 返回班级学习看板（班级教师与管理员）。
+func (h *Handler) GetClassDashboard(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	dashboard, err := svc.Dashboard(r.GetCtx(), actorID, r.Get("id").Int64())
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "success", dashboard)
+}
+
+// GetClassStudentReport 返回班级内单个学生的章节明细。
+func (h *Handler) GetClassStudentReport(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	report, err := svc.StudentReport(r.GetCtx(), actorID, r.Get("id").Int64(), r.Get("userId").Int64())
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "success", report)
+}
+
+// SetTeacherRole 由管理员授予或撤销用户的教师角色。
+func (h *Handler) SetTeacherRole(r *ghttp.Request) {
+	svc, operatorID, ok := h.currentUser(r)
+	if !ok {
+		return
+	}
+	var req setTeacherRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	updated, err := svc.SetTeacher(r.GetCtx(), operatorID, r.Get("id").Int64(), req.Teacher)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "教师角色已更新", updated)
+}
+
+func (h *Handler) currentClassActor(r *ghttp.Request) (*classroom.Service, int64, bool) {
+	if h.classService == nil {
+		svc, err := internal.BuildClassroomService()
+		if err != nil {
+			writeError(r, http.StatusInternalServerError, 50001, "班级服务不可用")
+			return nil, 0, false
+		}
+		h.classService = svc
+	}
+	actorID := r.GetCtxVar("user_id").Int64()
+	if actorID <= 0 {
+		writeError(r, http.StatusUnauthorized, 40001, "认证信息缺失")
+		return nil, 0, false
+	}
+	return h.classService, actorID, true
+}
+
+func writeClassError(r *ghttp.Request, err error) {
+	switch err {
+	case classroom.ErrInvalidInput:
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+	case classroom.ErrUserNotFound:
+		writeError(r, http.StatusNotFound, 40001, "用户不存在")
+	case classroom.ErrClassNotFound:
+		writeError(r, http.StatusNotFound, 40024, "班级不存在")
+	case classroom.ErrPermissionDenied:
+		writeError(r, http.StatusForbidden, 40025, "无权执行该班级操作")
+	case classroom.ErrJoinCodeInvalid:
+		writeError(r, http.StatusBadRequest, 40026, "班级加入码无效")
+	case classroom.ErrAlreadyMember:
+		writeError(r, http.StatusConflict, 40027, "用户已在班级中")
+	case classroom.ErrLastTeacher:
+		writeError(r, http.StatusConflict, 40028, "班级至少需要保留一名教师")
+	case classroom.ErrNotMember:
+		writeError(r, http.StatusNotFound, 40029, "用户不在该班级")
+	case classroom.ErrAssignmentNotFound:
+		writeError(r, http.StatusNotFound, 40030, "作业不存在")
+	default:
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
+	}
+}
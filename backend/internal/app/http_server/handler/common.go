@@ -12,6 +12,7 @@ li:hover { background: #2d2d30; }
 pre { font-family: 'Consolas', 'Courier New', monospace; background: #2d2d30; padding: 15px; border-radius: 4px; overflow-x: auto; font-size: 14px; line-height: 1.5; color: #ce9178; box-shadow: 0 4px 6px rgba(0,0,0,0.3); }
 .back-link { display: inline-block; margin-top: 20px; padding: 10px 15px; background: #0e639c; color: white; border-radius: 4px; }
 .back-link:hover { background: #1177bb; color: white; }
+.note { color: #888; font-size: 0.9em; }
 </style>
 `
 
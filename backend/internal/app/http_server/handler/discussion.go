@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-study2/internal/app/http_server/handler/internal"
+	"go-study2/internal/domain/discussion"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type createThreadRequest struct {
+	Topic      string `json:"topic"`
+	Chapter    string `json:"chapter"`
+	QuestionID string `json:"questionId"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+}
+
+type replyRequest struct {
+	ParentID int64  `json:"parentId"`
+	Body     string `json:"body"`
+}
+
+type acceptRequest struct {
+	PostID int64 `json:"postId"`
+}
+
+type hidePostRequest struct {
+	Hidden bool `json:"hidden"`
+}
+
+type markMentionsRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// ListDiscussions 分页查询讨论主题，支持 topic、chapter、questionId、page、pageSize 与 includeHidden（管理员）参数。
+func (h *Handler) ListDiscussions(r *ghttp.Request) {
+	svc, userID, ok := h.currentDiscussionUser(r)
+	if !ok {
+		return
+	}
+	page, err := svc.List(r.GetCtx(), userID, discussion.Filter{
+		Topic:         r.Get("topic").String(),
+		Chapter:       r.Get("chapter").String(),
+		QuestionID:    r.Get("questionId").String(),
+		Page:          r.Get("page").Int(),
+		PageSize:      r.Get("pageSize").Int(),
+		IncludeHidden: r.Get("includeHidden").Bool(),
+	})
+	if err != nil {
+		writeDiscussionError(r, err)
+		return
+	}
+	writeSuccess(r, "success", page)
+}
+
+// CreateDiscussion 在章节或测验题下发起讨论。
+func (h *Handler) CreateDiscussion(r *ghttp.Request) {
+	svc, userID, ok := h.currentDiscussionUser(r)
+	if !ok {
+		return
+	}
+	var req createThreadRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	detail, err := svc.CreateThread(r.GetCtx(), userID, req.Topic, req.Chapter, req.QuestionID, req.Title, req.Body)
+	if err != nil {
+		writeDiscussionError(r, err)
+		return
+	}
+	writeSuccess(r, "讨论已发布", detail)
+}
+
+// GetDiscussion 返回主题及全部帖子，并标记为已读。
+func (h *Handler) GetDiscussion(r *ghttp.Request) {
+	svc, userID, ok := h.currentDiscussionUser(r)
+	if !ok {
+		return
+	}
+	detail, err := svc.Thread(r.GetCtx(), userID, r.Get("id").Int64())
+	if err != nil {
+		writeDiscussionError(r, err)
+		return
+	}
+	writeSuccess(r, "success", detail)
+}
+
+// ReplyDiscussion 在主题中发布回复。
+func (h *Handler) ReplyDiscussion(r *ghttp.Request) {
+	svc, userID, ok := h.currentDiscussionUser(r)
+	if !ok {
+		return
+	}
+	var req replyRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	post, err := svc.Reply(r.GetCtx(), userID, r.Get("id").Int64(), req.ParentID, req.Body)
+	if err != nil {
+		writeDiscussionError(r, err)
+		return
+	}
+	writeSuccess(r, "回复已发布", post)
+}
+
+// AcceptDiscussionAnswer 由教师或管理员采纳回答，postId 为 0 表示取消采纳。
+func (h *Handler) AcceptDiscussionAnswer(r *ghttp.Request) {
+	svc, userID, ok := h.currentDiscussionUser(r)
+	if !ok {
+		return
+	}
+	var req acceptRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	thread, err := svc.Accept(r.GetCtx(), userID, r.Get("id").Int64(), req.PostID)
+	if err != nil {
+		writeDiscussionError(r, err)
+		return
+	}
+	writeSuccess(r, "采纳状态已更新", thread)
+}
+
+// SetDiscussionPostHidden 由管理员隐藏或恢复帖子。
+func (h *Handler) SetDiscussionPostHidden(r *ghttp.Request) {
+	svc, userID, ok := h.currentDiscussionUser(r)
+	if !ok {
+		return
+	}
+	var req hidePostRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	post, err := svc.SetHidden(r.GetCtx(), userID, r.Get("postId").Int64(), req.Hidden)
+	if err != nil {
+		writeDiscussionError(r, err)
+		return
+	}
+	writeSuccess(r, "帖子状态已更新", post)
+}
+
+// ListMentions 返回当前用户收到的 @ 提及，unread=true 时只返回未读项。
+func (h *Handler) ListMentions(r *ghttp.Request) {
+	svc, userID, ok := h.currentDiscussionUser(r)
+	if !ok {
+		return
+	}
+	items, err := svc.Mentions(r.GetCtx(), userID, r.Get("unread").Bool())
+	if err != nil {
+		writeDiscussionError(r, err)
+		return
+	}
+	writeSuccess(r, "success", items)
+}
+
+// MarkMentionsRead 将提及标记为已读，ids 为空表示全部。
+func (h *Handler) MarkMentionsRead(r *ghttp.Request) {
+	svc, userID, ok := h.currentDiscussionUser(r)
+	if !ok {
+		return
+	}
+	var req markMentionsRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	if err := svc.MarkMentionsRead(r.GetCtx(), userID, req.IDs); err != nil {
+		writeDiscussionError(r, err)
+		return
+	}
+	writeSuccess(r, "提及已标记为已读", nil)
+}
+
+func (h *Handler) getDiscussionService(r *ghttp.Request) (*discussion.Service, bool) {
+	if h.discussService != nil {
+		return h.discussService, true
+	}
+	svc, err := internal.BuildDiscussionService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "讨论服务不可用")
+		return nil, false
+	}
+	h.discussService = svc
+	return svc, true
+}
+
+func (h *Handler) currentDiscussionUser(r *ghttp.Request) (*discussion.Service, int64, bool) {
+	svc, ok := h.getDiscussionService(r)
+	if !ok {
+		return nil, 0, false
+	}
+	userID := r.GetCtxVar("user_id").Int64()
+	if userID <= 0 {
+		writeError(r, http.StatusUnauthorized, 40001, "认证信息缺失")
+		return nil, 0, false
+	}
+	return svc, userID, true
+}
+
+func writeDiscussionError(r *ghttp.Request, err error) {
+	switch err {
+	case discussion.ErrInvalidInput:
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+	case discussion.ErrThreadNotFound:
+		writeError(r, http.StatusNotFound, 40038, "讨论主题不存在")
+	case discussion.ErrPostNotFound:
+		writeError(r, http.StatusNotFound, 40039, "帖子不存在")
+	case discussion.ErrPermissionDenied:
+		writeError(r, http.StatusForbidden, 40040, "无权操作讨论")
+	default:
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
+	}
+}
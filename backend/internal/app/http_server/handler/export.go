@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-study2/internal/domain/notes"
+	"go-study2/internal/domain/quiz"
+	"go-study2/internal/infrastructure/audit"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// userDataExport 为个人数据导出的内容。
+type userDataExport struct {
+	ExportedAt  time.Time          `json:"exportedAt"`
+	Profile     exportProfile      `json:"profile"`
+	Progress    []progressResponse `json:"progress"`
+	QuizHistory []quiz.HistoryItem `json:"quizHistory"`
+	Notes       []notes.Note       `json:"notes"`
+}
+
+type exportProfile struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	IsAdmin   bool      `json:"isAdmin"`
+	IsTeacher bool      `json:"isTeacher"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ExportMyData 以 JSON 附件导出当前用户的资料、学习进度、测验历史与笔记。
+func (h *Handler) ExportMyData(r *ghttp.Request) {
+	userSvc, err := h.ensureUserService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "认证服务不可用")
+		return
+	}
+	progressSvc, ok := h.getProgressService(r)
+	if !ok {
+		return
+	}
+	quizSvc, ok := h.getQuizService(r)
+	if !ok {
+		return
+	}
+	notesSvc, userID, ok := h.currentNotesUser(r)
+	if !ok {
+		return
+	}
+
+	ctx := r.GetCtx()
+	info, err := userSvc.Profile(ctx, userID)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	progressItems, err := progressSvc.ListAll(ctx, userID)
+	if err != nil {
+		h.writeProgressError(r, err)
+		return
+	}
+	history, err := quizSvc.History(ctx, userID, "", nil, nil)
+	if err != nil {
+		h.writeQuizError(r, err)
+		return
+	}
+	allNotes, err := notesSvc.Export(ctx, userID)
+	if err != nil {
+		writeNotesError(r, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	audit.Record(ctx, "user_data_exported", userID, "ok", fmt.Sprintf("notes=%d", len(allNotes)))
+	r.Response.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="go-study2-%s-%s.json"`, info.Username, now.Format("20060102T150405Z")))
+	writeSuccess(r, "success", userDataExport{
+		ExportedAt: now,
+		Profile: exportProfile{
+			ID:        info.ID,
+			Username:  info.Username,
+			IsAdmin:   info.IsAdmin,
+			IsTeacher: info.IsTeacher,
+			CreatedAt: info.CreatedAt,
+		},
+		Progress:    toProgressResponses(progressItems),
+		QuizHistory: history,
+		Notes:       allNotes,
+	})
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/infrastructure/database"
+	"go-study2/src/learning/types"
+	"go-study2/src/learning/variables"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// 单项检查与整体的状态值。
+const (
+	healthOK      = "ok"
+	healthFail    = "fail"
+	healthSkipped = "skipped"
+)
+
+// defaultCertMinValidDays 未配置 health.certMinValidDays 时证书剩余有效期的下限（天）。
+const defaultCertMinValidDays = 7
+
+// draining 为 true 表示服务正在关闭，就绪检查返回未就绪以便负载均衡先摘除实例。
+var draining atomic.Bool
+
+// startedAt 进程启动时间，用于存活检查输出运行时长。
+var startedAt = time.Now()
+
+// HealthCheck 单项检查结果。
+type HealthCheck struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // ok、fail 或 skipped
+	DurationMs int64  `json:"durationMs"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// HealthReport 健康检查响应：任一检查失败时 status 为 fail，HTTP 状态码为 503。
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// SetDraining 设置服务是否处于关闭前的摘流阶段。
+func SetDraining(v bool) {
+	draining.Store(v)
+}
+
+// GetHealthz 存活检查：进程能够处理请求即视为存活，不检查外部依赖，避免依赖故障导致实例被反复重启。
+func (h *Handler) GetHealthz(r *ghttp.Request) {
+	writeHealth(r, []HealthCheck{{
+		Name:   "process",
+		Status: healthOK,
+		Detail: fmt.Sprintf("已运行 %s", time.Since(startedAt).Truncate(time.Second)),
+	}})
+}
+
+// GetReadyz 就绪检查：依次检查摘流状态、数据库连接、迁移版本、学习内容与 HTTPS 证书有效期。
+func (h *Handler) GetReadyz(r *ghttp.Request) {
+	cfg := config.Default()
+	checks := []HealthCheck{
+		runHealthCheck("shutdown", func() (string, error) {
+			if draining.Load() {
+				return "", fmt.Errorf("服务正在关闭")
+			}
+			return "", nil
+		}),
+		runHealthCheck("database", func() (string, error) {
+			db := database.Default()
+			if db == nil {
+				return "", fmt.Errorf("数据库未初始化")
+			}
+			return "", db.PingMaster()
+		}),
+		runHealthCheck("migrations", func() (string, error) {
+			db := database.Default()
+			if db == nil {
+				return "", fmt.Errorf("数据库未初始化")
+			}
+			version, err := database.CurrentSchemaVersion(r.Context(), db)
+			if err != nil {
+				return "", err
+			}
+			if version != database.SchemaVersion {
+				return "", fmt.Errorf("数据库结构版本为 %d，期望 %d", version, database.SchemaVersion)
+			}
+			return fmt.Sprintf("version %d", version), nil
+		}),
+		runHealthCheck("content", contentLoaded),
+	}
+	if cfg != nil && cfg.Https.Enabled {
+		minValid := cfg.Health.CertMinValidDays
+		if minValid <= 0 {
+			minValid = defaultCertMinValidDays
+		}
+		checks = append(checks, runHealthCheck("tls", func() (string, error) {
+			return certValidity(cfg.Https.CertFile, time.Now(), time.Duration(minValid)*24*time.Hour)
+		}))
+	} else {
+		checks = append(checks, HealthCheck{Name: "tls", Status: healthSkipped, Detail: "未启用 HTTPS"})
+	}
+	writeHealth(r, checks)
+}
+
+func runHealthCheck(name string, fn func() (string, error)) HealthCheck {
+	start := time.Now()
+	detail, err := fn()
+	check := HealthCheck{Name: name, Status: healthOK, DurationMs: time.Since(start).Milliseconds(), Detail: detail}
+	if err != nil {
+		check.Status = healthFail
+		check.Error = err.Error()
+	}
+	return check
+}
+
+func writeHealth(r *ghttp.Request, checks []HealthCheck) {
+	report := HealthReport{Status: healthOK, Checks: checks}
+	status := http.StatusOK
+	for _, c := range checks {
+		if c.Status == healthFail {
+			report.Status = healthFail
+			status = http.StatusServiceUnavailable
+		}
+	}
+	r.Response.Header().Set("Cache-Control", "no-store")
+	r.Response.WriteStatus(status)
+	r.Response.ClearBuffer()
+	r.Response.WriteJson(report)
+}
+
+// contentLoaded 确认 courseTopics 中各主题的章节已注册；Variables 与 Types 的素材在包初始化时写入注册表。
+func contentLoaded() (string, error) {
+	chapters := 0
+	for _, topic := range courseTopics {
+		switch topic.ID {
+		case "lexical_elements":
+			chapters += len(lexicalChapters)
+		case "constants":
+			chapters += len(constantsChapters)
+		case "variables":
+			for _, v := range variableTopics {
+				if _, err := variables.LoadContent(v.Topic); err != nil {
+					return "", fmt.Errorf("Variables 子主题 %s 未加载: %w", v.ID, err)
+				}
+				chapters++
+			}
+		case "types":
+			for _, t := range types.AllTopics() {
+				if _, err := types.LoadContent(t); err != nil {
+					return "", fmt.Errorf("Types 子主题 %s 未加载: %w", t, err)
+				}
+				chapters++
+			}
+		default:
+			return "", fmt.Errorf("主题 %s 没有对应的内容", topic.ID)
+		}
+	}
+	if chapters == 0 {
+		return "", fmt.Errorf("没有可用的学习内容")
+	}
+	return fmt.Sprintf("%d 个主题，%d 个章节", len(courseTopics), chapters), nil
+}
+
+// certValidity 读取证书文件中的首个证书，剩余有效期不足 minValid 时返回错误。
+func certValidity(certFile string, now time.Time, minValid time.Duration) (string, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", fmt.Errorf("读取证书失败: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", fmt.Errorf("证书格式无效: %s", certFile)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("解析证书失败: %w", err)
+	}
+	expires := cert.NotAfter.UTC().Format(time.RFC3339)
+	if remaining := cert.NotAfter.Sub(now); remaining < minValid {
+		if remaining <= 0 {
+			return "", fmt.Errorf("证书已于 %s 过期", expires)
+		}
+		return "", fmt.Errorf("证书将于 %s 过期，剩余不足 %d 天", expires, int(minValid/(24*time.Hour)))
+	}
+	return "有效期至 " + expires, nil
+}
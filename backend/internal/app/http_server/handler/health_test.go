@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/infrastructure/database"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := gctx.New()
+		_ = os.MkdirAll("testdata", 0o755)
+		db, err := database.Init(ctx, config.DatabaseConfig{
+			Type: "sqlite3",
+			Path: filepath.ToSlash(filepath.Join("testdata", fmt.Sprintf("health_handler_%d.db", time.Now().UnixNano()))),
+		})
+		t.AssertNil(err)
+		previous := config.Default()
+		defer config.SetDefault(previous)
+		config.SetDefault(&config.Config{})
+
+		s := g.Server("test-health")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+		h := New()
+		s.Group("/", func(group *ghttp.RouterGroup) {
+			group.GET("/healthz", h.GetHealthz)
+			group.GET("/readyz", h.GetReadyz)
+		})
+		s.Start()
+		defer s.Shutdown()
+
+		base := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+		get := func(path string) (int, HealthReport, map[string]HealthCheck) {
+			resp, err := g.Client().Get(ctx, base+path)
+			t.AssertNil(err)
+			defer resp.Close()
+			var report HealthReport
+			t.AssertNil(json.Unmarshal(resp.ReadAll(), &report))
+			checks := make(map[string]HealthCheck)
+			for _, c := range report.Checks {
+				checks[c.Name] = c
+			}
+			return resp.StatusCode, report, checks
+		}
+
+		status, report, checks := get("/healthz")
+		t.Assert(status, http.StatusOK)
+		t.Assert(report.Status, healthOK)
+		t.Assert(checks["process"].Status, healthOK)
+
+		status, report, checks = get("/readyz")
+		t.Assert(status, http.StatusOK)
+		t.Assert(report.Status, healthOK)
+		for _, name := range []string{"shutdown", "database", "migrations", "content"} {
+			t.Assert(checks[name].Status, healthOK)
+		}
+		t.Assert(checks["migrations"].Detail, fmt.Sprintf("version %d", database.SchemaVersion))
+		t.Assert(checks["tls"].Status, healthSkipped)
+
+		// 结构版本与代码不一致时未就绪
+		_, err = db.Exec(ctx, "PRAGMA user_version = 0")
+		t.AssertNil(err)
+		status, report, checks = get("/readyz")
+		t.Assert(status, http.StatusServiceUnavailable)
+		t.Assert(report.Status, healthFail)
+		t.Assert(checks["migrations"].Status, healthFail)
+		t.Assert(checks["database"].Status, healthOK)
+		_, err = db.Exec(ctx, fmt.Sprintf("PRAGMA user_version = %d", database.SchemaVersion))
+		t.AssertNil(err)
+
+		// 摘流期间就绪检查失败，存活检查不受影响
+		SetDraining(true)
+		defer SetDraining(false)
+		status, _, checks = get("/readyz")
+		t.Assert(status, http.StatusServiceUnavailable)
+		t.Assert(checks["shutdown"].Status, healthFail)
+		status, _, _ = get("/healthz")
+		t.Assert(status, http.StatusOK)
+	})
+}
+
+func TestCertValidity(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		t.AssertNil(err)
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			NotBefore:    now.AddDate(0, -1, 0),
+			NotAfter:     now.AddDate(0, 0, 10),
+		}, &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "localhost"}}, &key.PublicKey, key)
+		t.AssertNil(err)
+		certFile := filepath.Join(t.TempDir(), "server.crt")
+		t.AssertNil(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+		detail, err := certValidity(certFile, now, 7*24*time.Hour)
+		t.AssertNil(err)
+		t.Assert(detail, "有效期至 2024-05-11T00:00:00Z")
+
+		_, err = certValidity(certFile, now, 14*24*time.Hour)
+		t.AssertNE(err, nil)
+		t.AssertIN("剩余不足 14 天", err.Error())
+
+		_, err = certValidity(certFile, now.AddDate(0, 1, 0), 7*24*time.Hour)
+		t.AssertNE(err, nil)
+		t.AssertIN("已于", err.Error())
+
+		_, err = certValidity(filepath.Join(t.TempDir(), "missing.crt"), now, time.Hour)
+		t.AssertNE(err, nil)
+	})
+}
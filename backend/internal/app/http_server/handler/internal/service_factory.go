@@ -1,14 +1,34 @@
 package internal
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
 
+	"go-study2/internal/config"
+	"go-study2/internal/domain/classroom"
+	"go-study2/internal/domain/discussion"
+	"go-study2/internal/domain/jobs"
+	"go-study2/internal/domain/leaderboard"
+	"go-study2/internal/domain/notes"
+	"go-study2/internal/domain/notifications"
 	"go-study2/internal/domain/progress"
 	"go-study2/internal/domain/quiz"
 	"go-study2/internal/domain/user"
+	"go-study2/internal/domain/webhooks"
+	"go-study2/internal/infrastructure/audit"
 	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/eventbus"
 	"go-study2/internal/infrastructure/repository"
 	appjwt "go-study2/internal/pkg/jwt"
+	"go-study2/internal/pkg/oidc"
+
+	"github.com/gogf/gf/v2/frame/g"
 )
 
 // BuildUserService 基于全局依赖构建默认用户服务。
@@ -17,7 +37,82 @@ func BuildUserService() (*user.Service, error) {
 	if db == nil {
 		return nil, errors.New("数据库未初始化")
 	}
-	return user.NewService(repository.NewUserRepository(db), appjwt.AccessTokenTTL(), appjwt.RefreshTokenTTL()), nil
+	svc := user.NewService(repository.NewUserRepository(db), appjwt.AccessTokenTTL(), appjwt.RefreshTokenTTL())
+	return svc.WithMFA(repository.NewMFARepository(db), mfaPolicy()).
+		WithRegistration(repository.NewInviteRepository(db), registrationPolicy()).
+		WithClassEnrollment(classroom.NewService(repository.NewClassroomRepository(db))).
+		WithIdentities(repository.NewIdentityRepository(db)).
+		WithAccessTokens(repository.NewAccessTokenRepository(db)).
+		WithPasswordPolicy(repository.NewPasswordHistoryRepository(db), passwordPolicy()).
+		WithCredentialCleanup(repository.NewCredentialCleanupRepository(db)).
+		WithEvents(events()), nil
+}
+
+// OIDCProvider 组合提供方配置与依赖方客户端。
+type OIDCProvider struct {
+	Config config.OidcProviderConfig
+	Client *oidc.Provider
+}
+
+// BuildOIDCProviders 按全局配置创建全部 OIDC 提供方客户端，键为提供方名称。
+func BuildOIDCProviders() map[string]*OIDCProvider {
+	providers := make(map[string]*OIDCProvider)
+	cfg := config.Default()
+	if cfg == nil {
+		return providers
+	}
+	for _, p := range cfg.Auth.Oidc.Providers {
+		providers[p.Name] = &OIDCProvider{
+			Config: p,
+			Client: oidc.NewProvider(oidc.Config{
+				Issuer:       p.Issuer,
+				ClientID:     p.ClientId,
+				ClientSecret: p.ClientSecret,
+				RedirectURL:  p.RedirectUrl,
+				Scopes:       p.Scopes,
+			}),
+		}
+	}
+	return providers
+}
+
+// registrationPolicy 从全局配置读取自助注册策略，未加载配置时默认关闭公开注册。
+func registrationPolicy() user.RegistrationPolicy {
+	cfg := config.Default()
+	if cfg == nil {
+		return user.RegistrationPolicy{}
+	}
+	return user.RegistrationPolicy{Open: cfg.Auth.Registration.Open}
+}
+
+// passwordPolicy 从全局配置读取密码有效期与历史复用限制，未加载配置时仅禁止复用当前密码。
+func passwordPolicy() user.PasswordPolicy {
+	cfg := config.Default()
+	if cfg == nil {
+		return user.PasswordPolicy{}
+	}
+	return user.PasswordPolicy{
+		MaxAge:      time.Duration(cfg.Auth.Password.MaxAgeDays) * 24 * time.Hour,
+		HistorySize: cfg.Auth.Password.HistorySize,
+	}
+}
+
+// mfaPolicy 从全局配置读取两步验证策略，未加载配置时使用默认值。
+func mfaPolicy() user.MFAPolicy {
+	cfg := config.Default()
+	if cfg == nil {
+		return user.MFAPolicy{Skew: 1}
+	}
+	issuer := cfg.Auth.Mfa.Issuer
+	if issuer == "" {
+		issuer = cfg.Jwt.Issuer
+	}
+	return user.MFAPolicy{
+		Issuer:          issuer,
+		RequireForAdmin: cfg.Auth.Mfa.RequireForAdmin,
+		ChallengeTTL:    time.Duration(cfg.Auth.Mfa.ChallengeTtl) * time.Second,
+		Skew:            cfg.Auth.Mfa.Skew,
+	}
 }
 
 // BuildProgressService 基于全局依赖构建学习进度服务。
@@ -26,7 +121,7 @@ func BuildProgressService() (*progress.Service, error) {
 	if db == nil {
 		return nil, errors.New("数据库未初始化")
 	}
-	return progress.NewService(repository.NewProgressRepository(db)), nil
+	return progress.NewService(repository.NewProgressRepository(db)).WithEvents(events()), nil
 }
 
 // BuildQuizService 基于全局依赖构建测验服务。
@@ -35,5 +130,320 @@ func BuildQuizService() (*quiz.Service, error) {
 	if db == nil {
 		return nil, errors.New("数据库未初始化")
 	}
-	return quiz.NewService(repository.NewQuizRepository(db)), nil
+	return quiz.NewService(repository.NewQuizRepository(db)).WithBank(repository.NewQuestionBankRepository(db)).WithEvents(events()), nil
+}
+
+// BuildClassroomService 基于全局依赖构建班级服务。
+func BuildClassroomService() (*classroom.Service, error) {
+	db := database.Default()
+	if db == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	repo := repository.NewClassroomRepository(db)
+	return classroom.NewService(repo).WithAssignments(repo).WithEvents(events()), nil
+}
+
+// BuildLeaderboardService 基于全局依赖构建排行榜服务。
+func BuildLeaderboardService() (*leaderboard.Service, error) {
+	db := database.Default()
+	if db == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	return leaderboard.NewService(repository.NewLeaderboardRepository(db)), nil
+}
+
+// BuildNotesService 基于全局依赖构建笔记服务。
+func BuildNotesService() (*notes.Service, error) {
+	db := database.Default()
+	if db == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	return notes.NewService(repository.NewNotesRepository(db)), nil
+}
+
+// BuildDiscussionService 基于全局依赖构建讨论区服务，关联题目通过测验服务校验。
+func BuildDiscussionService() (*discussion.Service, error) {
+	db := database.Default()
+	if db == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	quizSvc, err := BuildQuizService()
+	if err != nil {
+		return nil, err
+	}
+	return discussion.NewService(repository.NewDiscussionRepository(db)).WithQuestions(quizSvc).WithEvents(events()), nil
+}
+
+var (
+	notifyOnce sync.Once
+	notifyHub  *notifications.Hub
+)
+
+// BuildNotificationService 基于全局依赖构建通知服务，所有实例共享进程级推送中心。
+func BuildNotificationService() (*notifications.Service, error) {
+	db := database.Default()
+	if db == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	startNotifications()
+	return notifications.NewService(repository.NewNotificationRepository(db), notifyHub), nil
+}
+
+// startNotifications 创建推送中心并让通知服务订阅事件总线，只执行一次。
+func startNotifications() {
+	notifyOnce.Do(func() {
+		notifyHub = notifications.NewHub()
+		eventbus.Default().Subscribe(func(ctx context.Context, event eventbus.Event) {
+			if !notifications.Supports(event.Type) {
+				return
+			}
+			svc, err := BuildNotificationService()
+			if err != nil {
+				g.Log().Warning(ctx, err)
+				return
+			}
+			if err := svc.Deliver(ctx, event); err != nil {
+				g.Log().Errorf(ctx, "投递 %s 通知失败: %v", event.Type, err)
+			}
+		})
+	})
+}
+
+var (
+	webhookOnce         sync.Once
+	webhookDispatchOnce sync.Once
+	webhookWake         = make(chan struct{}, 1)
+)
+
+// BuildWebhookService 基于全局依赖构建 Webhook 服务，新投递入队后立即唤醒后台投递任务。
+func BuildWebhookService() (*webhooks.Service, error) {
+	db := database.Default()
+	if db == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	policy := webhookPolicy()
+	return webhooks.NewService(repository.NewWebhookRepository(db), webhooks.NewHTTPSender(policy.Timeout), policy).
+		WithWakeup(wakeWebhooks), nil
+}
+
+// StartWebhooks 让 Webhook 服务订阅事件总线并启动后台投递任务，只执行一次；
+// 服务启动时调用，以继续投递上次退出前发件箱中遗留的记录。
+func StartWebhooks() {
+	subscribeWebhooks()
+	webhookDispatchOnce.Do(func() {
+		go dispatchWebhooks()
+	})
+}
+
+// subscribeWebhooks 将事件写入 Webhook 发件箱，只订阅一次；投递由 StartWebhooks 启动的后台任务完成。
+func subscribeWebhooks() {
+	webhookOnce.Do(func() {
+		eventbus.Default().Subscribe(func(ctx context.Context, event eventbus.Event) {
+			svc, err := BuildWebhookService()
+			if err != nil {
+				g.Log().Warning(ctx, err)
+				return
+			}
+			if _, err := svc.Enqueue(ctx, event); err != nil {
+				g.Log().Errorf(ctx, "写入 %s Webhook 发件箱失败: %v", event.Type, err)
+			}
+		})
+	})
+}
+
+// dispatchWebhooks 按扫描间隔或入队唤醒发送到期投递，每轮取最新的数据库与配置。
+func dispatchWebhooks() {
+	ctx := context.Background()
+	for {
+		timer := time.NewTimer(webhookPollInterval())
+		select {
+		case <-webhookWake:
+			timer.Stop()
+		case <-timer.C:
+		}
+		if database.Default() == nil {
+			continue
+		}
+		svc, err := BuildWebhookService()
+		if err != nil {
+			g.Log().Warning(ctx, err)
+			continue
+		}
+		for {
+			handled, err := svc.DispatchDue(ctx)
+			if err != nil {
+				g.Log().Errorf(ctx, "投递 Webhook 失败: %v", err)
+				break
+			}
+			if handled == 0 {
+				break
+			}
+		}
+	}
+}
+
+func wakeWebhooks() {
+	select {
+	case webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// webhookPolicy 从全局配置读取 Webhook 重试策略，未设置的项由 webhooks.NewService 取默认值。
+func webhookPolicy() webhooks.Policy {
+	cfg := config.Default()
+	if cfg == nil {
+		return webhooks.Policy{}
+	}
+	return webhooks.Policy{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		BaseDelay:   time.Duration(cfg.Webhooks.RetryBaseSeconds) * time.Second,
+		MaxDelay:    time.Duration(cfg.Webhooks.RetryMaxSeconds) * time.Second,
+		Timeout:     time.Duration(cfg.Webhooks.TimeoutSeconds) * time.Second,
+	}
+}
+
+// webhookPollInterval 返回发件箱扫描间隔，默认 5 秒。
+func webhookPollInterval() time.Duration {
+	cfg := config.Default()
+	if cfg == nil || cfg.Webhooks.PollIntervalSeconds <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(cfg.Webhooks.PollIntervalSeconds) * time.Second
+}
+
+// auditRetentionJobName 为审计事件保留清理任务的名称。
+const auditRetentionJobName = "audit_retention"
+
+var (
+	jobsOnce sync.Once
+	// jobOwner 为本进程的实例标识，写入任务租约与执行记录。
+	jobOwner = sync.OnceValue(func() string {
+		host, err := os.Hostname()
+		if err != nil || host == "" {
+			host = "unknown"
+		}
+		suffix := make([]byte, 3)
+		_, _ = rand.Read(suffix)
+		return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+	})
+)
+
+// BuildJobService 基于全局依赖构建后台任务服务，任务计划取当前配置。
+func BuildJobService() (*jobs.Service, error) {
+	db := database.Default()
+	if db == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	registry, err := jobRegistry()
+	if err != nil {
+		return nil, err
+	}
+	return jobs.NewService(repository.NewJobRepository(db), registry, jobOwner()), nil
+}
+
+// jobRegistry 登记各领域提供的后台任务，任务执行时再构建所需服务。
+func jobRegistry() (*jobs.Registry, error) {
+	idleDays := 3
+	if cfg := config.Default(); cfg != nil && cfg.Jobs.ReviewIdleDays > 0 {
+		idleDays = cfg.Jobs.ReviewIdleDays
+	}
+	registry := jobs.NewRegistry()
+	for _, def := range []jobs.Definition{
+		user.CredentialCleanupJob(jobSchedule(user.CredentialCleanupJobName, user.DefaultCredentialCleanupSchedule), BuildUserService),
+		auditRetentionJob(),
+		progress.ReviewReminderJob(jobSchedule(progress.ReviewReminderJobName, progress.DefaultReviewReminderSchedule),
+			time.Duration(idleDays)*24*time.Hour, BuildProgressService),
+	} {
+		if err := registry.Register(def); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// auditRetentionJob 按 audit 配置归档并删除过期审计事件；兼容 pruneIntervalHours 作为默认执行间隔。
+func auditRetentionJob() jobs.Definition {
+	schedule := "30 3 * * *"
+	if cfg := config.Default(); cfg != nil && cfg.Audit.PruneIntervalHours > 0 {
+		schedule = fmt.Sprintf("@every %dh", cfg.Audit.PruneIntervalHours)
+	}
+	return jobs.Definition{
+		Name:        auditRetentionJobName,
+		Description: "按保留天数归档并删除过期审计事件",
+		Schedule:    jobSchedule(auditRetentionJobName, schedule),
+		Run: func(ctx context.Context) (string, error) {
+			cfg := config.Default()
+			if cfg == nil || cfg.Audit.RetentionDays <= 0 {
+				return "未配置保留天数，跳过", nil
+			}
+			result, err := audit.Prune(ctx, audit.RetentionPolicy{
+				MaxAge:     time.Duration(cfg.Audit.RetentionDays) * 24 * time.Hour,
+				ArchiveDir: cfg.Audit.ArchiveDir,
+			}, time.Now())
+			if err != nil {
+				return "", err
+			}
+			if result.ArchiveFile != "" {
+				return fmt.Sprintf("deleted=%d archive=%s", result.Deleted, result.ArchiveFile), nil
+			}
+			return fmt.Sprintf("deleted=%d", result.Deleted), nil
+		},
+	}
+}
+
+// jobSchedule 返回配置中覆盖的 cron 表达式，未配置时使用默认值。
+func jobSchedule(name, fallback string) string {
+	if cfg := config.Default(); cfg != nil && cfg.Jobs.Schedules[name] != "" {
+		return cfg.Jobs.Schedules[name]
+	}
+	return fallback
+}
+
+// StartJobs 启动后台任务调度循环，只执行一次；每轮按当前配置决定本实例是否执行到期任务。
+func StartJobs() {
+	jobsOnce.Do(func() {
+		go runJobs()
+	})
+}
+
+func runJobs() {
+	ctx := context.Background()
+	for {
+		if cfg := config.Default(); cfg != nil && cfg.Jobs.Enabled && database.Default() != nil {
+			runDueJobs(ctx)
+		}
+		time.Sleep(jobPollInterval())
+	}
+}
+
+func runDueJobs(ctx context.Context) {
+	svc, err := BuildJobService()
+	if err != nil {
+		g.Log().Warning(ctx, err)
+		return
+	}
+	if err := svc.Sync(ctx); err != nil {
+		g.Log().Errorf(ctx, "同步后台任务失败: %v", err)
+		return
+	}
+	if _, err := svc.RunDue(ctx); err != nil {
+		g.Log().Errorf(ctx, "执行后台任务失败: %v", err)
+	}
+}
+
+// jobPollInterval 返回检查到期任务的间隔，默认 30 秒。
+func jobPollInterval() time.Duration {
+	cfg := config.Default()
+	if cfg == nil || cfg.Jobs.PollIntervalSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(cfg.Jobs.PollIntervalSeconds) * time.Second
+}
+
+// events 返回领域服务使用的事件发布者，并确保通知与 Webhook 订阅已就绪。
+func events() eventbus.Publisher {
+	startNotifications()
+	subscribeWebhooks()
+	return eventbus.Default()
 }
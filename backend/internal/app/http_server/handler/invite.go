@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type signupRequest struct {
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	InviteCode string `json:"inviteCode"`
+	RememberMe bool   `json:"rememberMe"`
+}
+
+type createInviteRequest struct {
+	Role string `json:"role"`
+	// ClassID 大于 0 时注册成功后自动加入该班级（教师邀请码以教师身份加入）。
+	ClassID int64 `json:"classId"`
+	// MaxUses 为 0 时默认只允许使用一次。
+	MaxUses int `json:"maxUses"`
+	// ExpiresIn 有效期（秒），为 0 时永不过期。
+	ExpiresIn int64  `json:"expiresIn"`
+	Note      string `json:"note"`
+}
+
+type createInviteResponse struct {
+	Code   string       `json:"code"`
+	Invite *user.Invite `json:"invite"`
+}
+
+// Signup 处理公开自助注册，未开放注册时必须携带邀请码。
+func (h *Handler) Signup(r *ghttp.Request) {
+	svc, err := h.ensureUserService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "认证服务不可用")
+		return
+	}
+
+	var req signupRequest
+	if err := r.Parse(&req); err != nil || req.Username == "" || req.Password == "" {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+
+	result, err := svc.Signup(r.GetCtx(), req.InviteCode, req.Username, req.Password)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+
+	h.setRefreshCookie(r, svc, result.Tokens.RefreshToken, req.RememberMe)
+	writeSuccess(r, "注册成功", authResponse{
+		AccessToken:        result.Tokens.AccessToken,
+		ExpiresIn:          result.Tokens.AccessExpiresIn,
+		NeedPasswordChange: result.User.MustChangePassword,
+		NeedMfaSetup:       result.MFASetupRequired,
+		IsAdmin:            result.User.IsAdmin,
+	})
+}
+
+// CreateInvite 由管理员生成邀请码，明文邀请码仅在响应中返回一次。
+func (h *Handler) CreateInvite(r *ghttp.Request) {
+	svc, operatorID, ok := h.currentUser(r)
+	if !ok {
+		return
+	}
+
+	var req createInviteRequest
+	if err := r.Parse(&req); err != nil || req.ExpiresIn < 0 {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+
+	invite, code, err := svc.CreateInvite(r.GetCtx(), operatorID, user.InviteOptions{
+		Role:      req.Role,
+		ClassID:   req.ClassID,
+		MaxUses:   req.MaxUses,
+		ExpiresIn: time.Duration(req.ExpiresIn) * time.Second,
+		Note:      req.Note,
+	})
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "邀请码已创建，请妥善保存", createInviteResponse{Code: code, Invite: invite})
+}
+
+// ListInvites 返回全部邀请码及使用情况。
+func (h *Handler) ListInvites(r *ghttp.Request) {
+	svc, operatorID, ok := h.currentUser(r)
+	if !ok {
+		return
+	}
+	invites, err := svc.ListInvites(r.GetCtx(), operatorID)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "success", invites)
+}
+
+// RevokeInvite 撤销指定邀请码。
+func (h *Handler) RevokeInvite(r *ghttp.Request) {
+	svc, operatorID, ok := h.currentUser(r)
+	if !ok {
+		return
+	}
+	if err := svc.RevokeInvite(r.GetCtx(), operatorID, r.Get("id").Int64()); err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "邀请码已撤销", nil)
+}
+
+// ListInviteRedemptions 返回指定邀请码的使用记录。
+func (h *Handler) ListInviteRedemptions(r *ghttp.Request) {
+	svc, operatorID, ok := h.currentUser(r)
+	if !ok {
+		return
+	}
+	redemptions, err := svc.ListInviteRedemptions(r.GetCtx(), operatorID, r.Get("id").Int64())
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "success", redemptions)
+}
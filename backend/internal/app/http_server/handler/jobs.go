@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-study2/internal/app/http_server/handler/internal"
+	"go-study2/internal/domain/jobs"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type jobPausedRequest struct {
+	Paused *bool `json:"paused"`
+}
+
+// StartBackground 启动 Webhook 事件订阅与投递、定时任务调度、指标统计等后台任务，服务启动时调用一次即可。
+func StartBackground() {
+	startMetrics()
+	internal.StartWebhooks()
+	internal.StartJobs()
+}
+
+// ListJobs 返回全部定时任务及最近一次执行（管理员）。
+func (h *Handler) ListJobs(r *ghttp.Request) {
+	svc, ok := h.getJobService(r)
+	if !ok {
+		return
+	}
+	list, err := svc.List(r.GetCtx())
+	if err != nil {
+		writeJobError(r, err)
+		return
+	}
+	writeSuccess(r, "success", list)
+}
+
+// ListJobRuns 返回任务的执行历史，支持 limit 参数。
+func (h *Handler) ListJobRuns(r *ghttp.Request) {
+	svc, ok := h.getJobService(r)
+	if !ok {
+		return
+	}
+	runs, err := svc.Runs(r.GetCtx(), r.Get("name").String(), r.Get("limit").Int())
+	if err != nil {
+		writeJobError(r, err)
+		return
+	}
+	writeSuccess(r, "success", runs)
+}
+
+// SetJobPaused 暂停或恢复任务的计划执行。
+func (h *Handler) SetJobPaused(r *ghttp.Request) {
+	svc, ok := h.getJobService(r)
+	if !ok {
+		return
+	}
+	var req jobPausedRequest
+	if err := r.Parse(&req); err != nil || req.Paused == nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	job, err := svc.SetPaused(r.GetCtx(), r.GetCtxVar("user_id").Int64(), r.Get("name").String(), *req.Paused)
+	if err != nil {
+		writeJobError(r, err)
+		return
+	}
+	message := "任务已恢复"
+	if job.Paused {
+		message = "任务已暂停"
+	}
+	writeSuccess(r, message, job)
+}
+
+// TriggerJob 立即在后台执行一次任务，返回本次执行记录。
+func (h *Handler) TriggerJob(r *ghttp.Request) {
+	svc, ok := h.getJobService(r)
+	if !ok {
+		return
+	}
+	run, err := svc.Trigger(r.GetCtx(), r.GetCtxVar("user_id").Int64(), r.Get("name").String())
+	if err != nil {
+		writeJobError(r, err)
+		return
+	}
+	writeSuccess(r, "任务已开始执行", run)
+}
+
+func (h *Handler) getJobService(r *ghttp.Request) (*jobs.Service, bool) {
+	if h.jobService != nil {
+		return h.jobService, true
+	}
+	svc, err := internal.BuildJobService()
+	if err != nil {
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "定时任务服务不可用")
+		return nil, false
+	}
+	h.jobService = svc
+	return svc, true
+}
+
+func writeJobError(r *ghttp.Request, err error) {
+	switch err {
+	case jobs.ErrInvalidInput:
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+	case jobs.ErrJobNotFound:
+		writeError(r, http.StatusNotFound, 40043, "定时任务不存在")
+	case jobs.ErrJobRunning:
+		writeError(r, http.StatusConflict, 40044, "任务正在执行，请稍后再试")
+	default:
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
+	}
+}
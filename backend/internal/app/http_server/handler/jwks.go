@@ -0,0 +1,14 @@
+package handler
+
+import (
+	appjwt "go-study2/internal/pkg/jwt"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// GetJWKS 发布当前可用于验签的公钥集合，供其他内部服务校验本服务签发的令牌。
+// 按 RFC 7517 直接返回 JWK Set，不使用统一响应包装；HS256 对称密钥不会出现在结果中。
+func (h *Handler) GetJWKS(r *ghttp.Request) {
+	r.Response.Header().Set("Cache-Control", "public, max-age=300")
+	r.Response.WriteJson(appjwt.PublicJWKS())
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-study2/internal/app/http_server/handler/internal"
+	"go-study2/internal/domain/leaderboard"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type leaderboardPreferencesRequest struct {
+	OptOut   bool   `json:"optOut"`
+	Nickname string `json:"nickname"`
+}
+
+type classLeaderboardRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetLeaderboard 返回排行榜，支持 metric、scope、classId、window、topic、chapter 与 limit 参数。
+func (h *Handler) GetLeaderboard(r *ghttp.Request) {
+	svc, actorID, ok := h.currentBoardActor(r)
+	if !ok {
+		return
+	}
+	board, err := svc.Board(r.GetCtx(), actorID, leaderboard.Query{
+		Metric:  r.Get("metric").String(),
+		Scope:   r.Get("scope").String(),
+		ClassID: r.Get("classId").Int64(),
+		Window:  r.Get("window").String(),
+		Topic:   r.Get("topic").String(),
+		Chapter: r.Get("chapter").String(),
+		Limit:   r.Get("limit").Int(),
+	})
+	if err != nil {
+		writeLeaderboardError(r, err)
+		return
+	}
+	writeSuccess(r, "success", board)
+}
+
+// GetLeaderboardPreferences 返回当前用户的排行榜隐私设置。
+func (h *Handler) GetLeaderboardPreferences(r *ghttp.Request) {
+	svc, actorID, ok := h.currentBoardActor(r)
+	if !ok {
+		return
+	}
+	prefs, err := svc.Preferences(r.GetCtx(), actorID)
+	if err != nil {
+		writeLeaderboardError(r, err)
+		return
+	}
+	writeSuccess(r, "success", prefs)
+}
+
+// UpdateLeaderboardPreferences 设置是否退出排行榜与展示昵称。
+func (h *Handler) UpdateLeaderboardPreferences(r *ghttp.Request) {
+	svc, actorID, ok := h.currentBoardActor(r)
+	if !ok {
+		return
+	}
+	var req leaderboardPreferencesRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	prefs, err := svc.UpdatePreferences(r.GetCtx(), actorID, leaderboard.Preferences{OptOut: req.OptOut, Nickname: req.Nickname})
+	if err != nil {
+		writeLeaderboardError(r, err)
+		return
+	}
+	writeSuccess(r, "排行榜设置已更新", prefs)
+}
+
+// SetClassLeaderboard 由管理员开启或关闭班级排行榜。
+func (h *Handler) SetClassLeaderboard(r *ghttp.Request) {
+	svc, actorID, ok := h.currentClassActor(r)
+	if !ok {
+		return
+	}
+	var req classLeaderboardRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	class, err := svc.SetLeaderboardEnabled(r.GetCtx(), actorID, r.Get("id").Int64(), req.Enabled)
+	if err != nil {
+		writeClassError(r, err)
+		return
+	}
+	writeSuccess(r, "班级排行榜设置已更新", class)
+}
+
+func (h *Handler) currentBoardActor(r *ghttp.Request) (*leaderboard.Service, int64, bool) {
+	if h.boardService == nil {
+		svc, err := internal.BuildLeaderboardService()
+		if err != nil {
+			writeError(r, http.StatusInternalServerError, 50001, "排行榜服务不可用")
+			return nil, 0, false
+		}
+		h.boardService = svc
+	}
+	actorID := r.GetCtxVar("user_id").Int64()
+	if actorID <= 0 {
+		writeError(r, http.StatusUnauthorized, 40001, "认证信息缺失")
+		return nil, 0, false
+	}
+	return h.boardService, actorID, true
+}
+
+func writeLeaderboardError(r *ghttp.Request, err error) {
+	switch err {
+	case leaderboard.ErrInvalidInput:
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+	case leaderboard.ErrClassNotFound:
+		writeError(r, http.StatusNotFound, 40024, "班级不存在")
+	case leaderboard.ErrLeaderboardDisabled:
+		writeError(r, http.StatusForbidden, 40034, "班级排行榜已关闭")
+	case leaderboard.ErrNicknameTaken:
+		writeError(r, http.StatusConflict, 40035, "昵称已被占用")
+	default:
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
+	}
+}
@@ -1,8 +1,7 @@
 package handler
 
 import (
-	"fmt"
-	"strings"
+	"net/http"
 
 	"go-study2/internal/app/lexical_elements"
 
@@ -15,112 +14,113 @@ type chapterDef struct {
 	ContentFunc func() string
 }
 
-// 词法元素章节列表
-var lexicalChapters = []chapterDef{
-	{"comments", "Comments (注释)", lexical_elements.GetCommentsContent},
-	{"tokens", "Tokens (标记)", lexical_elements.GetTokensContent},
-	{"semicolons", "Semicolons (分号)", lexical_elements.GetSemicolonsContent},
-	{"identifiers", "Identifiers (标识符)", lexical_elements.GetIdentifiersContent},
-	{"keywords", "Keywords (关键字)", lexical_elements.GetKeywordsContent},
-	{"operators", "Operators (运算符)", lexical_elements.GetOperatorsContent},
-	{"integers", "Integers (整数)", lexical_elements.GetIntegersContent},
-	{"floats", "Floats (浮点数)", lexical_elements.GetFloatsContent},
-	{"imaginary", "Imaginary (虚数)", lexical_elements.GetImaginaryContent},
-	{"runes", "Runes (符文)", lexical_elements.GetRunesContent},
-	{"strings", "Strings (字符串)", lexical_elements.GetStringsContent},
+const (
+	lexicalBasePath  = apiPrefix + "/topic/lexical_elements"
+	lexicalMenuTitle = "Lexical Elements"
+)
+
+// 词法元素章节列表，由 lexical_elements 包统一维护，测验章节校验使用同一份目录
+var lexicalChapters = lexicalChapterDefs()
+
+func lexicalChapterDefs() []chapterDef {
+	var defs []chapterDef
+	for _, c := range lexical_elements.Chapters() {
+		defs = append(defs, chapterDef(c))
+	}
+	return defs
 }
 
+// topicsBackLink 菜单页面返回主题列表的链接
+var topicsBackLink = pageLink{Text: "Back to Topics", Path: apiPrefix + "/topics"}
+
 // GetLexicalMenu 获取词法元素菜单
 func (h *Handler) GetLexicalMenu(r *ghttp.Request) {
-	format := r.GetCtxVar("format").String()
+	writeChapterMenu(r, lexicalMenuTitle, lexicalBasePath, lexicalChapters)
+}
+
+// GetLexicalContent 获取具体章节内容
+func (h *Handler) GetLexicalContent(r *ghttp.Request) {
+	writeChapter(r, lexicalChapters, r.Get("chapter").String(), "Chapter not found", lexicalBasePath)
+}
 
-	items := make([]LexicalMenuItem, len(lexicalChapters))
-	for i, c := range lexicalChapters {
+// writeChapterMenu 输出 chapterDef 章节菜单，菜单项 ID 为章节下标，通过 Name 路由。
+func writeChapterMenu(r *ghttp.Request, title, basePath string, chapters []chapterDef) {
+	items := chapterMenuItems(chapters)
+	if rd := contentRenderer(r); rd != nil {
+		writePage(r, http.StatusOK, rd, menuPage(title, basePath, items, topicsBackLink))
+		return
+	}
+	writeMenuJSON(r, items)
+}
+
+func chapterMenuItems(chapters []chapterDef) []LexicalMenuItem {
+	items := make([]LexicalMenuItem, len(chapters))
+	for i, c := range chapters {
 		items[i] = LexicalMenuItem{
-			ID:    i, // 使用索引作为简单 ID，通过 Name 路由
+			ID:    i,
 			Title: c.Title,
 			Name:  c.ID,
 		}
 	}
-
-	if format == "html" {
-		h.sendLexicalMenuHTML(r, items)
-	} else {
-		h.sendLexicalMenuJSON(r, items)
-	}
+	return items
 }
 
-func (h *Handler) sendLexicalMenuJSON(r *ghttp.Request, items []LexicalMenuItem) {
-	response := Response{
+func writeMenuJSON(r *ghttp.Request, items []LexicalMenuItem) {
+	r.Response.WriteJson(Response{
 		Code:    20000,
 		Message: "OK",
 		Data: LexicalMenuResponse{
 			Items: items,
 		},
-	}
-	r.Response.WriteJson(response)
-}
-
-func (h *Handler) sendLexicalMenuHTML(r *ghttp.Request, items []LexicalMenuItem) {
-	var sb strings.Builder
-	sb.WriteString("<h1>Lexical Elements</h1>\n<ul>\n")
-
-	for _, item := range items {
-		sb.WriteString(fmt.Sprintf("<li><a href=\"/api/v1/topic/lexical_elements/%s?format=html\">%s</a></li>\n", item.Name, item.Title))
-	}
-
-	sb.WriteString("</ul>\n")
-	sb.WriteString("<a href=\"/api/v1/topics?format=html\" class=\"back-link\">Back to Topics</a>")
-	r.Response.Write(getHtmlPage("Lexical Elements", sb.String()))
+	})
 }
 
-// GetLexicalContent 获取具体章节内容
-func (h *Handler) GetLexicalContent(r *ghttp.Request) {
-	chapterName := r.Get("chapter").String()
-	format := r.GetCtxVar("format").String()
-
-	var contentFunc func() string
-	var title string
-
-	for _, c := range lexicalChapters {
-		if c.ID == chapterName {
-			contentFunc = c.ContentFunc
-			title = c.Title
+// writeChapter 输出 chapterDef 章节正文，章节不存在时返回 404。
+func writeChapter(r *ghttp.Request, chapters []chapterDef, name, notFound, basePath string) {
+	var chapter *chapterDef
+	for i := range chapters {
+		if chapters[i].ID == name {
+			chapter = &chapters[i]
 			break
 		}
 	}
 
-	if contentFunc == nil {
-		r.Response.WriteStatus(404)
-		if format == "html" {
-			r.Response.Write("Chapter not found")
-		} else {
-			r.Response.WriteJson(Response{
-				Code:    404,
-				Message: "Chapter not found",
-			})
+	back := menuBackLink(basePath)
+	rd := contentRenderer(r)
+	if chapter == nil {
+		if rd != nil {
+			writeNotFoundPage(r, rd, notFound, back)
+			return
 		}
+		r.Response.WriteHeader(http.StatusNotFound)
+		r.Response.WriteJson(Response{
+			Code:    404,
+			Message: notFound,
+		})
 		return
 	}
 
-	content := contentFunc()
-
-	if format == "html" {
-		var sb strings.Builder
-		sb.WriteString("<h1>" + title + "</h1>\n")
-		sb.WriteString("<pre>\n" + content + "\n</pre>\n")
-		sb.WriteString("<a href=\"/api/v1/topic/lexical_elements?format=html\" class=\"back-link\">Back to Menu</a>")
-		r.Response.Write(getHtmlPage(title, sb.String()))
-	} else {
-		// JSON 响应：直接返回字符串内容
-		// 也可以包装在对象中
-		r.Response.WriteJson(Response{
-			Code:    20000,
-			Message: "OK",
-			Data: map[string]string{
-				"title":   title,
-				"content": content,
-			},
-		})
+	content := chapter.ContentFunc()
+	if rd != nil {
+		writePage(r, http.StatusOK, rd, chapterPage(chapter.Title, content, back))
+		return
 	}
+	r.Response.WriteJson(Response{
+		Code:    20000,
+		Message: "OK",
+		Data: ChapterContentResponse{
+			Title:   chapter.Title,
+			Content: content,
+		},
+	})
+}
+
+// menuBackLink 章节页面返回所属菜单的链接
+func menuBackLink(basePath string) pageLink {
+	return pageLink{Text: "Back to Menu", Path: basePath}
+}
+
+// chapterPage 词法元素与常量章节正文为预排版文本，整体作为文本块输出。
+func chapterPage(title, content string, back pageLink) *page {
+	return &page{Title: title, Blocks: []block{codeBlock("text", content)}, Back: &back}
 }
@@ -223,13 +223,12 @@ func TestGetLexicalContent_InvalidChapter_HTML(t *testing.T) {
 	})
 }
 
-func TestSendLexicalMenuJSON(t *testing.T) {
+func TestWriteMenuJSON(t *testing.T) {
 	gtest.C(t, func(t *gtest.T) {
-		s := g.Server("test-send-lexical-menu-json")
+		s := g.Server("test-write-menu-json")
 		s.SetPort(0)
 		s.SetAccessLogEnabled(false)
 
-		h := New()
 		var capturedResponse Response
 
 		s.Group("/test", func(group *ghttp.RouterGroup) {
@@ -237,7 +236,7 @@ func TestSendLexicalMenuJSON(t *testing.T) {
 				items := []LexicalMenuItem{
 					{ID: 0, Title: "Test Chapter", Name: "test"},
 				}
-				h.sendLexicalMenuJSON(r, items)
+				writeMenuJSON(r, items)
 			})
 		})
 
@@ -259,20 +258,18 @@ func TestSendLexicalMenuJSON(t *testing.T) {
 	})
 }
 
-func TestSendLexicalMenuHTML(t *testing.T) {
+func TestWritePage_MenuHTML(t *testing.T) {
 	gtest.C(t, func(t *gtest.T) {
-		s := g.Server("test-send-lexical-menu-html")
+		s := g.Server("test-write-page-menu-html")
 		s.SetPort(0)
 		s.SetAccessLogEnabled(false)
 
-		h := New()
-
 		s.Group("/test", func(group *ghttp.RouterGroup) {
 			group.GET("/menu", func(r *ghttp.Request) {
 				items := []LexicalMenuItem{
 					{ID: 0, Title: "Test Chapter", Name: "test"},
 				}
-				h.sendLexicalMenuHTML(r, items)
+				writePage(r, 200, htmlRenderer{}, menuPage("Test Menu", "/api/v1/topic/test", items, topicsBackLink))
 			})
 		})
 
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/pkg/metrics"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// 登录方式，用作 gostudy_logins_total 的 method 标签。
+const (
+	loginMethodPassword = "password"
+	loginMethodMFA      = "mfa"
+	loginMethodOIDC     = "oidc"
+)
+
+var (
+	loginsTotal = metrics.Default.NewCounter("gostudy_logins_total",
+		"登录次数，按方式（password、mfa、oidc）与结果（success、mfa_required、failed、error）区分", "method", "result")
+	quizSubmissions = metrics.Default.NewCounter("gostudy_quiz_submissions_total", "测验提交次数，按主题区分", "topic")
+	quizScores      = metrics.Default.NewHistogram("gostudy_quiz_score_ratio",
+		"测验得分率（答对题数 / 总题数），按主题区分", []float64{0.2, 0.4, 0.6, 0.8, 1}, "topic")
+
+	metricsOnce sync.Once
+)
+
+// recordLogin 按登录结果累加计数：凭据、验证码或外部登录校验不通过记为 failed，其余错误记为 error。
+func recordLogin(method string, result *user.AuthResult, err error) {
+	outcome := "success"
+	switch {
+	case err == nil && result != nil && result.MFAChallenge != nil:
+		outcome = "mfa_required"
+	case errors.Is(err, user.ErrInvalidCredential), errors.Is(err, user.ErrInvalidInput),
+		errors.Is(err, user.ErrMFACodeInvalid), errors.Is(err, user.ErrMFAChallengeInvalid),
+		errors.Is(err, user.ErrExternalLoginState), errors.Is(err, user.ErrExternalNotLinked):
+		outcome = "failed"
+	case err != nil:
+		outcome = "error"
+	}
+	loginsTotal.With(method, outcome).Inc()
+}
+
+// startMetrics 订阅测验提交事件，按主题统计提交次数与得分率，只执行一次。
+func startMetrics() {
+	metricsOnce.Do(func() {
+		eventbus.Default().Subscribe(func(ctx context.Context, event eventbus.Event) {
+			if event.Type != eventbus.TypeQuizSubmitted {
+				return
+			}
+			topic := gconv.String(event.Data["topic"])
+			quizSubmissions.With(topic).Inc()
+			if total := gconv.Float64(event.Data["total"]); total > 0 {
+				quizScores.With(topic).Observe(gconv.Float64(event.Data["score"]) / total)
+			}
+		})
+	})
+}
+
+// GetMetrics 以 Prometheus 文本格式输出指标；配置 metrics.token 时需携带 Authorization: Bearer <token>。
+func (h *Handler) GetMetrics(r *ghttp.Request) {
+	if cfg := config.Default(); cfg != nil && cfg.Metrics.Token != "" {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			writeError(r, http.StatusUnauthorized, 40001, "未提供指标访问令牌")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), []byte(cfg.Metrics.Token)) != 1 {
+			writeError(r, http.StatusUnauthorized, 40002, "指标访问令牌无效")
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.Default.WriteText(&buf); err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "指标输出失败")
+		return
+	}
+	r.Response.Header().Set("Content-Type", metrics.ContentType)
+	r.Response.Header().Set("Cache-Control", "no-store")
+	r.Response.Write(buf.Bytes())
+}
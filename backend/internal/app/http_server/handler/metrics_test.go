@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/pkg/metrics"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestRecordLogin(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		value := func(method, result string) float64 { return loginsTotal.With(method, result).Value() }
+		success, challenged := value(loginMethodPassword, "success"), value(loginMethodPassword, "mfa_required")
+		failed, broken := value(loginMethodMFA, "failed"), value(loginMethodOIDC, "error")
+
+		recordLogin(loginMethodPassword, &user.AuthResult{}, nil)
+		recordLogin(loginMethodPassword, &user.AuthResult{MFAChallenge: &user.MFAChallenge{}}, nil)
+		recordLogin(loginMethodMFA, nil, user.ErrMFACodeInvalid)
+		recordLogin(loginMethodOIDC, nil, errors.New("db down"))
+
+		t.Assert(value(loginMethodPassword, "success"), success+1)
+		t.Assert(value(loginMethodPassword, "mfa_required"), challenged+1)
+		t.Assert(value(loginMethodMFA, "failed"), failed+1)
+		t.Assert(value(loginMethodOIDC, "error"), broken+1)
+	})
+}
+
+func TestQuizMetrics(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		startMetrics()
+		eventbus.Default().Publish(context.Background(), eventbus.Event{
+			Type: eventbus.TypeQuizSubmitted,
+			Data: map[string]interface{}{"topic": "metrics_test", "score": 3, "total": 4},
+		})
+		t.Assert(quizSubmissions.With("metrics_test").Value(), 1)
+		t.Assert(quizScores.With("metrics_test").Count(), 1)
+
+		var b strings.Builder
+		t.AssertNil(metrics.Default.WriteText(&b))
+		t.AssertIN(`gostudy_quiz_score_ratio_bucket{topic="metrics_test",le="0.6"} 0`, b.String())
+		t.AssertIN(`gostudy_quiz_score_ratio_bucket{topic="metrics_test",le="0.8"} 1`, b.String())
+	})
+}
+
+func TestGetMetrics(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		previous := config.Default()
+		defer config.SetDefault(previous)
+		config.SetDefault(&config.Config{Metrics: config.MetricsConfig{Token: "scrape-secret"}})
+
+		s := g.Server("test-get-metrics")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+		s.Group("/", func(group *ghttp.RouterGroup) {
+			group.GET("/metrics", New().GetMetrics)
+		})
+		s.Start()
+		defer s.Shutdown()
+
+		url := fmt.Sprintf("http://127.0.0.1:%d/metrics", s.GetListenedPort())
+		get := func(token string) (*http.Response, string) {
+			client := g.Client()
+			if token != "" {
+				client.SetHeader("Authorization", "Bearer "+token)
+			}
+			resp, err := client.Get(nil, url)
+			t.AssertNil(err)
+			defer resp.Close()
+			return resp.Response, resp.ReadAllString()
+		}
+
+		resp, body := get("")
+		t.Assert(resp.StatusCode, http.StatusUnauthorized)
+		t.AssertIN("40001", body)
+		resp, _ = get("wrong")
+		t.Assert(resp.StatusCode, http.StatusUnauthorized)
+
+		resp, body = get("scrape-secret")
+		t.Assert(resp.StatusCode, http.StatusOK)
+		t.Assert(resp.Header.Get("Content-Type"), metrics.ContentType)
+		t.AssertIN("# TYPE gostudy_logins_total counter", body)
+		t.AssertIN("# TYPE gostudy_db_query_duration_seconds histogram", body)
+	})
+}
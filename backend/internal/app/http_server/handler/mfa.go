@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type mfaChallengeResponse struct {
+	MfaRequired bool   `json:"mfaRequired"`
+	MfaToken    string `json:"mfaToken"`
+	ExpiresAt   string `json:"expiresAt"`
+}
+
+type mfaVerifyRequest struct {
+	MfaToken   string `json:"mfaToken"`
+	Code       string `json:"code"`
+	RememberMe bool   `json:"rememberMe"`
+	Remember   bool   `json:"remember"`
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type mfaSetupResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauthUri"`
+}
+
+type mfaRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type mfaStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+// VerifyMFALogin 使用登录挑战与验证码（TOTP 或恢复码）完成第二步登录。
+func (h *Handler) VerifyMFALogin(r *ghttp.Request) {
+	svc, err := h.ensureUserService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "认证服务不可用")
+		return
+	}
+
+	var req mfaVerifyRequest
+	if err := r.Parse(&req); err != nil || req.MfaToken == "" || req.Code == "" {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+
+	result, err := svc.VerifyMFA(r.GetCtx(), req.MfaToken, req.Code)
+	recordLogin(loginMethodMFA, result, err)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+
+	h.setRefreshCookie(r, svc, result.Tokens.RefreshToken, req.RememberMe || req.Remember)
+	writeSuccess(r, "登录成功", authResponse{
+		AccessToken:        result.Tokens.AccessToken,
+		ExpiresIn:          result.Tokens.AccessExpiresIn,
+		NeedPasswordChange: result.User.MustChangePassword,
+		IsAdmin:            result.User.IsAdmin,
+	})
+}
+
+// GetMFAStatus 返回当前用户的两步验证状态。
+func (h *Handler) GetMFAStatus(r *ghttp.Request) {
+	svc, userID, ok := h.mfaContext(r)
+	if !ok {
+		return
+	}
+	enabled, remaining, err := svc.MFAStatus(r.GetCtx(), userID)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "success", mfaStatusResponse{
+		Enabled:                enabled,
+		RecoveryCodesRemaining: remaining,
+	})
+}
+
+// SetupMFA 生成新的 TOTP 密钥与 otpauth 地址。
+func (h *Handler) SetupMFA(r *ghttp.Request) {
+	svc, userID, ok := h.mfaContext(r)
+	if !ok {
+		return
+	}
+	enrollment, err := svc.BeginMFAEnrollment(r.GetCtx(), userID)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "请使用认证器扫描并输入验证码确认", mfaSetupResponse{
+		Secret:     enrollment.Secret,
+		OtpauthUri: enrollment.URI,
+	})
+}
+
+// ConfirmMFA 使用首个验证码确认绑定，返回仅展示一次的恢复码。
+func (h *Handler) ConfirmMFA(r *ghttp.Request) {
+	svc, userID, ok := h.mfaContext(r)
+	if !ok {
+		return
+	}
+	code, ok := parseMFACode(r)
+	if !ok {
+		return
+	}
+	codes, err := svc.ConfirmMFAEnrollment(r.GetCtx(), userID, code)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "两步验证已启用，请妥善保存恢复码", mfaRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMFA 校验验证码后关闭两步验证。
+func (h *Handler) DisableMFA(r *ghttp.Request) {
+	svc, userID, ok := h.mfaContext(r)
+	if !ok {
+		return
+	}
+	code, ok := parseMFACode(r)
+	if !ok {
+		return
+	}
+	if err := svc.DisableMFA(r.GetCtx(), userID, code); err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "两步验证已关闭", nil)
+}
+
+// RegenerateRecoveryCodes 作废旧恢复码并返回新的一组。
+func (h *Handler) RegenerateRecoveryCodes(r *ghttp.Request) {
+	svc, userID, ok := h.mfaContext(r)
+	if !ok {
+		return
+	}
+	code, ok := parseMFACode(r)
+	if !ok {
+		return
+	}
+	codes, err := svc.RegenerateRecoveryCodes(r.GetCtx(), userID, code)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	writeSuccess(r, "恢复码已重新生成", mfaRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *Handler) mfaContext(r *ghttp.Request) (*user.Service, int64, bool) {
+	svc, err := h.ensureUserService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "认证服务不可用")
+		return nil, 0, false
+	}
+	userID := r.GetCtxVar("user_id").Int64()
+	if userID <= 0 {
+		writeError(r, http.StatusUnauthorized, 40001, "认证信息缺失")
+		return nil, 0, false
+	}
+	return svc, userID, true
+}
+
+func parseMFACode(r *ghttp.Request) (string, bool) {
+	var req mfaCodeRequest
+	if err := r.Parse(&req); err != nil || req.Code == "" {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return "", false
+	}
+	return req.Code, true
+}
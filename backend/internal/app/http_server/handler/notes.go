@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-study2/internal/app/http_server/handler/internal"
+	"go-study2/internal/domain/notes"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type noteRequest struct {
+	Kind    string   `json:"kind"`
+	Topic   string   `json:"topic"`
+	Chapter string   `json:"chapter"`
+	Anchor  string   `json:"anchor"`
+	Title   string   `json:"title"`
+	Body    string   `json:"body"`
+	Tags    []string `json:"tags"`
+}
+
+func (req noteRequest) input() notes.Input {
+	return notes.Input{
+		Kind:    req.Kind,
+		Topic:   req.Topic,
+		Chapter: req.Chapter,
+		Anchor:  req.Anchor,
+		Title:   req.Title,
+		Body:    req.Body,
+		Tags:    req.Tags,
+	}
+}
+
+// ListNotes 查询当前用户的笔记与书签，支持 topic、chapter、anchor、kind、tag、q 与 limit、offset 参数。
+func (h *Handler) ListNotes(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotesUser(r)
+	if !ok {
+		return
+	}
+	items, err := svc.List(r.GetCtx(), userID, notes.Filter{
+		Topic:   r.Get("topic").String(),
+		Chapter: r.Get("chapter").String(),
+		Anchor:  r.Get("anchor").String(),
+		Kind:    r.Get("kind").String(),
+		Tag:     r.Get("tag").String(),
+		Query:   r.Get("q").String(),
+		Limit:   r.Get("limit").Int(),
+		Offset:  r.Get("offset").Int(),
+	})
+	if err != nil {
+		writeNotesError(r, err)
+		return
+	}
+	writeSuccess(r, "success", items)
+}
+
+// CreateNote 创建笔记或书签。
+func (h *Handler) CreateNote(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotesUser(r)
+	if !ok {
+		return
+	}
+	var req noteRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	note, err := svc.Create(r.GetCtx(), userID, req.input())
+	if err != nil {
+		writeNotesError(r, err)
+		return
+	}
+	writeSuccess(r, "笔记已保存", note)
+}
+
+// GetNote 返回当前用户的单条笔记。
+func (h *Handler) GetNote(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotesUser(r)
+	if !ok {
+		return
+	}
+	note, err := svc.Get(r.GetCtx(), userID, r.Get("id").Int64())
+	if err != nil {
+		writeNotesError(r, err)
+		return
+	}
+	writeSuccess(r, "success", note)
+}
+
+// UpdateNote 以完整内容更新笔记。
+func (h *Handler) UpdateNote(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotesUser(r)
+	if !ok {
+		return
+	}
+	var req noteRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	note, err := svc.Update(r.GetCtx(), userID, r.Get("id").Int64(), req.input())
+	if err != nil {
+		writeNotesError(r, err)
+		return
+	}
+	writeSuccess(r, "笔记已更新", note)
+}
+
+// DeleteNote 删除当前用户的笔记。
+func (h *Handler) DeleteNote(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotesUser(r)
+	if !ok {
+		return
+	}
+	if err := svc.Delete(r.GetCtx(), userID, r.Get("id").Int64()); err != nil {
+		writeNotesError(r, err)
+		return
+	}
+	writeSuccess(r, "笔记已删除", nil)
+}
+
+func (h *Handler) getNotesService(r *ghttp.Request) (*notes.Service, bool) {
+	if h.notesService != nil {
+		return h.notesService, true
+	}
+	svc, err := internal.BuildNotesService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "笔记服务不可用")
+		return nil, false
+	}
+	h.notesService = svc
+	return svc, true
+}
+
+func (h *Handler) currentNotesUser(r *ghttp.Request) (*notes.Service, int64, bool) {
+	svc, ok := h.getNotesService(r)
+	if !ok {
+		return nil, 0, false
+	}
+	userID := r.GetCtxVar("user_id").Int64()
+	if userID <= 0 {
+		writeError(r, http.StatusUnauthorized, 40001, "认证信息缺失")
+		return nil, 0, false
+	}
+	return svc, userID, true
+}
+
+func writeNotesError(r *ghttp.Request, err error) {
+	switch err {
+	case notes.ErrInvalidInput:
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+	case notes.ErrNoteNotFound:
+		writeError(r, http.StatusNotFound, 40036, "笔记不存在")
+	case notes.ErrBookmarkExists:
+		writeError(r, http.StatusConflict, 40037, "该位置已添加书签")
+	default:
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
+	}
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-study2/internal/app/http_server/handler/internal"
+	"go-study2/internal/domain/notifications"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// streamHeartbeat 为 SSE 心跳间隔，避免代理因连接空闲而断开。
+var streamHeartbeat = 25 * time.Second
+
+// streamRetryMs 提示浏览器断线后的重连等待时间。
+const streamRetryMs = 3000
+
+type markNotificationsRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+type markNotificationsResponse struct {
+	Updated int `json:"updated"`
+}
+
+type notificationPreferencesRequest struct {
+	Preferences []notifications.Preference `json:"preferences"`
+}
+
+// ListNotifications 返回当前用户的收件箱，支持 unread=true、before（通知 ID）与 limit 参数。
+func (h *Handler) ListNotifications(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotificationUser(r)
+	if !ok {
+		return
+	}
+	inbox, err := svc.List(r.GetCtx(), userID, r.Get("unread").Bool(), r.Get("before").Int64(), r.Get("limit").Int())
+	if err != nil {
+		writeNotificationError(r, err)
+		return
+	}
+	writeSuccess(r, "success", inbox)
+}
+
+// MarkNotificationsRead 将通知标记为已读，ids 为空表示全部。
+func (h *Handler) MarkNotificationsRead(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotificationUser(r)
+	if !ok {
+		return
+	}
+	var req markNotificationsRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	updated, err := svc.MarkRead(r.GetCtx(), userID, req.IDs)
+	if err != nil {
+		writeNotificationError(r, err)
+		return
+	}
+	writeSuccess(r, "通知已标记为已读", markNotificationsResponse{Updated: updated})
+}
+
+// GetNotificationPreferences 返回各类通知的接收开关。
+func (h *Handler) GetNotificationPreferences(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotificationUser(r)
+	if !ok {
+		return
+	}
+	prefs, err := svc.Preferences(r.GetCtx(), userID)
+	if err != nil {
+		writeNotificationError(r, err)
+		return
+	}
+	writeSuccess(r, "success", prefs)
+}
+
+// UpdateNotificationPreferences 更新指定类型的接收开关。
+func (h *Handler) UpdateNotificationPreferences(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotificationUser(r)
+	if !ok {
+		return
+	}
+	var req notificationPreferencesRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	prefs, err := svc.UpdatePreferences(r.GetCtx(), userID, req.Preferences)
+	if err != nil {
+		writeNotificationError(r, err)
+		return
+	}
+	writeSuccess(r, "通知偏好已更新", prefs)
+}
+
+// StreamNotifications 以 Server-Sent Events 推送新通知，事件 ID 为通知 ID；
+// 携带 Last-Event-ID 请求头（或 lastEventId 参数）重连时先补发错过的通知。
+func (h *Handler) StreamNotifications(r *ghttp.Request) {
+	svc, userID, ok := h.currentNotificationUser(r)
+	if !ok {
+		return
+	}
+	lastID := r.Get("lastEventId").Int64()
+	if header := strings.TrimSpace(r.Header.Get("Last-Event-ID")); header != "" {
+		parsed, err := strconv.ParseInt(header, 10, 64)
+		if err != nil {
+			writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+			return
+		}
+		lastID = parsed
+	}
+	backlog, live, cancel, err := svc.Subscribe(r.GetCtx(), userID, lastID)
+	if err != nil {
+		writeNotificationError(r, err)
+		return
+	}
+	defer cancel()
+
+	resp := r.Response
+	resp.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.Header().Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(fmt.Sprintf("retry: %d\n\n", streamRetryMs))
+
+	sent := lastID
+	for _, n := range backlog {
+		writeNotificationEvent(resp, n)
+		sent = n.ID
+	}
+	resp.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	done := r.Context().Done()
+	for {
+		select {
+		case <-done:
+			return
+		case n, open := <-live:
+			if !open {
+				// 客户端消费过慢被移出订阅，结束连接让其携带 Last-Event-ID 重连补齐
+				return
+			}
+			if n.ID <= sent {
+				continue
+			}
+			writeNotificationEvent(resp, n)
+			sent = n.ID
+			resp.Flush()
+		case <-heartbeat.C:
+			resp.Write(": ping\n\n")
+			resp.Flush()
+		}
+	}
+}
+
+func writeNotificationEvent(resp *ghttp.Response, n notifications.Notification) {
+	payload, _ := json.Marshal(n)
+	resp.Write(fmt.Sprintf("id: %d\nevent: notification\ndata: %s\n\n", n.ID, payload))
+}
+
+func (h *Handler) getNotificationService(r *ghttp.Request) (*notifications.Service, bool) {
+	if h.notifyService != nil {
+		return h.notifyService, true
+	}
+	svc, err := internal.BuildNotificationService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "通知服务不可用")
+		return nil, false
+	}
+	h.notifyService = svc
+	return svc, true
+}
+
+func (h *Handler) currentNotificationUser(r *ghttp.Request) (*notifications.Service, int64, bool) {
+	svc, ok := h.getNotificationService(r)
+	if !ok {
+		return nil, 0, false
+	}
+	userID := r.GetCtxVar("user_id").Int64()
+	if userID <= 0 {
+		writeError(r, http.StatusUnauthorized, 40001, "认证信息缺失")
+		return nil, 0, false
+	}
+	return svc, userID, true
+}
+
+func writeNotificationError(r *ghttp.Request, err error) {
+	switch err {
+	case notifications.ErrInvalidInput:
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+	default:
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
+	}
+}
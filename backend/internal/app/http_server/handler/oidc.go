@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"go-study2/internal/app/http_server/handler/internal"
+	"go-study2/internal/domain/user"
+	"go-study2/internal/pkg/oidc"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type oidcProviderItem struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	LoginUrl    string `json:"loginUrl"`
+}
+
+// ListOIDCProviders 返回已配置的外部登录提供方，供登录页展示按钮。
+func (h *Handler) ListOIDCProviders(r *ghttp.Request) {
+	providers := h.ensureOIDCProviders()
+	items := make([]oidcProviderItem, 0, len(providers))
+	for name, p := range providers {
+		display := p.Config.DisplayName
+		if display == "" {
+			display = name
+		}
+		items = append(items, oidcProviderItem{
+			Name:        name,
+			DisplayName: display,
+			LoginUrl:    "/api/v1/auth/oidc/" + name + "/login",
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	writeSuccess(r, "success", items)
+}
+
+// OIDCLogin 生成 state、nonce 与 PKCE code_verifier 后重定向到提供方授权页。
+func (h *Handler) OIDCLogin(r *ghttp.Request) {
+	provider, ok := h.oidcProvider(r)
+	if !ok {
+		return
+	}
+	svc, err := h.ensureUserService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "认证服务不可用")
+		return
+	}
+
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	nonce, err := oidc.NewNonce()
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	state, err := svc.BeginExternalLogin(r.GetCtx(), provider.Config.Name, nonce, verifier)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	authURL, err := provider.Client.AuthCodeURL(r.GetCtx(), state, nonce, verifier)
+	if err != nil {
+		g.Log().Warning(r.GetCtx(), err)
+		writeError(r, http.StatusBadGateway, 50002, "外部登录提供方不可用")
+		return
+	}
+	r.Response.RedirectTo(authURL, http.StatusFound)
+}
+
+// OIDCCallback 校验 state、用授权码与 code_verifier 换取 ID Token，并签发本系统令牌。
+func (h *Handler) OIDCCallback(r *ghttp.Request) {
+	provider, ok := h.oidcProvider(r)
+	if !ok {
+		return
+	}
+	svc, err := h.ensureUserService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "认证服务不可用")
+		return
+	}
+
+	if errCode := r.Get("error").String(); errCode != "" {
+		g.Log().Infof(r.GetCtx(), "OIDC 提供方 %s 返回错误: %s", provider.Config.Name, errCode)
+		writeError(r, http.StatusUnauthorized, 40019, "外部登录失败")
+		return
+	}
+	code := r.Get("code").String()
+	if code == "" {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+
+	state, err := svc.ResumeExternalLogin(r.GetCtx(), provider.Config.Name, r.Get("state").String())
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+	token, err := provider.Client.Exchange(r.GetCtx(), code, state.CodeVerifier)
+	if err != nil {
+		g.Log().Warning(r.GetCtx(), err)
+		writeError(r, http.StatusUnauthorized, 40019, "外部登录失败")
+		return
+	}
+	claims, err := provider.Client.VerifyIDToken(r.GetCtx(), token.IDToken, state.Nonce)
+	if err != nil {
+		g.Log().Warning(r.GetCtx(), err)
+		writeError(r, http.StatusUnauthorized, 40019, "外部登录失败")
+		return
+	}
+
+	result, err := svc.LoginWithExternal(r.GetCtx(), user.ExternalProfile{
+		Provider:      provider.Config.Name,
+		Subject:       claims.Subject(),
+		Username:      claims.String(provider.Config.UsernameClaim),
+		Email:         claims.String("email"),
+		IsAdmin:       claims.HasAny(provider.Config.RoleClaim, provider.Config.AdminValues),
+		AutoProvision: provider.Config.AutoProvision,
+	})
+	recordLogin(loginMethodOIDC, result, err)
+	if err != nil {
+		writeAuthError(r, err)
+		return
+	}
+
+	redirect := provider.Config.PostLoginRedirect
+	if result.MFAChallenge != nil {
+		if redirect != "" {
+			r.Response.RedirectTo(redirect+"#mfaToken="+url.QueryEscape(result.MFAChallenge.Token), http.StatusFound)
+			return
+		}
+		writeSuccess(r, "需要两步验证", mfaChallengeResponse{
+			MfaRequired: true,
+			MfaToken:    result.MFAChallenge.Token,
+			ExpiresAt:   result.MFAChallenge.ExpiresAt.Format(time.RFC3339),
+		})
+		return
+	}
+
+	h.setRefreshCookie(r, svc, result.Tokens.RefreshToken, false)
+	if redirect != "" {
+		// 前端落地后调用 /auth/refresh 用 Cookie 换取访问令牌，避免令牌出现在地址栏
+		r.Response.RedirectTo(redirect, http.StatusFound)
+		return
+	}
+	writeSuccess(r, "登录成功", authResponse{
+		AccessToken:        result.Tokens.AccessToken,
+		ExpiresIn:          result.Tokens.AccessExpiresIn,
+		NeedPasswordChange: result.User.MustChangePassword,
+		NeedMfaSetup:       result.MFASetupRequired,
+		IsAdmin:            result.User.IsAdmin,
+	})
+}
+
+func (h *Handler) oidcProvider(r *ghttp.Request) (*internal.OIDCProvider, bool) {
+	provider, ok := h.ensureOIDCProviders()[r.Get("provider").String()]
+	if !ok {
+		writeError(r, http.StatusNotFound, 40004, "未知的登录提供方")
+		return nil, false
+	}
+	return provider, true
+}
+
+func (h *Handler) ensureOIDCProviders() map[string]*internal.OIDCProvider {
+	h.oidcOnce.Do(func() {
+		h.oidcProviders = internal.BuildOIDCProviders()
+	})
+	return h.oidcProviders
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	_ "embed"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go-study2/internal/pkg/openapi"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// apiPrefix 为接口表中相对路径的前缀。
+const apiPrefix = "/api/v1"
+
+// apiErrorCodes 为响应 code 字段可能出现的错误码。学习内容接口沿用 HTTP 风格的 400/404/500，
+// 其余接口使用五位业务码；新增错误码时需同步登记，否则契约测试会失败。
+var apiErrorCodes = []struct {
+	code int
+	desc string
+}{
+	{400, "学习内容接口：参数无效"},
+	{404, "学习内容接口：内容不存在"},
+	{500, "学习内容接口：内容加载失败"},
+	{40001, "未认证、用户不存在或用户名密码错误"},
+	{40002, "令牌无效或已过期"},
+	{40004, "请求参数无效"},
+	{40009, "用户名已存在"},
+	{40010, "需要管理员权限"},
+	{40011, "需要先修改密码"},
+	{40012, "需要先启用两步验证"},
+	{40013, "两步验证码无效"},
+	{40014, "两步验证已过期"},
+	{40015, "两步验证未启用"},
+	{40016, "两步验证已启用"},
+	{40017, "邀请码无效、已过期或已用尽"},
+	{40018, "未开放注册"},
+	{40019, "外部登录失败或已过期"},
+	{40020, "外部账号未关联本地用户"},
+	{40021, "访问令牌权限不足"},
+	{40022, "访问令牌不存在"},
+	{40023, "密码不符合安全策略"},
+	{40024, "班级不存在"},
+	{40025, "无权执行该班级操作"},
+	{40026, "班级加入码无效"},
+	{40027, "用户已在班级中"},
+	{40028, "班级至少需要保留一名教师"},
+	{40029, "用户不在该班级"},
+	{40030, "作业不存在"},
+	{40031, "需要教师或管理员权限"},
+	{40032, "题目不存在"},
+	{40033, "题目未通过校验"},
+	{40034, "班级排行榜已关闭"},
+	{40035, "昵称已被占用"},
+	{40036, "笔记不存在"},
+	{40037, "该位置已添加书签"},
+	{40038, "讨论主题不存在"},
+	{40039, "帖子不存在"},
+	{40040, "无权操作讨论"},
+	{40041, "Webhook 接收地址不存在"},
+	{40042, "Webhook 投递记录不存在"},
+	{40043, "定时任务不存在"},
+	{40044, "任务正在执行"},
+	{40045, "请求过于频繁，已被限流"},
+	{50001, "服务器内部错误或服务不可用"},
+	{50002, "外部登录提供方不可用"},
+}
+
+const errorSchemaName = "Error"
+
+// pageMediaTypes 学习内容接口渲染页面时的内容类型
+var pageMediaTypes = []string{"text/html", "text/markdown", "text/plain"}
+
+//go:embed openapi_docs.html
+var apiDocsPage string
+
+var openAPIDocument = sync.OnceValue(buildOpenAPIDocument)
+
+// OpenAPIDocument 返回根据接口表与请求、响应结构体生成的 OpenAPI 文档，首次调用后缓存。
+func OpenAPIDocument() *openapi.Document {
+	return openAPIDocument()
+}
+
+// GetOpenAPI 返回 OpenAPI 文档。
+func (h *Handler) GetOpenAPI(r *ghttp.Request) {
+	r.Response.WriteJson(OpenAPIDocument())
+}
+
+// GetAPIDocs 返回内置的接口文档页面，页面读取 /api/v1/openapi.json 渲染，不依赖外部资源。
+func (h *Handler) GetAPIDocs(r *ghttp.Request) {
+	r.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	r.Response.Write(apiDocsPage)
+}
+
+func buildOpenAPIDocument() *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{
+		Title:   "Go Study API",
+		Version: "v1",
+		Description: "除 JWKS、文档与事件流外，响应统一为 {code, message, data}：成功时 code 为 20000，" +
+			"失败时 code 为业务错误码（见 Error 结构），HTTP 状态码与错误类型对应。" +
+			"启用限流时响应附带 RateLimit-Limit、RateLimit-Remaining 与 RateLimit-Reset 头，超出后返回 429 与 Retry-After。",
+	})
+	doc.Components.SecuritySchemes["bearerAuth"] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "登录返回的访问令牌，或以 gst_ 开头的个人访问令牌",
+	}
+	gen := openapi.NewGenerator(doc.Components.Schemas)
+	doc.Components.Schemas[errorSchemaName] = errorSchema()
+
+	seenTags := make(map[string]bool)
+	for _, op := range apiOperations {
+		if !seenTags[op.tag] {
+			seenTags[op.tag] = true
+			doc.Tags = append(doc.Tags, openapi.Tag{Name: op.tag})
+		}
+		doc.AddOperation(op.method, op.fullPath(), op.build(doc, gen))
+	}
+	return doc
+}
+
+// rootPaths 为挂载在根路径下、不带 /api/v1 前缀的运维接口。
+var rootPaths = map[string]bool{"/metrics": true, "/healthz": true, "/readyz": true}
+
+func (op apiOperation) fullPath() string {
+	if strings.HasPrefix(op.path, "/.well-known/") || rootPaths[op.path] {
+		return op.path
+	}
+	return apiPrefix + op.path
+}
+
+func (op apiOperation) build(doc *openapi.Document, gen *openapi.Generator) *openapi.Operation {
+	out := &openapi.Operation{
+		OperationID: op.id,
+		Summary:     op.summary,
+		Tags:        []string{op.tag},
+		Responses:   make(map[string]*openapi.Response),
+	}
+	switch op.access {
+	case accessUser:
+		out.Security = bearerSecurity()
+	case accessTeacher:
+		out.Security = bearerSecurity()
+		out.Description = "需要教师或管理员权限。"
+	case accessAdmin:
+		out.Security = bearerSecurity()
+		out.Description = "需要管理员权限。"
+	}
+	out.Parameters = op.parameters()
+	if op.body != nil {
+		out.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]openapi.MediaType{"application/json": {Schema: requestSchema(doc, gen, op.body)}},
+		}
+	}
+
+	success := openapi.MediaType{Schema: op.successSchema(gen)}
+	if op.raw && op.data == nil {
+		success = openapi.MediaType{}
+	}
+	ok := &openapi.Response{Description: "成功", Content: map[string]openapi.MediaType{}}
+	if success.Schema != nil {
+		ok.Content["application/json"] = success
+	}
+	for _, mediaType := range op.produces {
+		ok.Content[mediaType] = openapi.MediaType{}
+	}
+	failure := &openapi.Response{
+		Description: "错误",
+		Content:     map[string]openapi.MediaType{"application/json": {Schema: openapi.Ref(errorSchemaName)}},
+	}
+	if op.content {
+		// 学习内容接口的部分错误以 HTTP 200 返回，html、markdown、text 格式返回渲染后的页面
+		ok.Content["application/json"] = openapi.MediaType{Schema: &openapi.Schema{
+			AnyOf: []*openapi.Schema{success.Schema, openapi.Ref(errorSchemaName)},
+		}}
+		for _, mediaType := range pageMediaTypes {
+			ok.Content[mediaType] = openapi.MediaType{}
+			failure.Content[mediaType] = openapi.MediaType{}
+		}
+	}
+	out.Responses["200"] = ok
+	if op.cached {
+		out.Responses["304"] = &openapi.Response{Description: "内容未变化（If-None-Match 命中）"}
+	}
+	if op.redirect {
+		out.Responses["302"] = &openapi.Response{Description: "重定向"}
+	}
+	if op.unavailable {
+		out.Responses["503"] = &openapi.Response{Description: "未就绪", Content: map[string]openapi.MediaType{"application/json": success}}
+	}
+	out.Responses["default"] = failure
+	return out
+}
+
+func (op apiOperation) parameters() []openapi.Parameter {
+	var params []openapi.Parameter
+	for _, segment := range strings.Split(op.path, "/") {
+		if !strings.HasPrefix(segment, "{") {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		typ := "string"
+		if name == "id" || strings.HasSuffix(name, "Id") {
+			typ = "integer"
+		}
+		params = append(params, openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: openapi.TypeSet{typ}}})
+	}
+	if op.content {
+		params = append(params, openapi.Parameter{
+			Name: "format", In: "query", Description: "响应格式，未提供时按 Accept 头协商",
+			Schema: &openapi.Schema{Type: openapi.TypeSet{"string"}, Enum: []interface{}{"json", "html", "markdown", "text"}},
+		})
+	}
+	if op.cached {
+		params = append(params, openapi.Parameter{
+			Name: "If-None-Match", In: "header", Description: "上次响应的 ETag，内容未变化时返回 304",
+			Schema: &openapi.Schema{Type: openapi.TypeSet{"string"}},
+		})
+	}
+	for _, p := range op.query {
+		params = append(params, openapi.Parameter{Name: p.name, In: "query", Description: p.desc, Schema: &openapi.Schema{Type: openapi.TypeSet{p.typ}}})
+	}
+	return params
+}
+
+// successSchema 返回成功响应的结构：raw 接口直接为 data 的结构，其余为 code 固定 20000 的统一包装。
+func (op apiOperation) successSchema(gen *openapi.Generator) *openapi.Schema {
+	var data *openapi.Schema
+	switch v := op.data.(type) {
+	case nil:
+	case apiAlternatives:
+		data = &openapi.Schema{}
+		for _, alt := range v {
+			data.AnyOf = append(data.AnyOf, gen.Schema(alt))
+		}
+	default:
+		data = gen.Schema(v)
+	}
+	if op.raw {
+		return data
+	}
+	envelope := &openapi.Schema{
+		Type: openapi.TypeSet{"object"},
+		Properties: map[string]*openapi.Schema{
+			"code":    {Type: openapi.TypeSet{"integer"}, Const: 20000},
+			"message": {Type: openapi.TypeSet{"string"}},
+		},
+		Required:             []string{"code", "message"},
+		AdditionalProperties: false,
+	}
+	if data != nil {
+		envelope.Properties["data"] = data
+		envelope.Required = append(envelope.Required, "data")
+	}
+	return envelope
+}
+
+// requestSchema 生成请求体结构；处理器自行校验必填项，因此顶层请求结构不标记必填字段。
+func requestSchema(doc *openapi.Document, gen *openapi.Generator, body interface{}) *openapi.Schema {
+	s := gen.Schema(body)
+	if reflect.TypeOf(body).PkgPath() == reflect.TypeOf(apiOperation{}).PkgPath() {
+		if component, ok := doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]; ok {
+			component.Required = nil
+		}
+	}
+	return s
+}
+
+func bearerSecurity() []map[string][]string {
+	return []map[string][]string{{"bearerAuth": {}}}
+}
+
+func errorSchema() *openapi.Schema {
+	codes := make([]interface{}, 0, len(apiErrorCodes))
+	lines := make([]string, 0, len(apiErrorCodes))
+	for _, c := range apiErrorCodes {
+		codes = append(codes, c.code)
+		lines = append(lines, fmt.Sprintf("- `%d`：%s", c.code, c.desc))
+	}
+	return &openapi.Schema{
+		Type:        openapi.TypeSet{"object"},
+		Description: "错误响应",
+		Properties: map[string]*openapi.Schema{
+			"code":      {Type: openapi.TypeSet{"integer"}, Enum: codes, Description: "错误码：\n" + strings.Join(lines, "\n")},
+			"message":   {Type: openapi.TypeSet{"string"}},
+			"data":      {Description: "错误明细，如密码策略违规项或题目校验问题"},
+			"requestId": {Type: openapi.TypeSet{"string"}, Description: "请求 ID，与响应头 X-Request-ID 一致"},
+		},
+		Required:             []string{"code", "message"},
+		AdditionalProperties: false,
+	}
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"go-study2/internal/domain/classroom"
+	"go-study2/internal/domain/discussion"
+	"go-study2/internal/domain/jobs"
+	"go-study2/internal/domain/leaderboard"
+	"go-study2/internal/domain/notes"
+	"go-study2/internal/domain/notifications"
+	"go-study2/internal/domain/quiz"
+	"go-study2/internal/domain/user"
+	"go-study2/internal/domain/webhooks"
+	"go-study2/internal/infrastructure/audit"
+	appjwt "go-study2/internal/pkg/jwt"
+	"go-study2/src/learning/types"
+)
+
+// apiAccess 表示接口的访问要求。
+type apiAccess int
+
+const (
+	accessPublic apiAccess = iota
+	accessUser
+	accessTeacher
+	accessAdmin
+)
+
+// apiParam 描述查询参数；路径参数由路径模板自动生成。
+type apiParam struct {
+	name string
+	typ  string
+	desc string
+}
+
+// apiAlternatives 表示 data 可能是其中任一结构。
+type apiAlternatives []interface{}
+
+// apiOperation 描述一个接口，与 router.go 中的路由一一对应，用于生成 OpenAPI 文档。
+type apiOperation struct {
+	id      string
+	method  string
+	path    string // 相对 /api/v1 的路径模板，/.well-known 下的路径与 rootPaths 中的运维接口除外
+	tag     string
+	summary string
+	access  apiAccess
+	query   []apiParam
+	body    interface{} // 请求体结构的零值
+	data    interface{} // 成功响应 data 的零值，nil 表示响应不含 data
+	// content 为学习内容接口：支持 format=json|html|markdown|text，部分错误以 HTTP 200 返回 code 400/404/500
+	content bool
+	// cached 为按路径与格式缓存的公开内容：响应带 ETag 与 Cache-Control，If-None-Match 命中时返回 304
+	cached bool
+	// produces 为成功时可能返回的其他内容类型，如导出文件与事件流
+	produces []string
+	redirect bool
+	// raw 表示响应不使用统一的 {code,message,data} 包装
+	raw bool
+	// unavailable 表示不可用时以 503 返回与成功相同的结构，如就绪检查
+	unavailable bool
+}
+
+var (
+	limitParam   = apiParam{"limit", "integer", "返回条数上限"}
+	topicParam   = apiParam{"topic", "string", "主题"}
+	chapterParam = apiParam{"chapter", "string", "章节"}
+)
+
+var apiOperations = []apiOperation{
+	{id: "GetMetrics", method: "GET", path: "/metrics", tag: "运维", summary: "Prometheus 指标（配置 metrics.token 时需携带 Authorization: Bearer <token>）", produces: []string{"text/plain"}, raw: true},
+	{id: "GetHealthz", method: "GET", path: "/healthz", tag: "运维", summary: "存活检查", data: HealthReport{}, raw: true},
+	{id: "GetReadyz", method: "GET", path: "/readyz", tag: "运维", summary: "就绪检查（数据库、迁移版本、学习内容、HTTPS 证书），关闭摘流期间返回 503", data: HealthReport{}, raw: true, unavailable: true},
+	{id: "GetJWKS", method: "GET", path: "/.well-known/jwks.json", tag: "文档", summary: "令牌验签公钥（JWKS）", data: appjwt.JWKSet{}, raw: true},
+	{id: "GetOpenAPI", method: "GET", path: "/openapi.json", tag: "文档", summary: "OpenAPI 3.1 接口文档", data: map[string]interface{}{}, raw: true},
+	{id: "GetAPIDocs", method: "GET", path: "/docs", tag: "文档", summary: "接口文档页面", produces: []string{"text/html"}, raw: true},
+
+	// 认证
+	{id: "Login", method: "POST", path: "/auth/login", tag: "认证", summary: "用户名密码登录，启用两步验证时返回挑战令牌", body: authRequest{}, data: apiAlternatives{authResponse{}, mfaChallengeResponse{}}},
+	{id: "RefreshToken", method: "POST", path: "/auth/refresh", tag: "认证", summary: "使用 HttpOnly 刷新令牌 Cookie 换取新访问令牌", data: authResponse{}},
+	{id: "VerifyMFALogin", method: "POST", path: "/auth/login/mfa", tag: "认证", summary: "提交两步验证码或恢复码完成登录", body: mfaVerifyRequest{}, data: authResponse{}},
+	{id: "Signup", method: "POST", path: "/auth/signup", tag: "认证", summary: "自助注册（按配置需要邀请码）", body: signupRequest{}, data: authResponse{}},
+	{id: "Register", method: "POST", path: "/auth/register", tag: "认证", summary: "管理员创建用户", access: accessAdmin, body: authRequest{}, data: authResponse{}},
+	{id: "GetProfile", method: "GET", path: "/auth/profile", tag: "认证", summary: "当前用户信息", access: accessUser, data: profileResponse{}},
+	{id: "Logout", method: "POST", path: "/auth/logout", tag: "认证", summary: "退出并吊销刷新令牌", access: accessUser},
+	{id: "ChangePassword", method: "POST", path: "/auth/change-password", tag: "认证", summary: "修改密码，成功后需重新登录", access: accessUser, body: changePasswordRequest{}},
+	{id: "ExportMyData", method: "GET", path: "/auth/export", tag: "认证", summary: "导出个人数据", access: accessUser, data: userDataExport{}},
+
+	// 外部登录
+	{id: "ListOIDCProviders", method: "GET", path: "/auth/oidc/providers", tag: "外部登录", summary: "已配置的外部身份提供方", data: []oidcProviderItem{}},
+	{id: "OIDCLogin", method: "GET", path: "/auth/oidc/{provider}/login", tag: "外部登录", summary: "重定向到提供方授权页", redirect: true},
+	{id: "OIDCCallback", method: "GET", path: "/auth/oidc/{provider}/callback", tag: "外部登录", summary: "授权回调，配置了落地页时重定向", query: []apiParam{{"code", "string", "授权码"}, {"state", "string", "登录状态"}, {"error", "string", "提供方返回的错误"}}, data: apiAlternatives{authResponse{}, mfaChallengeResponse{}}, redirect: true},
+
+	// 两步验证
+	{id: "GetMFAStatus", method: "GET", path: "/auth/mfa", tag: "两步验证", summary: "两步验证状态", access: accessUser, data: mfaStatusResponse{}},
+	{id: "SetupMFA", method: "POST", path: "/auth/mfa/setup", tag: "两步验证", summary: "生成 TOTP 密钥", access: accessUser, data: mfaSetupResponse{}},
+	{id: "ConfirmMFA", method: "POST", path: "/auth/mfa/confirm", tag: "两步验证", summary: "确认绑定并返回恢复码", access: accessUser, body: mfaCodeRequest{}, data: mfaRecoveryCodesResponse{}},
+	{id: "DisableMFA", method: "POST", path: "/auth/mfa/disable", tag: "两步验证", summary: "关闭两步验证", access: accessUser, body: mfaCodeRequest{}},
+	{id: "RegenerateRecoveryCodes", method: "POST", path: "/auth/mfa/recovery-codes", tag: "两步验证", summary: "重新生成恢复码", access: accessUser, body: mfaCodeRequest{}, data: mfaRecoveryCodesResponse{}},
+
+	// 访问令牌
+	{id: "ListAccessTokens", method: "GET", path: "/auth/tokens", tag: "访问令牌", summary: "个人访问令牌列表", access: accessUser, data: []user.AccessToken{}},
+	{id: "CreateAccessToken", method: "POST", path: "/auth/tokens", tag: "访问令牌", summary: "创建访问令牌，明文仅返回一次", access: accessUser, body: createAccessTokenRequest{}, data: createAccessTokenResponse{}},
+	{id: "RevokeAccessToken", method: "DELETE", path: "/auth/tokens/{id}", tag: "访问令牌", summary: "撤销访问令牌", access: accessUser},
+
+	// 用户管理
+	{id: "CreateInvite", method: "POST", path: "/admin/invites", tag: "用户管理", summary: "创建邀请码，明文仅返回一次", access: accessAdmin, body: createInviteRequest{}, data: createInviteResponse{}},
+	{id: "ListInvites", method: "GET", path: "/admin/invites", tag: "用户管理", summary: "邀请码列表", access: accessAdmin, data: []user.Invite{}},
+	{id: "RevokeInvite", method: "DELETE", path: "/admin/invites/{id}", tag: "用户管理", summary: "撤销邀请码", access: accessAdmin},
+	{id: "ListInviteRedemptions", method: "GET", path: "/admin/invites/{id}/redemptions", tag: "用户管理", summary: "邀请码使用记录", access: accessAdmin, data: []user.InviteRedemption{}},
+	{id: "SetTeacherRole", method: "PUT", path: "/admin/users/{id}/teacher", tag: "用户管理", summary: "授予或撤销教师角色", access: accessAdmin, body: setTeacherRequest{}, data: user.User{}},
+
+	// 审计日志
+	{id: "ListAuditEvents", method: "GET", path: "/admin/audit", tag: "审计日志", summary: "查询审计事件，export=csv|jsonl 时导出文件", access: accessAdmin,
+		query: []apiParam{{"eventType", "string", "事件类型"}, {"userId", "integer", "用户 ID"}, {"result", "string", "结果"}, {"from", "string", "起始时间（RFC 3339 或 YYYY-MM-DD）"}, {"to", "string", "截止时间"}, {"cursor", "string", "分页游标"}, limitParam, {"export", "string", "导出格式 csv|jsonl"}},
+		data:  audit.Page{}, produces: []string{"text/csv", "application/x-ndjson"}},
+	{id: "VerifyAuditChain", method: "GET", path: "/admin/audit/verify", tag: "审计日志", summary: "校验审计哈希链", access: accessAdmin, data: audit.VerifyReport{}},
+
+	// Webhook
+	{id: "ListWebhooks", method: "GET", path: "/admin/webhooks", tag: "Webhook", summary: "接收地址列表", access: accessAdmin, data: []webhooks.Endpoint{}},
+	{id: "CreateWebhook", method: "POST", path: "/admin/webhooks", tag: "Webhook", summary: "创建接收地址，签名密钥仅返回一次", access: accessAdmin, body: webhookEndpointRequest{}, data: webhooks.Endpoint{}},
+	{id: "GetWebhook", method: "GET", path: "/admin/webhooks/{id}", tag: "Webhook", summary: "接收地址详情", access: accessAdmin, data: webhooks.Endpoint{}},
+	{id: "UpdateWebhook", method: "PUT", path: "/admin/webhooks/{id}", tag: "Webhook", summary: "更新接收地址", access: accessAdmin, body: webhookEndpointRequest{}, data: webhooks.Endpoint{}},
+	{id: "DeleteWebhook", method: "DELETE", path: "/admin/webhooks/{id}", tag: "Webhook", summary: "删除接收地址", access: accessAdmin},
+	{id: "SendWebhookTest", method: "POST", path: "/admin/webhooks/{id}/test", tag: "Webhook", summary: "发送测试事件", access: accessAdmin, data: webhooks.Delivery{}},
+	{id: "ListWebhookDeliveries", method: "GET", path: "/admin/webhooks/{id}/deliveries", tag: "Webhook", summary: "投递记录", access: accessAdmin, query: []apiParam{{"status", "string", "pending|delivered|dead"}, limitParam}, data: []webhooks.Delivery{}},
+	{id: "RetryWebhookDelivery", method: "POST", path: "/admin/webhooks/deliveries/{deliveryId}/retry", tag: "Webhook", summary: "立即重投", access: accessAdmin, data: webhooks.Delivery{}},
+
+	// 定时任务
+	{id: "ListJobs", method: "GET", path: "/admin/jobs", tag: "定时任务", summary: "任务列表与最近一次执行", access: accessAdmin, data: []jobs.Job{}},
+	{id: "ListJobRuns", method: "GET", path: "/admin/jobs/{name}/runs", tag: "定时任务", summary: "执行历史", access: accessAdmin, query: []apiParam{limitParam}, data: []jobs.Run{}},
+	{id: "SetJobPaused", method: "PUT", path: "/admin/jobs/{name}/paused", tag: "定时任务", summary: "暂停或恢复计划执行", access: accessAdmin, body: jobPausedRequest{}, data: jobs.Job{}},
+	{id: "TriggerJob", method: "POST", path: "/admin/jobs/{name}/run", tag: "定时任务", summary: "立即在后台执行一次", access: accessAdmin, data: jobs.Run{}},
+
+	// 题库
+	{id: "ListBankQuestions", method: "GET", path: "/admin/questions", tag: "题库", summary: "题目列表", access: accessTeacher, query: []apiParam{topicParam, chapterParam, {"status", "string", "draft|published"}, {"tag", "string", "标签"}}, data: []quiz.BankQuestion{}},
+	{id: "CreateBankQuestion", method: "POST", path: "/admin/questions", tag: "题库", summary: "创建题目", access: accessTeacher, body: bankQuestionRequest{}, data: quiz.BankQuestion{}},
+	{id: "GetBankQuestion", method: "GET", path: "/admin/questions/{id}", tag: "题库", summary: "题目详情", access: accessTeacher, data: quiz.BankQuestion{}},
+	{id: "UpdateBankQuestion", method: "PUT", path: "/admin/questions/{id}", tag: "题库", summary: "更新题目", access: accessTeacher, body: bankQuestionRequest{}, data: quiz.BankQuestion{}},
+	{id: "DeleteBankQuestion", method: "DELETE", path: "/admin/questions/{id}", tag: "题库", summary: "删除题目", access: accessTeacher},
+	{id: "ListBankQuestionRevisions", method: "GET", path: "/admin/questions/{id}/revisions", tag: "题库", summary: "题目修订历史", access: accessTeacher, data: []quiz.BankRevision{}},
+
+	// 班级
+	{id: "ListClasses", method: "GET", path: "/classes", tag: "班级", summary: "我的班级，管理员可用 all=true 查看全部", access: accessUser, query: []apiParam{{"all", "boolean", "查看全部班级（管理员）"}}, data: []classroom.Class{}},
+	{id: "CreateClass", method: "POST", path: "/classes", tag: "班级", summary: "创建班级", access: accessUser, body: createClassRequest{}, data: classroom.Class{}},
+	{id: "JoinClass", method: "POST", path: "/classes/join", tag: "班级", summary: "使用加入码加入班级", access: accessUser, body: joinClassRequest{}, data: classroom.Class{}},
+	{id: "GetClass", method: "GET", path: "/classes/{id}", tag: "班级", summary: "班级详情与成员", access: accessUser, data: classroom.ClassDetail{}},
+	{id: "DeleteClass", method: "DELETE", path: "/classes/{id}", tag: "班级", summary: "删除班级", access: accessUser},
+	{id: "RegenerateClassJoinCode", method: "POST", path: "/classes/{id}/join-code", tag: "班级", summary: "重新生成加入码", access: accessUser, data: classroom.Class{}},
+	{id: "AddClassMember", method: "POST", path: "/classes/{id}/members", tag: "班级", summary: "添加成员", access: accessUser, body: addClassMemberRequest{}, data: classroom.Member{}},
+	{id: "RemoveClassMember", method: "DELETE", path: "/classes/{id}/members/{userId}", tag: "班级", summary: "移除成员", access: accessUser},
+	{id: "GetClassDashboard", method: "GET", path: "/classes/{id}/dashboard", tag: "班级", summary: "班级看板", access: accessUser, data: classroom.Dashboard{}},
+	{id: "GetClassStudentReport", method: "GET", path: "/classes/{id}/students/{userId}", tag: "班级", summary: "学生学习报告", access: accessUser, data: classroom.StudentStats{}},
+	{id: "SetClassLeaderboard", method: "PUT", path: "/classes/{id}/leaderboard", tag: "班级", summary: "开启或关闭班级排行榜", access: accessUser, body: classLeaderboardRequest{}, data: classroom.Class{}},
+
+	// 作业
+	{id: "ListClassAssignments", method: "GET", path: "/classes/{id}/assignments", tag: "作业", summary: "班级作业列表", access: accessUser, data: []classroom.Assignment{}},
+	{id: "CreateAssignment", method: "POST", path: "/classes/{id}/assignments", tag: "作业", summary: "布置作业", access: accessUser, body: createAssignmentRequest{}, data: classroom.Assignment{}},
+	{id: "DeleteAssignment", method: "DELETE", path: "/classes/{id}/assignments/{assignmentId}", tag: "作业", summary: "删除作业", access: accessUser},
+	{id: "GetAssignmentReport", method: "GET", path: "/classes/{id}/assignments/{assignmentId}/report", tag: "作业", summary: "作业完成报告，export=csv 时导出成绩表", access: accessUser, query: []apiParam{{"export", "string", "导出格式 csv"}}, data: classroom.AssignmentReport{}, produces: []string{"text/csv"}},
+	{id: "ListMyAssignments", method: "GET", path: "/assignments", tag: "作业", summary: "我的作业", access: accessUser, data: classroom.StudentAssignments{}},
+
+	// 排行榜
+	{id: "GetLeaderboard", method: "GET", path: "/leaderboards", tag: "排行榜", summary: "排行榜",
+		access: accessUser, query: []apiParam{{"metric", "string", "points|mastered|chapter"}, {"scope", "string", "global|class"}, {"classId", "integer", "班级 ID"}, {"window", "string", "all|week"}, topicParam, chapterParam, limitParam}, data: leaderboard.Board{}},
+	{id: "GetLeaderboardPreferences", method: "GET", path: "/leaderboards/preferences", tag: "排行榜", summary: "排行榜偏好", access: accessUser, data: leaderboard.Preferences{}},
+	{id: "UpdateLeaderboardPreferences", method: "PUT", path: "/leaderboards/preferences", tag: "排行榜", summary: "更新排行榜偏好", access: accessUser, body: leaderboardPreferencesRequest{}, data: leaderboard.Preferences{}},
+
+	// 学习进度
+	{id: "GetAllProgress", method: "GET", path: "/progress", tag: "学习进度", summary: "全部学习进度", access: accessUser, data: []progressResponse{}},
+	{id: "GetTopicProgress", method: "GET", path: "/progress/{topic}", tag: "学习进度", summary: "指定主题的学习进度", access: accessUser, data: []progressResponse{}},
+	{id: "SaveProgress", method: "POST", path: "/progress", tag: "学习进度", summary: "保存章节进度", access: accessUser, body: progressRequest{}, data: progressResponse{}},
+
+	// 笔记
+	{id: "ListNotes", method: "GET", path: "/notes", tag: "笔记", summary: "笔记与书签列表", access: accessUser,
+		query: []apiParam{topicParam, chapterParam, {"anchor", "string", "定位锚点"}, {"kind", "string", "note|bookmark"}, {"tag", "string", "标签"}, {"q", "string", "全文检索"}, limitParam, {"offset", "integer", "偏移量"}}, data: []notes.Note{}},
+	{id: "CreateNote", method: "POST", path: "/notes", tag: "笔记", summary: "新建笔记或书签", access: accessUser, body: noteRequest{}, data: notes.Note{}},
+	{id: "GetNote", method: "GET", path: "/notes/{id}", tag: "笔记", summary: "笔记详情", access: accessUser, data: notes.Note{}},
+	{id: "UpdateNote", method: "PUT", path: "/notes/{id}", tag: "笔记", summary: "更新笔记", access: accessUser, body: noteRequest{}, data: notes.Note{}},
+	{id: "DeleteNote", method: "DELETE", path: "/notes/{id}", tag: "笔记", summary: "删除笔记", access: accessUser},
+
+	// 讨论区
+	{id: "ListDiscussions", method: "GET", path: "/discussions", tag: "讨论区", summary: "讨论主题列表", access: accessUser,
+		query: []apiParam{topicParam, chapterParam, {"questionId", "string", "题目 ID"}, {"page", "integer", "页码"}, {"pageSize", "integer", "每页条数"}, {"includeHidden", "boolean", "包含已隐藏内容（教师）"}}, data: discussion.ThreadPage{}},
+	{id: "CreateDiscussion", method: "POST", path: "/discussions", tag: "讨论区", summary: "发布讨论", access: accessUser, body: createThreadRequest{}, data: discussion.ThreadDetail{}},
+	{id: "GetDiscussion", method: "GET", path: "/discussions/{id}", tag: "讨论区", summary: "讨论详情与回复", access: accessUser, data: discussion.ThreadDetail{}},
+	{id: "ReplyDiscussion", method: "POST", path: "/discussions/{id}/posts", tag: "讨论区", summary: "回复讨论", access: accessUser, body: replyRequest{}, data: discussion.Post{}},
+	{id: "AcceptDiscussionAnswer", method: "PUT", path: "/discussions/{id}/accepted", tag: "讨论区", summary: "采纳回答", access: accessUser, body: acceptRequest{}, data: discussion.Thread{}},
+	{id: "SetDiscussionPostHidden", method: "PUT", path: "/discussions/posts/{postId}/hidden", tag: "讨论区", summary: "隐藏或恢复帖子（教师）", access: accessUser, body: hidePostRequest{}, data: discussion.Post{}},
+	{id: "ListMentions", method: "GET", path: "/mentions", tag: "讨论区", summary: "提及我的帖子", access: accessUser, query: []apiParam{{"unread", "boolean", "仅未读"}}, data: []discussion.Mention{}},
+	{id: "MarkMentionsRead", method: "POST", path: "/mentions/read", tag: "讨论区", summary: "标记提及为已读", access: accessUser, body: markMentionsRequest{}},
+
+	// 通知
+	{id: "ListNotifications", method: "GET", path: "/notifications", tag: "通知", summary: "通知收件箱", access: accessUser, query: []apiParam{{"unread", "boolean", "仅未读"}, {"before", "integer", "返回该 ID 之前的通知"}, limitParam}, data: notifications.Inbox{}},
+	{id: "MarkNotificationsRead", method: "POST", path: "/notifications/read", tag: "通知", summary: "标记通知为已读，ids 为空时全部标记", access: accessUser, body: markNotificationsRequest{}, data: markNotificationsResponse{}},
+	{id: "GetNotificationPreferences", method: "GET", path: "/notifications/preferences", tag: "通知", summary: "通知偏好", access: accessUser, data: []notifications.Preference{}},
+	{id: "UpdateNotificationPreferences", method: "PUT", path: "/notifications/preferences", tag: "通知", summary: "更新通知偏好", access: accessUser, body: notificationPreferencesRequest{}, data: []notifications.Preference{}},
+	{id: "StreamNotifications", method: "GET", path: "/notifications/stream", tag: "通知", summary: "通知实时推送（Server-Sent Events），可用 access_token 参数携带令牌", access: accessUser,
+		query: []apiParam{{"lastEventId", "integer", "从该 ID 之后补发"}, {"access_token", "string", "访问令牌（EventSource 无法设置请求头）"}}, produces: []string{"text/event-stream"}, raw: true},
+
+	// 测验
+	{id: "GetQuiz", method: "GET", path: "/quiz/{topic}/{chapter}", tag: "测验", summary: "章节测验题目", access: accessUser, data: []quiz.Question{}},
+	{id: "SubmitQuiz", method: "POST", path: "/quiz/submit", tag: "测验", summary: "提交测验并评分", access: accessUser, body: quizSubmitRequest{}, data: quiz.Result{}},
+	{id: "GetQuizHistory", method: "GET", path: "/quiz/history", tag: "测验", summary: "测验历史", access: accessUser, query: []apiParam{{"from", "string", "起始时间（RFC 3339）"}, {"to", "string", "截止时间（RFC 3339）"}}, data: []quiz.HistoryItem{}},
+	{id: "GetQuizHistoryByTopic", method: "GET", path: "/quiz/history/{topic}", tag: "测验", summary: "指定主题的测验历史", access: accessUser, query: []apiParam{{"from", "string", "起始时间（RFC 3339）"}, {"to", "string", "截止时间（RFC 3339）"}}, data: []quiz.HistoryItem{}},
+
+	// 学习内容
+	{id: "GetTopics", method: "GET", path: "/topics", tag: "学习内容", summary: "主题列表", data: TopicListResponse{}, content: true, cached: true},
+	{id: "GetLexicalMenu", method: "GET", path: "/topic/lexical_elements", tag: "学习内容", summary: "词法元素菜单", data: LexicalMenuResponse{}, content: true, cached: true},
+	{id: "GetLexicalContent", method: "GET", path: "/topic/lexical_elements/{chapter}", tag: "学习内容", summary: "词法元素章节内容", data: ChapterContentResponse{}, content: true, cached: true},
+	{id: "GetConstantsMenu", method: "GET", path: "/topic/constants", tag: "学习内容", summary: "常量菜单", data: LexicalMenuResponse{}, content: true, cached: true},
+	{id: "GetConstantsContent", method: "GET", path: "/topic/constants/{subtopic}", tag: "学习内容", summary: "常量子主题内容", data: ChapterContentResponse{}, content: true, cached: true},
+	{id: "GetVariablesMenu", method: "GET", path: "/topic/variables", tag: "学习内容", summary: "Variables 菜单", data: LexicalMenuResponse{}, content: true, cached: true},
+	{id: "GetVariableContent", method: "GET", path: "/topic/variables/{subtopic}", tag: "学习内容", summary: "Variables 子主题内容与测验", data: variablesContentResponse{}, content: true, cached: true},
+	{id: "GetTypesMenu", method: "GET", path: "/topic/types", tag: "学习内容", summary: "Types 菜单", data: LexicalMenuResponse{}, content: true, cached: true},
+	{id: "GetTypesContent", method: "GET", path: "/topic/types/{subtopic}", tag: "学习内容", summary: "Types 子主题内容与测验", data: typesContentResponse{}, content: true, cached: true},
+	{id: "GetTypesOutline", method: "GET", path: "/topic/types/outline", tag: "学习内容", summary: "Types 提纲", data: typesOutlineResponse{}, content: true, cached: true},
+	{id: "SearchTypes", method: "GET", path: "/topic/types/search", tag: "学习内容", summary: "Types 关键词检索", query: []apiParam{{"keyword", "string", "关键词"}}, data: typesSearchResponse{}, content: true},
+	{id: "SubmitTypesQuiz", method: "POST", path: "/topic/types/quiz/submit", tag: "学习内容", summary: "Types 综合测验评分", body: typesQuizSubmitRequest{}, data: types.QuizResult{}, content: true},
+	{id: "ExportBook", method: "GET", path: "/export/book", tag: "学习内容", summary: "导出课程电子书（EPUB 或单文件 HTML）",
+		query: []apiParam{{"format", "string", "导出格式 epub（默认）或 html"}, {"quiz", "boolean", "附带各章测验与答案附录"}}, produces: []string{"application/epub+zip", "text/html"}, raw: true},
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go-study2/internal/domain/quiz"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type bankQuestionRequest struct {
+	QuestionID  string   `json:"questionId"`
+	Topic       string   `json:"topic"`
+	Chapter     string   `json:"chapter"`
+	Stem        string   `json:"stem"`
+	Options     []string `json:"options"`
+	Answer      string   `json:"answer"`
+	Explanation string   `json:"explanation"`
+	Tags        []string `json:"tags"`
+	Difficulty  string   `json:"difficulty"`
+	RuleRef     string   `json:"ruleRef"`
+	Status      string   `json:"status"`
+}
+
+func (req bankQuestionRequest) input() quiz.BankQuestionInput {
+	return quiz.BankQuestionInput{
+		QuestionID:  req.QuestionID,
+		Topic:       req.Topic,
+		Chapter:     req.Chapter,
+		Stem:        req.Stem,
+		Options:     req.Options,
+		Answer:      req.Answer,
+		Explanation: req.Explanation,
+		Tags:        req.Tags,
+		Difficulty:  req.Difficulty,
+		RuleRef:     req.RuleRef,
+		Status:      req.Status,
+	}
+}
+
+type questionValidationResponse struct {
+	Problems []quiz.ValidationProblem `json:"problems"`
+}
+
+// ListBankQuestions 按 topic、chapter、status、tag 过滤题库（教师与管理员）。
+func (h *Handler) ListBankQuestions(r *ghttp.Request) {
+	svc, ok := h.getQuizService(r)
+	if !ok {
+		return
+	}
+	items, err := svc.ListBankQuestions(r.GetCtx(), quiz.BankFilter{
+		Topic:   r.Get("topic").String(),
+		Chapter: r.Get("chapter").String(),
+		Status:  r.Get("status").String(),
+		Tag:     r.Get("tag").String(),
+	})
+	if err != nil {
+		writeQuestionBankError(r, err)
+		return
+	}
+	writeSuccess(r, "success", items)
+}
+
+// CreateBankQuestion 新建题目，默认保存为草稿。
+func (h *Handler) CreateBankQuestion(r *ghttp.Request) {
+	svc, ok := h.getQuizService(r)
+	if !ok {
+		return
+	}
+	var req bankQuestionRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	question, err := svc.CreateBankQuestion(r.GetCtx(), r.GetCtxVar("user_id").Int64(), req.input())
+	if err != nil {
+		writeQuestionBankError(r, err)
+		return
+	}
+	writeSuccess(r, "题目已创建", question)
+}
+
+// GetBankQuestion 返回单个题目。
+func (h *Handler) GetBankQuestion(r *ghttp.Request) {
+	svc, ok := h.getQuizService(r)
+	if !ok {
+		return
+	}
+	question, err := svc.GetBankQuestion(r.GetCtx(), r.Get("id").Int64())
+	if err != nil {
+		writeQuestionBankError(r, err)
+		return
+	}
+	writeSuccess(r, "success", question)
+}
+
+// UpdateBankQuestion 以完整内容更新题目并生成新修订，status 设为 published 即发布。
+func (h *Handler) UpdateBankQuestion(r *ghttp.Request) {
+	svc, ok := h.getQuizService(r)
+	if !ok {
+		return
+	}
+	var req bankQuestionRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	question, err := svc.UpdateBankQuestion(r.GetCtx(), r.GetCtxVar("user_id").Int64(), r.Get("id").Int64(), req.input())
+	if err != nil {
+		writeQuestionBankError(r, err)
+		return
+	}
+	writeSuccess(r, "题目已更新", question)
+}
+
+// DeleteBankQuestion 删除题目及其修订历史。
+func (h *Handler) DeleteBankQuestion(r *ghttp.Request) {
+	svc, ok := h.getQuizService(r)
+	if !ok {
+		return
+	}
+	if err := svc.DeleteBankQuestion(r.GetCtx(), r.GetCtxVar("user_id").Int64(), r.Get("id").Int64()); err != nil {
+		writeQuestionBankError(r, err)
+		return
+	}
+	writeSuccess(r, "题目已删除", nil)
+}
+
+// ListBankQuestionRevisions 返回题目的修订历史。
+func (h *Handler) ListBankQuestionRevisions(r *ghttp.Request) {
+	svc, ok := h.getQuizService(r)
+	if !ok {
+		return
+	}
+	revisions, err := svc.BankRevisions(r.GetCtx(), r.Get("id").Int64())
+	if err != nil {
+		writeQuestionBankError(r, err)
+		return
+	}
+	writeSuccess(r, "success", revisions)
+}
+
+func writeQuestionBankError(r *ghttp.Request, err error) {
+	var validationErr *quiz.ValidationError
+	if errors.As(err, &validationErr) {
+		writeErrorData(r, http.StatusBadRequest, 40033, "题目未通过校验", questionValidationResponse{Problems: validationErr.Problems})
+		return
+	}
+
+	switch err {
+	case quiz.ErrInvalidInput:
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+	case quiz.ErrQuestionNotFound:
+		writeError(r, http.StatusNotFound, 40032, "题目不存在")
+	default:
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
+	}
+}
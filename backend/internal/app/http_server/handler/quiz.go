@@ -63,14 +63,7 @@ func (h *Handler) SubmitQuiz(r *ghttp.Request) {
 		return
 	}
 
-	writeSuccess(r, "提交成功", map[string]interface{}{
-		"score":       result.Score,
-		"total":       result.Total,
-		"correctIds":  result.CorrectIDs,
-		"wrongIds":    result.WrongIDs,
-		"submittedAt": result.SubmittedAt.Format(time.RFC3339),
-		"durationMs":  result.DurationMs,
-	})
+	writeSuccess(r, "提交成功", result)
 }
 
 // GetQuizHistory 返回当前用户的测验历史。
@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// page 为与输出格式无关的学习内容页面：处理器只负责组装内容，HTML、Markdown 与纯文本由 renderer 输出。
+type page struct {
+	Title  string
+	Blocks []block
+	Back   *pageLink
+}
+
+// pageLink 站内链接，Path 不带 format 参数，由 renderer 按当前格式补全。
+type pageLink struct {
+	Text string
+	Path string
+}
+
+type blockKind int
+
+const (
+	blockHeading blockKind = iota
+	blockParagraph
+	blockList
+	blockOrderedList
+	blockCode
+)
+
+// block 页面中的一个内容块。
+type block struct {
+	Kind  blockKind
+	Level int    // 标题级别，页面标题占用 1 级，内容标题从 2 开始
+	Text  string // 标题、段落或代码文本
+	Lang  string // 代码语言，用于 Markdown 围栏
+	Items []listItem
+}
+
+// listItem 列表项：Link 非空时主文本为链接，Sub 为嵌套的选项，Notes 为逐行显示的补充说明。
+type listItem struct {
+	Text  string
+	Link  string
+	Sub   []string
+	Notes []string
+}
+
+func headingBlock(level int, text string) block {
+	return block{Kind: blockHeading, Level: level, Text: text}
+}
+
+func paragraphBlock(text string) block {
+	return block{Kind: blockParagraph, Text: text}
+}
+
+func listBlock(items ...listItem) block {
+	return block{Kind: blockList, Items: items}
+}
+
+func orderedListBlock(items ...listItem) block {
+	return block{Kind: blockOrderedList, Items: items}
+}
+
+func codeBlock(lang, text string) block {
+	return block{Kind: blockCode, Lang: lang, Text: text}
+}
+
+// textItems 把字符串转换为无链接的列表项。
+func textItems(lines []string) []listItem {
+	items := make([]listItem, len(lines))
+	for i, line := range lines {
+		items[i] = listItem{Text: line}
+	}
+	return items
+}
+
+// menuPage 生成章节菜单页面，链接指向 basePath 下的各个章节。
+func menuPage(title, basePath string, items []LexicalMenuItem, back pageLink) *page {
+	links := make([]listItem, len(items))
+	for i, item := range items {
+		links[i] = listItem{Text: item.Title, Link: basePath + "/" + item.Name}
+	}
+	return &page{Title: title, Blocks: []block{listBlock(links...)}, Back: &back}
+}
+
+// optionLabel 返回选项序号对应的字母标签，如 A)、B)。
+func optionLabel(i int, option string) string {
+	return fmt.Sprintf("%c) %s", 'A'+i, option)
+}
+
+// renderer 把 page 渲染为一种文本格式。
+type renderer interface {
+	// ContentType 返回响应的 Content-Type。
+	ContentType() string
+	// Render 渲染完整页面。
+	Render(p *page) string
+}
+
+// renderers 为 format 参数到渲染器的映射，json 由处理器直接输出结构化数据。
+var renderers = map[string]renderer{
+	"html":     htmlRenderer{},
+	"markdown": markdownRenderer{},
+	"text":     textRenderer{},
+}
+
+// contentRenderer 返回当前请求协商出的渲染器，JSON 格式返回 nil。
+func contentRenderer(r *ghttp.Request) renderer {
+	return renderers[r.GetCtxVar("format").String()]
+}
+
+// writePage 以指定状态码输出渲染后的页面。
+func writePage(r *ghttp.Request, status int, rd renderer, p *page) {
+	r.Response.Header().Set("Content-Type", rd.ContentType())
+	r.Response.WriteHeader(status)
+	r.Response.Write(rd.Render(p))
+}
+
+// writeNotFoundPage 以 404 输出内容不存在页面。
+func writeNotFoundPage(r *ghttp.Request, rd renderer, msg string, back pageLink) {
+	writePage(r, http.StatusNotFound, rd, &page{Title: "Not Found", Blocks: []block{paragraphBlock(msg)}, Back: &back})
+}
+
+// formatHref 为站内路径补全 format 参数。
+func formatHref(path, format string) string {
+	return path + "?format=" + format
+}
+
+// htmlRenderer 沿用 getHtmlPage 的页面模板与样式。
+type htmlRenderer struct{}
+
+var htmlText = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func (htmlRenderer) ContentType() string { return "text/html; charset=utf-8" }
+
+func (htmlRenderer) Render(p *page) string {
+	return htmlPage(p, func(path string) string { return formatHref(path, "html") })
+}
+
+// htmlPage 以 getHtmlPage 模板输出完整页面，href 把站内路径转换为链接地址。
+func htmlPage(p *page, href func(path string) string) string {
+	return getHtmlPage(htmlText.Replace(p.Title), htmlBody(p, 1, href))
+}
+
+// htmlBody 渲染页面正文（含页面标题与返回链接，不含页面模板），输出同时是格式良好的 XHTML。
+// level 为页面标题的标题级别，href 把站内路径转换为链接地址。
+func htmlBody(p *page, level int, href func(path string) string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<h%d>%s</h%d>\n", level, htmlText.Replace(p.Title), level)
+	for _, b := range p.Blocks {
+		switch b.Kind {
+		case blockHeading:
+			h := min(6, level+b.Level)
+			fmt.Fprintf(&sb, "<h%d>%s</h%d>\n", h, htmlText.Replace(b.Text), h)
+		case blockParagraph:
+			sb.WriteString("<p>" + htmlText.Replace(b.Text) + "</p>\n")
+		case blockCode:
+			sb.WriteString("<pre>\n" + htmlText.Replace(b.Text) + "\n</pre>\n")
+		case blockList, blockOrderedList:
+			tag := "ul"
+			if b.Kind == blockOrderedList {
+				tag = "ol"
+			}
+			sb.WriteString("<" + tag + ">\n")
+			for _, item := range b.Items {
+				sb.WriteString("<li>")
+				if item.Link == "" {
+					sb.WriteString(htmlText.Replace(item.Text))
+				} else {
+					fmt.Fprintf(&sb, "<a href=\"%s\">%s</a>", html.EscapeString(href(item.Link)), htmlText.Replace(item.Text))
+				}
+				if len(item.Sub) > 0 {
+					sb.WriteString("<ul>")
+					for _, sub := range item.Sub {
+						sb.WriteString("<li>" + htmlText.Replace(sub) + "</li>")
+					}
+					sb.WriteString("</ul>")
+				}
+				for _, note := range item.Notes {
+					sb.WriteString("<br/><span class=\"note\">" + htmlText.Replace(note) + "</span>")
+				}
+				sb.WriteString("</li>\n")
+			}
+			sb.WriteString("</" + tag + ">\n")
+		}
+	}
+	if p.Back != nil {
+		fmt.Fprintf(&sb, "<a href=\"%s\" class=\"back-link\">%s</a>", html.EscapeString(href(p.Back.Path)), htmlText.Replace(p.Back.Text))
+	}
+	return sb.String()
+}
+
+// markdownRenderer 输出 CommonMark，代码块使用围栏并标注语言。
+type markdownRenderer struct{}
+
+func (markdownRenderer) ContentType() string { return "text/markdown; charset=utf-8" }
+
+func (markdownRenderer) Render(p *page) string {
+	var sb strings.Builder
+	sb.WriteString("# " + p.Title + "\n")
+	for _, b := range p.Blocks {
+		sb.WriteString("\n")
+		switch b.Kind {
+		case blockHeading:
+			sb.WriteString(strings.Repeat("#", b.Level) + " " + b.Text + "\n")
+		case blockParagraph:
+			sb.WriteString(b.Text + "\n")
+		case blockCode:
+			fence := codeFence(b.Text)
+			sb.WriteString(fence + b.Lang + "\n" + strings.TrimRight(b.Text, "\n") + "\n" + fence + "\n")
+		case blockList, blockOrderedList:
+			for i, item := range b.Items {
+				marker := "- "
+				if b.Kind == blockOrderedList {
+					marker = fmt.Sprintf("%d. ", i+1)
+				}
+				indent := strings.Repeat(" ", len(marker))
+				text := item.Text
+				if item.Link != "" {
+					text = fmt.Sprintf("[%s](%s)", text, formatHref(item.Link, "markdown"))
+				}
+				sb.WriteString(marker + text + "\n")
+				for _, sub := range item.Sub {
+					sb.WriteString(indent + "- " + sub + "\n")
+				}
+				for _, note := range item.Notes {
+					sb.WriteString("\n" + indent + note + "\n")
+				}
+			}
+		}
+	}
+	if p.Back != nil {
+		fmt.Fprintf(&sb, "\n[%s](%s)\n", p.Back.Text, formatHref(p.Back.Path, "markdown"))
+	}
+	return sb.String()
+}
+
+// codeFence 返回比代码中最长反引号串更长的围栏，避免代码提前结束代码块。
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for _, c := range code {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+			continue
+		}
+		run = 0
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// textRenderer 输出适合终端阅读的纯文本：标题加下划线，代码缩进四格，链接以括号附上地址。
+type textRenderer struct{}
+
+func (textRenderer) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (textRenderer) Render(p *page) string {
+	var sb strings.Builder
+	sb.WriteString(p.Title + "\n" + strings.Repeat("=", displayWidth(p.Title)) + "\n")
+	for _, b := range p.Blocks {
+		sb.WriteString("\n")
+		switch b.Kind {
+		case blockHeading:
+			sb.WriteString(b.Text + "\n")
+			if b.Level <= 2 {
+				sb.WriteString(strings.Repeat("-", displayWidth(b.Text)) + "\n")
+			}
+		case blockParagraph:
+			sb.WriteString(b.Text + "\n")
+		case blockCode:
+			for _, line := range strings.Split(strings.TrimRight(b.Text, "\n"), "\n") {
+				sb.WriteString(strings.TrimRight("    "+line, " ") + "\n")
+			}
+		case blockList, blockOrderedList:
+			for i, item := range b.Items {
+				marker := "- "
+				if b.Kind == blockOrderedList {
+					marker = fmt.Sprintf("%d. ", i+1)
+				}
+				indent := strings.Repeat(" ", len(marker))
+				text := item.Text
+				if item.Link != "" {
+					text += " (" + formatHref(item.Link, "text") + ")"
+				}
+				sb.WriteString(marker + text + "\n")
+				for _, sub := range item.Sub {
+					sb.WriteString(indent + sub + "\n")
+				}
+				for _, note := range item.Notes {
+					sb.WriteString(indent + note + "\n")
+				}
+			}
+		}
+	}
+	if p.Back != nil {
+		fmt.Fprintf(&sb, "\n%s: %s\n", p.Back.Text, formatHref(p.Back.Path, "text"))
+	}
+	return sb.String()
+}
+
+// displayWidth 估算终端显示宽度，中日韩等宽字符按两列计算。
+func displayWidth(s string) int {
+	width := 0
+	for _, c := range s {
+		if c >= 0x1100 && utf8.RuneLen(c) >= 3 {
+			width += 2
+			continue
+		}
+		width++
+	}
+	return width
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"fmt"
+	"testing"
+
+	"go-study2/internal/app/http_server/middleware"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func samplePage() *page {
+	return &page{
+		Title: "通道 <chan>",
+		Blocks: []block{
+			paragraphBlock("a < b && c"),
+			headingBlock(2, "示例"),
+			codeBlock("go", "ch := make(chan int)\n// ```\n<-ch"),
+			orderedListBlock(quizListItem("哪个正确？", []string{"x", "y"}, "B", "解析")),
+			listBlock(listItem{Text: "下一节", Link: "/api/v1/topic/types/map"}),
+		},
+		Back: &pageLink{Text: "返回", Path: "/api/v1/topic/types"},
+	}
+}
+
+func TestRenderers(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		html := htmlRenderer{}.Render(samplePage())
+		t.AssertIN("<!DOCTYPE html>", html)
+		t.AssertIN("<h1>通道 &lt;chan&gt;</h1>", html)
+		t.AssertIN("<p>a &lt; b &amp;&amp; c</p>", html)
+		t.AssertIN("&lt;-ch", html)
+		t.AssertIN("<ol>\n<li>哪个正确？<ul><li>A) x</li><li>B) y</li></ul><br/><span class=\"note\">答案: B</span>", html)
+		t.AssertIN(`<a href="/api/v1/topic/types/map?format=html">下一节</a>`, html)
+		t.AssertIN(`<a href="/api/v1/topic/types?format=html" class="back-link">返回</a>`, html)
+
+		md := markdownRenderer{}.Render(samplePage())
+		t.AssertIN("# 通道 <chan>\n", md)
+		t.AssertIN("## 示例\n", md)
+		// 代码中含三个反引号时围栏需要更长
+		t.AssertIN("````go\nch := make(chan int)\n// ```\n<-ch\n````\n", md)
+		t.AssertIN("1. 哪个正确？\n   - A) x\n   - B) y\n\n   答案: B\n", md)
+		t.AssertIN("- [下一节](/api/v1/topic/types/map?format=markdown)\n", md)
+		t.AssertIN("[返回](/api/v1/topic/types?format=markdown)\n", md)
+
+		text := textRenderer{}.Render(samplePage())
+		t.AssertIN("通道 <chan>\n===========\n", text)
+		t.AssertIN("示例\n----\n", text)
+		t.AssertIN("    ch := make(chan int)\n", text)
+		t.AssertIN("1. 哪个正确？\n   A) x\n   B) y\n   答案: B\n   解析\n", text)
+		t.AssertIN("- 下一节 (/api/v1/topic/types/map?format=text)\n", text)
+		t.AssertIN("返回: /api/v1/topic/types?format=text\n", text)
+	})
+}
+
+func TestContentFormats_Negotiated(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		s := g.Server("test-content-formats")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+
+		s.Group("/api/v1", func(group *ghttp.RouterGroup) {
+			h := New()
+			group.Middleware(middleware.Format)
+			group.ALL("/topic/lexical_elements/:chapter", h.GetLexicalContent)
+			group.ALL("/topic/types/:subtopic", h.GetTypesContent)
+		})
+
+		s.Start()
+		defer s.Shutdown()
+
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+
+		resp, err := client.Get(nil, "/api/v1/topic/lexical_elements/comments?format=markdown")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		t.AssertIN("text/markdown", resp.Header.Get("Content-Type"))
+		body := resp.ReadAllString()
+		t.AssertIN("# Comments (注释)\n", body)
+		t.AssertIN("```text\n", body)
+		resp.Close()
+
+		resp, err = client.Header(map[string]string{"Accept": "text/plain"}).Get(nil, "/api/v1/topic/types/slice")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		t.AssertIN("text/plain", resp.Header.Get("Content-Type"))
+		t.AssertIN("测验\n----\n", resp.ReadAllString())
+		resp.Close()
+
+		resp, err = client.Get(nil, "/api/v1/topic/lexical_elements/missing?format=text")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 404)
+		t.AssertIN("Chapter not found", resp.ReadAllString())
+		resp.Close()
+	})
+}
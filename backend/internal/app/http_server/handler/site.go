@@ -0,0 +1,369 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go-study2/src/learning/types"
+	"go-study2/src/learning/variables"
+)
+
+const (
+	siteManifestFile = ".site-manifest.json"
+	siteSearchFile   = "search.html"
+	siteIndexFile    = "search-index.json"
+	siteMapFile      = "sitemap.xml"
+)
+
+// SiteOptions 静态站点生成选项。
+type SiteOptions struct {
+	BaseURL string    // 站点根地址，用于 sitemap.xml 中的绝对地址；为空时写入相对路径
+	Force   bool      // 忽略上次构建记录，重新渲染全部页面
+	Now     time.Time // 页面更新日期，零值时取当前时间
+}
+
+// SiteReport 汇总一次构建的结果。
+type SiteReport struct {
+	Rendered  int // 新增或内容有变化而重新渲染的页面
+	Unchanged int // 内容哈希未变而跳过的页面
+	Removed   int // 已不再生成而被删除的页面
+}
+
+// sitePage 静态站点中的一个页面：Link 为对应的 API 路径，站内链接按它改写为相对地址。
+type sitePage struct {
+	Link    string
+	File    string
+	Section string // 所属主题，写入搜索索引
+	Page    *page
+}
+
+// siteManifest 记录上次构建时各页面的内容哈希与更新日期，用于增量构建。
+// Template 为页面模板的哈希，模板变化时全部页面重新渲染。
+type siteManifest struct {
+	Template string                       `json:"template"`
+	Pages    map[string]siteManifestEntry `json:"pages"`
+}
+
+type siteManifestEntry struct {
+	Hash    string `json:"hash"`
+	Updated string `json:"updated"`
+}
+
+// siteSearchEntry 客户端搜索索引中的一条记录，URL 相对于站点根目录。
+type siteSearchEntry struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Section string `json:"section"`
+	Text    string `json:"text"`
+}
+
+// BuildSite 把全部学习内容渲染为静态站点写入 dir：页面与 format=html 的输出使用同一模板，
+// 另生成客户端搜索页、搜索索引与 sitemap。内容哈希未变化的页面不会重新渲染。
+func BuildSite(dir string, opts SiteOptions) (SiteReport, error) {
+	var report SiteReport
+	pages, err := sitePages()
+	if err != nil {
+		return report, err
+	}
+	files := map[string]string{siteSearchFile: siteSearchFile}
+	for _, sp := range pages {
+		files[sp.Link] = sp.File
+	}
+	// 先校验全部链接，避免未重新渲染的页面指向已不存在的页面
+	for _, sp := range pages {
+		for _, link := range pageLinks(sp.Page) {
+			if _, ok := files[link]; !ok {
+				return report, fmt.Errorf("页面 %s 的链接 %s 没有对应的静态页面", sp.Link, link)
+			}
+		}
+	}
+
+	previous, err := readSiteManifest(dir)
+	if err != nil {
+		return report, err
+	}
+	template := contentHash([]byte(getHtmlPage("", "")))
+	reusable := previous.Pages
+	if opts.Force || previous.Template != template {
+		reusable = nil
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	next := siteManifest{Template: template, Pages: make(map[string]siteManifestEntry, len(pages))}
+
+	for _, sp := range pages {
+		data, err := json.Marshal(sp.Page)
+		if err != nil {
+			return report, err
+		}
+		hash := contentHash(data)
+		target := filepath.Join(dir, filepath.FromSlash(sp.File))
+		if entry, ok := reusable[sp.File]; ok && entry.Hash == hash && fileExists(target) {
+			next.Pages[sp.File] = entry
+			report.Unchanged++
+			continue
+		}
+		href := func(link string) string { return relativeHref(sp.File, files[link]) }
+		if err := writeSiteFile(target, []byte(htmlPage(sp.Page, href))); err != nil {
+			return report, err
+		}
+		next.Pages[sp.File] = siteManifestEntry{Hash: hash, Updated: now.UTC().Format("2006-01-02")}
+		report.Rendered++
+	}
+
+	for file := range previous.Pages {
+		if _, ok := next.Pages[file]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(file))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return report, err
+		}
+		report.Removed++
+	}
+
+	index, err := siteSearchIndex(pages)
+	if err != nil {
+		return report, err
+	}
+	manifest, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return report, err
+	}
+	for name, content := range map[string][]byte{
+		siteSearchFile:   []byte(siteSearchPage()),
+		siteIndexFile:    index,
+		siteMapFile:      []byte(siteMap(pages, next, opts.BaseURL)),
+		siteManifestFile: manifest,
+	} {
+		if err := writeSiteFileIfChanged(filepath.Join(dir, name), content); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+var (
+	contentPathsOnce sync.Once
+	contentPaths     map[string]bool
+)
+
+// IsContentPath 判断 API 路径是否为公开学习内容页面（主题列表、菜单、章节与 Types 提纲），
+// 这些响应只取决于路径与格式，可以缓存。
+func IsContentPath(path string) bool {
+	contentPathsOnce.Do(func() {
+		contentPaths = make(map[string]bool)
+		pages, err := sitePages()
+		if err != nil {
+			return
+		}
+		for _, sp := range pages {
+			contentPaths[sp.Link] = true
+		}
+	})
+	return contentPaths[path]
+}
+
+// sitePages 按 courseTopics 的顺序列出全部静态页面，内容与对应 GET 接口的 format=html 输出一致。
+func sitePages() ([]sitePage, error) {
+	index := topicsPage(courseTopics)
+	// 静态站点没有检索接口，首页额外链接到客户端搜索页
+	index.Blocks = append(index.Blocks, listBlock(listItem{Text: "搜索课程内容", Link: siteSearchFile}))
+	pages := []sitePage{{Link: topicsBackLink.Path, File: siteFile(topicsBackLink.Path), Page: index}}
+
+	for _, topic := range courseTopics {
+		add := func(link string, p *page) {
+			pages = append(pages, sitePage{Link: link, File: siteFile(link), Section: topic.Title, Page: p})
+		}
+		addChapters := func(title, basePath string, chapters []chapterDef) {
+			add(basePath, menuPage(title, basePath, chapterMenuItems(chapters), topicsBackLink))
+			for _, c := range chapters {
+				add(basePath+"/"+c.ID, chapterPage(c.Title, c.ContentFunc(), menuBackLink(basePath)))
+			}
+		}
+
+		switch topic.ID {
+		case "lexical_elements":
+			addChapters(lexicalMenuTitle, lexicalBasePath, lexicalChapters)
+		case "constants":
+			addChapters(constantsMenuTitle, constantsBasePath, constantsChapters)
+		case "variables":
+			add(variablesBasePath, variablesMenuPage())
+			for _, v := range variableTopics {
+				content, err := variables.LoadContent(v.Topic)
+				if err != nil {
+					return nil, err
+				}
+				quiz, quizErr := variables.LoadQuiz(v.Topic)
+				add(variablesBasePath+"/"+v.ID, variableContentPage(content, quiz, quizErr))
+			}
+		case "types":
+			add(typesBasePath, typesMenuPage())
+			add(typesBasePath+"/outline", typesOutlinePage(types.GetOverview()))
+			for _, t := range types.AllTopics() {
+				content, err := types.LoadContent(t)
+				if err != nil {
+					return nil, err
+				}
+				quiz, quizErr := types.LoadQuiz(t)
+				add(typesBasePath+"/"+string(t), typesContentPage(content, quiz, quizErr))
+			}
+		default:
+			return nil, fmt.Errorf("主题 %s 没有可生成的页面", topic.ID)
+		}
+	}
+	return pages, nil
+}
+
+// siteFile 把 API 路径映射为站点内的文件：主题列表为 index.html，其余去掉 /api/v1 前缀并加 .html。
+func siteFile(link string) string {
+	if link == topicsBackLink.Path {
+		return "index.html"
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(link, apiPrefix), "/") + ".html"
+}
+
+// relativeHref 返回从 from 页面链接到 to 页面的相对地址，两者均为相对站点根目录的路径。
+func relativeHref(from, to string) string {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+	return filepath.ToSlash(rel)
+}
+
+func pageLinks(p *page) []string {
+	var links []string
+	for _, b := range p.Blocks {
+		for _, item := range b.Items {
+			if item.Link != "" {
+				links = append(links, item.Link)
+			}
+		}
+	}
+	if p.Back != nil {
+		links = append(links, p.Back.Path)
+	}
+	return links
+}
+
+// pageText 提取页面中的全部文字并合并空白，作为搜索索引的正文。
+func pageText(p *page) string {
+	var parts []string
+	for _, b := range p.Blocks {
+		parts = append(parts, b.Text)
+		for _, item := range b.Items {
+			parts = append(parts, item.Text)
+			parts = append(parts, item.Sub...)
+			parts = append(parts, item.Notes...)
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+func siteSearchIndex(pages []sitePage) ([]byte, error) {
+	entries := make([]siteSearchEntry, len(pages))
+	for i, sp := range pages {
+		entries[i] = siteSearchEntry{Title: sp.Page.Title, URL: sp.File, Section: sp.Section, Text: pageText(sp.Page)}
+	}
+	return json.Marshal(entries)
+}
+
+// siteMap 生成 sitemap.xml，lastmod 取页面内容最近一次变化的日期。
+func siteMap(pages []sitePage, manifest siteManifest, baseURL string) string {
+	prefix := ""
+	if baseURL != "" {
+		prefix = strings.TrimRight(baseURL, "/") + "/"
+	}
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	sb.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, sp := range pages {
+		fmt.Fprintf(&sb, "  <url><loc>%s</loc><lastmod>%s</lastmod></url>\n", html.EscapeString(prefix+sp.File), manifest.Pages[sp.File].Updated)
+	}
+	sb.WriteString("</urlset>\n")
+	return sb.String()
+}
+
+// siteSearchPage 客户端搜索页：加载 search-index.json，按标题与正文做不区分大小写的子串匹配。
+func siteSearchPage() string {
+	return getHtmlPage("搜索课程内容", `<h1>搜索课程内容</h1>
+<p><input id="q" type="search" placeholder="输入关键词，如 slice、iota" autofocus></p>
+<ul id="results"></ul>
+<a href="index.html" class="back-link">`+topicsBackLink.Text+`</a>
+<script>
+(function () {
+  var input = document.getElementById('q'), list = document.getElementById('results'), index = [];
+  function search() {
+    var q = input.value.trim().toLowerCase();
+    list.textContent = '';
+    if (!q) { return; }
+    index.forEach(function (p) {
+      if ((p.title + ' ' + p.text).toLowerCase().indexOf(q) < 0) { return; }
+      var li = document.createElement('li'), a = document.createElement('a'), note = document.createElement('span');
+      a.href = p.url;
+      a.textContent = p.title;
+      note.className = 'note';
+      note.textContent = ' ' + p.section;
+      li.appendChild(a);
+      li.appendChild(note);
+      list.appendChild(li);
+    });
+  }
+  input.addEventListener('input', search);
+  fetch('`+siteIndexFile+`').then(function (r) { return r.json(); }).then(function (data) { index = data; search(); });
+})();
+</script>`)
+}
+
+func readSiteManifest(dir string) (siteManifest, error) {
+	var m siteManifest
+	data, err := os.ReadFile(filepath.Join(dir, siteManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		// 记录损坏时按首次构建处理
+		return siteManifest{}, nil
+	}
+	return m, nil
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func writeSiteFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// writeSiteFileIfChanged 内容相同时不改写文件，保留修改时间，便于同步工具跳过未变化的文件。
+func writeSiteFileIfChanged(path string, content []byte) error {
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, content) {
+		return nil
+	}
+	return writeSiteFile(path, content)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func readSiteFile(t *gtest.T, dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	t.AssertNil(err)
+	return string(data)
+}
+
+func TestBuildSite(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		dir := t.TempDir()
+		opts := SiteOptions{BaseURL: "https://go.example.com/course/", Now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+		report, err := BuildSite(dir, opts)
+		t.AssertNil(err)
+		t.Assert(report.Unchanged, 0)
+		t.AssertGT(report.Rendered, 40)
+
+		// 页面沿用 format=html 的模板，站内链接改写为相对地址
+		slice := readSiteFile(t, dir, "topic/types/slice.html")
+		t.AssertIN(htmlStyle, slice)
+		t.AssertIN("<h3>测验</h3>", slice)
+		t.AssertIN(`<a href="../types.html" class="back-link">返回 Types 菜单</a>`, slice)
+		t.AssertIN(`<a href="topic/lexical_elements.html">Lexical Elements</a>`, readSiteFile(t, dir, "index.html"))
+		t.AssertIN(`<a href="lexical_elements/comments.html">`, readSiteFile(t, dir, "topic/lexical_elements.html"))
+		t.AssertIN("Types 提纲", readSiteFile(t, dir, "topic/types/outline.html"))
+		t.AssertIN("search-index.json", readSiteFile(t, dir, "search.html"))
+
+		var index []siteSearchEntry
+		t.AssertNil(json.Unmarshal([]byte(readSiteFile(t, dir, "search-index.json")), &index))
+		t.Assert(len(index), report.Rendered)
+		found := false
+		for _, e := range index {
+			if e.URL == "topic/constants/iota.html" {
+				found = true
+				t.Assert(e.Section, "Constants")
+				t.AssertIN("iota", e.Text)
+			}
+		}
+		t.Assert(found, true)
+
+		t.AssertIN("<loc>https://go.example.com/course/topic/types/slice.html</loc><lastmod>2024-05-01</lastmod>", readSiteFile(t, dir, "sitemap.xml"))
+	})
+}
+
+func TestBuildSite_Incremental(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		dir := t.TempDir()
+		first, err := BuildSite(dir, SiteOptions{})
+		t.AssertNil(err)
+
+		report, err := BuildSite(dir, SiteOptions{})
+		t.AssertNil(err)
+		t.Assert(report, SiteReport{Unchanged: first.Rendered})
+
+		// 内容哈希变化或文件丢失的页面重新渲染，已不再生成的页面被删除
+		manifest, err := readSiteManifest(dir)
+		t.AssertNil(err)
+		entry := manifest.Pages["topic/types/map.html"]
+		entry.Hash = "stale"
+		manifest.Pages["topic/types/map.html"] = entry
+		manifest.Pages["topic/types/removed.html"] = siteManifestEntry{Hash: "old"}
+		data, _ := json.Marshal(manifest)
+		t.AssertNil(os.WriteFile(filepath.Join(dir, siteManifestFile), data, 0o644))
+		t.AssertNil(os.WriteFile(filepath.Join(dir, "topic", "types", "removed.html"), []byte("old"), 0o644))
+		t.AssertNil(os.Remove(filepath.Join(dir, "topic", "variables", "zero.html")))
+
+		report, err = BuildSite(dir, SiteOptions{})
+		t.AssertNil(err)
+		t.Assert(report, SiteReport{Rendered: 2, Unchanged: first.Rendered - 2, Removed: 1})
+		t.Assert(fileExists(filepath.Join(dir, "topic", "types", "removed.html")), false)
+		t.Assert(fileExists(filepath.Join(dir, "topic", "variables", "zero.html")), true)
+
+		report, err = BuildSite(dir, SiteOptions{Force: true})
+		t.AssertNil(err)
+		t.Assert(report, SiteReport{Rendered: first.Rendered})
+	})
+}
+
+func TestRelativeHref(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(relativeHref("index.html", "topic/types.html"), "topic/types.html")
+		t.Assert(relativeHref("topic/types.html", "topic/types/map.html"), "types/map.html")
+		t.Assert(relativeHref("topic/types/map.html", "topic/types.html"), "../types.html")
+		t.Assert(relativeHref("topic/types/map.html", "index.html"), "../../index.html")
+	})
+}
@@ -171,27 +171,64 @@ func TestSendTopicsJSON(t *testing.T) {
 		t.AssertNil(err)
 		defer resp.Close()
 
+		t.AssertIN("application/json", resp.Header.Get("Content-Type"))
 		err = json.Unmarshal(resp.ReadAll(), &capturedResponse)
 		t.AssertNil(err)
 		t.Assert(capturedResponse.Code, 20000)
 		t.Assert(capturedResponse.Message, "OK")
+		topics := capturedResponse.Data.(map[string]interface{})["topics"].([]interface{})
+		t.Assert(len(topics), 2)
+		t.Assert(topics[1].(map[string]interface{})["description"], "Description 2")
 	})
 }
 
-func TestSendTopicsHTML(t *testing.T) {
+func TestWritePage_TopicsMarkdown(t *testing.T) {
 	gtest.C(t, func(t *gtest.T) {
-		s := g.Server("test-send-topics-html")
+		s := g.Server("test-write-page-topics-markdown")
 		s.SetPort(0)
 		s.SetAccessLogEnabled(false)
 
-		h := New()
+		s.Group("/test", func(group *ghttp.RouterGroup) {
+			group.GET("/topics", func(r *ghttp.Request) {
+				topics := []Topic{
+					{ID: "test1", Title: "Test 1", Description: "Description 1"},
+					{ID: "test2", Title: "Test 2", Description: "Description 2"},
+				}
+				writePage(r, 200, markdownRenderer{}, topicsPage(topics))
+			})
+		})
+
+		s.Start()
+		defer s.Shutdown()
+
+		port := s.GetListenedPort()
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", port))
+
+		resp, err := client.Get(nil, "/test/topics")
+		t.AssertNil(err)
+		defer resp.Close()
+
+		t.AssertIN("text/markdown", resp.Header.Get("Content-Type"))
+		body := resp.ReadAllString()
+		t.AssertIN("# Available Learning Topics", body)
+		t.AssertIN("- [Test 1](/api/v1/topic/test1?format=markdown)", body)
+		t.AssertIN("Description 2", body)
+	})
+}
+
+func TestWritePage_TopicsHTML(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		s := g.Server("test-write-page-topics-html")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
 
 		s.Group("/test", func(group *ghttp.RouterGroup) {
 			group.GET("/topics", func(r *ghttp.Request) {
 				topics := []Topic{
 					{ID: "test1", Title: "Test 1", Description: "Description 1"},
 				}
-				h.sendTopicsHTML(r, topics)
+				writePage(r, 200, htmlRenderer{}, topicsPage(topics))
 			})
 		})
 
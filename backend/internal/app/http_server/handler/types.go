@@ -1,9 +1,19 @@
 package handler
 
 import (
+	"sync"
+
+	"go-study2/internal/app/http_server/handler/internal"
+	"go-study2/internal/domain/classroom"
+	"go-study2/internal/domain/discussion"
+	"go-study2/internal/domain/jobs"
+	"go-study2/internal/domain/leaderboard"
+	"go-study2/internal/domain/notes"
+	"go-study2/internal/domain/notifications"
 	"go-study2/internal/domain/progress"
 	"go-study2/internal/domain/quiz"
 	"go-study2/internal/domain/user"
+	"go-study2/internal/domain/webhooks"
 )
 
 // Topic 学习主题
@@ -18,6 +28,8 @@ type Response struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	// RequestID 仅错误响应携带，与响应头 X-Request-ID 一致
+	RequestID string `json:"requestId,omitempty"`
 }
 
 // TopicListResponse 主题列表响应数据
@@ -37,11 +49,27 @@ type LexicalMenuResponse struct {
 	Items []LexicalMenuItem `json:"items"`
 }
 
+// ChapterContentResponse 词法元素与常量章节内容响应
+type ChapterContentResponse struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
 // Handler 处理 HTTP 请求的控制器，包含需要的领域服务。
 type Handler struct {
 	userService     *user.Service
 	progressService *progress.Service
 	quizService     *quiz.Service
+	classService    *classroom.Service
+	boardService    *leaderboard.Service
+	notesService    *notes.Service
+	discussService  *discussion.Service
+	notifyService   *notifications.Service
+	webhookService  *webhooks.Service
+	jobService      *jobs.Service
+
+	oidcOnce      sync.Once
+	oidcProviders map[string]*internal.OIDCProvider
 }
 
 // New 创建默认 Handler。
@@ -2,64 +2,84 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 
+	"go-study2/internal/domain/quiz"
+	"go-study2/internal/infrastructure/database"
 	"go-study2/src/learning/types"
 
 	"github.com/gogf/gf/v2/net/ghttp"
 )
 
+const typesBasePath = apiPrefix + "/topic/types"
+
+// typesBackLink Types 内容页面返回菜单的链接
+var typesBackLink = pageLink{Text: "返回 Types 菜单", Path: typesBasePath}
+
+// typesContentResponse Types 子主题内容与测验题
+type typesContentResponse struct {
+	Content types.TopicContent `json:"content"`
+	Quiz    []types.QuizItem   `json:"quiz"`
+}
+
+// typesQuizSubmitRequest Types 综合测验提交请求，答案为空时按标准答案评分
+type typesQuizSubmitRequest struct {
+	Answers []typesQuizAnswer `json:"answers"`
+}
+
+type typesQuizAnswer struct {
+	ID     string `json:"id"`
+	Choice string `json:"choice"`
+}
+
+// typesSearchResponse Types 关键词检索结果
+type typesSearchResponse struct {
+	Keyword string                 `json:"keyword"`
+	Results []types.ReferenceIndex `json:"results"`
+}
+
+// typesOutlineResponse Types 提纲
+type typesOutlineResponse struct {
+	Title     string   `json:"title"`
+	Version   string   `json:"version"`
+	Printable []string `json:"printable"`
+}
+
 // GetTypesMenu 返回 Types 章节菜单。
 func (h *Handler) GetTypesMenu(r *ghttp.Request) {
-	format := r.GetCtxVar("format").String()
-	items := buildTypesMenuItems()
-
-	if format == "html" {
-		h.sendTypesMenuHTML(r, items)
+	if rd := contentRenderer(r); rd != nil {
+		writePage(r, http.StatusOK, rd, typesMenuPage())
 		return
 	}
-
-	r.Response.WriteJson(Response{
-		Code:    20000,
-		Message: "OK",
-		Data: LexicalMenuResponse{
-			Items: items,
-		},
-	})
+	writeMenuJSON(r, buildTypesMenuItems())
 }
 
-func (h *Handler) sendTypesMenuHTML(r *ghttp.Request, items []LexicalMenuItem) {
-	var sb strings.Builder
-	sb.WriteString("<h1>Types Learning</h1>\n<ul>\n")
-	for _, item := range items {
-		sb.WriteString(fmt.Sprintf("<li><a href=\"/api/v1/topic/types/%s?format=html\">%s</a></li>\n", item.Name, item.Title))
-	}
-	sb.WriteString("</ul>\n")
-	sb.WriteString("<a href=\"/api/v1/topics?format=html\" class=\"back-link\">返回主题列表</a>")
-	r.Response.Write(getHtmlPage("Types Learning", sb.String()))
+func typesMenuPage() *page {
+	return menuPage("Types Learning", typesBasePath, buildTypesMenuItems(), pageLink{Text: "返回主题列表", Path: topicsBackLink.Path})
 }
 
 // GetTypesContent 返回子主题内容，占位实现提示待上线。
 func (h *Handler) GetTypesContent(r *ghttp.Request) {
-	format := r.GetCtxVar("format").String()
 	subtopic := r.Get("subtopic").String()
 	topic := types.NormalizeTopic(subtopic)
 	if !types.IsSupportedTopic(topic) {
-		h.writeTypesNotFound(r, format, "未知的 Types 子主题")
+		h.writeTypesNotFound(r, "未知的 Types 子主题")
 		return
 	}
 
 	content, err := types.LoadContent(topic)
 	if err != nil {
-		h.writeTypesNotFound(r, format, err.Error())
+		h.writeTypesNotFound(r, err.Error())
 		return
 	}
 	quiz, quizErr := types.LoadQuiz(topic)
 
-	if format == "html" {
-		h.sendTypesContentHTML(r, content, quiz, quizErr)
+	if rd := contentRenderer(r); rd != nil {
+		writePage(r, http.StatusOK, rd, typesContentPage(content, quiz, quizErr))
 		return
 	}
 
@@ -71,23 +91,17 @@ func (h *Handler) GetTypesContent(r *ghttp.Request) {
 	r.Response.WriteJson(Response{
 		Code:    20000,
 		Message: "OK",
-		Data: map[string]interface{}{
-			"content": content,
-			"quiz":    quiz,
+		Data: typesContentResponse{
+			Content: content,
+			Quiz:    quiz,
 		},
 	})
 }
 
-// SubmitTypesQuiz 接收综合测验答案并评分。
+// SubmitTypesQuiz 接收综合测验答案并评分，题目与 /quiz 接口相同（内置题目加题库中已发布的题目），不记录成绩；
+// 未初始化数据库时只按内置题目评分。
 func (h *Handler) SubmitTypesQuiz(r *ghttp.Request) {
-	format := r.GetCtxVar("format").String()
-
-	var payload struct {
-		Answers []struct {
-			ID     string `json:"id"`
-			Choice string `json:"choice"`
-		} `json:"answers"`
-	}
+	var payload typesQuizSubmitRequest
 	body, _ := io.ReadAll(r.Body)
 	if len(body) == 0 {
 		body = r.GetBody()
@@ -96,24 +110,42 @@ func (h *Handler) SubmitTypesQuiz(r *ghttp.Request) {
 		h.writeErrorJSON(r, 400, "请求体解析失败")
 		return
 	}
-	answerMap := map[string]string{}
+	if h.quizService == nil && database.Default() == nil {
+		h.writeTypesQuizResult(r, evaluateBuiltinTypesQuiz(payload.Answers))
+		return
+	}
+	svc, ok := h.getQuizService(r)
+	if !ok {
+		return
+	}
+
+	ctx := r.GetCtx()
+	answers := make([]quiz.SubmitAnswer, 0, len(payload.Answers))
 	for _, a := range payload.Answers {
-		answerMap[a.ID] = a.Choice
+		answers = append(answers, quiz.SubmitAnswer{ID: a.ID, Choices: strings.Split(a.Choice, "")})
 	}
-	if len(answerMap) == 0 {
-		for _, item := range types.LoadComprehensiveQuiz() {
-			answerMap[item.ID] = item.Answer
+	if len(answers) == 0 {
+		questions, err := svc.GetQuestions(ctx, "types", quiz.TypesComprehensiveChapter)
+		if err != nil {
+			h.writeTypesQuizError(r, err)
+			return
+		}
+		for _, q := range questions {
+			answers = append(answers, quiz.SubmitAnswer{ID: q.ID, Choices: q.Answer})
 		}
 	}
 
-	result, err := types.EvaluateComprehensiveQuiz(answerMap)
+	questions, graded, err := svc.Grade(ctx, "types", quiz.TypesComprehensiveChapter, answers)
 	if err != nil {
-		h.writeErrorJSON(r, 400, err.Error())
+		h.writeTypesQuizError(r, err)
 		return
 	}
+	h.writeTypesQuizResult(r, typesQuizResult(questions, graded))
+}
 
-	if format == "html" {
-		h.sendTypesQuizHTML(r, "comprehensive", result)
+func (h *Handler) writeTypesQuizResult(r *ghttp.Request, result types.QuizResult) {
+	if rd := contentRenderer(r); rd != nil {
+		writePage(r, http.StatusOK, rd, typesQuizResultPage("comprehensive", result))
 		return
 	}
 
@@ -124,9 +156,51 @@ func (h *Handler) SubmitTypesQuiz(r *ghttp.Request) {
 	})
 }
 
+// evaluateBuiltinTypesQuiz 仅按内置综合测验题目评分，答案为空时按标准答案评分。
+func evaluateBuiltinTypesQuiz(answers []typesQuizAnswer) types.QuizResult {
+	answerMap := make(map[string]string, len(answers))
+	for _, a := range answers {
+		answerMap[a.ID] = a.Choice
+	}
+	if len(answerMap) == 0 {
+		for _, item := range types.LoadComprehensiveQuiz() {
+			answerMap[item.ID] = item.Answer
+		}
+	}
+	result, _ := types.EvaluateComprehensiveQuiz(answerMap)
+	return result
+}
+
+func (h *Handler) writeTypesQuizError(r *ghttp.Request, err error) {
+	if errors.Is(err, quiz.ErrInvalidInput) || errors.Is(err, quiz.ErrQuizUnavailable) {
+		h.writeErrorJSON(r, 400, err.Error())
+		return
+	}
+	h.writeErrorJSON(r, 500, "服务器繁忙，请稍后再试")
+}
+
+// typesQuizResult 把测验服务的评分结果转换为 Types 测验结果，逐题附带答案与解析。
+func typesQuizResult(questions []quiz.Question, graded *quiz.Result) types.QuizResult {
+	correct := make(map[string]struct{}, len(graded.CorrectIDs))
+	for _, id := range graded.CorrectIDs {
+		correct[id] = struct{}{}
+	}
+	details := make([]types.QuizAnswerFeedback, 0, len(questions))
+	for _, q := range questions {
+		_, ok := correct[q.ID]
+		details = append(details, types.QuizAnswerFeedback{
+			ID:          q.ID,
+			Correct:     ok,
+			Answer:      strings.Join(q.Answer, ""),
+			Explanation: q.Explanation,
+			RuleRef:     q.RuleRef,
+		})
+	}
+	return types.QuizResult{Score: graded.Score, Total: graded.Total, Details: details}
+}
+
 // SearchTypes 返回检索占位响应。
 func (h *Handler) SearchTypes(r *ghttp.Request) {
-	format := r.GetCtxVar("format").String()
 	keyword := r.GetQuery("keyword").String()
 	results, err := types.SearchReferences(keyword)
 	if err != nil {
@@ -134,21 +208,21 @@ func (h *Handler) SearchTypes(r *ghttp.Request) {
 		return
 	}
 	if len(results) == 0 {
-		h.writeTypesNotFound(r, format, "未找到匹配关键词")
+		h.writeTypesNotFound(r, "未找到匹配关键词")
 		return
 	}
 
-	if format == "html" {
-		h.sendTypesSearchHTML(r, keyword, results)
+	if rd := contentRenderer(r); rd != nil {
+		writePage(r, http.StatusOK, rd, typesSearchPage(keyword, results))
 		return
 	}
 
 	r.Response.WriteJson(Response{
 		Code:    20000,
 		Message: "OK",
-		Data: map[string]interface{}{
-			"keyword": keyword,
-			"results": results,
+		Data: typesSearchResponse{
+			Keyword: keyword,
+			Results: results,
 		},
 	})
 }
@@ -201,106 +275,103 @@ func formatTypesTitle(topic types.Topic) string {
 	}
 }
 
-func (h *Handler) sendTypesContentHTML(r *ghttp.Request, content types.TopicContent, quiz []types.QuizItem, quizErr error) {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("<h1>%s</h1>\n", content.Concept.Title))
-	sb.WriteString(fmt.Sprintf("<p>%s</p>\n", content.Concept.Summary))
-	if len(content.Concept.Rules) > 0 {
-		sb.WriteString("<h3>规则</h3><ul>\n")
-		for _, rule := range content.Concept.Rules {
-			sb.WriteString(fmt.Sprintf("<li>%s</li>\n", rule))
+func typesContentPage(content types.TopicContent, quiz []types.QuizItem, quizErr error) *page {
+	p := typesLessonPage(content)
+	p.Blocks = append(p.Blocks, headingBlock(2, "测验"))
+	switch {
+	case quizErr == types.ErrQuizUnavailable:
+		p.Blocks = append(p.Blocks, paragraphBlock("当前主题暂无测验。"))
+	case quizErr != nil:
+		p.Blocks = append(p.Blocks, paragraphBlock(fmt.Sprintf("测验加载失败: %v", quizErr)))
+	default:
+		items := make([]listItem, len(quiz))
+		for i, item := range quiz {
+			items[i] = quizListItem(item.Stem, item.Options, item.Answer, item.Explanation)
 		}
-		sb.WriteString("</ul>\n")
+		p.Blocks = append(p.Blocks, orderedListBlock(items...))
+	}
+	return p
+}
+
+// typesLessonPage 生成 Types 子主题的讲解部分（概念、规则与示例，不含测验）。
+func typesLessonPage(content types.TopicContent) *page {
+	p := &page{Title: content.Concept.Title, Back: &typesBackLink}
+	p.Blocks = append(p.Blocks, paragraphBlock(content.Concept.Summary))
+	if len(content.Concept.Rules) > 0 {
+		p.Blocks = append(p.Blocks, headingBlock(2, "规则"), listBlock(textItems(content.Concept.Rules)...))
 	}
 	if len(content.Examples) > 0 {
-		sb.WriteString("<h3>示例</h3>\n")
+		p.Blocks = append(p.Blocks, headingBlock(2, "示例"))
 		for _, ex := range content.Examples {
-			sb.WriteString(fmt.Sprintf("<h4>%s</h4><pre>%s</pre>\n", ex.Title, ex.Code))
+			p.Blocks = append(p.Blocks, headingBlock(3, ex.Title), codeBlock("go", ex.Code))
 			if ex.ExpectedOutput != "" {
-				sb.WriteString(fmt.Sprintf("<p>输出: %s</p>\n", ex.ExpectedOutput))
+				p.Blocks = append(p.Blocks, paragraphBlock("输出: "+ex.ExpectedOutput))
 			}
 		}
 	}
-	sb.WriteString("<h3>测验</h3>\n")
-	if quizErr == types.ErrQuizUnavailable {
-		sb.WriteString("<p>当前主题暂无测验。</p>")
-	} else if quizErr != nil {
-		sb.WriteString(fmt.Sprintf("<p>测验加载失败: %v</p>", quizErr))
-	} else {
-		sb.WriteString("<ol>\n")
-		for _, item := range quiz {
-			sb.WriteString(fmt.Sprintf("<li>%s<ul>", item.Stem))
-			for i, opt := range item.Options {
-				sb.WriteString(fmt.Sprintf("<li>%c) %s</li>", 'A'+i, opt))
-			}
-			sb.WriteString(fmt.Sprintf("</ul><strong>答案:</strong> %s<br><em>%s</em></li>\n", item.Answer, item.Explanation))
-		}
-		sb.WriteString("</ol>\n")
-	}
-	sb.WriteString("<a href=\"/api/v1/topic/types?format=html\" class=\"back-link\">返回菜单</a>")
-	r.Response.Write(getHtmlPage(content.Concept.Title, sb.String()))
+	return p
 }
 
-func (h *Handler) sendTypesQuizHTML(r *ghttp.Request, topic types.Topic, result types.QuizResult) {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("<h1>Types 测验结果 - %s</h1>", topic))
-	sb.WriteString(fmt.Sprintf("<p>得分: %d / %d</p>", result.Score, result.Total))
+func typesQuizResultPage(topic types.Topic, result types.QuizResult) *page {
+	p := &page{
+		Title:  fmt.Sprintf("Types 测验结果 - %s", topic),
+		Blocks: []block{paragraphBlock(fmt.Sprintf("得分: %d / %d", result.Score, result.Total))},
+		Back:   &typesBackLink,
+	}
 	if len(result.Details) > 0 {
-		sb.WriteString("<ol>\n")
-		for _, d := range result.Details {
+		items := make([]listItem, len(result.Details))
+		for i, d := range result.Details {
 			state := "错误"
 			if d.Correct {
 				state = "正确"
 			}
-			sb.WriteString(fmt.Sprintf("<li>%s - %s (答案: %s)</li>\n", d.ID, state, d.Answer))
-			sb.WriteString(fmt.Sprintf("<p>%s</p>\n", d.Explanation))
+			items[i] = listItem{Text: fmt.Sprintf("%s - %s (答案: %s)", d.ID, state, d.Answer), Notes: []string{d.Explanation}}
 		}
-		sb.WriteString("</ol>\n")
+		p.Blocks = append(p.Blocks, orderedListBlock(items...))
 	}
-	sb.WriteString("<a href=\"/api/v1/topic/types?format=html\" class=\"back-link\">返回 Types 菜单</a>")
-	r.Response.Write(getHtmlPage("Types Quiz", sb.String()))
+	return p
 }
 
-func (h *Handler) sendTypesSearchHTML(r *ghttp.Request, keyword string, results []types.ReferenceIndex) {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("<h1>Types 搜索: %s</h1>\n<ul>\n", keyword))
-	for _, res := range results {
-		sb.WriteString(fmt.Sprintf("<li><strong>%s</strong>: %s</li>\n", res.Keyword, res.Summary))
+func typesSearchPage(keyword string, results []types.ReferenceIndex) *page {
+	items := make([]listItem, len(results))
+	for i, res := range results {
+		items[i] = listItem{Text: fmt.Sprintf("%s: %s", res.Keyword, res.Summary)}
 	}
-	sb.WriteString("</ul>\n<a href=\"/api/v1/topic/types?format=html\" class=\"back-link\">返回 Types 菜单</a>")
-	r.Response.Write(getHtmlPage("Types Search", sb.String()))
+	return &page{Title: fmt.Sprintf("Types 搜索: %s", keyword), Blocks: []block{listBlock(items...)}, Back: &typesBackLink}
 }
 
 // GetTypesOutline 返回类型提纲。
 func (h *Handler) GetTypesOutline(r *ghttp.Request) {
-	format := r.GetCtxVar("format").String()
 	overview := types.GetOverview()
 
-	if format == "html" {
-		var sb strings.Builder
-		sb.WriteString("<h1>Types 提纲</h1>\n")
-		for _, line := range overview.Printable {
-			sb.WriteString(fmt.Sprintf("<p>%s</p>\n", line))
-		}
-		sb.WriteString("<a href=\"/api/v1/topic/types?format=html\" class=\"back-link\">返回 Types 菜单</a>")
-		r.Response.Write(getHtmlPage("Types Outline", sb.String()))
+	if rd := contentRenderer(r); rd != nil {
+		writePage(r, http.StatusOK, rd, typesOutlinePage(overview))
 		return
 	}
 
 	r.Response.WriteJson(Response{
 		Code:    20000,
 		Message: "OK",
-		Data: map[string]interface{}{
-			"title":     overview.Title,
-			"version":   overview.Version,
-			"printable": overview.Printable,
+		Data: typesOutlineResponse{
+			Title:     overview.Title,
+			Version:   overview.Version,
+			Printable: overview.Printable,
 		},
 	})
 }
 
-func (h *Handler) writeTypesNotFound(r *ghttp.Request, format, msg string) {
-	if format == "html" {
-		r.Response.WriteStatus(404, getHtmlPage("Not Found", fmt.Sprintf("<p>%s</p><a href=\"/api/v1/topic/types?format=html\" class=\"back-link\">返回 Types 菜单</a>", msg)))
+// typesOutlinePage 把可打印提纲逐行输出为段落。
+func typesOutlinePage(overview types.TypeOverview) *page {
+	p := &page{Title: "Types 提纲", Back: &typesBackLink}
+	for _, line := range overview.Printable {
+		p.Blocks = append(p.Blocks, paragraphBlock(line))
+	}
+	return p
+}
+
+func (h *Handler) writeTypesNotFound(r *ghttp.Request, msg string) {
+	if rd := contentRenderer(r); rd != nil {
+		writeNotFoundPage(r, rd, msg, typesBackLink)
 		return
 	}
 	r.Response.WriteStatusExit(404, Response{
@@ -2,13 +2,15 @@ package handler
 
 import (
 	"fmt"
-	"strings"
+	"net/http"
 
 	"go-study2/src/learning/variables"
 
 	"github.com/gogf/gf/v2/net/ghttp"
 )
 
+const variablesBasePath = apiPrefix + "/topic/variables"
+
 var variableTopics = []struct {
 	ID          string
 	Title       string
@@ -21,10 +23,22 @@ var variableTopics = []struct {
 	{"zero", "Zero (零值与取值规则)", variables.TopicZero, "各类型零值与复合元素零值规则"},
 }
 
+// variablesContentResponse Variables 子主题内容与测验题
+type variablesContentResponse struct {
+	Content variables.Content    `json:"content"`
+	Quiz    []variables.QuizItem `json:"quiz"`
+}
+
 // GetVariablesMenu 获取 Variables 菜单
 func (h *Handler) GetVariablesMenu(r *ghttp.Request) {
-	format := r.GetCtxVar("format").String()
+	if rd := contentRenderer(r); rd != nil {
+		writePage(r, http.StatusOK, rd, variablesMenuPage())
+		return
+	}
+	writeMenuJSON(r, variableMenuItems())
+}
 
+func variableMenuItems() []LexicalMenuItem {
 	items := make([]LexicalMenuItem, len(variableTopics))
 	for i, v := range variableTopics {
 		items[i] = LexicalMenuItem{
@@ -33,55 +47,32 @@ func (h *Handler) GetVariablesMenu(r *ghttp.Request) {
 			Name:  v.ID,
 		}
 	}
-
-	if format == "html" {
-		h.sendVariablesMenuHTML(r, items)
-		return
-	}
-	h.sendVariablesMenuJSON(r, items)
-}
-
-func (h *Handler) sendVariablesMenuJSON(r *ghttp.Request, items []LexicalMenuItem) {
-	r.Response.WriteJson(Response{
-		Code:    20000,
-		Message: "OK",
-		Data: LexicalMenuResponse{
-			Items: items,
-		},
-	})
+	return items
 }
 
-func (h *Handler) sendVariablesMenuHTML(r *ghttp.Request, items []LexicalMenuItem) {
-	var sb strings.Builder
-	sb.WriteString("<h1>Variables Learning</h1>\n<ul>\n")
-	for _, item := range items {
-		sb.WriteString(fmt.Sprintf("<li><a href=\"/api/v1/topic/variables/%s?format=html\">%s</a></li>\n", item.Name, item.Title))
-	}
-	sb.WriteString("</ul>\n")
-	sb.WriteString("<a href=\"/api/v1/topics?format=html\" class=\"back-link\">Back to Topics</a>")
-	r.Response.Write(getHtmlPage("Variables Learning", sb.String()))
+func variablesMenuPage() *page {
+	return menuPage("Variables Learning", variablesBasePath, variableMenuItems(), topicsBackLink)
 }
 
 // GetVariableContent 获取指定变量子主题内容与测验
 func (h *Handler) GetVariableContent(r *ghttp.Request) {
-	format := r.GetCtxVar("format").String()
 	subtopic := r.Get("subtopic").String()
 	topic := variables.NormalizeTopic(subtopic)
 
 	if !variables.IsSupportedTopic(topic) {
-		h.writeNotFound(r, format, "Subtopic not found")
+		h.writeNotFound(r, "Subtopic not found")
 		return
 	}
 
 	content, err := variables.LoadContent(topic)
 	if err != nil {
-		h.writeNotFound(r, format, err.Error())
+		h.writeNotFound(r, err.Error())
 		return
 	}
 	quiz, quizErr := variables.LoadQuiz(topic)
 
-	if format == "html" {
-		h.sendVariableContentHTML(r, content, quiz, quizErr)
+	if rd := contentRenderer(r); rd != nil {
+		writePage(r, http.StatusOK, rd, variableContentPage(content, quiz, quizErr))
 		return
 	}
 
@@ -93,65 +84,73 @@ func (h *Handler) GetVariableContent(r *ghttp.Request) {
 	r.Response.WriteJson(Response{
 		Code:    20000,
 		Message: "OK",
-		Data: map[string]interface{}{
-			"content": content,
-			"quiz":    quiz,
+		Data: variablesContentResponse{
+			Content: content,
+			Quiz:    quiz,
 		},
 	})
 }
 
-func (h *Handler) sendVariableContentHTML(r *ghttp.Request, content variables.Content, quiz []variables.QuizItem, quizErr error) {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("<h1>%s (%s)</h1>\n", content.Title, content.Topic))
-	sb.WriteString(fmt.Sprintf("<p>%s</p>\n", content.Summary))
-	if len(content.Details) > 0 {
-		sb.WriteString("<ul>\n")
-		for _, d := range content.Details {
-			sb.WriteString(fmt.Sprintf("<li>%s</li>\n", d))
+func variableContentPage(content variables.Content, quiz []variables.QuizItem, quizErr error) *page {
+	p := variableLessonPage(content)
+	p.Blocks = append(p.Blocks, headingBlock(2, "Quiz"))
+	switch {
+	case quizErr == variables.ErrQuizUnavailable:
+		p.Blocks = append(p.Blocks, paragraphBlock("当前主题暂无测验。"))
+	case quizErr != nil:
+		p.Blocks = append(p.Blocks, paragraphBlock(fmt.Sprintf("测验加载失败: %v", quizErr)))
+	default:
+		items := make([]listItem, len(quiz))
+		for i, item := range quiz {
+			items[i] = quizListItem(item.Stem, item.Options, item.Answer, item.Explanation)
 		}
-		sb.WriteString("</ul>\n")
+		p.Blocks = append(p.Blocks, orderedListBlock(items...))
+	}
+	return p
+}
+
+// variableLessonPage 生成 Variables 子主题的讲解部分（不含测验）。
+func variableLessonPage(content variables.Content) *page {
+	back := menuBackLink(variablesBasePath)
+	p := &page{
+		Title: fmt.Sprintf("%s (%s)", content.Title, content.Topic),
+		Back:  &back,
+	}
+	p.Blocks = append(p.Blocks, paragraphBlock(content.Summary))
+	if len(content.Details) > 0 {
+		p.Blocks = append(p.Blocks, listBlock(textItems(content.Details)...))
 	}
 	if content.Snippet != "" {
-		sb.WriteString("<h3>Snippet</h3>\n<pre>\n")
-		sb.WriteString(content.Snippet)
-		sb.WriteString("\n</pre>\n")
+		p.Blocks = append(p.Blocks, headingBlock(2, "Snippet"), codeBlock("go", content.Snippet))
 	}
 	if len(content.Examples) > 0 {
-		sb.WriteString("<h3>Examples</h3>\n")
+		p.Blocks = append(p.Blocks, headingBlock(2, "Examples"))
 		for _, ex := range content.Examples {
-			sb.WriteString(fmt.Sprintf("<h4>%s</h4>\n<pre>\n%s\n</pre>\n<p>输出: %s</p>\n", ex.Title, ex.Code, ex.Output))
+			p.Blocks = append(p.Blocks, headingBlock(3, ex.Title), codeBlock("go", ex.Code), paragraphBlock("输出: "+ex.Output))
 			if len(ex.Notes) > 0 {
-				sb.WriteString("<ul>\n")
-				for _, n := range ex.Notes {
-					sb.WriteString(fmt.Sprintf("<li>%s</li>\n", n))
-				}
-				sb.WriteString("</ul>\n")
+				p.Blocks = append(p.Blocks, listBlock(textItems(ex.Notes)...))
 			}
 		}
 	}
-	sb.WriteString("<h3>Quiz</h3>\n")
-	if quizErr == variables.ErrQuizUnavailable {
-		sb.WriteString("<p>当前主题暂无测验。</p>")
-	} else if quizErr != nil {
-		sb.WriteString(fmt.Sprintf("<p>测验加载失败: %v</p>", quizErr))
-	} else {
-		sb.WriteString("<ol>\n")
-		for _, item := range quiz {
-			sb.WriteString(fmt.Sprintf("<li>%s<ul>", item.Stem))
-			for i, opt := range item.Options {
-				sb.WriteString(fmt.Sprintf("<li>%c) %s</li>", 'A'+i, opt))
-			}
-			sb.WriteString(fmt.Sprintf("</ul><strong>答案:</strong> %s<br><em>%s</em></li>\n", item.Answer, item.Explanation))
-		}
-		sb.WriteString("</ol>\n")
+	return p
+}
+
+// quizListItem 把一道测验题（题干、选项、答案与解析）转换为列表项。
+func quizListItem(stem string, options []string, answer, explanation string) listItem {
+	item := listItem{Text: stem, Notes: []string{"答案: " + answer}}
+	for i, opt := range options {
+		item.Sub = append(item.Sub, optionLabel(i, opt))
+	}
+	if explanation != "" {
+		item.Notes = append(item.Notes, explanation)
 	}
-	sb.WriteString("<a href=\"/api/v1/topic/variables?format=html\" class=\"back-link\">Back to Menu</a>")
-	r.Response.Write(getHtmlPage(string(content.Topic), sb.String()))
+	return item
 }
 
-func (h *Handler) writeNotFound(r *ghttp.Request, format, msg string) {
-	if format == "html" {
-		r.Response.WriteStatus(404, msg)
+// writeNotFound 输出 Variables 子主题不存在：JSON 沿用 HTTP 200 与业务码 404，其余格式返回 404 页面。
+func (h *Handler) writeNotFound(r *ghttp.Request, msg string) {
+	if rd := contentRenderer(r); rd != nil {
+		writeNotFoundPage(r, rd, msg, menuBackLink(variablesBasePath))
 		return
 	}
 	h.writeErrorJSON(r, 404, msg)
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-study2/internal/app/http_server/handler/internal"
+	"go-study2/internal/domain/webhooks"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type webhookEndpointRequest struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+func (req webhookEndpointRequest) input() webhooks.EndpointInput {
+	return webhooks.EndpointInput{
+		Name:   req.Name,
+		URL:    req.URL,
+		Events: req.Events,
+		Active: req.Active,
+	}
+}
+
+// ListWebhooks 返回全部 Webhook 接收地址（管理员）。
+func (h *Handler) ListWebhooks(r *ghttp.Request) {
+	svc, ok := h.getWebhookService(r)
+	if !ok {
+		return
+	}
+	list, err := svc.ListEndpoints(r.GetCtx())
+	if err != nil {
+		writeWebhookError(r, err)
+		return
+	}
+	writeSuccess(r, "success", list)
+}
+
+// CreateWebhook 新建接收地址，签名密钥仅在本次响应中返回。
+func (h *Handler) CreateWebhook(r *ghttp.Request) {
+	svc, ok := h.getWebhookService(r)
+	if !ok {
+		return
+	}
+	var req webhookEndpointRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	endpoint, err := svc.CreateEndpoint(r.GetCtx(), r.GetCtxVar("user_id").Int64(), req.input())
+	if err != nil {
+		writeWebhookError(r, err)
+		return
+	}
+	writeSuccess(r, "Webhook 已创建，请妥善保存签名密钥", endpoint)
+}
+
+// GetWebhook 返回单个接收地址。
+func (h *Handler) GetWebhook(r *ghttp.Request) {
+	svc, ok := h.getWebhookService(r)
+	if !ok {
+		return
+	}
+	endpoint, err := svc.GetEndpoint(r.GetCtx(), r.Get("id").Int64())
+	if err != nil {
+		writeWebhookError(r, err)
+		return
+	}
+	writeSuccess(r, "success", endpoint)
+}
+
+// UpdateWebhook 修改接收地址，未传 active 时保持原启用状态。
+func (h *Handler) UpdateWebhook(r *ghttp.Request) {
+	svc, ok := h.getWebhookService(r)
+	if !ok {
+		return
+	}
+	var req webhookEndpointRequest
+	if err := r.Parse(&req); err != nil {
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+		return
+	}
+	endpoint, err := svc.UpdateEndpoint(r.GetCtx(), r.GetCtxVar("user_id").Int64(), r.Get("id").Int64(), req.input())
+	if err != nil {
+		writeWebhookError(r, err)
+		return
+	}
+	writeSuccess(r, "Webhook 已更新", endpoint)
+}
+
+// DeleteWebhook 删除接收地址及其投递记录。
+func (h *Handler) DeleteWebhook(r *ghttp.Request) {
+	svc, ok := h.getWebhookService(r)
+	if !ok {
+		return
+	}
+	if err := svc.DeleteEndpoint(r.GetCtx(), r.GetCtxVar("user_id").Int64(), r.Get("id").Int64()); err != nil {
+		writeWebhookError(r, err)
+		return
+	}
+	writeSuccess(r, "Webhook 已删除", nil)
+}
+
+// SendWebhookTest 向接收地址发送一条 webhook.test 测试事件。
+func (h *Handler) SendWebhookTest(r *ghttp.Request) {
+	svc, ok := h.getWebhookService(r)
+	if !ok {
+		return
+	}
+	delivery, err := svc.SendTest(r.GetCtx(), r.GetCtxVar("user_id").Int64(), r.Get("id").Int64())
+	if err != nil {
+		writeWebhookError(r, err)
+		return
+	}
+	writeSuccess(r, "测试事件已加入投递队列", delivery)
+}
+
+// ListWebhookDeliveries 返回接收地址的投递记录，支持 status 与 limit 参数。
+func (h *Handler) ListWebhookDeliveries(r *ghttp.Request) {
+	svc, ok := h.getWebhookService(r)
+	if !ok {
+		return
+	}
+	list, err := svc.Deliveries(r.GetCtx(), r.Get("id").Int64(), r.Get("status").String(), r.Get("limit").Int())
+	if err != nil {
+		writeWebhookError(r, err)
+		return
+	}
+	writeSuccess(r, "success", list)
+}
+
+// RetryWebhookDelivery 立即重新投递指定记录（包括死信）。
+func (h *Handler) RetryWebhookDelivery(r *ghttp.Request) {
+	svc, ok := h.getWebhookService(r)
+	if !ok {
+		return
+	}
+	delivery, err := svc.RetryDelivery(r.GetCtx(), r.GetCtxVar("user_id").Int64(), r.Get("deliveryId").Int64())
+	if err != nil {
+		writeWebhookError(r, err)
+		return
+	}
+	writeSuccess(r, "已重新加入投递队列", delivery)
+}
+
+func (h *Handler) getWebhookService(r *ghttp.Request) (*webhooks.Service, bool) {
+	if h.webhookService != nil {
+		return h.webhookService, true
+	}
+	svc, err := internal.BuildWebhookService()
+	if err != nil {
+		writeError(r, http.StatusInternalServerError, 50001, "Webhook 服务不可用")
+		return nil, false
+	}
+	h.webhookService = svc
+	return svc, true
+}
+
+func writeWebhookError(r *ghttp.Request, err error) {
+	switch err {
+	case webhooks.ErrInvalidInput:
+		writeError(r, http.StatusBadRequest, 40004, "请求参数无效")
+	case webhooks.ErrEndpointNotFound:
+		writeError(r, http.StatusNotFound, 40041, "Webhook 接收地址不存在")
+	case webhooks.ErrDeliveryNotFound:
+		writeError(r, http.StatusNotFound, 40042, "Webhook 投递记录不存在")
+	default:
+		g.Log().Error(r.GetCtx(), err)
+		writeError(r, http.StatusInternalServerError, 50001, "服务器繁忙，请稍后再试")
+	}
+}
@@ -3,8 +3,11 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
+	"go-study2/internal/domain/user"
 	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/repository"
 	appjwt "go-study2/internal/pkg/jwt"
 
 	"github.com/gogf/gf/v2/frame/g"
@@ -12,49 +15,74 @@ import (
 	"github.com/gogf/gf/v2/os/gctx"
 )
 
-// Auth 验证 Bearer Token 的中间件。
+// 认证方式，写入上下文变量 auth_method。
+const (
+	AuthMethodJWT = "jwt"
+	AuthMethodPAT = "pat"
+)
+
+// Auth 验证 Bearer Token 的中间件，接受 JWT 访问令牌与个人访问令牌。
 func Auth(r *ghttp.Request) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-		r.Response.WriteStatus(http.StatusUnauthorized)
-		r.Response.ClearBuffer()
-		r.Response.WriteJson(g.Map{
-			"code":    40001,
-			"message": "未提供认证令牌",
-			"data":    nil,
-		})
-		r.ExitAll()
+		writeUnauthorized(r, 40001, "未提供认证令牌")
 		return
 	}
 
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if user.IsAccessToken(tokenString) {
+		authAccessToken(r, tokenString)
+		return
+	}
+
 	claims, err := appjwt.VerifyToken(tokenString)
 	if err != nil {
-		r.Response.WriteStatus(http.StatusUnauthorized)
-		r.Response.ClearBuffer()
-		r.Response.WriteJson(g.Map{
-			"code":    40002,
-			"message": "令牌无效或已过期",
-			"data":    nil,
-		})
-		r.ExitAll()
+		writeUnauthorized(r, 40002, "令牌无效或已过期")
 		return
 	}
 
 	if db := database.Default(); db != nil {
 		if count, _ := db.Model("refresh_tokens").Where("user_id", claims.UserID).Count(gctx.New()); count == 0 {
-			r.Response.WriteStatus(http.StatusUnauthorized)
-			r.Response.ClearBuffer()
-			r.Response.WriteJson(g.Map{
-				"code":    40002,
-				"message": "令牌无效或已过期",
-				"data":    nil,
-			})
-			r.ExitAll()
+			writeUnauthorized(r, 40002, "令牌无效或已过期")
 			return
 		}
 	}
 
 	r.SetCtxVar("user_id", claims.UserID)
+	r.SetCtxVar("auth_method", AuthMethodJWT)
+	r.Middleware.Next()
+}
+
+// authAccessToken 校验个人访问令牌并记录最近使用时间与 IP。
+func authAccessToken(r *ghttp.Request, raw string) {
+	db := database.Default()
+	if db == nil {
+		writeUnauthorized(r, 40002, "令牌无效或已过期")
+		return
+	}
+	repo := repository.NewAccessTokenRepository(db)
+	token, err := repo.FindAccessTokenByHash(r.GetCtx(), user.HashAccessToken(raw))
+	if err != nil {
+		g.Log().Error(r.GetCtx(), err)
+	}
+	now := time.Now()
+	if token == nil || !token.Active(now) {
+		writeUnauthorized(r, 40002, "令牌无效或已过期")
+		return
+	}
+	if err := repo.TouchAccessToken(r.GetCtx(), token.ID, r.GetClientIp(), now); err != nil {
+		g.Log().Warning(r.GetCtx(), err)
+	}
+
+	r.SetCtxVar("user_id", token.UserID)
+	r.SetCtxVar("auth_method", AuthMethodPAT)
+	r.SetCtxVar("token_scopes", token.Scopes)
 	r.Middleware.Next()
 }
+
+func writeUnauthorized(r *ghttp.Request, code int, message string) {
+	r.Response.WriteStatus(http.StatusUnauthorized)
+	r.Response.ClearBuffer()
+	r.Response.WriteJson(errorEnvelope(r, code, message))
+	r.ExitAll()
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// contentCacheControl 公开学习内容的缓存策略：过期后凭 ETag 重新验证
+const contentCacheControl = "public, max-age=300"
+
+// contentEncodings 支持的压缩编码，q 值相同时按此顺序优先
+var contentEncodings = []string{"br", "gzip"}
+
+// cachedResponse 一份预先生成的响应：原文与各压缩版本，ETag 按原文内容哈希生成。
+type cachedResponse struct {
+	contentType string
+	etag        string
+	variants    map[string][]byte // 编码 -> 响应体，identity 为原文
+}
+
+// NoStore 禁止客户端与中间代理缓存响应，用于包含用户数据或令牌的接口。
+func NoStore(r *ghttp.Request) {
+	r.Response.Header().Set("Cache-Control", "no-store")
+	r.Middleware.Next()
+}
+
+// ContentCache 缓存公开学习内容：cacheable 判定的路径在每种格式下首次请求时生成并保存响应，
+// 之后直接返回保存的内容，附带强 ETag 与 Cache-Control，If-None-Match 命中时返回 304，
+// 并按 Accept-Encoding 返回预先压缩的 br 或 gzip 版本。需注册在 Format 之后，以便按格式区分。
+func ContentCache(cacheable func(path string) bool) ghttp.HandlerFunc {
+	var entries sync.Map // 路径 + 格式 -> *cachedResponse
+	return func(r *ghttp.Request) {
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || !cacheable(r.URL.Path) {
+			r.Middleware.Next()
+			return
+		}
+
+		key := r.URL.Path + "?format=" + r.GetCtxVar("format").String()
+		value, ok := entries.Load(key)
+		if !ok {
+			r.Middleware.Next()
+			// 仅缓存成功响应，错误响应照常输出
+			if status := r.Response.Status; status != 0 && status != http.StatusOK {
+				return
+			}
+			value, _ = entries.LoadOrStore(key, newCachedResponse(r.Response.Header().Get("Content-Type"), r.Response.Buffer()))
+			r.Response.ClearBuffer()
+		}
+		value.(*cachedResponse).write(r)
+	}
+}
+
+func newCachedResponse(contentType string, body []byte) *cachedResponse {
+	sum := sha256.Sum256(body)
+	entry := &cachedResponse{
+		contentType: contentType,
+		etag:        hex.EncodeToString(sum[:16]),
+		variants:    map[string][]byte{"identity": bytes.Clone(body)},
+	}
+	for _, encoding := range contentEncodings {
+		// 压缩后不更小的内容直接返回原文
+		if compressed := compress(encoding, body); len(compressed) < len(body) {
+			entry.variants[encoding] = compressed
+		}
+	}
+	return entry
+}
+
+func compress(encoding string, body []byte) []byte {
+	var buf bytes.Buffer
+	switch encoding {
+	case "br":
+		w := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+		_, _ = w.Write(body)
+		_ = w.Close()
+	case "gzip":
+		w, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		_, _ = w.Write(body)
+		_ = w.Close()
+	}
+	return buf.Bytes()
+}
+
+// write 输出缓存的响应。不同编码的字节不同，各自使用独立的强 ETag。
+func (c *cachedResponse) write(r *ghttp.Request) {
+	encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), c.variants)
+	etag := `"` + c.etag + `"`
+	if encoding != "identity" {
+		etag = `"` + c.etag + "-" + encoding + `"`
+	}
+
+	header := r.Response.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", contentCacheControl)
+	header.Add("Vary", "Accept-Encoding")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		r.Response.WriteHeader(http.StatusNotModified)
+		return
+	}
+	header.Set("Content-Type", c.contentType)
+	if encoding != "identity" {
+		header.Set("Content-Encoding", encoding)
+	}
+	r.Response.Write(c.variants[encoding])
+}
+
+// negotiateEncoding 按 Accept-Encoding 的 q 值选择已有的压缩版本，没有可用编码时返回 identity。
+func negotiateEncoding(accept string, variants map[string][]byte) string {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		weights[coding] = q
+	}
+
+	best, bestQ := "identity", 0.0
+	for _, encoding := range contentEncodings {
+		if _, ok := variants[encoding]; !ok {
+			continue
+		}
+		q, ok := weights[encoding]
+		if !ok {
+			q = weights["*"]
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// etagMatches 按 If-None-Match 的弱比较规则判断客户端缓存是否仍然有效。
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestContentCache(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var calls atomic.Int32
+		body := strings.Repeat("Go 语言规范学习内容。", 200)
+
+		s := g.Server("test-content-cache")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+		s.Group("/api", func(group *ghttp.RouterGroup) {
+			group.Middleware(Format, NoStore)
+			group.Group("/", func(contentGroup *ghttp.RouterGroup) {
+				contentGroup.Middleware(ContentCache(func(path string) bool { return path != "/api/missing" }))
+				contentGroup.ALL("/content", func(r *ghttp.Request) {
+					calls.Add(1)
+					if r.GetCtxVar("format").String() == "html" {
+						r.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+						r.Response.Write("<p>" + body + "</p>")
+						return
+					}
+					r.Response.WriteJson(g.Map{"body": body})
+				})
+				contentGroup.GET("/missing", func(r *ghttp.Request) {
+					r.Response.WriteHeader(http.StatusNotFound)
+					r.Response.Write("missing")
+				})
+			})
+			group.GET("/profile", func(r *ghttp.Request) {
+				r.Response.WriteJson(g.Map{"user": 1})
+			})
+		})
+		s.Start()
+		defer s.Shutdown()
+
+		base := fmt.Sprintf("http://127.0.0.1:%d/api", s.GetListenedPort())
+		// 使用标准客户端并显式设置 Accept-Encoding，避免自动解压掩盖响应头
+		client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+		do := func(method, path string, header map[string]string) (*http.Response, []byte) {
+			req, err := http.NewRequest(method, base+path, nil)
+			t.AssertNil(err)
+			for k, v := range header {
+				req.Header.Set(k, v)
+			}
+			resp, err := client.Do(req)
+			t.AssertNil(err)
+			defer resp.Body.Close()
+			data, err := io.ReadAll(resp.Body)
+			t.AssertNil(err)
+			return resp, data
+		}
+
+		resp, plain := do(http.MethodGet, "/content", nil)
+		t.Assert(resp.StatusCode, 200)
+		t.AssertIN(body, string(plain))
+		t.Assert(resp.Header.Get("Cache-Control"), contentCacheControl)
+		t.AssertIN("Accept-Encoding", resp.Header.Values("Vary"))
+		t.Assert(resp.Header.Get("Content-Encoding"), "")
+		etag := resp.Header.Get("ETag")
+		t.Assert(strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`), true)
+
+		// 同一路径与格式只生成一次，ETag 稳定
+		resp, _ = do(http.MethodGet, "/content", nil)
+		t.Assert(resp.Header.Get("ETag"), etag)
+		t.Assert(calls.Load(), 1)
+
+		resp, data := do(http.MethodGet, "/content", map[string]string{"If-None-Match": `"other", W/` + etag})
+		t.Assert(resp.StatusCode, http.StatusNotModified)
+		t.Assert(len(data), 0)
+		t.Assert(resp.Header.Get("ETag"), etag)
+
+		// 预压缩版本使用各自的 ETag，解压后与原文一致
+		resp, data = do(http.MethodGet, "/content", map[string]string{"Accept-Encoding": "gzip, br"})
+		t.Assert(resp.Header.Get("Content-Encoding"), "br")
+		t.Assert(resp.Header.Get("ETag"), strings.TrimSuffix(etag, `"`)+`-br"`)
+		decoded, err := io.ReadAll(brotli.NewReader(bytes.NewReader(data)))
+		t.AssertNil(err)
+		t.Assert(bytes.Equal(decoded, plain), true)
+
+		resp, data = do(http.MethodGet, "/content", map[string]string{"Accept-Encoding": "br;q=0.5, gzip"})
+		t.Assert(resp.Header.Get("Content-Encoding"), "gzip")
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		t.AssertNil(err)
+		decoded, err = io.ReadAll(gz)
+		t.AssertNil(err)
+		t.Assert(bytes.Equal(decoded, plain), true)
+
+		resp, _ = do(http.MethodGet, "/content", map[string]string{"Accept-Encoding": "gzip", "If-None-Match": etag})
+		t.Assert(resp.StatusCode, 200)
+
+		// 不同格式分别缓存
+		resp, data = do(http.MethodGet, "/content?format=html", nil)
+		t.AssertIN("text/html", resp.Header.Get("Content-Type"))
+		t.Assert(strings.HasPrefix(string(data), "<p>"), true)
+		t.AssertNE(resp.Header.Get("ETag"), etag)
+		t.Assert(calls.Load(), 2)
+
+		// 非 GET 请求、错误响应与未声明可缓存的接口不缓存
+		resp, _ = do(http.MethodPost, "/content", nil)
+		t.Assert(resp.Header.Get("ETag"), "")
+		t.Assert(calls.Load(), 3)
+
+		resp, data = do(http.MethodGet, "/missing", nil)
+		t.Assert(resp.StatusCode, 404)
+		t.Assert(string(data), "missing")
+		t.Assert(resp.Header.Get("ETag"), "")
+
+		resp, _ = do(http.MethodGet, "/profile", map[string]string{"Accept-Encoding": "gzip"})
+		t.Assert(resp.Header.Get("Cache-Control"), "no-store")
+		t.Assert(resp.Header.Get("ETag"), "")
+		t.Assert(resp.Header.Get("Content-Encoding"), "")
+	})
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		variants := map[string][]byte{"identity": nil, "br": nil, "gzip": nil}
+		t.Assert(negotiateEncoding("", variants), "identity")
+		t.Assert(negotiateEncoding("gzip", variants), "gzip")
+		t.Assert(negotiateEncoding("gzip, deflate, br", variants), "br")
+		t.Assert(negotiateEncoding("br;q=0, gzip;q=0.1", variants), "gzip")
+		t.Assert(negotiateEncoding("*", variants), "br")
+		t.Assert(negotiateEncoding("*, br;q=0", variants), "gzip")
+		t.Assert(negotiateEncoding("br", map[string][]byte{"identity": nil}), "identity")
+	})
+}
@@ -6,7 +6,8 @@ import "github.com/gogf/gf/v2/net/ghttp"
 func Cors(r *ghttp.Request) {
 	r.Response.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
 	r.Response.Header().Set("Access-Control-Allow-Credentials", "true")
-	r.Response.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	r.Response.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+	r.Response.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 	r.Response.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 
 	if r.Method == "OPTIONS" {
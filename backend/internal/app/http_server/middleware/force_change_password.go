@@ -67,10 +67,6 @@ func allowWithoutChange(path string) bool {
 func writeNeedChangePassword(r *ghttp.Request) {
 	r.Response.WriteStatus(http.StatusForbidden)
 	r.Response.ClearBuffer()
-	r.Response.WriteJson(g.Map{
-		"code":    40011,
-		"message": "需要先修改密码",
-		"data":    nil,
-	})
+	r.Response.WriteJson(errorEnvelope(r, 40011, "需要先修改密码"))
 	r.ExitAll()
 }
@@ -1,25 +1,39 @@
 package middleware
 
 import (
+	"mime"
+	"strconv"
+	"strings"
+
 	"github.com/gogf/gf/v2/net/ghttp"
 )
 
+// formatMediaTypes 支持的响应格式及其对应的媒体类型
+var formatMediaTypes = []struct {
+	format     string
+	mediaTypes []string
+}{
+	{"json", []string{"application/json"}},
+	{"html", []string{"text/html", "application/xhtml+xml"}},
+	{"markdown", []string{"text/markdown", "text/x-markdown"}},
+	{"text", []string{"text/plain"}},
+}
+
 // Format 处理响应格式中间件
-// 验证 format 参数 (json/html)，默认为 json
+// 优先使用 format 参数 (json/html/markdown/text)，未提供时按 Accept 头协商，默认为 json
 // 将格式存储在上下文中供处理程序使用
 func Format(r *ghttp.Request) {
 	format := r.Get("format").String()
 
 	if format == "" {
-		format = "json"
+		format = negotiateFormat(r.Header.Get("Accept"))
+		// 响应随 Accept 变化，告知缓存按该请求头区分
+		r.Response.Header().Add("Vary", "Accept")
 	}
 
-	if format != "json" && format != "html" {
-		r.Response.WriteStatus(400)
-		r.Response.WriteJson(map[string]interface{}{
-			"code":    400,
-			"message": "Invalid format parameter. Supported values: json, html",
-		})
+	if !isSupportedFormat(format) {
+		r.Response.WriteHeader(400)
+		r.Response.WriteJson(errorEnvelope(r, 400, "Invalid format parameter. Supported values: json, html, markdown, text"))
 		r.Exit() // 停止后续处理
 		return
 	}
@@ -29,3 +43,45 @@ func Format(r *ghttp.Request) {
 
 	r.Middleware.Next()
 }
+
+func isSupportedFormat(format string) bool {
+	for _, f := range formatMediaTypes {
+		if f.format == format {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateFormat 按 Accept 头的 q 值选择格式，q 值相同时取头中靠前的媒体类型；
+// 通配符与不支持的媒体类型不参与选择，无可用项时返回 json。
+func negotiateFormat(accept string) string {
+	best, bestQ := "json", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if format := formatOf(mediaType); format != "" && q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	return best
+}
+
+func formatOf(mediaType string) string {
+	for _, f := range formatMediaTypes {
+		for _, mt := range f.mediaTypes {
+			if mt == mediaType {
+				return f.format
+			}
+		}
+	}
+	return ""
+}
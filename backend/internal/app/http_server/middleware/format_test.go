@@ -187,3 +187,66 @@ func TestFormat_EmptyFormat(t *testing.T) {
 		t.Assert(result["format"], "json")
 	})
 }
+
+func TestFormat_MarkdownAndAccept(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		s := g.Server("test-format-negotiate")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+
+		s.Group("/test", func(group *ghttp.RouterGroup) {
+			group.Middleware(Format)
+			group.GET("/data", func(r *ghttp.Request) {
+				r.Response.Write(r.GetCtxVar("format").String())
+			})
+		})
+
+		s.Start()
+		defer s.Shutdown()
+
+		port := s.GetListenedPort()
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", port))
+
+		for _, format := range []string{"markdown", "text"} {
+			resp, err := client.Get(nil, "/test/data?format="+format)
+			t.AssertNil(err)
+			t.Assert(resp.StatusCode, 200)
+			t.Assert(resp.ReadAllString(), format)
+			resp.Close()
+		}
+
+		// format 参数优先于 Accept 头
+		resp, err := client.Header(map[string]string{"Accept": "text/markdown"}).Get(nil, "/test/data?format=json")
+		t.AssertNil(err)
+		t.Assert(resp.ReadAllString(), "json")
+		resp.Close()
+
+		resp, err = client.Header(map[string]string{"Accept": "text/plain;q=0.5, text/markdown;q=0.9"}).Get(nil, "/test/data")
+		t.AssertNil(err)
+		t.Assert(resp.ReadAllString(), "markdown")
+		t.AssertIN("Accept", resp.Header.Get("Vary"))
+		resp.Close()
+	})
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		cases := map[string]string{
+			"":                                  "json",
+			"*/*":                               "json",
+			"image/png":                         "json",
+			"application/json, text/plain, */*": "json",
+			"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": "html",
+			"text/markdown":                         "markdown",
+			"text/x-markdown; charset=utf-8":        "markdown",
+			"text/plain":                            "text",
+			"text/html;q=0.2, text/plain":           "text",
+			"text/markdown;q=0, text/html;q=0.1":    "html",
+			"text/plain;q=abc, text/markdown;q=0.3": "markdown",
+		}
+		for accept, want := range cases {
+			t.Assert(negotiateFormat(accept), want)
+		}
+	})
+}
@@ -3,11 +3,13 @@ package middleware
 import (
 	"time"
 
+	"go-study2/internal/infrastructure/logging"
+
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
 )
 
-// Logger 记录请求日志中间件
+// Logger 记录请求日志中间件，字段以 logging.Fields 传入，JSON 格式下可直接按字段检索
 func Logger(r *ghttp.Request) {
 	ctx := r.Context()
 	startTime := time.Now()
@@ -15,16 +17,13 @@ func Logger(r *ghttp.Request) {
 	// 继续执行后续处理
 	r.Middleware.Next()
 
-	// 记录请求耗时和状态
-	duration := time.Since(startTime)
-	status := r.Response.Status
-
-	g.Log().Infof(ctx,
-		"Status: %d | Method: %s | URL: %s | ClientIP: %s | Duration: %v",
-		status,
-		r.Method,
-		r.URL.String(),
-		r.GetClientIp(),
-		duration,
-	)
+	// 记录请求耗时和状态；请求 ID 与追踪 ID 由日志处理器从 context 中补充
+	g.Log().Info(ctx, "http request", logging.Fields{
+		"status":      responseStatus(r),
+		"method":      r.Method,
+		"path":        r.URL.Path,
+		"route":       requestRoute(r),
+		"client_ip":   r.GetClientIp(),
+		"duration_ms": time.Since(startTime).Milliseconds(),
+	})
 }
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-study2/internal/pkg/metrics"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+var (
+	httpRequests = metrics.Default.NewCounter("gostudy_http_requests_total",
+		"HTTP 请求数，按方法、路由模板与状态码区分", "method", "route", "status")
+	httpDuration = metrics.Default.NewHistogram("gostudy_http_request_duration_seconds",
+		"HTTP 请求耗时（秒），按方法、路由模板与状态码区分", metrics.DefaultBuckets, "method", "route", "status")
+)
+
+// activeUserWindows 活跃用户统计的时间窗口，标签值按 Prometheus 时长写法
+var activeUserWindows = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+}
+
+// activeUsers 记录各认证用户最近一次请求的时间，超出最大窗口的记录在采集时清理。
+var activeUsers = &activeUserTracker{lastSeen: make(map[int64]time.Time), now: time.Now}
+
+func init() {
+	metrics.Default.NewGaugeFunc("gostudy_active_users", "时间窗口内发起过认证请求的用户数", activeUsers.samples, "window")
+}
+
+type activeUserTracker struct {
+	mu       sync.Mutex
+	lastSeen map[int64]time.Time
+	now      func() time.Time
+}
+
+func (t *activeUserTracker) seen(userID int64) {
+	t.mu.Lock()
+	t.lastSeen[userID] = t.now()
+	t.mu.Unlock()
+}
+
+func (t *activeUserTracker) samples() []metrics.Sample {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	counts := make([]int, len(activeUserWindows))
+	for userID, at := range t.lastSeen {
+		age := now.Sub(at)
+		if age > activeUserWindows[len(activeUserWindows)-1].duration {
+			delete(t.lastSeen, userID)
+			continue
+		}
+		for i, w := range activeUserWindows {
+			if age <= w.duration {
+				counts[i]++
+			}
+		}
+	}
+	samples := make([]metrics.Sample, len(activeUserWindows))
+	for i, w := range activeUserWindows {
+		samples[i] = metrics.Sample{LabelValues: []string{w.label}, Value: float64(counts[i])}
+	}
+	return samples
+}
+
+// Metrics 统计请求数与耗时，路由取匹配到的路由模板而非原始 URL，避免路径参数导致标签无限增长；
+// 同时记录认证用户的活跃时间。
+func Metrics(r *ghttp.Request) {
+	start := time.Now()
+	r.Middleware.Next()
+
+	labels := []string{metricsMethod(r.Method), requestRoute(r), strconv.Itoa(responseStatus(r))}
+	httpRequests.With(labels...).Inc()
+	httpDuration.With(labels...).Observe(time.Since(start).Seconds())
+
+	if userID := r.GetCtxVar("user_id").Int64(); userID > 0 {
+		activeUsers.seen(userID)
+	}
+}
+
+// requestRoute 返回请求匹配到的路由模板，静态文件为 static，未匹配为 unmatched。
+// r.Router 在未匹配时会停留在全局中间件的 /*，因此取业务处理函数的路由。
+func requestRoute(r *ghttp.Request) string {
+	switch handler := r.GetServeHandler(); {
+	case handler != nil && handler.Handler.Router != nil:
+		return handler.Handler.Router.Uri
+	case r.StaticFile != nil:
+		return "static"
+	}
+	return "unmatched"
+}
+
+// responseStatus 返回响应状态码，未显式设置时为 200。
+func responseStatus(r *ghttp.Request) int {
+	if r.Response.Status == 0 {
+		return http.StatusOK
+	}
+	return r.Response.Status
+}
+
+// metricsMethod 把非标准方法归为 OTHER。
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
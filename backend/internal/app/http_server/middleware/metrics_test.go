@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestMetrics(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		s := g.Server("test-metrics")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+		s.Use(Metrics)
+		s.Group("/", func(group *ghttp.RouterGroup) {
+			group.GET("/items/:id", func(r *ghttp.Request) { r.Response.Write("ok") })
+			group.POST("/items", func(r *ghttp.Request) { r.Response.WriteStatus(http.StatusCreated) })
+		})
+		s.Start()
+		defer s.Shutdown()
+
+		value := func(method, route, status string) float64 { return httpRequests.With(method, route, status).Value() }
+		items, created, missing := value("GET", "/items/:id", "200"), value("POST", "/items", "201"), value("GET", "unmatched", "404")
+
+		base := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+		client := g.Client()
+		for _, id := range []string{"1", "2"} {
+			resp, err := client.Get(nil, base+"/items/"+id)
+			t.AssertNil(err)
+			resp.Close()
+		}
+		resp, err := client.Post(nil, base+"/items")
+		t.AssertNil(err)
+		resp.Close()
+		resp, err = client.Get(nil, base+"/nowhere")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, http.StatusNotFound)
+		resp.Close()
+
+		// 路径参数不进入标签，两次请求归入同一路由模板
+		t.Assert(value("GET", "/items/:id", "200"), items+2)
+		t.Assert(value("POST", "/items", "201"), created+1)
+		t.Assert(value("GET", "unmatched", "404"), missing+1)
+		t.Assert(httpDuration.With("GET", "/items/:id", "200").Count() >= 2, true)
+	})
+}
+
+func TestActiveUserTracker(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		tracker := &activeUserTracker{lastSeen: make(map[int64]time.Time), now: func() time.Time { return now }}
+
+		tracker.seen(1)
+		now = now.Add(50 * time.Minute)
+		tracker.seen(2)
+		now = now.Add(2 * time.Minute)
+		tracker.seen(3)
+		tracker.seen(3)
+
+		counts := func() map[string]float64 {
+			m := make(map[string]float64)
+			for _, s := range tracker.samples() {
+				m[s.LabelValues[0]] = s.Value
+			}
+			return m
+		}
+		t.Assert(counts(), map[string]float64{"5m": 2, "1h": 3, "24h": 3})
+
+		// 超过最大窗口的用户在采集时清理
+		now = now.Add(25 * time.Hour)
+		tracker.seen(4)
+		t.Assert(counts(), map[string]float64{"5m": 1, "1h": 1, "24h": 1})
+		t.Assert(len(tracker.lastSeen), 1)
+	})
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/pkg/ratelimit"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+var (
+	rateLimiterMu sync.RWMutex
+	rateLimiter   = ratelimit.New(ratelimit.NewMemoryStore())
+)
+
+// SetRateLimiter 替换限流器，用于接入多实例共享的存储或在测试中注入时钟。
+func SetRateLimiter(l *ratelimit.Limiter) {
+	rateLimiterMu.Lock()
+	defer rateLimiterMu.Unlock()
+	rateLimiter = l
+}
+
+func currentRateLimiter() *ratelimit.Limiter {
+	rateLimiterMu.RLock()
+	defer rateLimiterMu.RUnlock()
+	return rateLimiter
+}
+
+// RateLimit 按 rateLimit.policies 中名为 policy 的令牌桶策略限流，未启用或未配置该策略时直接放行。
+// 按用户计数的策略需注册在 Auth 之后，未认证的请求按客户端 IP 计数。
+// 响应附带 RateLimit-Limit、RateLimit-Remaining 与 RateLimit-Reset，超出时返回 429 与 Retry-After。
+func RateLimit(policy string) ghttp.HandlerFunc {
+	return func(r *ghttp.Request) {
+		cfg := config.Default()
+		if cfg == nil || !cfg.RateLimit.Enabled {
+			r.Middleware.Next()
+			return
+		}
+		pc, ok := cfg.RateLimit.Policies[policy]
+		p := ratelimit.Policy{Requests: pc.Requests, Period: time.Duration(pc.PeriodSeconds) * time.Second, Burst: pc.Burst}
+		if !ok || !p.Valid() {
+			r.Middleware.Next()
+			return
+		}
+
+		res := currentRateLimiter().Allow(policy+":"+rateLimitKey(r, pc.Key, cfg.RateLimit.TrustedProxies), p)
+		header := r.Response.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+		if !res.Allowed {
+			header.Set("Retry-After", ceilSeconds(res.RetryAfter))
+			r.Response.WriteHeader(http.StatusTooManyRequests)
+			r.Response.ClearBuffer()
+			r.Response.WriteJson(errorEnvelope(r, 40045, "请求过于频繁，请稍后再试"))
+			r.ExitAll()
+			return
+		}
+		r.Middleware.Next()
+	}
+}
+
+// rateLimitKey 返回计数维度：认证用户或客户端 IP。
+func rateLimitKey(r *ghttp.Request, key string, trustedProxies []string) string {
+	if key == "user" {
+		if userID := r.GetCtxVar("user_id").Int64(); userID > 0 {
+			return "user:" + strconv.FormatInt(userID, 10)
+		}
+	}
+	return "ip:" + rateLimitClientIP(r, trustedProxies)
+}
+
+// rateLimitClientIP 默认使用直连对端地址；转发头可被客户端任意伪造，只有对端是可信代理时才从
+// X-Forwarded-For 末尾向前跳过可信代理，取第一个不可信的地址作为客户端 IP。
+func rateLimitClientIP(r *ghttp.Request, trustedProxies []string) string {
+	remote := r.GetRemoteIp()
+	if !isTrustedProxy(remote, trustedProxies) {
+		return remote
+	}
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		if !isTrustedProxy(hop, trustedProxies) {
+			return hop
+		}
+	}
+	return remote
+}
+
+// isTrustedProxy 判断 ip 是否命中可信代理列表中的地址或网段。
+func isTrustedProxy(ip string, trustedProxies []string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range trustedProxies {
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if proxy := net.ParseIP(entry); proxy != nil && proxy.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ceilSeconds 把时长向上取整为秒，用于 RateLimit-Reset 与 Retry-After。
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
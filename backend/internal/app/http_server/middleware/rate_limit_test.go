@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/pkg/ratelimit"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestRateLimit(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		previous := config.Default()
+		defer config.SetDefault(previous)
+		config.SetDefault(&config.Config{RateLimit: config.RateLimitConfig{
+			Enabled: true,
+			Policies: map[string]config.RateLimitPolicy{
+				"auth": {Requests: 1, PeriodSeconds: 30, Burst: 2, Key: "ip"},
+				"quiz": {Requests: 60, PeriodSeconds: 60, Burst: 1, Key: "user"},
+			},
+		}})
+		// 请求在服务端协程中读取时钟，使用原子值推进
+		var now atomic.Int64
+		now.Store(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC).UnixNano())
+		SetRateLimiter(ratelimit.New(ratelimit.NewMemoryStore()).WithClock(func() time.Time { return time.Unix(0, now.Load()) }))
+		defer SetRateLimiter(ratelimit.New(ratelimit.NewMemoryStore()))
+
+		s := g.Server("test-rate-limit")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+		s.Group("/", func(group *ghttp.RouterGroup) {
+			group.Group("/", func(authGroup *ghttp.RouterGroup) {
+				authGroup.Middleware(RateLimit("auth"))
+				authGroup.POST("/login", func(r *ghttp.Request) { r.Response.WriteJson(g.Map{"code": 20000}) })
+			})
+			group.Group("/", func(quizGroup *ghttp.RouterGroup) {
+				// 模拟 Auth：按请求头设置当前用户
+				quizGroup.Middleware(func(r *ghttp.Request) {
+					if uid := r.Header.Get("X-User"); uid != "" {
+						r.SetCtxVar("user_id", uid)
+					}
+					r.Middleware.Next()
+				}, RateLimit("quiz"))
+				quizGroup.POST("/submit", func(r *ghttp.Request) { r.Response.WriteJson(g.Map{"code": 20000}) })
+			})
+			group.Group("/", func(openGroup *ghttp.RouterGroup) {
+				openGroup.Middleware(RateLimit("missing"))
+				openGroup.GET("/open", func(r *ghttp.Request) { r.Response.Write("ok") })
+			})
+		})
+		s.Start()
+		defer s.Shutdown()
+
+		base := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+		post := func(path, user string) *http.Response {
+			req, err := http.NewRequest(http.MethodPost, base+path, nil)
+			t.AssertNil(err)
+			if user != "" {
+				req.Header.Set("X-User", user)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			t.AssertNil(err)
+			return resp
+		}
+		status := func(path, user string) int {
+			resp := post(path, user)
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		resp := post("/login", "")
+		resp.Body.Close()
+		t.Assert(resp.StatusCode, 200)
+		t.Assert(resp.Header.Get("RateLimit-Limit"), "2")
+		t.Assert(resp.Header.Get("RateLimit-Remaining"), "1")
+		t.Assert(resp.Header.Get("RateLimit-Reset"), "30")
+		t.Assert(status("/login", ""), 200)
+
+		resp = post("/login", "")
+		t.Assert(resp.StatusCode, http.StatusTooManyRequests)
+		t.Assert(resp.Header.Get("Retry-After"), "30")
+		t.Assert(resp.Header.Get("RateLimit-Remaining"), "0")
+		var body struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		}
+		t.AssertNil(json.NewDecoder(resp.Body).Decode(&body))
+		resp.Body.Close()
+		t.Assert(body.Code, 40045)
+		t.AssertNE(body.Message, "")
+		t.Assert(string(body.Data), "null")
+
+		// 未配置可信代理时伪造的转发头不会换来新的令牌桶
+		for _, forwarded := range []string{"203.0.113.7", "198.51.100.1, 203.0.113.8"} {
+			req, err := http.NewRequest(http.MethodPost, base+"/login", nil)
+			t.AssertNil(err)
+			req.Header.Set("X-Forwarded-For", forwarded)
+			req.Header.Set("X-Real-IP", forwarded)
+			spoofed, err := http.DefaultClient.Do(req)
+			t.AssertNil(err)
+			spoofed.Body.Close()
+			t.Assert(spoofed.StatusCode, http.StatusTooManyRequests)
+		}
+
+		// 时间推进后补充令牌
+		now.Add(int64(20 * time.Second))
+		resp = post("/login", "")
+		resp.Body.Close()
+		t.Assert(resp.Header.Get("Retry-After"), "10")
+		now.Add(int64(10 * time.Second))
+		resp = post("/login", "")
+		resp.Body.Close()
+		t.Assert(resp.StatusCode, 200)
+
+		// 按用户计数：不同用户各自独立，未认证请求按 IP 计数
+		t.Assert(status("/submit", "1"), 200)
+		t.Assert(status("/submit", "1"), http.StatusTooManyRequests)
+		t.Assert(status("/submit", "2"), 200)
+		t.Assert(status("/submit", ""), 200)
+		t.Assert(status("/submit", ""), http.StatusTooManyRequests)
+
+		// 未配置的策略不限流
+		for i := 0; i < 3; i++ {
+			resp, err := http.Get(base + "/open")
+			t.AssertNil(err)
+			resp.Body.Close()
+			t.Assert(resp.StatusCode, 200)
+			t.Assert(resp.Header.Get("RateLimit-Limit"), "")
+		}
+	})
+}
+
+func TestRateLimitClientIP(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		s := g.Server("test-rate-limit-client-ip")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+		var trusted []string
+		s.BindHandler("/ip", func(r *ghttp.Request) {
+			r.Response.Write(rateLimitClientIP(r, trusted))
+		})
+		s.Start()
+		defer s.Shutdown()
+
+		get := func(forwarded string) string {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:%d/ip", s.GetListenedPort()), nil)
+			t.AssertNil(err)
+			if forwarded != "" {
+				req.Header.Set("X-Forwarded-For", forwarded)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			t.AssertNil(err)
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			return string(body)
+		}
+
+		// 对端不是可信代理时忽略转发头
+		t.Assert(get("203.0.113.7"), "127.0.0.1")
+
+		// 对端可信时从右向左跳过可信代理，客户端在左侧伪造的地址不生效
+		trusted = []string{"127.0.0.1", "10.0.0.0/8"}
+		t.Assert(get("203.0.113.7"), "203.0.113.7")
+		t.Assert(get("198.51.100.1, 203.0.113.7, 10.1.2.3"), "203.0.113.7")
+		t.Assert(get("10.1.2.3"), "127.0.0.1")
+		t.Assert(get("not-an-ip"), "127.0.0.1")
+		t.Assert(get(""), "127.0.0.1")
+	})
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"go-study2/internal/pkg/requestid"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// RequestID 为每个请求确定请求 ID：沿用客户端传入的合法 X-Request-ID，否则生成新值。
+// 请求 ID 写入 context 与响应头，供日志、审计事件与错误响应关联同一请求。
+func RequestID(r *ghttp.Request) {
+	id := r.Header.Get(requestid.Header)
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+	r.SetCtx(requestid.NewContext(r.Context(), id))
+	r.Response.Header().Set(requestid.Header, id)
+	r.Middleware.Next()
+}
+
+// errorEnvelope 构造中间件拒绝请求时的统一响应体，附带请求 ID 便于排查。
+func errorEnvelope(r *ghttp.Request, code int, message string) g.Map {
+	body := g.Map{
+		"code":    code,
+		"message": message,
+		"data":    nil,
+	}
+	if id := requestid.FromContext(r.Context()); id != "" {
+		body["requestId"] = id
+	}
+	return body
+}
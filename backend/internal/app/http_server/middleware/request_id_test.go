@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-study2/internal/pkg/requestid"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestRequestID(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		s := g.Server("test-request-id")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+		s.Use(RequestID)
+		s.Group("/", func(group *ghttp.RouterGroup) {
+			group.GET("/echo", func(r *ghttp.Request) { r.Response.Write(requestid.FromContext(r.Context())) })
+			group.Group("/", func(authGroup *ghttp.RouterGroup) {
+				authGroup.Middleware(Auth)
+				authGroup.GET("/private", func(r *ghttp.Request) { r.Response.Write("ok") })
+			})
+		})
+		s.Start()
+		defer s.Shutdown()
+
+		ctx := gctx.New()
+		base := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+		get := func(path, id string) (*http.Response, string) {
+			client := g.Client()
+			if id != "" {
+				client.SetHeader(requestid.Header, id)
+			}
+			resp, err := client.Get(ctx, base+path)
+			t.AssertNil(err)
+			defer resp.Close()
+			return resp.Response, resp.ReadAllString()
+		}
+
+		// 未携带时生成，处理函数从 context 读取到的与响应头一致
+		resp, body := get("/echo", "")
+		generated := resp.Header.Get(requestid.Header)
+		t.Assert(len(generated), 32)
+		t.Assert(body, generated)
+
+		// 合法的请求 ID 原样沿用
+		resp, body = get("/echo", "client-id:42")
+		t.Assert(resp.Header.Get(requestid.Header), "client-id:42")
+		t.Assert(body, "client-id:42")
+
+		// 非法的请求 ID 被替换，避免把任意内容写入日志
+		resp, body = get("/echo", "bad id")
+		t.AssertNE(resp.Header.Get(requestid.Header), "bad id")
+		t.Assert(requestid.Valid(resp.Header.Get(requestid.Header)), true)
+		t.Assert(body, resp.Header.Get(requestid.Header))
+
+		// 中间件拒绝请求时，错误响应体携带同一请求 ID
+		resp, body = get("/private", "trace-me")
+		t.Assert(resp.StatusCode, http.StatusUnauthorized)
+		var envelope struct {
+			Code      int    `json:"code"`
+			RequestID string `json:"requestId"`
+		}
+		t.AssertNil(json.Unmarshal([]byte(body), &envelope))
+		t.Assert(envelope.Code, 40001)
+		t.Assert(envelope.RequestID, "trace-me")
+	})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/repository"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// RequireAdmin 仅允许管理员访问，需挂在 Auth 之后。
+func RequireAdmin(r *ghttp.Request) {
+	userID := r.GetCtxVar("user_id").Int64()
+	db := database.Default()
+	if userID <= 0 || db == nil {
+		writeAdminRequired(r)
+		return
+	}
+
+	user, err := repository.NewUserRepository(db).FindByID(r.GetCtx(), userID)
+	if err != nil {
+		g.Log().Error(r.GetCtx(), err)
+	}
+	if user == nil || !user.IsAdmin {
+		audit.Record(r.GetCtx(), "admin_access_denied", userID, "permission_denied", r.Method+" "+r.URL.Path)
+		writeAdminRequired(r)
+		return
+	}
+
+	r.Middleware.Next()
+}
+
+func writeAdminRequired(r *ghttp.Request) {
+	r.Response.WriteStatus(http.StatusForbidden)
+	r.Response.ClearBuffer()
+	r.Response.WriteJson(errorEnvelope(r, 40010, "需要管理员权限"))
+	r.ExitAll()
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go-study2/internal/config"
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/repository"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+// RequireMFASetup 在策略要求管理员启用两步验证时，拦截尚未绑定的管理员，仅放行绑定与账号相关接口。
+func RequireMFASetup(r *ghttp.Request) {
+	cfg := config.Default()
+	if cfg == nil || !cfg.Auth.Mfa.RequireForAdmin {
+		r.Middleware.Next()
+		return
+	}
+
+	userID := r.GetCtxVar("user_id").Int64()
+	if userID <= 0 || allowWithoutMFA(r.URL.Path) {
+		r.Middleware.Next()
+		return
+	}
+
+	db := database.Default()
+	if db == nil {
+		r.Middleware.Next()
+		return
+	}
+
+	account, err := repository.NewUserRepository(db).FindByID(gctx.New(), userID)
+	if err != nil {
+		g.Log().Error(gctx.New(), err)
+		writeNeedMFASetup(r)
+		return
+	}
+	if account == nil || !account.IsAdmin {
+		r.Middleware.Next()
+		return
+	}
+
+	settings, err := repository.NewMFARepository(db).FindMFA(gctx.New(), userID)
+	if err != nil {
+		g.Log().Error(gctx.New(), err)
+		writeNeedMFASetup(r)
+		return
+	}
+	if settings == nil || !settings.Enabled {
+		audit.Record(r.GetCtx(), "access_blocked_need_mfa", userID, "blocked", r.URL.Path)
+		writeNeedMFASetup(r)
+		return
+	}
+
+	r.Middleware.Next()
+}
+
+func allowWithoutMFA(path string) bool {
+	if path == "/api/v1/auth/mfa" || strings.HasPrefix(path, "/api/v1/auth/mfa/") {
+		return true
+	}
+	return allowWithoutChange(path)
+}
+
+func writeNeedMFASetup(r *ghttp.Request) {
+	r.Response.WriteStatus(http.StatusForbidden)
+	r.Response.ClearBuffer()
+	r.Response.WriteJson(errorEnvelope(r, 40012, "需要先启用两步验证"))
+	r.ExitAll()
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/repository"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// RequireTeacher 仅允许教师或管理员访问，需挂在 Auth 之后。
+func RequireTeacher(r *ghttp.Request) {
+	userID := r.GetCtxVar("user_id").Int64()
+	db := database.Default()
+	if userID <= 0 || db == nil {
+		writeTeacherRequired(r)
+		return
+	}
+
+	user, err := repository.NewUserRepository(db).FindByID(r.GetCtx(), userID)
+	if err != nil {
+		g.Log().Error(r.GetCtx(), err)
+	}
+	if user == nil || (!user.IsAdmin && !user.IsTeacher) {
+		audit.Record(r.GetCtx(), "teacher_access_denied", userID, "permission_denied", r.Method+" "+r.URL.Path)
+		writeTeacherRequired(r)
+		return
+	}
+
+	r.Middleware.Next()
+}
+
+func writeTeacherRequired(r *ghttp.Request) {
+	r.Response.WriteStatus(http.StatusForbidden)
+	r.Response.ClearBuffer()
+	r.Response.WriteJson(errorEnvelope(r, 40031, "需要教师或管理员权限"))
+	r.ExitAll()
+}
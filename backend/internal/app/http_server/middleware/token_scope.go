@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go-study2/internal/domain/user"
+	"go-study2/internal/infrastructure/audit"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+type scopeRule struct {
+	method string
+	prefix string
+	scope  string
+}
+
+// tokenScopeRules 声明个人访问令牌可访问的接口；未列出的接口一律拒绝。
+var tokenScopeRules = []scopeRule{
+	{method: http.MethodGet, prefix: "/api/v1/progress", scope: user.ScopeProgressRead},
+	{method: http.MethodPost, prefix: "/api/v1/progress", scope: user.ScopeProgressWrite},
+	{method: http.MethodGet, prefix: "/api/v1/quiz", scope: user.ScopeQuizRead},
+	{method: http.MethodPost, prefix: "/api/v1/quiz", scope: user.ScopeQuizWrite},
+	{method: http.MethodGet, prefix: "/api/v1/leaderboards", scope: user.ScopeQuizRead},
+	{method: http.MethodGet, prefix: "/api/v1/topics", scope: user.ScopeContentRead},
+	{method: http.MethodGet, prefix: "/api/v1/topic", scope: user.ScopeContentRead},
+	{method: http.MethodGet, prefix: "/api/v1/classes", scope: user.ScopeClassesRead},
+	{method: http.MethodGet, prefix: "/api/v1/assignments", scope: user.ScopeClassesRead},
+	{method: http.MethodGet, prefix: "/api/v1/notes", scope: user.ScopeNotesRead},
+	{method: http.MethodPost, prefix: "/api/v1/notes", scope: user.ScopeNotesWrite},
+	{method: http.MethodPut, prefix: "/api/v1/notes", scope: user.ScopeNotesWrite},
+	{method: http.MethodDelete, prefix: "/api/v1/notes", scope: user.ScopeNotesWrite},
+}
+
+// TokenScope 限制个人访问令牌只能访问其权限范围内的接口，JWT 请求不受影响。
+func TokenScope(r *ghttp.Request) {
+	if r.GetCtxVar("auth_method").String() != AuthMethodPAT {
+		r.Middleware.Next()
+		return
+	}
+
+	required := requiredScope(r.Method, r.URL.Path)
+	if required != "" {
+		for _, scope := range r.GetCtxVar("token_scopes").Strings() {
+			if scope == required {
+				r.Middleware.Next()
+				return
+			}
+		}
+	}
+
+	audit.RecordFields(r.GetCtx(), "access_token_scope_denied", r.GetCtxVar("user_id").Int64(), "blocked", "", audit.Fields{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	})
+	r.Response.WriteStatus(http.StatusForbidden)
+	r.Response.ClearBuffer()
+	r.Response.WriteJson(errorEnvelope(r, 40021, "访问令牌权限不足"))
+	r.ExitAll()
+}
+
+func requiredScope(method, path string) string {
+	for _, rule := range tokenScopeRules {
+		if rule.method != method {
+			continue
+		}
+		if path == rule.prefix || strings.HasPrefix(path, rule.prefix+"/") {
+			return rule.scope
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"go-study2/internal/domain/user"
+)
+
+func TestRequiredScope(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/api/v1/progress", user.ScopeProgressRead},
+		{http.MethodGet, "/api/v1/progress/variables", user.ScopeProgressRead},
+		{http.MethodPost, "/api/v1/progress", user.ScopeProgressWrite},
+		{http.MethodGet, "/api/v1/quiz/history", user.ScopeQuizRead},
+		{http.MethodPost, "/api/v1/quiz/submit", user.ScopeQuizWrite},
+		{http.MethodGet, "/api/v1/topics", user.ScopeContentRead},
+		{http.MethodGet, "/api/v1/progressive", ""},
+		{http.MethodGet, "/api/v1/auth/tokens", ""},
+		{http.MethodPost, "/api/v1/auth/change-password", ""},
+		{http.MethodDelete, "/api/v1/progress", ""},
+	}
+	for _, c := range cases {
+		if got := requiredScope(c.method, c.path); got != c.want {
+			t.Fatalf("%s %s 期望权限 %q，得到 %q", c.method, c.path, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-study2/internal/infrastructure/tracing"
+	"go-study2/internal/pkg/requestid"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// untracedPaths 探针与指标抓取请求频繁且没有业务含义，不生成 span。
+var untracedPaths = map[string]struct{}{
+	"/metrics": {},
+	"/healthz": {},
+	"/readyz":  {},
+}
+
+// Tracing 为每个请求创建服务端 span：沿用请求头中的 W3C traceparent，span 名为“方法 路由模板”，
+// 只记录方法、路由、状态码与请求 ID，不记录请求头与查询参数。5xx 响应标记为错误。
+func Tracing(r *ghttp.Request) {
+	if _, skip := untracedPaths[r.URL.Path]; skip {
+		r.Middleware.Next()
+		return
+	}
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := tracing.Tracer().Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+	r.SetCtx(ctx)
+
+	r.Middleware.Next()
+
+	route, status := requestRoute(r), responseStatus(r)
+	span.SetName(r.Method + " " + route)
+	span.SetAttributes(
+		attribute.String("http.request.method", r.Method),
+		attribute.String("http.route", route),
+		attribute.Int("http.response.status_code", status),
+		attribute.String("request.id", requestid.FromContext(ctx)),
+	)
+	if err := r.GetError(); err != nil {
+		span.RecordError(err)
+	}
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/infrastructure/tracing"
+	"go-study2/internal/pkg/requestid"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		previous, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+		exporter := tracetest.NewInMemoryExporter()
+		tp := tracing.NewProvider(exporter, config.TracingConfig{})
+		tracing.Install(tp)
+		defer func() {
+			_ = tp.Shutdown(context.Background())
+			otel.SetTracerProvider(previous)
+			otel.SetTextMapPropagator(propagator)
+		}()
+
+		s := g.Server("test-tracing")
+		s.SetPort(0)
+		s.SetAccessLogEnabled(false)
+		s.Use(RequestID, Tracing)
+		s.Group("/", func(group *ghttp.RouterGroup) {
+			group.GET("/items/:id", func(r *ghttp.Request) {
+				_, span := tracing.Start(r.Context(), "items.Service.Get")
+				span.End()
+				r.Response.Write("ok")
+			})
+			group.GET("/boom", func(r *ghttp.Request) { r.Response.WriteStatus(http.StatusInternalServerError) })
+			group.GET("/healthz", func(r *ghttp.Request) { r.Response.Write("ok") })
+		})
+		s.Start()
+		defer s.Shutdown()
+
+		// 客户端会按 context 注入 traceparent，这里使用不带 span 的 context 以便手动指定
+		ctx := context.Background()
+		base := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+		get := func(path string, headers map[string]string) {
+			resp, err := g.Client().Header(headers).Get(ctx, base+path)
+			t.AssertNil(err)
+			resp.Close()
+		}
+		spans := func() tracetest.SpanStubs {
+			_ = tp.ForceFlush(context.Background())
+			got := exporter.GetSpans()
+			exporter.Reset()
+			return got
+		}
+
+		// 沿用上游 traceparent，span 名使用路由模板，领域 span 挂在请求 span 之下
+		parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		get("/items/42", map[string]string{"traceparent": parent, requestid.Header: "req-42"})
+		got := spans()
+		t.Assert(len(got), 2)
+		service, server := got[0], got[1]
+		t.Assert(server.Name, "GET /items/:id")
+		t.Assert(server.SpanKind, trace.SpanKindServer)
+		t.Assert(server.SpanContext.TraceID().String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+		t.Assert(server.Parent.SpanID().String(), "00f067aa0ba902b7")
+		t.Assert(server.Parent.IsRemote(), true)
+		attrs := attribute.NewSet(server.Attributes...)
+		for key, want := range map[attribute.Key]string{
+			"http.request.method": "GET",
+			"http.route":          "/items/:id",
+			"request.id":          "req-42",
+		} {
+			v, _ := attrs.Value(key)
+			t.Assert(v.AsString(), want)
+		}
+		status, _ := attrs.Value("http.response.status_code")
+		t.Assert(status.AsInt64(), 200)
+		t.Assert(server.Status.Code, codes.Unset)
+		t.Assert(service.Name, "items.Service.Get")
+		t.Assert(service.Parent.SpanID(), server.SpanContext.SpanID())
+
+		// 5xx 标记为错误
+		get("/boom", nil)
+		got = spans()
+		t.Assert(len(got), 1)
+		t.Assert(got[0].Name, "GET /boom")
+		t.Assert(got[0].Status.Code, codes.Error)
+
+		// 探针不生成 span
+		get("/healthz", nil)
+		t.Assert(len(spans()), 0)
+	})
+}
@@ -11,62 +11,229 @@ import (
 func RegisterRoutes(s *ghttp.Server) {
 	h := handler.New()
 
+	// 公钥发布（JWKS），供其他服务验签
+	s.Group("/.well-known", func(group *ghttp.RouterGroup) {
+		group.GET("/jwks.json", h.GetJWKS)
+	})
+
+	// 运维接口：Prometheus 指标（配置 metrics.token 时需携带令牌）、存活与就绪探针，不经过限流
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.GET("/metrics", h.GetMetrics)
+		group.GET("/healthz", h.GetHealthz)
+		group.GET("/readyz", h.GetReadyz)
+	})
+
+	// 课程电子书导出：format 参数取值 epub/html，与内容格式协商含义不同，因此不经过 Format 中间件
+	s.Group("/api/v1/export", func(group *ghttp.RouterGroup) {
+		group.GET("/book", h.ExportBook)
+	})
+
 	// API v1 路由组
 	s.Group("/api/v1", func(group *ghttp.RouterGroup) {
 		// 应用格式转换中间件
 		group.Middleware(middleware.Format)
+		// 除下方显式缓存的学习内容外，接口响应均包含用户数据或令牌，禁止缓存
+		group.Middleware(middleware.NoStore)
+		// 全部接口按客户端 IP 限流，下方分组另有更严格的策略
+		group.Middleware(middleware.RateLimit("api"))
+
+		// 接口文档（OpenAPI 3.1）
+		group.GET("/openapi.json", h.GetOpenAPI)
+		group.GET("/docs", h.GetAPIDocs)
+
+		// 认证路由（无需 JWT 验证），单独限流以防暴力尝试
+		group.Group("/", func(loginGroup *ghttp.RouterGroup) {
+			loginGroup.Middleware(middleware.RateLimit("auth"))
+			loginGroup.POST("/auth/login", h.Login)
+			loginGroup.POST("/auth/refresh", h.RefreshToken)
+			loginGroup.POST("/auth/login/mfa", h.VerifyMFALogin)
+			loginGroup.POST("/auth/signup", h.Signup)
+		})
 
-		// 认证路由（无需 JWT 验证）
-		group.POST("/auth/login", h.Login)
-		group.POST("/auth/refresh", h.RefreshToken)
+		// 外部身份登录（OIDC 授权码 + PKCE）
+		group.GET("/auth/oidc/providers", h.ListOIDCProviders)
+		group.GET("/auth/oidc/:provider/login", h.OIDCLogin)
+		group.GET("/auth/oidc/:provider/callback", h.OIDCCallback)
 
 		// 需要认证的路由
 		group.Group("/", func(authGroup *ghttp.RouterGroup) {
 			authGroup.Middleware(middleware.Auth)
+			authGroup.Middleware(middleware.TokenScope)
 			authGroup.Middleware(middleware.ForceChangePassword)
+			authGroup.Middleware(middleware.RequireMFASetup)
 			authGroup.POST("/auth/register", h.Register)
 			authGroup.GET("/auth/profile", h.GetProfile)
 			authGroup.POST("/auth/logout", h.Logout)
 			authGroup.POST("/auth/change-password", h.ChangePassword)
+			authGroup.GET("/auth/export", h.ExportMyData)
+
+			// 两步验证
+			authGroup.GET("/auth/mfa", h.GetMFAStatus)
+			authGroup.POST("/auth/mfa/setup", h.SetupMFA)
+			authGroup.POST("/auth/mfa/confirm", h.ConfirmMFA)
+			authGroup.POST("/auth/mfa/disable", h.DisableMFA)
+			authGroup.POST("/auth/mfa/recovery-codes", h.RegenerateRecoveryCodes)
+
+			// 个人访问令牌
+			authGroup.GET("/auth/tokens", h.ListAccessTokens)
+			authGroup.POST("/auth/tokens", h.CreateAccessToken)
+			authGroup.DELETE("/auth/tokens/:id", h.RevokeAccessToken)
+
+			// 邀请码管理（管理员）
+			authGroup.POST("/admin/invites", h.CreateInvite)
+			authGroup.GET("/admin/invites", h.ListInvites)
+			authGroup.DELETE("/admin/invites/:id", h.RevokeInvite)
+			authGroup.GET("/admin/invites/:id/redemptions", h.ListInviteRedemptions)
+
+			// 用户角色（管理员）
+			authGroup.PUT("/admin/users/:id/teacher", h.SetTeacherRole)
+
+			// 审计日志（管理员）
+			authGroup.Group("/admin/audit", func(adminGroup *ghttp.RouterGroup) {
+				adminGroup.Middleware(middleware.RequireAdmin)
+				adminGroup.GET("/", h.ListAuditEvents)
+				adminGroup.GET("/verify", h.VerifyAuditChain)
+			})
+
+			// Webhook（管理员）
+			authGroup.Group("/admin/webhooks", func(hookGroup *ghttp.RouterGroup) {
+				hookGroup.Middleware(middleware.RequireAdmin)
+				hookGroup.GET("/", h.ListWebhooks)
+				hookGroup.POST("/", h.CreateWebhook)
+				hookGroup.GET("/:id", h.GetWebhook)
+				hookGroup.PUT("/:id", h.UpdateWebhook)
+				hookGroup.DELETE("/:id", h.DeleteWebhook)
+				hookGroup.POST("/:id/test", h.SendWebhookTest)
+				hookGroup.GET("/:id/deliveries", h.ListWebhookDeliveries)
+				hookGroup.POST("/deliveries/:deliveryId/retry", h.RetryWebhookDelivery)
+			})
+
+			// 定时任务（管理员）
+			authGroup.Group("/admin/jobs", func(jobGroup *ghttp.RouterGroup) {
+				jobGroup.Middleware(middleware.RequireAdmin)
+				jobGroup.GET("/", h.ListJobs)
+				jobGroup.GET("/:name/runs", h.ListJobRuns)
+				jobGroup.PUT("/:name/paused", h.SetJobPaused)
+				jobGroup.POST("/:name/run", h.TriggerJob)
+			})
+
+			// 题库（教师与管理员）
+			authGroup.Group("/admin/questions", func(bankGroup *ghttp.RouterGroup) {
+				bankGroup.Middleware(middleware.RequireTeacher)
+				bankGroup.GET("/", h.ListBankQuestions)
+				bankGroup.POST("/", h.CreateBankQuestion)
+				bankGroup.GET("/:id", h.GetBankQuestion)
+				bankGroup.PUT("/:id", h.UpdateBankQuestion)
+				bankGroup.DELETE("/:id", h.DeleteBankQuestion)
+				bankGroup.GET("/:id/revisions", h.ListBankQuestionRevisions)
+			})
+
+			// 班级
+			authGroup.GET("/classes", h.ListClasses)
+			authGroup.POST("/classes", h.CreateClass)
+			authGroup.POST("/classes/join", h.JoinClass)
+			authGroup.GET("/classes/:id", h.GetClass)
+			authGroup.DELETE("/classes/:id", h.DeleteClass)
+			authGroup.POST("/classes/:id/join-code", h.RegenerateClassJoinCode)
+			authGroup.POST("/classes/:id/members", h.AddClassMember)
+			authGroup.DELETE("/classes/:id/members/:userId", h.RemoveClassMember)
+			authGroup.GET("/classes/:id/dashboard", h.GetClassDashboard)
+			authGroup.GET("/classes/:id/students/:userId", h.GetClassStudentReport)
+			authGroup.GET("/classes/:id/assignments", h.ListClassAssignments)
+			authGroup.POST("/classes/:id/assignments", h.CreateAssignment)
+			authGroup.DELETE("/classes/:id/assignments/:assignmentId", h.DeleteAssignment)
+			authGroup.GET("/classes/:id/assignments/:assignmentId/report", h.GetAssignmentReport)
+			authGroup.GET("/assignments", h.ListMyAssignments)
+			authGroup.PUT("/classes/:id/leaderboard", h.SetClassLeaderboard)
+
+			// 排行榜
+			authGroup.GET("/leaderboards", h.GetLeaderboard)
+			authGroup.GET("/leaderboards/preferences", h.GetLeaderboardPreferences)
+			authGroup.PUT("/leaderboards/preferences", h.UpdateLeaderboardPreferences)
 
 			// 学习进度
 			authGroup.GET("/progress", h.GetAllProgress)
 			authGroup.GET("/progress/:topic", h.GetTopicProgress)
 			authGroup.POST("/progress", h.SaveProgress)
 
+			// 笔记与书签
+			authGroup.GET("/notes", h.ListNotes)
+			authGroup.POST("/notes", h.CreateNote)
+			authGroup.GET("/notes/:id", h.GetNote)
+			authGroup.PUT("/notes/:id", h.UpdateNote)
+			authGroup.DELETE("/notes/:id", h.DeleteNote)
+
+			// 讨论区
+			authGroup.GET("/discussions", h.ListDiscussions)
+			authGroup.POST("/discussions", h.CreateDiscussion)
+			authGroup.GET("/discussions/:id", h.GetDiscussion)
+			authGroup.POST("/discussions/:id/posts", h.ReplyDiscussion)
+			authGroup.PUT("/discussions/:id/accepted", h.AcceptDiscussionAnswer)
+			authGroup.PUT("/discussions/posts/:postId/hidden", h.SetDiscussionPostHidden)
+			authGroup.GET("/mentions", h.ListMentions)
+			authGroup.POST("/mentions/read", h.MarkMentionsRead)
+
+			// 通知收件箱
+			authGroup.GET("/notifications", h.ListNotifications)
+			authGroup.POST("/notifications/read", h.MarkNotificationsRead)
+			authGroup.GET("/notifications/preferences", h.GetNotificationPreferences)
+			authGroup.PUT("/notifications/preferences", h.UpdateNotificationPreferences)
+
 			// 测验
 			authGroup.GET("/quiz/:topic/:chapter", h.GetQuiz)
-			authGroup.POST("/quiz/submit", h.SubmitQuiz)
+			authGroup.Group("/", func(quizGroup *ghttp.RouterGroup) {
+				quizGroup.Middleware(middleware.RateLimit("quiz"))
+				quizGroup.POST("/quiz/submit", h.SubmitQuiz)
+			})
 			authGroup.GET("/quiz/history", h.GetQuizHistory)
 			authGroup.GET("/quiz/history/:topic", h.GetQuizHistory)
 		})
 
-		// 主题列表
-		group.ALL("/topics", h.GetTopics)
-
-		// 词法元素菜单
-		group.ALL("/topic/lexical_elements", h.GetLexicalMenu)
-		// 词法元素章节内容
-		group.ALL("/topic/lexical_elements/:chapter", h.GetLexicalContent)
-
-		// Constants 菜单
-		group.ALL("/topic/constants", h.GetConstantsMenu)
-		// Constants 内容
-		group.ALL("/topic/constants/:subtopic", h.GetConstantsContent)
-
-		// Variables 菜单
-		group.ALL("/topic/variables", h.GetVariablesMenu)
-		// Variables 内容
-		group.ALL("/topic/variables/:subtopic", h.GetVariableContent)
-
-		// Types 菜单
-		group.ALL("/topic/types", h.GetTypesMenu)
-		// Types 内容
-		group.ALL("/topic/types/:subtopic", h.GetTypesContent)
-		// Types 提纲
-		group.ALL("/topic/types/outline", h.GetTypesOutline)
+		// 通知实时推送：EventSource 无法设置请求头，允许以 access_token 参数携带 JWT
+		group.Group("/", func(streamGroup *ghttp.RouterGroup) {
+			streamGroup.Middleware(middleware.QueryToken)
+			streamGroup.Middleware(middleware.Auth)
+			streamGroup.Middleware(middleware.TokenScope)
+			streamGroup.Middleware(middleware.ForceChangePassword)
+			streamGroup.Middleware(middleware.RequireMFASetup)
+			streamGroup.GET("/notifications/stream", h.StreamNotifications)
+		})
+
+		// 公开学习内容：响应按路径与格式预先生成并缓存，支持 ETag 条件请求与压缩
+		group.Group("/", func(contentGroup *ghttp.RouterGroup) {
+			contentGroup.Middleware(middleware.ContentCache(handler.IsContentPath))
+
+			// 主题列表
+			contentGroup.ALL("/topics", h.GetTopics)
+
+			// 词法元素菜单
+			contentGroup.ALL("/topic/lexical_elements", h.GetLexicalMenu)
+			// 词法元素章节内容
+			contentGroup.ALL("/topic/lexical_elements/:chapter", h.GetLexicalContent)
+
+			// Constants 菜单
+			contentGroup.ALL("/topic/constants", h.GetConstantsMenu)
+			// Constants 内容
+			contentGroup.ALL("/topic/constants/:subtopic", h.GetConstantsContent)
+
+			// Variables 菜单
+			contentGroup.ALL("/topic/variables", h.GetVariablesMenu)
+			// Variables 内容
+			contentGroup.ALL("/topic/variables/:subtopic", h.GetVariableContent)
+
+			// Types 菜单
+			contentGroup.ALL("/topic/types", h.GetTypesMenu)
+			// Types 内容
+			contentGroup.ALL("/topic/types/:subtopic", h.GetTypesContent)
+			// Types 提纲
+			contentGroup.ALL("/topic/types/outline", h.GetTypesOutline)
+		})
+
 		// Types 测验提交
-		group.ALL("/topic/types/quiz/submit", h.SubmitTypesQuiz)
+		group.Group("/", func(quizGroup *ghttp.RouterGroup) {
+			quizGroup.Middleware(middleware.RateLimit("quiz"))
+			quizGroup.ALL("/topic/types/quiz/submit", h.SubmitTypesQuiz)
+		})
 		// Types 搜索
 		group.ALL("/topic/types/search", h.SearchTypes)
 
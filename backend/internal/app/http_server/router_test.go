@@ -54,6 +54,13 @@ func TestRegisterRoutes(t *testing.T) {
 		t.AssertNil(err)
 		defer resp5.Close()
 		t.Assert(resp5.StatusCode, 200)
+
+		// 测试 JWKS 公钥发布路由
+		resp6, err := client.Get(nil, "/.well-known/jwks.json")
+		t.AssertNil(err)
+		defer resp6.Close()
+		t.Assert(resp6.StatusCode, 200)
+		t.AssertIN(`"keys"`, resp6.ReadAllString())
 	})
 }
 
@@ -7,18 +7,23 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"go-study2/internal/app/http_server/handler"
 	"go-study2/internal/app/http_server/middleware"
 	"go-study2/internal/config"
 	"go-study2/internal/domain/user"
 	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/logging"
 	"go-study2/internal/infrastructure/repository"
 	appjwt "go-study2/internal/pkg/jwt"
+	"go-study2/internal/pkg/password"
 
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/gogf/gf/v2/os/gctx"
 	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gproc"
 )
 
 // NewServer 创建并配置 HTTP/HTTPS 服务器
@@ -30,21 +35,39 @@ func NewServer(cfg *config.Config, names ...string) (*ghttp.Server, error) {
 		s = g.Server()
 	}
 
+	// 记录生效配置，供按需构建的服务与中间件读取
+	config.SetDefault(cfg)
+
 	// 基础配置
 	s.SetGraceful(true) // 开启优雅关闭
 
-	// 注册全局中间件
+	// 日志附带请求 ID，按配置输出文本或 JSON
+	logging.Configure(g.Log(), cfg.Logger)
+	logging.Configure(s.Logger(), cfg.Logger)
+
+	// 注册全局中间件：请求 ID 最先确定，随后创建追踪 span，指标统计覆盖其余全部处理
+	s.Use(middleware.RequestID)
+	s.Use(middleware.Tracing)
+	s.Use(middleware.Metrics)
 	s.Use(middleware.Logger)
 	s.Use(middleware.Cors)
 
 	// 注册路由
 	RegisterRoutes(s)
 
+	// 密码强度策略对注册、改密等全部入口生效
+	if err := configurePasswordPolicy(cfg.Auth.Password); err != nil {
+		return nil, err
+	}
+
 	// 启动前确保默认管理员存在（幂等）
 	if err := ensureDefaultAdmin(cfg); err != nil {
 		return nil, err
 	}
 
+	// 订阅学习事件、继续投递发件箱中遗留的 Webhook，并启动定时任务调度
+	handler.StartBackground()
+
 	if cfg.Https.Enabled {
 		tlsCfg, err := buildTLSConfig(cfg.Https)
 		if err != nil {
@@ -92,6 +115,16 @@ func NewServer(cfg *config.Config, names ...string) (*ghttp.Server, error) {
 	return s, nil
 }
 
+// DrainOnShutdown 注册停止信号处理：先把就绪检查置为未就绪并等待 drain，让负载均衡摘除实例，
+// 随后再由 Run 注册的处理优雅关闭服务。信号处理按注册顺序执行，因此必须在 Run 之前调用。
+func DrainOnShutdown(drain time.Duration) {
+	gproc.AddSigHandlerShutdown(func(sig os.Signal) {
+		handler.SetDraining(true)
+		g.Log().Infof(gctx.New(), "收到信号 %s，就绪检查已置为未就绪，%s 后关闭服务", sig, drain)
+		time.Sleep(drain)
+	})
+}
+
 // buildTLSConfig 构建 TLS 配置，设定最小版本和可选的 CA/跳过校验
 func buildTLSConfig(cfg config.HttpsConfig) (*tls.Config, error) {
 	tlsCfg := &tls.Config{
@@ -120,6 +153,39 @@ func buildTLSConfig(cfg config.HttpsConfig) (*tls.Config, error) {
 	return tlsCfg, nil
 }
 
+// configurePasswordPolicy 将密码策略配置应用到 password 包，并加载离线泄露密码库。
+func configurePasswordPolicy(cfg config.PasswordConfig) error {
+	policy := password.Policy{
+		MinLength:   cfg.MinLength,
+		CheckCommon: !cfg.DisableCommonList,
+	}
+	classes := cfg.RequiredClasses
+	if len(classes) == 0 {
+		classes = []string{password.ClassLower, password.ClassUpper, password.ClassDigit, password.ClassSymbol}
+	}
+	for _, class := range classes {
+		switch class {
+		case password.ClassLower:
+			policy.RequireLower = true
+		case password.ClassUpper:
+			policy.RequireUpper = true
+		case password.ClassDigit:
+			policy.RequireDigit = true
+		case password.ClassSymbol:
+			policy.RequireSymbol = true
+		}
+	}
+	if cfg.BreachedList != "" {
+		list, err := password.LoadBreachedList(cfg.BreachedList)
+		if err != nil {
+			return err
+		}
+		policy.Breached = list
+	}
+	password.Configure(policy)
+	return nil
+}
+
 func ensureDefaultAdmin(cfg *config.Config) error {
 	if cfg.Database.Path == "" {
 		return nil
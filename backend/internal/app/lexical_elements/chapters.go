@@ -0,0 +1,38 @@
+package lexical_elements
+
+// Chapter 描述一个词法元素章节，ID 同时用作 HTTP 路由参数与测验章节标识。
+type Chapter struct {
+	ID          string
+	Title       string
+	ContentFunc func() string
+}
+
+// chapters 按菜单顺序排列的全部章节。
+var chapters = []Chapter{
+	{"comments", "Comments (注释)", GetCommentsContent},
+	{"tokens", "Tokens (标记)", GetTokensContent},
+	{"semicolons", "Semicolons (分号)", GetSemicolonsContent},
+	{"identifiers", "Identifiers (标识符)", GetIdentifiersContent},
+	{"keywords", "Keywords (关键字)", GetKeywordsContent},
+	{"operators", "Operators (运算符)", GetOperatorsContent},
+	{"integers", "Integers (整数)", GetIntegersContent},
+	{"floats", "Floats (浮点数)", GetFloatsContent},
+	{"imaginary", "Imaginary (虚数)", GetImaginaryContent},
+	{"runes", "Runes (符文)", GetRunesContent},
+	{"strings", "Strings (字符串)", GetStringsContent},
+}
+
+// Chapters 返回全部章节的副本，顺序与菜单一致。
+func Chapters() []Chapter {
+	return append([]Chapter(nil), chapters...)
+}
+
+// IsSupportedChapter 判断 id 是否为已有章节。
+func IsSupportedChapter(id string) bool {
+	for _, c := range chapters {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
+}
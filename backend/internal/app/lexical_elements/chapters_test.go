@@ -0,0 +1,22 @@
+package lexical_elements
+
+import "testing"
+
+// TestChapters 验证章节目录与菜单一致且每个章节都有内容。
+func TestChapters(t *testing.T) {
+	list := Chapters()
+	if len(list) != 11 {
+		t.Fatalf("期望 11 个章节，得到 %d", len(list))
+	}
+	for _, c := range list {
+		if !IsSupportedChapter(c.ID) {
+			t.Errorf("章节 %s 应被识别", c.ID)
+		}
+		if c.Title == "" || c.ContentFunc() == "" {
+			t.Errorf("章节 %s 缺少标题或内容", c.ID)
+		}
+	}
+	if IsSupportedChapter("iota") || IsSupportedChapter("") {
+		t.Errorf("不存在的章节不应被识别")
+	}
+}
@@ -86,7 +86,8 @@ func GetOperatorsContent() string {
 	num := 42
 	ptr := &num // & 取地址
 	sb.WriteString(fmt.Sprintf("   num = %d\n", num))
-	sb.WriteString(fmt.Sprintf("   ptr = &num  → 地址: %p\n", ptr))
+	// 地址每次运行都不同，只展示指针是否为空，保证章节内容稳定
+	sb.WriteString(fmt.Sprintf("   ptr = &num  → ptr != nil: %t (指向 num 的地址)\n", ptr != nil))
 	sb.WriteString(fmt.Sprintf("   *ptr = %d   (解引用，获取指针指向的值)\n", *ptr))
 	*ptr = 100 // 通过指针修改值
 	sb.WriteString(fmt.Sprintf("   *ptr = 100  → num = %d\n", num))
@@ -0,0 +1,227 @@
+// Package notebook 为命令行提供“添加笔记”与“我的笔记”入口。
+//
+// CLI 直接读写配置文件指定的本地数据库，并以个人访问令牌识别用户：
+// 令牌优先读取环境变量 GO_STUDY2_TOKEN，未设置时在终端中输入。
+package notebook
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/notes"
+	"go-study2/internal/domain/user"
+	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/repository"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+// TokenEnv 为读取个人访问令牌的环境变量名。
+const TokenEnv = "GO_STUDY2_TOKEN"
+
+// bodyTerminator 单独成行时结束正文输入。
+const bodyTerminator = "."
+
+var errTokenInvalid = errors.New("访问令牌无效、已过期或缺少所需权限")
+
+// store 为 CLI 使用的笔记能力，由 *notes.Service 实现。
+type store interface {
+	Create(ctx context.Context, userID int64, input notes.Input) (*notes.Note, error)
+	List(ctx context.Context, userID int64, filter notes.Filter) ([]notes.Note, error)
+}
+
+// session 为通过访问令牌识别出的用户及其笔记存储。
+type session struct {
+	userID int64
+	store  store
+}
+
+// connect 打开本地数据库并校验访问令牌，测试中可替换。
+var connect = func(ctx context.Context, raw, scope string) (*session, error) {
+	db := database.Default()
+	if db == nil {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, fmt.Errorf("加载配置失败: %w", err)
+		}
+		if db, err = database.Init(ctx, cfg.Database); err != nil {
+			return nil, fmt.Errorf("初始化数据库失败: %w", err)
+		}
+	}
+	if !user.IsAccessToken(raw) {
+		return nil, errTokenInvalid
+	}
+	token, err := repository.NewAccessTokenRepository(db).FindAccessTokenByHash(ctx, user.HashAccessToken(raw))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || !token.Active(time.Now()) || !token.HasScope(scope) {
+		return nil, errTokenInvalid
+	}
+	return &session{userID: token.UserID, store: notes.NewService(repository.NewNotesRepository(db))}, nil
+}
+
+// AddNote 交互式地在指定主题、章节与锚点处添加笔记或书签。
+func AddNote(stdin io.Reader, stdout, stderr io.Writer) {
+	reader := bufio.NewReader(stdin)
+	ctx := gctx.New()
+	sess, ok := open(ctx, reader, stdout, stderr, user.ScopeNotesWrite)
+	if !ok {
+		return
+	}
+
+	input := notes.Input{Kind: notes.KindNote}
+	if kind, ok := prompt(reader, stdout, stderr, "类型 (n=笔记, b=书签，默认 n): "); !ok {
+		return
+	} else if kind == "b" {
+		input.Kind = notes.KindBookmark
+	}
+	fields := []struct {
+		label string
+		dest  *string
+	}{
+		{"主题 (如 types): ", &input.Topic},
+		{"章节 (如 slice): ", &input.Chapter},
+		{"锚点 (小节或示例 ID，可留空): ", &input.Anchor},
+		{"标题 (可留空): ", &input.Title},
+	}
+	for _, f := range fields {
+		value, ok := prompt(reader, stdout, stderr, f.label)
+		if !ok {
+			return
+		}
+		*f.dest = value
+	}
+	tags, ok := prompt(reader, stdout, stderr, "标签 (逗号分隔，可留空): ")
+	if !ok {
+		return
+	}
+	if tags != "" {
+		input.Tags = strings.Split(tags, ",")
+	}
+	if input.Kind == notes.KindNote {
+		fmt.Fprintf(stdout, "正文 (支持 Markdown，单独一行 %s 结束):\n", bodyTerminator)
+		body, ok := readBody(reader, stderr)
+		if !ok {
+			return
+		}
+		input.Body = body
+	}
+
+	note, err := sess.store.Create(ctx, sess.userID, input)
+	if err != nil {
+		fmt.Fprintf(stderr, "保存笔记失败: %v\n", err)
+		return
+	}
+	fmt.Fprintf(stdout, "已保存 #%d %s\n", note.ID, position(*note))
+}
+
+// MyNotes 按主题与关键词列出当前用户的笔记与书签。
+func MyNotes(stdin io.Reader, stdout, stderr io.Writer) {
+	reader := bufio.NewReader(stdin)
+	ctx := gctx.New()
+	sess, ok := open(ctx, reader, stdout, stderr, user.ScopeNotesRead)
+	if !ok {
+		return
+	}
+	topic, ok := prompt(reader, stdout, stderr, "主题 (留空表示全部): ")
+	if !ok {
+		return
+	}
+	query, ok := prompt(reader, stdout, stderr, "关键词 (留空表示不检索): ")
+	if !ok {
+		return
+	}
+
+	list, err := sess.store.List(ctx, sess.userID, notes.Filter{Topic: topic, Query: query})
+	if err != nil {
+		fmt.Fprintf(stderr, "查询笔记失败: %v\n", err)
+		return
+	}
+	if len(list) == 0 {
+		fmt.Fprintln(stdout, "暂无笔记。")
+		return
+	}
+	for _, note := range list {
+		fmt.Fprintf(stdout, "\n#%d [%s] %s", note.ID, note.Kind, position(note))
+		if note.Title != "" {
+			fmt.Fprintf(stdout, " %s", note.Title)
+		}
+		if len(note.Tags) > 0 {
+			fmt.Fprintf(stdout, " (%s)", strings.Join(note.Tags, ", "))
+		}
+		fmt.Fprintf(stdout, "\n更新于 %s\n", note.UpdatedAt.Local().Format("2006-01-02 15:04"))
+		if note.Body == "" {
+			continue
+		}
+		for _, line := range strings.Split(note.Body, "\n") {
+			fmt.Fprintf(stdout, "  %s\n", line)
+		}
+	}
+}
+
+// open 读取访问令牌并建立会话，失败时输出原因。
+func open(ctx context.Context, reader *bufio.Reader, stdout, stderr io.Writer, scope string) (*session, bool) {
+	raw := strings.TrimSpace(os.Getenv(TokenEnv))
+	if raw == "" {
+		var ok bool
+		if raw, ok = prompt(reader, stdout, stderr, fmt.Sprintf("请输入个人访问令牌 (需要 %s 权限): ", scope)); !ok {
+			return nil, false
+		}
+	}
+	sess, err := connect(ctx, raw, scope)
+	if err != nil {
+		fmt.Fprintf(stderr, "无法打开笔记: %v\n", err)
+		return nil, false
+	}
+	return sess, true
+}
+
+func prompt(reader *bufio.Reader, stdout, stderr io.Writer, label string) (string, bool) {
+	fmt.Fprint(stdout, label)
+	input, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || input == "") {
+		fmt.Fprintf(stderr, "读取输入失败: %v\n", err)
+		return "", false
+	}
+	return strings.TrimSpace(input), true
+}
+
+// readBody 逐行读取正文直到结束标记或输入结束。
+func readBody(reader *bufio.Reader, stderr io.Writer) (string, bool) {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		trimmed := strings.TrimRight(line, "\r\n")
+		if trimmed == bodyTerminator {
+			break
+		}
+		if line != "" {
+			lines = append(lines, trimmed)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "读取输入失败: %v\n", err)
+			return "", false
+		}
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// position 以 topic/chapter#anchor 形式描述笔记位置。
+func position(note notes.Note) string {
+	pos := note.Topic + "/" + note.Chapter
+	if note.Anchor != "" {
+		pos += "#" + note.Anchor
+	}
+	return pos
+}
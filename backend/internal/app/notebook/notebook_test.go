@@ -0,0 +1,105 @@
+package notebook
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/notes"
+	"go-study2/internal/domain/user"
+)
+
+type stubStore struct {
+	created    []notes.Input
+	lastFilter notes.Filter
+	list       []notes.Note
+}
+
+func (s *stubStore) Create(_ context.Context, userID int64, input notes.Input) (*notes.Note, error) {
+	s.created = append(s.created, input)
+	return &notes.Note{ID: int64(len(s.created)), UserID: userID, Kind: input.Kind, Topic: input.Topic, Chapter: input.Chapter, Anchor: input.Anchor}, nil
+}
+
+func (s *stubStore) List(_ context.Context, _ int64, filter notes.Filter) ([]notes.Note, error) {
+	s.lastFilter = filter
+	return s.list, nil
+}
+
+func stubConnect(t *testing.T, store *stubStore) *string {
+	t.Helper()
+	var gotScope string
+	original := connect
+	connect = func(_ context.Context, raw, scope string) (*session, error) {
+		if raw != "gsp_test" {
+			return nil, errTokenInvalid
+		}
+		gotScope = scope
+		return &session{userID: 7, store: store}, nil
+	}
+	t.Cleanup(func() { connect = original })
+	return &gotScope
+}
+
+func TestAddNote_ReadsFieldsAndMultilineBody(t *testing.T) {
+	store := &stubStore{}
+	scope := stubConnect(t, store)
+	t.Setenv(TokenEnv, "")
+
+	input := strings.Join([]string{"gsp_test", "", "types", "slice", "slice-append", "扩容", "runtime, slice", "**append**", "", "第二段", ".", ""}, "\n")
+	var stdout, stderr bytes.Buffer
+	AddNote(strings.NewReader(input), &stdout, &stderr)
+
+	if stderr.Len() > 0 {
+		t.Fatalf("不应输出错误: %s", stderr.String())
+	}
+	if *scope != user.ScopeNotesWrite || len(store.created) != 1 {
+		t.Fatalf("应以 notes:write 权限创建一条笔记: %q %+v", *scope, store.created)
+	}
+	got := store.created[0]
+	if got.Kind != notes.KindNote || got.Anchor != "slice-append" || got.Body != "**append**\n\n第二段" || len(got.Tags) != 2 {
+		t.Fatalf("笔记内容不正确: %+v", got)
+	}
+	if !strings.Contains(stdout.String(), "已保存 #1 types/slice#slice-append") {
+		t.Fatalf("应提示保存位置: %s", stdout.String())
+	}
+}
+
+func TestAddNote_BookmarkSkipsBodyAndUsesEnvToken(t *testing.T) {
+	store := &stubStore{}
+	stubConnect(t, store)
+	t.Setenv(TokenEnv, "gsp_test")
+
+	var stdout, stderr bytes.Buffer
+	AddNote(strings.NewReader("b\nvariables\nstorage\nex-1\n\n\n"), &stdout, &stderr)
+	if len(store.created) != 1 || store.created[0].Kind != notes.KindBookmark || store.created[0].Body != "" {
+		t.Fatalf("书签不应读取正文: %+v, %s", store.created, stderr.String())
+	}
+}
+
+func TestMyNotes_ListsAndReportsInvalidToken(t *testing.T) {
+	store := &stubStore{list: []notes.Note{{
+		ID: 3, Kind: notes.KindNote, Topic: "types", Chapter: "map", Title: "并发", Body: "map 不是并发安全的",
+		Tags: []string{"map"}, UpdatedAt: time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+	}}}
+	scope := stubConnect(t, store)
+	t.Setenv(TokenEnv, "gsp_test")
+
+	var stdout, stderr bytes.Buffer
+	MyNotes(strings.NewReader("types\n并发\n"), &stdout, &stderr)
+	if *scope != user.ScopeNotesRead || store.lastFilter.Topic != "types" || store.lastFilter.Query != "并发" {
+		t.Fatalf("查询参数不正确: %q %+v", *scope, store.lastFilter)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "#3 [note] types/map 并发 (map)") || !strings.Contains(out, "  map 不是并发安全的") {
+		t.Fatalf("笔记列表输出不正确: %s", out)
+	}
+
+	t.Setenv(TokenEnv, "gsp_wrong")
+	stderr.Reset()
+	MyNotes(strings.NewReader(""), &stdout, &stderr)
+	if !strings.Contains(stderr.String(), "访问令牌无效") {
+		t.Fatalf("无效令牌应提示错误: %s", stderr.String())
+	}
+}
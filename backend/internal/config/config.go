@@ -6,6 +6,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"go-study2/internal/pkg/cron"
 
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gcfg"
@@ -15,13 +20,21 @@ import (
 
 // Config 应用配置结构
 type Config struct {
-	Http     HttpConfig     `json:"http"`
-	Https    HttpsConfig    `json:"https"`
-	Server   ServerConfig   `json:"server"`
-	Logger   LoggerConfig   `json:"logger"`
-	Database DatabaseConfig `json:"database"`
-	Jwt      JwtConfig      `json:"jwt"`
-	Static   StaticConfig   `json:"static"`
+	Http      HttpConfig      `json:"http"`
+	Https     HttpsConfig     `json:"https"`
+	Server    ServerConfig    `json:"server"`
+	Logger    LoggerConfig    `json:"logger"`
+	Database  DatabaseConfig  `json:"database"`
+	Jwt       JwtConfig       `json:"jwt"`
+	Auth      AuthConfig      `json:"auth"`
+	Audit     AuditConfig     `json:"audit"`
+	Webhooks  WebhooksConfig  `json:"webhooks"`
+	Jobs      JobsConfig      `json:"jobs"`
+	RateLimit RateLimitConfig `json:"rateLimit"`
+	Metrics   MetricsConfig   `json:"metrics"`
+	Health    HealthConfig    `json:"health"`
+	Tracing   TracingConfig   `json:"tracing"`
+	Static    StaticConfig    `json:"static"`
 }
 
 // HttpConfig HTTP 配置
@@ -50,6 +63,8 @@ type LoggerConfig struct {
 	Level  string `json:"level"`
 	Path   string `json:"path"`
 	Stdout bool   `json:"stdout"`
+	// Format 日志格式：text（默认）或 json，json 每行一个对象，包含请求 ID 与追踪 ID
+	Format string `json:"format"`
 }
 
 // DatabaseConfig 数据库配置
@@ -64,10 +79,182 @@ type DatabaseConfig struct {
 
 // JwtConfig JWT 配置
 type JwtConfig struct {
-	Secret             string `json:"secret"`
-	AccessTokenExpiry  int64  `json:"accessTokenExpiry"`
-	RefreshTokenExpiry int64  `json:"refreshTokenExpiry"`
-	Issuer             string `json:"issuer"`
+	Secret             string         `json:"secret"`
+	AccessTokenExpiry  int64          `json:"accessTokenExpiry"`
+	RefreshTokenExpiry int64          `json:"refreshTokenExpiry"`
+	Issuer             string         `json:"issuer"`
+	Audience           string         `json:"audience"`
+	ActiveKeyId        string         `json:"activeKeyId"`
+	Keys               []JwtKeyConfig `json:"keys"`
+}
+
+// JwtKeyConfig 密钥环中单把密钥的来源，密钥材料只从文件或环境变量读取
+type JwtKeyConfig struct {
+	Id        string `json:"id"`
+	Algorithm string `json:"algorithm"`
+	File      string `json:"file"`
+	Env       string `json:"env"`
+	RetireAt  string `json:"retireAt"`
+}
+
+// AuthConfig 账号安全相关配置
+type AuthConfig struct {
+	Mfa          MfaConfig          `json:"mfa"`
+	Registration RegistrationConfig `json:"registration"`
+	Oidc         OidcConfig         `json:"oidc"`
+	Password     PasswordConfig     `json:"password"`
+}
+
+// PasswordConfig 密码策略配置
+type PasswordConfig struct {
+	// MinLength 最小长度，为 0 时默认 8
+	MinLength int `json:"minLength"`
+	// RequiredClasses 必须包含的字符类别（lower、upper、digit、symbol），为空时要求全部四类
+	RequiredClasses []string `json:"requiredClasses"`
+	// MaxAgeDays 密码最长使用天数，到期后登录需先修改密码；0 表示永不过期
+	MaxAgeDays int `json:"maxAgeDays"`
+	// HistorySize 禁止复用的历史密码数量（当前密码始终不可复用），最大 24
+	HistorySize int `json:"historySize"`
+	// BreachedList 离线泄露密码库：SHA-1 哈希列表文件，或按 5 位哈希前缀拆分的分片目录
+	BreachedList string `json:"breachedList"`
+	// DisableCommonList 为 true 时不再拒绝内置常见弱密码表中的密码
+	DisableCommonList bool `json:"disableCommonList"`
+}
+
+// OidcConfig 外部身份提供方（OpenID Connect）配置
+type OidcConfig struct {
+	Providers []OidcProviderConfig `json:"providers"`
+}
+
+// OidcProviderConfig 单个 OIDC 提供方，登录地址为 /api/v1/auth/oidc/{name}/login
+type OidcProviderConfig struct {
+	// Name 提供方标识，仅允许小写字母、数字、- 与 _
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	// Issuer 提供方地址，需支持 /.well-known/openid-configuration
+	Issuer       string `json:"issuer"`
+	ClientId     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	// ClientSecretEnv 从环境变量读取 clientSecret，优先于 clientSecret
+	ClientSecretEnv string `json:"clientSecretEnv"`
+	// RedirectUrl 需与提供方登记的回调地址一致，指向 /api/v1/auth/oidc/{name}/callback
+	RedirectUrl string   `json:"redirectUrl"`
+	Scopes      []string `json:"scopes"`
+	// UsernameClaim 用于派生本地用户名的声明，默认 preferred_username
+	UsernameClaim string `json:"usernameClaim"`
+	// RoleClaim 与 AdminValues 决定首次开通时是否为管理员，如 groups: [go-study-admins]
+	RoleClaim   string   `json:"roleClaim"`
+	AdminValues []string `json:"adminValues"`
+	// AutoProvision 为 true 时首次登录自动创建本地用户
+	AutoProvision bool `json:"autoProvision"`
+	// PostLoginRedirect 回调成功后跳转的前端地址；为空时直接返回 JSON
+	PostLoginRedirect string `json:"postLoginRedirect"`
+}
+
+// RegistrationConfig 自助注册配置
+type RegistrationConfig struct {
+	// Open 为 true 时允许不携带邀请码通过 /auth/signup 注册普通用户
+	Open bool `json:"open"`
+}
+
+// MfaConfig 两步验证（TOTP）配置
+type MfaConfig struct {
+	// Issuer 认证器 App 中显示的发行方名称，默认复用 jwt.issuer
+	Issuer string `json:"issuer"`
+	// RequireForAdmin 为 true 时管理员必须先启用两步验证才能访问其他接口
+	RequireForAdmin bool `json:"requireForAdmin"`
+	// ChallengeTtl 登录二次验证挑战的有效期（秒）
+	ChallengeTtl int `json:"challengeTtl"`
+	// Skew 允许的时钟偏差（时间步数量，每步 30 秒）
+	Skew int `json:"skew"`
+}
+
+// AuditConfig 审计日志保留配置
+type AuditConfig struct {
+	// RetentionDays 审计事件保留天数，0 表示永久保留
+	RetentionDays int `json:"retentionDays"`
+	// ArchiveDir 非空时清理前先将事件归档为 JSONL 文件
+	ArchiveDir string `json:"archiveDir"`
+	// PruneIntervalHours 清理任务执行间隔（小时），为 0 时每天 03:30 执行；jobs.schedules 中的 audit_retention 优先
+	PruneIntervalHours int `json:"pruneIntervalHours"`
+}
+
+// WebhooksConfig Webhook 投递与重试配置，未设置的项使用内置默认值
+type WebhooksConfig struct {
+	// MaxAttempts 最大尝试次数，超过后进入死信，默认 8
+	MaxAttempts int `json:"maxAttempts"`
+	// RetryBaseSeconds 首次重试等待秒数，之后每次翻倍，默认 30
+	RetryBaseSeconds int `json:"retryBaseSeconds"`
+	// RetryMaxSeconds 单次重试等待上限（秒），默认 3600
+	RetryMaxSeconds int `json:"retryMaxSeconds"`
+	// TimeoutSeconds 单次请求超时（秒），默认 10
+	TimeoutSeconds int `json:"timeoutSeconds"`
+	// PollIntervalSeconds 后台扫描发件箱的间隔（秒），默认 5
+	PollIntervalSeconds int `json:"pollIntervalSeconds"`
+}
+
+// JobsConfig 后台定时任务配置
+type JobsConfig struct {
+	// Enabled 是否由本实例按计划执行任务，关闭后仍可在管理端手动触发
+	Enabled bool `json:"enabled"`
+	// PollIntervalSeconds 检查到期任务的间隔（秒），默认 30
+	PollIntervalSeconds int `json:"pollIntervalSeconds"`
+	// Schedules 按任务名覆盖默认 cron 表达式
+	Schedules map[string]string `json:"schedules"`
+	// ReviewIdleDays 学习中章节多少天未访问后提醒复习，默认 3
+	ReviewIdleDays int `json:"reviewIdleDays"`
+}
+
+// RateLimitConfig 接口限流配置（令牌桶）
+type RateLimitConfig struct {
+	// Enabled 是否启用限流
+	Enabled bool `json:"enabled"`
+	// Policies 按路由组名称配置策略：api（全部 /api/v1 接口）、auth（登录、刷新与注册）、quiz（测验提交），未配置的组不限流
+	Policies map[string]RateLimitPolicy `json:"policies"`
+	// TrustedProxies 可信反向代理的 IP 或 CIDR；仅当直连对端在列表中时才按 X-Forwarded-For 识别客户端 IP
+	TrustedProxies []string `json:"trustedProxies"`
+}
+
+// RateLimitPolicy 单个路由组的令牌桶策略
+type RateLimitPolicy struct {
+	// Requests 每个周期补充的请求数
+	Requests int `json:"requests"`
+	// PeriodSeconds 补充周期（秒），默认 60
+	PeriodSeconds int `json:"periodSeconds"`
+	// Burst 桶容量，即允许的突发请求数，为 0 时等于 requests
+	Burst int `json:"burst"`
+	// Key 限流维度：ip（默认）或 user；user 按认证用户计数，未认证请求退回按 IP
+	Key string `json:"key"`
+}
+
+// MetricsConfig Prometheus 指标接口（/metrics）配置
+type MetricsConfig struct {
+	// Token 非空时抓取请求需携带 Authorization: Bearer <token>
+	Token string `json:"token"`
+	// TokenEnv 从环境变量读取 token，优先于 token
+	TokenEnv string `json:"tokenEnv"`
+}
+
+// HealthConfig 健康检查（/healthz、/readyz）配置
+type HealthConfig struct {
+	// DrainSeconds 收到停止信号后就绪检查先返回未就绪，等待该秒数供负载均衡摘除实例再关闭服务
+	DrainSeconds int `json:"drainSeconds"`
+	// CertMinValidDays HTTPS 证书剩余有效期低于该天数时视为未就绪，默认 7
+	CertMinValidDays int `json:"certMinValidDays"`
+}
+
+// TracingConfig OpenTelemetry 链路追踪配置
+type TracingConfig struct {
+	// Exporter 导出方式：none（默认，不采集）、stdout 或 otlp
+	Exporter string `json:"exporter"`
+	// Endpoint OTLP/HTTP 接收端地址（host:port），为空时使用 OTEL_EXPORTER_OTLP_ENDPOINT 或 localhost:4318
+	Endpoint string `json:"endpoint"`
+	// Insecure 使用 HTTP 而非 HTTPS 连接 OTLP 接收端
+	Insecure bool `json:"insecure"`
+	// SampleRatio 根 span 采样比例，取值 0-1，0 表示全部采样
+	SampleRatio float64 `json:"sampleRatio"`
+	// ServiceName 上报的服务名，默认 go-study2
+	ServiceName string `json:"serviceName"`
 }
 
 // StaticConfig 静态资源配置
@@ -77,6 +264,20 @@ type StaticConfig struct {
 	SpaFallback bool   `json:"spaFallback"`
 }
 
+var defaultConfig atomic.Pointer[Config]
+
+var oidcNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// Default 返回最近一次通过 Load 或 SetDefault 生效的配置，未加载时返回 nil。
+func Default() *Config {
+	return defaultConfig.Load()
+}
+
+// SetDefault 设置全局默认配置，供无法显式注入配置的组件读取。
+func SetDefault(cfg *Config) {
+	defaultConfig.Store(cfg)
+}
+
 // Load 加载配置文件（默认读取 configs/config.yaml）
 func Load() (*Config, error) {
 	ctx := gctx.New()
@@ -94,6 +295,7 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	SetDefault(&cfg)
 	return &cfg, nil
 }
 
@@ -158,6 +360,83 @@ func Validate(cfg *Config) error {
 		}
 	}
 
+	if err := validateJwtKeys(&cfg.Jwt); err != nil {
+		return err
+	}
+
+	if cfg.Auth.Mfa.ChallengeTtl < 0 {
+		return fmt.Errorf("配置项 auth.mfa.challengeTtl 不能为负数")
+	}
+	if cfg.Auth.Mfa.Skew < 0 || cfg.Auth.Mfa.Skew > 3 {
+		return fmt.Errorf("配置项 auth.mfa.skew 必须在0-3范围内")
+	}
+
+	if err := validateOidcProviders(&cfg.Auth.Oidc); err != nil {
+		return err
+	}
+
+	if err := validatePasswordPolicy(&cfg.Auth.Password); err != nil {
+		return err
+	}
+
+	if cfg.Audit.RetentionDays < 0 {
+		return fmt.Errorf("配置项 audit.retentionDays 不能为负数")
+	}
+	if cfg.Audit.PruneIntervalHours < 0 {
+		return fmt.Errorf("配置项 audit.pruneIntervalHours 不能为负数")
+	}
+	if cfg.Audit.ArchiveDir != "" {
+		resolved, err := resolvePath(cfg.Audit.ArchiveDir)
+		if err != nil {
+			return err
+		}
+		cfg.Audit.ArchiveDir = resolved
+	}
+
+	if cfg.Webhooks.MaxAttempts < 0 || cfg.Webhooks.RetryBaseSeconds < 0 || cfg.Webhooks.RetryMaxSeconds < 0 ||
+		cfg.Webhooks.TimeoutSeconds < 0 || cfg.Webhooks.PollIntervalSeconds < 0 {
+		return fmt.Errorf("配置项 webhooks 中的次数与时间不能为负数")
+	}
+
+	if cfg.Jobs.PollIntervalSeconds < 0 || cfg.Jobs.ReviewIdleDays < 0 {
+		return fmt.Errorf("配置项 jobs 中的时间不能为负数")
+	}
+	for name, expr := range cfg.Jobs.Schedules {
+		if _, err := cron.Parse(expr); err != nil {
+			return fmt.Errorf("配置项 jobs.schedules.%s 无效: %w", name, err)
+		}
+	}
+
+	if err := validateRateLimit(&cfg.RateLimit); err != nil {
+		return err
+	}
+
+	if cfg.Metrics.TokenEnv != "" {
+		cfg.Metrics.Token = os.Getenv(cfg.Metrics.TokenEnv)
+		if cfg.Metrics.Token == "" {
+			return fmt.Errorf("环境变量 %s 未设置（metrics.tokenEnv）", cfg.Metrics.TokenEnv)
+		}
+	}
+
+	if cfg.Health.DrainSeconds < 0 || cfg.Health.CertMinValidDays < 0 {
+		return fmt.Errorf("配置项 health 中的时间不能为负数")
+	}
+
+	switch cfg.Logger.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("配置项 logger.format 仅支持 text 或 json")
+	}
+
+	switch cfg.Tracing.Exporter {
+	case "", "none", "stdout", "otlp":
+	default:
+		return fmt.Errorf("配置项 tracing.exporter 仅支持 none、stdout 或 otlp")
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("配置项 tracing.sampleRatio 必须在0-1范围内")
+	}
+
 	if cfg.Static.Enabled && cfg.Static.Path == "" {
 		return fmt.Errorf("配置项 static.path 为必填项，请在configs/config.yaml中设置")
 	}
@@ -165,6 +444,130 @@ func Validate(cfg *Config) error {
 	return nil
 }
 
+// validateJwtKeys 校验 JWT 密钥环配置并解析密钥文件路径
+func validateJwtKeys(cfg *JwtConfig) error {
+	seen := make(map[string]struct{}, len(cfg.Keys))
+	for i := range cfg.Keys {
+		key := &cfg.Keys[i]
+		if key.Id == "" {
+			return fmt.Errorf("配置项 jwt.keys[%d].id 为必填项", i)
+		}
+		if _, dup := seen[key.Id]; dup {
+			return fmt.Errorf("配置项 jwt.keys 中 id 重复: %s", key.Id)
+		}
+		seen[key.Id] = struct{}{}
+		switch key.Algorithm {
+		case "HS256", "RS256", "EdDSA":
+		default:
+			return fmt.Errorf("配置项 jwt.keys[%d].algorithm 仅支持 HS256/RS256/EdDSA", i)
+		}
+		if (key.File == "") == (key.Env == "") {
+			return fmt.Errorf("配置项 jwt.keys[%d] 需且仅需设置 file 或 env 之一", i)
+		}
+		if key.File != "" {
+			resolved, err := resolvePath(key.File)
+			if err != nil {
+				return err
+			}
+			key.File = resolved
+		}
+		if key.RetireAt != "" {
+			if _, err := time.Parse(time.RFC3339, key.RetireAt); err != nil {
+				return fmt.Errorf("配置项 jwt.keys[%d].retireAt 需为 RFC3339 时间: %v", i, err)
+			}
+		}
+	}
+	if cfg.ActiveKeyId != "" {
+		if _, ok := seen[cfg.ActiveKeyId]; !ok {
+			return fmt.Errorf("配置项 jwt.activeKeyId 未在 jwt.keys 中定义: %s", cfg.ActiveKeyId)
+		}
+	}
+	return nil
+}
+
+// validateOidcProviders 校验 OIDC 提供方配置并从环境变量解析 clientSecret
+func validateOidcProviders(cfg *OidcConfig) error {
+	seen := make(map[string]struct{}, len(cfg.Providers))
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		if !oidcNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("配置项 auth.oidc.providers[%d].name 仅允许小写字母、数字、- 与 _", i)
+		}
+		if _, dup := seen[p.Name]; dup {
+			return fmt.Errorf("配置项 auth.oidc.providers 中 name 重复: %s", p.Name)
+		}
+		seen[p.Name] = struct{}{}
+		if p.Issuer == "" || p.ClientId == "" || p.RedirectUrl == "" {
+			return fmt.Errorf("配置项 auth.oidc.providers[%d] 需设置 issuer、clientId 与 redirectUrl", i)
+		}
+		if p.ClientSecretEnv != "" {
+			p.ClientSecret = os.Getenv(p.ClientSecretEnv)
+			if p.ClientSecret == "" {
+				return fmt.Errorf("环境变量 %s 未设置（auth.oidc.providers[%d].clientSecretEnv）", p.ClientSecretEnv, i)
+			}
+		}
+		if p.UsernameClaim == "" {
+			p.UsernameClaim = "preferred_username"
+		}
+	}
+	return nil
+}
+
+// validateRateLimit 校验限流策略并填充默认周期与维度
+func validateRateLimit(cfg *RateLimitConfig) error {
+	for name, policy := range cfg.Policies {
+		if policy.Requests <= 0 {
+			return fmt.Errorf("配置项 rateLimit.policies.%s.requests 必须为正数", name)
+		}
+		if policy.PeriodSeconds < 0 || policy.Burst < 0 {
+			return fmt.Errorf("配置项 rateLimit.policies.%s 中的周期与突发数不能为负数", name)
+		}
+		if policy.PeriodSeconds == 0 {
+			policy.PeriodSeconds = 60
+		}
+		switch policy.Key {
+		case "":
+			policy.Key = "ip"
+		case "ip", "user":
+		default:
+			return fmt.Errorf("配置项 rateLimit.policies.%s.key 仅支持 ip 或 user", name)
+		}
+		cfg.Policies[name] = policy
+	}
+	return nil
+}
+
+// validatePasswordPolicy 校验密码策略配置并解析泄露密码库路径
+func validatePasswordPolicy(cfg *PasswordConfig) error {
+	if cfg.MinLength < 0 || cfg.MinLength > 128 {
+		return fmt.Errorf("配置项 auth.password.minLength 必须在0-128范围内")
+	}
+	for _, class := range cfg.RequiredClasses {
+		switch class {
+		case "lower", "upper", "digit", "symbol":
+		default:
+			return fmt.Errorf("配置项 auth.password.requiredClasses 包含未知类别: %s（可选 lower、upper、digit、symbol）", class)
+		}
+	}
+	if cfg.MaxAgeDays < 0 {
+		return fmt.Errorf("配置项 auth.password.maxAgeDays 不能为负数")
+	}
+	if cfg.HistorySize < 0 || cfg.HistorySize > 24 {
+		return fmt.Errorf("配置项 auth.password.historySize 必须在0-24范围内")
+	}
+	if cfg.BreachedList != "" {
+		resolved, err := resolvePath(cfg.BreachedList)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(resolved); err != nil {
+			return fmt.Errorf("泄露密码库不存在: %s", resolved)
+		}
+		cfg.BreachedList = resolved
+	}
+	return nil
+}
+
 // setConfigPath 将配置适配器路径指向 configs 目录
 func setConfigPath() error {
 	adapter, ok := g.Cfg().GetAdapter().(*gcfg.AdapterFile)
@@ -162,6 +162,236 @@ func TestValidate(t *testing.T) {
 	})
 }
 
+func TestValidateJwtKeys(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		base := func(jwt JwtConfig) *Config {
+			return &Config{
+				Server: ServerConfig{Host: "127.0.0.1"},
+				Http:   HttpConfig{Port: 8080},
+				Jwt:    jwt,
+			}
+		}
+
+		// 同时配置 file 与 env
+		err := Validate(base(JwtConfig{Keys: []JwtKeyConfig{{Id: "k1", Algorithm: "HS256", File: "a", Env: "B"}}}))
+		t.AssertNE(err, nil)
+		t.AssertIN("file 或 env", err.Error())
+
+		// 不支持的算法
+		err = Validate(base(JwtConfig{Keys: []JwtKeyConfig{{Id: "k1", Algorithm: "none", Env: "B"}}}))
+		t.AssertNE(err, nil)
+		t.AssertIN("algorithm", err.Error())
+
+		// activeKeyId 未定义
+		err = Validate(base(JwtConfig{ActiveKeyId: "k2", Keys: []JwtKeyConfig{{Id: "k1", Algorithm: "RS256", Env: "B"}}}))
+		t.AssertNE(err, nil)
+		t.AssertIN("activeKeyId", err.Error())
+
+		// retireAt 格式错误
+		err = Validate(base(JwtConfig{Keys: []JwtKeyConfig{{Id: "k1", Algorithm: "EdDSA", Env: "B", RetireAt: "tomorrow"}}}))
+		t.AssertNE(err, nil)
+		t.AssertIN("retireAt", err.Error())
+
+		// 合法配置，相对路径被解析为绝对路径
+		cfg := base(JwtConfig{ActiveKeyId: "k1", Keys: []JwtKeyConfig{{Id: "k1", Algorithm: "RS256", File: "keys/jwt.pem", RetireAt: "2030-01-01T00:00:00Z"}}})
+		t.AssertNil(Validate(cfg))
+		t.Assert(filepath.IsAbs(cfg.Jwt.Keys[0].File), true)
+	})
+}
+
+func TestValidateOidcProviders(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		base := func(providers ...OidcProviderConfig) *Config {
+			return &Config{
+				Server: ServerConfig{Host: "127.0.0.1"},
+				Http:   HttpConfig{Port: 8080},
+				Auth:   AuthConfig{Oidc: OidcConfig{Providers: providers}},
+			}
+		}
+		valid := OidcProviderConfig{Name: "corp", Issuer: "https://idp.example.com", ClientId: "go-study2", RedirectUrl: "https://app.example.com/api/v1/auth/oidc/corp/callback"}
+
+		// 名称不合法
+		invalid := valid
+		invalid.Name = "Corp IdP"
+		err := Validate(base(invalid))
+		t.AssertNE(err, nil)
+		t.AssertIN("name", err.Error())
+
+		// 名称重复
+		err = Validate(base(valid, valid))
+		t.AssertNE(err, nil)
+		t.AssertIN("重复", err.Error())
+
+		// 缺少 issuer
+		invalid = valid
+		invalid.Issuer = ""
+		err = Validate(base(invalid))
+		t.AssertNE(err, nil)
+		t.AssertIN("issuer", err.Error())
+
+		// clientSecretEnv 未设置
+		invalid = valid
+		invalid.ClientSecretEnv = "GO_STUDY2_TEST_OIDC_SECRET_UNSET"
+		err = Validate(base(invalid))
+		t.AssertNE(err, nil)
+
+		// 合法配置，从环境变量解析 secret 并补齐默认声明
+		withEnv := valid
+		withEnv.ClientSecretEnv = "GO_STUDY2_TEST_OIDC_SECRET"
+		t.Setenv("GO_STUDY2_TEST_OIDC_SECRET", "s3cret")
+		cfg := base(withEnv)
+		t.AssertNil(Validate(cfg))
+		t.Assert(cfg.Auth.Oidc.Providers[0].ClientSecret, "s3cret")
+		t.Assert(cfg.Auth.Oidc.Providers[0].UsernameClaim, "preferred_username")
+	})
+}
+
+func TestValidatePasswordPolicy(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		base := func(password PasswordConfig) *Config {
+			return &Config{
+				Server: ServerConfig{Host: "127.0.0.1"},
+				Http:   HttpConfig{Port: 8080},
+				Auth:   AuthConfig{Password: password},
+			}
+		}
+
+		t.AssertNil(Validate(base(PasswordConfig{})))
+
+		err := Validate(base(PasswordConfig{RequiredClasses: []string{"lower", "emoji"}}))
+		t.AssertNE(err, nil)
+		t.AssertIN("requiredClasses", err.Error())
+
+		err = Validate(base(PasswordConfig{HistorySize: 25}))
+		t.AssertNE(err, nil)
+		t.AssertIN("historySize", err.Error())
+
+		err = Validate(base(PasswordConfig{MaxAgeDays: -1}))
+		t.AssertNE(err, nil)
+
+		err = Validate(base(PasswordConfig{BreachedList: "testdata/missing-breached.txt"}))
+		t.AssertNE(err, nil)
+		t.AssertIN("泄露密码库", err.Error())
+
+		listFile := filepath.Join(t.TempDir(), "breached.txt")
+		t.AssertNil(os.WriteFile(listFile, []byte(""), 0o600))
+		cfg := base(PasswordConfig{MinLength: 12, BreachedList: listFile, HistorySize: 5})
+		t.AssertNil(Validate(cfg))
+		t.Assert(cfg.Auth.Password.BreachedList, listFile)
+	})
+}
+
+func TestValidateAuditConfig(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		base := func(audit AuditConfig) *Config {
+			return &Config{
+				Server: ServerConfig{Host: "127.0.0.1"},
+				Http:   HttpConfig{Port: 8080},
+				Audit:  audit,
+			}
+		}
+
+		err := Validate(base(AuditConfig{RetentionDays: -1}))
+		t.AssertNE(err, nil)
+		t.AssertIN("retentionDays", err.Error())
+
+		cfg := base(AuditConfig{RetentionDays: 90, ArchiveDir: "data/audit-archive"})
+		t.AssertNil(Validate(cfg))
+		t.Assert(filepath.IsAbs(cfg.Audit.ArchiveDir), true)
+	})
+}
+
+func TestValidateWebhooksConfig(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		cfg := &Config{
+			Server:   ServerConfig{Host: "127.0.0.1"},
+			Http:     HttpConfig{Port: 8080},
+			Webhooks: WebhooksConfig{MaxAttempts: -1},
+		}
+		err := Validate(cfg)
+		t.AssertNE(err, nil)
+		t.AssertIN("webhooks", err.Error())
+
+		cfg.Webhooks = WebhooksConfig{MaxAttempts: 3, RetryBaseSeconds: 1}
+		t.AssertNil(Validate(cfg))
+	})
+}
+
+func TestValidateJobsConfig(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		cfg := &Config{
+			Server: ServerConfig{Host: "127.0.0.1"},
+			Http:   HttpConfig{Port: 8080},
+			Jobs:   JobsConfig{Schedules: map[string]string{"token_cleanup": "61 * * * *"}},
+		}
+		err := Validate(cfg)
+		t.AssertNE(err, nil)
+		t.AssertIN("jobs.schedules.token_cleanup", err.Error())
+
+		cfg.Jobs = JobsConfig{ReviewIdleDays: -1}
+		t.AssertNE(Validate(cfg), nil)
+
+		cfg.Jobs = JobsConfig{Enabled: true, Schedules: map[string]string{"token_cleanup": "@every 30m"}}
+		t.AssertNil(Validate(cfg))
+	})
+}
+
+func TestValidateRateLimitConfig(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		cfg := &Config{
+			Server:    ServerConfig{Host: "127.0.0.1"},
+			Http:      HttpConfig{Port: 8080},
+			RateLimit: RateLimitConfig{Policies: map[string]RateLimitPolicy{"quiz": {Requests: 0}}},
+		}
+		err := Validate(cfg)
+		t.AssertNE(err, nil)
+		t.AssertIN("rateLimit.policies.quiz.requests", err.Error())
+
+		cfg.RateLimit.Policies = map[string]RateLimitPolicy{"quiz": {Requests: 10, Key: "session"}}
+		t.AssertNE(Validate(cfg), nil)
+
+		cfg.RateLimit.Policies = map[string]RateLimitPolicy{"quiz": {Requests: 10, Burst: -1}}
+		t.AssertNE(Validate(cfg), nil)
+
+		cfg.RateLimit = RateLimitConfig{Enabled: true, Policies: map[string]RateLimitPolicy{"quiz": {Requests: 10}}}
+		t.AssertNil(Validate(cfg))
+		t.Assert(cfg.RateLimit.Policies["quiz"], RateLimitPolicy{Requests: 10, PeriodSeconds: 60, Key: "ip"})
+	})
+}
+
+func TestValidateMetricsConfig(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		cfg := &Config{
+			Server:  ServerConfig{Host: "127.0.0.1"},
+			Http:    HttpConfig{Port: 8080},
+			Metrics: MetricsConfig{TokenEnv: "GOSTUDY_TEST_METRICS_TOKEN"},
+		}
+		err := Validate(cfg)
+		t.AssertNE(err, nil)
+		t.AssertIN("metrics.tokenEnv", err.Error())
+
+		t.Setenv("GOSTUDY_TEST_METRICS_TOKEN", "scrape-secret")
+		t.AssertNil(Validate(cfg))
+		t.Assert(cfg.Metrics.Token, "scrape-secret")
+	})
+}
+
+func TestValidateHealthConfig(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		cfg := &Config{
+			Server: ServerConfig{Host: "127.0.0.1"},
+			Http:   HttpConfig{Port: 8080},
+			Health: HealthConfig{DrainSeconds: -1},
+		}
+		err := Validate(cfg)
+		t.AssertNE(err, nil)
+		t.AssertIN("health", err.Error())
+
+		cfg.Health = HealthConfig{DrainSeconds: 5, CertMinValidDays: 14}
+		t.AssertNil(Validate(cfg))
+	})
+}
+
 func TestLoadWithValidConfig(t *testing.T) {
 	gtest.C(t, func(t *gtest.T) {
 		// 测试加载有效配置
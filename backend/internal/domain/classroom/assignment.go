@@ -0,0 +1,278 @@
+package classroom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-study2/internal/domain/quiz"
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/infrastructure/tracing"
+)
+
+// ErrAssignmentNotFound 表示作业不存在或不属于该班级。
+var ErrAssignmentNotFound = errors.New("作业不存在")
+
+// WithAssignments 启用班级作业能力。
+func (s *Service) WithAssignments(repo AssignmentRepository) *Service {
+	s.assignments = repo
+	return s
+}
+
+// CreateAssignment 由班级教师布置作业，章节必须属于所选主题，截止时间必须晚于开放时间。
+func (s *Service) CreateAssignment(ctx context.Context, actorID, classID int64, input AssignmentInput) (*Assignment, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.CreateAssignment")
+	defer span.End()
+	if _, err := s.manage(ctx, actorID, classID, "class_manage_denied"); err != nil {
+		return nil, err
+	}
+	if s.assignments == nil {
+		return nil, ErrAssignmentNotFound
+	}
+	title := strings.TrimSpace(input.Title)
+	topic := strings.TrimSpace(input.Topic)
+	chapter := strings.ToLower(strings.TrimSpace(input.Chapter))
+	opensAt := input.OpensAt
+	if opensAt.IsZero() {
+		opensAt = s.now()
+	}
+	if title == "" {
+		title = fmt.Sprintf("%s/%s", topic, chapter)
+	}
+	if utf8.RuneCountInString(title) > maxNameLength || !quiz.IsSupportedTopic(topic) || !quiz.IsSupportedChapter(topic, chapter) ||
+		input.MinScore < 0 || input.MinScore > 100 || input.DueAt.IsZero() || !input.DueAt.After(opensAt) {
+		return nil, ErrInvalidInput
+	}
+
+	assignment := &Assignment{
+		ClassID:   classID,
+		Title:     title,
+		Topic:     topic,
+		Chapter:   chapter,
+		MinScore:  input.MinScore,
+		OpensAt:   opensAt.UTC().Truncate(time.Second),
+		DueAt:     input.DueAt.UTC().Truncate(time.Second),
+		CreatedBy: actorID,
+		CreatedAt: s.now(),
+	}
+	id, err := s.assignments.CreateAssignment(ctx, assignment)
+	if err != nil {
+		return nil, err
+	}
+	assignment.ID = id
+	audit.Record(ctx, "assignment_created", actorID, "ok", fmt.Sprintf("class_id=%d assignment_id=%d", classID, id))
+	s.announceAssignment(ctx, actorID, assignment)
+	return assignment, nil
+}
+
+// announceAssignment 向班级学生发布新作业事件；尚未开放的作业对学生不可见，因此不通知。
+func (s *Service) announceAssignment(ctx context.Context, actorID int64, assignment *Assignment) {
+	if s.events == nil || assignment.OpensAt.After(s.now()) {
+		return
+	}
+	members, err := s.repo.ListMembers(ctx, assignment.ClassID)
+	if err != nil {
+		return
+	}
+	var students []int64
+	for _, m := range members {
+		if m.Role == RoleStudent {
+			students = append(students, m.UserID)
+		}
+	}
+	if len(students) == 0 {
+		return
+	}
+	s.events.Publish(ctx, eventbus.Event{
+		Type:    eventbus.TypeAssignment,
+		UserIDs: students,
+		ActorID: actorID,
+		Title:   "新作业：" + assignment.Title,
+		Body:    fmt.Sprintf("截止时间 %s，最低得分 %d", assignment.DueAt.Format(time.RFC3339), assignment.MinScore),
+		Link:    fmt.Sprintf("/classes/%d/assignments", assignment.ClassID),
+		Data:    map[string]interface{}{"classId": assignment.ClassID, "assignmentId": assignment.ID},
+	})
+}
+
+// ListClassAssignments 返回班级作业；学生只能看到已开放的作业。
+func (s *Service) ListClassAssignments(ctx context.Context, actorID, classID int64) ([]Assignment, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.ListClassAssignments")
+	defer span.End()
+	access, err := s.access(ctx, actorID, classID)
+	if err != nil {
+		return nil, err
+	}
+	if s.assignments == nil {
+		return []Assignment{}, nil
+	}
+	items, err := s.assignments.ListAssignments(ctx, classID)
+	if err != nil {
+		return nil, err
+	}
+	if access.canManage() {
+		return items, nil
+	}
+	now := s.now()
+	visible := make([]Assignment, 0, len(items))
+	for _, a := range items {
+		if !a.OpensAt.After(now) {
+			visible = append(visible, a)
+		}
+	}
+	return visible, nil
+}
+
+// DeleteAssignment 删除作业。
+func (s *Service) DeleteAssignment(ctx context.Context, actorID, classID, assignmentID int64) error {
+	ctx, span := tracing.Start(ctx, "classroom.Service.DeleteAssignment")
+	defer span.End()
+	if _, err := s.manage(ctx, actorID, classID, "class_manage_denied"); err != nil {
+		return err
+	}
+	if _, err := s.findAssignment(ctx, classID, assignmentID); err != nil {
+		return err
+	}
+	if err := s.assignments.DeleteAssignment(ctx, assignmentID); err != nil {
+		return err
+	}
+	audit.Record(ctx, "assignment_deleted", actorID, "ok", fmt.Sprintf("class_id=%d assignment_id=%d", classID, assignmentID))
+	return nil
+}
+
+// AssignmentReport 按学生列出作业的按时完成、迟交与缺交情况，仅班级教师与管理员可见。
+func (s *Service) AssignmentReport(ctx context.Context, actorID, classID, assignmentID int64) (*AssignmentReport, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.AssignmentReport")
+	defer span.End()
+	if _, err := s.manage(ctx, actorID, classID, "class_dashboard_denied"); err != nil {
+		return nil, err
+	}
+	assignment, err := s.findAssignment(ctx, classID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, classID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.assignments.ListAssignmentAttempts(ctx, *assignment, 0)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[int64]AssignmentAttempt, len(attempts))
+	for _, a := range attempts {
+		byUser[a.UserID] = a
+	}
+
+	now := s.now()
+	report := &AssignmentReport{
+		Assignment:  *assignment,
+		GeneratedAt: now,
+		Summary: map[string]int{
+			AssignmentOnTime:  0,
+			AssignmentLate:    0,
+			AssignmentMissing: 0,
+			AssignmentOpen:    0,
+		},
+		Students: []AssignmentResult{},
+	}
+	for _, m := range members {
+		if m.Role != RoleStudent {
+			continue
+		}
+		result := AssignmentResult{UserID: m.UserID, Username: m.Username}
+		attempt, ok := byUser[m.UserID]
+		if ok {
+			result.Attempts = attempt.Attempts
+			result.BestScore = roundPercent(attempt.BestPercent)
+			result.CompletedAt = attempt.CompletedAt
+		}
+		result.Status = assignmentStatus(*assignment, result.CompletedAt, now)
+		report.Summary[result.Status]++
+		report.Students = append(report.Students, result)
+	}
+	sort.SliceStable(report.Students, func(i, j int) bool { return report.Students[i].Username < report.Students[j].Username })
+	return report, nil
+}
+
+// MyAssignments 返回学生所在班级中已开放的作业，按未完成、已逾期与已完成分组。
+func (s *Service) MyAssignments(ctx context.Context, actorID int64) (*StudentAssignments, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.MyAssignments")
+	defer span.End()
+	if _, err := s.account(ctx, actorID); err != nil {
+		return nil, err
+	}
+	result := &StudentAssignments{
+		Open:      []StudentAssignment{},
+		Overdue:   []StudentAssignment{},
+		Completed: []StudentAssignment{},
+	}
+	if s.assignments == nil {
+		return result, nil
+	}
+	items, err := s.assignments.ListAssignmentsForStudent(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.assignments.ListStudentAssignmentAttempts(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	byAssignment := make(map[int64]AssignmentAttempt, len(attempts))
+	for _, a := range attempts {
+		byAssignment[a.AssignmentID] = a
+	}
+	now := s.now()
+	for _, item := range items {
+		if item.OpensAt.After(now) {
+			continue
+		}
+		if a, ok := byAssignment[item.ID]; ok {
+			item.Attempts = a.Attempts
+			item.BestScore = roundPercent(a.BestPercent)
+			item.CompletedAt = a.CompletedAt
+		}
+		item.Status = assignmentStatus(item.Assignment, item.CompletedAt, now)
+		switch item.Status {
+		case AssignmentOpen:
+			result.Open = append(result.Open, item)
+		case AssignmentMissing:
+			result.Overdue = append(result.Overdue, item)
+		default:
+			result.Completed = append(result.Completed, item)
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) findAssignment(ctx context.Context, classID, assignmentID int64) (*Assignment, error) {
+	if s.assignments == nil || assignmentID <= 0 {
+		return nil, ErrAssignmentNotFound
+	}
+	assignment, err := s.assignments.FindAssignment(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment == nil || assignment.ClassID != classID {
+		return nil, ErrAssignmentNotFound
+	}
+	return assignment, nil
+}
+
+// assignmentStatus 截止前达标为按时完成，截止后达标为迟交，截止后仍未达标为缺交。
+func assignmentStatus(a Assignment, completedAt *time.Time, now time.Time) string {
+	switch {
+	case completedAt != nil && !completedAt.After(a.DueAt):
+		return AssignmentOnTime
+	case completedAt != nil:
+		return AssignmentLate
+	case now.After(a.DueAt):
+		return AssignmentMissing
+	default:
+		return AssignmentOpen
+	}
+}
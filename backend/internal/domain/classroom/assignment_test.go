@@ -0,0 +1,236 @@
+package classroom
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+type mockAssignmentRepo struct {
+	classes     *mockRepo
+	assignments map[int64]*Assignment
+	// attempts 按作业 ID 记录各学生的测验汇总。
+	attempts map[int64][]AssignmentAttempt
+	autoID   int64
+	// perAssignmentCalls 统计 ListAssignmentAttempts 的调用次数。
+	perAssignmentCalls int
+}
+
+func newMockAssignmentRepo(classes *mockRepo) *mockAssignmentRepo {
+	return &mockAssignmentRepo{
+		classes:     classes,
+		assignments: make(map[int64]*Assignment),
+		attempts:    make(map[int64][]AssignmentAttempt),
+		autoID:      1,
+	}
+}
+
+func (m *mockAssignmentRepo) CreateAssignment(_ context.Context, assignment *Assignment) (int64, error) {
+	id := m.autoID
+	m.autoID++
+	clone := *assignment
+	clone.ID = id
+	m.assignments[id] = &clone
+	return id, nil
+}
+
+func (m *mockAssignmentRepo) FindAssignment(_ context.Context, id int64) (*Assignment, error) {
+	if a, ok := m.assignments[id]; ok {
+		clone := *a
+		return &clone, nil
+	}
+	return nil, nil
+}
+
+func (m *mockAssignmentRepo) ListAssignments(_ context.Context, classID int64) ([]Assignment, error) {
+	var list []Assignment
+	for _, a := range m.assignments {
+		if a.ClassID == classID {
+			list = append(list, *a)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (m *mockAssignmentRepo) ListAssignmentsForStudent(_ context.Context, userID int64) ([]StudentAssignment, error) {
+	var list []StudentAssignment
+	for _, a := range m.assignments {
+		if m.classes.isStudent(a.ClassID, userID) {
+			list = append(list, StudentAssignment{Assignment: *a, ClassName: m.classes.classes[a.ClassID].Name})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (m *mockAssignmentRepo) DeleteAssignment(_ context.Context, id int64) error {
+	delete(m.assignments, id)
+	return nil
+}
+
+func (m *mockAssignmentRepo) ListAssignmentAttempts(_ context.Context, assignment Assignment, studentID int64) ([]AssignmentAttempt, error) {
+	m.perAssignmentCalls++
+	var list []AssignmentAttempt
+	for _, a := range m.attempts[assignment.ID] {
+		if studentID <= 0 || a.UserID == studentID {
+			a.AssignmentID = assignment.ID
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockAssignmentRepo) ListStudentAssignmentAttempts(_ context.Context, userID int64) ([]AssignmentAttempt, error) {
+	var list []AssignmentAttempt
+	for id, attempts := range m.attempts {
+		if a, ok := m.assignments[id]; !ok || !m.classes.isStudent(a.ClassID, userID) {
+			continue
+		}
+		for _, a := range attempts {
+			if a.UserID == userID {
+				a.AssignmentID = id
+				list = append(list, a)
+			}
+		}
+	}
+	return list, nil
+}
+
+func TestService_CreateAssignmentValidatesInput(t *testing.T) {
+	svc, repo, class := setupService(t)
+	svc.WithAssignments(newMockAssignmentRepo(repo))
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	svc.WithClock(func() time.Time { return now })
+	_, _ = svc.Join(ctx, aliceID, class.JoinCode)
+
+	valid := AssignmentInput{Topic: "types", Chapter: "interface_impl", MinScore: 80, DueAt: now.Add(72 * time.Hour)}
+	if _, err := svc.CreateAssignment(ctx, aliceID, class.ID, valid); err != ErrPermissionDenied {
+		t.Fatalf("学生不应能布置作业，得到: %v", err)
+	}
+	if _, err := svc.CreateAssignment(ctx, otherTchID, class.ID, valid); err != ErrClassNotFound {
+		t.Fatalf("其他教师不应能布置作业，得到: %v", err)
+	}
+
+	invalid := []AssignmentInput{
+		{Topic: "unknown", Chapter: "x", DueAt: now.Add(time.Hour)},
+		{Topic: "types", Chapter: " ", DueAt: now.Add(time.Hour)},
+		{Topic: "types", Chapter: "no_such_chapter", DueAt: now.Add(time.Hour)},
+		{Topic: "constants", Chapter: "storage", DueAt: now.Add(time.Hour)},
+		{Topic: "types", Chapter: "interface_impl", MinScore: 101, DueAt: now.Add(time.Hour)},
+		{Topic: "types", Chapter: "interface_impl"},
+		{Topic: "types", Chapter: "interface_impl", OpensAt: now.Add(2 * time.Hour), DueAt: now.Add(time.Hour)},
+	}
+	for i, input := range invalid {
+		if _, err := svc.CreateAssignment(ctx, teacherID, class.ID, input); err != ErrInvalidInput {
+			t.Fatalf("第 %d 个非法输入应被拒绝，得到: %v", i, err)
+		}
+	}
+
+	created, err := svc.CreateAssignment(ctx, teacherID, class.ID, valid)
+	if err != nil {
+		t.Fatalf("布置作业失败: %v", err)
+	}
+	if created.Title != "types/interface_impl" || !created.OpensAt.Equal(now) || created.ClassID != class.ID {
+		t.Fatalf("作业默认值不正确: %+v", created)
+	}
+
+	if _, err := svc.AssignmentReport(ctx, teacherID, class.ID, created.ID+100); err != ErrAssignmentNotFound {
+		t.Fatalf("不存在的作业应返回 ErrAssignmentNotFound，得到: %v", err)
+	}
+	if err := svc.DeleteAssignment(ctx, aliceID, class.ID, created.ID); err != ErrPermissionDenied {
+		t.Fatalf("学生不应能删除作业，得到: %v", err)
+	}
+	if err := svc.DeleteAssignment(ctx, teacherID, class.ID, created.ID); err != nil {
+		t.Fatalf("删除作业失败: %v", err)
+	}
+}
+
+func TestService_AssignmentReportAndStudentView(t *testing.T) {
+	svc, repo, class := setupService(t)
+	assignments := newMockAssignmentRepo(repo)
+	svc.WithAssignments(assignments)
+	ctx := context.Background()
+	start := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	now := start
+	svc.WithClock(func() time.Time { return now })
+	_, _ = svc.Join(ctx, aliceID, class.JoinCode)
+	_, _ = svc.Join(ctx, bobID, class.JoinCode)
+	repo.addAccount(7, "carol", false, false)
+	_, _ = svc.Join(ctx, 7, class.JoinCode)
+
+	due := start.Add(96 * time.Hour)
+	current, err := svc.CreateAssignment(ctx, teacherID, class.ID, AssignmentInput{Title: "接口实现", Topic: "types", Chapter: "interface_impl", MinScore: 80, DueAt: due})
+	if err != nil {
+		t.Fatalf("布置作业失败: %v", err)
+	}
+	future, err := svc.CreateAssignment(ctx, teacherID, class.ID, AssignmentInput{Topic: "variables", Chapter: "storage", OpensAt: start.Add(240 * time.Hour), DueAt: start.Add(300 * time.Hour)})
+	if err != nil {
+		t.Fatalf("布置未开放作业失败: %v", err)
+	}
+
+	visible, _ := svc.ListClassAssignments(ctx, aliceID, class.ID)
+	if len(visible) != 1 || visible[0].ID != current.ID {
+		t.Fatalf("学生只应看到已开放的作业: %+v", visible)
+	}
+	if all, _ := svc.ListClassAssignments(ctx, teacherID, class.ID); len(all) != 2 {
+		t.Fatalf("教师应看到全部作业: %+v", all)
+	}
+
+	onTime := due.Add(-time.Hour)
+	late := due.Add(time.Hour)
+	assignments.attempts[current.ID] = []AssignmentAttempt{
+		{UserID: aliceID, Attempts: 2, BestPercent: 90, CompletedAt: &onTime},
+		{UserID: bobID, Attempts: 3, BestPercent: 85, CompletedAt: &late},
+		{UserID: 7, Attempts: 1, BestPercent: 50},
+	}
+
+	mine, err := svc.MyAssignments(ctx, 7)
+	if err != nil {
+		t.Fatalf("查询学生作业失败: %v", err)
+	}
+	if len(mine.Open) != 1 || len(mine.Overdue) != 0 || mine.Open[0].ClassName != class.Name || *mine.Open[0].BestScore != 50 {
+		t.Fatalf("截止前未达标应为未完成: %+v", mine)
+	}
+
+	now = due.Add(2 * time.Hour)
+	report, err := svc.AssignmentReport(ctx, teacherID, class.ID, current.ID)
+	if err != nil {
+		t.Fatalf("生成作业报告失败: %v", err)
+	}
+	want := map[string]string{"alice": AssignmentOnTime, "bob": AssignmentLate, "carol": AssignmentMissing}
+	if len(report.Students) != 3 {
+		t.Fatalf("报告应只包含学生: %+v", report.Students)
+	}
+	for _, s := range report.Students {
+		if want[s.Username] != s.Status {
+			t.Fatalf("%s 状态应为 %s，得到 %s", s.Username, want[s.Username], s.Status)
+		}
+	}
+	if report.Summary[AssignmentOnTime] != 1 || report.Summary[AssignmentLate] != 1 || report.Summary[AssignmentMissing] != 1 || report.Summary[AssignmentOpen] != 0 {
+		t.Fatalf("汇总不正确: %+v", report.Summary)
+	}
+	if _, err := svc.AssignmentReport(ctx, aliceID, class.ID, current.ID); err != ErrPermissionDenied {
+		t.Fatalf("学生不应能查看作业报告，得到: %v", err)
+	}
+
+	mine, _ = svc.MyAssignments(ctx, 7)
+	if len(mine.Overdue) != 1 || len(mine.Open) != 0 {
+		t.Fatalf("截止后未达标应为逾期: %+v", mine)
+	}
+	assignments.perAssignmentCalls = 0
+	mine, _ = svc.MyAssignments(ctx, bobID)
+	if len(mine.Completed) != 1 || mine.Completed[0].Status != AssignmentLate {
+		t.Fatalf("迟交应归入已完成: %+v", mine)
+	}
+	if assignments.perAssignmentCalls != 0 {
+		t.Fatalf("学生作业列表应一次查询全部作业的测验汇总，逐个作业查询了 %d 次", assignments.perAssignmentCalls)
+	}
+	for _, item := range append(mine.Open, mine.Overdue...) {
+		if item.ID == future.ID {
+			t.Fatalf("未开放的作业不应出现在学生列表中")
+		}
+	}
+}
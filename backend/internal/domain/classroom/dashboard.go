@@ -0,0 +1,282 @@
+package classroom
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"go-study2/internal/domain/progress"
+)
+
+// chapterKey 标识某主题下的章节。
+type chapterKey struct {
+	topic   string
+	chapter string
+}
+
+// scoreAcc 按测验次数加权累计平均得分率。
+type scoreAcc struct {
+	attempts int
+	sum      float64
+}
+
+func (a *scoreAcc) add(avg float64, attempts int) {
+	a.attempts += attempts
+	a.sum += avg * float64(attempts)
+}
+
+func (a scoreAcc) avg() *float64 {
+	if a.attempts == 0 {
+		return nil
+	}
+	return roundPercent(a.sum / float64(a.attempts))
+}
+
+func roundPercent(v float64) *float64 {
+	rounded := math.Round(v*10) / 10
+	return &rounded
+}
+
+// buildDashboard 由原始进度与测验汇总计算班级看板。
+func (s *Service) buildDashboard(class Class, members []Member, rows []ChapterProgress, quizzes []QuizSummary) *Dashboard {
+	students := s.buildStudents(members, rows, quizzes)
+
+	chapters := make(map[chapterKey]*ChapterStats)
+	chapterScores := make(map[chapterKey]*scoreAcc)
+	topics := make(map[string]*TopicStats)
+	topicScores := make(map[string]*scoreAcc)
+	var total scoreAcc
+	summary := DashboardSummary{Students: len(students)}
+
+	for _, st := range students {
+		if st.Stuck {
+			summary.StuckStudents++
+		}
+		if !containsReason(st.StuckReasons, StuckInactive) {
+			summary.ActiveStudents++
+		}
+		started := make(map[string]bool)
+		for _, ch := range st.Chapters {
+			key := chapterKey{topic: ch.Topic, chapter: ch.Chapter}
+			stats, ok := chapters[key]
+			if !ok {
+				stats = &ChapterStats{Topic: ch.Topic, Chapter: ch.Chapter}
+				chapters[key] = stats
+				chapterScores[key] = &scoreAcc{}
+			}
+			topic, ok := topics[ch.Topic]
+			if !ok {
+				topic = &TopicStats{Topic: ch.Topic}
+				topics[ch.Topic] = topic
+				topicScores[ch.Topic] = &scoreAcc{}
+			}
+			switch ch.Status {
+			case progress.StatusDone:
+				stats.Done++
+				topic.ChaptersDone++
+			case progress.StatusInProgress:
+				stats.InProgress++
+			}
+			if ch.Stalled || ch.LowScore {
+				stats.StuckStudents++
+			}
+			if ch.QuizAttempts > 0 {
+				stats.QuizAttempts += ch.QuizAttempts
+				topic.QuizAttempts += ch.QuizAttempts
+				chapterScores[key].add(*ch.AvgScore, ch.QuizAttempts)
+				topicScores[ch.Topic].add(*ch.AvgScore, ch.QuizAttempts)
+				total.add(*ch.AvgScore, ch.QuizAttempts)
+			}
+			started[ch.Topic] = true
+		}
+		for topic := range started {
+			topics[topic].StudentsStarted++
+		}
+	}
+
+	dashboard := &Dashboard{
+		Class:       class,
+		GeneratedAt: s.now(),
+		Students:    students,
+		Topics:      make([]TopicStats, 0, len(topics)),
+		Chapters:    make([]ChapterStats, 0, len(chapters)),
+	}
+	for key, stats := range chapters {
+		stats.AvgScore = chapterScores[key].avg()
+		dashboard.Chapters = append(dashboard.Chapters, *stats)
+	}
+	sort.Slice(dashboard.Chapters, func(i, j int) bool {
+		a, b := dashboard.Chapters[i], dashboard.Chapters[j]
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.Chapter < b.Chapter
+	})
+	for name, stats := range topics {
+		stats.AvgScore = topicScores[name].avg()
+		dashboard.Topics = append(dashboard.Topics, *stats)
+	}
+	sort.Slice(dashboard.Topics, func(i, j int) bool { return dashboard.Topics[i].Topic < dashboard.Topics[j].Topic })
+
+	summary.QuizAttempts = total.attempts
+	summary.AvgScore = total.avg()
+	dashboard.Summary = summary
+
+	// 看板列表只保留每个学生的主题汇总，章节明细通过单个学生报告查看
+	for i := range dashboard.Students {
+		dashboard.Students[i].Chapters = nil
+	}
+	return dashboard
+}
+
+// buildStudents 计算每个学生成员的汇总与章节明细，卡住的学生排在前面。
+func (s *Service) buildStudents(members []Member, rows []ChapterProgress, quizzes []QuizSummary) []StudentStats {
+	now := s.now()
+	type studentAcc struct {
+		stats    *StudentStats
+		chapters map[chapterKey]*StudentChapterStat
+	}
+	byID := make(map[int64]*studentAcc)
+	order := make([]*studentAcc, 0, len(members))
+	for _, m := range members {
+		if m.Role != RoleStudent {
+			continue
+		}
+		acc := &studentAcc{
+			stats:    &StudentStats{UserID: m.UserID, Username: m.Username, StuckReasons: []string{}, Topics: []StudentTopicStats{}},
+			chapters: make(map[chapterKey]*StudentChapterStat),
+		}
+		byID[m.UserID] = acc
+		order = append(order, acc)
+	}
+
+	chapterOf := func(acc *studentAcc, topic, chapter string) *StudentChapterStat {
+		key := chapterKey{topic: topic, chapter: chapter}
+		ch, ok := acc.chapters[key]
+		if !ok {
+			ch = &StudentChapterStat{Topic: topic, Chapter: chapter, Status: progress.StatusNotStarted}
+			acc.chapters[key] = ch
+		}
+		return ch
+	}
+	touch := func(stats *StudentStats, at time.Time) {
+		if at.IsZero() {
+			return
+		}
+		if stats.LastActivity == nil || at.After(*stats.LastActivity) {
+			t := at
+			stats.LastActivity = &t
+		}
+	}
+
+	for _, row := range rows {
+		acc, ok := byID[row.UserID]
+		if !ok {
+			continue
+		}
+		ch := chapterOf(acc, row.Topic, row.Chapter)
+		ch.Status = row.Status
+		if !row.LastVisit.IsZero() {
+			visit := row.LastVisit
+			ch.LastVisit = &visit
+		}
+		touch(acc.stats, row.LastVisit)
+	}
+	for _, q := range quizzes {
+		acc, ok := byID[q.UserID]
+		if !ok || q.Attempts == 0 {
+			continue
+		}
+		ch := chapterOf(acc, q.Topic, q.Chapter)
+		ch.QuizAttempts += q.Attempts
+		ch.AvgScore = roundPercent(q.AvgPercent)
+		ch.BestScore = roundPercent(q.BestPercent)
+		touch(acc.stats, q.LastAt)
+	}
+
+	result := make([]StudentStats, 0, len(order))
+	for _, acc := range order {
+		st := acc.stats
+		topics := make(map[string]*StudentTopicStats)
+		topicScores := make(map[string]*scoreAcc)
+		var total scoreAcc
+		stalled, lowScore := false, false
+
+		chapters := make([]StudentChapterStat, 0, len(acc.chapters))
+		for _, ch := range acc.chapters {
+			topic, ok := topics[ch.Topic]
+			if !ok {
+				topic = &StudentTopicStats{Topic: ch.Topic}
+				topics[ch.Topic] = topic
+				topicScores[ch.Topic] = &scoreAcc{}
+			}
+			switch ch.Status {
+			case progress.StatusDone:
+				st.ChaptersDone++
+				topic.ChaptersDone++
+			case progress.StatusInProgress:
+				st.ChaptersInProgress++
+				topic.ChaptersInProgress++
+				if ch.LastVisit != nil && now.Sub(*ch.LastVisit) >= s.stuck.StalledAfter {
+					ch.Stalled = true
+					stalled = true
+				}
+			}
+			if ch.QuizAttempts > 0 {
+				st.QuizAttempts += ch.QuizAttempts
+				topic.QuizAttempts += ch.QuizAttempts
+				topicScores[ch.Topic].add(*ch.AvgScore, ch.QuizAttempts)
+				total.add(*ch.AvgScore, ch.QuizAttempts)
+				if ch.QuizAttempts >= s.stuck.MinAttempts && *ch.AvgScore < s.stuck.LowScorePercent && ch.Status != progress.StatusDone {
+					ch.LowScore = true
+					lowScore = true
+				}
+			}
+			chapters = append(chapters, *ch)
+		}
+
+		st.AvgScore = total.avg()
+		for name, topic := range topics {
+			topic.AvgScore = topicScores[name].avg()
+			st.Topics = append(st.Topics, *topic)
+		}
+		sort.Slice(st.Topics, func(i, j int) bool { return st.Topics[i].Topic < st.Topics[j].Topic })
+
+		if st.LastActivity == nil || now.Sub(*st.LastActivity) >= s.stuck.InactiveAfter {
+			st.StuckReasons = append(st.StuckReasons, StuckInactive)
+		}
+		if stalled {
+			st.StuckReasons = append(st.StuckReasons, StuckStalledChapter)
+		}
+		if lowScore {
+			st.StuckReasons = append(st.StuckReasons, StuckLowQuizScore)
+		}
+		st.Stuck = len(st.StuckReasons) > 0
+
+		sort.Slice(chapters, func(i, j int) bool {
+			if chapters[i].Topic != chapters[j].Topic {
+				return chapters[i].Topic < chapters[j].Topic
+			}
+			return chapters[i].Chapter < chapters[j].Chapter
+		})
+		st.Chapters = chapters
+		result = append(result, *st)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Stuck != result[j].Stuck {
+			return result[i].Stuck
+		}
+		return result[i].Username < result[j].Username
+	})
+	return result
+}
+
+func containsReason(reasons []string, reason string) bool {
+	for _, r := range reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package classroom
+
+import "time"
+
+// 班级成员角色。
+const (
+	RoleTeacher = "teacher"
+	RoleStudent = "student"
+)
+
+// 学生被判定为“卡住”的原因。
+const (
+	StuckInactive       = "inactive"
+	StuckStalledChapter = "stalled_chapter"
+	StuckLowQuizScore   = "low_quiz_score"
+)
+
+// Class 表示一个学习班级，加入码只对班级教师与管理员可见。
+type Class struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	JoinCode     string    `json:"joinCode,omitempty"`
+	CreatedBy    int64     `json:"createdBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+	StudentCount int       `json:"studentCount"`
+	// LeaderboardEnabled 为班级排行榜开关，仅管理员可修改。
+	LeaderboardEnabled bool `json:"leaderboardEnabled"`
+	// Role 为当前用户在班级中的角色，管理员查看非所属班级时为空。
+	Role string `json:"role,omitempty"`
+}
+
+// Member 表示班级成员。
+type Member struct {
+	ClassID  int64     `json:"classId"`
+	UserID   int64     `json:"userId"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// Account 为班级权限判断所需的用户信息。
+type Account struct {
+	ID        int64
+	Username  string
+	IsAdmin   bool
+	IsTeacher bool
+}
+
+// ChapterProgress 为学生在某章节的学习进度。
+type ChapterProgress struct {
+	UserID    int64
+	Topic     string
+	Chapter   string
+	Status    string
+	LastVisit time.Time
+}
+
+// QuizSummary 为学生在某章节的测验汇总，分数均为百分比。
+type QuizSummary struct {
+	UserID      int64
+	Topic       string
+	Chapter     string
+	Attempts    int
+	AvgPercent  float64
+	BestPercent float64
+	LastAt      time.Time
+}
+
+// ClassDetail 为班级详情，学生只能看到教师名单。
+type ClassDetail struct {
+	Class   Class    `json:"class"`
+	Members []Member `json:"members"`
+}
+
+// Dashboard 为教师查看的班级学习看板。
+type Dashboard struct {
+	Class       Class            `json:"class"`
+	GeneratedAt time.Time        `json:"generatedAt"`
+	Summary     DashboardSummary `json:"summary"`
+	Students    []StudentStats   `json:"students"`
+	Topics      []TopicStats     `json:"topics"`
+	Chapters    []ChapterStats   `json:"chapters"`
+}
+
+// DashboardSummary 为班级整体指标。
+type DashboardSummary struct {
+	Students       int `json:"students"`
+	ActiveStudents int `json:"activeStudents"`
+	StuckStudents  int `json:"stuckStudents"`
+	// AvgScore 为全部测验的平均得分率（百分比），无测验时为空。
+	AvgScore     *float64 `json:"avgScore"`
+	QuizAttempts int      `json:"quizAttempts"`
+}
+
+// StudentStats 为单个学生的汇总指标。
+type StudentStats struct {
+	UserID             int64                `json:"userId"`
+	Username           string               `json:"username"`
+	ChaptersDone       int                  `json:"chaptersDone"`
+	ChaptersInProgress int                  `json:"chaptersInProgress"`
+	QuizAttempts       int                  `json:"quizAttempts"`
+	AvgScore           *float64             `json:"avgScore"`
+	LastActivity       *time.Time           `json:"lastActivity"`
+	Stuck              bool                 `json:"stuck"`
+	StuckReasons       []string             `json:"stuckReasons"`
+	Topics             []StudentTopicStats  `json:"topics"`
+	Chapters           []StudentChapterStat `json:"chapters,omitempty"`
+}
+
+// StudentTopicStats 为学生在某主题下的汇总。
+type StudentTopicStats struct {
+	Topic              string   `json:"topic"`
+	ChaptersDone       int      `json:"chaptersDone"`
+	ChaptersInProgress int      `json:"chaptersInProgress"`
+	QuizAttempts       int      `json:"quizAttempts"`
+	AvgScore           *float64 `json:"avgScore"`
+}
+
+// StudentChapterStat 为学生在某章节的明细，仅在单个学生报告中返回。
+type StudentChapterStat struct {
+	Topic        string     `json:"topic"`
+	Chapter      string     `json:"chapter"`
+	Status       string     `json:"status"`
+	LastVisit    *time.Time `json:"lastVisit"`
+	QuizAttempts int        `json:"quizAttempts"`
+	AvgScore     *float64   `json:"avgScore"`
+	BestScore    *float64   `json:"bestScore"`
+	Stalled      bool       `json:"stalled"`
+	LowScore     bool       `json:"lowScore"`
+}
+
+// TopicStats 为班级在某主题下的汇总。
+type TopicStats struct {
+	Topic           string   `json:"topic"`
+	StudentsStarted int      `json:"studentsStarted"`
+	ChaptersDone    int      `json:"chaptersDone"`
+	QuizAttempts    int      `json:"quizAttempts"`
+	AvgScore        *float64 `json:"avgScore"`
+}
+
+// ChapterStats 为班级在某章节的汇总。
+type ChapterStats struct {
+	Topic         string   `json:"topic"`
+	Chapter       string   `json:"chapter"`
+	Done          int      `json:"done"`
+	InProgress    int      `json:"inProgress"`
+	QuizAttempts  int      `json:"quizAttempts"`
+	AvgScore      *float64 `json:"avgScore"`
+	StuckStudents int      `json:"stuckStudents"`
+}
+
+// 作业完成状态。
+const (
+	AssignmentOpen    = "open"
+	AssignmentOnTime  = "on_time"
+	AssignmentLate    = "late"
+	AssignmentMissing = "missing"
+)
+
+// Assignment 表示布置给班级的章节作业，学生在开放后达到要求分数的测验即视为完成。
+type Assignment struct {
+	ID      int64  `json:"id"`
+	ClassID int64  `json:"classId"`
+	Title   string `json:"title"`
+	Topic   string `json:"topic"`
+	Chapter string `json:"chapter"`
+	// MinScore 要求的测验得分率（百分比），0 表示提交任意测验即可。
+	MinScore  int       `json:"minScore"`
+	OpensAt   time.Time `json:"opensAt"`
+	DueAt     time.Time `json:"dueAt"`
+	CreatedBy int64     `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AssignmentInput 为创建作业的参数。
+type AssignmentInput struct {
+	Title    string
+	Topic    string
+	Chapter  string
+	MinScore int
+	// OpensAt 为零值时立即开放。
+	OpensAt time.Time
+	DueAt   time.Time
+}
+
+// AssignmentAttempt 为学生在作业开放后的测验汇总。
+type AssignmentAttempt struct {
+	AssignmentID int64
+	UserID       int64
+	Attempts     int
+	BestPercent  float64
+	// CompletedAt 为首次达到要求分数的时间，未达到时为 nil。
+	CompletedAt *time.Time
+}
+
+// AssignmentResult 为学生在某作业上的完成情况。
+type AssignmentResult struct {
+	UserID      int64      `json:"userId"`
+	Username    string     `json:"username"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	BestScore   *float64   `json:"bestScore"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+// AssignmentReport 为教师查看的作业完成报告。
+type AssignmentReport struct {
+	Assignment  Assignment         `json:"assignment"`
+	GeneratedAt time.Time          `json:"generatedAt"`
+	Summary     map[string]int     `json:"summary"`
+	Students    []AssignmentResult `json:"students"`
+}
+
+// StudentAssignment 为学生视角的作业及完成情况。
+type StudentAssignment struct {
+	Assignment
+	ClassName   string     `json:"className"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	BestScore   *float64   `json:"bestScore"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+// StudentAssignments 按状态分组的学生作业列表。
+type StudentAssignments struct {
+	Open      []StudentAssignment `json:"open"`
+	Overdue   []StudentAssignment `json:"overdue"`
+	Completed []StudentAssignment `json:"completed"`
+}
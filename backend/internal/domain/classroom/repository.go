@@ -0,0 +1,45 @@
+package classroom
+
+import "context"
+
+// Repository 定义班级领域所需的持久化接口。
+type Repository interface {
+	// CreateClass 创建班级并把创建者登记为教师成员。
+	CreateClass(ctx context.Context, class *Class) (int64, error)
+	FindClass(ctx context.Context, id int64) (*Class, error)
+	FindClassByJoinCode(ctx context.Context, code string) (*Class, error)
+	ListClasses(ctx context.Context) ([]Class, error)
+	// ListClassesForUser 返回用户所属的班级，Role 为其在班级中的角色。
+	ListClassesForUser(ctx context.Context, userID int64) ([]Class, error)
+	UpdateJoinCode(ctx context.Context, classID int64, code string) error
+	UpdateLeaderboardEnabled(ctx context.Context, classID int64, enabled bool) error
+	DeleteClass(ctx context.Context, id int64) error
+
+	AddMember(ctx context.Context, member Member) error
+	RemoveMember(ctx context.Context, classID, userID int64) error
+	FindMember(ctx context.Context, classID, userID int64) (*Member, error)
+	ListMembers(ctx context.Context, classID int64) ([]Member, error)
+
+	FindAccount(ctx context.Context, id int64) (*Account, error)
+	FindAccountByUsername(ctx context.Context, username string) (*Account, error)
+
+	// ListStudentProgress 返回班级学生的章节进度，studentID 大于 0 时只返回该学生。
+	ListStudentProgress(ctx context.Context, classID, studentID int64) ([]ChapterProgress, error)
+	// ListStudentQuizSummaries 按学生与章节汇总班级学生的测验记录，studentID 大于 0 时只返回该学生。
+	ListStudentQuizSummaries(ctx context.Context, classID, studentID int64) ([]QuizSummary, error)
+}
+
+// AssignmentRepository 定义班级作业的持久化接口。
+type AssignmentRepository interface {
+	CreateAssignment(ctx context.Context, assignment *Assignment) (int64, error)
+	FindAssignment(ctx context.Context, id int64) (*Assignment, error)
+	// ListAssignments 按截止时间升序列出班级作业。
+	ListAssignments(ctx context.Context, classID int64) ([]Assignment, error)
+	// ListAssignmentsForStudent 列出学生所在全部班级的作业，附带班级名称。
+	ListAssignmentsForStudent(ctx context.Context, userID int64) ([]StudentAssignment, error)
+	DeleteAssignment(ctx context.Context, id int64) error
+	// ListAssignmentAttempts 汇总班级学生在作业开放后对目标章节的测验，studentID 大于 0 时只返回该学生。
+	ListAssignmentAttempts(ctx context.Context, assignment Assignment, studentID int64) ([]AssignmentAttempt, error)
+	// ListStudentAssignmentAttempts 一次汇总学生在所在班级全部作业上的测验，没有测验的作业不返回。
+	ListStudentAssignmentAttempts(ctx context.Context, userID int64) ([]AssignmentAttempt, error)
+}
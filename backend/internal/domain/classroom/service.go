@@ -0,0 +1,531 @@
+package classroom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/infrastructure/tracing"
+	"go-study2/internal/pkg/randcode"
+)
+
+// 域内错误定义，便于 handler 做精确映射。
+var (
+	ErrInvalidInput     = errors.New("班级参数不合法")
+	ErrClassNotFound    = errors.New("班级不存在")
+	ErrPermissionDenied = errors.New("无权执行该班级操作")
+	ErrUserNotFound     = errors.New("用户不存在")
+	ErrAlreadyMember    = errors.New("用户已在班级中")
+	ErrNotMember        = errors.New("用户不在该班级")
+	ErrJoinCodeInvalid  = errors.New("班级加入码无效")
+	ErrLastTeacher      = errors.New("班级至少需要保留一名教师")
+)
+
+const (
+	maxNameLength        = 100
+	maxDescriptionLength = 500
+	joinCodeLength       = 8
+)
+
+// StuckPolicy 描述判定学生“卡住”的阈值。
+type StuckPolicy struct {
+	// InactiveAfter 超过该时长没有任何学习或测验记录视为不活跃。
+	InactiveAfter time.Duration
+	// StalledAfter 章节停留在学习中状态超过该时长视为停滞。
+	StalledAfter time.Duration
+	// LowScorePercent 章节平均得分率低于该值视为低分。
+	LowScorePercent float64
+	// MinAttempts 判定低分所需的最少测验次数，避免一次失误即被标记。
+	MinAttempts int
+}
+
+// DefaultStuckPolicy 返回默认阈值：7 天不活跃、章节停滞 3 天、两次以上测验平均低于 60%。
+func DefaultStuckPolicy() StuckPolicy {
+	return StuckPolicy{
+		InactiveAfter:   7 * 24 * time.Hour,
+		StalledAfter:    3 * 24 * time.Hour,
+		LowScorePercent: 60,
+		MinAttempts:     2,
+	}
+}
+
+// Service 封装班级管理、成员加入与教师看板的业务逻辑。
+//
+// 所有读取学生数据的入口都先校验操作者与班级的关系：教师只能看到自己班级的学生，
+// 学生只能看到自己所在的班级与自己的报告。
+type Service struct {
+	repo Repository
+	// assignments 为 nil 时不提供作业功能。
+	assignments AssignmentRepository
+	stuck       StuckPolicy
+	// events 为 nil 时不发布领域事件。
+	events eventbus.Publisher
+	now    func() time.Time
+}
+
+// NewService 创建班级服务。
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo, stuck: DefaultStuckPolicy(), now: time.Now}
+}
+
+// WithEvents 设置领域事件发布者，用于通知学生新作业等。
+func (s *Service) WithEvents(pub eventbus.Publisher) *Service {
+	s.events = pub
+	return s
+}
+
+// WithStuckPolicy 覆盖“卡住”判定阈值。
+func (s *Service) WithStuckPolicy(policy StuckPolicy) *Service {
+	s.stuck = policy
+	return s
+}
+
+// WithClock 注入时钟，便于测试活跃度判定。
+func (s *Service) WithClock(now func() time.Time) *Service {
+	if now != nil {
+		s.now = now
+	}
+	return s
+}
+
+// classAccess 为操作者与班级的关系。
+type classAccess struct {
+	class   *Class
+	account *Account
+	member  *Member
+}
+
+// canManage 班级教师与管理员可以管理成员并查看看板。
+func (a classAccess) canManage() bool {
+	return a.account.IsAdmin || (a.member != nil && a.member.Role == RoleTeacher)
+}
+
+// CreateClass 由教师或管理员创建班级，创建者自动成为班级教师。
+func (s *Service) CreateClass(ctx context.Context, actorID int64, name, description string) (*Class, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.CreateClass")
+	defer span.End()
+	account, err := s.account(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsTeacher && !account.IsAdmin {
+		audit.Record(ctx, "class_create_denied", actorID, "permission_denied", "")
+		return nil, ErrPermissionDenied
+	}
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength || utf8.RuneCountInString(description) > maxDescriptionLength {
+		return nil, ErrInvalidInput
+	}
+
+	code, err := randcode.New(joinCodeLength)
+	if err != nil {
+		return nil, err
+	}
+	class := &Class{
+		Name:               name,
+		Description:        description,
+		JoinCode:           code,
+		CreatedBy:          actorID,
+		CreatedAt:          s.now(),
+		Role:               RoleTeacher,
+		LeaderboardEnabled: true,
+	}
+	id, err := s.repo.CreateClass(ctx, class)
+	if err != nil {
+		return nil, err
+	}
+	class.ID = id
+	audit.Record(ctx, "class_created", actorID, "ok", fmt.Sprintf("class_id=%d", id))
+	return class, nil
+}
+
+// ListClasses 返回操作者所属的班级；管理员传入 all 时返回全部班级。
+func (s *Service) ListClasses(ctx context.Context, actorID int64, all bool) ([]Class, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.ListClasses")
+	defer span.End()
+	account, err := s.account(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	var classes []Class
+	if all && account.IsAdmin {
+		classes, err = s.repo.ListClasses(ctx)
+	} else {
+		classes, err = s.repo.ListClassesForUser(ctx, actorID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range classes {
+		if classes[i].Role != RoleTeacher && !account.IsAdmin {
+			classes[i].JoinCode = ""
+		}
+	}
+	return classes, nil
+}
+
+// GetClass 返回班级详情；学生只能看到教师与自己，看不到其他同学。
+func (s *Service) GetClass(ctx context.Context, actorID, classID int64) (*ClassDetail, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.GetClass")
+	defer span.End()
+	access, err := s.access(ctx, actorID, classID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, classID)
+	if err != nil {
+		return nil, err
+	}
+	detail := &ClassDetail{Class: *access.class, Members: make([]Member, 0, len(members))}
+	manage := access.canManage()
+	for _, m := range members {
+		if manage || m.Role == RoleTeacher || m.UserID == actorID {
+			detail.Members = append(detail.Members, m)
+		}
+	}
+	if !manage {
+		detail.Class.JoinCode = ""
+	}
+	return detail, nil
+}
+
+// DeleteClass 删除班级与成员关系，学习记录保留在学生名下。
+func (s *Service) DeleteClass(ctx context.Context, actorID, classID int64) error {
+	ctx, span := tracing.Start(ctx, "classroom.Service.DeleteClass")
+	defer span.End()
+	if _, err := s.manage(ctx, actorID, classID, "class_delete_denied"); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteClass(ctx, classID); err != nil {
+		return err
+	}
+	audit.Record(ctx, "class_deleted", actorID, "ok", fmt.Sprintf("class_id=%d", classID))
+	return nil
+}
+
+// RegenerateJoinCode 重新生成加入码，旧加入码立即失效。
+func (s *Service) RegenerateJoinCode(ctx context.Context, actorID, classID int64) (*Class, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.RegenerateJoinCode")
+	defer span.End()
+	access, err := s.manage(ctx, actorID, classID, "class_manage_denied")
+	if err != nil {
+		return nil, err
+	}
+	code, err := randcode.New(joinCodeLength)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateJoinCode(ctx, classID, code); err != nil {
+		return nil, err
+	}
+	access.class.JoinCode = code
+	audit.Record(ctx, "class_join_code_regenerated", actorID, "ok", fmt.Sprintf("class_id=%d", classID))
+	return access.class, nil
+}
+
+// SetLeaderboardEnabled 由管理员开启或关闭班级排行榜。
+func (s *Service) SetLeaderboardEnabled(ctx context.Context, actorID, classID int64, enabled bool) (*Class, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.SetLeaderboardEnabled")
+	defer span.End()
+	access, err := s.access(ctx, actorID, classID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.account.IsAdmin {
+		audit.Record(ctx, "class_leaderboard_denied", actorID, "permission_denied", fmt.Sprintf("class_id=%d", classID))
+		return nil, ErrPermissionDenied
+	}
+	if err := s.repo.UpdateLeaderboardEnabled(ctx, classID, enabled); err != nil {
+		return nil, err
+	}
+	access.class.LeaderboardEnabled = enabled
+	audit.Record(ctx, "class_leaderboard_changed", actorID, "ok", fmt.Sprintf("class_id=%d enabled=%t", classID, enabled))
+	return access.class, nil
+}
+
+// AddMember 由班级教师按用户名添加成员，添加教师时目标用户必须具备教师角色。
+func (s *Service) AddMember(ctx context.Context, actorID, classID int64, username, role string) (*Member, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.AddMember")
+	defer span.End()
+	if _, err := s.manage(ctx, actorID, classID, "class_manage_denied"); err != nil {
+		return nil, err
+	}
+	username = strings.TrimSpace(username)
+	role = strings.TrimSpace(role)
+	if role == "" {
+		role = RoleStudent
+	}
+	if username == "" || (role != RoleStudent && role != RoleTeacher) {
+		return nil, ErrInvalidInput
+	}
+	target, err := s.repo.FindAccountByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrUserNotFound
+	}
+	if role == RoleTeacher && !target.IsTeacher && !target.IsAdmin {
+		return nil, ErrInvalidInput
+	}
+	return s.enroll(ctx, actorID, classID, target, role, "class_member_added")
+}
+
+// Join 学生通过加入码加入班级，已是成员时直接返回该班级。
+func (s *Service) Join(ctx context.Context, actorID int64, code string) (*Class, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.Join")
+	defer span.End()
+	account, err := s.account(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, ErrJoinCodeInvalid
+	}
+	class, err := s.repo.FindClassByJoinCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if class == nil {
+		audit.Record(ctx, "class_join_denied", actorID, "join_code_invalid", "")
+		return nil, ErrJoinCodeInvalid
+	}
+
+	existing, err := s.repo.FindMember(ctx, class.ID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		class.Role = existing.Role
+	} else {
+		if _, err := s.enroll(ctx, actorID, class.ID, account, RoleStudent, "class_joined"); err != nil {
+			return nil, err
+		}
+		class.Role = RoleStudent
+	}
+	if class.Role != RoleTeacher {
+		class.JoinCode = ""
+	}
+	return class, nil
+}
+
+// ClassExists 返回班级是否存在，供管理员创建绑定班级的邀请码时校验。
+func (s *Service) ClassExists(ctx context.Context, classID int64) (bool, error) {
+	if classID <= 0 {
+		return false, nil
+	}
+	class, err := s.repo.FindClass(ctx, classID)
+	if err != nil {
+		return false, err
+	}
+	return class != nil, nil
+}
+
+// EnrollInvited 把通过绑定班级的邀请码注册的用户加入班级，已在班级中时不做处理。
+func (s *Service) EnrollInvited(ctx context.Context, classID, userID int64, asTeacher bool) error {
+	ctx, span := tracing.Start(ctx, "classroom.Service.EnrollInvited")
+	defer span.End()
+	exists, err := s.ClassExists(ctx, classID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrClassNotFound
+	}
+	account, err := s.repo.FindAccount(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return ErrUserNotFound
+	}
+	role := RoleStudent
+	if asTeacher {
+		role = RoleTeacher
+	}
+	if _, err := s.enroll(ctx, userID, classID, account, role, "class_joined"); err != nil && !errors.Is(err, ErrAlreadyMember) {
+		return err
+	}
+	return nil
+}
+
+// RemoveMember 由班级教师移除成员，学生也可以移除自己以退出班级。
+func (s *Service) RemoveMember(ctx context.Context, actorID, classID, userID int64) error {
+	ctx, span := tracing.Start(ctx, "classroom.Service.RemoveMember")
+	defer span.End()
+	access, err := s.access(ctx, actorID, classID)
+	if err != nil {
+		return err
+	}
+	if !access.canManage() && actorID != userID {
+		audit.Record(ctx, "class_manage_denied", actorID, "permission_denied", fmt.Sprintf("class_id=%d", classID))
+		return ErrPermissionDenied
+	}
+	target, err := s.repo.FindMember(ctx, classID, userID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrNotMember
+	}
+	if target.Role == RoleTeacher {
+		members, err := s.repo.ListMembers(ctx, classID)
+		if err != nil {
+			return err
+		}
+		teachers := 0
+		for _, m := range members {
+			if m.Role == RoleTeacher {
+				teachers++
+			}
+		}
+		if teachers <= 1 {
+			return ErrLastTeacher
+		}
+	}
+	if err := s.repo.RemoveMember(ctx, classID, userID); err != nil {
+		return err
+	}
+	audit.Record(ctx, "class_member_removed", actorID, "ok", fmt.Sprintf("class_id=%d user_id=%d", classID, userID))
+	return nil
+}
+
+// Dashboard 汇总班级学生的学习进度与测验成绩，仅班级教师与管理员可见。
+func (s *Service) Dashboard(ctx context.Context, actorID, classID int64) (*Dashboard, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.Dashboard")
+	defer span.End()
+	access, err := s.manage(ctx, actorID, classID, "class_dashboard_denied")
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, classID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.repo.ListStudentProgress(ctx, classID, 0)
+	if err != nil {
+		return nil, err
+	}
+	quizzes, err := s.repo.ListStudentQuizSummaries(ctx, classID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildDashboard(*access.class, members, progress, quizzes), nil
+}
+
+// StudentReport 返回班级内单个学生的章节明细；学生只能查看自己的报告。
+func (s *Service) StudentReport(ctx context.Context, actorID, classID, studentID int64) (*StudentStats, error) {
+	ctx, span := tracing.Start(ctx, "classroom.Service.StudentReport")
+	defer span.End()
+	access, err := s.access(ctx, actorID, classID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.canManage() && actorID != studentID {
+		audit.Record(ctx, "class_dashboard_denied", actorID, "permission_denied", fmt.Sprintf("class_id=%d user_id=%d", classID, studentID))
+		return nil, ErrPermissionDenied
+	}
+	member, err := s.repo.FindMember(ctx, classID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.Role != RoleStudent {
+		return nil, ErrNotMember
+	}
+	progress, err := s.repo.ListStudentProgress(ctx, classID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	quizzes, err := s.repo.ListStudentQuizSummaries(ctx, classID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	stats := s.buildStudents([]Member{*member}, progress, quizzes)
+	return &stats[0], nil
+}
+
+func (s *Service) enroll(ctx context.Context, actorID, classID int64, target *Account, role, event string) (*Member, error) {
+	existing, err := s.repo.FindMember(ctx, classID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadyMember
+	}
+	member := Member{
+		ClassID:  classID,
+		UserID:   target.ID,
+		Username: target.Username,
+		Role:     role,
+		JoinedAt: s.now(),
+	}
+	if err := s.repo.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, event, actorID, "ok", fmt.Sprintf("class_id=%d user_id=%d role=%s", classID, target.ID, role))
+	return &member, nil
+}
+
+func (s *Service) account(ctx context.Context, actorID int64) (*Account, error) {
+	if actorID <= 0 {
+		return nil, ErrPermissionDenied
+	}
+	account, err := s.repo.FindAccount(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrPermissionDenied
+	}
+	return account, nil
+}
+
+// access 加载班级与操作者的成员关系；非成员（管理员除外）一律返回班级不存在，避免暴露班级信息。
+func (s *Service) access(ctx context.Context, actorID, classID int64) (classAccess, error) {
+	account, err := s.account(ctx, actorID)
+	if err != nil {
+		return classAccess{}, err
+	}
+	if classID <= 0 {
+		return classAccess{}, ErrClassNotFound
+	}
+	class, err := s.repo.FindClass(ctx, classID)
+	if err != nil {
+		return classAccess{}, err
+	}
+	if class == nil {
+		return classAccess{}, ErrClassNotFound
+	}
+	member, err := s.repo.FindMember(ctx, classID, actorID)
+	if err != nil {
+		return classAccess{}, err
+	}
+	if member == nil && !account.IsAdmin {
+		audit.Record(ctx, "class_access_denied", actorID, "permission_denied", fmt.Sprintf("class_id=%d", classID))
+		return classAccess{}, ErrClassNotFound
+	}
+	if member != nil {
+		class.Role = member.Role
+	}
+	return classAccess{class: class, account: account, member: member}, nil
+}
+
+// manage 要求操作者为班级教师或管理员。
+func (s *Service) manage(ctx context.Context, actorID, classID int64, deniedEvent string) (classAccess, error) {
+	access, err := s.access(ctx, actorID, classID)
+	if err != nil {
+		return classAccess{}, err
+	}
+	if !access.canManage() {
+		audit.Record(ctx, deniedEvent, actorID, "permission_denied", fmt.Sprintf("class_id=%d", classID))
+		return classAccess{}, ErrPermissionDenied
+	}
+	return access, nil
+}
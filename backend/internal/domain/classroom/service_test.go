@@ -0,0 +1,423 @@
+package classroom
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/progress"
+)
+
+type mockRepo struct {
+	classes  map[int64]*Class
+	members  map[int64]map[int64]Member
+	accounts map[int64]*Account
+	progress []ChapterProgress
+	quizzes  []QuizSummary
+	autoID   int64
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{
+		classes:  make(map[int64]*Class),
+		members:  make(map[int64]map[int64]Member),
+		accounts: make(map[int64]*Account),
+		autoID:   1,
+	}
+}
+
+func (m *mockRepo) addAccount(id int64, username string, admin, teacher bool) {
+	m.accounts[id] = &Account{ID: id, Username: username, IsAdmin: admin, IsTeacher: teacher}
+}
+
+func (m *mockRepo) CreateClass(_ context.Context, class *Class) (int64, error) {
+	id := m.autoID
+	m.autoID++
+	clone := *class
+	clone.ID = id
+	m.classes[id] = &clone
+	m.members[id] = map[int64]Member{class.CreatedBy: {ClassID: id, UserID: class.CreatedBy, Username: m.accounts[class.CreatedBy].Username, Role: RoleTeacher}}
+	return id, nil
+}
+
+func (m *mockRepo) FindClass(_ context.Context, id int64) (*Class, error) {
+	if c, ok := m.classes[id]; ok {
+		clone := *c
+		clone.Role = ""
+		return &clone, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) FindClassByJoinCode(_ context.Context, code string) (*Class, error) {
+	for _, c := range m.classes {
+		if c.JoinCode == code {
+			clone := *c
+			clone.Role = ""
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) ListClasses(_ context.Context) ([]Class, error) {
+	var list []Class
+	for _, c := range m.classes {
+		clone := *c
+		clone.Role = ""
+		list = append(list, clone)
+	}
+	return list, nil
+}
+
+func (m *mockRepo) ListClassesForUser(_ context.Context, userID int64) ([]Class, error) {
+	var list []Class
+	for id, members := range m.members {
+		if member, ok := members[userID]; ok {
+			clone := *m.classes[id]
+			clone.Role = member.Role
+			list = append(list, clone)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockRepo) UpdateJoinCode(_ context.Context, classID int64, code string) error {
+	m.classes[classID].JoinCode = code
+	return nil
+}
+
+func (m *mockRepo) UpdateLeaderboardEnabled(_ context.Context, classID int64, enabled bool) error {
+	m.classes[classID].LeaderboardEnabled = enabled
+	return nil
+}
+
+func (m *mockRepo) DeleteClass(_ context.Context, id int64) error {
+	delete(m.classes, id)
+	delete(m.members, id)
+	return nil
+}
+
+func (m *mockRepo) AddMember(_ context.Context, member Member) error {
+	m.members[member.ClassID][member.UserID] = member
+	return nil
+}
+
+func (m *mockRepo) RemoveMember(_ context.Context, classID, userID int64) error {
+	delete(m.members[classID], userID)
+	return nil
+}
+
+func (m *mockRepo) FindMember(_ context.Context, classID, userID int64) (*Member, error) {
+	if member, ok := m.members[classID][userID]; ok {
+		return &member, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) ListMembers(_ context.Context, classID int64) ([]Member, error) {
+	var list []Member
+	for _, member := range m.members[classID] {
+		list = append(list, member)
+	}
+	return list, nil
+}
+
+func (m *mockRepo) FindAccount(_ context.Context, id int64) (*Account, error) {
+	if a, ok := m.accounts[id]; ok {
+		clone := *a
+		return &clone, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) FindAccountByUsername(_ context.Context, username string) (*Account, error) {
+	for _, a := range m.accounts {
+		if a.Username == username {
+			clone := *a
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) isStudent(classID, userID int64) bool {
+	member, ok := m.members[classID][userID]
+	return ok && member.Role == RoleStudent
+}
+
+func (m *mockRepo) ListStudentProgress(_ context.Context, classID, studentID int64) ([]ChapterProgress, error) {
+	var rows []ChapterProgress
+	for _, row := range m.progress {
+		if m.isStudent(classID, row.UserID) && (studentID == 0 || row.UserID == studentID) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockRepo) ListStudentQuizSummaries(_ context.Context, classID, studentID int64) ([]QuizSummary, error) {
+	var rows []QuizSummary
+	for _, row := range m.quizzes {
+		if m.isStudent(classID, row.UserID) && (studentID == 0 || row.UserID == studentID) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+const (
+	adminID    int64 = 1
+	teacherID  int64 = 2
+	otherTchID int64 = 3
+	aliceID    int64 = 4
+	bobID      int64 = 5
+	outsiderID int64 = 6
+)
+
+func setupService(t *testing.T) (*Service, *mockRepo, *Class) {
+	t.Helper()
+	repo := newMockRepo()
+	repo.addAccount(adminID, "admin", true, false)
+	repo.addAccount(teacherID, "teacher", false, true)
+	repo.addAccount(otherTchID, "teacher2", false, true)
+	repo.addAccount(aliceID, "alice", false, false)
+	repo.addAccount(bobID, "bob", false, false)
+	repo.addAccount(outsiderID, "outsider", false, false)
+
+	svc := NewService(repo)
+	class, err := svc.CreateClass(context.Background(), teacherID, " 周三学习小组 ", "")
+	if err != nil {
+		t.Fatalf("创建班级失败: %v", err)
+	}
+	return svc, repo, class
+}
+
+func TestService_CreateClassRequiresTeacher(t *testing.T) {
+	svc, _, class := setupService(t)
+	ctx := context.Background()
+
+	if class.Name != "周三学习小组" || len(class.JoinCode) != joinCodeLength || class.Role != RoleTeacher {
+		t.Fatalf("班级信息不正确: %+v", class)
+	}
+	if _, err := svc.CreateClass(ctx, aliceID, "学生建班", ""); err != ErrPermissionDenied {
+		t.Fatalf("学生不应能创建班级，得到: %v", err)
+	}
+	if _, err := svc.CreateClass(ctx, adminID, "管理员建班", ""); err != nil {
+		t.Fatalf("管理员应能创建班级: %v", err)
+	}
+	if _, err := svc.CreateClass(ctx, teacherID, "  ", ""); err != ErrInvalidInput {
+		t.Fatalf("空名称应被拒绝，得到: %v", err)
+	}
+}
+
+func TestService_EnrollmentAndVisibility(t *testing.T) {
+	svc, _, class := setupService(t)
+	ctx := context.Background()
+
+	joined, err := svc.Join(ctx, aliceID, " "+class.JoinCode+" ")
+	if err != nil || joined.ID != class.ID || joined.Role != RoleStudent || joined.JoinCode != "" {
+		t.Fatalf("学生加入班级失败: %+v, %v", joined, err)
+	}
+	if again, err := svc.Join(ctx, aliceID, class.JoinCode); err != nil || again.ID != class.ID {
+		t.Fatalf("重复加入应幂等: %+v, %v", again, err)
+	}
+	if _, err := svc.Join(ctx, bobID, "WRONG123"); err != ErrJoinCodeInvalid {
+		t.Fatalf("错误加入码应被拒绝，得到: %v", err)
+	}
+	if _, err := svc.AddMember(ctx, teacherID, class.ID, "bob", ""); err != nil {
+		t.Fatalf("教师按用户名添加学生失败: %v", err)
+	}
+	if _, err := svc.AddMember(ctx, teacherID, class.ID, "bob", ""); err != ErrAlreadyMember {
+		t.Fatalf("重复添加应返回 ErrAlreadyMember，得到: %v", err)
+	}
+	if _, err := svc.AddMember(ctx, teacherID, class.ID, "ghost", ""); err != ErrUserNotFound {
+		t.Fatalf("不存在的用户应返回 ErrUserNotFound，得到: %v", err)
+	}
+	if _, err := svc.AddMember(ctx, teacherID, class.ID, "outsider", RoleTeacher); err != ErrInvalidInput {
+		t.Fatalf("非教师不能被设为班级教师，得到: %v", err)
+	}
+	if _, err := svc.AddMember(ctx, aliceID, class.ID, "outsider", ""); err != ErrPermissionDenied {
+		t.Fatalf("学生不能添加成员，得到: %v", err)
+	}
+
+	detail, err := svc.GetClass(ctx, aliceID, class.ID)
+	if err != nil {
+		t.Fatalf("学生查看班级失败: %v", err)
+	}
+	for _, m := range detail.Members {
+		if m.UserID == bobID {
+			t.Fatalf("学生不应看到其他同学: %+v", detail.Members)
+		}
+	}
+	if detail.Class.JoinCode != "" {
+		t.Fatalf("学生不应看到加入码")
+	}
+	if teacherView, _ := svc.GetClass(ctx, teacherID, class.ID); len(teacherView.Members) != 3 || teacherView.Class.JoinCode == "" {
+		t.Fatalf("教师应看到全部成员与加入码: %+v", teacherView)
+	}
+
+	if _, err := svc.GetClass(ctx, outsiderID, class.ID); err != ErrClassNotFound {
+		t.Fatalf("非成员应得到班级不存在，得到: %v", err)
+	}
+	if list, _ := svc.ListClasses(ctx, outsiderID, true); len(list) != 0 {
+		t.Fatalf("非管理员传 all 也只能看到自己的班级: %+v", list)
+	}
+	if list, _ := svc.ListClasses(ctx, aliceID, false); len(list) != 1 || list[0].JoinCode != "" {
+		t.Fatalf("学生班级列表不正确: %+v", list)
+	}
+}
+
+func TestService_EnrollInvited(t *testing.T) {
+	svc, repo, class := setupService(t)
+	ctx := context.Background()
+
+	if exists, err := svc.ClassExists(ctx, class.ID); err != nil || !exists {
+		t.Fatalf("班级应存在: %v, %v", exists, err)
+	}
+	if exists, _ := svc.ClassExists(ctx, class.ID+100); exists {
+		t.Fatalf("不存在的班级应返回 false")
+	}
+	if err := svc.EnrollInvited(ctx, class.ID, aliceID, false); err != nil {
+		t.Fatalf("邀请码注册的学生加入班级失败: %v", err)
+	}
+	if err := svc.EnrollInvited(ctx, class.ID, aliceID, false); err != nil {
+		t.Fatalf("重复加入应幂等，得到: %v", err)
+	}
+	if err := svc.EnrollInvited(ctx, class.ID, otherTchID, true); err != nil {
+		t.Fatalf("教师邀请码注册的用户加入班级失败: %v", err)
+	}
+	if member, _ := repo.FindMember(ctx, class.ID, otherTchID); member == nil || member.Role != RoleTeacher {
+		t.Fatalf("教师邀请码应以教师身份加入: %+v", member)
+	}
+	if member, _ := repo.FindMember(ctx, class.ID, aliceID); member == nil || member.Role != RoleStudent {
+		t.Fatalf("普通邀请码应以学生身份加入: %+v", member)
+	}
+	if err := svc.EnrollInvited(ctx, class.ID+100, bobID, false); err != ErrClassNotFound {
+		t.Fatalf("班级不存在时应返回 ErrClassNotFound，得到: %v", err)
+	}
+}
+
+func TestService_RemoveMember(t *testing.T) {
+	svc, _, class := setupService(t)
+	ctx := context.Background()
+	_, _ = svc.Join(ctx, aliceID, class.JoinCode)
+	_, _ = svc.Join(ctx, bobID, class.JoinCode)
+
+	if err := svc.RemoveMember(ctx, aliceID, class.ID, bobID); err != ErrPermissionDenied {
+		t.Fatalf("学生不能移除他人，得到: %v", err)
+	}
+	if err := svc.RemoveMember(ctx, aliceID, class.ID, aliceID); err != nil {
+		t.Fatalf("学生应能退出班级: %v", err)
+	}
+	if err := svc.RemoveMember(ctx, teacherID, class.ID, teacherID); err != ErrLastTeacher {
+		t.Fatalf("不能移除最后一名教师，得到: %v", err)
+	}
+	if _, err := svc.AddMember(ctx, teacherID, class.ID, "teacher2", RoleTeacher); err != nil {
+		t.Fatalf("添加协作教师失败: %v", err)
+	}
+	if err := svc.RemoveMember(ctx, teacherID, class.ID, teacherID); err != nil {
+		t.Fatalf("存在其他教师时应能移除: %v", err)
+	}
+	if err := svc.RemoveMember(ctx, otherTchID, class.ID, aliceID); err != ErrNotMember {
+		t.Fatalf("移除非成员应返回 ErrNotMember，得到: %v", err)
+	}
+}
+
+func TestService_DashboardAccessControl(t *testing.T) {
+	svc, _, class := setupService(t)
+	ctx := context.Background()
+	_, _ = svc.Join(ctx, aliceID, class.JoinCode)
+
+	if _, err := svc.Dashboard(ctx, aliceID, class.ID); err != ErrPermissionDenied {
+		t.Fatalf("学生不能查看看板，得到: %v", err)
+	}
+	if _, err := svc.Dashboard(ctx, otherTchID, class.ID); err != ErrClassNotFound {
+		t.Fatalf("其他班级教师不能查看看板，得到: %v", err)
+	}
+	if _, err := svc.Dashboard(ctx, adminID, class.ID); err != nil {
+		t.Fatalf("管理员应能查看看板: %v", err)
+	}
+	if _, err := svc.StudentReport(ctx, teacherID, class.ID, outsiderID); err != ErrNotMember {
+		t.Fatalf("教师不能查看班级外学生，得到: %v", err)
+	}
+	if _, err := svc.StudentReport(ctx, aliceID, class.ID, aliceID); err != nil {
+		t.Fatalf("学生应能查看自己的报告: %v", err)
+	}
+	if _, err := svc.StudentReport(ctx, teacherID, class.ID, teacherID); err != ErrNotMember {
+		t.Fatalf("教师不是学生成员，得到: %v", err)
+	}
+}
+
+func TestService_DashboardAggregatesAndFlagsStuckStudents(t *testing.T) {
+	svc, repo, class := setupService(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	svc.WithClock(func() time.Time { return now })
+	_, _ = svc.Join(ctx, aliceID, class.JoinCode)
+	_, _ = svc.Join(ctx, bobID, class.JoinCode)
+	repo.addAccount(7, "carol", false, false)
+	_, _ = svc.Join(ctx, 7, class.JoinCode)
+
+	repo.progress = []ChapterProgress{
+		{UserID: aliceID, Topic: "variables", Chapter: "storage", Status: progress.StatusDone, LastVisit: now.Add(-time.Hour)},
+		{UserID: aliceID, Topic: "constants", Chapter: "iota", Status: progress.StatusInProgress, LastVisit: now.Add(-2 * time.Hour)},
+		{UserID: bobID, Topic: "variables", Chapter: "storage", Status: progress.StatusInProgress, LastVisit: now.Add(-4 * 24 * time.Hour)},
+		// 班级外用户的数据不应出现在看板中
+		{UserID: outsiderID, Topic: "variables", Chapter: "storage", Status: progress.StatusDone, LastVisit: now},
+	}
+	repo.quizzes = []QuizSummary{
+		{UserID: aliceID, Topic: "variables", Chapter: "storage", Attempts: 1, AvgPercent: 100, BestPercent: 100, LastAt: now.Add(-time.Hour)},
+		{UserID: bobID, Topic: "variables", Chapter: "storage", Attempts: 3, AvgPercent: 40, BestPercent: 50, LastAt: now.Add(-24 * time.Hour)},
+	}
+
+	dashboard, err := svc.Dashboard(ctx, teacherID, class.ID)
+	if err != nil {
+		t.Fatalf("生成看板失败: %v", err)
+	}
+	if dashboard.Summary.Students != 3 || dashboard.Summary.StuckStudents != 2 || dashboard.Summary.ActiveStudents != 2 {
+		t.Fatalf("班级汇总不正确: %+v", dashboard.Summary)
+	}
+	if dashboard.Summary.QuizAttempts != 4 || *dashboard.Summary.AvgScore != 55 {
+		t.Fatalf("平均分应按测验次数加权: %+v", dashboard.Summary)
+	}
+
+	byName := make(map[string]StudentStats)
+	for _, st := range dashboard.Students {
+		byName[st.Username] = st
+		if st.Chapters != nil {
+			t.Fatalf("看板不应包含章节明细")
+		}
+	}
+	if alice := byName["alice"]; alice.Stuck || alice.ChaptersDone != 1 || alice.ChaptersInProgress != 1 || len(alice.Topics) != 2 {
+		t.Fatalf("alice 统计不正确: %+v", alice)
+	}
+	bob := byName["bob"]
+	if !bob.Stuck || len(bob.StuckReasons) != 2 || bob.StuckReasons[0] != StuckStalledChapter || bob.StuckReasons[1] != StuckLowQuizScore {
+		t.Fatalf("bob 应因章节停滞和低分被标记: %+v", bob)
+	}
+	if !bob.LastActivity.Equal(now.Add(-24 * time.Hour)) {
+		t.Fatalf("最近活动时间应取进度与测验的最大值: %v", bob.LastActivity)
+	}
+	if carol := byName["carol"]; !carol.Stuck || carol.StuckReasons[0] != StuckInactive || carol.LastActivity != nil {
+		t.Fatalf("无任何记录的学生应视为不活跃: %+v", carol)
+	}
+	if dashboard.Students[len(dashboard.Students)-1].Username != "alice" {
+		t.Fatalf("卡住的学生应排在前面: %+v", dashboard.Students)
+	}
+
+	var storage *ChapterStats
+	for i := range dashboard.Chapters {
+		if dashboard.Chapters[i].Chapter == "storage" {
+			storage = &dashboard.Chapters[i]
+		}
+	}
+	if storage == nil || storage.Done != 1 || storage.InProgress != 1 || storage.StuckStudents != 1 || *storage.AvgScore != 55 {
+		t.Fatalf("章节统计不正确: %+v", storage)
+	}
+
+	report, err := svc.StudentReport(ctx, teacherID, class.ID, bobID)
+	if err != nil || len(report.Chapters) != 1 || !report.Chapters[0].Stalled || !report.Chapters[0].LowScore {
+		t.Fatalf("学生报告应包含章节明细: %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,91 @@
+package discussion
+
+import "time"
+
+// Thread 表示挂在章节或某道测验题下的讨论主题，首帖为提问内容。
+type Thread struct {
+	ID      int64  `json:"id"`
+	Topic   string `json:"topic"`
+	Chapter string `json:"chapter"`
+	// QuestionID 为关联的测验题目 ID，为空表示章节讨论。
+	QuestionID string `json:"questionId,omitempty"`
+	Title      string `json:"title"`
+	AuthorID   int64  `json:"authorId"`
+	Author     string `json:"author"`
+	RootPostID int64  `json:"rootPostId"`
+	// AcceptedPostID 为教师采纳的回答，0 表示尚未采纳。
+	AcceptedPostID int64 `json:"acceptedPostId,omitempty"`
+	// Hidden 在首帖被管理员隐藏时为 true，普通用户看不到该主题。
+	Hidden     bool      `json:"hidden"`
+	ReplyCount int       `json:"replyCount"`
+	Unread     int       `json:"unread"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastPostAt time.Time `json:"lastPostAt"`
+}
+
+// Post 表示主题中的一条帖子，ParentID 指向被回复的帖子以形成楼中楼。
+type Post struct {
+	ID       int64  `json:"id"`
+	ThreadID int64  `json:"threadId"`
+	ParentID int64  `json:"parentId,omitempty"`
+	AuthorID int64  `json:"authorId"`
+	Author   string `json:"author"`
+	// Body 为服务端清理后的 Markdown，隐藏的帖子对普通用户返回空字符串。
+	Body      string    `json:"body"`
+	Hidden    bool      `json:"hidden"`
+	Accepted  bool      `json:"accepted"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ThreadDetail 为主题及其全部帖子，帖子按发布时间排序。
+type ThreadDetail struct {
+	Thread
+	Posts []Post `json:"posts"`
+}
+
+// Filter 为主题列表的过滤与分页条件，空字段表示不过滤。
+type Filter struct {
+	Topic      string
+	Chapter    string
+	QuestionID string
+	Page       int
+	PageSize   int
+	// IncludeHidden 为 true 时包含被隐藏的主题，仅管理员可用。
+	IncludeHidden bool
+}
+
+// ChapterUnread 为某章节下当前用户未读的帖子数。
+type ChapterUnread struct {
+	Topic   string `json:"topic"`
+	Chapter string `json:"chapter"`
+	Count   int    `json:"count"`
+}
+
+// ThreadPage 为主题列表的一页结果，Unread 为过滤范围内各章节的未读数。
+type ThreadPage struct {
+	Items    []Thread        `json:"items"`
+	Total    int             `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"pageSize"`
+	Unread   []ChapterUnread `json:"unread"`
+}
+
+// Mention 表示帖子中对用户的 @ 提及。
+type Mention struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"postId"`
+	ThreadID  int64     `json:"threadId"`
+	Topic     string    `json:"topic"`
+	Chapter   string    `json:"chapter"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Actor 为操作者的角色信息。
+type Actor struct {
+	ID        int64
+	IsAdmin   bool
+	IsTeacher bool
+}
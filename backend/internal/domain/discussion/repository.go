@@ -0,0 +1,40 @@
+package discussion
+
+import "context"
+
+// Repository 定义讨论区的持久化操作。
+type Repository interface {
+	// CreateThread 在同一事务中保存主题、首帖与提及，返回主题与首帖 ID。
+	CreateThread(ctx context.Context, thread *Thread, root *Post, mentions []int64) (int64, int64, error)
+	// CreatePost 保存回复与提及，并更新主题的最后回复时间。
+	CreatePost(ctx context.Context, post *Post, mentions []int64) (int64, error)
+	// FindThread 返回主题，不存在时返回 nil。
+	FindThread(ctx context.Context, id int64) (*Thread, error)
+	// FindPost 返回帖子，不存在时返回 nil。
+	FindPost(ctx context.Context, id int64) (*Post, error)
+	// ListThreads 按最后回复时间倒序分页返回主题及总数，Unread 按 viewerID 计算。
+	ListThreads(ctx context.Context, viewerID int64, filter Filter) ([]Thread, int, error)
+	ListPosts(ctx context.Context, threadID int64) ([]Post, error)
+	// SetAccepted 设置主题的采纳回答，postID 为 0 表示取消。
+	SetAccepted(ctx context.Context, threadID, postID int64) error
+	// SetPostHidden 隐藏或恢复帖子，首帖的状态同步到主题。
+	SetPostHidden(ctx context.Context, postID int64, hidden bool, operatorID int64) error
+
+	// MarkRead 记录用户已读到主题中的 lastPostID。
+	MarkRead(ctx context.Context, userID, threadID, lastPostID int64) error
+	// UnreadByChapter 统计他人发布、未隐藏且未读的帖子数，topic 为空时统计全部主题。
+	UnreadByChapter(ctx context.Context, userID int64, topic string) ([]ChapterUnread, error)
+
+	// FindUserIDs 按用户名（不区分大小写）查找启用的用户。
+	FindUserIDs(ctx context.Context, usernames []string) ([]int64, error)
+	// FindActor 返回用户角色，用户不存在时返回 nil。
+	FindActor(ctx context.Context, userID int64) (*Actor, error)
+	ListMentions(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]Mention, error)
+	// MarkMentionsRead 将用户的提及标记为已读，ids 为空表示全部。
+	MarkMentionsRead(ctx context.Context, userID int64, ids []int64) error
+}
+
+// QuestionCatalog 用于校验讨论关联的测验题目是否存在。
+type QuestionCatalog interface {
+	HasQuestion(ctx context.Context, topic, chapter, questionID string) (bool, error)
+}
@@ -0,0 +1,480 @@
+package discussion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-study2/internal/domain/progress"
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/infrastructure/tracing"
+	"go-study2/internal/pkg/markdown"
+)
+
+var (
+	// ErrInvalidInput 表示讨论参数不合法。
+	ErrInvalidInput = errors.New("讨论参数不合法")
+	// ErrThreadNotFound 表示主题不存在或已被隐藏。
+	ErrThreadNotFound = errors.New("讨论主题不存在")
+	// ErrPostNotFound 表示帖子不存在或不属于该主题。
+	ErrPostNotFound = errors.New("帖子不存在")
+	// ErrPermissionDenied 表示当前用户无权执行该操作。
+	ErrPermissionDenied = errors.New("无权操作讨论")
+)
+
+const (
+	maxTitleRunes   = 200
+	maxBodyRunes    = 10000
+	maxMentions     = 10
+	defaultPageSize = 20
+	maxPageSize     = 100
+	maxMentionList  = 100
+)
+
+var (
+	chapterPattern    = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	questionIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+	mentionPattern    = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@/])@([A-Za-z0-9_]{3,50})`)
+	codeFencePattern  = regexp.MustCompile("(?s)```.*?```|~~~.*?~~~|`[^`\n]*`")
+)
+
+// Service 管理章节与测验题的讨论、采纳、隐藏与 @ 提及。
+type Service struct {
+	repo      Repository
+	questions QuestionCatalog
+	// events 为 nil 时不发布回复与提及事件。
+	events eventbus.Publisher
+	now    func() time.Time
+}
+
+// NewService 创建讨论服务。
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// WithQuestions 启用题目 ID 校验，未设置时只校验格式。
+func (s *Service) WithQuestions(catalog QuestionCatalog) *Service {
+	s.questions = catalog
+	return s
+}
+
+// WithEvents 设置领域事件发布者，用于通知回复与 @ 提及。
+func (s *Service) WithEvents(pub eventbus.Publisher) *Service {
+	s.events = pub
+	return s
+}
+
+// List 分页返回主题，并附带过滤范围内各章节的未读数；仅管理员能看到被隐藏的主题。
+func (s *Service) List(ctx context.Context, actorID int64, filter Filter) (*ThreadPage, error) {
+	ctx, span := tracing.Start(ctx, "discussion.Service.List")
+	defer span.End()
+	actor, err := s.actor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	filter.Topic = strings.TrimSpace(filter.Topic)
+	filter.Chapter = strings.TrimSpace(filter.Chapter)
+	filter.QuestionID = strings.TrimSpace(filter.QuestionID)
+	if filter.Topic != "" && !progress.IsSupportedTopic(filter.Topic) {
+		return nil, ErrInvalidInput
+	}
+	if (filter.Chapter != "" || filter.QuestionID != "") && filter.Topic == "" {
+		return nil, ErrInvalidInput
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = defaultPageSize
+	}
+	if filter.PageSize > maxPageSize {
+		filter.PageSize = maxPageSize
+	}
+	filter.IncludeHidden = filter.IncludeHidden && actor.IsAdmin
+
+	items, total, err := s.repo.ListThreads(ctx, actorID, filter)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.repo.UnreadByChapter(ctx, actorID, filter.Topic)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Chapter != "" {
+		unread = filterChapter(unread, filter.Chapter)
+	}
+	return &ThreadPage{Items: items, Total: total, Page: filter.Page, PageSize: filter.PageSize, Unread: unread}, nil
+}
+
+// CreateThread 在章节或题目下发起讨论，正文经过清理后保存。
+func (s *Service) CreateThread(ctx context.Context, actorID int64, topic, chapter, questionID, title, body string) (*ThreadDetail, error) {
+	ctx, span := tracing.Start(ctx, "discussion.Service.CreateThread")
+	defer span.End()
+	if _, err := s.actor(ctx, actorID); err != nil {
+		return nil, err
+	}
+	topic, chapter, questionID = strings.TrimSpace(topic), strings.TrimSpace(chapter), strings.TrimSpace(questionID)
+	title = strings.TrimSpace(title)
+	if !progress.IsSupportedTopic(topic) || !chapterPattern.MatchString(chapter) {
+		return nil, ErrInvalidInput
+	}
+	if title == "" || utf8.RuneCountInString(title) > maxTitleRunes {
+		return nil, ErrInvalidInput
+	}
+	if questionID != "" {
+		if err := s.checkQuestion(ctx, topic, chapter, questionID); err != nil {
+			return nil, err
+		}
+	}
+	body, err := cleanBody(body)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := s.resolveMentions(ctx, actorID, body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC().Truncate(time.Second)
+	thread := &Thread{
+		Topic:      topic,
+		Chapter:    chapter,
+		QuestionID: questionID,
+		Title:      title,
+		AuthorID:   actorID,
+		CreatedAt:  now,
+		LastPostAt: now,
+	}
+	root := &Post{AuthorID: actorID, Body: body, CreatedAt: now}
+	threadID, _, err := s.repo.CreateThread(ctx, thread, root, mentions)
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "discussion_thread_created", actorID, "ok", fmt.Sprintf("thread_id=%d mentions=%d", threadID, len(mentions)))
+	thread.ID = threadID
+	s.announce(ctx, actorID, thread, nil, mentions)
+	return s.Thread(ctx, actorID, threadID)
+}
+
+// Reply 在主题中发布回复，parentID 不为 0 时回复同一主题中的某条帖子。
+func (s *Service) Reply(ctx context.Context, actorID, threadID, parentID int64, body string) (*Post, error) {
+	ctx, span := tracing.Start(ctx, "discussion.Service.Reply")
+	defer span.End()
+	actor, err := s.actor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	thread, err := s.visibleThread(ctx, actor, threadID)
+	if err != nil {
+		return nil, err
+	}
+	replyTo := []int64{thread.AuthorID}
+	if parentID != 0 {
+		parent, err := s.repo.FindPost(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil || parent.ThreadID != threadID {
+			return nil, ErrPostNotFound
+		}
+		replyTo = append(replyTo, parent.AuthorID)
+	}
+	body, err = cleanBody(body)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := s.resolveMentions(ctx, actorID, body)
+	if err != nil {
+		return nil, err
+	}
+
+	post := &Post{
+		ThreadID:  threadID,
+		ParentID:  parentID,
+		AuthorID:  actorID,
+		Body:      body,
+		CreatedAt: s.now().UTC().Truncate(time.Second),
+	}
+	id, err := s.repo.CreatePost(ctx, post, mentions)
+	if err != nil {
+		return nil, err
+	}
+	post.ID = id
+	audit.Record(ctx, "discussion_post_created", actorID, "ok", fmt.Sprintf("thread_id=%d post_id=%d mentions=%d", threadID, id, len(mentions)))
+	s.announce(ctx, actorID, thread, replyTo, mentions)
+	return post, nil
+}
+
+// Thread 返回主题与全部帖子，并将其标记为已读；隐藏帖子的正文只对管理员可见。
+func (s *Service) Thread(ctx context.Context, actorID, threadID int64) (*ThreadDetail, error) {
+	ctx, span := tracing.Start(ctx, "discussion.Service.Thread")
+	defer span.End()
+	actor, err := s.actor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	thread, err := s.visibleThread(ctx, actor, threadID)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.repo.ListPosts(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	var lastID int64
+	for i := range posts {
+		if posts[i].ID > lastID {
+			lastID = posts[i].ID
+		}
+		posts[i].Accepted = posts[i].ID == thread.AcceptedPostID
+		if posts[i].Hidden && !actor.IsAdmin {
+			posts[i].Body = ""
+		}
+	}
+	if lastID > 0 {
+		if err := s.repo.MarkRead(ctx, actorID, threadID, lastID); err != nil {
+			return nil, err
+		}
+	}
+	thread.Unread = 0
+	return &ThreadDetail{Thread: *thread, Posts: posts}, nil
+}
+
+// Accept 由教师或管理员采纳主题中的一条回答，postID 为 0 表示取消采纳。
+func (s *Service) Accept(ctx context.Context, actorID, threadID, postID int64) (*Thread, error) {
+	ctx, span := tracing.Start(ctx, "discussion.Service.Accept")
+	defer span.End()
+	actor, err := s.actor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	thread, err := s.visibleThread(ctx, actor, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsTeacher && !actor.IsAdmin {
+		audit.Record(ctx, "discussion_accept_denied", actorID, "permission_denied", fmt.Sprintf("thread_id=%d", threadID))
+		return nil, ErrPermissionDenied
+	}
+	if postID != 0 {
+		post, err := s.repo.FindPost(ctx, postID)
+		if err != nil {
+			return nil, err
+		}
+		if post == nil || post.ThreadID != threadID || post.Hidden {
+			return nil, ErrPostNotFound
+		}
+		if post.ID == thread.RootPostID {
+			return nil, ErrInvalidInput
+		}
+	}
+	if err := s.repo.SetAccepted(ctx, threadID, postID); err != nil {
+		return nil, err
+	}
+	thread.AcceptedPostID = postID
+	audit.Record(ctx, "discussion_answer_accepted", actorID, "ok", fmt.Sprintf("thread_id=%d post_id=%d", threadID, postID))
+	return thread, nil
+}
+
+// SetHidden 由管理员隐藏或恢复帖子，隐藏首帖会同时隐藏整个主题，被采纳的回答隐藏后取消采纳。
+func (s *Service) SetHidden(ctx context.Context, actorID, postID int64, hidden bool) (*Post, error) {
+	ctx, span := tracing.Start(ctx, "discussion.Service.SetHidden")
+	defer span.End()
+	actor, err := s.actor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin {
+		audit.Record(ctx, "discussion_hide_denied", actorID, "permission_denied", fmt.Sprintf("post_id=%d", postID))
+		return nil, ErrPermissionDenied
+	}
+	post, err := s.repo.FindPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+	if err := s.repo.SetPostHidden(ctx, postID, hidden, actorID); err != nil {
+		return nil, err
+	}
+	if hidden {
+		thread, err := s.repo.FindThread(ctx, post.ThreadID)
+		if err != nil {
+			return nil, err
+		}
+		if thread != nil && thread.AcceptedPostID == postID {
+			if err := s.repo.SetAccepted(ctx, thread.ID, 0); err != nil {
+				return nil, err
+			}
+		}
+	}
+	post.Hidden = hidden
+	audit.Record(ctx, "discussion_post_hidden", actorID, "ok", fmt.Sprintf("post_id=%d hidden=%t", postID, hidden))
+	return post, nil
+}
+
+// Mentions 返回用户收到的 @ 提及，最新的在前。
+func (s *Service) Mentions(ctx context.Context, userID int64, unreadOnly bool) ([]Mention, error) {
+	ctx, span := tracing.Start(ctx, "discussion.Service.Mentions")
+	defer span.End()
+	if userID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.repo.ListMentions(ctx, userID, unreadOnly, maxMentionList)
+}
+
+// MarkMentionsRead 将指定提及标记为已读，ids 为空表示全部。
+func (s *Service) MarkMentionsRead(ctx context.Context, userID int64, ids []int64) error {
+	ctx, span := tracing.Start(ctx, "discussion.Service.MarkMentionsRead")
+	defer span.End()
+	if userID <= 0 {
+		return ErrInvalidInput
+	}
+	return s.repo.MarkMentionsRead(ctx, userID, ids)
+}
+
+func (s *Service) actor(ctx context.Context, actorID int64) (*Actor, error) {
+	if actorID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	actor, err := s.repo.FindActor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == nil {
+		return nil, ErrPermissionDenied
+	}
+	return actor, nil
+}
+
+func (s *Service) visibleThread(ctx context.Context, actor *Actor, threadID int64) (*Thread, error) {
+	if threadID <= 0 {
+		return nil, ErrThreadNotFound
+	}
+	thread, err := s.repo.FindThread(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil || (thread.Hidden && !actor.IsAdmin) {
+		return nil, ErrThreadNotFound
+	}
+	return thread, nil
+}
+
+func (s *Service) checkQuestion(ctx context.Context, topic, chapter, questionID string) error {
+	if !questionIDPattern.MatchString(questionID) {
+		return ErrInvalidInput
+	}
+	if s.questions == nil {
+		return nil
+	}
+	ok, err := s.questions.HasQuestion(ctx, topic, chapter, questionID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// resolveMentions 解析正文中代码之外的 @用户名，忽略不存在的用户与作者本人。
+func (s *Service) resolveMentions(ctx context.Context, authorID int64, body string) ([]int64, error) {
+	names := ParseMentions(body)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	ids, err := s.repo.FindUserIDs(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id != authorID {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// announce 发布提及与回复事件；同时被提及的回复对象只收到提及通知。
+func (s *Service) announce(ctx context.Context, actorID int64, thread *Thread, replyTo, mentions []int64) {
+	if s.events == nil {
+		return
+	}
+	link := fmt.Sprintf("/discussions/%d", thread.ID)
+	data := map[string]interface{}{"threadId": thread.ID, "topic": thread.Topic, "chapter": thread.Chapter}
+	if len(mentions) > 0 {
+		s.events.Publish(ctx, eventbus.Event{
+			Type:    eventbus.TypeMention,
+			UserIDs: mentions,
+			ActorID: actorID,
+			Title:   "有人在讨论中提到了你：" + thread.Title,
+			Link:    link,
+			Data:    data,
+		})
+	}
+	mentioned := make(map[int64]struct{}, len(mentions))
+	for _, id := range mentions {
+		mentioned[id] = struct{}{}
+	}
+	var recipients []int64
+	for _, id := range replyTo {
+		if _, ok := mentioned[id]; !ok {
+			recipients = append(recipients, id)
+		}
+	}
+	if len(recipients) > 0 {
+		s.events.Publish(ctx, eventbus.Event{
+			Type:    eventbus.TypeDiscussionReply,
+			UserIDs: recipients,
+			ActorID: actorID,
+			Title:   "你参与的讨论有新回复：" + thread.Title,
+			Link:    link,
+			Data:    data,
+		})
+	}
+}
+
+// ParseMentions 返回正文中被 @ 的用户名（小写、去重，最多 10 个），代码中的 @ 不计入。
+func ParseMentions(body string) []string {
+	body = codeFencePattern.ReplaceAllString(body, " ")
+	seen := make(map[string]struct{})
+	var names []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(m[1])
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+		if len(names) == maxMentions {
+			break
+		}
+	}
+	return names
+}
+
+func cleanBody(body string) (string, error) {
+	body = strings.TrimSpace(markdown.Sanitize(body))
+	if body == "" || utf8.RuneCountInString(body) > maxBodyRunes {
+		return "", ErrInvalidInput
+	}
+	return body, nil
+}
+
+func filterChapter(list []ChapterUnread, chapter string) []ChapterUnread {
+	out := make([]ChapterUnread, 0, 1)
+	for _, item := range list {
+		if item.Chapter == chapter {
+			out = append(out, item)
+		}
+	}
+	return out
+}
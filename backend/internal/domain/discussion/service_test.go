@@ -0,0 +1,317 @@
+package discussion
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-study2/internal/infrastructure/eventbus"
+)
+
+type mockRepo struct {
+	threads  map[int64]*Thread
+	posts    map[int64]*Post
+	actors   map[int64]*Actor
+	users    map[string]int64
+	mentions map[int64][]int64
+	reads    map[[2]int64]int64
+	nextID   int64
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{
+		threads:  make(map[int64]*Thread),
+		posts:    make(map[int64]*Post),
+		actors:   map[int64]*Actor{1: {ID: 1}, 2: {ID: 2}, 3: {ID: 3, IsTeacher: true}, 9: {ID: 9, IsAdmin: true}},
+		users:    map[string]int64{"alice": 1, "bob": 2, "teacher": 3},
+		mentions: make(map[int64][]int64),
+		reads:    make(map[[2]int64]int64),
+	}
+}
+
+func (m *mockRepo) CreateThread(_ context.Context, thread *Thread, root *Post, mentions []int64) (int64, int64, error) {
+	m.nextID++
+	t := *thread
+	t.ID = m.nextID
+	m.nextID++
+	p := *root
+	p.ID, p.ThreadID = m.nextID, t.ID
+	t.RootPostID = p.ID
+	m.threads[t.ID], m.posts[p.ID] = &t, &p
+	m.mentions[p.ID] = mentions
+	return t.ID, p.ID, nil
+}
+
+func (m *mockRepo) CreatePost(_ context.Context, post *Post, mentions []int64) (int64, error) {
+	m.nextID++
+	p := *post
+	p.ID = m.nextID
+	m.posts[p.ID] = &p
+	m.mentions[p.ID] = mentions
+	return p.ID, nil
+}
+
+func (m *mockRepo) FindThread(_ context.Context, id int64) (*Thread, error) {
+	if t, ok := m.threads[id]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) FindPost(_ context.Context, id int64) (*Post, error) {
+	if p, ok := m.posts[id]; ok {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) ListThreads(_ context.Context, _ int64, filter Filter) ([]Thread, int, error) {
+	var items []Thread
+	for _, t := range m.threads {
+		if (filter.Topic == "" || t.Topic == filter.Topic) && (!t.Hidden || filter.IncludeHidden) {
+			items = append(items, *t)
+		}
+	}
+	return items, len(items), nil
+}
+
+func (m *mockRepo) ListPosts(_ context.Context, threadID int64) ([]Post, error) {
+	var posts []Post
+	for id := int64(1); id <= m.nextID; id++ {
+		if p, ok := m.posts[id]; ok && p.ThreadID == threadID {
+			posts = append(posts, *p)
+		}
+	}
+	return posts, nil
+}
+
+func (m *mockRepo) SetAccepted(_ context.Context, threadID, postID int64) error {
+	m.threads[threadID].AcceptedPostID = postID
+	return nil
+}
+
+func (m *mockRepo) SetPostHidden(_ context.Context, postID int64, hidden bool, _ int64) error {
+	p := m.posts[postID]
+	p.Hidden = hidden
+	if t := m.threads[p.ThreadID]; t.RootPostID == postID {
+		t.Hidden = hidden
+	}
+	return nil
+}
+
+func (m *mockRepo) MarkRead(_ context.Context, userID, threadID, lastPostID int64) error {
+	m.reads[[2]int64{userID, threadID}] = lastPostID
+	return nil
+}
+
+func (m *mockRepo) UnreadByChapter(_ context.Context, _ int64, _ string) ([]ChapterUnread, error) {
+	return []ChapterUnread{{Topic: "types", Chapter: "map", Count: 2}, {Topic: "types", Chapter: "slice", Count: 1}}, nil
+}
+
+func (m *mockRepo) FindUserIDs(_ context.Context, usernames []string) ([]int64, error) {
+	var ids []int64
+	for _, name := range usernames {
+		if id, ok := m.users[name]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockRepo) FindActor(_ context.Context, userID int64) (*Actor, error) {
+	return m.actors[userID], nil
+}
+
+func (m *mockRepo) ListMentions(_ context.Context, _ int64, _ bool, _ int) ([]Mention, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) MarkMentionsRead(_ context.Context, _ int64, _ []int64) error {
+	return nil
+}
+
+type recordingPublisher struct {
+	events []eventbus.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event eventbus.Event) {
+	p.events = append(p.events, event)
+}
+
+type stubCatalog map[string]bool
+
+func (c stubCatalog) HasQuestion(_ context.Context, topic, chapter, questionID string) (bool, error) {
+	return c[topic+"/"+chapter+"/"+questionID], nil
+}
+
+func TestParseMentions(t *testing.T) {
+	got := ParseMentions("@Alice 看看 `@bob` 以及\n```\n@teacher\n```\nmail a@b.com @alice @ab @carol_1")
+	want := []string{"alice", "carol_1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseMentions = %v, want %v", got, want)
+	}
+}
+
+func TestService_CreateThreadSanitizesAndMentions(t *testing.T) {
+	repo := newMockRepo()
+	svc := NewService(repo).WithQuestions(stubCatalog{"types/map/q-map-1": true})
+	ctx := context.Background()
+
+	detail, err := svc.CreateThread(ctx, 1, "types", "map", "q-map-1", " map 遍历顺序 ", "请 @bob 和 @alice 看 <script>x</script> [链接](javascript:alert(1))")
+	if err != nil {
+		t.Fatalf("创建主题失败: %v", err)
+	}
+	if detail.Title != "map 遍历顺序" || len(detail.Posts) != 1 {
+		t.Fatalf("主题不符合预期: %+v", detail)
+	}
+	body := detail.Posts[0].Body
+	if strings.Contains(body, "<script>") || strings.Contains(body, "javascript:") {
+		t.Fatalf("正文未被清理: %q", body)
+	}
+	if got := repo.mentions[detail.RootPostID]; !reflect.DeepEqual(got, []int64{2}) {
+		t.Fatalf("提及应排除作者本人, got %v", got)
+	}
+	if repo.reads[[2]int64{1, detail.ID}] != detail.RootPostID {
+		t.Fatalf("作者查看主题后应标记已读")
+	}
+
+	if _, err := svc.CreateThread(ctx, 1, "types", "map", "q-missing", "标题", "正文"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("不存在的题目应返回 ErrInvalidInput, got %v", err)
+	}
+	if _, err := svc.CreateThread(ctx, 1, "unknown", "map", "", "标题", "正文"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("未知主题应返回 ErrInvalidInput, got %v", err)
+	}
+	if _, err := svc.CreateThread(ctx, 1, "types", "map", "", "标题", "  \x00 "); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("空正文应返回 ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestService_AcceptRequiresTeacher(t *testing.T) {
+	repo := newMockRepo()
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	detail, err := svc.CreateThread(ctx, 1, "types", "map", "", "问题", "为什么？")
+	if err != nil {
+		t.Fatalf("创建主题失败: %v", err)
+	}
+	reply, err := svc.Reply(ctx, 2, detail.ID, detail.RootPostID, "因为哈希")
+	if err != nil {
+		t.Fatalf("回复失败: %v", err)
+	}
+
+	if _, err := svc.Accept(ctx, 1, detail.ID, reply.ID); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("学生采纳应被拒绝, got %v", err)
+	}
+	if _, err := svc.Accept(ctx, 3, detail.ID, detail.RootPostID); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("不能采纳首帖, got %v", err)
+	}
+	thread, err := svc.Accept(ctx, 3, detail.ID, reply.ID)
+	if err != nil || thread.AcceptedPostID != reply.ID {
+		t.Fatalf("教师采纳失败: %+v %v", thread, err)
+	}
+
+	got, err := svc.Thread(ctx, 2, detail.ID)
+	if err != nil {
+		t.Fatalf("查看主题失败: %v", err)
+	}
+	if !got.Posts[1].Accepted || got.Posts[0].Accepted {
+		t.Fatalf("采纳标记不正确: %+v", got.Posts)
+	}
+
+	if _, err := svc.Reply(ctx, 2, detail.ID, 999, "x"); !errors.Is(err, ErrPostNotFound) {
+		t.Fatalf("回复不存在的帖子应返回 ErrPostNotFound, got %v", err)
+	}
+}
+
+func TestService_HideIsAdminOnly(t *testing.T) {
+	repo := newMockRepo()
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	detail, _ := svc.CreateThread(ctx, 1, "types", "map", "", "问题", "为什么？")
+	reply, _ := svc.Reply(ctx, 2, detail.ID, 0, "广告内容")
+	if _, err := svc.Accept(ctx, 3, detail.ID, reply.ID); err != nil {
+		t.Fatalf("采纳失败: %v", err)
+	}
+
+	if _, err := svc.SetHidden(ctx, 3, reply.ID, true); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("教师隐藏应被拒绝, got %v", err)
+	}
+	if _, err := svc.SetHidden(ctx, 9, reply.ID, true); err != nil {
+		t.Fatalf("管理员隐藏失败: %v", err)
+	}
+	if repo.threads[detail.ID].AcceptedPostID != 0 {
+		t.Fatalf("隐藏被采纳的回答后应取消采纳")
+	}
+
+	got, _ := svc.Thread(ctx, 1, detail.ID)
+	if !got.Posts[1].Hidden || got.Posts[1].Body != "" {
+		t.Fatalf("普通用户不应看到隐藏帖子的正文: %+v", got.Posts[1])
+	}
+	got, _ = svc.Thread(ctx, 9, detail.ID)
+	if got.Posts[1].Body != "广告内容" {
+		t.Fatalf("管理员应看到隐藏帖子的正文: %+v", got.Posts[1])
+	}
+
+	if _, err := svc.SetHidden(ctx, 9, detail.RootPostID, true); err != nil {
+		t.Fatalf("隐藏首帖失败: %v", err)
+	}
+	if _, err := svc.Thread(ctx, 1, detail.ID); !errors.Is(err, ErrThreadNotFound) {
+		t.Fatalf("首帖隐藏后普通用户应看不到主题, got %v", err)
+	}
+	page, err := svc.List(ctx, 1, Filter{Topic: "types", IncludeHidden: true})
+	if err != nil || page.Total != 0 {
+		t.Fatalf("普通用户不能列出隐藏主题: %+v %v", page, err)
+	}
+	page, _ = svc.List(ctx, 9, Filter{Topic: "types", IncludeHidden: true})
+	if page.Total != 1 {
+		t.Fatalf("管理员应能列出隐藏主题: %+v", page)
+	}
+}
+
+func TestService_ListDefaultsAndUnread(t *testing.T) {
+	svc := NewService(newMockRepo())
+	ctx := context.Background()
+
+	page, err := svc.List(ctx, 1, Filter{Topic: "types", Chapter: "map", PageSize: 1000})
+	if err != nil {
+		t.Fatalf("列表失败: %v", err)
+	}
+	if page.Page != 1 || page.PageSize != maxPageSize {
+		t.Fatalf("分页参数未规范化: %+v", page)
+	}
+	if len(page.Unread) != 1 || page.Unread[0].Chapter != "map" || page.Unread[0].Count != 2 {
+		t.Fatalf("未读数应只包含过滤的章节: %+v", page.Unread)
+	}
+	if _, err := svc.List(ctx, 1, Filter{Chapter: "map"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("只传章节应返回 ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestService_ReplyPublishesEvents(t *testing.T) {
+	pub := &recordingPublisher{}
+	svc := NewService(newMockRepo()).WithEvents(pub)
+	ctx := context.Background()
+
+	detail, _ := svc.CreateThread(ctx, 1, "types", "map", "", "问题", "为什么？")
+	first, _ := svc.Reply(ctx, 2, detail.ID, 0, "先回答一下")
+	pub.events = nil
+
+	if _, err := svc.Reply(ctx, 3, detail.ID, first.ID, "@alice 请看楼上"); err != nil {
+		t.Fatalf("回复失败: %v", err)
+	}
+	if len(pub.events) != 2 {
+		t.Fatalf("应发布提及与回复两个事件: %+v", pub.events)
+	}
+	if pub.events[0].Type != eventbus.TypeMention || !reflect.DeepEqual(pub.events[0].UserIDs, []int64{1}) {
+		t.Fatalf("提及事件不正确: %+v", pub.events[0])
+	}
+	if pub.events[1].Type != eventbus.TypeDiscussionReply || !reflect.DeepEqual(pub.events[1].UserIDs, []int64{2}) {
+		t.Fatalf("已被提及的主题作者不应再收到回复事件: %+v", pub.events[1])
+	}
+}
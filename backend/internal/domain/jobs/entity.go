@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// 执行状态。
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// 触发方式：schedule 为按计划执行，manual 为管理员手动触发。
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Definition 为各领域注册的后台任务。
+type Definition struct {
+	// Name 为任务唯一标识，如 token_cleanup。
+	Name        string
+	Description string
+	// Schedule 为 cron 表达式，支持 @daily、@every 1h 等写法。
+	Schedule string
+	// Timeout 为单次执行的超时与租约时长，零值使用 DefaultTimeout。
+	Timeout time.Duration
+	// Run 执行任务并返回结果摘要。
+	Run func(ctx context.Context) (string, error)
+}
+
+// State 为任务在数据库中的调度状态；租约未过期时表示已有实例在执行。
+type State struct {
+	Name        string
+	Schedule    string
+	Paused      bool
+	NextRunAt   time.Time
+	LeaseOwner  string
+	LeaseUntil  *time.Time
+	LastError   string
+	LastErrorAt *time.Time
+}
+
+// Run 为一次执行记录。
+type Run struct {
+	ID      int64  `json:"id"`
+	JobName string `json:"jobName"`
+	Trigger string `json:"trigger"`
+	Status  string `json:"status"`
+	// Instance 为执行该任务的服务实例标识。
+	Instance   string     `json:"instance"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	DurationMs int64      `json:"durationMs"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Job 为管理端展示的任务视图。
+type Job struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"`
+	Paused      bool       `json:"paused"`
+	Running     bool       `json:"running"`
+	NextRunAt   *time.Time `json:"nextRunAt,omitempty"`
+	LastRun     *Run       `json:"lastRun,omitempty"`
+	// LastError 为最近一次失败的错误信息，成功执行后仍保留以便排查。
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+}
@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// Repository 定义任务调度状态与执行历史的持久化操作。
+type Repository interface {
+	// EnsureJob 不存在时创建任务；计划表达式变化时同时更新表达式与下次执行时间。
+	EnsureJob(ctx context.Context, name, schedule string, next time.Time) error
+	// FindState 未找到时返回 nil。
+	FindState(ctx context.Context, name string) (*State, error)
+	// SetPaused 设置暂停状态，next 非 nil 时同时更新下次执行时间。
+	SetPaused(ctx context.Context, name string, paused bool, next *time.Time) error
+	// Claim 在租约空闲或已过期时把租约授予 owner 直到 until，返回是否成功；
+	// dueOnly 为 true 时还要求任务未暂停且已到执行时间。
+	Claim(ctx context.Context, name, owner string, now, until time.Time, dueOnly bool) (bool, error)
+	// Release 释放 owner 持有的租约；next 非 nil 时更新下次执行时间，failure 非空时记录为最近错误。
+	Release(ctx context.Context, name, owner string, next *time.Time, failure string, at time.Time) error
+
+	CreateRun(ctx context.Context, run *Run) (int64, error)
+	FinishRun(ctx context.Context, run *Run) error
+	// ListRuns 按开始时间倒序返回执行记录。
+	ListRuns(ctx context.Context, name string, limit int) ([]Run, error)
+	// PruneRuns 只保留任务最近 keep 条执行记录。
+	PruneRuns(ctx context.Context, name string, keep int) error
+}
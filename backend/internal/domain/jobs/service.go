@@ -0,0 +1,367 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/tracing"
+	"go-study2/internal/pkg/cron"
+)
+
+var (
+	// ErrInvalidInput 表示任务定义或参数不合法。
+	ErrInvalidInput = errors.New("任务参数不合法")
+	// ErrJobNotFound 表示任务未注册。
+	ErrJobNotFound = errors.New("任务不存在")
+	// ErrJobRunning 表示任务正在其他实例或本实例中执行。
+	ErrJobRunning = errors.New("任务正在执行")
+)
+
+const (
+	// DefaultTimeout 为未设置超时的任务的执行超时与租约时长。
+	DefaultTimeout = 10 * time.Minute
+	// keepRuns 为每个任务保留的执行记录数。
+	keepRuns         = 50
+	maxResultRunes   = 500
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+type entry struct {
+	def      Definition
+	schedule *cron.Schedule
+}
+
+// Registry 保存各领域注册的任务定义，按注册顺序展示。
+type Registry struct {
+	mu      sync.RWMutex
+	entries []*entry
+	byName  map[string]*entry
+}
+
+// NewRegistry 创建空的任务注册表。
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]*entry)}
+}
+
+// Register 校验并登记任务定义，名称重复或 cron 表达式无效时返回错误。
+func (r *Registry) Register(def Definition) error {
+	if !namePattern.MatchString(def.Name) || def.Run == nil || def.Timeout < 0 {
+		return ErrInvalidInput
+	}
+	schedule, err := cron.Parse(def.Schedule)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidInput, def.Name, err)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("%w: %s: 表达式永远不会触发", ErrInvalidInput, def.Name)
+	}
+	if def.Timeout == 0 {
+		def.Timeout = DefaultTimeout
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byName[def.Name]; ok {
+		return fmt.Errorf("%w: 任务 %s 重复注册", ErrInvalidInput, def.Name)
+	}
+	e := &entry{def: def, schedule: schedule}
+	r.entries = append(r.entries, e)
+	r.byName[def.Name] = e
+	return nil
+}
+
+func (r *Registry) lookup(name string) (*entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.byName[name]
+	return e, ok
+}
+
+func (r *Registry) all() []*entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*entry(nil), r.entries...)
+}
+
+// Service 调度并执行注册的后台任务。调度状态保存在数据库中，执行前先抢占带期限的租约，
+// 保证多个服务实例共享同一数据库时每次计划只由一个实例执行。
+type Service struct {
+	repo     Repository
+	registry *Registry
+	// owner 为本实例标识，写入租约与执行记录。
+	owner string
+	now   func() time.Time
+	spawn func(func())
+}
+
+// NewService 创建任务服务，owner 用于区分不同服务实例。
+func NewService(repo Repository, registry *Registry, owner string) *Service {
+	return &Service{
+		repo:     repo,
+		registry: registry,
+		owner:    owner,
+		now:      time.Now,
+		spawn:    func(fn func()) { go fn() },
+	}
+}
+
+// WithClock 注入时钟，便于测试计划时间。
+func (s *Service) WithClock(now func() time.Time) *Service {
+	if now != nil {
+		s.now = now
+	}
+	return s
+}
+
+// WithSpawn 设置手动触发时执行任务的方式，默认在新协程中执行。
+func (s *Service) WithSpawn(spawn func(func())) *Service {
+	if spawn != nil {
+		s.spawn = spawn
+	}
+	return s
+}
+
+// Sync 为每个注册任务创建调度状态，计划表达式变化时按新表达式重新计算下次执行时间。
+func (s *Service) Sync(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "jobs.Service.Sync")
+	defer span.End()
+	now := s.now()
+	for _, e := range s.registry.all() {
+		if err := s.repo.EnsureJob(ctx, e.def.Name, e.schedule.String(), s.nextRun(e, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List 按注册顺序返回全部任务及其最近一次执行。
+func (s *Service) List(ctx context.Context) ([]Job, error) {
+	ctx, span := tracing.Start(ctx, "jobs.Service.List")
+	defer span.End()
+	if err := s.Sync(ctx); err != nil {
+		return nil, err
+	}
+	entries := s.registry.all()
+	list := make([]Job, 0, len(entries))
+	for _, e := range entries {
+		job, err := s.view(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *job)
+	}
+	return list, nil
+}
+
+// Runs 返回任务最近的执行记录。
+func (s *Service) Runs(ctx context.Context, name string, limit int) ([]Run, error) {
+	ctx, span := tracing.Start(ctx, "jobs.Service.Runs")
+	defer span.End()
+	if _, ok := s.registry.lookup(name); !ok {
+		return nil, ErrJobNotFound
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	runs, err := s.repo.ListRuns(ctx, name, limit)
+	if err != nil {
+		return nil, err
+	}
+	if runs == nil {
+		runs = []Run{}
+	}
+	return runs, nil
+}
+
+// SetPaused 暂停或恢复按计划执行；恢复时从当前时间起重新计算下次执行时间，不补跑暂停期间错过的计划。
+func (s *Service) SetPaused(ctx context.Context, operatorID int64, name string, paused bool) (*Job, error) {
+	ctx, span := tracing.Start(ctx, "jobs.Service.SetPaused")
+	defer span.End()
+	e, ok := s.registry.lookup(name)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	if err := s.Sync(ctx); err != nil {
+		return nil, err
+	}
+	var next *time.Time
+	if !paused {
+		at := s.nextRun(e, s.now())
+		next = &at
+	}
+	if err := s.repo.SetPaused(ctx, name, paused, next); err != nil {
+		return nil, err
+	}
+	event := "job_resumed"
+	if paused {
+		event = "job_paused"
+	}
+	audit.Record(ctx, event, operatorID, "ok", "job="+name)
+	return s.view(ctx, e)
+}
+
+// Trigger 立即在后台执行一次任务（暂停中的任务同样可以手动触发），不影响下次计划时间。
+func (s *Service) Trigger(ctx context.Context, operatorID int64, name string) (*Run, error) {
+	ctx, span := tracing.Start(ctx, "jobs.Service.Trigger")
+	defer span.End()
+	e, ok := s.registry.lookup(name)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	if err := s.Sync(ctx); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	claimed, err := s.repo.Claim(ctx, name, s.owner, now.UTC(), now.Add(e.def.Timeout).UTC(), false)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrJobRunning
+	}
+	run, err := s.start(ctx, e, TriggerManual, now)
+	if err != nil {
+		s.release(ctx, e, nil, err, now)
+		return nil, err
+	}
+	audit.Record(ctx, "job_triggered", operatorID, "ok", fmt.Sprintf("job=%s run_id=%d", name, run.ID))
+	view := *run
+	bg := context.WithoutCancel(ctx)
+	s.spawn(func() { s.execute(bg, e, run, nil) })
+	return &view, nil
+}
+
+// RunDue 依次执行已到期且未暂停的任务，返回本实例执行的任务数；租约被其他实例持有的任务会被跳过。
+func (s *Service) RunDue(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "jobs.Service.RunDue")
+	defer span.End()
+	executed := 0
+	for _, e := range s.registry.all() {
+		if ctx.Err() != nil {
+			return executed, ctx.Err()
+		}
+		now := s.now()
+		claimed, err := s.repo.Claim(ctx, e.def.Name, s.owner, now.UTC(), now.Add(e.def.Timeout).UTC(), true)
+		if err != nil {
+			return executed, err
+		}
+		if !claimed {
+			continue
+		}
+		run, err := s.start(ctx, e, TriggerSchedule, now)
+		if err != nil {
+			s.release(ctx, e, nil, err, now)
+			return executed, err
+		}
+		next := s.nextRun(e, now)
+		s.execute(ctx, e, run, &next)
+		executed++
+	}
+	return executed, nil
+}
+
+// start 写入一条执行中的记录。
+func (s *Service) start(ctx context.Context, e *entry, trigger string, now time.Time) (*Run, error) {
+	run := &Run{
+		JobName:   e.def.Name,
+		Trigger:   trigger,
+		Status:    RunRunning,
+		Instance:  s.owner,
+		StartedAt: now.UTC(),
+	}
+	id, err := s.repo.CreateRun(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+	run.ID = id
+	return run, nil
+}
+
+// execute 在超时限制内执行任务，记录结果并释放租约；任务 panic 视为失败。
+func (s *Service) execute(ctx context.Context, e *entry, run *Run, next *time.Time) {
+	runCtx, cancel := context.WithTimeout(ctx, e.def.Timeout)
+	result, runErr := safeRun(runCtx, e.def.Run)
+	cancel()
+
+	finished := s.now()
+	finishedAt := finished.UTC()
+	run.FinishedAt = &finishedAt
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	run.Result = truncate(result)
+	run.Status = RunSucceeded
+	if runErr != nil {
+		run.Status = RunFailed
+		run.Error = truncate(runErr.Error())
+	}
+	// 任务可能因 ctx 结束而中断，收尾写库不受其影响
+	store := context.WithoutCancel(ctx)
+	_ = s.repo.FinishRun(store, run)
+	s.release(store, e, next, runErr, finished)
+	_ = s.repo.PruneRuns(store, e.def.Name, keepRuns)
+}
+
+func (s *Service) release(ctx context.Context, e *entry, next *time.Time, runErr error, at time.Time) {
+	failure := ""
+	if runErr != nil {
+		failure = truncate(runErr.Error())
+	}
+	_ = s.repo.Release(ctx, e.def.Name, s.owner, next, failure, at.UTC())
+}
+
+func (s *Service) view(ctx context.Context, e *entry) (*Job, error) {
+	job := &Job{Name: e.def.Name, Description: e.def.Description, Schedule: e.schedule.String()}
+	state, err := s.repo.FindState(ctx, e.def.Name)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil {
+		job.Paused = state.Paused
+		job.Running = state.LeaseUntil != nil && state.LeaseUntil.After(s.now())
+		if !state.Paused {
+			next := state.NextRunAt
+			job.NextRunAt = &next
+		}
+		job.LastError = state.LastError
+		job.LastErrorAt = state.LastErrorAt
+	}
+	runs, err := s.repo.ListRuns(ctx, e.def.Name, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) > 0 {
+		job.LastRun = &runs[0]
+	}
+	return job, nil
+}
+
+// nextRun 按服务器本地时区计算下次执行时间，以 UTC 保存。
+func (s *Service) nextRun(e *entry, now time.Time) time.Time {
+	return e.schedule.Next(now.Local()).UTC()
+}
+
+func safeRun(ctx context.Context, run func(context.Context) (string, error)) (result string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("任务异常退出: %v", p)
+		}
+	}()
+	return run(ctx)
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxResultRunes {
+		return s
+	}
+	return string([]rune(s)[:maxResultRunes]) + "…"
+}
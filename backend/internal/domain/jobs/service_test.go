@@ -0,0 +1,284 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+type mockRepo struct {
+	states map[string]*State
+	runs   []Run
+	nextID int64
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{states: make(map[string]*State)}
+}
+
+func (m *mockRepo) EnsureJob(_ context.Context, name, schedule string, next time.Time) error {
+	state, ok := m.states[name]
+	if !ok {
+		m.states[name] = &State{Name: name, Schedule: schedule, NextRunAt: next}
+		return nil
+	}
+	if state.Schedule != schedule {
+		state.Schedule = schedule
+		state.NextRunAt = next
+	}
+	return nil
+}
+
+func (m *mockRepo) FindState(_ context.Context, name string) (*State, error) {
+	state, ok := m.states[name]
+	if !ok {
+		return nil, nil
+	}
+	copied := *state
+	return &copied, nil
+}
+
+func (m *mockRepo) SetPaused(_ context.Context, name string, paused bool, next *time.Time) error {
+	state := m.states[name]
+	state.Paused = paused
+	if next != nil {
+		state.NextRunAt = *next
+	}
+	return nil
+}
+
+func (m *mockRepo) Claim(_ context.Context, name, owner string, now, until time.Time, dueOnly bool) (bool, error) {
+	state, ok := m.states[name]
+	if !ok || (state.LeaseUntil != nil && state.LeaseUntil.After(now)) {
+		return false, nil
+	}
+	if dueOnly && (state.Paused || state.NextRunAt.After(now)) {
+		return false, nil
+	}
+	state.LeaseOwner = owner
+	state.LeaseUntil = &until
+	return true, nil
+}
+
+func (m *mockRepo) Release(_ context.Context, name, owner string, next *time.Time, failure string, at time.Time) error {
+	state := m.states[name]
+	if state.LeaseOwner != owner {
+		return nil
+	}
+	state.LeaseOwner = ""
+	state.LeaseUntil = nil
+	if next != nil {
+		state.NextRunAt = *next
+	}
+	if failure != "" {
+		state.LastError = failure
+		state.LastErrorAt = &at
+	}
+	return nil
+}
+
+func (m *mockRepo) CreateRun(_ context.Context, run *Run) (int64, error) {
+	m.nextID++
+	copied := *run
+	copied.ID = m.nextID
+	m.runs = append(m.runs, copied)
+	return copied.ID, nil
+}
+
+func (m *mockRepo) FinishRun(_ context.Context, run *Run) error {
+	for i := range m.runs {
+		if m.runs[i].ID == run.ID {
+			m.runs[i] = *run
+		}
+	}
+	return nil
+}
+
+func (m *mockRepo) ListRuns(_ context.Context, name string, limit int) ([]Run, error) {
+	var list []Run
+	for _, run := range m.runs {
+		if run.JobName == name {
+			list = append(list, run)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (m *mockRepo) PruneRuns(_ context.Context, name string, keep int) error {
+	return nil
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestService(t *testing.T, defs ...Definition) (*Service, *mockRepo, *fakeClock) {
+	t.Helper()
+	registry := NewRegistry()
+	for _, def := range defs {
+		if err := registry.Register(def); err != nil {
+			t.Fatalf("注册任务失败: %v", err)
+		}
+	}
+	repo := newMockRepo()
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 10, 30, 0, 0, time.Local)}
+	svc := NewService(repo, registry, "node-a").WithClock(clock.Now).WithSpawn(func(fn func()) { fn() })
+	return svc, repo, clock
+}
+
+func TestRegistry_RejectsInvalidDefinitions(t *testing.T) {
+	registry := NewRegistry()
+	run := func(context.Context) (string, error) { return "", nil }
+	for _, def := range []Definition{
+		{Name: "", Schedule: "@daily", Run: run},
+		{Name: "Bad-Name", Schedule: "@daily", Run: run},
+		{Name: "no_run", Schedule: "@daily"},
+		{Name: "bad_cron", Schedule: "61 * * * *", Run: run},
+		{Name: "never", Schedule: "0 0 30 2 *", Run: run},
+	} {
+		if err := registry.Register(def); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("%q 应被拒绝，得到: %v", def.Name, err)
+		}
+	}
+	if err := registry.Register(Definition{Name: "ok", Schedule: "@hourly", Run: run}); err != nil {
+		t.Fatalf("合法任务注册失败: %v", err)
+	}
+	if err := registry.Register(Definition{Name: "ok", Schedule: "@daily", Run: run}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("重复注册应被拒绝")
+	}
+}
+
+func TestService_RunDueExecutesOncePerSchedule(t *testing.T) {
+	calls := 0
+	svc, repo, clock := newTestService(t, Definition{
+		Name:     "cleanup",
+		Schedule: "0 * * * *",
+		Run: func(context.Context) (string, error) {
+			calls++
+			return "deleted=3", nil
+		},
+	})
+	ctx := context.Background()
+	if err := svc.Sync(ctx); err != nil {
+		t.Fatalf("同步任务失败: %v", err)
+	}
+	if n, _ := svc.RunDue(ctx); n != 0 || calls != 0 {
+		t.Fatalf("未到执行时间不应运行: %d", n)
+	}
+
+	clock.now = clock.now.Add(30 * time.Minute)
+	if n, err := svc.RunDue(ctx); err != nil || n != 1 || calls != 1 {
+		t.Fatalf("到期任务应执行一次: %d %v", n, err)
+	}
+	if n, _ := svc.RunDue(ctx); n != 0 || calls != 1 {
+		t.Fatalf("同一计划不应重复执行")
+	}
+	state := repo.states["cleanup"]
+	if want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local); !state.NextRunAt.Equal(want) || state.LeaseUntil != nil {
+		t.Fatalf("执行后应释放租约并推进到下一整点: %+v", state)
+	}
+	runs, _ := svc.Runs(ctx, "cleanup", 0)
+	if len(runs) != 1 || runs[0].Status != RunSucceeded || runs[0].Trigger != TriggerSchedule ||
+		runs[0].Result != "deleted=3" || runs[0].Instance != "node-a" || runs[0].FinishedAt == nil {
+		t.Fatalf("执行记录不正确: %+v", runs)
+	}
+}
+
+func TestService_LeaseHeldByOtherInstanceSkipsRun(t *testing.T) {
+	calls := 0
+	svc, repo, clock := newTestService(t, Definition{
+		Name:     "reminders",
+		Schedule: "@every 1m",
+		Run:      func(context.Context) (string, error) { calls++; return "", nil },
+	})
+	ctx := context.Background()
+	_ = svc.Sync(ctx)
+	clock.now = clock.now.Add(2 * time.Minute)
+	until := clock.now.Add(time.Minute)
+	repo.states["reminders"].LeaseOwner = "node-b"
+	repo.states["reminders"].LeaseUntil = &until
+
+	if n, _ := svc.RunDue(ctx); n != 0 || calls != 0 {
+		t.Fatalf("其他实例持有租约时不应执行")
+	}
+	if _, err := svc.Trigger(ctx, 1, "reminders"); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("租约被占用时手动触发应返回 ErrJobRunning，得到: %v", err)
+	}
+	// 租约过期后视为持有者已失联
+	clock.now = until
+	if n, _ := svc.RunDue(ctx); n != 1 || calls != 1 {
+		t.Fatalf("租约过期后应由本实例接管")
+	}
+}
+
+func TestService_FailureAndPanicRecordedAsLastError(t *testing.T) {
+	svc, repo, clock := newTestService(t,
+		Definition{Name: "failing", Schedule: "@every 1m", Run: func(context.Context) (string, error) {
+			return "", errors.New("数据库已锁定")
+		}},
+		Definition{Name: "panicking", Schedule: "@every 1m", Run: func(context.Context) (string, error) {
+			panic("boom")
+		}},
+	)
+	ctx := context.Background()
+	_ = svc.Sync(ctx)
+	clock.now = clock.now.Add(time.Minute)
+	if n, err := svc.RunDue(ctx); err != nil || n != 2 {
+		t.Fatalf("失败的任务也应完成执行流程: %d %v", n, err)
+	}
+	list, err := svc.List(ctx)
+	if err != nil || len(list) != 2 {
+		t.Fatalf("列出任务失败: %v", err)
+	}
+	if list[0].LastError != "数据库已锁定" || list[0].LastRun == nil || list[0].LastRun.Status != RunFailed {
+		t.Fatalf("失败原因应记录为最近错误: %+v", list[0])
+	}
+	if repo.states["panicking"].LastError == "" || list[1].Running {
+		t.Fatalf("panic 应视为失败并释放租约: %+v", list[1])
+	}
+}
+
+func TestService_PauseResumeAndManualTrigger(t *testing.T) {
+	calls := 0
+	svc, repo, clock := newTestService(t, Definition{
+		Name:     "report",
+		Schedule: "0 9 * * *",
+		Run:      func(context.Context) (string, error) { calls++; return "ok", nil },
+	})
+	ctx := context.Background()
+
+	job, err := svc.SetPaused(ctx, 1, "report", true)
+	if err != nil || !job.Paused || job.NextRunAt != nil {
+		t.Fatalf("暂停任务失败: %+v %v", job, err)
+	}
+	clock.now = clock.now.Add(48 * time.Hour)
+	if n, _ := svc.RunDue(ctx); n != 0 {
+		t.Fatalf("暂停中的任务不应按计划执行")
+	}
+	run, err := svc.Trigger(ctx, 1, "report")
+	if err != nil || run.Trigger != TriggerManual || calls != 1 {
+		t.Fatalf("暂停中的任务仍可手动触发: %+v %v", run, err)
+	}
+	if repo.states["report"].LeaseUntil != nil {
+		t.Fatalf("手动执行结束后应释放租约")
+	}
+
+	job, err = svc.SetPaused(ctx, 1, "report", false)
+	if err != nil || job.Paused || job.NextRunAt == nil {
+		t.Fatalf("恢复任务失败: %+v %v", job, err)
+	}
+	if want := time.Date(2024, 5, 4, 9, 0, 0, 0, time.Local); !job.NextRunAt.Equal(want) {
+		t.Fatalf("恢复后应从当前时间计算下次执行，得到 %v", job.NextRunAt)
+	}
+	if _, err := svc.Trigger(ctx, 1, "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("未注册的任务应返回 ErrJobNotFound")
+	}
+}
@@ -0,0 +1,98 @@
+package leaderboard
+
+import "time"
+
+// 排行指标。
+const (
+	// MetricPoints 积分：每个章节取最佳得分率（0-100）累加，重复测验不会刷分。
+	MetricPoints = "points"
+	// MetricMastered 掌握章节数：最佳得分率达到 MasteryPercent 的章节数。
+	MetricMastered = "mastered"
+	// MetricChapter 单章节最佳得分率，需指定 topic 与 chapter。
+	MetricChapter = "chapter"
+)
+
+// 排行范围。
+const (
+	ScopeGlobal = "global"
+	ScopeClass  = "class"
+)
+
+// 统计窗口。
+const (
+	WindowAll  = "all"
+	WindowWeek = "week"
+)
+
+// MasteryPercent 为判定章节已掌握的得分率。
+const MasteryPercent = 80
+
+// Query 描述一次排行榜查询，WeekStart 仅在周窗口下有效。
+type Query struct {
+	Metric    string
+	Scope     string
+	ClassID   int64
+	Window    string
+	WeekStart time.Time
+	Topic     string
+	Chapter   string
+	Limit     int
+}
+
+// Row 为仓储返回的单个用户成绩，已排除选择退出的用户。
+type Row struct {
+	UserID   int64
+	Username string
+	Nickname string
+	Value    float64
+}
+
+// Standing 为某个用户在排行中的位置。
+type Standing struct {
+	Value float64
+	// Rank 为并列排名（1 + 成绩更高的人数），用户无成绩时为 0。
+	Rank int
+}
+
+// Entry 为排行榜中的一行，不暴露用户 ID。
+type Entry struct {
+	Rank        int     `json:"rank"`
+	DisplayName string  `json:"displayName"`
+	Value       float64 `json:"value"`
+	IsMe        bool    `json:"isMe"`
+}
+
+// MyStanding 为当前用户自己的成绩，选择退出时不计排名。
+type MyStanding struct {
+	Rank      int     `json:"rank"`
+	Value     float64 `json:"value"`
+	OptedOut  bool    `json:"optedOut"`
+	Nickname  string  `json:"nickname,omitempty"`
+	HasResult bool    `json:"hasResult"`
+}
+
+// Board 为排行榜查询结果。
+type Board struct {
+	Metric    string     `json:"metric"`
+	Scope     string     `json:"scope"`
+	ClassID   int64      `json:"classId,omitempty"`
+	Window    string     `json:"window"`
+	WeekStart *time.Time `json:"weekStart,omitempty"`
+	Topic     string     `json:"topic,omitempty"`
+	Chapter   string     `json:"chapter,omitempty"`
+	Entries   []Entry    `json:"entries"`
+	Me        MyStanding `json:"me"`
+}
+
+// Preferences 为用户的排行榜隐私设置。
+type Preferences struct {
+	OptOut   bool   `json:"optOut"`
+	Nickname string `json:"nickname"`
+}
+
+// ClassState 为班级排行榜的可见性信息。
+type ClassState struct {
+	Enabled bool
+	// Member 表示查看者是否为班级成员（教师或学生）。
+	Member bool
+}
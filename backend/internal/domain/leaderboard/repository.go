@@ -0,0 +1,20 @@
+package leaderboard
+
+import "context"
+
+// Repository 定义排行榜所需的查询接口，成绩来自测验记录的物化汇总。
+type Repository interface {
+	// Top 按成绩降序返回前 limit 名，班级范围只统计学生成员，已选择退出的用户不参与。
+	Top(ctx context.Context, q Query, limit int) ([]Row, error)
+	// Standing 返回用户在同一查询下的成绩与并列排名，无成绩时返回 nil。
+	Standing(ctx context.Context, q Query, userID int64) (*Standing, error)
+
+	FindPreferences(ctx context.Context, userID int64) (*Preferences, error)
+	SavePreferences(ctx context.Context, userID int64, prefs Preferences) error
+	// NicknameTaken 判断昵称是否已被其他用户用作昵称或用户名。
+	NicknameTaken(ctx context.Context, nickname string, userID int64) (bool, error)
+
+	// ClassState 返回班级排行榜状态，班级不存在时返回 nil。
+	ClassState(ctx context.Context, classID, userID int64) (*ClassState, error)
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+}
@@ -0,0 +1,272 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go-study2/internal/domain/quiz"
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/tracing"
+)
+
+var (
+	// ErrInvalidInput 表示查询或设置参数不合法。
+	ErrInvalidInput = errors.New("排行榜参数不合法")
+	// ErrClassNotFound 表示班级不存在或当前用户不在班级中。
+	ErrClassNotFound = errors.New("班级不存在")
+	// ErrLeaderboardDisabled 表示班级排行榜已被管理员关闭。
+	ErrLeaderboardDisabled = errors.New("班级排行榜已关闭")
+	// ErrNicknameTaken 表示昵称已被占用。
+	ErrNicknameTaken = errors.New("昵称已被占用")
+)
+
+const (
+	defaultLimit      = 20
+	maxLimit          = 100
+	minNicknameLength = 2
+	maxNicknameLength = 20
+)
+
+// Service 计算排行榜并管理隐私设置。
+type Service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+// NewService 创建排行榜服务。
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// WithClock 替换时钟，便于测试周窗口。
+func (s *Service) WithClock(now func() time.Time) *Service {
+	s.now = now
+	return s
+}
+
+// Board 返回排行榜；班级范围仅班级成员与管理员可见，且班级未关闭排行榜。
+func (s *Service) Board(ctx context.Context, actorID int64, q Query) (*Board, error) {
+	ctx, span := tracing.Start(ctx, "leaderboard.Service.Board")
+	defer span.End()
+	if actorID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	q, err := s.normalize(q)
+	if err != nil {
+		return nil, err
+	}
+	if q.Scope == ScopeClass {
+		if err := s.checkClass(ctx, actorID, q.ClassID); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.repo.Top(ctx, q, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	board := &Board{
+		Metric:  q.Metric,
+		Scope:   q.Scope,
+		ClassID: q.ClassID,
+		Window:  q.Window,
+		Topic:   q.Topic,
+		Chapter: q.Chapter,
+		Entries: make([]Entry, 0, len(rows)),
+	}
+	if q.Window == WindowWeek {
+		weekStart := q.WeekStart
+		board.WeekStart = &weekStart
+	}
+	for i, row := range rows {
+		rank := i + 1
+		if i > 0 && rows[i-1].Value == row.Value {
+			rank = board.Entries[i-1].Rank
+		}
+		name := row.Username
+		if row.Nickname != "" {
+			name = row.Nickname
+		}
+		board.Entries = append(board.Entries, Entry{
+			Rank:        rank,
+			DisplayName: name,
+			Value:       roundValue(q.Metric, row.Value),
+			IsMe:        row.UserID == actorID,
+		})
+	}
+
+	prefs, err := s.Preferences(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	board.Me = MyStanding{OptedOut: prefs.OptOut, Nickname: prefs.Nickname}
+	standing, err := s.repo.Standing(ctx, q, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if standing != nil {
+		board.Me.HasResult = true
+		board.Me.Value = roundValue(q.Metric, standing.Value)
+		if !prefs.OptOut {
+			board.Me.Rank = standing.Rank
+		}
+	}
+	return board, nil
+}
+
+// Preferences 返回用户的隐私设置，未设置时为默认值。
+func (s *Service) Preferences(ctx context.Context, userID int64) (*Preferences, error) {
+	ctx, span := tracing.Start(ctx, "leaderboard.Service.Preferences")
+	defer span.End()
+	if userID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	prefs, err := s.repo.FindPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil {
+		return &Preferences{}, nil
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences 设置是否退出排行榜与展示昵称，昵称为空时展示用户名。
+func (s *Service) UpdatePreferences(ctx context.Context, userID int64, prefs Preferences) (*Preferences, error) {
+	ctx, span := tracing.Start(ctx, "leaderboard.Service.UpdatePreferences")
+	defer span.End()
+	if userID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	prefs.Nickname = strings.TrimSpace(prefs.Nickname)
+	if prefs.Nickname != "" {
+		if !validNickname(prefs.Nickname) {
+			return nil, ErrInvalidInput
+		}
+		taken, err := s.repo.NicknameTaken(ctx, prefs.Nickname, userID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrNicknameTaken
+		}
+	}
+	if err := s.repo.SavePreferences(ctx, userID, prefs); err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "leaderboard_preferences_updated", userID, "ok", fmt.Sprintf("opt_out=%t nickname=%t", prefs.OptOut, prefs.Nickname != ""))
+	return &prefs, nil
+}
+
+func (s *Service) normalize(q Query) (Query, error) {
+	q.Metric = strings.TrimSpace(q.Metric)
+	q.Scope = strings.TrimSpace(q.Scope)
+	q.Window = strings.TrimSpace(q.Window)
+	q.Topic = strings.TrimSpace(q.Topic)
+	q.Chapter = strings.ToLower(strings.TrimSpace(q.Chapter))
+	if q.Metric == "" {
+		q.Metric = MetricPoints
+	}
+	if q.Scope == "" {
+		q.Scope = ScopeGlobal
+		if q.ClassID > 0 {
+			q.Scope = ScopeClass
+		}
+	}
+	if q.Window == "" {
+		q.Window = WindowAll
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultLimit
+	}
+	if q.Limit > maxLimit {
+		q.Limit = maxLimit
+	}
+
+	switch q.Metric {
+	case MetricPoints, MetricMastered:
+		q.Topic, q.Chapter = "", ""
+	case MetricChapter:
+		if !quiz.IsSupportedTopic(q.Topic) || q.Chapter == "" {
+			return q, ErrInvalidInput
+		}
+	default:
+		return q, ErrInvalidInput
+	}
+	switch q.Scope {
+	case ScopeGlobal:
+		q.ClassID = 0
+	case ScopeClass:
+		if q.ClassID <= 0 {
+			return q, ErrInvalidInput
+		}
+	default:
+		return q, ErrInvalidInput
+	}
+	switch q.Window {
+	case WindowAll:
+		q.WeekStart = time.Time{}
+	case WindowWeek:
+		q.WeekStart = WeekStart(s.now())
+	default:
+		return q, ErrInvalidInput
+	}
+	return q, nil
+}
+
+func (s *Service) checkClass(ctx context.Context, actorID, classID int64) error {
+	state, err := s.repo.ClassState(ctx, classID, actorID)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return ErrClassNotFound
+	}
+	if !state.Member {
+		admin, err := s.repo.IsAdmin(ctx, actorID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return ErrClassNotFound
+		}
+	}
+	if !state.Enabled {
+		return ErrLeaderboardDisabled
+	}
+	return nil
+}
+
+// WeekStart 返回 t 所在自然周的周一零点（UTC）。
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// roundValue 积分与章节数取整，得分率保留一位小数。
+func roundValue(metric string, value float64) float64 {
+	if metric == MetricChapter {
+		return math.Round(value*10) / 10
+	}
+	return math.Round(value)
+}
+
+func validNickname(nickname string) bool {
+	n := utf8.RuneCountInString(nickname)
+	if n < minNicknameLength || n > maxNicknameLength {
+		return false
+	}
+	for _, r := range nickname {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != ' ' {
+			return false
+		}
+	}
+	return true
+}
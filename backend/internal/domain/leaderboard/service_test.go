@@ -0,0 +1,217 @@
+package leaderboard
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+type mockRepo struct {
+	rows      []Row
+	prefs     map[int64]Preferences
+	usernames map[int64]string
+	classes   map[int64]*ClassState
+	admins    map[int64]bool
+	lastQuery Query
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{
+		prefs:     make(map[int64]Preferences),
+		usernames: make(map[int64]string),
+		classes:   make(map[int64]*ClassState),
+		admins:    make(map[int64]bool),
+	}
+}
+
+func (m *mockRepo) visible() []Row {
+	var list []Row
+	for _, row := range m.rows {
+		if m.prefs[row.UserID].OptOut {
+			continue
+		}
+		row.Nickname = m.prefs[row.UserID].Nickname
+		list = append(list, row)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Value > list[j].Value })
+	return list
+}
+
+func (m *mockRepo) Top(_ context.Context, q Query, limit int) ([]Row, error) {
+	m.lastQuery = q
+	list := m.visible()
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (m *mockRepo) Standing(_ context.Context, _ Query, userID int64) (*Standing, error) {
+	for _, row := range m.rows {
+		if row.UserID != userID {
+			continue
+		}
+		above := 0
+		for _, other := range m.visible() {
+			if other.Value > row.Value {
+				above++
+			}
+		}
+		return &Standing{Value: row.Value, Rank: above + 1}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) FindPreferences(_ context.Context, userID int64) (*Preferences, error) {
+	if p, ok := m.prefs[userID]; ok {
+		return &p, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) SavePreferences(_ context.Context, userID int64, prefs Preferences) error {
+	m.prefs[userID] = prefs
+	return nil
+}
+
+func (m *mockRepo) NicknameTaken(_ context.Context, nickname string, userID int64) (bool, error) {
+	for id, p := range m.prefs {
+		if id != userID && p.Nickname == nickname {
+			return true, nil
+		}
+	}
+	for id, name := range m.usernames {
+		if id != userID && name == nickname {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRepo) ClassState(_ context.Context, classID, _ int64) (*ClassState, error) {
+	return m.classes[classID], nil
+}
+
+func (m *mockRepo) IsAdmin(_ context.Context, userID int64) (bool, error) {
+	return m.admins[userID], nil
+}
+
+func TestService_BoardRanksTiesAndPrivacy(t *testing.T) {
+	repo := newMockRepo()
+	repo.rows = []Row{
+		{UserID: 1, Username: "alice", Value: 300},
+		{UserID: 2, Username: "bob", Value: 250},
+		{UserID: 3, Username: "carol", Value: 250},
+		{UserID: 4, Username: "dave", Value: 400},
+	}
+	repo.usernames = map[int64]string{1: "alice", 2: "bob", 3: "carol", 4: "dave"}
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.UpdatePreferences(ctx, 4, Preferences{OptOut: true}); err != nil {
+		t.Fatalf("退出排行榜失败: %v", err)
+	}
+	if _, err := svc.UpdatePreferences(ctx, 2, Preferences{Nickname: "  夜猫子 "}); err != nil {
+		t.Fatalf("设置昵称失败: %v", err)
+	}
+	if _, err := svc.UpdatePreferences(ctx, 3, Preferences{Nickname: "夜猫子"}); err != ErrNicknameTaken {
+		t.Fatalf("重复昵称应被拒绝，得到: %v", err)
+	}
+	if _, err := svc.UpdatePreferences(ctx, 3, Preferences{Nickname: "alice"}); err != ErrNicknameTaken {
+		t.Fatalf("昵称不能冒用他人用户名，得到: %v", err)
+	}
+	if _, err := svc.UpdatePreferences(ctx, 3, Preferences{Nickname: "<b>x</b>"}); err != ErrInvalidInput {
+		t.Fatalf("非法昵称应被拒绝，得到: %v", err)
+	}
+
+	board, err := svc.Board(ctx, 3, Query{})
+	if err != nil {
+		t.Fatalf("查询排行榜失败: %v", err)
+	}
+	if board.Metric != MetricPoints || board.Scope != ScopeGlobal || board.Window != WindowAll || board.WeekStart != nil {
+		t.Fatalf("默认查询参数不正确: %+v", board)
+	}
+	if len(board.Entries) != 3 {
+		t.Fatalf("退出排行榜的用户不应出现: %+v", board.Entries)
+	}
+	want := []Entry{
+		{Rank: 1, DisplayName: "alice", Value: 300},
+		{Rank: 2, DisplayName: "夜猫子", Value: 250},
+		{Rank: 2, DisplayName: "carol", Value: 250, IsMe: true},
+	}
+	for i, e := range want {
+		if board.Entries[i] != e {
+			t.Fatalf("第 %d 行期望 %+v，得到 %+v", i, e, board.Entries[i])
+		}
+	}
+	if board.Me.Rank != 2 || !board.Me.HasResult {
+		t.Fatalf("当前用户排名不正确: %+v", board.Me)
+	}
+
+	hidden, _ := svc.Board(ctx, 4, Query{})
+	if !hidden.Me.OptedOut || hidden.Me.Rank != 0 || hidden.Me.Value != 400 {
+		t.Fatalf("退出者应能看到自己的成绩但没有排名: %+v", hidden.Me)
+	}
+}
+
+func TestService_BoardValidatesQueryAndClassAccess(t *testing.T) {
+	repo := newMockRepo()
+	repo.classes[10] = &ClassState{Enabled: true, Member: true}
+	repo.classes[11] = &ClassState{Enabled: true}
+	repo.classes[12] = &ClassState{Enabled: false, Member: true}
+	repo.admins[9] = true
+	now := time.Date(2026, 3, 12, 15, 0, 0, 0, time.UTC) // 周四
+	svc := NewService(repo).WithClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	invalid := []Query{
+		{Metric: "speed"},
+		{Metric: MetricChapter, Topic: "types"},
+		{Metric: MetricChapter, Topic: "unknown", Chapter: "x"},
+		{Scope: ScopeClass},
+		{Window: "month"},
+	}
+	for i, q := range invalid {
+		if _, err := svc.Board(ctx, 1, q); err != ErrInvalidInput {
+			t.Fatalf("第 %d 个非法查询应被拒绝，得到: %v", i, err)
+		}
+	}
+
+	board, err := svc.Board(ctx, 1, Query{ClassID: 10, Window: WindowWeek, Limit: 500})
+	if err != nil {
+		t.Fatalf("班级成员应能查看班级排行榜: %v", err)
+	}
+	if board.Scope != ScopeClass || board.WeekStart == nil || !board.WeekStart.Equal(time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("班级周榜参数不正确: %+v", board)
+	}
+	if repo.lastQuery.Limit != maxLimit {
+		t.Fatalf("limit 应被限制为 %d，得到 %d", maxLimit, repo.lastQuery.Limit)
+	}
+
+	if _, err := svc.Board(ctx, 1, Query{ClassID: 11}); err != ErrClassNotFound {
+		t.Fatalf("非成员应看不到班级排行榜，得到: %v", err)
+	}
+	if _, err := svc.Board(ctx, 9, Query{ClassID: 11}); err != nil {
+		t.Fatalf("管理员应能查看任意班级排行榜: %v", err)
+	}
+	if _, err := svc.Board(ctx, 1, Query{ClassID: 12}); err != ErrLeaderboardDisabled {
+		t.Fatalf("关闭排行榜的班级应返回 ErrLeaderboardDisabled，得到: %v", err)
+	}
+	if _, err := svc.Board(ctx, 1, Query{ClassID: 99}); err != ErrClassNotFound {
+		t.Fatalf("不存在的班级应返回 ErrClassNotFound，得到: %v", err)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	cases := map[time.Time]time.Time{
+		time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC):    time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 15, 23, 59, 0, 0, time.UTC): time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC):   time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC),
+	}
+	for in, want := range cases {
+		if got := WeekStart(in); !got.Equal(want) {
+			t.Fatalf("WeekStart(%s) 期望 %s，得到 %s", in, want, got)
+		}
+	}
+}
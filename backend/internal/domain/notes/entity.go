@@ -0,0 +1,50 @@
+package notes
+
+import "time"
+
+// 笔记类型：笔记需要正文，书签只标记位置。
+const (
+	KindNote     = "note"
+	KindBookmark = "bookmark"
+)
+
+// Note 表示锚定在内容位置上的个人笔记或书签，仅作者本人可见。
+type Note struct {
+	ID      int64  `json:"id"`
+	UserID  int64  `json:"userId"`
+	Kind    string `json:"kind"`
+	Topic   string `json:"topic"`
+	Chapter string `json:"chapter"`
+	// Anchor 为章节内稳定的小节或示例 ID，为空表示锚定整个章节。
+	Anchor string `json:"anchor"`
+	Title  string `json:"title"`
+	// Body 为 Markdown 原文，按原样保存与返回。
+	Body      string    `json:"body"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Input 为创建或修改笔记的参数，Kind 为空时视为笔记。
+type Input struct {
+	Kind    string
+	Topic   string
+	Chapter string
+	Anchor  string
+	Title   string
+	Body    string
+	Tags    []string
+}
+
+// Filter 为笔记列表的过滤条件，空字段表示不过滤。
+type Filter struct {
+	Topic   string
+	Chapter string
+	Anchor  string
+	Kind    string
+	Tag     string
+	// Query 为全文检索关键词，多个词以空白分隔且需同时命中标题、正文或标签。
+	Query  string
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,17 @@
+package notes
+
+import "context"
+
+// Repository 定义笔记与书签的持久化操作，所有查询都限定在用户本人范围内。
+type Repository interface {
+	Create(ctx context.Context, note *Note) (int64, error)
+	Update(ctx context.Context, note *Note) error
+	// Find 返回用户的笔记，不存在或不属于该用户时返回 nil。
+	Find(ctx context.Context, userID, id int64) (*Note, error)
+	// List 按更新时间倒序返回符合条件的笔记，Limit 为 0 表示不限制。
+	List(ctx context.Context, userID int64, filter Filter) ([]Note, error)
+	// Delete 删除用户的笔记，返回是否删除了记录。
+	Delete(ctx context.Context, userID, id int64) (bool, error)
+	// FindBookmark 返回同一位置的书签，不存在时返回 nil。
+	FindBookmark(ctx context.Context, userID int64, topic, chapter, anchor string) (*Note, error)
+}
@@ -0,0 +1,257 @@
+package notes
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-study2/internal/domain/progress"
+	"go-study2/internal/infrastructure/tracing"
+)
+
+var (
+	// ErrInvalidInput 表示笔记参数不合法。
+	ErrInvalidInput = errors.New("笔记参数不合法")
+	// ErrNoteNotFound 表示笔记不存在或不属于当前用户。
+	ErrNoteNotFound = errors.New("笔记不存在")
+	// ErrBookmarkExists 表示同一位置已有书签。
+	ErrBookmarkExists = errors.New("该位置已添加书签")
+)
+
+const (
+	maxTitleRunes   = 200
+	maxBodyRunes    = 20000
+	maxTags         = 10
+	maxTagRunes     = 32
+	maxQueryRunes   = 100
+	maxQueryTerms   = 5
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+var (
+	chapterPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	anchorPattern  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:-]{0,127}$`)
+)
+
+// Service 管理用户的个人笔记与书签。
+type Service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+// NewService 创建笔记服务。
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Create 创建笔记或书签，同一位置只能有一个书签。
+func (s *Service) Create(ctx context.Context, userID int64, input Input) (*Note, error) {
+	ctx, span := tracing.Start(ctx, "notes.Service.Create")
+	defer span.End()
+	if userID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	note, err := normalize(input)
+	if err != nil {
+		return nil, err
+	}
+	if note.Kind == KindBookmark {
+		existing, err := s.repo.FindBookmark(ctx, userID, note.Topic, note.Chapter, note.Anchor)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, ErrBookmarkExists
+		}
+	}
+	now := s.now().UTC().Truncate(time.Second)
+	note.UserID = userID
+	note.CreatedAt = now
+	note.UpdatedAt = now
+	id, err := s.repo.Create(ctx, note)
+	if err != nil {
+		return nil, err
+	}
+	note.ID = id
+	return note, nil
+}
+
+// Get 返回用户本人的笔记。
+func (s *Service) Get(ctx context.Context, userID, id int64) (*Note, error) {
+	ctx, span := tracing.Start(ctx, "notes.Service.Get")
+	defer span.End()
+	if userID <= 0 || id <= 0 {
+		return nil, ErrNoteNotFound
+	}
+	note, err := s.repo.Find(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, ErrNoteNotFound
+	}
+	return note, nil
+}
+
+// Update 以完整内容覆盖笔记，类型在创建后不可修改。
+func (s *Service) Update(ctx context.Context, userID, id int64, input Input) (*Note, error) {
+	ctx, span := tracing.Start(ctx, "notes.Service.Update")
+	defer span.End()
+	existing, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(input.Kind) == "" {
+		input.Kind = existing.Kind
+	}
+	note, err := normalize(input)
+	if err != nil {
+		return nil, err
+	}
+	if note.Kind != existing.Kind {
+		return nil, ErrInvalidInput
+	}
+	if note.Kind == KindBookmark {
+		other, err := s.repo.FindBookmark(ctx, userID, note.Topic, note.Chapter, note.Anchor)
+		if err != nil {
+			return nil, err
+		}
+		if other != nil && other.ID != id {
+			return nil, ErrBookmarkExists
+		}
+	}
+	note.ID = id
+	note.UserID = userID
+	note.CreatedAt = existing.CreatedAt
+	note.UpdatedAt = s.now().UTC().Truncate(time.Second)
+	if err := s.repo.Update(ctx, note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// Delete 删除用户本人的笔记。
+func (s *Service) Delete(ctx context.Context, userID, id int64) error {
+	ctx, span := tracing.Start(ctx, "notes.Service.Delete")
+	defer span.End()
+	if userID <= 0 || id <= 0 {
+		return ErrNoteNotFound
+	}
+	deleted, err := s.repo.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNoteNotFound
+	}
+	return nil
+}
+
+// List 按过滤条件与关键词检索用户本人的笔记，默认每页 50 条。
+func (s *Service) List(ctx context.Context, userID int64, filter Filter) ([]Note, error) {
+	ctx, span := tracing.Start(ctx, "notes.Service.List")
+	defer span.End()
+	if userID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	filter.Topic = strings.TrimSpace(filter.Topic)
+	filter.Chapter = strings.TrimSpace(filter.Chapter)
+	filter.Anchor = strings.TrimSpace(filter.Anchor)
+	filter.Kind = strings.TrimSpace(filter.Kind)
+	filter.Tag = strings.ToLower(strings.TrimSpace(filter.Tag))
+	filter.Query = strings.Join(strings.Fields(filter.Query), " ")
+	if filter.Topic != "" && !progress.IsSupportedTopic(filter.Topic) {
+		return nil, ErrInvalidInput
+	}
+	if filter.Kind != "" && filter.Kind != KindNote && filter.Kind != KindBookmark {
+		return nil, ErrInvalidInput
+	}
+	if utf8.RuneCountInString(filter.Query) > maxQueryRunes || len(strings.Fields(filter.Query)) > maxQueryTerms {
+		return nil, ErrInvalidInput
+	}
+	if filter.Offset < 0 {
+		return nil, ErrInvalidInput
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+	return s.repo.List(ctx, userID, filter)
+}
+
+// Export 返回用户的全部笔记与书签，用于个人数据导出。
+func (s *Service) Export(ctx context.Context, userID int64) ([]Note, error) {
+	ctx, span := tracing.Start(ctx, "notes.Service.Export")
+	defer span.End()
+	if userID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.repo.List(ctx, userID, Filter{})
+}
+
+// normalize 校验并规范化输入，标签统一小写、去重并排序。
+func normalize(input Input) (*Note, error) {
+	note := &Note{
+		Kind:    strings.TrimSpace(input.Kind),
+		Topic:   strings.TrimSpace(input.Topic),
+		Chapter: strings.TrimSpace(input.Chapter),
+		Anchor:  strings.TrimSpace(input.Anchor),
+		Title:   strings.TrimSpace(input.Title),
+		Body:    strings.TrimSpace(input.Body),
+	}
+	if note.Kind == "" {
+		note.Kind = KindNote
+	}
+	if note.Kind != KindNote && note.Kind != KindBookmark {
+		return nil, ErrInvalidInput
+	}
+	if !progress.IsSupportedTopic(note.Topic) || !chapterPattern.MatchString(note.Chapter) {
+		return nil, ErrInvalidInput
+	}
+	if note.Anchor != "" && !anchorPattern.MatchString(note.Anchor) {
+		return nil, ErrInvalidInput
+	}
+	if note.Kind == KindNote && note.Body == "" {
+		return nil, ErrInvalidInput
+	}
+	if utf8.RuneCountInString(note.Title) > maxTitleRunes || utf8.RuneCountInString(note.Body) > maxBodyRunes {
+		return nil, ErrInvalidInput
+	}
+	tags, ok := normalizeTags(input.Tags)
+	if !ok {
+		return nil, ErrInvalidInput
+	}
+	note.Tags = tags
+	return note, nil
+}
+
+func normalizeTags(tags []string) ([]string, bool) {
+	list := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagRunes || strings.ContainsAny(tag, ", \t\n") {
+			return nil, false
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		list = append(list, tag)
+	}
+	if len(list) > maxTags {
+		return nil, false
+	}
+	sort.Strings(list)
+	return list, true
+}
@@ -0,0 +1,170 @@
+package notes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type mockRepo struct {
+	notes      map[int64]Note
+	nextID     int64
+	lastFilter Filter
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{notes: make(map[int64]Note)}
+}
+
+func (m *mockRepo) Create(_ context.Context, note *Note) (int64, error) {
+	m.nextID++
+	stored := *note
+	stored.ID = m.nextID
+	m.notes[m.nextID] = stored
+	return m.nextID, nil
+}
+
+func (m *mockRepo) Update(_ context.Context, note *Note) error {
+	m.notes[note.ID] = *note
+	return nil
+}
+
+func (m *mockRepo) Find(_ context.Context, userID, id int64) (*Note, error) {
+	note, ok := m.notes[id]
+	if !ok || note.UserID != userID {
+		return nil, nil
+	}
+	return &note, nil
+}
+
+func (m *mockRepo) List(_ context.Context, userID int64, filter Filter) ([]Note, error) {
+	m.lastFilter = filter
+	var list []Note
+	for _, note := range m.notes {
+		if note.UserID == userID && (filter.Topic == "" || note.Topic == filter.Topic) {
+			list = append(list, note)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockRepo) Delete(_ context.Context, userID, id int64) (bool, error) {
+	note, ok := m.notes[id]
+	if !ok || note.UserID != userID {
+		return false, nil
+	}
+	delete(m.notes, id)
+	return true, nil
+}
+
+func (m *mockRepo) FindBookmark(_ context.Context, userID int64, topic, chapter, anchor string) (*Note, error) {
+	for _, note := range m.notes {
+		if note.UserID == userID && note.Kind == KindBookmark && note.Topic == topic && note.Chapter == chapter && note.Anchor == anchor {
+			return &note, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestService_CreateNormalizesAndValidates(t *testing.T) {
+	svc := NewService(newMockRepo())
+	ctx := context.Background()
+
+	note, err := svc.Create(ctx, 1, Input{
+		Topic:   "types",
+		Chapter: "slice",
+		Anchor:  "slice-append",
+		Title:   "  扩容  ",
+		Body:    "**append** 可能触发扩容",
+		Tags:    []string{"Runtime", "slice", "runtime", " "},
+	})
+	if err != nil {
+		t.Fatalf("创建笔记失败: %v", err)
+	}
+	if note.ID == 0 || note.Kind != KindNote || note.Title != "扩容" || note.CreatedAt.IsZero() {
+		t.Fatalf("笔记字段不正确: %+v", note)
+	}
+	if !reflect.DeepEqual(note.Tags, []string{"runtime", "slice"}) {
+		t.Fatalf("标签应小写、去重并排序: %v", note.Tags)
+	}
+
+	invalid := []Input{
+		{Topic: "unknown", Chapter: "slice", Body: "x"},
+		{Topic: "types", Chapter: "", Body: "x"},
+		{Topic: "types", Chapter: "slice", Anchor: "带空格 的锚点", Body: "x"},
+		{Topic: "types", Chapter: "slice"},
+		{Topic: "types", Chapter: "slice", Body: "x", Kind: "highlight"},
+		{Topic: "types", Chapter: "slice", Body: "x", Tags: []string{"a,b"}},
+	}
+	for i, input := range invalid {
+		if _, err := svc.Create(ctx, 1, input); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("第 %d 个输入应校验失败，得到 %v", i, err)
+		}
+	}
+}
+
+func TestService_BookmarksAreUniquePerPosition(t *testing.T) {
+	svc := NewService(newMockRepo())
+	ctx := context.Background()
+
+	first, err := svc.Create(ctx, 1, Input{Kind: KindBookmark, Topic: "variables", Chapter: "storage", Anchor: "ex-1"})
+	if err != nil {
+		t.Fatalf("书签无需正文: %v", err)
+	}
+	if _, err := svc.Create(ctx, 1, Input{Kind: KindBookmark, Topic: "variables", Chapter: "storage", Anchor: "ex-1"}); !errors.Is(err, ErrBookmarkExists) {
+		t.Fatalf("同一位置重复书签应报错，得到 %v", err)
+	}
+	if _, err := svc.Create(ctx, 2, Input{Kind: KindBookmark, Topic: "variables", Chapter: "storage", Anchor: "ex-1"}); err != nil {
+		t.Fatalf("其他用户可在同一位置添加书签: %v", err)
+	}
+	second, _ := svc.Create(ctx, 1, Input{Kind: KindBookmark, Topic: "variables", Chapter: "storage", Anchor: "ex-2"})
+	if _, err := svc.Update(ctx, 1, second.ID, Input{Topic: "variables", Chapter: "storage", Anchor: "ex-1"}); !errors.Is(err, ErrBookmarkExists) {
+		t.Fatalf("移动书签到已有书签的位置应报错，得到 %v", err)
+	}
+	if _, err := svc.Update(ctx, 1, first.ID, Input{Kind: KindNote, Topic: "variables", Chapter: "storage", Body: "x"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("类型不可修改，得到 %v", err)
+	}
+}
+
+func TestService_OwnershipAndList(t *testing.T) {
+	repo := newMockRepo()
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	note, _ := svc.Create(ctx, 1, Input{Topic: "types", Chapter: "map", Body: "map 不是并发安全的"})
+	if _, err := svc.Get(ctx, 2, note.ID); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("他人笔记应不可见，得到 %v", err)
+	}
+	if _, err := svc.Update(ctx, 2, note.ID, Input{Topic: "types", Chapter: "map", Body: "x"}); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("他人笔记应不可修改，得到 %v", err)
+	}
+	if err := svc.Delete(ctx, 2, note.ID); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("他人笔记应不可删除，得到 %v", err)
+	}
+
+	updated, err := svc.Update(ctx, 1, note.ID, Input{Topic: "types", Chapter: "map", Body: "使用 sync.Map 或加锁"})
+	if err != nil || updated.Body != "使用 sync.Map 或加锁" || updated.CreatedAt != note.CreatedAt {
+		t.Fatalf("更新笔记失败: %+v, %v", updated, err)
+	}
+
+	if _, err := svc.List(ctx, 1, Filter{Topic: "types", Query: "  sync   map ", Limit: 1000, Tag: " Go "}); err != nil {
+		t.Fatalf("查询笔记失败: %v", err)
+	}
+	if f := repo.lastFilter; f.Query != "sync map" || f.Limit != maxPageSize || f.Tag != "go" {
+		t.Fatalf("过滤条件未规范化: %+v", f)
+	}
+	if _, err := svc.List(ctx, 1, Filter{Topic: "unknown"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("不支持的主题应报错，得到 %v", err)
+	}
+	if _, err := svc.List(ctx, 1, Filter{Query: "a b c d e f"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("关键词过多应报错，得到 %v", err)
+	}
+
+	if err := svc.Delete(ctx, 1, note.ID); err != nil {
+		t.Fatalf("删除笔记失败: %v", err)
+	}
+	if all, _ := svc.Export(ctx, 1); len(all) != 0 {
+		t.Fatalf("删除后导出应为空: %+v", all)
+	}
+}
@@ -0,0 +1,29 @@
+package notifications
+
+import "time"
+
+// Notification 为收件箱中的一条通知，Type 取自 eventbus 的事件类型。
+type Notification struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"-"`
+	Type   string `json:"type"`
+	// ActorID 为触发通知的用户，0 表示系统。
+	ActorID   int64     `json:"actorId,omitempty"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body,omitempty"`
+	Link      string    `json:"link,omitempty"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Inbox 为收件箱的一页，Unread 为全部未读数。
+type Inbox struct {
+	Items  []Notification `json:"items"`
+	Unread int            `json:"unread"`
+}
+
+// Preference 为某类通知的接收开关，未设置时默认接收。
+type Preference struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
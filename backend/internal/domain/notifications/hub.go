@@ -0,0 +1,72 @@
+package notifications
+
+import "sync"
+
+// subscriberBuffer 为每个实时订阅的缓冲条数，写满说明客户端过慢，订阅会被关闭，由客户端携带 Last-Event-ID 重连补齐。
+const subscriberBuffer = 32
+
+// Hub 在进程内把新通知推送给该用户当前在线的订阅。
+type Hub struct {
+	mu   sync.Mutex
+	subs map[int64]map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	ch     chan Notification
+	closed bool
+}
+
+// NewHub 创建推送中心。
+func NewHub() *Hub {
+	return &Hub{subs: make(map[int64]map[*subscriber]struct{})}
+}
+
+// Subscribe 订阅用户的新通知，返回只读通道与取消函数；通道关闭表示订阅已结束。
+func (h *Hub) Subscribe(userID int64) (<-chan Notification, func()) {
+	sub := &subscriber{ch: make(chan Notification, subscriberBuffer)}
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*subscriber]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(userID, sub)
+	}
+}
+
+// Publish 推送通知，不会阻塞发布者。
+func (h *Hub) Publish(n Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[n.UserID] {
+		select {
+		case sub.ch <- n:
+		default:
+			h.remove(n.UserID, sub)
+		}
+	}
+}
+
+// Online 返回用户当前的实时订阅数。
+func (h *Hub) Online(userID int64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[userID])
+}
+
+// remove 需在持有锁时调用。
+func (h *Hub) remove(userID int64, sub *subscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+	delete(h.subs[userID], sub)
+	if len(h.subs[userID]) == 0 {
+		delete(h.subs, userID)
+	}
+}
@@ -0,0 +1,19 @@
+package notifications
+
+import "context"
+
+// Repository 定义通知收件箱与偏好的持久化操作。
+type Repository interface {
+	Create(ctx context.Context, n *Notification) (int64, error)
+	// List 按 ID 倒序返回通知，beforeID 大于 0 时只返回更早的通知。
+	List(ctx context.Context, userID int64, unreadOnly bool, beforeID int64, limit int) ([]Notification, error)
+	// Since 按 ID 正序返回 afterID 之后的通知，用于断线续传。
+	Since(ctx context.Context, userID, afterID int64, limit int) ([]Notification, error)
+	CountUnread(ctx context.Context, userID int64) (int, error)
+	// MarkRead 将通知标记为已读并返回更新条数，ids 为空表示全部。
+	MarkRead(ctx context.Context, userID int64, ids []int64) (int, error)
+
+	// Preferences 返回用户显式设置过的开关，键为通知类型。
+	Preferences(ctx context.Context, userID int64) (map[string]bool, error)
+	SavePreferences(ctx context.Context, userID int64, prefs map[string]bool) error
+}
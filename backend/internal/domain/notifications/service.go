@@ -0,0 +1,219 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/infrastructure/tracing"
+)
+
+// ErrInvalidInput 表示通知参数不合法。
+var ErrInvalidInput = errors.New("通知参数不合法")
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+	// maxReplay 为断线续传时最多补发的通知数，更早的通知需通过收件箱查询。
+	maxReplay     = 100
+	maxTitleRunes = 200
+	maxBodyRunes  = 500
+)
+
+// Service 把领域事件写入用户收件箱并实时推送，同时管理已读状态与接收偏好。
+type Service struct {
+	repo Repository
+	hub  *Hub
+	now  func() time.Time
+}
+
+// NewService 创建通知服务，hub 为 nil 时只写收件箱不做实时推送。
+func NewService(repo Repository, hub *Hub) *Service {
+	return &Service{repo: repo, hub: hub, now: time.Now}
+}
+
+// Deliver 按接收偏好为事件的每个接收者生成通知，触发者本人不会收到。
+func (s *Service) Deliver(ctx context.Context, event eventbus.Event) error {
+	ctx, span := tracing.Start(ctx, "notifications.Service.Deliver")
+	defer span.End()
+	if !Supports(event.Type) {
+		return ErrInvalidInput
+	}
+	createdAt := event.OccurredAt
+	if createdAt.IsZero() {
+		createdAt = s.now()
+	}
+	seen := make(map[int64]struct{}, len(event.UserIDs))
+	for _, userID := range event.UserIDs {
+		if _, ok := seen[userID]; ok || userID <= 0 || userID == event.ActorID {
+			continue
+		}
+		seen[userID] = struct{}{}
+
+		prefs, err := s.repo.Preferences(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if enabled, ok := prefs[event.Type]; ok && !enabled {
+			continue
+		}
+		n := Notification{
+			UserID:    userID,
+			Type:      event.Type,
+			ActorID:   event.ActorID,
+			Title:     truncate(event.Title, maxTitleRunes),
+			Body:      truncate(event.Body, maxBodyRunes),
+			Link:      event.Link,
+			CreatedAt: createdAt.UTC().Truncate(time.Second),
+		}
+		id, err := s.repo.Create(ctx, &n)
+		if err != nil {
+			return err
+		}
+		n.ID = id
+		if s.hub != nil {
+			s.hub.Publish(n)
+		}
+	}
+	return nil
+}
+
+// List 返回收件箱的一页与未读总数，limit 默认 50、最多 200。
+func (s *Service) List(ctx context.Context, userID int64, unreadOnly bool, beforeID int64, limit int) (*Inbox, error) {
+	ctx, span := tracing.Start(ctx, "notifications.Service.List")
+	defer span.End()
+	if userID <= 0 || beforeID < 0 {
+		return nil, ErrInvalidInput
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	items, err := s.repo.List(ctx, userID, unreadOnly, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Inbox{Items: items, Unread: unread}, nil
+}
+
+// MarkRead 将通知标记为已读，ids 为空表示全部，返回更新条数。
+func (s *Service) MarkRead(ctx context.Context, userID int64, ids []int64) (int, error) {
+	ctx, span := tracing.Start(ctx, "notifications.Service.MarkRead")
+	defer span.End()
+	if userID <= 0 {
+		return 0, ErrInvalidInput
+	}
+	for _, id := range ids {
+		if id <= 0 {
+			return 0, ErrInvalidInput
+		}
+	}
+	return s.repo.MarkRead(ctx, userID, ids)
+}
+
+// Preferences 返回全部通知类型的接收开关。
+func (s *Service) Preferences(ctx context.Context, userID int64) ([]Preference, error) {
+	ctx, span := tracing.Start(ctx, "notifications.Service.Preferences")
+	defer span.End()
+	if userID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	stored, err := s.repo.Preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	types := eventbus.Types()
+	prefs := make([]Preference, 0, len(types))
+	for _, t := range types {
+		enabled, ok := stored[t]
+		prefs = append(prefs, Preference{Type: t, Enabled: !ok || enabled})
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences 更新指定类型的接收开关，未列出的类型保持不变。
+func (s *Service) UpdatePreferences(ctx context.Context, userID int64, updates []Preference) ([]Preference, error) {
+	ctx, span := tracing.Start(ctx, "notifications.Service.UpdatePreferences")
+	defer span.End()
+	if userID <= 0 || len(updates) == 0 {
+		return nil, ErrInvalidInput
+	}
+	changes := make(map[string]bool, len(updates))
+	for _, p := range updates {
+		if !Supports(p.Type) {
+			return nil, ErrInvalidInput
+		}
+		changes[p.Type] = p.Enabled
+	}
+	if err := s.repo.SavePreferences(ctx, userID, changes); err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "notification_preferences_updated", userID, "ok", describe(changes))
+	return s.Preferences(ctx, userID)
+}
+
+// Subscribe 建立实时订阅；lastEventID 大于 0 时同时返回其后错过的通知（最多 100 条）。
+// 先订阅再查询补发，调用方按 ID 去重即可保证不丢不重。
+func (s *Service) Subscribe(ctx context.Context, userID, lastEventID int64) ([]Notification, <-chan Notification, func(), error) {
+	ctx, span := tracing.Start(ctx, "notifications.Service.Subscribe")
+	defer span.End()
+	if userID <= 0 || lastEventID < 0 {
+		return nil, nil, nil, ErrInvalidInput
+	}
+	if s.hub == nil {
+		return nil, nil, nil, errors.New("实时推送未启用")
+	}
+	ch, cancel := s.hub.Subscribe(userID)
+	if lastEventID == 0 {
+		return nil, ch, cancel, nil
+	}
+	backlog, err := s.repo.Since(ctx, userID, lastEventID, maxReplay)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	return backlog, ch, cancel, nil
+}
+
+// Supports 判断事件类型是否生成站内通知。
+func Supports(t string) bool {
+	for _, known := range eventbus.Types() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max-1]) + "…"
+}
+
+func describe(changes map[string]bool) string {
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%t", k, changes[k]))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,185 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-study2/internal/infrastructure/eventbus"
+)
+
+type mockRepo struct {
+	items []Notification
+	prefs map[int64]map[string]bool
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{prefs: make(map[int64]map[string]bool)}
+}
+
+func (m *mockRepo) Create(_ context.Context, n *Notification) (int64, error) {
+	stored := *n
+	stored.ID = int64(len(m.items) + 1)
+	m.items = append(m.items, stored)
+	return stored.ID, nil
+}
+
+func (m *mockRepo) List(_ context.Context, userID int64, unreadOnly bool, beforeID int64, limit int) ([]Notification, error) {
+	var list []Notification
+	for i := len(m.items) - 1; i >= 0 && len(list) < limit; i-- {
+		n := m.items[i]
+		if n.UserID == userID && (!unreadOnly || !n.Read) && (beforeID == 0 || n.ID < beforeID) {
+			list = append(list, n)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockRepo) Since(_ context.Context, userID, afterID int64, limit int) ([]Notification, error) {
+	var list []Notification
+	for _, n := range m.items {
+		if n.UserID == userID && n.ID > afterID && len(list) < limit {
+			list = append(list, n)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockRepo) CountUnread(_ context.Context, userID int64) (int, error) {
+	count := 0
+	for _, n := range m.items {
+		if n.UserID == userID && !n.Read {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockRepo) MarkRead(_ context.Context, userID int64, ids []int64) (int, error) {
+	updated := 0
+	for i := range m.items {
+		n := &m.items[i]
+		if n.UserID != userID || n.Read {
+			continue
+		}
+		match := len(ids) == 0
+		for _, id := range ids {
+			match = match || id == n.ID
+		}
+		if match {
+			n.Read = true
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (m *mockRepo) Preferences(_ context.Context, userID int64) (map[string]bool, error) {
+	return m.prefs[userID], nil
+}
+
+func (m *mockRepo) SavePreferences(_ context.Context, userID int64, prefs map[string]bool) error {
+	if m.prefs[userID] == nil {
+		m.prefs[userID] = make(map[string]bool)
+	}
+	for k, v := range prefs {
+		m.prefs[userID][k] = v
+	}
+	return nil
+}
+
+func TestService_DeliverRespectsPreferencesAndPushes(t *testing.T) {
+	repo := newMockRepo()
+	hub := NewHub()
+	svc := NewService(repo, hub)
+	ctx := context.Background()
+
+	if _, err := svc.UpdatePreferences(ctx, 3, []Preference{{Type: eventbus.TypeAssignment, Enabled: false}}); err != nil {
+		t.Fatalf("更新偏好失败: %v", err)
+	}
+	live, cancel := hub.Subscribe(2)
+	defer cancel()
+
+	err := svc.Deliver(ctx, eventbus.Event{
+		Type:    eventbus.TypeAssignment,
+		UserIDs: []int64{1, 2, 2, 3},
+		ActorID: 1,
+		Title:   "新作业：切片",
+		Link:    "/classes/1",
+	})
+	if err != nil {
+		t.Fatalf("投递失败: %v", err)
+	}
+	if len(repo.items) != 1 || repo.items[0].UserID != 2 {
+		t.Fatalf("只有未关闭该类型的非触发者应收到一次通知: %+v", repo.items)
+	}
+	select {
+	case n := <-live:
+		if n.ID != repo.items[0].ID || n.Title != "新作业：切片" {
+			t.Fatalf("实时推送内容不正确: %+v", n)
+		}
+	default:
+		t.Fatalf("在线用户应收到实时推送")
+	}
+
+	if err := svc.Deliver(ctx, eventbus.Event{Type: "unknown", UserIDs: []int64{2}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("未知类型应返回 ErrInvalidInput, got %v", err)
+	}
+	if _, err := svc.UpdatePreferences(ctx, 3, []Preference{{Type: "unknown"}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("未知偏好类型应返回 ErrInvalidInput, got %v", err)
+	}
+	prefs, _ := svc.Preferences(ctx, 3)
+	if len(prefs) != len(eventbus.Types()) || prefs[0].Type != eventbus.TypeAssignment || prefs[0].Enabled || !prefs[1].Enabled {
+		t.Fatalf("偏好应列出全部类型且默认开启: %+v", prefs)
+	}
+}
+
+func TestService_ListMarkReadAndResume(t *testing.T) {
+	repo := newMockRepo()
+	svc := NewService(repo, NewHub())
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := svc.Deliver(ctx, eventbus.Event{Type: eventbus.TypeMention, UserIDs: []int64{5}, Title: "有人提到了你"}); err != nil {
+			t.Fatalf("投递失败: %v", err)
+		}
+	}
+	inbox, err := svc.List(ctx, 5, false, 0, 0)
+	if err != nil || len(inbox.Items) != 3 || inbox.Unread != 3 || inbox.Items[0].ID != 3 {
+		t.Fatalf("收件箱应按 ID 倒序: %+v %v", inbox, err)
+	}
+	if updated, _ := svc.MarkRead(ctx, 5, []int64{1}); updated != 1 {
+		t.Fatalf("应标记 1 条已读, got %d", updated)
+	}
+	if inbox, _ := svc.List(ctx, 5, true, 0, 0); len(inbox.Items) != 2 || inbox.Unread != 2 {
+		t.Fatalf("未读过滤不正确: %+v", inbox)
+	}
+
+	backlog, _, cancel, err := svc.Subscribe(ctx, 5, 1)
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	defer cancel()
+	if len(backlog) != 2 || backlog[0].ID != 2 || backlog[1].ID != 3 {
+		t.Fatalf("续传应按 ID 正序补发 Last-Event-ID 之后的通知: %+v", backlog)
+	}
+}
+
+func TestHub_SlowSubscriberIsClosed(t *testing.T) {
+	hub := NewHub()
+	ch, cancel := hub.Subscribe(7)
+	for i := 0; i <= subscriberBuffer; i++ {
+		hub.Publish(Notification{ID: int64(i + 1), UserID: 7})
+	}
+	if hub.Online(7) != 0 {
+		t.Fatalf("缓冲写满后订阅应被移除")
+	}
+	count := 0
+	for range ch {
+		count++
+	}
+	if count != subscriberBuffer {
+		t.Fatalf("关闭前应保留已缓冲的 %d 条, got %d", subscriberBuffer, count)
+	}
+	cancel()
+}
@@ -0,0 +1,39 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-study2/internal/domain/jobs"
+)
+
+const (
+	// ReviewReminderJobName 为复习提醒任务的名称。
+	ReviewReminderJobName = "review_reminders"
+	// DefaultReviewReminderSchedule 默认每天 9 点执行。
+	DefaultReviewReminderSchedule = "0 9 * * *"
+	// reviewReminderWindow 与每日执行的计划对应，保证每个章节只提醒一次。
+	reviewReminderWindow = 24 * time.Hour
+)
+
+// ReviewReminderJob 返回每日复习提醒任务：提醒最近访问恰好超过 idle 一天内、仍在学习中的章节，
+// service 在每次执行时获取。
+func ReviewReminderJob(schedule string, idle time.Duration, service func() (*Service, error)) jobs.Definition {
+	return jobs.Definition{
+		Name:        ReviewReminderJobName,
+		Description: fmt.Sprintf("提醒用户复习 %d 天未访问的学习中章节", int(idle/(24*time.Hour))),
+		Schedule:    schedule,
+		Run: func(ctx context.Context) (string, error) {
+			svc, err := service()
+			if err != nil {
+				return "", err
+			}
+			reminded, err := svc.RemindReview(ctx, time.Now(), idle, reviewReminderWindow)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("reminded_users=%d", reminded), nil
+		},
+	}
+}
@@ -1,10 +1,15 @@
 package progress
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository 定义学习进度的持久化操作。
 type Repository interface {
 	Upsert(ctx context.Context, record *Progress) error
 	ListByUser(ctx context.Context, userID int64) ([]Progress, error)
 	ListByTopic(ctx context.Context, userID int64, topic string) ([]Progress, error)
+	// ListIdle 跨用户返回指定状态且最近访问时间落在 [from, to) 内的进度。
+	ListIdle(ctx context.Context, status string, from, to time.Time) ([]Progress, error)
 }
@@ -3,8 +3,13 @@ package progress
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
+
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/infrastructure/tracing"
 )
 
 // ErrInvalidInput 表示请求参数不合法。
@@ -13,6 +18,8 @@ var ErrInvalidInput = errors.New("进度参数不合法")
 // Service 封装学习进度的业务逻辑。
 type Service struct {
 	repo Repository
+	// events 为 nil 时不发布领域事件。
+	events eventbus.Publisher
 }
 
 // NewService 创建学习进度服务。
@@ -20,8 +27,16 @@ func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
+// WithEvents 设置领域事件发布者，用于对外通知章节完成。
+func (s *Service) WithEvents(pub eventbus.Publisher) *Service {
+	s.events = pub
+	return s
+}
+
 // Save 记录或更新用户进度。
 func (s *Service) Save(ctx context.Context, userID int64, topic, chapter, status, position string) (*Progress, error) {
+	ctx, span := tracing.Start(ctx, "progress.Service.Save")
+	defer span.End()
 	topic = strings.TrimSpace(topic)
 	chapter = strings.TrimSpace(chapter)
 	status = strings.TrimSpace(status)
@@ -40,14 +55,100 @@ func (s *Service) Save(ctx context.Context, userID int64, topic, chapter, status
 		LastPosition: position,
 	}
 
+	// 仅在章节首次变为完成时发布事件，重复保存完成状态不再通知
+	completed := false
+	if s.events != nil && status == StatusDone {
+		done, err := s.isDone(ctx, userID, topic, chapter)
+		if err != nil {
+			return nil, err
+		}
+		completed = !done
+	}
+
 	if err := s.repo.Upsert(ctx, record); err != nil {
 		return nil, err
 	}
+	if completed {
+		s.events.Publish(ctx, eventbus.Event{
+			Type:    eventbus.TypeProgressDone,
+			ActorID: userID,
+			Data: map[string]interface{}{
+				"userId":  userID,
+				"topic":   topic,
+				"chapter": chapter,
+			},
+		})
+	}
 	return record, nil
 }
 
+func (s *Service) isDone(ctx context.Context, userID int64, topic, chapter string) (bool, error) {
+	items, err := s.repo.ListByTopic(ctx, userID, topic)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		if item.Chapter == chapter && item.Status == StatusDone {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// maxReminderChapters 为单条复习提醒正文中列出的章节上限。
+const maxReminderChapters = 5
+
+// RemindReview 为最近访问时间落在 [now-idle-window, now-idle) 的学习中章节发布复习提醒，
+// 每个用户一条事件，返回提醒的用户数；按 window 周期执行时每个章节只会提醒一次。
+func (s *Service) RemindReview(ctx context.Context, now time.Time, idle, window time.Duration) (int, error) {
+	ctx, span := tracing.Start(ctx, "progress.Service.RemindReview")
+	defer span.End()
+	if idle <= 0 || window <= 0 {
+		return 0, ErrInvalidInput
+	}
+	if s.events == nil {
+		return 0, nil
+	}
+	to := now.Add(-idle)
+	items, err := s.repo.ListIdle(ctx, StatusInProgress, to.Add(-window), to)
+	if err != nil {
+		return 0, err
+	}
+	byUser := make(map[int64][]Progress)
+	var users []int64
+	for _, item := range items {
+		if _, ok := byUser[item.UserID]; !ok {
+			users = append(users, item.UserID)
+		}
+		byUser[item.UserID] = append(byUser[item.UserID], item)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i] < users[j] })
+	for _, userID := range users {
+		chapters := byUser[userID]
+		names := make([]string, 0, len(chapters))
+		for _, c := range chapters {
+			names = append(names, c.Topic+"/"+c.Chapter)
+		}
+		body := strings.Join(names, "、")
+		if len(names) > maxReminderChapters {
+			body = strings.Join(names[:maxReminderChapters], "、") + " 等"
+		}
+		s.events.Publish(ctx, eventbus.Event{
+			Type:    eventbus.TypeReviewDue,
+			UserIDs: []int64{userID},
+			Title:   fmt.Sprintf("有 %d 个章节等待复习", len(chapters)),
+			Body:    body,
+			Link:    fmt.Sprintf("/topics/%s/%s", chapters[0].Topic, chapters[0].Chapter),
+			Data:    map[string]interface{}{"chapters": names},
+		})
+	}
+	return len(users), nil
+}
+
 // ListAll 返回用户的全部进度。
 func (s *Service) ListAll(ctx context.Context, userID int64) ([]Progress, error) {
+	ctx, span := tracing.Start(ctx, "progress.Service.ListAll")
+	defer span.End()
 	if userID <= 0 {
 		return nil, ErrInvalidInput
 	}
@@ -56,6 +157,8 @@ func (s *Service) ListAll(ctx context.Context, userID int64) ([]Progress, error)
 
 // ListByTopic 返回用户在指定主题下的进度。
 func (s *Service) ListByTopic(ctx context.Context, userID int64, topic string) ([]Progress, error) {
+	ctx, span := tracing.Start(ctx, "progress.Service.ListByTopic")
+	defer span.End()
 	topic = strings.TrimSpace(topic)
 	if userID <= 0 || !IsSupportedTopic(topic) {
 		return nil, ErrInvalidInput
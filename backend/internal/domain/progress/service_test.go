@@ -3,8 +3,11 @@ package progress
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
+
+	"go-study2/internal/infrastructure/eventbus"
 )
 
 type mockRepo struct {
@@ -49,6 +52,16 @@ func (m *mockRepo) ListByTopic(_ context.Context, userID int64, topic string) ([
 	return result, nil
 }
 
+func (m *mockRepo) ListIdle(_ context.Context, status string, from, to time.Time) ([]Progress, error) {
+	var result []Progress
+	for _, item := range m.items {
+		if item.Status == status && !item.LastVisit.Before(from) && item.LastVisit.Before(to) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
 func TestService_SaveAndList(t *testing.T) {
 	repo := &mockRepo{}
 	svc := NewService(repo)
@@ -83,3 +96,62 @@ func TestService_SaveAndList(t *testing.T) {
 		t.Fatalf("按主题查询进度数量不正确")
 	}
 }
+
+type recordingPublisher struct {
+	events []eventbus.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event eventbus.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestService_SavePublishesFirstCompletion(t *testing.T) {
+	pub := &recordingPublisher{}
+	svc := NewService(&mockRepo{}).WithEvents(pub)
+	ctx := context.Background()
+
+	if _, err := svc.Save(ctx, 1, "variables", "storage", StatusInProgress, ""); err != nil {
+		t.Fatalf("保存进度失败: %v", err)
+	}
+	if len(pub.events) != 0 {
+		t.Fatalf("未完成的章节不应发布事件: %+v", pub.events)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Save(ctx, 1, "variables", "storage", StatusDone, ""); err != nil {
+			t.Fatalf("保存进度失败: %v", err)
+		}
+	}
+	if len(pub.events) != 1 {
+		t.Fatalf("重复完成同一章节只应发布一次事件, got %d", len(pub.events))
+	}
+	ev := pub.events[0]
+	if ev.Type != eventbus.TypeProgressDone || ev.ActorID != 1 || ev.Data["chapter"] != "storage" {
+		t.Fatalf("完成事件内容不正确: %+v", ev)
+	}
+}
+
+func TestService_RemindReview(t *testing.T) {
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	repo := &mockRepo{items: []Progress{
+		{UserID: 1, Topic: "variables", Chapter: "storage", Status: StatusInProgress, LastVisit: now.Add(-80 * time.Hour)},
+		{UserID: 1, Topic: "constants", Chapter: "iota", Status: StatusInProgress, LastVisit: now.Add(-90 * time.Hour)},
+		{UserID: 2, Topic: "types", Chapter: "struct", Status: StatusDone, LastVisit: now.Add(-80 * time.Hour)},
+		{UserID: 3, Topic: "types", Chapter: "map", Status: StatusInProgress, LastVisit: now.Add(-2 * time.Hour)},
+		{UserID: 4, Topic: "types", Chapter: "slice", Status: StatusInProgress, LastVisit: now.Add(-120 * time.Hour)},
+	}}
+	pub := &recordingPublisher{}
+	svc := NewService(repo).WithEvents(pub)
+
+	reminded, err := svc.RemindReview(context.Background(), now, 72*time.Hour, 24*time.Hour)
+	if err != nil || reminded != 1 {
+		t.Fatalf("应只提醒 1 位用户: %d %v", reminded, err)
+	}
+	ev := pub.events[0]
+	if ev.Type != eventbus.TypeReviewDue || len(ev.UserIDs) != 1 || ev.UserIDs[0] != 1 ||
+		ev.Title != "有 2 个章节等待复习" || !strings.Contains(ev.Body, "constants/iota") {
+		t.Fatalf("复习提醒内容不正确: %+v", ev)
+	}
+	if _, err := svc.RemindReview(context.Background(), now, 0, time.Hour); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("非法时间窗口应返回 ErrInvalidInput")
+	}
+}
@@ -0,0 +1,450 @@
+package quiz
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/tracing"
+	"go-study2/src/learning/types"
+	"go-study2/src/learning/variables"
+)
+
+// 题库题目状态，只有已发布的题目会出现在测验中。
+const (
+	BankStatusDraft     = "draft"
+	BankStatusPublished = "published"
+)
+
+// 题目难度。
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+const (
+	maxBankOptions   = 8
+	maxBankTags      = 10
+	maxBankTagLength = 32
+	maxBankStemRunes = 2000
+)
+
+// ErrQuestionNotFound 表示题库题目不存在。
+var ErrQuestionNotFound = errors.New("题目不存在")
+
+var bankQuestionIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// BankQuestion 表示题库中由教师编写的题目。
+type BankQuestion struct {
+	ID int64 `json:"id"`
+	// QuestionID 为测验中使用的题目 ID，创建后不可修改，不能与内置题目重复。
+	QuestionID string   `json:"questionId"`
+	Topic      string   `json:"topic"`
+	Chapter    string   `json:"chapter"`
+	Stem       string   `json:"stem"`
+	Options    []string `json:"options"`
+	// Answer 为正确选项字母，多选题按字母顺序拼接，如 "AC"。
+	Answer      string    `json:"answer"`
+	Explanation string    `json:"explanation"`
+	Tags        []string  `json:"tags"`
+	Difficulty  string    `json:"difficulty"`
+	RuleRef     string    `json:"ruleRef"`
+	Status      string    `json:"status"`
+	Revision    int       `json:"revision"`
+	CreatedBy   int64     `json:"createdBy"`
+	UpdatedBy   int64     `json:"updatedBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// BankQuestionInput 为创建或修改题目的参数。
+type BankQuestionInput struct {
+	QuestionID  string
+	Topic       string
+	Chapter     string
+	Stem        string
+	Options     []string
+	Answer      string
+	Explanation string
+	Tags        []string
+	Difficulty  string
+	RuleRef     string
+	Status      string
+}
+
+// BankRevision 为题目某次保存后的完整快照。
+type BankRevision struct {
+	Revision  int          `json:"revision"`
+	EditorID  int64        `json:"editorId"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Question  BankQuestion `json:"question"`
+}
+
+// BankFilter 为题库列表的过滤条件，空字段表示不过滤。
+type BankFilter struct {
+	Topic   string
+	Chapter string
+	Status  string
+	Tag     string
+}
+
+// ValidationProblem 描述题目的一处校验问题。
+type ValidationProblem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError 汇总题目的全部校验问题。
+type ValidationError struct {
+	Problems []ValidationProblem
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		messages = append(messages, p.Field+": "+p.Message)
+	}
+	return strings.Join(messages, "；")
+}
+
+// Unwrap 使 errors.Is(err, ErrInvalidInput) 成立。
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// BankRepository 定义题库的持久化接口。
+type BankRepository interface {
+	// CreateQuestion 保存题目并写入第 1 版修订记录。
+	CreateQuestion(ctx context.Context, question *BankQuestion) (int64, error)
+	// UpdateQuestion 覆盖题目内容并写入新的修订记录，Revision 由调用方递增。
+	UpdateQuestion(ctx context.Context, question *BankQuestion) error
+	FindQuestion(ctx context.Context, id int64) (*BankQuestion, error)
+	FindQuestionByQuestionID(ctx context.Context, questionID string) (*BankQuestion, error)
+	ListQuestions(ctx context.Context, filter BankFilter) ([]BankQuestion, error)
+	DeleteQuestion(ctx context.Context, id int64) error
+	// ListRevisions 按版本号倒序返回修订历史。
+	ListRevisions(ctx context.Context, id int64) ([]BankRevision, error)
+}
+
+// WithBank 启用题库，已发布的题目会与内置题目合并。
+func (s *Service) WithBank(repo BankRepository) *Service {
+	s.bank = repo
+	return s
+}
+
+// ListBankQuestions 按条件列出题库题目。
+func (s *Service) ListBankQuestions(ctx context.Context, filter BankFilter) ([]BankQuestion, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.ListBankQuestions")
+	defer span.End()
+	if s.bank == nil {
+		return []BankQuestion{}, nil
+	}
+	filter.Topic = strings.TrimSpace(filter.Topic)
+	filter.Chapter = normalizeChapter(filter.Chapter)
+	filter.Status = strings.TrimSpace(filter.Status)
+	filter.Tag = strings.ToLower(strings.TrimSpace(filter.Tag))
+	if filter.Status != "" && filter.Status != BankStatusDraft && filter.Status != BankStatusPublished {
+		return nil, ErrInvalidInput
+	}
+	return s.bank.ListQuestions(ctx, filter)
+}
+
+// GetBankQuestion 返回单个题库题目。
+func (s *Service) GetBankQuestion(ctx context.Context, id int64) (*BankQuestion, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.GetBankQuestion")
+	defer span.End()
+	return s.findBankQuestion(ctx, id)
+}
+
+// CreateBankQuestion 校验并保存新题目，未指定状态时为草稿。
+func (s *Service) CreateBankQuestion(ctx context.Context, editorID int64, input BankQuestionInput) (*BankQuestion, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.CreateBankQuestion")
+	defer span.End()
+	if s.bank == nil {
+		return nil, ErrQuestionNotFound
+	}
+	question := &BankQuestion{
+		QuestionID: strings.ToLower(strings.TrimSpace(input.QuestionID)),
+		CreatedBy:  editorID,
+		UpdatedBy:  editorID,
+		Revision:   1,
+	}
+	if question.QuestionID == "" {
+		generated, err := generateBankQuestionID()
+		if err != nil {
+			return nil, err
+		}
+		question.QuestionID = generated
+	}
+	if err := s.applyBankInput(ctx, question, input); err != nil {
+		return nil, err
+	}
+	id, err := s.bank.CreateQuestion(ctx, question)
+	if err != nil {
+		return nil, err
+	}
+	question.ID = id
+	audit.Record(ctx, "question_created", editorID, "ok", fmt.Sprintf("question_id=%s status=%s", question.QuestionID, question.Status))
+	return s.findBankQuestion(ctx, id)
+}
+
+// UpdateBankQuestion 以完整内容覆盖题目并生成新修订；题目 ID 不可修改。
+func (s *Service) UpdateBankQuestion(ctx context.Context, editorID, id int64, input BankQuestionInput) (*BankQuestion, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.UpdateBankQuestion")
+	defer span.End()
+	question, err := s.findBankQuestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if requested := strings.ToLower(strings.TrimSpace(input.QuestionID)); requested != "" && requested != question.QuestionID {
+		return nil, &ValidationError{Problems: []ValidationProblem{{Field: "questionId", Message: "题目 ID 创建后不可修改"}}}
+	}
+	previous := question.Status
+	if err := s.applyBankInput(ctx, question, input); err != nil {
+		return nil, err
+	}
+	question.Revision++
+	question.UpdatedBy = editorID
+	if err := s.bank.UpdateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	event := "question_updated"
+	if previous != BankStatusPublished && question.Status == BankStatusPublished {
+		event = "question_published"
+	}
+	audit.Record(ctx, event, editorID, "ok", fmt.Sprintf("question_id=%s revision=%d", question.QuestionID, question.Revision))
+	return s.findBankQuestion(ctx, id)
+}
+
+// DeleteBankQuestion 删除题目及其修订历史。
+func (s *Service) DeleteBankQuestion(ctx context.Context, editorID, id int64) error {
+	ctx, span := tracing.Start(ctx, "quiz.Service.DeleteBankQuestion")
+	defer span.End()
+	question, err := s.findBankQuestion(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.bank.DeleteQuestion(ctx, id); err != nil {
+		return err
+	}
+	audit.Record(ctx, "question_deleted", editorID, "ok", "question_id="+question.QuestionID)
+	return nil
+}
+
+// BankRevisions 返回题目的修订历史。
+func (s *Service) BankRevisions(ctx context.Context, id int64) ([]BankRevision, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.BankRevisions")
+	defer span.End()
+	if _, err := s.findBankQuestion(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.bank.ListRevisions(ctx, id)
+}
+
+func (s *Service) findBankQuestion(ctx context.Context, id int64) (*BankQuestion, error) {
+	if s.bank == nil || id <= 0 {
+		return nil, ErrQuestionNotFound
+	}
+	question, err := s.bank.FindQuestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if question == nil {
+		return nil, ErrQuestionNotFound
+	}
+	return question, nil
+}
+
+// applyBankInput 规范化并校验输入，一次性返回全部问题。
+func (s *Service) applyBankInput(ctx context.Context, question *BankQuestion, input BankQuestionInput) error {
+	var problems []ValidationProblem
+	add := func(field, message string) {
+		problems = append(problems, ValidationProblem{Field: field, Message: message})
+	}
+
+	question.Topic = strings.TrimSpace(input.Topic)
+	question.Chapter = normalizeChapter(input.Chapter)
+	question.Stem = strings.TrimSpace(input.Stem)
+	question.Explanation = strings.TrimSpace(input.Explanation)
+	question.RuleRef = strings.TrimSpace(input.RuleRef)
+	question.Difficulty = strings.ToLower(strings.TrimSpace(input.Difficulty))
+	question.Status = strings.TrimSpace(input.Status)
+	if question.Difficulty == "" {
+		question.Difficulty = DifficultyMedium
+	}
+	if question.Status == "" {
+		question.Status = BankStatusDraft
+	}
+
+	if !bankQuestionIDPattern.MatchString(question.QuestionID) {
+		add("questionId", "题目 ID 只能包含小写字母、数字、下划线与连字符，长度不超过 64")
+	} else if question.ID == 0 {
+		if _, ok := builtinQuestionIDs()[question.QuestionID]; ok {
+			add("questionId", "题目 ID 与内置题目重复")
+		} else if existing, err := s.bank.FindQuestionByQuestionID(ctx, question.QuestionID); err != nil {
+			return err
+		} else if existing != nil {
+			add("questionId", "题目 ID 已存在")
+		}
+	}
+	if !IsSupportedTopic(question.Topic) {
+		add("topic", "主题不受支持")
+	}
+	if question.Chapter == "" {
+		add("chapter", "章节不能为空")
+	} else if !IsSupportedChapter(question.Topic, question.Chapter) {
+		add("chapter", "章节不属于该主题")
+	}
+	if question.Stem == "" || utf8.RuneCountInString(question.Stem) > maxBankStemRunes {
+		add("stem", fmt.Sprintf("题干不能为空且不超过 %d 字", maxBankStemRunes))
+	}
+
+	question.Options = make([]string, 0, len(input.Options))
+	for _, opt := range input.Options {
+		if opt = strings.TrimSpace(opt); opt != "" {
+			question.Options = append(question.Options, opt)
+		}
+	}
+	if len(question.Options) < 2 || len(question.Options) > maxBankOptions {
+		add("options", fmt.Sprintf("选项数量需在 2 到 %d 之间", maxBankOptions))
+	}
+	answer, ok := normalizeAnswer(input.Answer, len(question.Options))
+	if !ok {
+		add("answer", "答案须为选项范围内的字母且不能重复")
+	}
+	question.Answer = answer
+
+	question.Tags = normalizeTags(input.Tags)
+	if len(question.Tags) > maxBankTags {
+		add("tags", fmt.Sprintf("标签不超过 %d 个", maxBankTags))
+	}
+	for _, tag := range question.Tags {
+		if utf8.RuneCountInString(tag) > maxBankTagLength || strings.Contains(tag, ",") {
+			add("tags", fmt.Sprintf("标签不能包含逗号且不超过 %d 字", maxBankTagLength))
+			break
+		}
+	}
+	switch question.Difficulty {
+	case DifficultyEasy, DifficultyMedium, DifficultyHard:
+	default:
+		add("difficulty", "难度仅支持 easy、medium、hard")
+	}
+	switch question.Status {
+	case BankStatusDraft, BankStatusPublished:
+	default:
+		add("status", "状态仅支持 draft、published")
+	}
+	if question.RuleRef != "" {
+		if _, ok := types.FindRule(question.RuleRef); !ok {
+			add("ruleRef", "引用的规则不存在")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// publishedBankQuestions 返回章节下已发布的题库题目，跳过与内置题目 ID 冲突的条目。
+func (s *Service) publishedBankQuestions(ctx context.Context, topic, chapter string, builtin []Question) ([]Question, error) {
+	if s.bank == nil {
+		return nil, nil
+	}
+	items, err := s.bank.ListQuestions(ctx, BankFilter{Topic: topic, Chapter: normalizeChapter(chapter), Status: BankStatusPublished})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(builtin))
+	for _, q := range builtin {
+		seen[q.ID] = struct{}{}
+	}
+	list := make([]Question, 0, len(items))
+	for _, item := range items {
+		if _, dup := seen[item.QuestionID]; dup {
+			continue
+		}
+		seen[item.QuestionID] = struct{}{}
+		q := toQuestion(item.QuestionID, item.Stem, item.Options, item.Answer, item.Explanation)
+		q.RuleRef = item.RuleRef
+		list = append(list, q)
+	}
+	return list, nil
+}
+
+func normalizeChapter(chapter string) string {
+	return strings.ToLower(strings.TrimSpace(chapter))
+}
+
+// normalizeAnswer 把答案规范为按字母排序的大写选项，校验范围与重复。
+func normalizeAnswer(raw string, optionCount int) (string, bool) {
+	letters := normalizeChoices(splitAnswer(strings.ReplaceAll(raw, ",", "")))
+	if len(letters) == 0 {
+		return "", false
+	}
+	for i, letter := range letters {
+		if len(letter) != 1 || letter[0] < 'A' || int(letter[0]-'A') >= optionCount {
+			return "", false
+		}
+		if i > 0 && letters[i-1] == letter {
+			return "", false
+		}
+	}
+	return strings.Join(letters, ""), true
+}
+
+func normalizeTags(tags []string) []string {
+	list := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		list = append(list, tag)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// builtinQuestionIDs 汇总全部内置题目的 ID。
+func builtinQuestionIDs() map[string]struct{} {
+	ids := make(map[string]struct{})
+	for _, chapter := range variables.AllTopics() {
+		items, _ := variables.LoadQuiz(chapter)
+		for _, item := range items {
+			ids[strings.ToLower(item.ID)] = struct{}{}
+		}
+	}
+	for _, chapter := range types.AllTopics() {
+		items, _ := types.LoadQuiz(chapter)
+		for _, item := range items {
+			ids[strings.ToLower(item.ID)] = struct{}{}
+		}
+	}
+	for _, item := range types.LoadComprehensiveQuiz() {
+		ids[strings.ToLower(item.ID)] = struct{}{}
+	}
+	return ids
+}
+
+func generateBankQuestionID() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "bank-" + hex.EncodeToString(buf), nil
+}
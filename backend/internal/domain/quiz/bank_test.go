@@ -0,0 +1,307 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockBankRepo struct {
+	questions map[int64]*BankQuestion
+	revisions map[int64][]BankRevision
+	autoID    int64
+}
+
+func newMockBankRepo() *mockBankRepo {
+	return &mockBankRepo{
+		questions: make(map[int64]*BankQuestion),
+		revisions: make(map[int64][]BankRevision),
+		autoID:    1,
+	}
+}
+
+func (m *mockBankRepo) CreateQuestion(_ context.Context, question *BankQuestion) (int64, error) {
+	id := m.autoID
+	m.autoID++
+	clone := *question
+	clone.ID = id
+	m.questions[id] = &clone
+	m.revisions[id] = []BankRevision{{Revision: clone.Revision, EditorID: clone.UpdatedBy, Question: clone}}
+	return id, nil
+}
+
+func (m *mockBankRepo) UpdateQuestion(_ context.Context, question *BankQuestion) error {
+	clone := *question
+	m.questions[question.ID] = &clone
+	m.revisions[question.ID] = append([]BankRevision{{Revision: clone.Revision, EditorID: clone.UpdatedBy, Question: clone}}, m.revisions[question.ID]...)
+	return nil
+}
+
+func (m *mockBankRepo) FindQuestion(_ context.Context, id int64) (*BankQuestion, error) {
+	if q, ok := m.questions[id]; ok {
+		clone := *q
+		return &clone, nil
+	}
+	return nil, nil
+}
+
+func (m *mockBankRepo) FindQuestionByQuestionID(_ context.Context, questionID string) (*BankQuestion, error) {
+	for _, q := range m.questions {
+		if q.QuestionID == questionID {
+			clone := *q
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockBankRepo) ListQuestions(_ context.Context, filter BankFilter) ([]BankQuestion, error) {
+	var list []BankQuestion
+	for id := int64(1); id < m.autoID; id++ {
+		q, ok := m.questions[id]
+		if !ok {
+			continue
+		}
+		if (filter.Topic == "" || q.Topic == filter.Topic) && (filter.Chapter == "" || q.Chapter == filter.Chapter) &&
+			(filter.Status == "" || q.Status == filter.Status) {
+			list = append(list, *q)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockBankRepo) DeleteQuestion(_ context.Context, id int64) error {
+	delete(m.questions, id)
+	delete(m.revisions, id)
+	return nil
+}
+
+func (m *mockBankRepo) ListRevisions(_ context.Context, id int64) ([]BankRevision, error) {
+	return m.revisions[id], nil
+}
+
+func problemFields(err error) map[string]bool {
+	fields := map[string]bool{}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		for _, p := range validationErr.Problems {
+			fields[p.Field] = true
+		}
+	}
+	return fields
+}
+
+func TestService_BankQuestionValidation(t *testing.T) {
+	svc := NewService(&mockQuizRepo{}).WithBank(newMockBankRepo())
+	ctx := context.Background()
+
+	_, err := svc.CreateBankQuestion(ctx, 1, BankQuestionInput{
+		QuestionID: "Bad ID!",
+		Topic:      "types",
+		Chapter:    "no_such_chapter",
+		Options:    []string{"唯一选项", " "},
+		Answer:     "C",
+		Difficulty: "extreme",
+		RuleRef:    "TR-UNKNOWN",
+		Status:     "archived",
+	})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("非法题目应返回校验错误，得到: %v", err)
+	}
+	fields := problemFields(err)
+	for _, f := range []string{"questionId", "chapter", "stem", "options", "answer", "difficulty", "ruleRef", "status"} {
+		if !fields[f] {
+			t.Fatalf("缺少字段 %s 的校验问题: %v", f, err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		answer string
+		ok     bool
+	}{
+		{"单选", "b", true},
+		{"多选乱序", "C,A", true},
+		{"重复字母", "AA", false},
+		{"超出范围", "D", false},
+		{"非字母", "1", false},
+	}
+	for _, tc := range cases {
+		if _, ok := normalizeAnswer(tc.answer, 3); ok != tc.ok {
+			t.Fatalf("%s: 答案 %q 期望 %v", tc.name, tc.answer, tc.ok)
+		}
+	}
+
+	builtin, _ := builtinQuestions("types", "interface_impl")
+	if len(builtin) == 0 {
+		t.Fatalf("内置题目不应为空")
+	}
+	_, err = svc.CreateBankQuestion(ctx, 1, BankQuestionInput{
+		QuestionID: builtin[0].ID, Topic: "types", Chapter: "interface_impl",
+		Stem: "题干", Options: []string{"甲", "乙"}, Answer: "A",
+	})
+	if !problemFields(err)["questionId"] {
+		t.Fatalf("与内置题目重复的 ID 应被拒绝，得到: %v", err)
+	}
+	_, err = svc.CreateBankQuestion(ctx, 1, BankQuestionInput{
+		QuestionID: "q-all-1", Topic: "types", Chapter: TypesComprehensiveChapter,
+		Stem: "题干", Options: []string{"甲", "乙"}, Answer: "A",
+	})
+	if !problemFields(err)["questionId"] {
+		t.Fatalf("与综合测验内置题目重复的 ID 应被拒绝，得到: %v", err)
+	}
+}
+
+func TestService_BankQuestionLifecycleAndMerge(t *testing.T) {
+	bank := newMockBankRepo()
+	svc := NewService(&mockQuizRepo{}).WithBank(bank)
+	ctx := context.Background()
+
+	builtin, err := svc.GetQuestions(ctx, "types", "interface_impl")
+	if err != nil {
+		t.Fatalf("获取内置题目失败: %v", err)
+	}
+
+	input := BankQuestionInput{
+		QuestionID: "iface-extra-1",
+		Topic:      "types",
+		Chapter:    " Interface_Impl ",
+		Stem:       "以下哪些类型实现了 io.Reader？",
+		Options:    []string{"*os.File", "int", "*bytes.Buffer"},
+		Answer:     "c,a",
+		Tags:       []string{"Interface", "io", "interface"},
+		RuleRef:    "TR-IFACE-IMPL",
+	}
+	created, err := svc.CreateBankQuestion(ctx, 7, input)
+	if err != nil {
+		t.Fatalf("创建题目失败: %v", err)
+	}
+	if created.Status != BankStatusDraft || created.Answer != "AC" || created.Chapter != "interface_impl" ||
+		created.Difficulty != DifficultyMedium || len(created.Tags) != 2 || created.Revision != 1 {
+		t.Fatalf("题目规范化结果不正确: %+v", created)
+	}
+	if _, err := svc.CreateBankQuestion(ctx, 7, input); !problemFields(err)["questionId"] {
+		t.Fatalf("重复的题目 ID 应被拒绝，得到: %v", err)
+	}
+
+	if draft, _ := svc.GetQuestions(ctx, "types", "interface_impl"); len(draft) != len(builtin) {
+		t.Fatalf("草稿不应出现在测验中")
+	}
+
+	input.Status = BankStatusPublished
+	published, err := svc.UpdateBankQuestion(ctx, 8, created.ID, input)
+	if err != nil {
+		t.Fatalf("发布题目失败: %v", err)
+	}
+	if published.Revision != 2 || published.UpdatedBy != 8 {
+		t.Fatalf("修订信息不正确: %+v", published)
+	}
+	input.QuestionID = "renamed"
+	if _, err := svc.UpdateBankQuestion(ctx, 8, created.ID, input); !problemFields(err)["questionId"] {
+		t.Fatalf("题目 ID 不应允许修改，得到: %v", err)
+	}
+
+	merged, err := svc.GetQuestions(ctx, "types", "interface_impl")
+	if err != nil || len(merged) != len(builtin)+1 {
+		t.Fatalf("已发布题目应与内置题目合并: %d, %v", len(merged), err)
+	}
+	last := merged[len(merged)-1]
+	if last.ID != "iface-extra-1" || !last.Multi || len(last.Options) != 3 {
+		t.Fatalf("合并后的题目不正确: %+v", last)
+	}
+
+	result, err := svc.Submit(ctx, 1, "types", "interface_impl", []SubmitAnswer{{ID: "iface-extra-1", Choices: []string{"A", "C"}}}, 0)
+	if err != nil || len(result.CorrectIDs) != 1 || result.CorrectIDs[0] != "iface-extra-1" {
+		t.Fatalf("题库题目应参与评分: %+v, %v", result, err)
+	}
+
+	revisions, _ := svc.BankRevisions(ctx, created.ID)
+	if len(revisions) != 2 || revisions[0].Revision != 2 {
+		t.Fatalf("修订历史不正确: %+v", revisions)
+	}
+
+	if err := svc.DeleteBankQuestion(ctx, 8, created.ID); err != nil {
+		t.Fatalf("删除题目失败: %v", err)
+	}
+	if _, err := svc.GetBankQuestion(ctx, created.ID); err != ErrQuestionNotFound {
+		t.Fatalf("删除后应返回 ErrQuestionNotFound，得到: %v", err)
+	}
+}
+
+func TestService_BankQuestionsForTopicWithoutBuiltins(t *testing.T) {
+	svc := NewService(&mockQuizRepo{}).WithBank(newMockBankRepo())
+	ctx := context.Background()
+
+	if _, err := svc.GetQuestions(ctx, "constants", "iota"); err != ErrQuizUnavailable {
+		t.Fatalf("无题目时应返回 ErrQuizUnavailable，得到: %v", err)
+	}
+	_, err := svc.CreateBankQuestion(ctx, 1, BankQuestionInput{
+		Topic: "constants", Chapter: "iota", Stem: "iota 从几开始？",
+		Options: []string{"0", "1"}, Answer: "A", Status: BankStatusPublished,
+	})
+	if err != nil {
+		t.Fatalf("创建题目失败: %v", err)
+	}
+	questions, err := svc.GetQuestions(ctx, "constants", "iota")
+	if err != nil || len(questions) != 1 {
+		t.Fatalf("仅有题库题目的章节应可测验: %+v, %v", questions, err)
+	}
+}
+
+func TestService_GradeUsesBankQuestions(t *testing.T) {
+	repo := &mockQuizRepo{}
+	svc := NewService(repo).WithBank(newMockBankRepo())
+	ctx := context.Background()
+
+	builtin, err := svc.GetQuestions(ctx, "types", TypesComprehensiveChapter)
+	if err != nil || len(builtin) == 0 {
+		t.Fatalf("综合测验应包含内置题目: %d, %v", len(builtin), err)
+	}
+	_, err = svc.CreateBankQuestion(ctx, 1, BankQuestionInput{
+		QuestionID: "all-extra-1", Topic: "types", Chapter: "Comprehensive", Stem: "nil map 可以写入吗？",
+		Options: []string{"可以", "会 panic"}, Answer: "B", RuleRef: "TR-MAP-KEY", Status: BankStatusPublished,
+	})
+	if err != nil {
+		t.Fatalf("综合测验章节应允许录入题目: %v", err)
+	}
+
+	questions, result, err := svc.Grade(ctx, "types", TypesComprehensiveChapter, []SubmitAnswer{{ID: "all-extra-1", Choices: []string{"b"}}})
+	if err != nil {
+		t.Fatalf("评分失败: %v", err)
+	}
+	if len(questions) != len(builtin)+1 || result.Total != len(builtin)+1 || result.Score != 1 || result.CorrectIDs[0] != "all-extra-1" {
+		t.Fatalf("题库题目应参与评分: %d, %+v", len(questions), result)
+	}
+	if questions[len(questions)-1].RuleRef != "TR-MAP-KEY" || questions[0].RuleRef == "" {
+		t.Fatalf("题目应携带规则引用: %+v", questions)
+	}
+	if len(repo.saved) != 0 {
+		t.Fatalf("练习评分不应保存记录")
+	}
+	if _, _, err := svc.Grade(ctx, "types", TypesComprehensiveChapter, nil); err != ErrInvalidInput {
+		t.Fatalf("未提交答案应返回 ErrInvalidInput，得到: %v", err)
+	}
+}
+
+func TestIsSupportedChapter(t *testing.T) {
+	cases := []struct {
+		topic, chapter string
+		want           bool
+	}{
+		{"variables", "storage", true},
+		{"types", "interface_impl", true},
+		{"types", TypesComprehensiveChapter, true},
+		{"constants", "iota", true},
+		{"lexical_elements", "comments", true},
+		{"types", "Interface_Impl", false},
+		{"types", "storage", false},
+		{"constants", "comments", false},
+		{"unknown", "comments", false},
+		{"variables", "", false},
+	}
+	for _, c := range cases {
+		if got := IsSupportedChapter(c.topic, c.chapter); got != c.want {
+			t.Fatalf("IsSupportedChapter(%q, %q) 期望 %v，得到 %v", c.topic, c.chapter, c.want, got)
+		}
+	}
+}
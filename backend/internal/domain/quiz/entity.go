@@ -1,6 +1,13 @@
 package quiz
 
-import "time"
+import (
+	"time"
+
+	"go-study2/internal/app/constants"
+	"go-study2/internal/app/lexical_elements"
+	"go-study2/src/learning/types"
+	"go-study2/src/learning/variables"
+)
 
 // Option 表示题目选项。
 type Option struct {
@@ -16,6 +23,7 @@ type Question struct {
 	Multi       bool     `json:"multi"`
 	Answer      []string `json:"answer"`
 	Explanation string   `json:"explanation,omitempty"`
+	RuleRef     string   `json:"ruleRef,omitempty"`
 }
 
 // SubmitAnswer 表示用户提交的答案。
@@ -70,3 +78,19 @@ func IsSupportedTopic(topic string) bool {
 	_, ok := supportedTopics[topic]
 	return ok
 }
+
+// IsSupportedChapter 判断 chapter 是否为 topic 下真实存在的章节，chapter 需为小写章节 ID。
+func IsSupportedChapter(topic, chapter string) bool {
+	switch topic {
+	case "variables":
+		return variables.IsSupportedTopic(variables.Topic(chapter))
+	case "types":
+		return chapter == TypesComprehensiveChapter || types.IsSupportedTopic(types.Topic(chapter))
+	case "lexical_elements":
+		return lexical_elements.IsSupportedChapter(chapter)
+	case "constants":
+		return constants.IsSupportedChapter(chapter)
+	default:
+		return false
+	}
+}
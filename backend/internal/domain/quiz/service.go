@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/infrastructure/tracing"
 	"go-study2/src/learning/types"
 	"go-study2/src/learning/variables"
 )
@@ -18,9 +20,15 @@ var ErrInvalidInput = errors.New("测验参数不合法")
 // ErrQuizUnavailable 表示当前主题暂无测验。
 var ErrQuizUnavailable = errors.New("当前主题暂无测验")
 
+// TypesComprehensiveChapter Types 综合测验对应的章节名，题目来自 types.LoadComprehensiveQuiz。
+const TypesComprehensiveChapter = "comprehensive"
+
 // Service 封装测验相关业务。
 type Service struct {
 	repo Repository
+	bank BankRepository
+	// events 为 nil 时不发布领域事件。
+	events eventbus.Publisher
 }
 
 // NewService 创建测验服务。
@@ -28,25 +36,73 @@ func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
+// WithEvents 设置领域事件发布者，用于对外通知测验提交。
+func (s *Service) WithEvents(pub eventbus.Publisher) *Service {
+	s.events = pub
+	return s
+}
+
 // GetQuestions 获取测验题目列表。
 func (s *Service) GetQuestions(ctx context.Context, topic, chapter string) ([]Question, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.GetQuestions")
+	defer span.End()
 	topic = strings.TrimSpace(topic)
 	chapter = strings.TrimSpace(chapter)
 	if !IsSupportedTopic(topic) || chapter == "" {
 		return nil, ErrInvalidInput
 	}
-	return loadQuestions(topic, chapter)
+	return s.loadQuestions(ctx, topic, chapter)
+}
+
+// HasQuestion 判断章节中是否存在指定题目，包括题库中已发布的题目。
+func (s *Service) HasQuestion(ctx context.Context, topic, chapter, questionID string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.HasQuestion")
+	defer span.End()
+	if !IsSupportedTopic(topic) || strings.TrimSpace(chapter) == "" {
+		return false, nil
+	}
+	questions, err := s.loadQuestions(ctx, topic, chapter)
+	if err != nil {
+		if errors.Is(err, ErrQuizUnavailable) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, q := range questions {
+		if q.ID == questionID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Grade 使用与 Submit 相同的题目集合评分，但不保存记录、不发布事件，供无需登录的练习接口使用。
+func (s *Service) Grade(ctx context.Context, topic, chapter string, answers []SubmitAnswer) ([]Question, *Result, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.Grade")
+	defer span.End()
+	topic = strings.TrimSpace(topic)
+	chapter = strings.TrimSpace(chapter)
+	if !IsSupportedTopic(topic) || chapter == "" || len(answers) == 0 {
+		return nil, nil, ErrInvalidInput
+	}
+	questions, err := s.loadQuestions(ctx, topic, chapter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return questions, evaluate(questions, answers, 0), nil
 }
 
 // Submit 提交答案并记录测验结果。
 func (s *Service) Submit(ctx context.Context, userID int64, topic, chapter string, answers []SubmitAnswer, durationMs int64) (*Result, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.Submit")
+	defer span.End()
 	topic = strings.TrimSpace(topic)
 	chapter = strings.TrimSpace(chapter)
 	if userID <= 0 || !IsSupportedTopic(topic) || chapter == "" || len(answers) == 0 {
 		return nil, ErrInvalidInput
 	}
 
-	questions, err := loadQuestions(topic, chapter)
+	questions, err := s.loadQuestions(ctx, topic, chapter)
 	if err != nil {
 		if errors.Is(err, ErrQuizUnavailable) {
 			return nil, err
@@ -72,11 +128,28 @@ func (s *Service) Submit(ctx context.Context, userID int64, topic, chapter strin
 	if _, err := s.repo.SaveRecord(ctx, record); err != nil {
 		return nil, err
 	}
+	if s.events != nil {
+		s.events.Publish(ctx, eventbus.Event{
+			Type:    eventbus.TypeQuizSubmitted,
+			ActorID: userID,
+			Data: map[string]interface{}{
+				"userId":     userID,
+				"topic":      topic,
+				"chapter":    chapter,
+				"score":      result.Score,
+				"total":      result.Total,
+				"durationMs": result.DurationMs,
+			},
+			OccurredAt: result.SubmittedAt.UTC(),
+		})
+	}
 	return result, nil
 }
 
 // History 返回测验历史记录。
 func (s *Service) History(ctx context.Context, userID int64, topic string, from, to *time.Time) ([]HistoryItem, error) {
+	ctx, span := tracing.Start(ctx, "quiz.Service.History")
+	defer span.End()
 	if userID <= 0 {
 		return nil, ErrInvalidInput
 	}
@@ -102,7 +175,24 @@ func (s *Service) History(ctx context.Context, userID int64, topic string, from,
 	return items, nil
 }
 
-func loadQuestions(topic, chapter string) ([]Question, error) {
+// loadQuestions 合并内置题目与题库中已发布的题目，两者皆无时返回 ErrQuizUnavailable。
+func (s *Service) loadQuestions(ctx context.Context, topic, chapter string) ([]Question, error) {
+	builtin, err := builtinQuestions(topic, chapter)
+	if err != nil && !errors.Is(err, ErrQuizUnavailable) {
+		return nil, err
+	}
+	authored, bankErr := s.publishedBankQuestions(ctx, topic, chapter, builtin)
+	if bankErr != nil {
+		return nil, bankErr
+	}
+	questions := append(builtin, authored...)
+	if len(questions) == 0 {
+		return []Question{}, ErrQuizUnavailable
+	}
+	return questions, nil
+}
+
+func builtinQuestions(topic, chapter string) ([]Question, error) {
 	switch topic {
 	case "variables":
 		t := variables.NormalizeTopic(chapter)
@@ -116,6 +206,9 @@ func loadQuestions(topic, chapter string) ([]Question, error) {
 		return convertVariableQuiz(items), nil
 	case "types":
 		t := types.NormalizeTopic(chapter)
+		if t == TypesComprehensiveChapter {
+			return convertTypeQuiz(types.LoadComprehensiveQuiz()), nil
+		}
 		items, err := types.LoadQuiz(t)
 		if err != nil {
 			if errors.Is(err, types.ErrQuizUnavailable) {
@@ -132,21 +225,7 @@ func loadQuestions(topic, chapter string) ([]Question, error) {
 func convertVariableQuiz(items []variables.QuizItem) []Question {
 	list := make([]Question, 0, len(items))
 	for _, item := range items {
-		options := make([]Option, 0, len(item.Options))
-		for idx, opt := range item.Options {
-			options = append(options, Option{
-				ID:    optionID(idx),
-				Label: opt,
-			})
-		}
-		list = append(list, Question{
-			ID:          item.ID,
-			Stem:        item.Stem,
-			Options:     options,
-			Multi:       len(strings.TrimSpace(item.Answer)) > 1,
-			Answer:      splitAnswer(item.Answer),
-			Explanation: item.Explanation,
-		})
+		list = append(list, toQuestion(item.ID, item.Stem, item.Options, item.Answer, item.Explanation))
 	}
 	return list
 }
@@ -154,25 +233,31 @@ func convertVariableQuiz(items []variables.QuizItem) []Question {
 func convertTypeQuiz(items []types.QuizItem) []Question {
 	list := make([]Question, 0, len(items))
 	for _, item := range items {
-		options := make([]Option, 0, len(item.Options))
-		for idx, opt := range item.Options {
-			options = append(options, Option{
-				ID:    optionID(idx),
-				Label: opt,
-			})
-		}
-		list = append(list, Question{
-			ID:          item.ID,
-			Stem:        item.Stem,
-			Options:     options,
-			Multi:       len(strings.TrimSpace(item.Answer)) > 1,
-			Answer:      splitAnswer(item.Answer),
-			Explanation: item.Explanation,
-		})
+		q := toQuestion(item.ID, item.Stem, item.Options, item.Answer, item.Explanation)
+		q.RuleRef = item.RuleRef
+		list = append(list, q)
 	}
 	return list
 }
 
+func toQuestion(id, stem string, opts []string, answer, explanation string) Question {
+	options := make([]Option, 0, len(opts))
+	for idx, opt := range opts {
+		options = append(options, Option{
+			ID:    optionID(idx),
+			Label: opt,
+		})
+	}
+	return Question{
+		ID:          id,
+		Stem:        stem,
+		Options:     options,
+		Multi:       len(strings.TrimSpace(answer)) > 1,
+		Answer:      splitAnswer(answer),
+		Explanation: explanation,
+	}
+}
+
 func evaluate(questions []Question, submitted []SubmitAnswer, durationMs int64) *Result {
 	answerMap := map[string][]string{}
 	for _, ans := range submitted {
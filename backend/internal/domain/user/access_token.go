@@ -0,0 +1,171 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/tracing"
+)
+
+// 个人访问令牌可申请的权限范围。
+const (
+	ScopeProgressRead  = "progress:read"
+	ScopeProgressWrite = "progress:write"
+	ScopeQuizRead      = "quiz:read"
+	ScopeQuizWrite     = "quiz:write"
+	ScopeContentRead   = "content:read"
+	ScopeClassesRead   = "classes:read"
+	ScopeNotesRead     = "notes:read"
+	ScopeNotesWrite    = "notes:write"
+)
+
+// AccessTokenPrefix 为个人访问令牌的固定前缀，便于与 JWT 区分与密钥扫描。
+const AccessTokenPrefix = "gsp_"
+
+const (
+	maxAccessTokensPerUser = 50
+	maxAccessTokenTTL      = 366 * 24 * time.Hour
+	maxAccessTokenName     = 64
+)
+
+var validScopes = map[string]struct{}{
+	ScopeProgressRead:  {},
+	ScopeProgressWrite: {},
+	ScopeQuizRead:      {},
+	ScopeQuizWrite:     {},
+	ScopeContentRead:   {},
+	ScopeClassesRead:   {},
+	ScopeNotesRead:     {},
+	ScopeNotesWrite:    {},
+}
+
+// WithAccessTokens 启用个人访问令牌能力。
+func (s *Service) WithAccessTokens(repo AccessTokenRepository) *Service {
+	s.tokens = repo
+	return s
+}
+
+// CreateAccessToken 为当前用户创建访问令牌，ttl 为 0 表示永不过期；返回仅展示一次的明文。
+func (s *Service) CreateAccessToken(ctx context.Context, userID int64, name string, scopes []string, ttl time.Duration) (*AccessToken, string, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.CreateAccessToken")
+	defer span.End()
+	if s.tokens == nil || userID <= 0 {
+		return nil, "", ErrInvalidInput
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAccessTokenName {
+		return nil, "", ErrInvalidInput
+	}
+	normalized, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if ttl < 0 || ttl > maxAccessTokenTTL {
+		return nil, "", ErrInvalidInput
+	}
+	existing, err := s.tokens.ListAccessTokens(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) >= maxAccessTokensPerUser {
+		return nil, "", ErrInvalidInput
+	}
+
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	raw := AccessTokenPrefix + hex.EncodeToString(buf)
+	token := &AccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashToken(raw),
+		Prefix:    raw[:len(AccessTokenPrefix)+6],
+		Scopes:    normalized,
+		CreatedAt: s.now(),
+	}
+	if ttl > 0 {
+		expiresAt := s.now().Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	id, err := s.tokens.CreateAccessToken(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+	token.ID = id
+	audit.Record(ctx, "access_token_created", userID, "ok", fmt.Sprintf("token_id=%d scopes=%s", id, strings.Join(normalized, " ")))
+	return token, raw, nil
+}
+
+// ListAccessTokens 列出用户未撤销的访问令牌（不含明文）。
+func (s *Service) ListAccessTokens(ctx context.Context, userID int64) ([]AccessToken, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ListAccessTokens")
+	defer span.End()
+	if s.tokens == nil {
+		return []AccessToken{}, nil
+	}
+	return s.tokens.ListAccessTokens(ctx, userID)
+}
+
+// RevokeAccessToken 撤销用户自己的访问令牌。
+func (s *Service) RevokeAccessToken(ctx context.Context, userID, tokenID int64) error {
+	ctx, span := tracing.Start(ctx, "user.Service.RevokeAccessToken")
+	defer span.End()
+	if s.tokens == nil || tokenID <= 0 {
+		return ErrAccessTokenNotFound
+	}
+	revoked, err := s.tokens.RevokeAccessToken(ctx, userID, tokenID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAccessTokenNotFound
+	}
+	audit.Record(ctx, "access_token_revoked", userID, "ok", fmt.Sprintf("token_id=%d", tokenID))
+	return nil
+}
+
+// IsAccessToken 判断 Bearer 值是否为个人访问令牌。
+func IsAccessToken(raw string) bool {
+	return strings.HasPrefix(raw, AccessTokenPrefix)
+}
+
+// HashAccessToken 计算访问令牌的存储哈希，与刷新令牌使用相同算法。
+func HashAccessToken(raw string) string {
+	return hashToken(raw)
+}
+
+// ValidScope 判断权限范围是否受支持。
+func ValidScope(scope string) bool {
+	_, ok := validScopes[scope]
+	return ok
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !ValidScope(scope) {
+			return nil, ErrInvalidInput
+		}
+		if _, dup := seen[scope]; dup {
+			continue
+		}
+		seen[scope] = struct{}{}
+		out = append(out, scope)
+	}
+	if len(out) == 0 {
+		return nil, ErrInvalidInput
+	}
+	sort.Strings(out)
+	return out, nil
+}
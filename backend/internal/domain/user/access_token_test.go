@@ -0,0 +1,119 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockAccessTokenRepo struct {
+	tokens map[int64]*AccessToken
+	autoID int64
+}
+
+func newMockAccessTokenRepo() *mockAccessTokenRepo {
+	return &mockAccessTokenRepo{tokens: make(map[int64]*AccessToken), autoID: 1}
+}
+
+func (m *mockAccessTokenRepo) CreateAccessToken(_ context.Context, token *AccessToken) (int64, error) {
+	clone := *token
+	clone.ID = m.autoID
+	m.tokens[clone.ID] = &clone
+	m.autoID++
+	return clone.ID, nil
+}
+
+func (m *mockAccessTokenRepo) ListAccessTokens(_ context.Context, userID int64) ([]AccessToken, error) {
+	var list []AccessToken
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			list = append(list, *token)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockAccessTokenRepo) FindAccessTokenByHash(_ context.Context, tokenHash string) (*AccessToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			clone := *token
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAccessTokenRepo) RevokeAccessToken(_ context.Context, userID, id int64) (bool, error) {
+	token, ok := m.tokens[id]
+	if !ok || token.UserID != userID || token.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	return true, nil
+}
+
+func (m *mockAccessTokenRepo) TouchAccessToken(_ context.Context, id int64, ip string, at time.Time) error {
+	if token, ok := m.tokens[id]; ok {
+		token.LastUsedAt = &at
+		token.LastUsedIP = ip
+	}
+	return nil
+}
+
+func TestService_CreateAccessToken(t *testing.T) {
+	repo := newMockAccessTokenRepo()
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	svc := NewService(newMockRepo(), time.Hour, time.Hour).WithAccessTokens(repo).WithClock(clock.Now)
+	ctx := context.Background()
+
+	token, raw, err := svc.CreateAccessToken(ctx, 1, "ci", []string{ScopeQuizWrite, ScopeProgressRead, ScopeQuizWrite}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("创建访问令牌失败: %v", err)
+	}
+	if !IsAccessToken(raw) || !strings.HasPrefix(raw, token.Prefix) {
+		t.Fatalf("令牌格式不正确: %s", raw)
+	}
+	if token.TokenHash != HashAccessToken(raw) || token.TokenHash == raw {
+		t.Fatalf("令牌应只保存哈希")
+	}
+	if len(token.Scopes) != 2 || token.Scopes[0] != ScopeProgressRead {
+		t.Fatalf("权限范围应去重并排序: %v", token.Scopes)
+	}
+	if !token.Active(clock.Now()) || token.Active(clock.Now().Add(25*time.Hour)) {
+		t.Fatalf("过期判断不正确")
+	}
+
+	if _, _, err := svc.CreateAccessToken(ctx, 1, "bad", []string{"admin:all"}, 0); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("未知权限范围应被拒绝，得到: %v", err)
+	}
+	if _, _, err := svc.CreateAccessToken(ctx, 1, " ", []string{ScopeQuizRead}, 0); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("空名称应被拒绝，得到: %v", err)
+	}
+	if _, _, err := svc.CreateAccessToken(ctx, 1, "forever", []string{ScopeQuizRead}, 0); err != nil {
+		t.Fatalf("不设过期时间应允许创建: %v", err)
+	}
+}
+
+func TestService_RevokeAccessToken(t *testing.T) {
+	repo := newMockAccessTokenRepo()
+	svc := NewService(newMockRepo(), time.Hour, time.Hour).WithAccessTokens(repo)
+	ctx := context.Background()
+
+	token, _, _ := svc.CreateAccessToken(ctx, 1, "script", []string{ScopeContentRead}, 0)
+	if err := svc.RevokeAccessToken(ctx, 2, token.ID); !errors.Is(err, ErrAccessTokenNotFound) {
+		t.Fatalf("不能撤销他人的令牌，得到: %v", err)
+	}
+	if err := svc.RevokeAccessToken(ctx, 1, token.ID); err != nil {
+		t.Fatalf("撤销令牌失败: %v", err)
+	}
+	list, _ := svc.ListAccessTokens(ctx, 1)
+	if len(list) != 0 {
+		t.Fatalf("撤销后不应再列出")
+	}
+	if err := svc.RevokeAccessToken(ctx, 1, token.ID); !errors.Is(err, ErrAccessTokenNotFound) {
+		t.Fatalf("重复撤销应返回未找到，得到: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"go-study2/internal/infrastructure/tracing"
+)
+
+// CredentialPurge 为一次过期凭据清理删除的各类记录数。
+type CredentialPurge struct {
+	RefreshTokens int64 `json:"refreshTokens"`
+	MFAChallenges int64 `json:"mfaChallenges"`
+	LoginStates   int64 `json:"loginStates"`
+}
+
+// WithCredentialCleanup 启用过期凭据清理能力。
+func (s *Service) WithCredentialCleanup(repo CredentialCleanupRepository) *Service {
+	s.cleanup = repo
+	return s
+}
+
+// PurgeExpiredCredentials 删除已过期的刷新令牌、两步验证挑战与外部登录状态。
+func (s *Service) PurgeExpiredCredentials(ctx context.Context) (CredentialPurge, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.PurgeExpiredCredentials")
+	defer span.End()
+	var purge CredentialPurge
+	if s.cleanup == nil {
+		return purge, errors.New("未配置凭据清理仓储")
+	}
+	now := s.now()
+	var err error
+	if purge.RefreshTokens, err = s.cleanup.DeleteExpiredRefreshTokens(ctx, now); err != nil {
+		return purge, err
+	}
+	if purge.MFAChallenges, err = s.cleanup.DeleteExpiredMFAChallenges(ctx, now); err != nil {
+		return purge, err
+	}
+	if purge.LoginStates, err = s.cleanup.DeleteExpiredLoginStates(ctx, now); err != nil {
+		return purge, err
+	}
+	return purge, nil
+}
@@ -0,0 +1,48 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type stubCleanupRepo struct {
+	cutoffs []time.Time
+}
+
+func (s *stubCleanupRepo) DeleteExpiredRefreshTokens(_ context.Context, now time.Time) (int64, error) {
+	s.cutoffs = append(s.cutoffs, now)
+	return 3, nil
+}
+
+func (s *stubCleanupRepo) DeleteExpiredMFAChallenges(_ context.Context, now time.Time) (int64, error) {
+	s.cutoffs = append(s.cutoffs, now)
+	return 2, nil
+}
+
+func (s *stubCleanupRepo) DeleteExpiredLoginStates(_ context.Context, now time.Time) (int64, error) {
+	s.cutoffs = append(s.cutoffs, now)
+	return 1, nil
+}
+
+func TestService_PurgeExpiredCredentials(t *testing.T) {
+	now := time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC)
+	repo := &stubCleanupRepo{}
+	svc := NewService(newMockRepo(), time.Hour, time.Hour).WithClock(func() time.Time { return now })
+	if _, err := svc.PurgeExpiredCredentials(context.Background()); err == nil {
+		t.Fatalf("未配置清理仓储时应返回错误")
+	}
+
+	purge, err := svc.WithCredentialCleanup(repo).PurgeExpiredCredentials(context.Background())
+	if err != nil {
+		t.Fatalf("清理过期凭据失败: %v", err)
+	}
+	if purge != (CredentialPurge{RefreshTokens: 3, MFAChallenges: 2, LoginStates: 1}) {
+		t.Fatalf("清理计数不正确: %+v", purge)
+	}
+	for _, cutoff := range repo.cutoffs {
+		if !cutoff.Equal(now) {
+			t.Fatalf("应以服务时钟作为过期判断时间: %v", cutoff)
+		}
+	}
+}
@@ -8,8 +8,10 @@ type User struct {
 	Username           string    `json:"username"`
 	PasswordHash       string    `json:"-"`
 	IsAdmin            bool      `json:"isAdmin"`
+	IsTeacher          bool      `json:"isTeacher"`
 	Status             string    `json:"status"`
 	MustChangePassword bool      `json:"mustChangePassword"`
+	PasswordChangedAt  time.Time `json:"passwordChangedAt"`
 	CreatedAt          time.Time `json:"createdAt"`
 	UpdatedAt          time.Time `json:"updatedAt"`
 }
@@ -32,7 +34,120 @@ type TokenPair struct {
 }
 
 // AuthResult 返回用户基础信息与令牌对。
+// 启用两步验证的用户登录时 Tokens 为空，改为返回 MFAChallenge，需调用 VerifyMFA 换取令牌。
 type AuthResult struct {
-	User   *User     `json:"user"`
-	Tokens TokenPair `json:"tokens"`
+	User             *User         `json:"user"`
+	Tokens           TokenPair     `json:"tokens"`
+	MFAChallenge     *MFAChallenge `json:"mfaChallenge,omitempty"`
+	MFASetupRequired bool          `json:"mfaSetupRequired"`
+}
+
+// MFASettings 表示用户的 TOTP 两步验证配置。
+type MFASettings struct {
+	UserID       int64      `json:"userId"`
+	Secret       string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	LastUsedStep int64      `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmedAt,omitempty"`
+}
+
+// MFAChallengeRecord 表示持久化的登录二次验证挑战，仅保存令牌哈希。
+type MFAChallengeRecord struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"userId"`
+	TokenHash string    `json:"tokenHash"`
+	Attempts  int       `json:"attempts"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// MFAChallenge 为密码校验通过后返回给客户端的短期挑战。
+type MFAChallenge struct {
+	Token     string    `json:"mfaToken"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// MFAEnrollment 为开始绑定时返回的密钥与 otpauth 地址。
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauthUri"`
+}
+
+// Invite 表示管理员生成的注册邀请码，明文邀请码只在创建时返回一次。
+type Invite struct {
+	ID       int64  `json:"id"`
+	CodeHash string `json:"-"`
+	CodeHint string `json:"codeHint"`
+	Role     string `json:"role"`
+	// ClassID 大于 0 时注册成功后自动加入该班级。
+	ClassID   int64      `json:"classId,omitempty"`
+	MaxUses   int        `json:"maxUses"`
+	UsedCount int        `json:"usedCount"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	CreatedBy int64      `json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// InviteRedemption 记录一次邀请码使用，用于审计。
+type InviteRedemption struct {
+	ID        int64     `json:"id"`
+	InviteID  int64     `json:"inviteId"`
+	UserID    int64     `json:"userId"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ExternalIdentity 表示与本地用户绑定的外部身份（如 OIDC 提供方的 sub）。
+type ExternalIdentity struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"userId"`
+	Provider    string    `json:"provider"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastLoginAt time.Time `json:"lastLoginAt"`
+}
+
+// ExternalLoginState 保存一次外部登录跳转的 state 哈希、nonce 与 PKCE code_verifier。
+type ExternalLoginState struct {
+	ID           int64     `json:"id"`
+	StateHash    string    `json:"stateHash"`
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"codeVerifier"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// AccessToken 表示供脚本与 CI 使用的个人访问令牌，明文只在创建时返回一次。
+type AccessToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"userId"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP string     `json:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// Active 判断令牌在给定时间是否可用（未撤销且未过期）。
+func (t *AccessToken) Active(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// HasScope 判断令牌是否包含指定权限范围。
+func (t *AccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
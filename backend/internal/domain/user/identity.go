@@ -0,0 +1,211 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/tracing"
+)
+
+const (
+	// externalLoginTTL 为跳转到提供方到回调之间允许的最长时间。
+	externalLoginTTL = 10 * time.Minute
+	// externalPasswordHash 不是合法的 bcrypt 哈希，自动开通的外部账号无法使用本地密码登录。
+	externalPasswordHash = "!external"
+	maxUsernameLength    = 50
+)
+
+// ExternalProfile 为外部提供方认证通过后映射出的用户资料。
+type ExternalProfile struct {
+	Provider string
+	Subject  string
+	// Username 为期望的本地用户名，冲突或不合法时自动调整。
+	Username string
+	Email    string
+	// IsAdmin 由提供方声明映射而来，仅在首次开通时生效。
+	IsAdmin bool
+	// AutoProvision 为 false 时未绑定的外部账号将被拒绝。
+	AutoProvision bool
+}
+
+// WithIdentities 启用外部身份登录能力。
+func (s *Service) WithIdentities(repo IdentityRepository) *Service {
+	s.identities = repo
+	return s
+}
+
+// BeginExternalLogin 保存 nonce 与 code_verifier，返回发往提供方的一次性 state。
+func (s *Service) BeginExternalLogin(ctx context.Context, provider, nonce, codeVerifier string) (string, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.BeginExternalLogin")
+	defer span.End()
+	if s.identities == nil || provider == "" || nonce == "" || codeVerifier == "" {
+		return "", ErrInvalidInput
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	state := hex.EncodeToString(buf)
+	if err := s.identities.SaveLoginState(ctx, ExternalLoginState{
+		StateHash:    hashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    s.now().Add(externalLoginTTL),
+	}); err != nil {
+		return "", err
+	}
+	return state, nil
+}
+
+// ResumeExternalLogin 消费回调中的 state，返回对应的 nonce 与 code_verifier。
+func (s *Service) ResumeExternalLogin(ctx context.Context, provider, state string) (*ExternalLoginState, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ResumeExternalLogin")
+	defer span.End()
+	if s.identities == nil || state == "" {
+		return nil, ErrExternalLoginState
+	}
+	record, err := s.identities.TakeLoginState(ctx, hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.Provider != provider || s.now().After(record.ExpiresAt) {
+		audit.Record(ctx, "external_login_failed", 0, "invalid_state", provider)
+		return nil, ErrExternalLoginState
+	}
+	return record, nil
+}
+
+// LoginWithExternal 按 (provider, subject) 查找绑定用户，必要时自动开通，然后走常规登录流程。
+func (s *Service) LoginWithExternal(ctx context.Context, profile ExternalProfile) (*AuthResult, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.LoginWithExternal")
+	defer span.End()
+	if s.identities == nil || profile.Provider == "" || profile.Subject == "" {
+		return nil, ErrInvalidInput
+	}
+
+	identity, err := s.identities.FindIdentity(ctx, profile.Provider, profile.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing *User
+	if identity != nil {
+		existing, err = s.repo.FindByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, ErrUserNotFound
+		}
+		if err := s.identities.TouchIdentity(ctx, identity.ID, profile.Email); err != nil {
+			return nil, err
+		}
+	} else {
+		if !profile.AutoProvision {
+			audit.Record(ctx, "external_login_failed", 0, "not_linked", profile.Provider)
+			return nil, ErrExternalNotLinked
+		}
+		existing, err = s.provisionExternalUser(ctx, profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.completeLogin(ctx, existing)
+	if err == nil {
+		audit.Record(ctx, "external_login_success", existing.ID, "ok", profile.Provider)
+	}
+	return result, err
+}
+
+func (s *Service) provisionExternalUser(ctx context.Context, profile ExternalProfile) (*User, error) {
+	username, err := s.availableUsername(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	created := &User{
+		Username:     username,
+		PasswordHash: externalPasswordHash,
+		IsAdmin:      profile.IsAdmin,
+		Status:       defaultUserStatus,
+	}
+	userID, err := s.repo.Create(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+	created.ID = userID
+
+	if err := s.identities.CreateIdentity(ctx, ExternalIdentity{
+		UserID:   userID,
+		Provider: profile.Provider,
+		Subject:  profile.Subject,
+		Email:    profile.Email,
+	}); err != nil {
+		return nil, err
+	}
+	s.announceUser(ctx, created, "oidc:"+profile.Provider)
+	audit.Record(ctx, "external_user_provisioned", userID, "ok", fmt.Sprintf("provider=%s admin=%t", profile.Provider, profile.IsAdmin))
+	return created, nil
+}
+
+// availableUsername 从期望用户名或邮箱前缀派生合法且未占用的用户名；
+// 不会与同名本地账号自动合并，避免外部账号接管本地账号。
+func (s *Service) availableUsername(ctx context.Context, profile ExternalProfile) (string, error) {
+	base := sanitizeUsername(profile.Username)
+	if base == "" {
+		local, _, _ := strings.Cut(profile.Email, "@")
+		base = sanitizeUsername(local)
+	}
+	if base == "" {
+		base = sanitizeUsername(profile.Provider + "_" + hashToken(profile.Subject)[:8])
+	}
+
+	candidate := base
+	for i := 2; i <= 20; i++ {
+		existing, err := s.repo.FindByUsername(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+		suffix := fmt.Sprintf("_%d", i)
+		candidate = truncate(base, maxUsernameLength-len(suffix)) + suffix
+	}
+
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return truncate(base, maxUsernameLength-9) + "_" + hex.EncodeToString(buf), nil
+}
+
+func sanitizeUsername(raw string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		case r == '.' || r == '-' || r == ' ':
+			b.WriteRune('_')
+		}
+	}
+	name := truncate(b.String(), maxUsernameLength)
+	if !usernamePattern.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,153 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appjwt "go-study2/internal/pkg/jwt"
+)
+
+type mockIdentityRepo struct {
+	identities []ExternalIdentity
+	states     map[string]ExternalLoginState
+}
+
+func newMockIdentityRepo() *mockIdentityRepo {
+	return &mockIdentityRepo{states: make(map[string]ExternalLoginState)}
+}
+
+func (m *mockIdentityRepo) FindIdentity(_ context.Context, provider, subject string) (*ExternalIdentity, error) {
+	for _, identity := range m.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			clone := identity
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockIdentityRepo) CreateIdentity(_ context.Context, identity ExternalIdentity) error {
+	identity.ID = int64(len(m.identities) + 1)
+	m.identities = append(m.identities, identity)
+	return nil
+}
+
+func (m *mockIdentityRepo) TouchIdentity(_ context.Context, id int64, email string) error {
+	for i := range m.identities {
+		if m.identities[i].ID == id {
+			m.identities[i].Email = email
+		}
+	}
+	return nil
+}
+
+func (m *mockIdentityRepo) SaveLoginState(_ context.Context, state ExternalLoginState) error {
+	m.states[state.StateHash] = state
+	return nil
+}
+
+func (m *mockIdentityRepo) TakeLoginState(_ context.Context, stateHash string) (*ExternalLoginState, error) {
+	state, ok := m.states[stateHash]
+	if !ok {
+		return nil, nil
+	}
+	delete(m.states, stateHash)
+	return &state, nil
+}
+
+func newIdentityTestService(t *testing.T) (*Service, *mockRepo, *mockIdentityRepo, *fakeClock) {
+	t.Helper()
+	_ = appjwt.Configure(appjwt.Options{
+		Secret:             "abcdef0123456789",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: time.Hour,
+	})
+	repo := newMockRepo()
+	identities := newMockIdentityRepo()
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	svc := NewService(repo, time.Hour, time.Hour).WithIdentities(identities).WithClock(clock.Now)
+	return svc, repo, identities, clock
+}
+
+func TestService_ExternalLoginState_OneTimeAndExpiring(t *testing.T) {
+	svc, _, _, clock := newIdentityTestService(t)
+	ctx := context.Background()
+
+	state, err := svc.BeginExternalLogin(ctx, "corp", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("保存登录状态失败: %v", err)
+	}
+	if _, err := svc.ResumeExternalLogin(ctx, "other", state); !errors.Is(err, ErrExternalLoginState) {
+		t.Fatalf("提供方不一致应被拒绝，得到: %v", err)
+	}
+	if _, err := svc.ResumeExternalLogin(ctx, "corp", state); !errors.Is(err, ErrExternalLoginState) {
+		t.Fatalf("state 只能使用一次，得到: %v", err)
+	}
+
+	state, _ = svc.BeginExternalLogin(ctx, "corp", "nonce-2", "verifier-2")
+	record, err := svc.ResumeExternalLogin(ctx, "corp", state)
+	if err != nil || record.Nonce != "nonce-2" || record.CodeVerifier != "verifier-2" {
+		t.Fatalf("应返回保存的 nonce 与 code_verifier: %+v, %v", record, err)
+	}
+
+	state, _ = svc.BeginExternalLogin(ctx, "corp", "nonce-3", "verifier-3")
+	clock.Advance(externalLoginTTL + time.Second)
+	if _, err := svc.ResumeExternalLogin(ctx, "corp", state); !errors.Is(err, ErrExternalLoginState) {
+		t.Fatalf("过期 state 应被拒绝，得到: %v", err)
+	}
+}
+
+func TestService_LoginWithExternal_ProvisionsAndLinks(t *testing.T) {
+	svc, repo, identities, _ := newIdentityTestService(t)
+	ctx := context.Background()
+	// 同名本地账号不应被外部身份接管
+	localID, _ := repo.Create(ctx, &User{Username: "alice", PasswordHash: hashOrFail(t, "TestPass123!")})
+
+	profile := ExternalProfile{Provider: "corp", Subject: "sub-1", Username: "alice", Email: "alice@example.com", IsAdmin: true, AutoProvision: true}
+	first, err := svc.LoginWithExternal(ctx, profile)
+	if err != nil {
+		t.Fatalf("首次外部登录失败: %v", err)
+	}
+	if first.User.ID == localID || first.User.Username != "alice_2" || !first.User.IsAdmin {
+		t.Fatalf("应开通新的管理员账号并避开同名用户: %+v", first.User)
+	}
+	if first.Tokens.AccessToken == "" {
+		t.Fatalf("外部登录应签发令牌")
+	}
+	if _, err := svc.Login(ctx, "alice_2", "TestPass123!"); !errors.Is(err, ErrInvalidCredential) {
+		t.Fatalf("外部开通的账号不可使用本地密码登录，得到: %v", err)
+	}
+
+	profile.IsAdmin = false
+	second, err := svc.LoginWithExternal(ctx, profile)
+	if err != nil || second.User.ID != first.User.ID {
+		t.Fatalf("再次登录应复用已绑定用户: %v", err)
+	}
+	if len(identities.identities) != 1 {
+		t.Fatalf("不应重复创建绑定")
+	}
+}
+
+func TestService_LoginWithExternal_RequiresLinkWhenProvisionDisabled(t *testing.T) {
+	svc, _, _, _ := newIdentityTestService(t)
+	_, err := svc.LoginWithExternal(context.Background(), ExternalProfile{Provider: "corp", Subject: "sub-x"})
+	if !errors.Is(err, ErrExternalNotLinked) {
+		t.Fatalf("关闭自动开通时应拒绝未绑定账号，得到: %v", err)
+	}
+}
+
+func TestSanitizeUsername(t *testing.T) {
+	cases := map[string]string{
+		"john.doe":  "john_doe",
+		"a":         "",
+		"张三":        "",
+		"dev-ops 1": "dev_ops_1",
+	}
+	for in, want := range cases {
+		if got := sanitizeUsername(in); got != want {
+			t.Fatalf("sanitizeUsername(%q) = %q，期望 %q", in, got, want)
+		}
+	}
+}
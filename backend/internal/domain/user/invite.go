@@ -0,0 +1,286 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/tracing"
+	"go-study2/internal/pkg/randcode"
+)
+
+// 可分配的角色。
+const (
+	RoleUser    = "user"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
+const (
+	maxInviteUses    = 1000
+	inviteCodeLength = 12
+)
+
+// RegistrationPolicy 描述自助注册策略。
+type RegistrationPolicy struct {
+	// Open 为 true 时允许不带邀请码注册普通用户。
+	Open bool
+}
+
+// ClassEnroller 把通过邀请码注册的用户加入班级，由班级服务实现。
+type ClassEnroller interface {
+	// ClassExists 返回班级是否存在。
+	ClassExists(ctx context.Context, classID int64) (bool, error)
+	// EnrollInvited 把新用户加入班级，asTeacher 为 true 时以教师身份加入。
+	EnrollInvited(ctx context.Context, classID, userID int64, asTeacher bool) error
+}
+
+// InviteOptions 为管理员创建邀请码的参数。
+type InviteOptions struct {
+	Role string
+	// ClassID 大于 0 时注册成功后自动加入该班级，需要先通过 WithClassEnrollment 启用。
+	ClassID   int64
+	MaxUses   int
+	ExpiresIn time.Duration
+	Note      string
+}
+
+// WithRegistration 启用邀请码与自助注册能力。
+func (s *Service) WithRegistration(repo InviteRepository, policy RegistrationPolicy) *Service {
+	s.inviteRepo = repo
+	s.registration = policy
+	return s
+}
+
+// WithClassEnrollment 允许邀请码绑定班级，注册成功后自动加入。
+func (s *Service) WithClassEnrollment(classes ClassEnroller) *Service {
+	s.classes = classes
+	return s
+}
+
+// RegistrationOpen 返回是否允许无邀请码注册。
+func (s *Service) RegistrationOpen() bool {
+	return s.registration.Open
+}
+
+// CreateInvite 由管理员生成邀请码，返回邀请记录与仅展示一次的明文邀请码。
+func (s *Service) CreateInvite(ctx context.Context, operatorID int64, opts InviteOptions) (*Invite, string, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.CreateInvite")
+	defer span.End()
+	if err := s.requireAdmin(ctx, operatorID, "invite_create_denied"); err != nil {
+		return nil, "", err
+	}
+	if s.inviteRepo == nil {
+		return nil, "", ErrRegistrationClosed
+	}
+
+	role := strings.TrimSpace(opts.Role)
+	if role == "" {
+		role = RoleUser
+	}
+	if role != RoleUser && role != RoleTeacher && role != RoleAdmin {
+		return nil, "", ErrInvalidInput
+	}
+	maxUses := opts.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+	if maxUses < 0 || maxUses > maxInviteUses || opts.ExpiresIn < 0 || opts.ClassID < 0 {
+		return nil, "", ErrInvalidInput
+	}
+	if opts.ClassID > 0 {
+		if s.classes == nil {
+			return nil, "", ErrInvalidInput
+		}
+		exists, err := s.classes.ClassExists(ctx, opts.ClassID)
+		if err != nil {
+			return nil, "", err
+		}
+		if !exists {
+			return nil, "", ErrInvalidInput
+		}
+	}
+
+	code, err := randcode.New(inviteCodeLength)
+	if err != nil {
+		return nil, "", err
+	}
+	invite := &Invite{
+		CodeHash:  hashToken(code),
+		CodeHint:  code[len(code)-4:],
+		Role:      role,
+		ClassID:   opts.ClassID,
+		MaxUses:   maxUses,
+		Note:      strings.TrimSpace(opts.Note),
+		CreatedBy: operatorID,
+		CreatedAt: s.now(),
+	}
+	if opts.ExpiresIn > 0 {
+		expiresAt := s.now().Add(opts.ExpiresIn)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	id, err := s.inviteRepo.CreateInvite(ctx, invite)
+	if err != nil {
+		return nil, "", err
+	}
+	invite.ID = id
+	audit.Record(ctx, "invite_created", operatorID, "ok", fmt.Sprintf("invite_id=%d role=%s class_id=%d max_uses=%d", id, role, opts.ClassID, maxUses))
+	return invite, code, nil
+}
+
+// ListInvites 返回全部邀请码（不含明文）。
+func (s *Service) ListInvites(ctx context.Context, operatorID int64) ([]Invite, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ListInvites")
+	defer span.End()
+	if err := s.requireAdmin(ctx, operatorID, "invite_list_denied"); err != nil {
+		return nil, err
+	}
+	if s.inviteRepo == nil {
+		return []Invite{}, nil
+	}
+	return s.inviteRepo.ListInvites(ctx)
+}
+
+// RevokeInvite 撤销邀请码，已注册的用户不受影响。
+func (s *Service) RevokeInvite(ctx context.Context, operatorID, inviteID int64) error {
+	ctx, span := tracing.Start(ctx, "user.Service.RevokeInvite")
+	defer span.End()
+	if err := s.requireAdmin(ctx, operatorID, "invite_revoke_denied"); err != nil {
+		return err
+	}
+	if s.inviteRepo == nil || inviteID <= 0 {
+		return ErrInvalidInput
+	}
+	existing, err := s.inviteRepo.FindInviteByID(ctx, inviteID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrInviteInvalid
+	}
+	if err := s.inviteRepo.RevokeInvite(ctx, inviteID); err != nil {
+		return err
+	}
+	audit.Record(ctx, "invite_revoked", operatorID, "ok", fmt.Sprintf("invite_id=%d", inviteID))
+	return nil
+}
+
+// ListInviteRedemptions 返回邀请码的使用记录。
+func (s *Service) ListInviteRedemptions(ctx context.Context, operatorID, inviteID int64) ([]InviteRedemption, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ListInviteRedemptions")
+	defer span.End()
+	if err := s.requireAdmin(ctx, operatorID, "invite_list_denied"); err != nil {
+		return nil, err
+	}
+	if s.inviteRepo == nil || inviteID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.inviteRepo.ListRedemptions(ctx, inviteID)
+}
+
+// Signup 自助注册：携带邀请码时按邀请码分配角色，未开放注册时邀请码必填。
+func (s *Service) Signup(ctx context.Context, inviteCode, username, rawPassword string) (*AuthResult, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.Signup")
+	defer span.End()
+	inviteCode = strings.TrimSpace(inviteCode)
+	if inviteCode == "" {
+		if !s.registration.Open {
+			audit.Record(ctx, "signup_denied", 0, "registration_closed", username)
+			return nil, ErrRegistrationClosed
+		}
+		result, err := s.registerUser(ctx, username, rawPassword, createUserOptions{issueTokens: true, source: "signup"})
+		if err == nil {
+			audit.Record(ctx, "signup_success", result.User.ID, "ok", "open_registration")
+		}
+		return result, err
+	}
+	if s.inviteRepo == nil {
+		return nil, ErrInviteInvalid
+	}
+
+	// 先校验用户名与密码，避免无效请求占用邀请名额
+	if err := s.validateCredential(username, rawPassword); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrUserExists
+	}
+
+	invite, err := s.inviteRepo.FindInviteByHash(ctx, hashToken(inviteCode))
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil {
+		audit.Record(ctx, "signup_denied", 0, "invite_invalid", username)
+		return nil, ErrInviteInvalid
+	}
+	// 绑定的班级已删除时邀请码失效，避免创建出无法入班的账号
+	if invite.ClassID > 0 {
+		exists := false
+		if s.classes != nil {
+			if exists, err = s.classes.ClassExists(ctx, invite.ClassID); err != nil {
+				return nil, err
+			}
+		}
+		if !exists {
+			audit.Record(ctx, "signup_denied", 0, "invite_class_missing", fmt.Sprintf("invite_id=%d class_id=%d", invite.ID, invite.ClassID))
+			return nil, ErrInviteInvalid
+		}
+	}
+	consumed, err := s.inviteRepo.ConsumeInvite(ctx, invite.ID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		audit.Record(ctx, "signup_denied", 0, "invite_invalid", fmt.Sprintf("invite_id=%d username=%s", invite.ID, username))
+		return nil, ErrInviteInvalid
+	}
+
+	result, err := s.registerUser(ctx, username, rawPassword, createUserOptions{
+		isAdmin:     invite.Role == RoleAdmin,
+		isTeacher:   invite.Role == RoleTeacher,
+		issueTokens: true,
+		source:      "invite",
+	})
+	if err != nil {
+		_ = s.inviteRepo.ReleaseInvite(ctx, invite.ID)
+		return nil, err
+	}
+	if err := s.inviteRepo.SaveRedemption(ctx, InviteRedemption{
+		InviteID: invite.ID,
+		UserID:   result.User.ID,
+		Username: result.User.Username,
+	}); err != nil {
+		return nil, err
+	}
+	if invite.ClassID > 0 {
+		if err := s.classes.EnrollInvited(ctx, invite.ClassID, result.User.ID, invite.Role == RoleTeacher); err != nil {
+			return nil, err
+		}
+	}
+	audit.Record(ctx, "signup_success", result.User.ID, "ok", fmt.Sprintf("invite_id=%d role=%s class_id=%d", invite.ID, invite.Role, invite.ClassID))
+	return result, nil
+}
+
+func (s *Service) requireAdmin(ctx context.Context, operatorID int64, deniedEvent string) error {
+	if operatorID <= 0 {
+		audit.Record(ctx, deniedEvent, 0, "permission_denied", "missing_operator")
+		return ErrPermissionDenied
+	}
+	operator, err := s.repo.FindByID(ctx, operatorID)
+	if err != nil {
+		return err
+	}
+	if operator == nil || !operator.IsAdmin {
+		audit.Record(ctx, deniedEvent, operatorID, "permission_denied", "non_admin_operator")
+		return ErrPermissionDenied
+	}
+	return nil
+}
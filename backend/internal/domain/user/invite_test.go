@@ -0,0 +1,294 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-study2/internal/infrastructure/eventbus"
+	appjwt "go-study2/internal/pkg/jwt"
+	"go-study2/internal/pkg/password"
+)
+
+type mockInviteRepo struct {
+	invites     map[int64]*Invite
+	redemptions []InviteRedemption
+	autoID      int64
+}
+
+func newMockInviteRepo() *mockInviteRepo {
+	return &mockInviteRepo{invites: make(map[int64]*Invite), autoID: 1}
+}
+
+func (m *mockInviteRepo) CreateInvite(_ context.Context, invite *Invite) (int64, error) {
+	clone := *invite
+	clone.ID = m.autoID
+	m.invites[clone.ID] = &clone
+	m.autoID++
+	return clone.ID, nil
+}
+
+func (m *mockInviteRepo) FindInviteByHash(_ context.Context, codeHash string) (*Invite, error) {
+	for _, inv := range m.invites {
+		if inv.CodeHash == codeHash {
+			clone := *inv
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockInviteRepo) FindInviteByID(_ context.Context, id int64) (*Invite, error) {
+	if inv, ok := m.invites[id]; ok {
+		clone := *inv
+		return &clone, nil
+	}
+	return nil, nil
+}
+
+func (m *mockInviteRepo) ListInvites(_ context.Context) ([]Invite, error) {
+	list := make([]Invite, 0, len(m.invites))
+	for _, inv := range m.invites {
+		list = append(list, *inv)
+	}
+	return list, nil
+}
+
+func (m *mockInviteRepo) RevokeInvite(_ context.Context, id int64) error {
+	if inv, ok := m.invites[id]; ok && inv.RevokedAt == nil {
+		now := time.Now()
+		inv.RevokedAt = &now
+	}
+	return nil
+}
+
+func (m *mockInviteRepo) ConsumeInvite(_ context.Context, id int64, now time.Time) (bool, error) {
+	inv, ok := m.invites[id]
+	if !ok || inv.RevokedAt != nil || inv.UsedCount >= inv.MaxUses {
+		return false, nil
+	}
+	if inv.ExpiresAt != nil && !inv.ExpiresAt.After(now) {
+		return false, nil
+	}
+	inv.UsedCount++
+	return true, nil
+}
+
+func (m *mockInviteRepo) ReleaseInvite(_ context.Context, id int64) error {
+	if inv, ok := m.invites[id]; ok && inv.UsedCount > 0 {
+		inv.UsedCount--
+	}
+	return nil
+}
+
+func (m *mockInviteRepo) SaveRedemption(_ context.Context, redemption InviteRedemption) error {
+	m.redemptions = append(m.redemptions, redemption)
+	return nil
+}
+
+func (m *mockInviteRepo) ListRedemptions(_ context.Context, inviteID int64) ([]InviteRedemption, error) {
+	var list []InviteRedemption
+	for _, r := range m.redemptions {
+		if r.InviteID == inviteID {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+type mockClassEnroller struct {
+	classes  map[int64]bool
+	enrolled map[int64]bool
+}
+
+func (m *mockClassEnroller) ClassExists(_ context.Context, classID int64) (bool, error) {
+	return m.classes[classID], nil
+}
+
+func (m *mockClassEnroller) EnrollInvited(_ context.Context, classID, userID int64, asTeacher bool) error {
+	m.enrolled[userID] = asTeacher
+	return nil
+}
+
+func newInviteTestService(t *testing.T, policy RegistrationPolicy) (*Service, *mockRepo, *mockInviteRepo, *fakeClock, int64) {
+	t.Helper()
+	_ = appjwt.Configure(appjwt.Options{
+		Secret:             "abcdef0123456789",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: time.Hour,
+	})
+	repo := newMockRepo()
+	inviteRepo := newMockInviteRepo()
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	svc := NewService(repo, time.Hour, time.Hour).WithRegistration(inviteRepo, policy).WithClock(clock.Now)
+	adminID, _ := repo.Create(context.Background(), &User{Username: "invite_admin", PasswordHash: hashOrFail(t, "TestPass123!"), IsAdmin: true})
+	return svc, repo, inviteRepo, clock, adminID
+}
+
+func TestService_Signup_WithInvite(t *testing.T) {
+	svc, _, inviteRepo, _, adminID := newInviteTestService(t, RegistrationPolicy{})
+	ctx := context.Background()
+
+	invite, code, err := svc.CreateInvite(ctx, adminID, InviteOptions{Role: RoleAdmin, MaxUses: 1})
+	if err != nil {
+		t.Fatalf("创建邀请码失败: %v", err)
+	}
+	if code == "" || invite.CodeHash == code || invite.CodeHint != code[len(code)-4:] {
+		t.Fatalf("邀请码应仅保存哈希")
+	}
+
+	result, err := svc.Signup(ctx, code, "invited_admin", "TestPass123!")
+	if err != nil {
+		t.Fatalf("邀请注册失败: %v", err)
+	}
+	if !result.User.IsAdmin || result.Tokens.AccessToken == "" {
+		t.Fatalf("应按邀请码角色创建用户并签发令牌")
+	}
+	if len(inviteRepo.redemptions) != 1 || inviteRepo.redemptions[0].UserID != result.User.ID {
+		t.Fatalf("应记录邀请码使用明细")
+	}
+
+	if _, err := svc.Signup(ctx, code, "second_user", "TestPass123!"); !errors.Is(err, ErrInviteInvalid) {
+		t.Fatalf("名额用尽后应返回 ErrInviteInvalid，得到: %v", err)
+	}
+}
+
+func TestService_Signup_WithClassInvite(t *testing.T) {
+	svc, _, inviteRepo, _, adminID := newInviteTestService(t, RegistrationPolicy{})
+	ctx := context.Background()
+
+	if _, _, err := svc.CreateInvite(ctx, adminID, InviteOptions{ClassID: 7}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("未启用班级加入时不能绑定班级，得到: %v", err)
+	}
+	classes := &mockClassEnroller{classes: map[int64]bool{7: true}, enrolled: map[int64]bool{}}
+	svc.WithClassEnrollment(classes)
+	if _, _, err := svc.CreateInvite(ctx, adminID, InviteOptions{ClassID: 8}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("不存在的班级应被拒绝，得到: %v", err)
+	}
+
+	invite, code, err := svc.CreateInvite(ctx, adminID, InviteOptions{Role: RoleTeacher, ClassID: 7, MaxUses: 2})
+	if err != nil || invite.ClassID != 7 {
+		t.Fatalf("创建绑定班级的邀请码失败: %+v, %v", invite, err)
+	}
+	result, err := svc.Signup(ctx, code, "class_teacher", "TestPass123!")
+	if err != nil {
+		t.Fatalf("邀请注册失败: %v", err)
+	}
+	if asTeacher, ok := classes.enrolled[result.User.ID]; !ok || !asTeacher {
+		t.Fatalf("教师邀请码注册后应以教师身份加入班级: %v", classes.enrolled)
+	}
+
+	// 班级删除后邀请码失效，且不占用名额
+	delete(classes.classes, 7)
+	if _, err := svc.Signup(ctx, code, "late_teacher", "TestPass123!"); !errors.Is(err, ErrInviteInvalid) {
+		t.Fatalf("班级已删除时应返回 ErrInviteInvalid，得到: %v", err)
+	}
+	if inviteRepo.invites[invite.ID].UsedCount != 1 {
+		t.Fatalf("班级已删除时不应占用邀请名额")
+	}
+}
+
+func TestService_Signup_RejectsInvalidInvites(t *testing.T) {
+	svc, _, inviteRepo, clock, adminID := newInviteTestService(t, RegistrationPolicy{})
+	ctx := context.Background()
+
+	if _, err := svc.Signup(ctx, "", "no_code", "TestPass123!"); !errors.Is(err, ErrRegistrationClosed) {
+		t.Fatalf("未开放注册时应要求邀请码，得到: %v", err)
+	}
+	if _, err := svc.Signup(ctx, "UNKNOWNCODE1", "bad_code", "TestPass123!"); !errors.Is(err, ErrInviteInvalid) {
+		t.Fatalf("未知邀请码应被拒绝，得到: %v", err)
+	}
+
+	expiring, code, _ := svc.CreateInvite(ctx, adminID, InviteOptions{ExpiresIn: time.Hour})
+	if _, err := svc.Signup(ctx, code, "weak_pwd", "123"); !errors.Is(err, password.ErrPolicyViolation) {
+		t.Fatalf("弱密码应返回密码策略错误，得到: %v", err)
+	}
+	if inviteRepo.invites[expiring.ID].UsedCount != 0 {
+		t.Fatalf("校验失败时不应占用邀请名额")
+	}
+	clock.Advance(2 * time.Hour)
+	if _, err := svc.Signup(ctx, code, "late_user", "TestPass123!"); !errors.Is(err, ErrInviteInvalid) {
+		t.Fatalf("过期邀请码应被拒绝，得到: %v", err)
+	}
+
+	revoked, code, _ := svc.CreateInvite(ctx, adminID, InviteOptions{MaxUses: 5})
+	if err := svc.RevokeInvite(ctx, adminID, revoked.ID); err != nil {
+		t.Fatalf("撤销邀请码失败: %v", err)
+	}
+	if _, err := svc.Signup(ctx, code, "revoked_user", "TestPass123!"); !errors.Is(err, ErrInviteInvalid) {
+		t.Fatalf("已撤销邀请码应被拒绝，得到: %v", err)
+	}
+}
+
+func TestService_Signup_OpenRegistration(t *testing.T) {
+	svc, _, _, _, _ := newInviteTestService(t, RegistrationPolicy{Open: true})
+	pub := &recordingPublisher{}
+	svc.WithEvents(pub)
+	ctx := context.Background()
+
+	result, err := svc.Signup(ctx, "", "open_user", "TestPass123!")
+	if err != nil {
+		t.Fatalf("开放注册失败: %v", err)
+	}
+	if result.User.IsAdmin {
+		t.Fatalf("开放注册只能创建普通用户")
+	}
+	if _, err := svc.Signup(ctx, "", "open_user", "TestPass123!"); !errors.Is(err, ErrUserExists) {
+		t.Fatalf("重复用户名应返回 ErrUserExists，得到: %v", err)
+	}
+	if len(pub.events) != 1 || pub.events[0].Type != eventbus.TypeUserCreated ||
+		pub.events[0].Data["userId"] != result.User.ID || pub.events[0].Data["source"] != "signup" {
+		t.Fatalf("注册成功应且仅应发布一次 user.created: %+v", pub.events)
+	}
+}
+
+type recordingPublisher struct {
+	events []eventbus.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event eventbus.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestService_CreateInvite_RequiresAdmin(t *testing.T) {
+	svc, repo, _, _, _ := newInviteTestService(t, RegistrationPolicy{})
+	ctx := context.Background()
+	memberID, _ := repo.Create(ctx, &User{Username: "plain_member", PasswordHash: hashOrFail(t, "TestPass123!")})
+
+	if _, _, err := svc.CreateInvite(ctx, memberID, InviteOptions{}); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("普通用户不可创建邀请码，得到: %v", err)
+	}
+	if _, err := svc.ListInvites(ctx, memberID); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("普通用户不可查看邀请码，得到: %v", err)
+	}
+}
+
+func TestService_TeacherRole(t *testing.T) {
+	svc, repo, _, _, adminID := newInviteTestService(t, RegistrationPolicy{})
+	ctx := context.Background()
+
+	_, code, err := svc.CreateInvite(ctx, adminID, InviteOptions{Role: RoleTeacher})
+	if err != nil {
+		t.Fatalf("创建教师邀请码失败: %v", err)
+	}
+	result, err := svc.Signup(ctx, code, "invited_teacher", "TestPass123!")
+	if err != nil || !result.User.IsTeacher || result.User.IsAdmin {
+		t.Fatalf("应按邀请码创建教师: %+v, %v", result, err)
+	}
+
+	memberID, _ := repo.Create(ctx, &User{Username: "future_teacher", PasswordHash: hashOrFail(t, "TestPass123!")})
+	if _, err := svc.SetTeacher(ctx, result.User.ID, memberID, true); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("非管理员不可授予教师角色，得到: %v", err)
+	}
+	updated, err := svc.SetTeacher(ctx, adminID, memberID, true)
+	if err != nil || !updated.IsTeacher {
+		t.Fatalf("管理员授予教师角色失败: %+v, %v", updated, err)
+	}
+	if stored, _ := repo.FindByID(ctx, memberID); !stored.IsTeacher {
+		t.Fatalf("教师角色应已保存")
+	}
+	if _, err := svc.SetTeacher(ctx, adminID, 9999, true); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("不存在的用户应返回 ErrUserNotFound，得到: %v", err)
+	}
+}
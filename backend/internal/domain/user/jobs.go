@@ -0,0 +1,36 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"go-study2/internal/domain/jobs"
+)
+
+const (
+	// CredentialCleanupJobName 为过期凭据清理任务的名称。
+	CredentialCleanupJobName = "token_cleanup"
+	// DefaultCredentialCleanupSchedule 默认每小时第 17 分钟执行，避开整点高峰。
+	DefaultCredentialCleanupSchedule = "17 * * * *"
+)
+
+// CredentialCleanupJob 返回定期删除过期短期凭据的后台任务，service 在每次执行时获取。
+func CredentialCleanupJob(schedule string, service func() (*Service, error)) jobs.Definition {
+	return jobs.Definition{
+		Name:        CredentialCleanupJobName,
+		Description: "删除过期的刷新令牌、两步验证挑战与外部登录状态",
+		Schedule:    schedule,
+		Run: func(ctx context.Context) (string, error) {
+			svc, err := service()
+			if err != nil {
+				return "", err
+			}
+			purge, err := svc.PurgeExpiredCredentials(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("refresh_tokens=%d mfa_challenges=%d login_states=%d",
+				purge.RefreshTokens, purge.MFAChallenges, purge.LoginStates), nil
+		},
+	}
+}
@@ -0,0 +1,376 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/tracing"
+	"go-study2/internal/pkg/totp"
+)
+
+const (
+	defaultMFAChallengeTTL = 5 * time.Minute
+	maxMFAChallengeAttempt = 5
+	recoveryCodeCount      = 10
+)
+
+// MFAPolicy 描述两步验证策略。
+type MFAPolicy struct {
+	// Issuer 认证器 App 中显示的发行方。
+	Issuer string
+	// RequireForAdmin 为 true 时管理员必须启用两步验证。
+	RequireForAdmin bool
+	// ChallengeTTL 登录挑战有效期，零值使用 5 分钟。
+	ChallengeTTL time.Duration
+	// Skew 允许的时间步偏差。
+	Skew int
+}
+
+func (p MFAPolicy) requires(u *User) bool {
+	return p.RequireForAdmin && u != nil && u.IsAdmin
+}
+
+func (p MFAPolicy) challengeTTL() time.Duration {
+	if p.ChallengeTTL > 0 {
+		return p.ChallengeTTL
+	}
+	return defaultMFAChallengeTTL
+}
+
+func (p MFAPolicy) issuer() string {
+	if p.Issuer != "" {
+		return p.Issuer
+	}
+	return "go-study2"
+}
+
+// WithMFA 启用两步验证能力，未调用时登录流程保持单因素。
+func (s *Service) WithMFA(repo MFARepository, policy MFAPolicy) *Service {
+	s.mfaRepo = repo
+	s.mfaPolicy = policy
+	return s
+}
+
+// BeginMFAEnrollment 生成新的 TOTP 密钥并返回 otpauth 地址，需再调用 ConfirmMFAEnrollment 生效。
+func (s *Service) BeginMFAEnrollment(ctx context.Context, userID int64) (*MFAEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.BeginMFAEnrollment")
+	defer span.End()
+	record, err := s.mfaUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.mfaRepo.FindMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SaveMFA(ctx, MFASettings{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "mfa_enroll_started", userID, "ok", "")
+	return &MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.mfaPolicy.issuer(), record.Username, secret),
+	}, nil
+}
+
+// ConfirmMFAEnrollment 使用首个验证码确认绑定，返回仅展示一次的恢复码。
+func (s *Service) ConfirmMFAEnrollment(ctx context.Context, userID int64, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.ConfirmMFAEnrollment")
+	defer span.End()
+	if _, err := s.mfaUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	settings, err := s.mfaRepo.FindMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if settings.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, err := totp.Validate(settings.Secret, code, s.now(), s.mfaPolicy.Skew)
+	if err != nil {
+		audit.Record(ctx, "mfa_enroll_confirm", userID, "invalid_code", "")
+		return nil, ErrMFACodeInvalid
+	}
+
+	confirmedAt := s.now()
+	settings.Enabled = true
+	settings.LastUsedStep = step
+	settings.ConfirmedAt = &confirmedAt
+	if err := s.mfaRepo.SaveMFA(ctx, *settings); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "mfa_enabled", userID, "ok", "")
+	return codes, nil
+}
+
+// VerifyMFA 校验登录挑战与验证码（TOTP 或恢复码），通过后签发令牌对。
+func (s *Service) VerifyMFA(ctx context.Context, challengeToken, code string) (*AuthResult, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.VerifyMFA")
+	defer span.End()
+	if s.mfaRepo == nil || challengeToken == "" {
+		return nil, ErrMFAChallengeInvalid
+	}
+	challenge, err := s.mfaRepo.FindMFAChallenge(ctx, hashToken(challengeToken))
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, ErrMFAChallengeInvalid
+	}
+	if !challenge.ExpiresAt.After(s.now()) || challenge.Attempts >= maxMFAChallengeAttempt {
+		_ = s.mfaRepo.DeleteMFAChallenge(ctx, challenge.ID)
+		return nil, ErrMFAChallengeInvalid
+	}
+
+	ok, err := s.checkSecondFactor(ctx, challenge.UserID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.mfaRepo.IncrementMFAChallengeAttempts(ctx, challenge.ID); err != nil {
+			return nil, err
+		}
+		audit.Record(ctx, "login_mfa_failed", challenge.UserID, "invalid_code", "")
+		return nil, ErrMFACodeInvalid
+	}
+
+	if err := s.mfaRepo.DeleteMFAChallenge(ctx, challenge.ID); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrUserNotFound
+	}
+	tokens, err := s.issueTokenPair(ctx, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "login_mfa_success", existing.ID, "ok", "")
+	return &AuthResult{
+		User:   existing,
+		Tokens: *tokens,
+	}, nil
+}
+
+// DisableMFA 校验验证码后关闭两步验证；策略要求管理员启用时拒绝关闭。
+func (s *Service) DisableMFA(ctx context.Context, userID int64, code string) error {
+	ctx, span := tracing.Start(ctx, "user.Service.DisableMFA")
+	defer span.End()
+	record, err := s.mfaUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.mfaPolicy.requires(record) {
+		audit.Record(ctx, "mfa_disable_denied", userID, "permission_denied", "policy_required")
+		return ErrPermissionDenied
+	}
+	if err := s.requireSecondFactor(ctx, userID, code); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.DeleteMFA(ctx, userID); err != nil {
+		return err
+	}
+	audit.Record(ctx, "mfa_disabled", userID, "ok", "")
+	return nil
+}
+
+// RegenerateRecoveryCodes 校验验证码后作废旧恢复码并生成新的一组。
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.RegenerateRecoveryCodes")
+	defer span.End()
+	if _, err := s.mfaUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.requireSecondFactor(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "mfa_recovery_regenerated", userID, "ok", "")
+	return codes, nil
+}
+
+// MFAStatus 返回用户是否启用两步验证及剩余可用恢复码数量。
+func (s *Service) MFAStatus(ctx context.Context, userID int64) (bool, int, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.MFAStatus")
+	defer span.End()
+	if s.mfaRepo == nil {
+		return false, 0, nil
+	}
+	settings, err := s.mfaRepo.FindMFA(ctx, userID)
+	if err != nil || settings == nil || !settings.Enabled {
+		return false, 0, err
+	}
+	remaining, err := s.mfaRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, remaining, nil
+}
+
+// MFASetupRequired 判断用户是否受策略约束但尚未启用两步验证。
+func (s *Service) MFASetupRequired(ctx context.Context, u *User) (bool, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.MFASetupRequired")
+	defer span.End()
+	if !s.mfaPolicy.requires(u) {
+		return false, nil
+	}
+	settings, err := s.findMFA(ctx, u.ID)
+	if err != nil {
+		return false, err
+	}
+	return settings == nil || !settings.Enabled, nil
+}
+
+func (s *Service) mfaUser(ctx context.Context, userID int64) (*User, error) {
+	if userID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if s.mfaRepo == nil {
+		return nil, ErrMFANotEnabled
+	}
+	record, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrUserNotFound
+	}
+	return record, nil
+}
+
+func (s *Service) findMFA(ctx context.Context, userID int64) (*MFASettings, error) {
+	if s.mfaRepo == nil {
+		return nil, nil
+	}
+	return s.mfaRepo.FindMFA(ctx, userID)
+}
+
+func (s *Service) requireSecondFactor(ctx context.Context, userID int64, code string) error {
+	ok, err := s.checkSecondFactor(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMFACodeInvalid
+	}
+	return nil
+}
+
+// checkSecondFactor 依次尝试 TOTP 与恢复码；TOTP 时间步只能前进，防止同一验证码重放。
+func (s *Service) checkSecondFactor(ctx context.Context, userID int64, code string) (bool, error) {
+	settings, err := s.mfaRepo.FindMFA(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if settings == nil || !settings.Enabled {
+		return false, ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if step, err := totp.Validate(settings.Secret, code, s.now(), s.mfaPolicy.Skew); err == nil {
+		if step <= settings.LastUsedStep {
+			return false, nil
+		}
+		settings.LastUsedStep = step
+		return true, s.mfaRepo.SaveMFA(ctx, *settings)
+	} else if !errors.Is(err, totp.ErrInvalidCode) {
+		return false, err
+	}
+
+	if normalized := normalizeRecoveryCode(code); normalized != "" {
+		used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashToken(normalized))
+		if err != nil {
+			return false, err
+		}
+		if used {
+			audit.Record(ctx, "mfa_recovery_code_used", userID, "ok", "")
+		}
+		return used, nil
+	}
+	return false, nil
+}
+
+func (s *Service) issueMFAChallenge(ctx context.Context, userID int64) (*MFAChallenge, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(buf)
+	expiresAt := s.now().Add(s.mfaPolicy.challengeTTL())
+	if err := s.mfaRepo.SaveMFAChallenge(ctx, MFAChallengeRecord{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+	return &MFAChallenge{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+func (s *Service) replaceRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCode 生成形如 abcde-fghij 的 50 位熵恢复码。
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	raw := strings.ToLower(recoveryEncoding.EncodeToString(buf))[:10]
+	return raw[:5] + "-" + raw[5:], nil
+}
+
+// normalizeRecoveryCode 去除分隔符与大小写差异，非恢复码格式返回空串。
+func normalizeRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(normalized) != 10 {
+		return ""
+	}
+	return normalized
+}
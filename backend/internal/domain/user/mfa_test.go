@@ -0,0 +1,278 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appjwt "go-study2/internal/pkg/jwt"
+	"go-study2/internal/pkg/totp"
+)
+
+type mockMFARepo struct {
+	settings   map[int64]MFASettings
+	recovery   map[int64]map[string]bool
+	challenges map[string]MFAChallengeRecord
+	autoID     int64
+}
+
+func newMockMFARepo() *mockMFARepo {
+	return &mockMFARepo{
+		settings:   make(map[int64]MFASettings),
+		recovery:   make(map[int64]map[string]bool),
+		challenges: make(map[string]MFAChallengeRecord),
+		autoID:     1,
+	}
+}
+
+func (m *mockMFARepo) FindMFA(_ context.Context, userID int64) (*MFASettings, error) {
+	if s, ok := m.settings[userID]; ok {
+		clone := s
+		return &clone, nil
+	}
+	return nil, nil
+}
+
+func (m *mockMFARepo) SaveMFA(_ context.Context, settings MFASettings) error {
+	m.settings[settings.UserID] = settings
+	return nil
+}
+
+func (m *mockMFARepo) DeleteMFA(_ context.Context, userID int64) error {
+	delete(m.settings, userID)
+	delete(m.recovery, userID)
+	return nil
+}
+
+func (m *mockMFARepo) ReplaceRecoveryCodes(_ context.Context, userID int64, codeHashes []string) error {
+	codes := make(map[string]bool, len(codeHashes))
+	for _, h := range codeHashes {
+		codes[h] = false
+	}
+	m.recovery[userID] = codes
+	return nil
+}
+
+func (m *mockMFARepo) UseRecoveryCode(_ context.Context, userID int64, codeHash string) (bool, error) {
+	used, ok := m.recovery[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	m.recovery[userID][codeHash] = true
+	return true, nil
+}
+
+func (m *mockMFARepo) CountRecoveryCodes(_ context.Context, userID int64) (int, error) {
+	count := 0
+	for _, used := range m.recovery[userID] {
+		if !used {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockMFARepo) SaveMFAChallenge(_ context.Context, challenge MFAChallengeRecord) error {
+	challenge.ID = m.autoID
+	m.autoID++
+	m.challenges[challenge.TokenHash] = challenge
+	return nil
+}
+
+func (m *mockMFARepo) FindMFAChallenge(_ context.Context, tokenHash string) (*MFAChallengeRecord, error) {
+	if c, ok := m.challenges[tokenHash]; ok {
+		clone := c
+		return &clone, nil
+	}
+	return nil, nil
+}
+
+func (m *mockMFARepo) IncrementMFAChallengeAttempts(_ context.Context, id int64) error {
+	for hash, c := range m.challenges {
+		if c.ID == id {
+			c.Attempts++
+			m.challenges[hash] = c
+		}
+	}
+	return nil
+}
+
+func (m *mockMFARepo) DeleteMFAChallenge(_ context.Context, id int64) error {
+	for hash, c := range m.challenges {
+		if c.ID == id {
+			delete(m.challenges, hash)
+		}
+	}
+	return nil
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newMFATestService(t *testing.T, policy MFAPolicy) (*Service, *mockRepo, *mockMFARepo, *fakeClock) {
+	t.Helper()
+	_ = appjwt.Configure(appjwt.Options{
+		Secret:             "abcdef0123456789",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: time.Hour,
+	})
+	repo := newMockRepo()
+	mfaRepo := newMockMFARepo()
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	svc := NewService(repo, time.Hour, time.Hour).WithMFA(mfaRepo, policy).WithClock(clock.Now)
+	return svc, repo, mfaRepo, clock
+}
+
+func enrollOrFail(t *testing.T, svc *Service, clock *fakeClock, userID int64) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	enrollment, err := svc.BeginMFAEnrollment(ctx, userID)
+	if err != nil {
+		t.Fatalf("开始绑定失败: %v", err)
+	}
+	code, _ := totp.Generate(enrollment.Secret, clock.Now())
+	recovery, err := svc.ConfirmMFAEnrollment(ctx, userID, code)
+	if err != nil {
+		t.Fatalf("确认绑定失败: %v", err)
+	}
+	return enrollment.Secret, recovery
+}
+
+func TestService_MFA_LoginRequiresSecondStep(t *testing.T) {
+	svc, repo, _, clock := newMFATestService(t, MFAPolicy{Issuer: "go-study2", Skew: 1})
+	ctx := context.Background()
+	userID, _ := repo.Create(ctx, &User{Username: "mfa_user", PasswordHash: hashOrFail(t, "TestPass123!")})
+
+	secret, recovery := enrollOrFail(t, svc, clock, userID)
+	if len(recovery) != recoveryCodeCount {
+		t.Fatalf("恢复码数量不正确: %d", len(recovery))
+	}
+
+	login, err := svc.Login(ctx, "mfa_user", "TestPass123!")
+	if err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	if login.MFAChallenge == nil || login.Tokens.AccessToken != "" {
+		t.Fatalf("启用两步验证后登录应只返回挑战")
+	}
+
+	// 与绑定确认同一时间步的验证码视为重放
+	sameStep, _ := totp.Generate(secret, clock.Now())
+	if _, err := svc.VerifyMFA(ctx, login.MFAChallenge.Token, sameStep); !errors.Is(err, ErrMFACodeInvalid) {
+		t.Fatalf("重放验证码应被拒绝，得到: %v", err)
+	}
+
+	clock.Advance(totp.Period * time.Second)
+	next, _ := totp.Generate(secret, clock.Now())
+	result, err := svc.VerifyMFA(ctx, login.MFAChallenge.Token, next)
+	if err != nil {
+		t.Fatalf("二次验证失败: %v", err)
+	}
+	if result.Tokens.AccessToken == "" || result.Tokens.RefreshToken == "" {
+		t.Fatalf("二次验证后应签发令牌")
+	}
+
+	if _, err := svc.VerifyMFA(ctx, login.MFAChallenge.Token, next); !errors.Is(err, ErrMFAChallengeInvalid) {
+		t.Fatalf("挑战只能使用一次，得到: %v", err)
+	}
+}
+
+func TestService_MFA_RecoveryCodeIsOneTime(t *testing.T) {
+	svc, repo, _, clock := newMFATestService(t, MFAPolicy{Skew: 1})
+	ctx := context.Background()
+	userID, _ := repo.Create(ctx, &User{Username: "mfa_recovery", PasswordHash: hashOrFail(t, "TestPass123!")})
+	_, recovery := enrollOrFail(t, svc, clock, userID)
+
+	first, _ := svc.Login(ctx, "mfa_recovery", "TestPass123!")
+	if _, err := svc.VerifyMFA(ctx, first.MFAChallenge.Token, recovery[0]); err != nil {
+		t.Fatalf("恢复码登录失败: %v", err)
+	}
+
+	second, _ := svc.Login(ctx, "mfa_recovery", "TestPass123!")
+	if _, err := svc.VerifyMFA(ctx, second.MFAChallenge.Token, recovery[0]); !errors.Is(err, ErrMFACodeInvalid) {
+		t.Fatalf("恢复码不可重复使用，得到: %v", err)
+	}
+
+	_, remaining, _ := svc.MFAStatus(ctx, userID)
+	if remaining != recoveryCodeCount-1 {
+		t.Fatalf("剩余恢复码数量不正确: %d", remaining)
+	}
+}
+
+func TestService_MFA_ChallengeExpiresAndLimitsAttempts(t *testing.T) {
+	svc, repo, _, clock := newMFATestService(t, MFAPolicy{ChallengeTTL: time.Minute, Skew: 1})
+	ctx := context.Background()
+	userID, _ := repo.Create(ctx, &User{Username: "mfa_expire", PasswordHash: hashOrFail(t, "TestPass123!")})
+	secret, _ := enrollOrFail(t, svc, clock, userID)
+
+	login, _ := svc.Login(ctx, "mfa_expire", "TestPass123!")
+	clock.Advance(2 * time.Minute)
+	code, _ := totp.Generate(secret, clock.Now())
+	if _, err := svc.VerifyMFA(ctx, login.MFAChallenge.Token, code); !errors.Is(err, ErrMFAChallengeInvalid) {
+		t.Fatalf("过期挑战应被拒绝，得到: %v", err)
+	}
+
+	login, _ = svc.Login(ctx, "mfa_expire", "TestPass123!")
+	for i := 0; i < maxMFAChallengeAttempt; i++ {
+		if _, err := svc.VerifyMFA(ctx, login.MFAChallenge.Token, "000000"); !errors.Is(err, ErrMFACodeInvalid) {
+			t.Fatalf("错误验证码应返回 ErrMFACodeInvalid，得到: %v", err)
+		}
+	}
+	clock.Advance(totp.Period * time.Second)
+	code, _ = totp.Generate(secret, clock.Now())
+	if _, err := svc.VerifyMFA(ctx, login.MFAChallenge.Token, code); !errors.Is(err, ErrMFAChallengeInvalid) {
+		t.Fatalf("超过尝试次数后挑战应失效，得到: %v", err)
+	}
+}
+
+func TestService_MFA_AdminPolicy(t *testing.T) {
+	svc, repo, _, clock := newMFATestService(t, MFAPolicy{RequireForAdmin: true, Skew: 1})
+	ctx := context.Background()
+	adminID, _ := repo.Create(ctx, &User{Username: "mfa_admin", PasswordHash: hashOrFail(t, "TestPass123!"), IsAdmin: true})
+
+	login, err := svc.Login(ctx, "mfa_admin", "TestPass123!")
+	if err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	if !login.MFASetupRequired {
+		t.Fatalf("未绑定的管理员应被要求启用两步验证")
+	}
+
+	secret, _ := enrollOrFail(t, svc, clock, adminID)
+	admin, _ := repo.FindByID(ctx, adminID)
+	if required, _ := svc.MFASetupRequired(ctx, admin); required {
+		t.Fatalf("绑定后不应再要求启用")
+	}
+
+	clock.Advance(totp.Period * time.Second)
+	code, _ := totp.Generate(secret, clock.Now())
+	if err := svc.DisableMFA(ctx, adminID, code); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("策略要求时管理员不可关闭两步验证，得到: %v", err)
+	}
+}
+
+func TestService_MFA_ConfirmRejectsWrongCode(t *testing.T) {
+	svc, repo, _, _ := newMFATestService(t, MFAPolicy{Skew: 1})
+	ctx := context.Background()
+	userID, _ := repo.Create(ctx, &User{Username: "mfa_wrong", PasswordHash: hashOrFail(t, "TestPass123!")})
+
+	if _, err := svc.ConfirmMFAEnrollment(ctx, userID, "123456"); !errors.Is(err, ErrMFANotEnrolled) {
+		t.Fatalf("未开始绑定应返回 ErrMFANotEnrolled，得到: %v", err)
+	}
+	if _, err := svc.BeginMFAEnrollment(ctx, userID); err != nil {
+		t.Fatalf("开始绑定失败: %v", err)
+	}
+	if _, err := svc.ConfirmMFAEnrollment(ctx, userID, "abcdef"); !errors.Is(err, ErrMFACodeInvalid) {
+		t.Fatalf("错误验证码应返回 ErrMFACodeInvalid，得到: %v", err)
+	}
+	login, err := svc.Login(ctx, "mfa_wrong", "TestPass123!")
+	if err != nil || login.MFAChallenge != nil || login.Tokens.AccessToken == "" {
+		t.Fatalf("未确认绑定时应保持单因素登录")
+	}
+}
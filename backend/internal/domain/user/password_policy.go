@@ -0,0 +1,107 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/pkg/password"
+)
+
+// maxPasswordHistory 历史密码保留上限，避免逐条 bcrypt 比对耗时过长。
+const maxPasswordHistory = 24
+
+// PasswordPolicy 描述密码有效期与历史复用限制，强度规则由 password 包的全局策略负责。
+type PasswordPolicy struct {
+	// MaxAge 密码最长使用时间，到期后登录将被要求先修改密码；零值表示不过期。
+	MaxAge time.Duration
+	// HistorySize 禁止复用的历史密码数量，当前密码始终不可复用。
+	HistorySize int
+}
+
+func (p PasswordPolicy) historySize() int {
+	if p.HistorySize > maxPasswordHistory {
+		return maxPasswordHistory
+	}
+	return p.HistorySize
+}
+
+// WithPasswordPolicy 启用密码有效期与历史复用限制。
+func (s *Service) WithPasswordPolicy(repo PasswordHistoryRepository, policy PasswordPolicy) *Service {
+	s.passwordHistory = repo
+	s.passwordPolicy = policy
+	return s
+}
+
+// passwordExpired 判断用户密码是否已超过最长使用时间。
+func (s *Service) passwordExpired(u *User) bool {
+	if s.passwordPolicy.MaxAge <= 0 || u == nil {
+		return false
+	}
+	changedAt := u.PasswordChangedAt
+	if changedAt.IsZero() {
+		changedAt = u.CreatedAt
+	}
+	if changedAt.IsZero() {
+		return false
+	}
+	return s.now().Sub(changedAt) >= s.passwordPolicy.MaxAge
+}
+
+// checkNewPassword 校验新密码强度，并拒绝与当前密码或最近历史密码相同的密码。
+func (s *Service) checkNewPassword(ctx context.Context, record *User, rawPassword string) error {
+	violations := password.CurrentPolicy().Check(rawPassword)
+	if rawPassword != "" {
+		reused, err := s.passwordReused(ctx, record, rawPassword)
+		if err != nil {
+			return err
+		}
+		if reused {
+			message := "新密码不能与当前密码相同"
+			if size := s.passwordPolicy.historySize(); size > 0 && s.passwordHistory != nil {
+				message = fmt.Sprintf("新密码不能与当前及最近 %d 次使用过的密码相同", size)
+			}
+			violations = append(violations, password.Violation{Rule: password.RuleReused, Message: message})
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	rules := make([]string, 0, len(violations))
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	audit.RecordFields(ctx, "password_change_rejected", record.ID, "blocked", "", audit.Fields{"rules": rules})
+	return password.NewPolicyError(violations...)
+}
+
+func (s *Service) passwordReused(ctx context.Context, record *User, rawPassword string) (bool, error) {
+	if password.Verify(record.PasswordHash, rawPassword) == nil {
+		return true, nil
+	}
+	size := s.passwordPolicy.historySize()
+	if s.passwordHistory == nil || size <= 0 {
+		return false, nil
+	}
+	hashes, err := s.passwordHistory.RecentPasswordHashes(ctx, record.ID, size)
+	if err != nil {
+		return false, err
+	}
+	for _, hashed := range hashes {
+		if password.Verify(hashed, rawPassword) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rememberPassword 将被替换的密码哈希写入历史记录。
+func (s *Service) rememberPassword(ctx context.Context, userID int64, previousHash string) error {
+	size := s.passwordPolicy.historySize()
+	if s.passwordHistory == nil || size <= 0 || previousHash == "" {
+		return nil
+	}
+	return s.passwordHistory.AddPasswordHistory(ctx, userID, previousHash, size)
+}
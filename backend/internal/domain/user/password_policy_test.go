@@ -0,0 +1,167 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appjwt "go-study2/internal/pkg/jwt"
+	"go-study2/internal/pkg/password"
+)
+
+type mockPasswordHistoryRepo struct {
+	hashes map[int64][]string
+}
+
+func newMockPasswordHistoryRepo() *mockPasswordHistoryRepo {
+	return &mockPasswordHistoryRepo{hashes: make(map[int64][]string)}
+}
+
+func (m *mockPasswordHistoryRepo) RecentPasswordHashes(_ context.Context, userID int64, limit int) ([]string, error) {
+	hashes := m.hashes[userID]
+	if len(hashes) > limit {
+		hashes = hashes[:limit]
+	}
+	return append([]string(nil), hashes...), nil
+}
+
+func (m *mockPasswordHistoryRepo) AddPasswordHistory(_ context.Context, userID int64, passwordHash string, keep int) error {
+	hashes := append([]string{passwordHash}, m.hashes[userID]...)
+	if len(hashes) > keep {
+		hashes = hashes[:keep]
+	}
+	m.hashes[userID] = hashes
+	return nil
+}
+
+func newPasswordPolicyTestService(t *testing.T, policy PasswordPolicy) (*Service, *mockRepo, *mockPasswordHistoryRepo, *fakeClock) {
+	t.Helper()
+	_ = appjwt.Configure(appjwt.Options{
+		Secret:             "abcdef0123456789",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: time.Hour,
+	})
+	repo := newMockRepo()
+	history := newMockPasswordHistoryRepo()
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	svc := NewService(repo, time.Hour, time.Hour).WithPasswordPolicy(history, policy).WithClock(clock.Now)
+	return svc, repo, history, clock
+}
+
+func violationRules(t *testing.T, err error) []string {
+	t.Helper()
+	var policyErr *password.PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("应返回密码策略错误，得到: %v", err)
+	}
+	rules := make([]string, 0, len(policyErr.Violations))
+	for _, v := range policyErr.Violations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestChangePassword_RejectsCurrentPassword(t *testing.T) {
+	_ = appjwt.Configure(appjwt.Options{
+		Secret:             "abcdef0123456789",
+		AccessTokenExpiry:  time.Hour,
+		RefreshTokenExpiry: time.Hour,
+	})
+	repo := newMockRepo()
+	svc := NewService(repo, time.Hour, time.Hour)
+	ctx := context.Background()
+	userID, _ := repo.Create(ctx, &User{Username: "same_pwd", PasswordHash: hashOrFail(t, "Current123!")})
+
+	rules := violationRules(t, svc.ChangePassword(ctx, userID, "Current123!", "Current123!"))
+	if len(rules) != 1 || rules[0] != password.RuleReused {
+		t.Fatalf("未配置历史时仍应拒绝当前密码，得到: %v", rules)
+	}
+}
+
+func TestChangePassword_BlocksRecentHistory(t *testing.T) {
+	svc, repo, history, _ := newPasswordPolicyTestService(t, PasswordPolicy{HistorySize: 2})
+	ctx := context.Background()
+	userID, _ := repo.Create(ctx, &User{Username: "history", PasswordHash: hashOrFail(t, "First123!")})
+
+	steps := [][2]string{{"First123!", "Second123!"}, {"Second123!", "Third123!"}}
+	for _, step := range steps {
+		if err := svc.ChangePassword(ctx, userID, step[0], step[1]); err != nil {
+			t.Fatalf("修改密码失败: %v", err)
+		}
+	}
+	if got := len(history.hashes[userID]); got != 2 {
+		t.Fatalf("应保留 2 条历史密码，得到 %d", got)
+	}
+
+	rules := violationRules(t, svc.ChangePassword(ctx, userID, "Third123!", "First123!"))
+	if len(rules) != 1 || rules[0] != password.RuleReused {
+		t.Fatalf("最近使用过的密码应被拒绝，得到: %v", rules)
+	}
+
+	if err := svc.ChangePassword(ctx, userID, "Third123!", "Fourth123!"); err != nil {
+		t.Fatalf("修改密码失败: %v", err)
+	}
+	// First123! 已超出最近 2 条历史，可以重新使用
+	if err := svc.ChangePassword(ctx, userID, "Fourth123!", "First123!"); err != nil {
+		t.Fatalf("超出历史范围的密码应允许使用: %v", err)
+	}
+}
+
+func TestChangePassword_ReportsAllViolations(t *testing.T) {
+	svc, repo, _, _ := newPasswordPolicyTestService(t, PasswordPolicy{})
+	ctx := context.Background()
+	userID, _ := repo.Create(ctx, &User{Username: "weak_change", PasswordHash: hashOrFail(t, "Current123!")})
+
+	rules := violationRules(t, svc.ChangePassword(ctx, userID, "Current123!", "password"))
+	want := map[string]bool{password.RuleUpper: true, password.RuleDigit: true, password.RuleSymbol: true}
+	if len(rules) != len(want) {
+		t.Fatalf("违规规则数量不符: %v", rules)
+	}
+	for _, rule := range rules {
+		if !want[rule] {
+			t.Fatalf("出现意外规则 %s", rule)
+		}
+	}
+}
+
+func TestLogin_ExpiredPasswordRequiresChange(t *testing.T) {
+	svc, repo, _, clock := newPasswordPolicyTestService(t, PasswordPolicy{MaxAge: 90 * 24 * time.Hour})
+	ctx := context.Background()
+	userID, _ := repo.Create(ctx, &User{
+		Username:          "aging",
+		PasswordHash:      hashOrFail(t, "Aging123!"),
+		PasswordChangedAt: clock.Now().Add(-89 * 24 * time.Hour),
+	})
+
+	result, err := svc.Login(ctx, "aging", "Aging123!")
+	if err != nil || result.User.MustChangePassword {
+		t.Fatalf("未过期的密码不应要求修改: %v", err)
+	}
+
+	clock.Advance(2 * 24 * time.Hour)
+	result, err = svc.Login(ctx, "aging", "Aging123!")
+	if err != nil {
+		t.Fatalf("过期密码仍应允许登录: %v", err)
+	}
+	if !result.User.MustChangePassword {
+		t.Fatalf("过期密码登录后应要求修改密码")
+	}
+	if stored, _ := repo.FindByID(ctx, userID); !stored.MustChangePassword {
+		t.Fatalf("需改密标记应持久化")
+	}
+}
+
+func TestLogin_DoesNotEnforceStrengthPolicy(t *testing.T) {
+	svc, repo, _, _ := newPasswordPolicyTestService(t, PasswordPolicy{})
+	ctx := context.Background()
+	legacy, err := password.HashUnchecked("legacy")
+	if err != nil {
+		t.Fatalf("hash 失败: %v", err)
+	}
+	_, _ = repo.Create(ctx, &User{Username: "legacy_user", PasswordHash: legacy})
+
+	if _, err := svc.Login(ctx, "legacy_user", "legacy"); err != nil {
+		t.Fatalf("策略收紧前设置的密码应仍可登录: %v", err)
+	}
+}
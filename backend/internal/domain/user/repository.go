@@ -1,6 +1,9 @@
 package user
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository 定义用户领域所需的持久化接口。
 type Repository interface {
@@ -11,4 +14,70 @@ type Repository interface {
 	DeleteRefreshTokensByUser(ctx context.Context, userID int64) error
 	FindRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error)
 	UpdatePasswordAndFlag(ctx context.Context, userID int64, passwordHash string, mustChange bool) error
+	// MarkMustChangePassword 仅设置需改密标记，不改变密码设置时间。
+	MarkMustChangePassword(ctx context.Context, userID int64) error
+	UpdateTeacherFlag(ctx context.Context, userID int64, isTeacher bool) error
+}
+
+// PasswordHistoryRepository 定义历史密码哈希的持久化接口。
+type PasswordHistoryRepository interface {
+	// RecentPasswordHashes 按时间倒序返回最近 limit 个历史密码哈希。
+	RecentPasswordHashes(ctx context.Context, userID int64, limit int) ([]string, error)
+	// AddPasswordHistory 追加历史密码哈希，并只保留最近 keep 条。
+	AddPasswordHistory(ctx context.Context, userID int64, passwordHash string, keep int) error
+}
+
+// MFARepository 定义两步验证所需的持久化接口。
+type MFARepository interface {
+	FindMFA(ctx context.Context, userID int64) (*MFASettings, error)
+	SaveMFA(ctx context.Context, settings MFASettings) error
+	DeleteMFA(ctx context.Context, userID int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int64) (int, error)
+	SaveMFAChallenge(ctx context.Context, challenge MFAChallengeRecord) error
+	FindMFAChallenge(ctx context.Context, tokenHash string) (*MFAChallengeRecord, error)
+	IncrementMFAChallengeAttempts(ctx context.Context, id int64) error
+	DeleteMFAChallenge(ctx context.Context, id int64) error
+}
+
+// InviteRepository 定义邀请码的持久化接口。
+type InviteRepository interface {
+	CreateInvite(ctx context.Context, invite *Invite) (int64, error)
+	FindInviteByHash(ctx context.Context, codeHash string) (*Invite, error)
+	FindInviteByID(ctx context.Context, id int64) (*Invite, error)
+	ListInvites(ctx context.Context) ([]Invite, error)
+	RevokeInvite(ctx context.Context, id int64) error
+	// ConsumeInvite 在未撤销、未过期且未用尽时原子地占用一次名额，返回是否成功。
+	ConsumeInvite(ctx context.Context, id int64, now time.Time) (bool, error)
+	ReleaseInvite(ctx context.Context, id int64) error
+	SaveRedemption(ctx context.Context, redemption InviteRedemption) error
+	ListRedemptions(ctx context.Context, inviteID int64) ([]InviteRedemption, error)
+}
+
+// IdentityRepository 定义外部身份绑定与登录状态的持久化接口。
+type IdentityRepository interface {
+	FindIdentity(ctx context.Context, provider, subject string) (*ExternalIdentity, error)
+	CreateIdentity(ctx context.Context, identity ExternalIdentity) error
+	TouchIdentity(ctx context.Context, id int64, email string) error
+	SaveLoginState(ctx context.Context, state ExternalLoginState) error
+	// TakeLoginState 查询并删除登录状态，保证每个 state 只能使用一次。
+	TakeLoginState(ctx context.Context, stateHash string) (*ExternalLoginState, error)
+}
+
+// CredentialCleanupRepository 定义过期凭据清理的持久化接口，返回删除的行数。
+type CredentialCleanupRepository interface {
+	DeleteExpiredRefreshTokens(ctx context.Context, now time.Time) (int64, error)
+	DeleteExpiredMFAChallenges(ctx context.Context, now time.Time) (int64, error)
+	DeleteExpiredLoginStates(ctx context.Context, now time.Time) (int64, error)
+}
+
+// AccessTokenRepository 定义个人访问令牌的持久化接口。
+type AccessTokenRepository interface {
+	CreateAccessToken(ctx context.Context, token *AccessToken) (int64, error)
+	ListAccessTokens(ctx context.Context, userID int64) ([]AccessToken, error)
+	FindAccessTokenByHash(ctx context.Context, tokenHash string) (*AccessToken, error)
+	// RevokeAccessToken 撤销属于该用户的令牌，返回是否命中。
+	RevokeAccessToken(ctx context.Context, userID, id int64) (bool, error)
+	TouchAccessToken(ctx context.Context, id int64, ip string, at time.Time) error
 }
@@ -5,10 +5,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"regexp"
 	"time"
 
 	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/infrastructure/tracing"
 	appjwt "go-study2/internal/pkg/jwt"
 	"go-study2/internal/pkg/password"
 )
@@ -23,6 +26,17 @@ var (
 	ErrUserNotFound        = errors.New("用户不存在")
 	ErrPermissionDenied    = errors.New("权限不足")
 	ErrMustChangePassword  = errors.New("需要先修改密码")
+	ErrMFACodeInvalid      = errors.New("两步验证码无效")
+	ErrMFAChallengeInvalid = errors.New("两步验证挑战无效或已过期")
+	ErrMFANotEnrolled      = errors.New("尚未开始绑定两步验证")
+	ErrMFAAlreadyEnabled   = errors.New("两步验证已启用")
+	ErrMFANotEnabled       = errors.New("两步验证未启用")
+	ErrMFASetupRequired    = errors.New("需要先启用两步验证")
+	ErrInviteInvalid       = errors.New("邀请码无效、已过期或已用尽")
+	ErrRegistrationClosed  = errors.New("当前未开放注册，请使用邀请码")
+	ErrExternalLoginState  = errors.New("外部登录状态无效或已过期")
+	ErrExternalNotLinked   = errors.New("外部账号未关联本地用户")
+	ErrAccessTokenNotFound = errors.New("访问令牌不存在")
 )
 
 var (
@@ -37,8 +51,13 @@ const (
 
 type createUserOptions struct {
 	isAdmin            bool
+	isTeacher          bool
 	mustChangePassword bool
 	issueTokens        bool
+	// skipPolicy 跳过密码强度校验，仅用于强制改密的预置账号。
+	skipPolicy bool
+	// source 为账号来源，随 user.created 事件发布。
+	source string
 }
 
 // Service 封装用户注册、登录、刷新令牌等业务能力。
@@ -46,6 +65,22 @@ type Service struct {
 	repo       Repository
 	accessTTL  time.Duration
 	refreshTTL time.Duration
+	mfaRepo    MFARepository
+	mfaPolicy  MFAPolicy
+	inviteRepo InviteRepository
+	// classes 为 nil 时邀请码不能绑定班级。
+	classes    ClassEnroller
+	identities IdentityRepository
+	tokens     AccessTokenRepository
+	cleanup    CredentialCleanupRepository
+	// passwordHistory 为 nil 时仅禁止复用当前密码。
+	passwordHistory PasswordHistoryRepository
+	passwordPolicy  PasswordPolicy
+	// registration 控制公开注册入口是否允许无邀请码注册。
+	registration RegistrationPolicy
+	// events 为 nil 时不发布领域事件。
+	events eventbus.Publisher
+	now    func() time.Time
 }
 
 // NewService 创建服务实例，需传入仓储实现与令牌过期时间。
@@ -54,27 +89,36 @@ func NewService(repo Repository, accessTTL, refreshTTL time.Duration) *Service {
 		repo:       repo,
 		accessTTL:  accessTTL,
 		refreshTTL: refreshTTL,
+		now:        time.Now,
 	}
 }
 
+// WithClock 注入时钟，便于测试令牌与验证码的过期逻辑。
+func (s *Service) WithClock(now func() time.Time) *Service {
+	if now != nil {
+		s.now = now
+	}
+	return s
+}
+
+// WithEvents 设置领域事件发布者，用于对外通知新用户创建。
+func (s *Service) WithEvents(pub eventbus.Publisher) *Service {
+	s.events = pub
+	return s
+}
+
 // Register 由管理员创建新用户，返回令牌对与用户信息。
 func (s *Service) Register(ctx context.Context, operatorID int64, username, rawPassword string) (*AuthResult, error) {
-	if operatorID <= 0 {
-		audit.Record(ctx, "register_denied", 0, "permission_denied", "missing_operator")
-		return nil, ErrPermissionDenied
-	}
-	operator, err := s.repo.FindByID(ctx, operatorID)
-	if err != nil {
+	ctx, span := tracing.Start(ctx, "user.Service.Register")
+	defer span.End()
+	if err := s.requireAdmin(ctx, operatorID, "register_denied"); err != nil {
 		return nil, err
 	}
-	if operator == nil || !operator.IsAdmin {
-		audit.Record(ctx, "register_denied", operatorID, "permission_denied", "non_admin_operator")
-		return nil, ErrPermissionDenied
-	}
 	result, regErr := s.registerUser(ctx, username, rawPassword, createUserOptions{
 		isAdmin:            false,
 		mustChangePassword: false,
 		issueTokens:        true,
+		source:             "admin",
 	})
 	if regErr == nil {
 		audit.Record(ctx, "register_success", operatorID, "ok", username)
@@ -84,6 +128,8 @@ func (s *Service) Register(ctx context.Context, operatorID int64, username, rawP
 
 // EnsureDefaultAdmin 确保默认管理员存在，幂等且不覆盖已存在账户。
 func (s *Service) EnsureDefaultAdmin(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "user.Service.EnsureDefaultAdmin")
+	defer span.End()
 	existing, err := s.repo.FindByUsername(ctx, DefaultAdminUsername)
 	if err != nil {
 		return err
@@ -96,6 +142,8 @@ func (s *Service) EnsureDefaultAdmin(ctx context.Context) error {
 		isAdmin:            true,
 		mustChangePassword: true,
 		issueTokens:        false,
+		skipPolicy:         true,
+		source:             "bootstrap",
 	})
 	if err == nil && created != nil && created.User != nil {
 		audit.Record(ctx, "default_admin_created", created.User.ID, "ok", "")
@@ -105,7 +153,10 @@ func (s *Service) EnsureDefaultAdmin(ctx context.Context) error {
 
 // Login 验证账号密码并返回新的令牌对。
 func (s *Service) Login(ctx context.Context, username, rawPassword string) (*AuthResult, error) {
-	if err := s.validateCredential(username, rawPassword); err != nil {
+	ctx, span := tracing.Start(ctx, "user.Service.Login")
+	defer span.End()
+	// 登录不校验密码强度，策略收紧后旧密码仍可登录并按需强制修改
+	if !usernamePattern.MatchString(username) || rawPassword == "" {
 		return nil, ErrInvalidInput
 	}
 
@@ -121,19 +172,51 @@ func (s *Service) Login(ctx context.Context, username, rawPassword string) (*Aut
 		return nil, ErrInvalidCredential
 	}
 
+	if !existing.MustChangePassword && s.passwordExpired(existing) {
+		if err := s.repo.MarkMustChangePassword(ctx, existing.ID); err != nil {
+			return nil, err
+		}
+		existing.MustChangePassword = true
+		audit.Record(ctx, "password_expired", existing.ID, "ok", "")
+	}
+
+	return s.completeLogin(ctx, existing)
+}
+
+// completeLogin 在第一因素通过后决定签发令牌或返回两步验证挑战。
+func (s *Service) completeLogin(ctx context.Context, existing *User) (*AuthResult, error) {
+	settings, err := s.findMFA(ctx, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil && settings.Enabled {
+		challenge, err := s.issueMFAChallenge(ctx, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		audit.Record(ctx, "login_mfa_challenge", existing.ID, "pending", "")
+		return &AuthResult{
+			User:         existing,
+			MFAChallenge: challenge,
+		}, nil
+	}
+
 	tokens, err := s.issueTokenPair(ctx, existing.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &AuthResult{
-		User:   existing,
-		Tokens: *tokens,
+		User:             existing,
+		Tokens:           *tokens,
+		MFASetupRequired: s.mfaPolicy.requires(existing),
 	}, nil
 }
 
 // Refresh 根据刷新令牌换取新的令牌对。
 func (s *Service) Refresh(ctx context.Context, refreshToken string) (*AuthResult, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.Refresh")
+	defer span.End()
 	if refreshToken == "" {
 		return nil, ErrRefreshTokenInvalid
 	}
@@ -147,7 +230,7 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string) (*AuthResult
 		return nil, ErrRefreshTokenInvalid
 	}
 
-	now := time.Now()
+	now := s.now()
 	if !record.ExpiresAt.IsZero() && record.ExpiresAt.Before(now) {
 		return nil, ErrRefreshTokenExpired
 	}
@@ -192,6 +275,8 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string) (*AuthResult
 
 // Logout 移除用户关联的刷新令牌。
 func (s *Service) Logout(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "user.Service.Logout")
+	defer span.End()
 	if userID <= 0 {
 		return ErrInvalidInput
 	}
@@ -200,6 +285,28 @@ func (s *Service) Logout(ctx context.Context, userID int64) error {
 
 // Profile 查询用户基础信息。
 func (s *Service) Profile(ctx context.Context, userID int64) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.Profile")
+	defer span.End()
+	if userID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	record, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrUserNotFound
+	}
+	return record, nil
+}
+
+// SetTeacher 由管理员授予或撤销用户的教师角色，教师可以创建班级。
+func (s *Service) SetTeacher(ctx context.Context, operatorID, userID int64, isTeacher bool) (*User, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.SetTeacher")
+	defer span.End()
+	if err := s.requireAdmin(ctx, operatorID, "teacher_role_denied"); err != nil {
+		return nil, err
+	}
 	if userID <= 0 {
 		return nil, ErrInvalidInput
 	}
@@ -210,11 +317,18 @@ func (s *Service) Profile(ctx context.Context, userID int64) (*User, error) {
 	if record == nil {
 		return nil, ErrUserNotFound
 	}
+	if err := s.repo.UpdateTeacherFlag(ctx, userID, isTeacher); err != nil {
+		return nil, err
+	}
+	record.IsTeacher = isTeacher
+	audit.Record(ctx, "teacher_role_changed", operatorID, "ok", fmt.Sprintf("user_id=%d teacher=%t", userID, isTeacher))
 	return record, nil
 }
 
 // ChangePassword 修改密码并重置需改密标记，清理历史刷新令牌。
 func (s *Service) ChangePassword(ctx context.Context, userID int64, oldPassword, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "user.Service.ChangePassword")
+	defer span.End()
 	if userID <= 0 {
 		return ErrInvalidInput
 	}
@@ -230,8 +344,8 @@ func (s *Service) ChangePassword(ctx context.Context, userID int64, oldPassword,
 	if err := password.Verify(record.PasswordHash, oldPassword); err != nil {
 		return ErrInvalidCredential
 	}
-	if err := password.Validate(newPassword); err != nil {
-		return ErrInvalidInput
+	if err := s.checkNewPassword(ctx, record, newPassword); err != nil {
+		return err
 	}
 
 	hashed, err := password.Hash(newPassword)
@@ -242,6 +356,9 @@ func (s *Service) ChangePassword(ctx context.Context, userID int64, oldPassword,
 	if err := s.repo.UpdatePasswordAndFlag(ctx, userID, hashed, false); err != nil {
 		return err
 	}
+	if err := s.rememberPassword(ctx, userID, record.PasswordHash); err != nil {
+		return err
+	}
 
 	audit.Record(ctx, "password_changed", userID, "ok", "")
 	return s.repo.DeleteRefreshTokensByUser(ctx, userID)
@@ -252,14 +369,12 @@ func (s *Service) RefreshTTL() time.Duration {
 	return s.refreshTTL
 }
 
+// validateCredential 校验用户名格式与密码策略，密码不达标时返回 *password.PolicyError。
 func (s *Service) validateCredential(username, rawPassword string) error {
 	if !usernamePattern.MatchString(username) {
 		return ErrInvalidInput
 	}
-	if err := password.Validate(rawPassword); err != nil {
-		return ErrInvalidInput
-	}
-	return nil
+	return password.Validate(rawPassword)
 }
 
 func (s *Service) issueTokenPair(ctx context.Context, userID int64) (*TokenPair, error) {
@@ -276,7 +391,7 @@ func (s *Service) issueTokenPair(ctx context.Context, userID int64) (*TokenPair,
 		return nil, err
 	}
 
-	expiresAt := time.Now().Add(s.refreshTTL)
+	expiresAt := s.now().Add(s.refreshTTL)
 	if err := s.repo.SaveRefreshToken(ctx, RefreshToken{
 		UserID:    userID,
 		TokenHash: hashToken(refresh),
@@ -298,9 +413,30 @@ func hashToken(token string) string {
 	return hex.EncodeToString(sum[:])
 }
 
+// announceUser 发布 user.created 事件。
+func (s *Service) announceUser(ctx context.Context, created *User, source string) {
+	if s.events == nil {
+		return
+	}
+	s.events.Publish(ctx, eventbus.Event{
+		Type: eventbus.TypeUserCreated,
+		Data: map[string]interface{}{
+			"userId":    created.ID,
+			"username":  created.Username,
+			"isAdmin":   created.IsAdmin,
+			"isTeacher": created.IsTeacher,
+			"source":    source,
+		},
+	})
+}
+
 func (s *Service) registerUser(ctx context.Context, username, rawPassword string, opts createUserOptions) (*AuthResult, error) {
-	if err := s.validateCredential(username, rawPassword); err != nil {
-		return nil, ErrInvalidInput
+	if opts.skipPolicy {
+		if !usernamePattern.MatchString(username) || rawPassword == "" {
+			return nil, ErrInvalidInput
+		}
+	} else if err := s.validateCredential(username, rawPassword); err != nil {
+		return nil, err
 	}
 
 	existing, err := s.repo.FindByUsername(ctx, username)
@@ -311,7 +447,7 @@ func (s *Service) registerUser(ctx context.Context, username, rawPassword string
 		return nil, ErrUserExists
 	}
 
-	hashed, err := password.Hash(rawPassword)
+	hashed, err := password.HashUnchecked(rawPassword)
 	if err != nil {
 		return nil, err
 	}
@@ -320,6 +456,7 @@ func (s *Service) registerUser(ctx context.Context, username, rawPassword string
 		Username:           username,
 		PasswordHash:       hashed,
 		IsAdmin:            opts.isAdmin,
+		IsTeacher:          opts.isTeacher,
 		Status:             defaultUserStatus,
 		MustChangePassword: opts.mustChangePassword,
 	}
@@ -329,6 +466,7 @@ func (s *Service) registerUser(ctx context.Context, username, rawPassword string
 		return nil, err
 	}
 	user.ID = userID
+	s.announceUser(ctx, user, opts.source)
 
 	if !opts.issueTokens {
 		return &AuthResult{
@@ -343,7 +481,8 @@ func (s *Service) registerUser(ctx context.Context, username, rawPassword string
 	}
 
 	return &AuthResult{
-		User:   user,
-		Tokens: *tokens,
+		User:             user,
+		Tokens:           *tokens,
+		MFASetupRequired: s.mfaPolicy.requires(user),
 	}, nil
 }
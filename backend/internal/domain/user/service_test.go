@@ -89,6 +89,22 @@ func (m *mockRepo) UpdatePasswordAndFlag(_ context.Context, userID int64, passwo
 	return errors.New("user not found")
 }
 
+func (m *mockRepo) MarkMustChangePassword(_ context.Context, userID int64) error {
+	if u, ok := m.usersByID[userID]; ok {
+		u.MustChangePassword = true
+		return nil
+	}
+	return errors.New("user not found")
+}
+
+func (m *mockRepo) UpdateTeacherFlag(_ context.Context, userID int64, isTeacher bool) error {
+	if u, ok := m.usersByID[userID]; ok {
+		u.IsTeacher = isTeacher
+		return nil
+	}
+	return errors.New("user not found")
+}
+
 func TestService_RegisterAndLogin(t *testing.T) {
 	_ = appjwt.Configure(appjwt.Options{
 		Secret:             "0123456789abcdef",
@@ -199,8 +215,8 @@ func TestService_RejectsWeakPassword(t *testing.T) {
 	})
 
 	_, err := svc.Register(ctx, adminID, "weakuser", "Weakpass1")
-	if !errors.Is(err, ErrInvalidInput) {
-		t.Fatalf("弱口令应返回 ErrInvalidInput")
+	if !errors.Is(err, password.ErrPolicyViolation) {
+		t.Fatalf("弱口令应返回密码策略错误，得到: %v", err)
 	}
 }
 
@@ -0,0 +1,74 @@
+package webhooks
+
+import "time"
+
+// 投递状态：失败后仍在重试的投递保持 pending，超过最大尝试次数进入 dead（死信）。
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+// TestEventType 为“发送测试事件”使用的事件类型，不可被订阅。
+const TestEventType = "webhook.test"
+
+// Endpoint 为管理员配置的 Webhook 接收地址。
+type Endpoint struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Secret 为签名密钥，仅在创建时返回一次。
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedBy int64     `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// EndpointInput 为创建或更新接收地址的参数，Active 为 nil 时保持原值（创建时默认启用）。
+type EndpointInput struct {
+	Name   string
+	URL    string
+	Events []string
+	Active *bool
+}
+
+// Delivery 为发件箱中的一次投递，同一事件投递给多个地址时共享 EventID。
+type Delivery struct {
+	ID         int64  `json:"id"`
+	EndpointID int64  `json:"endpointId"`
+	EventID    string `json:"eventId"`
+	EventType  string `json:"eventType"`
+	// Payload 为签名并发送的原始请求体。
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	LastError      string     `json:"lastError,omitempty"`
+	ResponseStatus int        `json:"responseStatus,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// Payload 为发送给接收方的请求体结构。
+type Payload struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	OccurredAt time.Time              `json:"occurredAt"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// Attempt 为一次发送的结果，Err 非空或状态码非 2xx 视为失败。
+type Attempt struct {
+	StatusCode int
+	Err        error
+}
+
+// Policy 控制超时与重试：第 n 次失败后等待 BaseDelay*2^(n-1)，不超过 MaxDelay。
+type Policy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Timeout     time.Duration
+}
@@ -0,0 +1,30 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+)
+
+// Repository 定义 Webhook 接收地址与发件箱的持久化操作。
+type Repository interface {
+	CreateEndpoint(ctx context.Context, endpoint *Endpoint) (int64, error)
+	UpdateEndpoint(ctx context.Context, endpoint *Endpoint) error
+	DeleteEndpoint(ctx context.Context, id int64) (bool, error)
+	// FindEndpoint 未找到时返回 nil；返回值包含签名密钥。
+	FindEndpoint(ctx context.Context, id int64) (*Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]Endpoint, error)
+	// ActiveEndpointsFor 返回订阅了该事件类型的启用地址。
+	ActiveEndpointsFor(ctx context.Context, eventType string) ([]Endpoint, error)
+
+	CreateDelivery(ctx context.Context, delivery *Delivery) (int64, error)
+	FindDelivery(ctx context.Context, id int64) (*Delivery, error)
+	// ListDeliveries 按 ID 倒序返回投递记录，status 为空表示全部。
+	ListDeliveries(ctx context.Context, endpointID int64, status string, limit int) ([]Delivery, error)
+	// DueDeliveries 按到期时间返回待投递记录。
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+	// ClaimDelivery 仅当记录仍待投递且已到期时把下次尝试时间推迟到 until，返回是否领取成功，
+	// 避免多个实例重复发送。
+	ClaimDelivery(ctx context.Context, id int64, now, until time.Time) (bool, error)
+	// SaveAttempt 写入投递结果（状态、次数、下次尝试时间、错误与响应码）。
+	SaveAttempt(ctx context.Context, delivery *Delivery) error
+}
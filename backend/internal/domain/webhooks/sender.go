@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// 投递请求头。
+const (
+	HeaderEvent     = "X-GoStudy-Event"
+	HeaderDelivery  = "X-GoStudy-Delivery"
+	HeaderSignature = "X-GoStudy-Signature"
+)
+
+// Sign 计算签名头的值：t=<Unix 秒>,v1=<hex(HMAC-SHA256(secret, "<t>.<body>"))>。
+// 接收方应按相同方式重新计算并比较，同时拒绝时间戳过旧的请求以防重放。
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Sender 发送一次投递请求。
+type Sender interface {
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) Attempt
+}
+
+// HTTPSender 通过 HTTP POST 发送投递，不跟随重定向，3xx 视为失败。
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender 创建带整体超时的发送器。
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	return &HTTPSender{client: &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Send 实现 Sender。
+func (h *HTTPSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) Attempt {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Attempt{Err: err}
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Attempt{Err: err}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return Attempt{StatusCode: resp.StatusCode}
+}
@@ -0,0 +1,488 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-study2/internal/infrastructure/audit"
+	"go-study2/internal/infrastructure/eventbus"
+	"go-study2/internal/infrastructure/tracing"
+)
+
+var (
+	// ErrInvalidInput 表示 Webhook 参数不合法。
+	ErrInvalidInput = errors.New("Webhook 参数不合法")
+	// ErrEndpointNotFound 表示接收地址不存在。
+	ErrEndpointNotFound = errors.New("Webhook 接收地址不存在")
+	// ErrDeliveryNotFound 表示投递记录不存在。
+	ErrDeliveryNotFound = errors.New("Webhook 投递记录不存在")
+)
+
+const (
+	maxNameRunes     = 100
+	maxURLLength     = 2000
+	maxErrorRunes    = 500
+	defaultListLimit = 50
+	maxListLimit     = 200
+	// dispatchBatch 为每轮最多发送的投递数。
+	dispatchBatch = 20
+	userAgent     = "go-study2-webhooks"
+)
+
+// DefaultPolicy 为未配置时的超时与重试策略，最后一次重试约在首次失败后 1 小时。
+var DefaultPolicy = Policy{
+	MaxAttempts: 8,
+	BaseDelay:   30 * time.Second,
+	MaxDelay:    time.Hour,
+	Timeout:     10 * time.Second,
+}
+
+// Service 管理 Webhook 接收地址，并把学习事件签名后投递出去：事件先写入持久化发件箱，
+// 再由后台任务发送，失败按指数退避重试，超过最大次数进入死信。
+type Service struct {
+	repo   Repository
+	sender Sender
+	policy Policy
+	now    func() time.Time
+	wakeup func()
+}
+
+// NewService 创建 Webhook 服务，policy 中未设置的字段取 DefaultPolicy。
+func NewService(repo Repository, sender Sender, policy Policy) *Service {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultPolicy.MaxAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultPolicy.BaseDelay
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = maxDuration(DefaultPolicy.MaxDelay, policy.BaseDelay)
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = DefaultPolicy.Timeout
+	}
+	return &Service{repo: repo, sender: sender, policy: policy, now: time.Now}
+}
+
+// WithClock 注入时钟，便于测试退避时间。
+func (s *Service) WithClock(now func() time.Time) *Service {
+	if now != nil {
+		s.now = now
+	}
+	return s
+}
+
+// WithWakeup 设置新投递入队后的回调，用于立即唤醒后台投递任务。
+func (s *Service) WithWakeup(fn func()) *Service {
+	s.wakeup = fn
+	return s
+}
+
+// CreateEndpoint 创建接收地址并生成签名密钥，密钥仅在返回值中出现一次。
+func (s *Service) CreateEndpoint(ctx context.Context, operatorID int64, input EndpointInput) (*Endpoint, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.CreateEndpoint")
+	defer span.End()
+	endpoint, err := normalize(input)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC().Truncate(time.Second)
+	endpoint.Secret = secret
+	endpoint.Active = input.Active == nil || *input.Active
+	endpoint.CreatedBy = operatorID
+	endpoint.CreatedAt = now
+	endpoint.UpdatedAt = now
+	id, err := s.repo.CreateEndpoint(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.ID = id
+	audit.Record(ctx, "webhook_created", operatorID, "ok", fmt.Sprintf("endpoint_id=%d events=%s", id, strings.Join(endpoint.Events, ",")))
+	return endpoint, nil
+}
+
+// UpdateEndpoint 修改名称、地址、订阅事件与启用状态，签名密钥保持不变。
+func (s *Service) UpdateEndpoint(ctx context.Context, operatorID, id int64, input EndpointInput) (*Endpoint, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.UpdateEndpoint")
+	defer span.End()
+	existing, err := s.findEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := normalize(input)
+	if err != nil {
+		return nil, err
+	}
+	existing.Name = updated.Name
+	existing.URL = updated.URL
+	existing.Events = updated.Events
+	if input.Active != nil {
+		existing.Active = *input.Active
+	}
+	existing.UpdatedAt = s.now().UTC().Truncate(time.Second)
+	if err := s.repo.UpdateEndpoint(ctx, existing); err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "webhook_updated", operatorID, "ok", fmt.Sprintf("endpoint_id=%d active=%t", id, existing.Active))
+	existing.Secret = ""
+	return existing, nil
+}
+
+// DeleteEndpoint 删除接收地址及其投递记录。
+func (s *Service) DeleteEndpoint(ctx context.Context, operatorID, id int64) error {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.DeleteEndpoint")
+	defer span.End()
+	deleted, err := s.repo.DeleteEndpoint(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrEndpointNotFound
+	}
+	audit.Record(ctx, "webhook_deleted", operatorID, "ok", fmt.Sprintf("endpoint_id=%d", id))
+	return nil
+}
+
+// ListEndpoints 返回全部接收地址，不含签名密钥。
+func (s *Service) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.ListEndpoints")
+	defer span.End()
+	list, err := s.repo.ListEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Secret = ""
+	}
+	return list, nil
+}
+
+// GetEndpoint 返回单个接收地址，不含签名密钥。
+func (s *Service) GetEndpoint(ctx context.Context, id int64) (*Endpoint, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.GetEndpoint")
+	defer span.End()
+	endpoint, err := s.findEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+// Enqueue 为订阅了该事件的每个启用地址写入一条待投递记录，返回入队数量；
+// 非学习事件直接忽略。
+func (s *Service) Enqueue(ctx context.Context, event eventbus.Event) (int, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.Enqueue")
+	defer span.End()
+	if !supported(event.Type) {
+		return 0, nil
+	}
+	endpoints, err := s.repo.ActiveEndpointsFor(ctx, event.Type)
+	if err != nil || len(endpoints) == 0 {
+		return 0, err
+	}
+	payload, eventID, err := s.encode(event.Type, event.OccurredAt, event.Data)
+	if err != nil {
+		return 0, err
+	}
+	for _, endpoint := range endpoints {
+		if _, err := s.insert(ctx, endpoint.ID, eventID, event.Type, payload); err != nil {
+			return 0, err
+		}
+	}
+	s.wake()
+	return len(endpoints), nil
+}
+
+// SendTest 向指定地址投递一条测试事件；停用的地址同样可以测试。
+func (s *Service) SendTest(ctx context.Context, operatorID, id int64) (*Delivery, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.SendTest")
+	defer span.End()
+	endpoint, err := s.findEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	payload, eventID, err := s.encode(TestEventType, time.Time{}, map[string]interface{}{
+		"endpointId": endpoint.ID,
+		"message":    "这是一条测试事件",
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := s.insert(ctx, endpoint.ID, eventID, TestEventType, payload)
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "webhook_test_sent", operatorID, "ok", fmt.Sprintf("endpoint_id=%d delivery_id=%d", id, delivery.ID))
+	s.wake()
+	return delivery, nil
+}
+
+// Deliveries 返回接收地址的投递记录，status 可按 pending、delivered、dead 过滤。
+func (s *Service) Deliveries(ctx context.Context, endpointID int64, status string, limit int) ([]Delivery, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.Deliveries")
+	defer span.End()
+	status = strings.TrimSpace(status)
+	if status != "" && status != StatusPending && status != StatusDelivered && status != StatusDead {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.findEndpoint(ctx, endpointID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return s.repo.ListDeliveries(ctx, endpointID, status, limit)
+}
+
+// RetryDelivery 立即重新投递一条记录并清零尝试次数，常用于处理死信。
+func (s *Service) RetryDelivery(ctx context.Context, operatorID, deliveryID int64) (*Delivery, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.RetryDelivery")
+	defer span.End()
+	delivery, err := s.repo.FindDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, ErrDeliveryNotFound
+	}
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = s.now().UTC()
+	delivery.DeliveredAt = nil
+	if err := s.repo.SaveAttempt(ctx, delivery); err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, "webhook_delivery_retried", operatorID, "ok", fmt.Sprintf("delivery_id=%d", deliveryID))
+	s.wake()
+	return delivery, nil
+}
+
+// DispatchDue 发送已到期的待投递记录，返回本轮处理的数量。
+func (s *Service) DispatchDue(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.DispatchDue")
+	defer span.End()
+	now := s.now().UTC()
+	due, err := s.repo.DueDeliveries(ctx, now, dispatchBatch)
+	if err != nil {
+		return 0, err
+	}
+	handled := 0
+	for i := range due {
+		// 领取期限覆盖一次请求的超时，实例中途退出时记录会在期限后被重新领取
+		claimed, err := s.repo.ClaimDelivery(ctx, due[i].ID, now, now.Add(2*s.policy.Timeout))
+		if err != nil {
+			return handled, err
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.deliver(ctx, &due[i]); err != nil {
+			return handled, err
+		}
+		handled++
+	}
+	return handled, nil
+}
+
+func (s *Service) deliver(ctx context.Context, delivery *Delivery) error {
+	endpoint, err := s.repo.FindEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		return err
+	}
+	delivery.Attempts++
+	switch {
+	case endpoint == nil:
+		return s.bury(ctx, delivery, "接收地址已删除")
+	case !endpoint.Active && delivery.EventType != TestEventType:
+		return s.bury(ctx, delivery, "接收地址已停用")
+	}
+
+	body := []byte(delivery.Payload)
+	sendCtx, cancel := context.WithTimeout(ctx, s.policy.Timeout)
+	result := s.sender.Send(sendCtx, endpoint.URL, map[string]string{
+		"Content-Type":  "application/json",
+		"User-Agent":    userAgent,
+		HeaderEvent:     delivery.EventType,
+		HeaderDelivery:  fmt.Sprint(delivery.ID),
+		HeaderSignature: Sign(endpoint.Secret, s.now().Unix(), body),
+	}, body)
+	cancel()
+
+	delivery.ResponseStatus = result.StatusCode
+	if result.Err == nil && result.StatusCode >= 200 && result.StatusCode < 300 {
+		deliveredAt := s.now().UTC()
+		delivery.Status = StatusDelivered
+		delivery.DeliveredAt = &deliveredAt
+		delivery.LastError = ""
+		return s.repo.SaveAttempt(ctx, delivery)
+	}
+	reason := fmt.Sprintf("接收方返回状态码 %d", result.StatusCode)
+	if result.Err != nil {
+		reason = result.Err.Error()
+	}
+	if delivery.Attempts >= s.policy.MaxAttempts {
+		return s.bury(ctx, delivery, reason)
+	}
+	delivery.Status = StatusPending
+	delivery.LastError = truncate(reason)
+	delivery.NextAttemptAt = s.now().UTC().Add(s.backoff(delivery.Attempts))
+	return s.repo.SaveAttempt(ctx, delivery)
+}
+
+// bury 把投递转入死信，不再自动重试。
+func (s *Service) bury(ctx context.Context, delivery *Delivery, reason string) error {
+	delivery.Status = StatusDead
+	delivery.LastError = truncate(reason)
+	return s.repo.SaveAttempt(ctx, delivery)
+}
+
+// backoff 返回第 attempts 次失败后的等待时间。
+func (s *Service) backoff(attempts int) time.Duration {
+	delay := s.policy.BaseDelay
+	for i := 1; i < attempts && delay < s.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.policy.MaxDelay {
+		delay = s.policy.MaxDelay
+	}
+	return delay
+}
+
+func (s *Service) insert(ctx context.Context, endpointID int64, eventID, eventType, payload string) (*Delivery, error) {
+	now := s.now().UTC()
+	delivery := &Delivery{
+		EndpointID:    endpointID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	id, err := s.repo.CreateDelivery(ctx, delivery)
+	if err != nil {
+		return nil, err
+	}
+	delivery.ID = id
+	return delivery, nil
+}
+
+func (s *Service) encode(eventType string, occurredAt time.Time, data map[string]interface{}) (string, string, error) {
+	eventID, err := newToken("evt_", 12)
+	if err != nil {
+		return "", "", err
+	}
+	if occurredAt.IsZero() {
+		occurredAt = s.now()
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(Payload{ID: eventID, Type: eventType, OccurredAt: occurredAt.UTC(), Data: data})
+	if err != nil {
+		return "", "", err
+	}
+	return string(encoded), eventID, nil
+}
+
+func (s *Service) findEndpoint(ctx context.Context, id int64) (*Endpoint, error) {
+	if id <= 0 {
+		return nil, ErrEndpointNotFound
+	}
+	endpoint, err := s.repo.FindEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, ErrEndpointNotFound
+	}
+	return endpoint, nil
+}
+
+func (s *Service) wake() {
+	if s.wakeup != nil {
+		s.wakeup()
+	}
+}
+
+// normalize 校验名称、地址与订阅事件，事件去重并保持原顺序。
+func normalize(input EndpointInput) (*Endpoint, error) {
+	name := strings.TrimSpace(input.Name)
+	rawURL := strings.TrimSpace(input.URL)
+	if name == "" || utf8.RuneCountInString(name) > maxNameRunes || rawURL == "" || len(rawURL) > maxURLLength {
+		return nil, ErrInvalidInput
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidInput
+	}
+	seen := make(map[string]struct{}, len(input.Events))
+	var events []string
+	for _, ev := range input.Events {
+		ev = strings.TrimSpace(ev)
+		if !supported(ev) {
+			return nil, ErrInvalidInput
+		}
+		if _, ok := seen[ev]; ok {
+			continue
+		}
+		seen[ev] = struct{}{}
+		events = append(events, ev)
+	}
+	if len(events) == 0 {
+		return nil, ErrInvalidInput
+	}
+	return &Endpoint{Name: name, URL: rawURL, Events: events}, nil
+}
+
+// supported 判断事件类型是否可被 Webhook 订阅。
+func supported(eventType string) bool {
+	for _, t := range eventbus.LearningTypes() {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func newSecret() (string, error) {
+	return newToken("whsec_", 32)
+}
+
+func newToken(prefix string, size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(buf), nil
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxErrorRunes {
+		return s
+	}
+	return string([]rune(s)[:maxErrorRunes])
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
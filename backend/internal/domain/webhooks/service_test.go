@@ -0,0 +1,328 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-study2/internal/infrastructure/eventbus"
+)
+
+type mockRepo struct {
+	endpoints  map[int64]*Endpoint
+	deliveries map[int64]*Delivery
+	nextID     int64
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{endpoints: make(map[int64]*Endpoint), deliveries: make(map[int64]*Delivery)}
+}
+
+func (m *mockRepo) CreateEndpoint(_ context.Context, endpoint *Endpoint) (int64, error) {
+	m.nextID++
+	copied := *endpoint
+	copied.ID = m.nextID
+	m.endpoints[copied.ID] = &copied
+	return copied.ID, nil
+}
+
+func (m *mockRepo) UpdateEndpoint(_ context.Context, endpoint *Endpoint) error {
+	copied := *endpoint
+	m.endpoints[endpoint.ID] = &copied
+	return nil
+}
+
+func (m *mockRepo) DeleteEndpoint(_ context.Context, id int64) (bool, error) {
+	if _, ok := m.endpoints[id]; !ok {
+		return false, nil
+	}
+	delete(m.endpoints, id)
+	return true, nil
+}
+
+func (m *mockRepo) FindEndpoint(_ context.Context, id int64) (*Endpoint, error) {
+	endpoint, ok := m.endpoints[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *endpoint
+	return &copied, nil
+}
+
+func (m *mockRepo) ListEndpoints(_ context.Context) ([]Endpoint, error) {
+	var list []Endpoint
+	for _, endpoint := range m.endpoints {
+		list = append(list, *endpoint)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (m *mockRepo) ActiveEndpointsFor(ctx context.Context, eventType string) ([]Endpoint, error) {
+	all, _ := m.ListEndpoints(ctx)
+	var list []Endpoint
+	for _, endpoint := range all {
+		for _, ev := range endpoint.Events {
+			if endpoint.Active && ev == eventType {
+				list = append(list, endpoint)
+			}
+		}
+	}
+	return list, nil
+}
+
+func (m *mockRepo) CreateDelivery(_ context.Context, delivery *Delivery) (int64, error) {
+	m.nextID++
+	copied := *delivery
+	copied.ID = m.nextID
+	m.deliveries[copied.ID] = &copied
+	return copied.ID, nil
+}
+
+func (m *mockRepo) FindDelivery(_ context.Context, id int64) (*Delivery, error) {
+	delivery, ok := m.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *delivery
+	return &copied, nil
+}
+
+func (m *mockRepo) ListDeliveries(_ context.Context, endpointID int64, status string, limit int) ([]Delivery, error) {
+	var list []Delivery
+	for _, delivery := range m.deliveries {
+		if delivery.EndpointID == endpointID && (status == "" || delivery.Status == status) {
+			list = append(list, *delivery)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (m *mockRepo) DueDeliveries(_ context.Context, now time.Time, limit int) ([]Delivery, error) {
+	var list []Delivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == StatusPending && !delivery.NextAttemptAt.After(now) {
+			list = append(list, *delivery)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (m *mockRepo) ClaimDelivery(_ context.Context, id int64, now, until time.Time) (bool, error) {
+	delivery, ok := m.deliveries[id]
+	if !ok || delivery.Status != StatusPending || delivery.NextAttemptAt.After(now) {
+		return false, nil
+	}
+	delivery.NextAttemptAt = until
+	return true, nil
+}
+
+func (m *mockRepo) SaveAttempt(_ context.Context, delivery *Delivery) error {
+	copied := *delivery
+	m.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+type sentRequest struct {
+	url     string
+	headers map[string]string
+	body    []byte
+}
+
+// stubSender 按顺序返回预设结果，用尽后返回 200。
+type stubSender struct {
+	results []Attempt
+	sent    []sentRequest
+}
+
+func (s *stubSender) Send(_ context.Context, url string, headers map[string]string, body []byte) Attempt {
+	s.sent = append(s.sent, sentRequest{url: url, headers: headers, body: body})
+	if len(s.results) == 0 {
+		return Attempt{StatusCode: 200}
+	}
+	result := s.results[0]
+	s.results = s.results[1:]
+	return result
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestService(sender Sender) (*Service, *mockRepo, *fakeClock) {
+	repo := newMockRepo()
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)}
+	svc := NewService(repo, sender, Policy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 90 * time.Second}).WithClock(clock.Now)
+	return svc, repo, clock
+}
+
+func TestService_EndpointValidationAndSecret(t *testing.T) {
+	svc, _, _ := newTestService(&stubSender{})
+	ctx := context.Background()
+
+	invalid := []EndpointInput{
+		{Name: "", URL: "https://a.example", Events: []string{eventbus.TypeQuizSubmitted}},
+		{Name: "ftp", URL: "ftp://a.example", Events: []string{eventbus.TypeQuizSubmitted}},
+		{Name: "relative", URL: "/hook", Events: []string{eventbus.TypeQuizSubmitted}},
+		{Name: "none", URL: "https://a.example"},
+		{Name: "notification", URL: "https://a.example", Events: []string{eventbus.TypeMention}},
+	}
+	for _, input := range invalid {
+		if _, err := svc.CreateEndpoint(ctx, 1, input); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("%+v 应返回 ErrInvalidInput，得到 %v", input, err)
+		}
+	}
+
+	created, err := svc.CreateEndpoint(ctx, 1, EndpointInput{Name: " bot ", URL: "https://bot.example/hook",
+		Events: []string{eventbus.TypeQuizSubmitted, eventbus.TypeQuizSubmitted, eventbus.TypeUserCreated}})
+	if err != nil {
+		t.Fatalf("创建接收地址失败: %v", err)
+	}
+	if !strings.HasPrefix(created.Secret, "whsec_") || !created.Active || created.Name != "bot" || len(created.Events) != 2 {
+		t.Fatalf("创建结果不正确: %+v", created)
+	}
+	if got, _ := svc.GetEndpoint(ctx, created.ID); got.Secret != "" {
+		t.Fatalf("查询时不应返回签名密钥")
+	}
+	disabled := false
+	updated, err := svc.UpdateEndpoint(ctx, 1, created.ID, EndpointInput{Name: "bot", URL: "https://bot.example/v2",
+		Events: []string{eventbus.TypeProgressDone}, Active: &disabled})
+	if err != nil || updated.Active || updated.URL != "https://bot.example/v2" || updated.Secret != "" {
+		t.Fatalf("更新结果不正确: %+v %v", updated, err)
+	}
+	if _, err := svc.UpdateEndpoint(ctx, 1, 999, EndpointInput{Name: "x", URL: "https://x.example", Events: []string{eventbus.TypeProgressDone}}); !errors.Is(err, ErrEndpointNotFound) {
+		t.Fatalf("不存在的地址应返回 ErrEndpointNotFound，得到 %v", err)
+	}
+	if err := svc.DeleteEndpoint(ctx, 1, created.ID); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if err := svc.DeleteEndpoint(ctx, 1, created.ID); !errors.Is(err, ErrEndpointNotFound) {
+		t.Fatalf("重复删除应返回 ErrEndpointNotFound，得到 %v", err)
+	}
+}
+
+func TestService_EnqueueAndSignedDelivery(t *testing.T) {
+	sender := &stubSender{}
+	svc, _, clock := newTestService(sender)
+	woken := 0
+	svc.WithWakeup(func() { woken++ })
+	ctx := context.Background()
+
+	quiz, _ := svc.CreateEndpoint(ctx, 1, EndpointInput{Name: "quiz", URL: "https://quiz.example", Events: []string{eventbus.TypeQuizSubmitted}})
+	_, _ = svc.CreateEndpoint(ctx, 1, EndpointInput{Name: "users", URL: "https://users.example", Events: []string{eventbus.TypeUserCreated}})
+
+	if n, err := svc.Enqueue(ctx, eventbus.Event{Type: eventbus.TypeMention, UserIDs: []int64{2}}); err != nil || n != 0 {
+		t.Fatalf("站内通知事件不应入队: n=%d %v", n, err)
+	}
+	n, err := svc.Enqueue(ctx, eventbus.Event{Type: eventbus.TypeQuizSubmitted, Data: map[string]interface{}{"score": 3}})
+	if err != nil || n != 1 || woken != 1 {
+		t.Fatalf("应只为订阅者入队并唤醒投递: n=%d woken=%d %v", n, woken, err)
+	}
+
+	handled, err := svc.DispatchDue(ctx)
+	if err != nil || handled != 1 || len(sender.sent) != 1 {
+		t.Fatalf("应发送 1 条投递: handled=%d sent=%d %v", handled, len(sender.sent), err)
+	}
+	req := sender.sent[0]
+	want := Sign(quiz.Secret, clock.now.Unix(), req.body)
+	if req.url != "https://quiz.example" || req.headers[HeaderSignature] != want || req.headers[HeaderEvent] != eventbus.TypeQuizSubmitted {
+		t.Fatalf("请求头或签名不正确: %+v", req.headers)
+	}
+	var payload Payload
+	if err := json.Unmarshal(req.body, &payload); err != nil || payload.Type != eventbus.TypeQuizSubmitted ||
+		!strings.HasPrefix(payload.ID, "evt_") || payload.Data["score"] != float64(3) {
+		t.Fatalf("请求体不正确: %s", req.body)
+	}
+	list, _ := svc.Deliveries(ctx, quiz.ID, StatusDelivered, 0)
+	if len(list) != 1 || list[0].Attempts != 1 || list[0].DeliveredAt == nil || strconv.FormatInt(list[0].ID, 10) != req.headers[HeaderDelivery] {
+		t.Fatalf("投递记录不正确: %+v", list)
+	}
+	if _, err := svc.Deliveries(ctx, quiz.ID, "unknown", 0); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("未知状态应返回 ErrInvalidInput，得到 %v", err)
+	}
+}
+
+func TestService_RetryBackoffAndDeadLetter(t *testing.T) {
+	sender := &stubSender{results: []Attempt{{StatusCode: 500}, {Err: errors.New("connection refused")}, {StatusCode: 302}}}
+	svc, repo, clock := newTestService(sender)
+	ctx := context.Background()
+
+	endpoint, _ := svc.CreateEndpoint(ctx, 1, EndpointInput{Name: "flaky", URL: "https://flaky.example", Events: []string{eventbus.TypeProgressDone}})
+	_, _ = svc.Enqueue(ctx, eventbus.Event{Type: eventbus.TypeProgressDone})
+
+	_, _ = svc.DispatchDue(ctx)
+	list, _ := svc.Deliveries(ctx, endpoint.ID, "", 0)
+	first := list[0]
+	if first.Status != StatusPending || first.Attempts != 1 || first.ResponseStatus != 500 || !first.NextAttemptAt.Equal(clock.now.Add(time.Minute)) {
+		t.Fatalf("首次失败应在 1 分钟后重试: %+v", first)
+	}
+	if handled, _ := svc.DispatchDue(ctx); handled != 0 {
+		t.Fatalf("未到期的投递不应发送")
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	_, _ = svc.DispatchDue(ctx)
+	second, _ := repo.FindDelivery(ctx, first.ID)
+	if second.Attempts != 2 || second.LastError != "connection refused" || !second.NextAttemptAt.Equal(clock.now.Add(90*time.Second)) {
+		t.Fatalf("第二次失败应指数退避且不超过上限: %+v", second)
+	}
+
+	clock.now = clock.now.Add(90 * time.Second)
+	_, _ = svc.DispatchDue(ctx)
+	dead, _ := repo.FindDelivery(ctx, first.ID)
+	if dead.Status != StatusDead || dead.Attempts != 3 || !strings.Contains(dead.LastError, "302") {
+		t.Fatalf("超过最大次数应进入死信: %+v", dead)
+	}
+
+	retried, err := svc.RetryDelivery(ctx, 1, dead.ID)
+	if err != nil || retried.Status != StatusPending || retried.Attempts != 0 {
+		t.Fatalf("手动重试应重置为待投递: %+v %v", retried, err)
+	}
+	_, _ = svc.DispatchDue(ctx)
+	if delivered, _ := repo.FindDelivery(ctx, dead.ID); delivered.Status != StatusDelivered {
+		t.Fatalf("手动重试后应投递成功: %+v", delivered)
+	}
+	if _, err := svc.RetryDelivery(ctx, 1, 999); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Fatalf("不存在的投递应返回 ErrDeliveryNotFound，得到 %v", err)
+	}
+}
+
+func TestService_SendTestToDisabledEndpoint(t *testing.T) {
+	sender := &stubSender{}
+	svc, repo, _ := newTestService(sender)
+	ctx := context.Background()
+
+	off := false
+	endpoint, _ := svc.CreateEndpoint(ctx, 1, EndpointInput{Name: "off", URL: "https://off.example", Events: []string{eventbus.TypeUserCreated}, Active: &off})
+	if n, _ := svc.Enqueue(ctx, eventbus.Event{Type: eventbus.TypeUserCreated}); n != 0 {
+		t.Fatalf("停用的地址不应接收事件")
+	}
+	delivery, err := svc.SendTest(ctx, 1, endpoint.ID)
+	if err != nil || delivery.EventType != TestEventType {
+		t.Fatalf("发送测试事件失败: %+v %v", delivery, err)
+	}
+	_, _ = svc.DispatchDue(ctx)
+	if sent, _ := repo.FindDelivery(ctx, delivery.ID); sent.Status != StatusDelivered || sender.sent[0].headers[HeaderEvent] != TestEventType {
+		t.Fatalf("停用的地址也应能收到测试事件: %+v", sent)
+	}
+	if _, err := svc.SendTest(ctx, 1, 999); !errors.Is(err, ErrEndpointNotFound) {
+		t.Fatalf("不存在的地址应返回 ErrEndpointNotFound，得到 %v", err)
+	}
+}
@@ -2,37 +2,167 @@ package audit
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/pkg/requestid"
 
+	"github.com/gogf/gf/v2/database/gdb"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gctx"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// timeLayout 与 SQLite CURRENT_TIMESTAMP 格式一致（UTC），便于按字符串比较时间范围。
+const timeLayout = "2006-01-02 15:04:05"
+
+// Fields 附加的结构化字段。
+type Fields map[string]any
+
+// Metadata 审计事件的结构化元数据，以 JSON 存储。
+type Metadata struct {
+	RequestID string `json:"requestId,omitempty"`
+	TraceID   string `json:"traceId,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	Fields    Fields `json:"fields,omitempty"`
+}
+
 // Event 表示审计事件模型。
 type Event struct {
 	ID        int64     `json:"id"`
 	EventType string    `json:"eventType"`
 	UserID    int64     `json:"userId"`
 	Result    string    `json:"result"`
-	Metadata  string    `json:"metadata"`
+	Metadata  Metadata  `json:"metadata"`
 	CreatedAt time.Time `json:"createdAt"`
+	PrevHash  string    `json:"prevHash"`
+	Hash      string    `json:"hash"`
+	// rawMetadata 与 createdAtText 为落库原文，用于哈希校验。
+	rawMetadata   string
+	createdAtText string
 }
 
-// Record 写入审计事件，失败时记录日志但不阻断业务。
-func Record(ctx context.Context, eventType string, userID int64, result string, metadata string) {
+// chainMu 串行化写入，保证每条记录链接到前一条记录的哈希。
+var chainMu sync.Mutex
+
+// Record 写入审计事件，detail 为简短说明；失败时记录日志但不阻断业务。
+func Record(ctx context.Context, eventType string, userID int64, result string, detail string) {
+	RecordFields(ctx, eventType, userID, result, detail, nil)
+}
+
+// RecordFields 写入附带结构化字段的审计事件，请求 ID、追踪 ID、IP 与 User-Agent 自动从请求上下文提取。
+func RecordFields(ctx context.Context, eventType string, userID int64, result string, detail string, fields Fields) {
 	db := database.Default()
 	if db == nil {
 		return
 	}
-	_, err := db.Insert(ctx, "audit_events", g.Map{
-		"event_type": eventType,
-		"user_id":    userID,
-		"result":     result,
-		"metadata":   metadata,
-	})
-	if err != nil {
+	meta := requestMetadata(ctx)
+	meta.Detail = detail
+	meta.Fields = fields
+	if err := appendEvent(ctx, db, eventType, userID, result, meta, time.Now()); err != nil {
 		g.Log().Warningf(gctx.New(), "写入审计事件失败: %v", err)
 	}
 }
+
+func requestMetadata(ctx context.Context) Metadata {
+	meta := Metadata{RequestID: requestid.FromContext(ctx)}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		meta.TraceID = sc.TraceID().String()
+	}
+	r := g.RequestFromCtx(ctx)
+	if r == nil {
+		return meta
+	}
+	if meta.RequestID == "" {
+		meta.RequestID = gctx.CtxId(ctx)
+	}
+	meta.IP = r.GetClientIp()
+	meta.UserAgent = r.UserAgent()
+	return meta
+}
+
+func appendEvent(ctx context.Context, db gdb.DB, eventType string, userID int64, result string, meta Metadata, at time.Time) error {
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	createdAt := at.UTC().Format(timeLayout)
+
+	chainMu.Lock()
+	defer chainMu.Unlock()
+	return db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		state, err := tx.GetOne("SELECT head_hash FROM audit_chain_state WHERE id = 1")
+		if err != nil {
+			return err
+		}
+		prev := state["head_hash"].String()
+		hash := chainHash(prev, eventType, userID, result, string(raw), createdAt)
+		if _, err := tx.Insert("audit_events", g.Map{
+			"event_type": eventType,
+			"user_id":    userID,
+			"result":     result,
+			"metadata":   string(raw),
+			"created_at": createdAt,
+			"prev_hash":  prev,
+			"hash":       hash,
+		}); err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE audit_chain_state SET head_hash = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 1", hash)
+		return err
+	})
+}
+
+// chainHash 计算记录哈希：覆盖前一条哈希与全部业务字段，任一字段被改动都会导致校验失败。
+func chainHash(prev, eventType string, userID int64, result, metadata, createdAt string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		prev, eventType, strconv.FormatInt(userID, 10), result, metadata, createdAt,
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseMetadata 解析元数据，兼容早期以纯文本保存的记录。
+func parseMetadata(raw string) Metadata {
+	var meta Metadata
+	if strings.HasPrefix(strings.TrimSpace(raw), "{") && json.Unmarshal([]byte(raw), &meta) == nil {
+		return meta
+	}
+	return Metadata{Detail: raw}
+}
+
+func parseTime(text string) time.Time {
+	for _, layout := range []string{timeLayout, time.RFC3339Nano, "2006-01-02T15:04:05Z"} {
+		if t, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// eventColumns 以文本形式读取 created_at，保证与写入时参与哈希的原文一致。
+const eventColumns = "id, event_type, COALESCE(user_id, 0) AS user_id, result, COALESCE(metadata, '') AS metadata, CAST(created_at AS TEXT) AS created_text, prev_hash, hash"
+
+func toEvent(record gdb.Record) Event {
+	raw := record["metadata"].String()
+	createdText := record["created_text"].String()
+	return Event{
+		ID:            record["id"].Int64(),
+		EventType:     record["event_type"].String(),
+		UserID:        record["user_id"].Int64(),
+		Result:        record["result"].String(),
+		Metadata:      parseMetadata(raw),
+		CreatedAt:     parseTime(createdText),
+		PrevHash:      record["prev_hash"].String(),
+		Hash:          record["hash"].String(),
+		rawMetadata:   raw,
+		createdAtText: createdText,
+	}
+}
@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/infrastructure/database"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func setupAuditDB(t *testing.T) gdb.DB {
+	t.Helper()
+	db, err := database.Init(gctx.New(), config.DatabaseConfig{
+		Type: "sqlite3",
+		Path: filepath.Join(t.TempDir(), "audit.db"),
+	})
+	if err != nil {
+		t.Fatalf("初始化测试数据库失败: %v", err)
+	}
+	return db
+}
+
+func seedEvents(t *testing.T, db gdb.DB, start time.Time, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		err := appendEvent(context.Background(), db, "login", int64(i%2+1), "ok", Metadata{Detail: "seed"}, start.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatalf("写入审计事件失败: %v", err)
+		}
+	}
+}
+
+func mustVerify(t *testing.T) *VerifyReport {
+	t.Helper()
+	report, err := Verify(context.Background())
+	if err != nil {
+		t.Fatalf("校验失败: %v", err)
+	}
+	return report
+}
+
+func TestRecordStoresStructuredMetadata(t *testing.T) {
+	setupAuditDB(t)
+	ctx := context.Background()
+	RecordFields(ctx, "password_change_rejected", 7, "blocked", "weak", Fields{"rules": []string{"min_length"}})
+	Record(ctx, "logout", 7, "ok", "")
+
+	page, err := Query(ctx, Filter{UserID: 7})
+	if err != nil || len(page.Items) != 2 {
+		t.Fatalf("查询失败: %+v, %v", page, err)
+	}
+	rejected := page.Items[1]
+	if rejected.Metadata.Detail != "weak" || rejected.Metadata.Fields["rules"] == nil {
+		t.Fatalf("元数据未结构化保存: %+v", rejected.Metadata)
+	}
+	if rejected.Hash == "" || page.Items[0].PrevHash != rejected.Hash {
+		t.Fatalf("记录未链接到前一条哈希")
+	}
+}
+
+func TestQueryFiltersAndCursor(t *testing.T) {
+	db := setupAuditDB(t)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	seedEvents(t, db, start, 5)
+	_ = appendEvent(context.Background(), db, "login_failed", 1, "failed", Metadata{}, start.Add(10*time.Hour))
+	ctx := context.Background()
+
+	page, err := Query(ctx, Filter{EventType: "login", Limit: 2})
+	if err != nil || len(page.Items) != 2 || page.NextCursor == "" {
+		t.Fatalf("第一页结果不正确: %+v, %v", page, err)
+	}
+	next, err := Query(ctx, Filter{EventType: "login", Limit: 2, Cursor: page.NextCursor})
+	if err != nil || len(next.Items) != 2 || next.Items[0].ID >= page.Items[1].ID {
+		t.Fatalf("游标翻页不正确: %+v, %v", next, err)
+	}
+	last, _ := Query(ctx, Filter{EventType: "login", Limit: 2, Cursor: next.NextCursor})
+	if len(last.Items) != 1 || last.NextCursor != "" {
+		t.Fatalf("最后一页不应返回游标: %+v", last)
+	}
+
+	if byUser, _ := Query(ctx, Filter{UserID: 2}); len(byUser.Items) != 2 {
+		t.Fatalf("按用户过滤失败: %d", len(byUser.Items))
+	}
+	if failed, _ := Query(ctx, Filter{Result: "failed"}); len(failed.Items) != 1 {
+		t.Fatalf("按结果过滤失败")
+	}
+	ranged, _ := Query(ctx, Filter{From: start.Add(time.Hour), To: start.Add(3 * time.Hour)})
+	if len(ranged.Items) != 2 {
+		t.Fatalf("按时间范围过滤失败: %d", len(ranged.Items))
+	}
+	if _, err := Query(ctx, Filter{Cursor: "not-a-cursor"}); err != ErrInvalidCursor {
+		t.Fatalf("非法游标应返回 ErrInvalidCursor，得到: %v", err)
+	}
+
+	var exported int
+	_ = Each(ctx, Filter{EventType: "login"}, func(Event) error {
+		exported++
+		return nil
+	})
+	if exported != 5 {
+		t.Fatalf("导出数量不正确: %d", exported)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	db := setupAuditDB(t)
+	ctx := context.Background()
+	seedEvents(t, db, time.Now().Add(-time.Hour), 4)
+
+	if report := mustVerify(t); !report.OK || report.Checked != 4 {
+		t.Fatalf("未篡改时应校验通过: %+v", report)
+	}
+
+	_, _ = db.Exec(ctx, "UPDATE audit_events SET result = 'failed' WHERE id = 2")
+	if report := mustVerify(t); report.OK || report.Reason != ReasonHashMismatch || report.BrokenAt != 2 {
+		t.Fatalf("修改记录应被发现: %+v", report)
+	}
+	_, _ = db.Exec(ctx, "UPDATE audit_events SET result = 'ok' WHERE id = 2")
+
+	_, _ = db.Exec(ctx, "DELETE FROM audit_events WHERE id = 3")
+	if report := mustVerify(t); report.OK || report.Reason != ReasonChainBroken || report.BrokenAt != 4 {
+		t.Fatalf("删除中间记录应被发现: %+v", report)
+	}
+}
+
+func TestVerifyDetectsTruncation(t *testing.T) {
+	db := setupAuditDB(t)
+	seedEvents(t, db, time.Now().Add(-time.Hour), 3)
+	_, _ = db.Exec(context.Background(), "DELETE FROM audit_events WHERE id = 3")
+	if report := mustVerify(t); report.OK || report.Reason != ReasonTruncated {
+		t.Fatalf("删除最新记录应被发现: %+v", report)
+	}
+}
+
+func TestPruneArchivesAndKeepsChainValid(t *testing.T) {
+	db := setupAuditDB(t)
+	now := time.Now()
+	seedEvents(t, db, now.Add(-72*time.Hour), 3)
+	seedEvents(t, db, now.Add(-time.Hour), 2)
+	archiveDir := filepath.Join(t.TempDir(), "archive")
+
+	result, err := Prune(context.Background(), RetentionPolicy{MaxAge: 48 * time.Hour, ArchiveDir: archiveDir}, now)
+	if err != nil || result.Deleted != 3 || result.PrunedThrough != 3 {
+		t.Fatalf("清理结果不正确: %+v, %v", result, err)
+	}
+
+	f, err := os.Open(result.ArchiveFile)
+	if err != nil {
+		t.Fatalf("归档文件不存在: %v", err)
+	}
+	defer f.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		lines++
+	}
+	if lines != 3 {
+		t.Fatalf("归档应包含 3 条记录，得到 %d", lines)
+	}
+
+	// 剩余 2 条 + 清理自身写入的 audit_pruned 事件
+	if report := mustVerify(t); !report.OK || report.Checked != 3 {
+		t.Fatalf("清理后哈希链应保持有效: %+v", report)
+	}
+
+	_, _ = db.Exec(context.Background(), "DELETE FROM audit_events WHERE id = 4")
+	if report := mustVerify(t); report.OK || report.Reason != ReasonChainBroken {
+		t.Fatalf("越权删除链首应被发现: %+v", report)
+	}
+}
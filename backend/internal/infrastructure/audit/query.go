@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-study2/internal/infrastructure/database"
+)
+
+const (
+	// DefaultPageSize 查询默认每页条数。
+	DefaultPageSize = 50
+	// MaxPageSize 查询每页条数上限。
+	MaxPageSize = 200
+	exportBatch = 500
+)
+
+var (
+	// ErrUnavailable 数据库未初始化。
+	ErrUnavailable = errors.New("审计存储不可用")
+	// ErrInvalidCursor 分页游标无法解析。
+	ErrInvalidCursor = errors.New("分页游标无效")
+)
+
+// Filter 审计事件查询条件，零值字段不参与过滤。
+type Filter struct {
+	EventType string
+	UserID    int64
+	Result    string
+	From      time.Time
+	To        time.Time
+	// Cursor 为上一页返回的 NextCursor，结果按 ID 倒序。
+	Cursor string
+	Limit  int
+}
+
+// Page 一页审计事件。
+type Page struct {
+	Items []Event `json:"items"`
+	// NextCursor 为空表示没有更多数据。
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Query 按条件倒序分页查询审计事件。
+func Query(ctx context.Context, filter Filter) (*Page, error) {
+	before, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	events, err := queryBefore(ctx, filter, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{Items: events}
+	if len(events) > limit {
+		page.Items = events[:limit]
+		page.NextCursor = encodeCursor(page.Items[limit-1].ID)
+	}
+	return page, nil
+}
+
+// Each 按 ID 倒序遍历全部匹配事件，用于导出；忽略 Cursor 与 Limit。
+func Each(ctx context.Context, filter Filter, fn func(Event) error) error {
+	var before int64
+	for {
+		events, err := queryBefore(ctx, filter, before, exportBatch)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			if err := fn(ev); err != nil {
+				return err
+			}
+		}
+		if len(events) < exportBatch {
+			return nil
+		}
+		before = events[len(events)-1].ID
+	}
+}
+
+func queryBefore(ctx context.Context, filter Filter, before int64, limit int) ([]Event, error) {
+	db := database.Default()
+	if db == nil {
+		return nil, ErrUnavailable
+	}
+
+	conds := make([]string, 0, 6)
+	args := make([]any, 0, 7)
+	if filter.EventType != "" {
+		conds = append(conds, "event_type = ?")
+		args = append(args, filter.EventType)
+	}
+	if filter.UserID > 0 {
+		conds = append(conds, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Result != "" {
+		conds = append(conds, "result = ?")
+		args = append(args, filter.Result)
+	}
+	if !filter.From.IsZero() {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, filter.From.UTC().Format(timeLayout))
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, filter.To.UTC().Format(timeLayout))
+	}
+	if before > 0 {
+		conds = append(conds, "id < ?")
+		args = append(args, before)
+	}
+
+	sql := "SELECT " + eventColumns + " FROM audit_events"
+	if len(conds) > 0 {
+		sql += " WHERE " + strings.Join(conds, " AND ")
+	}
+	sql += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	records, err := db.GetAll(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(records))
+	for _, record := range records {
+		events = append(events, toEvent(record))
+	}
+	return events, nil
+}
+
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("id:%d", id)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), "id:") {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(string(raw), "id:"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
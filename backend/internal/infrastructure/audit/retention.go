@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-study2/internal/infrastructure/database"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// RetentionPolicy 审计事件保留策略。
+type RetentionPolicy struct {
+	// MaxAge 保留时长，零值表示永久保留。
+	MaxAge time.Duration
+	// ArchiveDir 非空时先将待清理事件归档为 JSONL 文件。
+	ArchiveDir string
+}
+
+// PruneResult 一次清理的结果。
+type PruneResult struct {
+	Deleted       int    `json:"deleted"`
+	PrunedThrough int64  `json:"prunedThrough"`
+	ArchiveFile   string `json:"archiveFile,omitempty"`
+}
+
+// Prune 删除早于保留期限的事件，并把最后一条被删除记录的哈希保存为链首锚点。
+func Prune(ctx context.Context, policy RetentionPolicy, now time.Time) (*PruneResult, error) {
+	result := &PruneResult{}
+	if policy.MaxAge <= 0 {
+		return result, nil
+	}
+	db := database.Default()
+	if db == nil {
+		return nil, ErrUnavailable
+	}
+	cutoff := now.Add(-policy.MaxAge).UTC().Format(timeLayout)
+
+	if err := func() error {
+		chainMu.Lock()
+		defer chainMu.Unlock()
+
+		// 仅清理连续的头部记录，保证剩余记录仍是一条完整的链
+		value, err := db.GetValue(ctx, "SELECT COALESCE(MAX(id), 0) FROM audit_events WHERE created_at < ?", cutoff)
+		if err != nil {
+			return err
+		}
+		through := value.Int64()
+		if through == 0 {
+			return nil
+		}
+
+		if policy.ArchiveDir != "" {
+			file, err := archiveThrough(ctx, db, policy.ArchiveDir, through, now)
+			if err != nil {
+				return err
+			}
+			result.ArchiveFile = file
+		}
+
+		return db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			anchor, err := tx.GetValue("SELECT hash FROM audit_events WHERE id = ?", through)
+			if err != nil {
+				return err
+			}
+			res, err := tx.Exec("DELETE FROM audit_events WHERE id <= ?", through)
+			if err != nil {
+				return err
+			}
+			deleted, _ := res.RowsAffected()
+			result.Deleted = int(deleted)
+			result.PrunedThrough = through
+			_, err = tx.Exec("UPDATE audit_chain_state SET anchor_hash = ?, pruned_through = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 1",
+				anchor.String(), through)
+			return err
+		})
+	}(); err != nil {
+		return nil, err
+	}
+
+	if result.Deleted > 0 {
+		RecordFields(ctx, "audit_pruned", 0, "ok", "", Fields{
+			"deleted":       result.Deleted,
+			"prunedThrough": result.PrunedThrough,
+			"archiveFile":   result.ArchiveFile,
+		})
+	}
+	return result, nil
+}
+
+// archiveThrough 将 ID 不大于 through 的事件按 ID 顺序写入归档文件。
+func archiveThrough(ctx context.Context, db gdb.DB, dir string, through int64, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建审计归档目录失败: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("audit-%s-%d.jsonl", now.UTC().Format("20060102T150405Z"), through))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("创建审计归档文件失败: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	var after int64
+	for after < through {
+		records, err := db.GetAll(ctx, "SELECT "+eventColumns+" FROM audit_events WHERE id > ? AND id <= ? ORDER BY id ASC LIMIT ?", after, through, exportBatch)
+		if err != nil {
+			return "", err
+		}
+		if len(records) == 0 {
+			break
+		}
+		for _, record := range records {
+			ev := toEvent(record)
+			if err := enc.Encode(ev); err != nil {
+				return "", err
+			}
+			after = ev.ID
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return path, f.Sync()
+}
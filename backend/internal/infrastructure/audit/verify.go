@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"go-study2/internal/infrastructure/database"
+)
+
+// 哈希链校验失败原因。
+const (
+	ReasonHashMismatch = "hash_mismatch"
+	ReasonChainBroken  = "chain_broken"
+	ReasonUnsealed     = "unsealed"
+	ReasonTruncated    = "truncated"
+)
+
+const verifyBatch = 1000
+
+// VerifyReport 哈希链校验结果。
+type VerifyReport struct {
+	OK bool `json:"ok"`
+	// Checked 已校验的封存记录数。
+	Checked int `json:"checked"`
+	// Legacy 启用哈希链之前写入、无法校验的记录数。
+	Legacy int `json:"legacy"`
+	// BrokenAt 首条异常记录的 ID；记录被截断时为 0。
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+func (r *VerifyReport) fail(id int64, reason, message string) *VerifyReport {
+	r.OK = false
+	r.BrokenAt = id
+	r.Reason = reason
+	r.Message = message
+	return r
+}
+
+// Verify 按 ID 顺序重算哈希链，检测被修改、删除或插入的审计记录。
+//
+// 链首需与最近一次清理留下的锚点一致，链尾需与最新写入的哈希一致，因此中间删除、
+// 头部越权删除与尾部截断都会被发现。
+func Verify(ctx context.Context) (*VerifyReport, error) {
+	db := database.Default()
+	if db == nil {
+		return nil, ErrUnavailable
+	}
+
+	chainMu.Lock()
+	defer chainMu.Unlock()
+
+	state, err := db.GetOne(ctx, "SELECT anchor_hash, head_hash FROM audit_chain_state WHERE id = 1")
+	if err != nil {
+		return nil, err
+	}
+	expectedPrev := state["anchor_hash"].String()
+	head := state["head_hash"].String()
+
+	report := &VerifyReport{OK: true}
+	sealed := false
+	var after int64
+	for {
+		records, err := db.GetAll(ctx, "SELECT "+eventColumns+" FROM audit_events WHERE id > ? ORDER BY id ASC LIMIT ?", after, verifyBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			ev := toEvent(record)
+			after = ev.ID
+			if ev.Hash == "" {
+				if sealed {
+					return report.fail(ev.ID, ReasonUnsealed, "哈希链中出现未封存的记录"), nil
+				}
+				report.Legacy++
+				continue
+			}
+			sealed = true
+			if ev.PrevHash != expectedPrev {
+				return report.fail(ev.ID, ReasonChainBroken, fmt.Sprintf("记录 %d 与前一条记录不连续，可能有记录被删除或插入", ev.ID)), nil
+			}
+			if chainHash(ev.PrevHash, ev.EventType, ev.UserID, ev.Result, ev.rawMetadata, ev.createdAtText) != ev.Hash {
+				return report.fail(ev.ID, ReasonHashMismatch, fmt.Sprintf("记录 %d 内容与哈希不符，可能被修改", ev.ID)), nil
+			}
+			expectedPrev = ev.Hash
+			report.Checked++
+		}
+		if len(records) < verifyBatch {
+			break
+		}
+	}
+
+	if expectedPrev != head {
+		return report.fail(0, ReasonTruncated, "最新记录与链尾哈希不符，可能有记录被截断"), nil
+	}
+	return report, nil
+}
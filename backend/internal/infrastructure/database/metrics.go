@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go-study2/internal/infrastructure/tracing"
+	"go-study2/internal/pkg/metrics"
+
+	"github.com/gogf/gf/contrib/drivers/sqlite/v2"
+	"github.com/gogf/gf/v2/database/gdb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var queryDuration = metrics.Default.NewHistogram("gostudy_db_query_duration_seconds",
+	"数据库操作耗时（秒），按操作类型区分", metrics.DefaultBuckets, "operation")
+
+func init() {
+	// 覆盖 sqlite 驱动注册，所有经由 gdb 的 SQL 都会记录耗时
+	if err := gdb.Register("sqlite", &instrumentedDriver{}); err != nil {
+		panic(err)
+	}
+
+	metrics.Default.NewGaugeFunc("gostudy_db_connections", "数据库连接池中的连接数，按状态区分", func() []metrics.Sample {
+		stats, ok := poolStats()
+		if !ok {
+			return nil
+		}
+		return []metrics.Sample{
+			{LabelValues: []string{"in_use"}, Value: float64(stats.InUse)},
+			{LabelValues: []string{"idle"}, Value: float64(stats.Idle)},
+		}
+	}, "state")
+	metrics.Default.NewGaugeFunc("gostudy_db_max_open_connections", "数据库连接池允许的最大连接数，0 表示不限", func() []metrics.Sample {
+		stats, ok := poolStats()
+		if !ok {
+			return nil
+		}
+		return []metrics.Sample{{Value: float64(stats.MaxOpenConnections)}}
+	})
+	metrics.Default.NewCounterFunc("gostudy_db_wait_count_total", "因连接池耗尽而等待连接的次数", func() []metrics.Sample {
+		stats, ok := poolStats()
+		if !ok {
+			return nil
+		}
+		return []metrics.Sample{{Value: float64(stats.WaitCount)}}
+	})
+	metrics.Default.NewCounterFunc("gostudy_db_wait_duration_seconds_total", "等待连接的累计时间（秒）", func() []metrics.Sample {
+		stats, ok := poolStats()
+		if !ok {
+			return nil
+		}
+		return []metrics.Sample{{Value: stats.WaitDuration.Seconds()}}
+	})
+}
+
+// instrumentedDriver 在 sqlite 驱动外层统计每次数据库操作的耗时。
+type instrumentedDriver struct {
+	*sqlite.Driver
+}
+
+// New 实现 gdb.Driver。
+func (d *instrumentedDriver) New(core *gdb.Core, node *gdb.ConfigNode) (gdb.DB, error) {
+	return &instrumentedDriver{Driver: &sqlite.Driver{Core: core}}, nil
+}
+
+// DoCommit 是查询、执行、预处理与事务操作的统一出口。
+func (d *instrumentedDriver) DoCommit(ctx context.Context, in gdb.DoCommitInput) (gdb.DoCommitOutput, error) {
+	operation := operationLabel(in.Type)
+	ctx, span := startQuerySpan(ctx, operation, in.Sql)
+	start := time.Now()
+	out, err := d.Driver.DoCommit(ctx, in)
+	queryDuration.With(operation).Observe(time.Since(start).Seconds())
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	return out, err
+}
+
+// startQuerySpan 在已处于追踪中的调用下创建数据库 span，语句只记录带占位符的 SQL，不记录实参；
+// 没有父 span 的调用（如定时轮询）返回 nil，避免产生大量孤立的 span。
+func startQuerySpan(ctx context.Context, operation, statement string) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx, nil
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "sqlite"),
+		attribute.String("db.operation", operation),
+	}
+	if statement != "" {
+		attrs = append(attrs, attribute.String("db.statement", statement))
+	}
+	return tracing.Tracer().Start(ctx, "db."+operation,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// operationLabel 把 gdb 的操作类型归并为 query、exec 等少量标签值。
+func operationLabel(t gdb.SqlType) string {
+	switch t {
+	case gdb.SqlTypeQueryContext, gdb.SqlTypeStmtQueryContext, gdb.SqlTypeStmtQueryRowContext:
+		return "query"
+	case gdb.SqlTypeExecContext, gdb.SqlTypeStmtExecContext:
+		return "exec"
+	case gdb.SqlTypePrepareContext:
+		return "prepare"
+	case gdb.SqlTypeBegin:
+		return "begin"
+	case gdb.SqlTypeTXCommit:
+		return "commit"
+	case gdb.SqlTypeTXRollback:
+		return "rollback"
+	}
+	return "other"
+}
+
+// poolStats 汇总默认数据库各节点的连接池状态，未初始化时返回 false。
+func poolStats() (stats sql.DBStats, ok bool) {
+	db := Default()
+	if db == nil {
+		return stats, false
+	}
+	for _, item := range db.Stats(context.Background()) {
+		s := item.Stats()
+		stats.InUse += s.InUse
+		stats.Idle += s.Idle
+		stats.MaxOpenConnections += s.MaxOpenConnections
+		stats.WaitCount += s.WaitCount
+		stats.WaitDuration += s.WaitDuration
+	}
+	return stats, true
+}
@@ -8,6 +8,10 @@ import (
 	"github.com/gogf/gf/v2/database/gdb"
 )
 
+// SchemaVersion 为当前代码期望的数据库结构版本，迁移完成后写入 PRAGMA user_version；
+// 新增表或列时递增，就绪检查据此发现未迁移或由更新版本迁移过的数据库。
+const SchemaVersion = 2
+
 // Migrate 执行数据库迁移，创建核心表结构。
 func Migrate(ctx context.Context, db gdb.DB) error {
 	migrations := []string{
@@ -16,6 +20,38 @@ func Migrate(ctx context.Context, db gdb.DB) error {
 		createQuizRecordsTableSQL,
 		createRefreshTokensTableSQL,
 		createAuditEventsTableSQL,
+		createUserMFATableSQL,
+		createMFARecoveryCodesTableSQL,
+		createMFAChallengesTableSQL,
+		createInvitesTableSQL,
+		createInviteRedemptionsTableSQL,
+		createUserIdentitiesTableSQL,
+		createExternalLoginStatesTableSQL,
+		createPersonalAccessTokensTableSQL,
+		createPasswordHistoryTableSQL,
+		createAuditChainStateTableSQL,
+		createClassesTableSQL,
+		createClassMembersTableSQL,
+		createClassAssignmentsTableSQL,
+		createQuizQuestionsTableSQL,
+		createQuizQuestionTagsTableSQL,
+		createQuizQuestionRevisionsTableSQL,
+		createQuizChapterBestTableSQL,
+		createQuizWeeklyBestTableSQL,
+		createLeaderboardPreferencesTableSQL,
+		createNotesTableSQL,
+		createNoteTagsTableSQL,
+		createNotesFTSTableSQL,
+		createDiscussionThreadsTableSQL,
+		createDiscussionPostsTableSQL,
+		createDiscussionReadsTableSQL,
+		createDiscussionMentionsTableSQL,
+		createNotificationsTableSQL,
+		createNotificationPreferencesTableSQL,
+		createWebhookEndpointsTableSQL,
+		createWebhookDeliveriesTableSQL,
+		createScheduledJobsTableSQL,
+		createJobRunsTableSQL,
 	}
 
 	for _, stmt := range migrations {
@@ -34,8 +70,30 @@ func Migrate(ctx context.Context, db gdb.DB) error {
 	if err := ensureUserColumns(ctx, db); err != nil {
 		return err
 	}
+	if err := ensureAuditColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := ensureClassColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := ensureInviteColumns(ctx, db); err != nil {
+		return err
+	}
+	if err := backfillLeaderboardAggregates(ctx, db); err != nil {
+		return err
+	}
 
-	return nil
+	_, err := db.Exec(ctx, fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion))
+	return err
+}
+
+// CurrentSchemaVersion 读取数据库中记录的结构版本，未执行过迁移时为 0。
+func CurrentSchemaVersion(ctx context.Context, db gdb.DB) (int, error) {
+	value, err := db.GetValue(ctx, "PRAGMA user_version")
+	if err != nil {
+		return 0, err
+	}
+	return value.Int(), nil
 }
 
 const createUsersTableSQL = `
@@ -49,8 +107,63 @@ CREATE TABLE IF NOT EXISTS users (
 CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(username);
 `
 
+type columnDef struct {
+	name string
+	def  string
+}
+
 func ensureUserColumns(ctx context.Context, db gdb.DB) error {
-	columns, err := db.GetAll(ctx, "PRAGMA table_info(users)")
+	return ensureColumns(ctx, db, "users", []columnDef{
+		{name: "is_admin", def: "INTEGER NOT NULL DEFAULT 0"},
+		{name: "is_teacher", def: "INTEGER NOT NULL DEFAULT 0"},
+		{name: "status", def: "TEXT NOT NULL DEFAULT 'active'"},
+		{name: "must_change_password", def: "INTEGER NOT NULL DEFAULT 0"},
+		// SQLite 不允许以 CURRENT_TIMESTAMP 作为新增列默认值，存量用户为空时按 created_at 计算
+		{name: "password_changed_at", def: "DATETIME"},
+	})
+}
+
+// ensureAuditColumns 为审计表补充哈希链字段，存量记录保持为空视为未封存。
+func ensureAuditColumns(ctx context.Context, db gdb.DB) error {
+	return ensureColumns(ctx, db, "audit_events", []columnDef{
+		{name: "prev_hash", def: "TEXT NOT NULL DEFAULT ''"},
+		{name: "hash", def: "TEXT NOT NULL DEFAULT ''"},
+	})
+}
+
+// ensureClassColumns 为班级表补充排行榜开关，默认开启。
+func ensureClassColumns(ctx context.Context, db gdb.DB) error {
+	return ensureColumns(ctx, db, "classes", []columnDef{
+		{name: "leaderboard_enabled", def: "INTEGER NOT NULL DEFAULT 1"},
+	})
+}
+
+// ensureInviteColumns 为邀请码表补充绑定班级字段，存量邀请码不绑定班级。
+func ensureInviteColumns(ctx context.Context, db gdb.DB) error {
+	return ensureColumns(ctx, db, "invites", []columnDef{
+		{name: "class_id", def: "INTEGER"},
+	})
+}
+
+// backfillLeaderboardAggregates 在排行榜汇总表为空时按历史测验记录一次性重建，之后由写入测验记录时增量维护。
+func backfillLeaderboardAggregates(ctx context.Context, db gdb.DB) error {
+	count, err := db.GetValue(ctx, "SELECT COUNT(*) FROM quiz_chapter_best")
+	if err != nil {
+		return err
+	}
+	if count.Int() > 0 {
+		return nil
+	}
+	if _, err := db.Exec(ctx, backfillQuizChapterBestSQL); err != nil {
+		return err
+	}
+	_, err = db.Exec(ctx, backfillQuizWeeklyBestSQL)
+	return err
+}
+
+// ensureColumns 为已存在的表补齐缺失列，兼容旧版本数据库。
+func ensureColumns(ctx context.Context, db gdb.DB, table string, additions []columnDef) error {
+	columns, err := db.GetAll(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
 	if err != nil {
 		return err
 	}
@@ -63,22 +176,11 @@ func ensureUserColumns(ctx context.Context, db gdb.DB) error {
 		return false
 	}
 
-	type columnDef struct {
-		name string
-		def  string
-	}
-
-	additions := []columnDef{
-		{name: "is_admin", def: "INTEGER NOT NULL DEFAULT 0"},
-		{name: "status", def: "TEXT NOT NULL DEFAULT 'active'"},
-		{name: "must_change_password", def: "INTEGER NOT NULL DEFAULT 0"},
-	}
-
 	for _, col := range additions {
 		if has(col.name) {
 			continue
 		}
-		if _, err := db.Exec(ctx, fmt.Sprintf("ALTER TABLE users ADD COLUMN %s %s", col.name, col.def)); err != nil {
+		if _, err := db.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, col.name, col.def)); err != nil {
 			return err
 		}
 	}
@@ -146,3 +248,482 @@ CREATE INDEX IF NOT EXISTS idx_audit_events_type ON audit_events(event_type);
 CREATE INDEX IF NOT EXISTS idx_audit_events_user ON audit_events(user_id);
 CREATE INDEX IF NOT EXISTS idx_audit_events_created ON audit_events(created_at DESC);
 `
+
+const createUserMFATableSQL = `
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id INTEGER PRIMARY KEY,
+    secret TEXT NOT NULL,
+    enabled INTEGER NOT NULL DEFAULT 0,
+    last_used_step INTEGER NOT NULL DEFAULT 0,
+    confirmed_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+`
+
+const createMFARecoveryCodesTableSQL = `
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(user_id, code_hash)
+);
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_user ON mfa_recovery_codes(user_id);
+`
+
+const createMFAChallengesTableSQL = `
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_expires ON mfa_challenges(expires_at);
+`
+
+const createInvitesTableSQL = `
+CREATE TABLE IF NOT EXISTS invites (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code_hash TEXT NOT NULL UNIQUE,
+    code_hint TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT 'user',
+    class_id INTEGER,
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME,
+    revoked_at DATETIME,
+    note TEXT NOT NULL DEFAULT '',
+    created_by INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const createInviteRedemptionsTableSQL = `
+CREATE TABLE IF NOT EXISTS invite_redemptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    invite_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invite_id) REFERENCES invites(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_invite_redemptions_invite ON invite_redemptions(invite_id);
+`
+
+const createUserIdentitiesTableSQL = `
+CREATE TABLE IF NOT EXISTS user_identities (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_login_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+`
+
+const createExternalLoginStatesTableSQL = `
+CREATE TABLE IF NOT EXISTS external_login_states (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    state_hash TEXT NOT NULL UNIQUE,
+    provider TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_external_login_states_expires ON external_login_states(expires_at);
+`
+
+const createPersonalAccessTokensTableSQL = `
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL,
+    scopes TEXT NOT NULL,
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    last_used_ip TEXT NOT NULL DEFAULT '',
+    revoked_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user ON personal_access_tokens(user_id);
+`
+
+const createPasswordHistoryTableSQL = `
+CREATE TABLE IF NOT EXISTS password_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history(user_id, id);
+`
+
+// audit_chain_state 仅有一行：anchor_hash 为已清理的最后一条记录哈希，head_hash 为最新记录哈希。
+const createAuditChainStateTableSQL = `
+CREATE TABLE IF NOT EXISTS audit_chain_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    anchor_hash TEXT NOT NULL DEFAULT '',
+    head_hash TEXT NOT NULL DEFAULT '',
+    pruned_through INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT OR IGNORE INTO audit_chain_state (id) VALUES (1);
+`
+
+const createClassesTableSQL = `
+CREATE TABLE IF NOT EXISTS classes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    join_code TEXT NOT NULL UNIQUE,
+    created_by INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const createClassMembersTableSQL = `
+CREATE TABLE IF NOT EXISTS class_members (
+    class_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role TEXT NOT NULL DEFAULT 'student',
+    joined_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (class_id, user_id),
+    FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_class_members_user ON class_members(user_id);
+`
+
+// opens_at/due_at 以 UTC 文本保存，便于与 quiz_records.created_at 直接比较。
+const createClassAssignmentsTableSQL = `
+CREATE TABLE IF NOT EXISTS class_assignments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    class_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    chapter TEXT NOT NULL,
+    min_score INTEGER NOT NULL DEFAULT 0,
+    opens_at DATETIME NOT NULL,
+    due_at DATETIME NOT NULL,
+    created_by INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_class_assignments_class ON class_assignments(class_id, due_at);
+`
+
+// options 以 JSON 数组保存；question_id 为测验中使用的题目 ID。
+const createQuizQuestionsTableSQL = `
+CREATE TABLE IF NOT EXISTS quiz_questions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    question_id TEXT NOT NULL UNIQUE,
+    topic TEXT NOT NULL,
+    chapter TEXT NOT NULL,
+    stem TEXT NOT NULL,
+    options TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    explanation TEXT NOT NULL DEFAULT '',
+    difficulty TEXT NOT NULL DEFAULT 'medium',
+    rule_ref TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'draft',
+    revision INTEGER NOT NULL DEFAULT 1,
+    created_by INTEGER NOT NULL,
+    updated_by INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_chapter ON quiz_questions(topic, chapter, status);
+`
+
+const createQuizQuestionTagsTableSQL = `
+CREATE TABLE IF NOT EXISTS quiz_question_tags (
+    question_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (question_id, tag),
+    FOREIGN KEY (question_id) REFERENCES quiz_questions(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_quiz_question_tags_tag ON quiz_question_tags(tag);
+`
+
+// snapshot 保存该版本题目的完整 JSON。
+const createQuizQuestionRevisionsTableSQL = `
+CREATE TABLE IF NOT EXISTS quiz_question_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    question_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    editor_id INTEGER NOT NULL,
+    snapshot TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (question_id, revision),
+    FOREIGN KEY (question_id) REFERENCES quiz_questions(id) ON DELETE CASCADE
+);
+`
+
+// quiz_chapter_best 为每个用户每章节的最佳得分率物化汇总，mastered_at 为首次达到掌握线的时间。
+const createQuizChapterBestTableSQL = `
+CREATE TABLE IF NOT EXISTS quiz_chapter_best (
+    user_id INTEGER NOT NULL,
+    topic TEXT NOT NULL,
+    chapter TEXT NOT NULL,
+    best_percent REAL NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    mastered_at DATETIME,
+    last_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, topic, chapter),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_quiz_chapter_best_chapter ON quiz_chapter_best(topic, chapter, best_percent DESC);
+`
+
+// quiz_weekly_best 按自然周（周一开始，UTC）记录每章节的最佳得分率，用于周积分。
+const createQuizWeeklyBestTableSQL = `
+CREATE TABLE IF NOT EXISTS quiz_weekly_best (
+    user_id INTEGER NOT NULL,
+    week_start TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    chapter TEXT NOT NULL,
+    best_percent REAL NOT NULL,
+    PRIMARY KEY (user_id, week_start, topic, chapter),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_quiz_weekly_best_week ON quiz_weekly_best(week_start, user_id);
+`
+
+const createLeaderboardPreferencesTableSQL = `
+CREATE TABLE IF NOT EXISTS leaderboard_preferences (
+    user_id INTEGER PRIMARY KEY,
+    opt_out INTEGER NOT NULL DEFAULT 0,
+    nickname TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leaderboard_nickname ON leaderboard_preferences(nickname) WHERE nickname != '';
+`
+
+// 掌握线 80% 与 leaderboard.MasteryPercent 保持一致。
+const backfillQuizChapterBestSQL = `
+INSERT OR IGNORE INTO quiz_chapter_best (user_id, topic, chapter, best_percent, attempts, mastered_at, last_at)
+SELECT user_id, topic, COALESCE(chapter, ''), MAX(score * 100.0 / total), COUNT(*),
+       MIN(CASE WHEN score * 100.0 / total >= 80 THEN created_at END), MAX(created_at)
+FROM quiz_records WHERE total > 0
+GROUP BY user_id, topic, COALESCE(chapter, '')`
+
+const backfillQuizWeeklyBestSQL = `
+INSERT OR IGNORE INTO quiz_weekly_best (user_id, week_start, topic, chapter, best_percent)
+SELECT user_id, date(created_at, 'weekday 0', '-6 days'), topic, COALESCE(chapter, ''), MAX(score * 100.0 / total)
+FROM quiz_records WHERE total > 0
+GROUP BY user_id, date(created_at, 'weekday 0', '-6 days'), topic, COALESCE(chapter, '')`
+
+// notes 保存个人笔记与书签，anchor 为章节内的小节或示例 ID，同一位置只能有一个书签。
+const createNotesTableSQL = `
+CREATE TABLE IF NOT EXISTS notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL DEFAULT 'note',
+    topic TEXT NOT NULL,
+    chapter TEXT NOT NULL,
+    anchor TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_notes_user_position ON notes(user_id, topic, chapter, anchor);
+CREATE INDEX IF NOT EXISTS idx_notes_user_updated ON notes(user_id, updated_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notes_bookmark ON notes(user_id, topic, chapter, anchor) WHERE kind = 'bookmark';
+`
+
+const createNoteTagsTableSQL = `
+CREATE TABLE IF NOT EXISTS note_tags (
+    note_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (note_id, tag),
+    FOREIGN KEY (note_id) REFERENCES notes(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_note_tags_tag ON note_tags(tag);
+`
+
+// notes_fts 为笔记全文索引，rowid 与 notes.id 一致，由仓储随笔记写入同步维护。
+// trigram 分词对中文同样有效，LIKE 查询在关键词不少于 3 个字符时可走索引。
+const createNotesFTSTableSQL = `
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(title, body, tags, tokenize = 'trigram');
+`
+
+// discussion_threads 为章节或测验题下的讨论主题，question_id 为空表示章节讨论；
+// hidden 与首帖的隐藏状态同步，last_post_at 用于列表排序。
+const createDiscussionThreadsTableSQL = `
+CREATE TABLE IF NOT EXISTS discussion_threads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    topic TEXT NOT NULL,
+    chapter TEXT NOT NULL,
+    question_id TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL,
+    author_id INTEGER NOT NULL,
+    root_post_id INTEGER NOT NULL DEFAULT 0,
+    accepted_post_id INTEGER NOT NULL DEFAULT 0,
+    hidden INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_post_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_discussion_threads_position ON discussion_threads(topic, chapter, question_id, last_post_at DESC);
+CREATE INDEX IF NOT EXISTS idx_discussion_threads_last ON discussion_threads(last_post_at DESC);
+`
+
+const createDiscussionPostsTableSQL = `
+CREATE TABLE IF NOT EXISTS discussion_posts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    thread_id INTEGER NOT NULL,
+    parent_id INTEGER NOT NULL DEFAULT 0,
+    author_id INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    hidden INTEGER NOT NULL DEFAULT 0,
+    hidden_by INTEGER,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (thread_id) REFERENCES discussion_threads(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_discussion_posts_thread ON discussion_posts(thread_id, id);
+`
+
+// discussion_reads 记录用户在每个主题中已读到的最大帖子 ID，用于计算未读数。
+const createDiscussionReadsTableSQL = `
+CREATE TABLE IF NOT EXISTS discussion_reads (
+    user_id INTEGER NOT NULL,
+    thread_id INTEGER NOT NULL,
+    last_post_id INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, thread_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (thread_id) REFERENCES discussion_threads(id) ON DELETE CASCADE
+);
+`
+
+const createDiscussionMentionsTableSQL = `
+CREATE TABLE IF NOT EXISTS discussion_mentions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    post_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    read_at DATETIME,
+    UNIQUE (user_id, post_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES discussion_posts(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_discussion_mentions_user ON discussion_mentions(user_id, read_at, id DESC);
+`
+
+// notifications 为用户的通知收件箱，自增 ID 同时作为 SSE 的事件 ID 用于断线续传。
+const createNotificationsTableSQL = `
+CREATE TABLE IF NOT EXISTS notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    actor_id INTEGER NOT NULL DEFAULT 0,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    link TEXT NOT NULL DEFAULT '',
+    read_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id, id) WHERE read_at IS NULL;
+`
+
+// notification_preferences 只保存用户显式设置过的类型，缺省视为接收。
+const createNotificationPreferencesTableSQL = `
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+`
+
+// webhook_endpoints 保存 Webhook 接收地址，events 为空格分隔的订阅事件类型。
+const createWebhookEndpointsTableSQL = `
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL,
+    active INTEGER NOT NULL DEFAULT 1,
+    created_by INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// webhook_deliveries 为 Webhook 发件箱：事件先落库再投递，next_attempt_at 同时用作领取期限。
+const createWebhookDeliveriesTableSQL = `
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_id INTEGER NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    response_status INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at DATETIME,
+    FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+`
+
+// scheduled_jobs 保存后台任务的调度状态，lease_owner/lease_until 为保证单实例执行的租约。
+const createScheduledJobsTableSQL = `
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    name TEXT PRIMARY KEY,
+    schedule TEXT NOT NULL,
+    paused INTEGER NOT NULL DEFAULT 0,
+    next_run_at DATETIME NOT NULL,
+    lease_owner TEXT NOT NULL DEFAULT '',
+    lease_until DATETIME,
+    last_error TEXT NOT NULL DEFAULT '',
+    last_error_at DATETIME,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const createJobRunsTableSQL = `
+CREATE TABLE IF NOT EXISTS job_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_name TEXT NOT NULL,
+    trigger_type TEXT NOT NULL,
+    status TEXT NOT NULL,
+    instance TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    result TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_job_runs_job ON job_runs(job_name, id DESC);
+`
@@ -0,0 +1,134 @@
+// Package eventbus 提供进程内的领域事件总线：领域服务只负责发布事件，
+// 通知、推送等投递方式作为订阅者接入，领域层因此不依赖具体传输方式。
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// 站内通知事件类型，同时作为通知类型与用户偏好的键。
+const (
+	// TypeAssignment 表示班级布置了新作业。
+	TypeAssignment = "assignment"
+	// TypeReviewDue 表示有待复习的内容到期。
+	TypeReviewDue = "review_due"
+	// TypeAchievement 表示用户获得成就。
+	TypeAchievement = "achievement"
+	// TypeDiscussionReply 表示用户发起的讨论或帖子收到回复。
+	TypeDiscussionReply = "discussion_reply"
+	// TypeMention 表示用户在讨论中被 @ 提及。
+	TypeMention = "mention"
+)
+
+// 学习事件类型，供 Webhook 等外部集成订阅，不生成站内通知。
+const (
+	// TypeQuizSubmitted 表示用户提交了测验。
+	TypeQuizSubmitted = "quiz.submitted"
+	// TypeProgressDone 表示用户完成了某章节。
+	TypeProgressDone = "progress.done"
+	// TypeUserCreated 表示创建了新用户。
+	TypeUserCreated = "user.created"
+)
+
+// Types 返回全部站内通知事件类型，顺序固定。
+func Types() []string {
+	return []string{TypeAssignment, TypeReviewDue, TypeAchievement, TypeDiscussionReply, TypeMention}
+}
+
+// LearningTypes 返回全部学习事件类型，顺序固定。
+func LearningTypes() []string {
+	return []string{TypeQuizSubmitted, TypeProgressDone, TypeUserCreated}
+}
+
+// Event 为一次领域事件，UserIDs 为需要知晓该事件的用户。
+type Event struct {
+	Type    string
+	UserIDs []int64
+	// ActorID 为触发事件的用户，0 表示系统。
+	ActorID int64
+	Title   string
+	Body    string
+	// Link 为前端可跳转的站内路径。
+	Link       string
+	Data       map[string]interface{}
+	OccurredAt time.Time
+}
+
+// Publisher 为领域服务依赖的发布接口。
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// Handler 处理事件，错误由订阅者自行记录。
+type Handler func(ctx context.Context, event Event)
+
+// Bus 按订阅顺序同步分发事件，单个订阅者 panic 不影响发布者与其他订阅者。
+type Bus struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]Handler
+	order    []int
+}
+
+// New 创建事件总线。
+func New() *Bus {
+	return &Bus{handlers: make(map[int]Handler)}
+}
+
+// Subscribe 注册订阅者，返回取消订阅的函数。
+func (b *Bus) Subscribe(handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	b.handlers[id] = handler
+	b.order = append(b.order, id)
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+		for i, v := range b.order {
+			if v == id {
+				b.order = append(b.order[:i:i], b.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Publish 将事件分发给全部订阅者，未设置发生时间时取当前时间。
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.order))
+	for _, id := range b.order {
+		handlers = append(handlers, b.handlers[id])
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		dispatch(ctx, handler, event)
+	}
+}
+
+func dispatch(ctx context.Context, handler Handler, event Event) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			g.Log().Errorf(ctx, "事件订阅者处理 %s 失败: %v", event.Type, rec)
+		}
+	}()
+	handler(ctx, event)
+}
+
+var defaultBus = New()
+
+// Default 返回进程级事件总线。
+func Default() *Bus {
+	return defaultBus
+}
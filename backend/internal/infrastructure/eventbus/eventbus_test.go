@@ -0,0 +1,33 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBus_PublishOrderUnsubscribeAndPanic(t *testing.T) {
+	bus := New()
+	var got []string
+	bus.Subscribe(func(_ context.Context, e Event) { got = append(got, "a:"+e.Type) })
+	bus.Subscribe(func(_ context.Context, _ Event) { panic("订阅者异常") })
+	cancel := bus.Subscribe(func(_ context.Context, e Event) {
+		if e.OccurredAt.IsZero() {
+			t.Errorf("发布时应补充发生时间")
+		}
+		got = append(got, "c:"+e.Type)
+	})
+
+	bus.Publish(context.Background(), Event{Type: TypeMention})
+	cancel()
+	bus.Publish(context.Background(), Event{Type: TypeAssignment})
+
+	want := []string{"a:mention", "c:mention", "a:assignment"}
+	if len(got) != len(want) {
+		t.Fatalf("分发结果 = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("分发结果 = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package logging 按 LoggerConfig 配置 glog：每行日志附带请求 ID，format 为 json 时每行输出一个 JSON 对象，
+// 包含时间、级别、消息、请求 ID、追踪 ID 与通过 Fields 传入的结构化字段，便于日志平台检索与关联。
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/pkg/requestid"
+
+	"github.com/gogf/gf/v2/os/glog"
+	"github.com/gogf/gf/v2/util/gconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// FormatJSON 以 JSON 行输出日志的格式名。
+const FormatJSON = "json"
+
+// Fields 结构化日志字段，作为日志参数传入；JSON 格式下展开为顶层字段，文本格式下以 JSON 追加在消息后。
+type Fields map[string]any
+
+// reservedKeys 由处理器填写的字段，Fields 中的同名键会被忽略。
+var reservedKeys = map[string]struct{}{
+	"time": {}, "level": {}, "msg": {}, "request_id": {}, "trace_id": {}, "span_id": {}, "caller": {}, "stack": {},
+}
+
+// Configure 将日志配置应用到 logger：文本格式在行首输出请求 ID，json 格式改用 HandlerJSON。
+func Configure(logger *glog.Logger, cfg config.LoggerConfig) {
+	if cfg.Format == FormatJSON {
+		logger.SetHandlers(HandlerJSON)
+		return
+	}
+	logger.SetCtxKeys(requestid.CtxKey)
+}
+
+// HandlerJSON 将一条日志输出为单行 JSON。
+func HandlerJSON(ctx context.Context, in *glog.HandlerInput) {
+	entry := make(map[string]any, 8)
+	var msg []string
+	if in.Content != "" {
+		msg = append(msg, in.Content)
+	}
+	for _, v := range in.Values {
+		if fields, ok := v.(Fields); ok {
+			for k, fv := range fields {
+				if _, reserved := reservedKeys[k]; !reserved {
+					entry[k] = fv
+				}
+			}
+			continue
+		}
+		if s := gconv.String(v); s != "" {
+			msg = append(msg, s)
+		}
+	}
+
+	entry["time"] = in.Time.Format(time.RFC3339Nano)
+	entry["level"] = levelName(in.Level)
+	entry["msg"] = strings.Join(msg, " ")
+	if id := requestid.FromContext(ctx); id != "" {
+		entry["request_id"] = id
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		entry["trace_id"] = sc.TraceID().String()
+		entry["span_id"] = sc.SpanID().String()
+	} else if in.TraceId != "" {
+		entry["trace_id"] = in.TraceId
+	}
+	if in.CallerPath != "" {
+		entry["caller"] = strings.TrimSuffix(in.CallerPath, ":")
+	}
+	if in.Stack != "" {
+		entry["stack"] = in.Stack
+	}
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		raw, _ = json.Marshal(map[string]any{"time": entry["time"], "level": entry["level"], "msg": entry["msg"]})
+	}
+	in.Buffer.Write(raw)
+	in.Buffer.WriteByte('\n')
+	in.Next(ctx)
+}
+
+func levelName(level int) string {
+	switch level {
+	case glog.LEVEL_DEBU:
+		return "debug"
+	case glog.LEVEL_INFO:
+		return "info"
+	case glog.LEVEL_NOTI:
+		return "notice"
+	case glog.LEVEL_WARN:
+		return "warn"
+	case glog.LEVEL_ERRO:
+		return "error"
+	case glog.LEVEL_CRIT:
+		return "critical"
+	case glog.LEVEL_PANI:
+		return "panic"
+	case glog.LEVEL_FATA:
+		return "fatal"
+	default:
+		return "info"
+	}
+}
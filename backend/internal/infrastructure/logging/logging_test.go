@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/pkg/requestid"
+
+	"github.com/gogf/gf/v2/os/glog"
+	"github.com/gogf/gf/v2/test/gtest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestLogger(format string) (*glog.Logger, *bytes.Buffer) {
+	buf := new(bytes.Buffer)
+	logger := glog.New()
+	logger.SetWriter(buf)
+	logger.SetStdoutPrint(false)
+	Configure(logger, config.LoggerConfig{Format: format})
+	return logger, buf
+}
+
+func TestHandlerJSON(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		logger, buf := newTestLogger(FormatJSON)
+		sc := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x0a, 0x0b},
+			SpanID:     trace.SpanID{0x01},
+			TraceFlags: trace.FlagsSampled,
+		})
+		ctx := trace.ContextWithSpanContext(requestid.NewContext(context.Background(), "req-1"), sc)
+
+		logger.Warning(ctx, "http request", Fields{"status": 500, "route": "/api/v1/topics", "msg": "忽略"})
+		logger.Infof(context.Background(), "启动完成 %d", 1)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		t.Assert(len(lines), 2)
+		var entry map[string]any
+		t.AssertNil(json.Unmarshal([]byte(lines[0]), &entry))
+		t.Assert(entry["level"], "warn")
+		t.Assert(entry["msg"], "http request")
+		t.Assert(entry["request_id"], "req-1")
+		t.Assert(entry["trace_id"], sc.TraceID().String())
+		t.Assert(entry["span_id"], sc.SpanID().String())
+		t.Assert(entry["status"], 500)
+		t.Assert(entry["route"], "/api/v1/topics")
+		t.AssertNE(entry["time"], nil)
+
+		entry = nil
+		t.AssertNil(json.Unmarshal([]byte(lines[1]), &entry))
+		t.Assert(entry["level"], "info")
+		t.Assert(entry["msg"], "启动完成 1")
+		_, hasRequestID := entry["request_id"]
+		t.Assert(hasRequestID, false)
+	})
+}
+
+func TestConfigureText(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		logger, buf := newTestLogger("text")
+		logger.Info(requestid.NewContext(context.Background(), "req-2"), "http request", Fields{"status": 200})
+		out := buf.String()
+		t.Assert(strings.Contains(out, "{req-2}"), true)
+		t.Assert(strings.Contains(out, `http request {"status":200}`), true)
+	})
+}
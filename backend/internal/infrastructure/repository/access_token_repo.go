@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// AccessTokenRepository 使用 GoFrame gdb 实现个人访问令牌仓储。
+type AccessTokenRepository struct {
+	db gdb.DB
+}
+
+// NewAccessTokenRepository 创建个人访问令牌仓储。
+func NewAccessTokenRepository(db gdb.DB) *AccessTokenRepository {
+	return &AccessTokenRepository{db: db}
+}
+
+// CreateAccessToken 保存令牌哈希，权限范围以空格分隔存储。
+func (r *AccessTokenRepository) CreateAccessToken(ctx context.Context, token *user.AccessToken) (int64, error) {
+	data := map[string]interface{}{
+		"user_id":      token.UserID,
+		"name":         token.Name,
+		"token_hash":   token.TokenHash,
+		"token_prefix": token.Prefix,
+		"scopes":       strings.Join(token.Scopes, " "),
+	}
+	if token.ExpiresAt != nil {
+		data["expires_at"] = *token.ExpiresAt
+	}
+	res, err := r.db.Insert(ctx, "personal_access_tokens", data)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ListAccessTokens 按创建顺序列出用户未撤销的令牌。
+func (r *AccessTokenRepository) ListAccessTokens(ctx context.Context, userID int64) ([]user.AccessToken, error) {
+	records, err := r.db.Model("personal_access_tokens").
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		OrderAsc("id").
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]user.AccessToken, 0, len(records))
+	for _, record := range records {
+		tokens = append(tokens, *toAccessToken(record))
+	}
+	return tokens, nil
+}
+
+// FindAccessTokenByHash 通过哈希查询令牌（包含已撤销的），不存在时返回 nil。
+func (r *AccessTokenRepository) FindAccessTokenByHash(ctx context.Context, tokenHash string) (*user.AccessToken, error) {
+	record, err := r.db.Model("personal_access_tokens").Where("token_hash = ?", tokenHash).One(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	return toAccessToken(record), nil
+}
+
+// RevokeAccessToken 撤销属于该用户且尚未撤销的令牌。
+func (r *AccessTokenRepository) RevokeAccessToken(ctx context.Context, userID, id int64) (bool, error) {
+	res, err := r.db.Exec(ctx, "UPDATE personal_access_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ? AND revoked_at IS NULL", id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// TouchAccessToken 记录最近一次使用的时间与来源 IP。
+func (r *AccessTokenRepository) TouchAccessToken(ctx context.Context, id int64, ip string, at time.Time) error {
+	_, err := r.db.Exec(ctx, "UPDATE personal_access_tokens SET last_used_at = ?, last_used_ip = ? WHERE id = ?", at, ip, id)
+	return err
+}
+
+func toAccessToken(record gdb.Record) *user.AccessToken {
+	token := &user.AccessToken{
+		ID:         record["id"].Int64(),
+		UserID:     record["user_id"].Int64(),
+		Name:       record["name"].String(),
+		TokenHash:  record["token_hash"].String(),
+		Prefix:     record["token_prefix"].String(),
+		Scopes:     strings.Fields(record["scopes"].String()),
+		LastUsedIP: record["last_used_ip"].String(),
+		CreatedAt:  record["created_at"].Time(),
+	}
+	if !record["expires_at"].IsEmpty() {
+		expiresAt := record["expires_at"].Time()
+		token.ExpiresAt = &expiresAt
+	}
+	if !record["last_used_at"].IsEmpty() {
+		lastUsed := record["last_used_at"].Time()
+		token.LastUsedAt = &lastUsed
+	}
+	if !record["revoked_at"].IsEmpty() {
+		revokedAt := record["revoked_at"].Time()
+		token.RevokedAt = &revokedAt
+	}
+	return token
+}
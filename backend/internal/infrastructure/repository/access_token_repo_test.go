@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestAccessTokenRepository_Lifecycle(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	userID, err := NewUserRepository(db).Create(ctx, &user.User{Username: "pat_repo", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	repo := NewAccessTokenRepository(db)
+
+	expires := time.Now().Add(time.Hour)
+	id, err := repo.CreateAccessToken(ctx, &user.AccessToken{
+		UserID:    userID,
+		Name:      "ci",
+		TokenHash: "hash-1",
+		Prefix:    "gsp_abcdef",
+		Scopes:    []string{user.ScopeProgressRead, user.ScopeQuizWrite},
+		ExpiresAt: &expires,
+	})
+	if err != nil {
+		t.Fatalf("创建令牌失败: %v", err)
+	}
+
+	found, err := repo.FindAccessTokenByHash(ctx, "hash-1")
+	if err != nil || found == nil || found.ID != id {
+		t.Fatalf("按哈希查询失败: %+v, %v", found, err)
+	}
+	if len(found.Scopes) != 2 || !found.HasScope(user.ScopeQuizWrite) || found.ExpiresAt == nil {
+		t.Fatalf("令牌字段不正确: %+v", found)
+	}
+
+	usedAt := time.Now()
+	if err := repo.TouchAccessToken(ctx, id, "10.0.0.1", usedAt); err != nil {
+		t.Fatalf("记录使用失败: %v", err)
+	}
+	list, err := repo.ListAccessTokens(ctx, userID)
+	if err != nil || len(list) != 1 || list[0].LastUsedIP != "10.0.0.1" || list[0].LastUsedAt == nil {
+		t.Fatalf("使用记录未更新: %+v, %v", list, err)
+	}
+
+	if ok, _ := repo.RevokeAccessToken(ctx, userID+1, id); ok {
+		t.Fatalf("不应撤销其他用户的令牌")
+	}
+	if ok, err := repo.RevokeAccessToken(ctx, userID, id); err != nil || !ok {
+		t.Fatalf("撤销令牌失败: %v", err)
+	}
+	if list, _ := repo.ListAccessTokens(ctx, userID); len(list) != 0 {
+		t.Fatalf("撤销后不应再列出")
+	}
+	revoked, _ := repo.FindAccessTokenByHash(ctx, "hash-1")
+	if revoked == nil || revoked.Active(time.Now()) {
+		t.Fatalf("撤销后的令牌不应可用")
+	}
+}
@@ -0,0 +1,465 @@
+package repository
+
+import (
+	"context"
+
+	"go-study2/internal/domain/classroom"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// ClassroomRepository 使用 GoFrame gdb 实现班级仓储。
+type ClassroomRepository struct {
+	db gdb.DB
+}
+
+// NewClassroomRepository 创建班级仓储。
+func NewClassroomRepository(db gdb.DB) *ClassroomRepository {
+	return &ClassroomRepository{db: db}
+}
+
+// classColumns 查询班级时附带学生人数。
+const classColumns = `c.id, c.name, c.description, c.join_code, c.created_by, c.created_at, c.leaderboard_enabled,
+(SELECT COUNT(*) FROM class_members s WHERE s.class_id = c.id AND s.role = 'student') AS student_count`
+
+// CreateClass 在同一事务中创建班级并登记创建者为教师。
+func (r *ClassroomRepository) CreateClass(ctx context.Context, class *classroom.Class) (int64, error) {
+	var id int64
+	err := r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		data := map[string]interface{}{
+			"name":                class.Name,
+			"description":         class.Description,
+			"join_code":           class.JoinCode,
+			"created_by":          class.CreatedBy,
+			"leaderboard_enabled": class.LeaderboardEnabled,
+		}
+		if !class.CreatedAt.IsZero() {
+			data["created_at"] = class.CreatedAt
+		}
+		res, err := tx.Insert("classes", data)
+		if err != nil {
+			return err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+		_, err = tx.Insert("class_members", map[string]interface{}{
+			"class_id": id,
+			"user_id":  class.CreatedBy,
+			"role":     classroom.RoleTeacher,
+		})
+		return err
+	})
+	return id, err
+}
+
+// FindClass 按 ID 查询班级，不存在时返回 nil。
+func (r *ClassroomRepository) FindClass(ctx context.Context, id int64) (*classroom.Class, error) {
+	record, err := r.db.GetOne(ctx, "SELECT "+classColumns+" FROM classes c WHERE c.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return toClass(record), nil
+}
+
+// FindClassByJoinCode 按加入码查询班级，不存在时返回 nil。
+func (r *ClassroomRepository) FindClassByJoinCode(ctx context.Context, code string) (*classroom.Class, error) {
+	record, err := r.db.GetOne(ctx, "SELECT "+classColumns+" FROM classes c WHERE c.join_code = ?", code)
+	if err != nil {
+		return nil, err
+	}
+	return toClass(record), nil
+}
+
+// ListClasses 按创建时间倒序列出全部班级。
+func (r *ClassroomRepository) ListClasses(ctx context.Context) ([]classroom.Class, error) {
+	records, err := r.db.GetAll(ctx, "SELECT "+classColumns+" FROM classes c ORDER BY c.id DESC")
+	if err != nil {
+		return nil, err
+	}
+	return toClasses(records), nil
+}
+
+// ListClassesForUser 列出用户所属的班级及其角色。
+func (r *ClassroomRepository) ListClassesForUser(ctx context.Context, userID int64) ([]classroom.Class, error) {
+	records, err := r.db.GetAll(ctx, "SELECT "+classColumns+`, m.role
+FROM classes c
+JOIN class_members m ON m.class_id = c.id
+WHERE m.user_id = ?
+ORDER BY c.id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toClasses(records), nil
+}
+
+// UpdateJoinCode 替换班级加入码。
+func (r *ClassroomRepository) UpdateJoinCode(ctx context.Context, classID int64, code string) error {
+	_, err := r.db.Exec(ctx, "UPDATE classes SET join_code = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", code, classID)
+	return err
+}
+
+// UpdateLeaderboardEnabled 修改班级排行榜开关。
+func (r *ClassroomRepository) UpdateLeaderboardEnabled(ctx context.Context, classID int64, enabled bool) error {
+	_, err := r.db.Exec(ctx, "UPDATE classes SET leaderboard_enabled = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", enabled, classID)
+	return err
+}
+
+// DeleteClass 删除班级及成员关系。
+func (r *ClassroomRepository) DeleteClass(ctx context.Context, id int64) error {
+	return r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if _, err := tx.Exec("DELETE FROM class_assignments WHERE class_id = ?", id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM class_members WHERE class_id = ?", id); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM classes WHERE id = ?", id)
+		return err
+	})
+}
+
+// AddMember 登记班级成员。
+func (r *ClassroomRepository) AddMember(ctx context.Context, member classroom.Member) error {
+	data := map[string]interface{}{
+		"class_id": member.ClassID,
+		"user_id":  member.UserID,
+		"role":     member.Role,
+	}
+	if !member.JoinedAt.IsZero() {
+		// 与 CURRENT_TIMESTAMP 同为 UTC 文本，看板按加入时间筛选学习记录时可直接比较
+		data["joined_at"] = member.JoinedAt.UTC().Format(assignmentTimeLayout)
+	}
+	_, err := r.db.Insert(ctx, "class_members", data)
+	return err
+}
+
+// RemoveMember 移除班级成员。
+func (r *ClassroomRepository) RemoveMember(ctx context.Context, classID, userID int64) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM class_members WHERE class_id = ? AND user_id = ?", classID, userID)
+	return err
+}
+
+// FindMember 查询用户在班级中的成员记录，不是成员时返回 nil。
+func (r *ClassroomRepository) FindMember(ctx context.Context, classID, userID int64) (*classroom.Member, error) {
+	record, err := r.db.GetOne(ctx, `
+SELECT m.class_id, m.user_id, u.username, m.role, m.joined_at
+FROM class_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.class_id = ? AND m.user_id = ?`, classID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	member := toMember(record)
+	return &member, nil
+}
+
+// ListMembers 列出班级成员，教师在前。
+func (r *ClassroomRepository) ListMembers(ctx context.Context, classID int64) ([]classroom.Member, error) {
+	records, err := r.db.GetAll(ctx, `
+SELECT m.class_id, m.user_id, u.username, m.role, m.joined_at
+FROM class_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.class_id = ?
+ORDER BY CASE m.role WHEN 'teacher' THEN 0 ELSE 1 END, u.username`, classID)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]classroom.Member, 0, len(records))
+	for _, record := range records {
+		members = append(members, toMember(record))
+	}
+	return members, nil
+}
+
+// FindAccount 按 ID 查询权限判断所需的用户信息。
+func (r *ClassroomRepository) FindAccount(ctx context.Context, id int64) (*classroom.Account, error) {
+	record, err := r.db.GetOne(ctx, "SELECT id, username, is_admin, is_teacher FROM users WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return toAccount(record), nil
+}
+
+// FindAccountByUsername 按用户名查询权限判断所需的用户信息。
+func (r *ClassroomRepository) FindAccountByUsername(ctx context.Context, username string) (*classroom.Account, error) {
+	record, err := r.db.GetOne(ctx, "SELECT id, username, is_admin, is_teacher FROM users WHERE username = ?", username)
+	if err != nil {
+		return nil, err
+	}
+	return toAccount(record), nil
+}
+
+// ListStudentProgress 通过成员表关联，只返回该班级学生加入班级后更新过的进度，加入前的学习记录不对教师可见。
+func (r *ClassroomRepository) ListStudentProgress(ctx context.Context, classID, studentID int64) ([]classroom.ChapterProgress, error) {
+	sql := `
+SELECT p.user_id, p.topic, p.chapter, p.status, p.last_visit
+FROM learning_progress p
+JOIN class_members m ON m.user_id = p.user_id AND m.class_id = ? AND m.role = 'student'
+    AND p.updated_at >= m.joined_at`
+	args := []interface{}{classID}
+	if studentID > 0 {
+		sql += " WHERE p.user_id = ?"
+		args = append(args, studentID)
+	}
+	records, err := r.db.GetAll(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]classroom.ChapterProgress, 0, len(records))
+	for _, record := range records {
+		rows = append(rows, classroom.ChapterProgress{
+			UserID:    record["user_id"].Int64(),
+			Topic:     record["topic"].String(),
+			Chapter:   record["chapter"].String(),
+			Status:    record["status"].String(),
+			LastVisit: record["last_visit"].Time(),
+		})
+	}
+	return rows, nil
+}
+
+// ListStudentQuizSummaries 按学生、主题与章节聚合班级学生加入班级后的测验记录。
+func (r *ClassroomRepository) ListStudentQuizSummaries(ctx context.Context, classID, studentID int64) ([]classroom.QuizSummary, error) {
+	sql := `
+SELECT q.user_id, q.topic, COALESCE(q.chapter, '') AS chapter,
+       COUNT(*) AS attempts,
+       AVG(CASE WHEN q.total > 0 THEN q.score * 100.0 / q.total ELSE 0 END) AS avg_percent,
+       MAX(CASE WHEN q.total > 0 THEN q.score * 100.0 / q.total ELSE 0 END) AS best_percent,
+       MAX(q.created_at) AS last_at
+FROM quiz_records q
+JOIN class_members m ON m.user_id = q.user_id AND m.class_id = ? AND m.role = 'student'
+    AND q.created_at >= m.joined_at`
+	args := []interface{}{classID}
+	if studentID > 0 {
+		sql += " WHERE q.user_id = ?"
+		args = append(args, studentID)
+	}
+	sql += " GROUP BY q.user_id, q.topic, COALESCE(q.chapter, '')"
+	records, err := r.db.GetAll(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]classroom.QuizSummary, 0, len(records))
+	for _, record := range records {
+		summaries = append(summaries, classroom.QuizSummary{
+			UserID:      record["user_id"].Int64(),
+			Topic:       record["topic"].String(),
+			Chapter:     record["chapter"].String(),
+			Attempts:    record["attempts"].Int(),
+			AvgPercent:  record["avg_percent"].Float64(),
+			BestPercent: record["best_percent"].Float64(),
+			LastAt:      record["last_at"].Time(),
+		})
+	}
+	return summaries, nil
+}
+
+// assignmentTimeLayout 与 SQLite CURRENT_TIMESTAMP 一致的 UTC 文本格式，保证可与测验时间直接比较。
+const assignmentTimeLayout = "2006-01-02 15:04:05"
+
+// CreateAssignment 保存班级作业。
+func (r *ClassroomRepository) CreateAssignment(ctx context.Context, assignment *classroom.Assignment) (int64, error) {
+	res, err := r.db.Insert(ctx, "class_assignments", map[string]interface{}{
+		"class_id":   assignment.ClassID,
+		"title":      assignment.Title,
+		"topic":      assignment.Topic,
+		"chapter":    assignment.Chapter,
+		"min_score":  assignment.MinScore,
+		"opens_at":   assignment.OpensAt.UTC().Format(assignmentTimeLayout),
+		"due_at":     assignment.DueAt.UTC().Format(assignmentTimeLayout),
+		"created_by": assignment.CreatedBy,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// FindAssignment 按 ID 查询作业，不存在时返回 nil。
+func (r *ClassroomRepository) FindAssignment(ctx context.Context, id int64) (*classroom.Assignment, error) {
+	record, err := r.db.GetOne(ctx, "SELECT * FROM class_assignments WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	assignment := toAssignment(record)
+	return &assignment, nil
+}
+
+// ListAssignments 按截止时间升序列出班级作业。
+func (r *ClassroomRepository) ListAssignments(ctx context.Context, classID int64) ([]classroom.Assignment, error) {
+	records, err := r.db.GetAll(ctx, "SELECT * FROM class_assignments WHERE class_id = ? ORDER BY due_at, id", classID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]classroom.Assignment, 0, len(records))
+	for _, record := range records {
+		items = append(items, toAssignment(record))
+	}
+	return items, nil
+}
+
+// ListAssignmentsForStudent 列出学生所在班级的全部作业。
+func (r *ClassroomRepository) ListAssignmentsForStudent(ctx context.Context, userID int64) ([]classroom.StudentAssignment, error) {
+	records, err := r.db.GetAll(ctx, `
+SELECT a.*, c.name AS class_name
+FROM class_assignments a
+JOIN classes c ON c.id = a.class_id
+JOIN class_members m ON m.class_id = a.class_id AND m.user_id = ? AND m.role = 'student'
+ORDER BY a.due_at, a.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]classroom.StudentAssignment, 0, len(records))
+	for _, record := range records {
+		items = append(items, classroom.StudentAssignment{
+			Assignment: toAssignment(record),
+			ClassName:  record["class_name"].String(),
+		})
+	}
+	return items, nil
+}
+
+// DeleteAssignment 删除作业。
+func (r *ClassroomRepository) DeleteAssignment(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM class_assignments WHERE id = ?", id)
+	return err
+}
+
+// ListAssignmentAttempts 统计作业开放后各学生的测验次数、最高得分率与首次达标时间，学生加入班级前的测验不计入。
+func (r *ClassroomRepository) ListAssignmentAttempts(ctx context.Context, assignment classroom.Assignment, studentID int64) ([]classroom.AssignmentAttempt, error) {
+	sql := `
+SELECT q.user_id,
+       COUNT(*) AS attempts,
+       MAX(CASE WHEN q.total > 0 THEN q.score * 100.0 / q.total ELSE 0 END) AS best_percent,
+       MIN(CASE WHEN q.total > 0 AND q.score * 100.0 / q.total >= ? THEN q.created_at END) AS completed_at
+FROM quiz_records q
+JOIN class_members m ON m.user_id = q.user_id AND m.class_id = ? AND m.role = 'student'
+    AND q.created_at >= m.joined_at
+WHERE q.topic = ? AND COALESCE(q.chapter, '') = ? AND q.created_at >= ?`
+	args := []interface{}{
+		assignment.MinScore, assignment.ClassID, assignment.Topic, assignment.Chapter,
+		assignment.OpensAt.UTC().Format(assignmentTimeLayout),
+	}
+	if studentID > 0 {
+		sql += " AND q.user_id = ?"
+		args = append(args, studentID)
+	}
+	sql += " GROUP BY q.user_id"
+	records, err := r.db.GetAll(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	attempts := make([]classroom.AssignmentAttempt, 0, len(records))
+	for _, record := range records {
+		attempt := toAssignmentAttempt(record)
+		attempt.AssignmentID = assignment.ID
+		attempts = append(attempts, attempt)
+	}
+	return attempts, nil
+}
+
+// ListStudentAssignmentAttempts 以一次查询按作业汇总学生在所在班级全部作业上的测验，口径与 ListAssignmentAttempts 一致。
+func (r *ClassroomRepository) ListStudentAssignmentAttempts(ctx context.Context, userID int64) ([]classroom.AssignmentAttempt, error) {
+	records, err := r.db.GetAll(ctx, `
+SELECT a.id AS assignment_id, q.user_id,
+       COUNT(*) AS attempts,
+       MAX(CASE WHEN q.total > 0 THEN q.score * 100.0 / q.total ELSE 0 END) AS best_percent,
+       MIN(CASE WHEN q.total > 0 AND q.score * 100.0 / q.total >= a.min_score THEN q.created_at END) AS completed_at
+FROM class_assignments a
+JOIN class_members m ON m.class_id = a.class_id AND m.user_id = ? AND m.role = 'student'
+JOIN quiz_records q ON q.user_id = m.user_id AND q.topic = a.topic AND COALESCE(q.chapter, '') = a.chapter
+    AND q.created_at >= a.opens_at AND q.created_at >= m.joined_at
+GROUP BY a.id, q.user_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	attempts := make([]classroom.AssignmentAttempt, 0, len(records))
+	for _, record := range records {
+		attempt := toAssignmentAttempt(record)
+		attempt.AssignmentID = record["assignment_id"].Int64()
+		attempts = append(attempts, attempt)
+	}
+	return attempts, nil
+}
+
+func toAssignmentAttempt(record gdb.Record) classroom.AssignmentAttempt {
+	attempt := classroom.AssignmentAttempt{
+		UserID:      record["user_id"].Int64(),
+		Attempts:    record["attempts"].Int(),
+		BestPercent: record["best_percent"].Float64(),
+	}
+	if !record["completed_at"].IsEmpty() {
+		completedAt := record["completed_at"].Time()
+		attempt.CompletedAt = &completedAt
+	}
+	return attempt
+}
+
+func toAssignment(record gdb.Record) classroom.Assignment {
+	return classroom.Assignment{
+		ID:        record["id"].Int64(),
+		ClassID:   record["class_id"].Int64(),
+		Title:     record["title"].String(),
+		Topic:     record["topic"].String(),
+		Chapter:   record["chapter"].String(),
+		MinScore:  record["min_score"].Int(),
+		OpensAt:   record["opens_at"].Time(),
+		DueAt:     record["due_at"].Time(),
+		CreatedBy: record["created_by"].Int64(),
+		CreatedAt: record["created_at"].Time(),
+	}
+}
+
+func toClass(record gdb.Record) *classroom.Class {
+	if record == nil || len(record.Map()) == 0 {
+		return nil
+	}
+	return &classroom.Class{
+		ID:                 record["id"].Int64(),
+		Name:               record["name"].String(),
+		Description:        record["description"].String(),
+		JoinCode:           record["join_code"].String(),
+		CreatedBy:          record["created_by"].Int64(),
+		CreatedAt:          record["created_at"].Time(),
+		StudentCount:       record["student_count"].Int(),
+		Role:               record["role"].String(),
+		LeaderboardEnabled: record["leaderboard_enabled"].Bool(),
+	}
+}
+
+func toClasses(records gdb.Result) []classroom.Class {
+	classes := make([]classroom.Class, 0, len(records))
+	for _, record := range records {
+		classes = append(classes, *toClass(record))
+	}
+	return classes
+}
+
+func toMember(record gdb.Record) classroom.Member {
+	return classroom.Member{
+		ClassID:  record["class_id"].Int64(),
+		UserID:   record["user_id"].Int64(),
+		Username: record["username"].String(),
+		Role:     record["role"].String(),
+		JoinedAt: record["joined_at"].Time(),
+	}
+}
+
+func toAccount(record gdb.Record) *classroom.Account {
+	if record == nil || len(record.Map()) == 0 {
+		return nil
+	}
+	return &classroom.Account{
+		ID:        record["id"].Int64(),
+		Username:  record["username"].String(),
+		IsAdmin:   record["is_admin"].Bool(),
+		IsTeacher: record["is_teacher"].Bool(),
+	}
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/classroom"
+	"go-study2/internal/domain/progress"
+	"go-study2/internal/domain/quiz"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestClassroomRepository_MembersAndStats(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	create := func(name string, teacher bool) int64 {
+		id, err := users.Create(ctx, &user.User{Username: name, PasswordHash: "hash", IsTeacher: teacher})
+		if err != nil {
+			t.Fatalf("创建用户失败: %v", err)
+		}
+		return id
+	}
+	teacherID := create("class_teacher", true)
+	studentID := create("class_student", false)
+	outsiderID := create("class_outsider", false)
+
+	repo := NewClassroomRepository(db)
+	classID, err := repo.CreateClass(ctx, &classroom.Class{Name: "Go 学习小组", JoinCode: "ABCD2345", CreatedBy: teacherID})
+	if err != nil {
+		t.Fatalf("创建班级失败: %v", err)
+	}
+	owner, err := repo.FindMember(ctx, classID, teacherID)
+	if err != nil || owner == nil || owner.Role != classroom.RoleTeacher || owner.Username != "class_teacher" {
+		t.Fatalf("创建者应登记为教师: %+v, %v", owner, err)
+	}
+	if err := repo.AddMember(ctx, classroom.Member{ClassID: classID, UserID: studentID, Role: classroom.RoleStudent}); err != nil {
+		t.Fatalf("添加成员失败: %v", err)
+	}
+
+	found, err := repo.FindClassByJoinCode(ctx, "ABCD2345")
+	if err != nil || found == nil || found.ID != classID || found.StudentCount != 1 {
+		t.Fatalf("按加入码查询班级失败: %+v, %v", found, err)
+	}
+	mine, err := repo.ListClassesForUser(ctx, studentID)
+	if err != nil || len(mine) != 1 || mine[0].Role != classroom.RoleStudent {
+		t.Fatalf("学生班级列表不正确: %+v, %v", mine, err)
+	}
+	if none, _ := repo.ListClassesForUser(ctx, outsiderID); len(none) != 0 {
+		t.Fatalf("非成员不应看到班级: %+v", none)
+	}
+	account, err := repo.FindAccountByUsername(ctx, "class_teacher")
+	if err != nil || account == nil || !account.IsTeacher || account.IsAdmin {
+		t.Fatalf("账户信息不正确: %+v, %v", account, err)
+	}
+
+	progressRepo := NewProgressRepository(db)
+	quizRepo := NewQuizRepository(db)
+	for _, uid := range []int64{studentID, outsiderID, teacherID} {
+		_ = progressRepo.Upsert(ctx, &progress.Progress{UserID: uid, Topic: "variables", Chapter: "storage", Status: progress.StatusInProgress})
+		_, _ = quizRepo.SaveRecord(ctx, &quiz.Record{UserID: uid, Topic: "variables", Chapter: "storage", Score: 3, Total: 4, Answers: "[]"})
+	}
+	_, _ = quizRepo.SaveRecord(ctx, &quiz.Record{UserID: studentID, Topic: "variables", Chapter: "storage", Score: 1, Total: 4, Answers: "[]"})
+
+	rows, err := repo.ListStudentProgress(ctx, classID, 0)
+	if err != nil || len(rows) != 1 || rows[0].UserID != studentID || rows[0].LastVisit.IsZero() {
+		t.Fatalf("只应返回班级学生的进度: %+v, %v", rows, err)
+	}
+	summaries, err := repo.ListStudentQuizSummaries(ctx, classID, studentID)
+	if err != nil || len(summaries) != 1 {
+		t.Fatalf("测验汇总不正确: %+v, %v", summaries, err)
+	}
+	if s := summaries[0]; s.Attempts != 2 || s.AvgPercent != 50 || s.BestPercent != 75 || s.LastAt.IsZero() {
+		t.Fatalf("测验聚合结果不正确: %+v", s)
+	}
+
+	if err := repo.DeleteClass(context.Background(), classID); err != nil {
+		t.Fatalf("删除班级失败: %v", err)
+	}
+	if gone, _ := repo.FindClass(ctx, classID); gone != nil {
+		t.Fatalf("班级应已删除")
+	}
+	if member, _ := repo.FindMember(ctx, classID, studentID); member != nil {
+		t.Fatalf("成员关系应随班级删除")
+	}
+}
+
+func TestClassroomRepository_HistoryBeforeJoin(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	teacherID, err := users.Create(ctx, &user.User{Username: "history_teacher", PasswordHash: "hash", IsTeacher: true})
+	if err != nil {
+		t.Fatalf("创建教师失败: %v", err)
+	}
+	studentID, err := users.Create(ctx, &user.User{Username: "history_student", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("创建学生失败: %v", err)
+	}
+
+	// 加入班级前已有的学习记录
+	progressRepo := NewProgressRepository(db)
+	quizRepo := NewQuizRepository(db)
+	_ = progressRepo.Upsert(ctx, &progress.Progress{UserID: studentID, Topic: "variables", Chapter: "storage", Status: progress.StatusDone})
+	_, _ = quizRepo.SaveRecord(ctx, &quiz.Record{UserID: studentID, Topic: "variables", Chapter: "storage", Score: 1, Total: 4, Answers: "[]"})
+	_, _ = quizRepo.SaveRecord(ctx, &quiz.Record{UserID: studentID, Topic: "variables", Chapter: "storage", Score: 4, Total: 4, Answers: "[]"})
+	if _, err := db.Exec(ctx, "UPDATE learning_progress SET updated_at = '2024-01-01 00:00:00' WHERE user_id = ?", studentID); err != nil {
+		t.Fatalf("回写进度时间失败: %v", err)
+	}
+	if _, err := db.Exec(ctx, "UPDATE quiz_records SET created_at = '2024-01-01 00:00:00' WHERE user_id = ?", studentID); err != nil {
+		t.Fatalf("回写测验时间失败: %v", err)
+	}
+
+	repo := NewClassroomRepository(db)
+	classID, err := repo.CreateClass(ctx, &classroom.Class{Name: "历史隔离", JoinCode: "HIST2345", CreatedBy: teacherID})
+	if err != nil {
+		t.Fatalf("创建班级失败: %v", err)
+	}
+	joinedAt := time.Date(2024, 6, 1, 8, 0, 0, 0, time.FixedZone("UTC+8", 8*3600))
+	if err := repo.AddMember(ctx, classroom.Member{ClassID: classID, UserID: studentID, Role: classroom.RoleStudent, JoinedAt: joinedAt}); err != nil {
+		t.Fatalf("添加成员失败: %v", err)
+	}
+	member, err := repo.FindMember(ctx, classID, studentID)
+	if err != nil || member == nil {
+		t.Fatalf("查询成员失败: %+v, %v", member, err)
+	}
+	if raw, _ := db.GetValue(ctx, "SELECT joined_at FROM class_members WHERE class_id = ? AND user_id = ?", classID, studentID); raw.String() != "2024-06-01 00:00:00" {
+		t.Fatalf("加入时间应以 UTC 文本保存: %s", raw.String())
+	}
+
+	rows, err := repo.ListStudentProgress(ctx, classID, studentID)
+	if err != nil || len(rows) != 0 {
+		t.Fatalf("加入前的进度不应出现在看板: %+v, %v", rows, err)
+	}
+	summaries, err := repo.ListStudentQuizSummaries(ctx, classID, 0)
+	if err != nil || len(summaries) != 0 {
+		t.Fatalf("加入前的测验不应出现在看板: %+v, %v", summaries, err)
+	}
+	// 开放时间早于加入时间的作业同样不统计加入前的测验
+	assignment := classroom.Assignment{
+		ClassID: classID, Topic: "variables", Chapter: "storage", MinScore: 50,
+		OpensAt: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), DueAt: time.Now().Add(24 * time.Hour),
+	}
+	attempts, err := repo.ListAssignmentAttempts(ctx, assignment, 0)
+	if err != nil || len(attempts) != 0 {
+		t.Fatalf("加入前的测验不应计入作业: %+v, %v", attempts, err)
+	}
+
+	// 加入后的学习记录正常统计
+	_ = progressRepo.Upsert(ctx, &progress.Progress{UserID: studentID, Topic: "variables", Chapter: "storage", Status: progress.StatusDone})
+	_, _ = quizRepo.SaveRecord(ctx, &quiz.Record{UserID: studentID, Topic: "variables", Chapter: "storage", Score: 3, Total: 4, Answers: "[]"})
+	rows, err = repo.ListStudentProgress(ctx, classID, studentID)
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("加入后的进度应可见: %+v, %v", rows, err)
+	}
+	summaries, err = repo.ListStudentQuizSummaries(ctx, classID, studentID)
+	if err != nil || len(summaries) != 1 || summaries[0].Attempts != 1 || summaries[0].BestPercent != 75 {
+		t.Fatalf("只应统计加入后的测验: %+v, %v", summaries, err)
+	}
+	attempts, err = repo.ListAssignmentAttempts(ctx, assignment, studentID)
+	if err != nil || len(attempts) != 1 || attempts[0].Attempts != 1 || attempts[0].BestPercent != 75 {
+		t.Fatalf("作业只应统计加入后的测验: %+v, %v", attempts, err)
+	}
+}
+
+func TestClassroomRepository_Assignments(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	teacherID, _ := users.Create(ctx, &user.User{Username: "hw_teacher", PasswordHash: "hash", IsTeacher: true})
+	studentID, _ := users.Create(ctx, &user.User{Username: "hw_student", PasswordHash: "hash"})
+	laggardID, _ := users.Create(ctx, &user.User{Username: "hw_laggard", PasswordHash: "hash"})
+	outsiderID, _ := users.Create(ctx, &user.User{Username: "hw_outsider", PasswordHash: "hash"})
+
+	repo := NewClassroomRepository(db)
+	classID, err := repo.CreateClass(ctx, &classroom.Class{Name: "作业班", JoinCode: "HWCLASS2", CreatedBy: teacherID})
+	if err != nil {
+		t.Fatalf("创建班级失败: %v", err)
+	}
+	for _, uid := range []int64{studentID, laggardID} {
+		_ = repo.AddMember(ctx, classroom.Member{ClassID: classID, UserID: uid, Role: classroom.RoleStudent})
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	assignmentID, err := repo.CreateAssignment(ctx, &classroom.Assignment{
+		ClassID: classID, Title: "接口实现", Topic: "types", Chapter: "interface_impl", MinScore: 80,
+		OpensAt: now.Add(-time.Hour), DueAt: now.Add(24 * time.Hour), CreatedBy: teacherID,
+	})
+	if err != nil {
+		t.Fatalf("创建作业失败: %v", err)
+	}
+	assignment, err := repo.FindAssignment(ctx, assignmentID)
+	if err != nil || assignment == nil || !assignment.DueAt.Equal(now.Add(24*time.Hour)) || assignment.MinScore != 80 {
+		t.Fatalf("作业读取不正确: %+v, %v", assignment, err)
+	}
+	mine, err := repo.ListAssignmentsForStudent(ctx, studentID)
+	if err != nil || len(mine) != 1 || mine[0].ClassName != "作业班" {
+		t.Fatalf("学生作业列表不正确: %+v, %v", mine, err)
+	}
+	if none, _ := repo.ListAssignmentsForStudent(ctx, teacherID); len(none) != 0 {
+		t.Fatalf("教师不应出现在学生作业列表: %+v", none)
+	}
+
+	quizRepo := NewQuizRepository(db)
+	save := func(uid int64, chapter string, score int) {
+		if _, err := quizRepo.SaveRecord(ctx, &quiz.Record{UserID: uid, Topic: "types", Chapter: chapter, Score: score, Total: 4, Answers: "[]"}); err != nil {
+			t.Fatalf("保存测验记录失败: %v", err)
+		}
+	}
+	save(studentID, "interface_impl", 3)
+	save(studentID, "interface_impl", 4)
+	save(studentID, "other", 4)
+	save(laggardID, "interface_impl", 2)
+	save(outsiderID, "interface_impl", 4)
+
+	attempts, err := repo.ListAssignmentAttempts(ctx, *assignment, 0)
+	if err != nil || len(attempts) != 2 {
+		t.Fatalf("只应汇总班级学生的目标章节测验: %+v, %v", attempts, err)
+	}
+	for _, a := range attempts {
+		switch a.UserID {
+		case studentID:
+			if a.Attempts != 2 || a.BestPercent != 100 || a.CompletedAt == nil {
+				t.Fatalf("达标学生汇总不正确: %+v", a)
+			}
+		case laggardID:
+			if a.Attempts != 1 || a.BestPercent != 50 || a.CompletedAt != nil {
+				t.Fatalf("未达标学生汇总不正确: %+v", a)
+			}
+		}
+	}
+	if single, _ := repo.ListAssignmentAttempts(ctx, *assignment, laggardID); len(single) != 1 || single[0].UserID != laggardID {
+		t.Fatalf("按学生过滤失败: %+v", single)
+	}
+
+	// 学生视角一次汇总全部作业，口径与教师报告一致
+	secondID, err := repo.CreateAssignment(ctx, &classroom.Assignment{
+		ClassID: classID, Title: "其他章节", Topic: "types", Chapter: "other", MinScore: 100,
+		OpensAt: now.Add(-time.Hour), DueAt: now.Add(48 * time.Hour), CreatedBy: teacherID,
+	})
+	if err != nil {
+		t.Fatalf("创建作业失败: %v", err)
+	}
+	_, _ = repo.CreateAssignment(ctx, &classroom.Assignment{
+		ClassID: classID, Title: "尚无测验", Topic: "variables", Chapter: "storage", MinScore: 60,
+		OpensAt: now.Add(-time.Hour), DueAt: now.Add(72 * time.Hour), CreatedBy: teacherID,
+	})
+	byAssignment := map[int64]classroom.AssignmentAttempt{}
+	studentAttempts, err := repo.ListStudentAssignmentAttempts(ctx, studentID)
+	if err != nil || len(studentAttempts) != 2 {
+		t.Fatalf("学生作业汇总数量不正确: %+v, %v", studentAttempts, err)
+	}
+	for _, a := range studentAttempts {
+		byAssignment[a.AssignmentID] = a
+	}
+	if a := byAssignment[assignmentID]; a.UserID != studentID || a.Attempts != 2 || a.BestPercent != 100 || a.CompletedAt == nil {
+		t.Fatalf("目标章节汇总不正确: %+v", a)
+	}
+	if a := byAssignment[secondID]; a.Attempts != 1 || a.BestPercent != 100 || a.CompletedAt == nil {
+		t.Fatalf("第二个作业汇总不正确: %+v", a)
+	}
+	if laggard, _ := repo.ListStudentAssignmentAttempts(ctx, laggardID); len(laggard) != 1 || laggard[0].CompletedAt != nil {
+		t.Fatalf("未达标学生汇总不正确: %+v", laggard)
+	}
+	if outsider, _ := repo.ListStudentAssignmentAttempts(ctx, outsiderID); len(outsider) != 0 {
+		t.Fatalf("非班级学生不应有作业汇总: %+v", outsider)
+	}
+
+	if err := repo.DeleteAssignment(ctx, assignmentID); err != nil {
+		t.Fatalf("删除作业失败: %v", err)
+	}
+	if list, _ := repo.ListAssignments(ctx, classID); len(list) != 2 || list[0].ID == assignmentID || list[1].ID == assignmentID {
+		t.Fatalf("作业应已删除: %+v", list)
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// CredentialCleanupRepository 使用 GoFrame gdb 删除过期的短期凭据，依赖各表的 expires_at 索引。
+type CredentialCleanupRepository struct {
+	db gdb.DB
+}
+
+// NewCredentialCleanupRepository 创建过期凭据清理仓储。
+func NewCredentialCleanupRepository(db gdb.DB) *CredentialCleanupRepository {
+	return &CredentialCleanupRepository{db: db}
+}
+
+// DeleteExpiredRefreshTokens 删除已过期的刷新令牌。
+func (r *CredentialCleanupRepository) DeleteExpiredRefreshTokens(ctx context.Context, now time.Time) (int64, error) {
+	return r.deleteExpired(ctx, "refresh_tokens", now)
+}
+
+// DeleteExpiredMFAChallenges 删除已过期的两步验证登录挑战。
+func (r *CredentialCleanupRepository) DeleteExpiredMFAChallenges(ctx context.Context, now time.Time) (int64, error) {
+	return r.deleteExpired(ctx, "mfa_challenges", now)
+}
+
+// DeleteExpiredLoginStates 删除已过期的外部登录状态。
+func (r *CredentialCleanupRepository) DeleteExpiredLoginStates(ctx context.Context, now time.Time) (int64, error) {
+	return r.deleteExpired(ctx, "external_login_states", now)
+}
+
+func (r *CredentialCleanupRepository) deleteExpired(ctx context.Context, table string, now time.Time) (int64, error) {
+	res, err := r.db.Model(table).WhereLTE("expires_at", now).Delete(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
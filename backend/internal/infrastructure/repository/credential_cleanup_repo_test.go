@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestCredentialCleanupRepository_DeletesOnlyExpired(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	userID, err := users.Create(ctx, &user.User{Username: "cleanup_repo", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	now := time.Now()
+	mfa := NewMFARepository(db)
+	identities := NewIdentityRepository(db)
+	for i, expiresAt := range []time.Time{now.Add(-time.Hour), now.Add(time.Hour)} {
+		suffix := []string{"old", "new"}[i]
+		if err := users.SaveRefreshToken(ctx, user.RefreshToken{UserID: userID, TokenHash: "rt_" + suffix, ExpiresAt: expiresAt}); err != nil {
+			t.Fatalf("写入刷新令牌失败: %v", err)
+		}
+		if err := mfa.SaveMFAChallenge(ctx, user.MFAChallengeRecord{UserID: userID, TokenHash: "mc_" + suffix, ExpiresAt: expiresAt}); err != nil {
+			t.Fatalf("写入登录挑战失败: %v", err)
+		}
+		if err := identities.SaveLoginState(ctx, user.ExternalLoginState{StateHash: "st_" + suffix, Provider: "oidc", ExpiresAt: expiresAt}); err != nil {
+			t.Fatalf("写入登录状态失败: %v", err)
+		}
+	}
+
+	repo := NewCredentialCleanupRepository(db)
+	for name, purge := range map[string]func() (int64, error){
+		"refresh_tokens": func() (int64, error) { return repo.DeleteExpiredRefreshTokens(ctx, now) },
+		"mfa_challenges": func() (int64, error) { return repo.DeleteExpiredMFAChallenges(ctx, now) },
+		"login_states":   func() (int64, error) { return repo.DeleteExpiredLoginStates(ctx, now) },
+	} {
+		if deleted, err := purge(); err != nil || deleted != 1 {
+			t.Fatalf("%s 应只删除 1 条过期记录: %d %v", name, deleted, err)
+		}
+	}
+	if token, err := users.FindRefreshToken(ctx, "rt_new"); err != nil || token == nil {
+		t.Fatalf("未过期的刷新令牌不应被删除: %v", err)
+	}
+	if challenge, err := mfa.FindMFAChallenge(ctx, "mc_new"); err != nil || challenge == nil {
+		t.Fatalf("未过期的登录挑战不应被删除: %v", err)
+	}
+}
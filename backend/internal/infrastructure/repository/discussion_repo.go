@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-study2/internal/domain/discussion"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// DiscussionRepository 使用 GoFrame gdb 实现讨论区仓储。
+type DiscussionRepository struct {
+	db gdb.DB
+}
+
+// NewDiscussionRepository 创建讨论区仓储。
+func NewDiscussionRepository(db gdb.DB) *DiscussionRepository {
+	return &DiscussionRepository{db: db}
+}
+
+// threadColumns 查询主题时附带作者、可见回复数与 viewer 的未读数，需要绑定一个 viewerID 参数。
+const threadColumns = `t.id, t.topic, t.chapter, t.question_id, t.title, t.author_id, u.username AS author,
+t.root_post_id, t.accepted_post_id, t.hidden, t.created_at, t.last_post_at,
+(SELECT COUNT(*) FROM discussion_posts p WHERE p.thread_id = t.id AND p.hidden = 0 AND p.id != t.root_post_id) AS reply_count,
+(SELECT COUNT(*) FROM discussion_posts p WHERE p.thread_id = t.id AND p.hidden = 0 AND p.author_id != ?
+    AND p.id > COALESCE((SELECT r.last_post_id FROM discussion_reads r WHERE r.thread_id = t.id AND r.user_id = ?), 0)) AS unread`
+
+// CreateThread 在同一事务中保存主题、首帖与提及。
+func (r *DiscussionRepository) CreateThread(ctx context.Context, thread *discussion.Thread, root *discussion.Post, mentions []int64) (int64, int64, error) {
+	var threadID, postID int64
+	err := r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		res, err := tx.Insert("discussion_threads", map[string]interface{}{
+			"topic":        thread.Topic,
+			"chapter":      thread.Chapter,
+			"question_id":  thread.QuestionID,
+			"title":        thread.Title,
+			"author_id":    thread.AuthorID,
+			"created_at":   thread.CreatedAt,
+			"last_post_at": thread.LastPostAt,
+		})
+		if err != nil {
+			return err
+		}
+		if threadID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+		if postID, err = insertPost(tx, threadID, root, mentions); err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE discussion_threads SET root_post_id = ? WHERE id = ?", postID, threadID)
+		return err
+	})
+	return threadID, postID, err
+}
+
+// CreatePost 保存回复与提及，并更新主题的最后回复时间。
+func (r *DiscussionRepository) CreatePost(ctx context.Context, post *discussion.Post, mentions []int64) (int64, error) {
+	var id int64
+	err := r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		var err error
+		if id, err = insertPost(tx, post.ThreadID, post, mentions); err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE discussion_threads SET last_post_at = ? WHERE id = ?", post.CreatedAt, post.ThreadID)
+		return err
+	})
+	return id, err
+}
+
+func insertPost(tx gdb.TX, threadID int64, post *discussion.Post, mentions []int64) (int64, error) {
+	res, err := tx.Insert("discussion_posts", map[string]interface{}{
+		"thread_id":  threadID,
+		"parent_id":  post.ParentID,
+		"author_id":  post.AuthorID,
+		"body":       post.Body,
+		"created_at": post.CreatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	for _, userID := range mentions {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO discussion_mentions (user_id, post_id, created_at) VALUES (?, ?, ?)",
+			userID, id, post.CreatedAt); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// FindThread 按主键查询主题，不存在时返回 nil；未读数恒为 0。
+func (r *DiscussionRepository) FindThread(ctx context.Context, id int64) (*discussion.Thread, error) {
+	record, err := r.db.GetOne(ctx,
+		"SELECT "+threadColumns+" FROM discussion_threads t JOIN users u ON u.id = t.author_id WHERE t.id = ?", 0, 0, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.IsEmpty() {
+		return nil, nil
+	}
+	thread := toThread(record)
+	thread.Unread = 0
+	return &thread, nil
+}
+
+// FindPost 按主键查询帖子，不存在时返回 nil。
+func (r *DiscussionRepository) FindPost(ctx context.Context, id int64) (*discussion.Post, error) {
+	record, err := r.db.GetOne(ctx,
+		"SELECT p.*, u.username AS author FROM discussion_posts p JOIN users u ON u.id = p.author_id WHERE p.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if record.IsEmpty() {
+		return nil, nil
+	}
+	post := toPost(record)
+	return &post, nil
+}
+
+// ListThreads 按最后回复时间倒序分页返回主题及总数。
+func (r *DiscussionRepository) ListThreads(ctx context.Context, viewerID int64, filter discussion.Filter) ([]discussion.Thread, int, error) {
+	var conds []string
+	var args []interface{}
+	if !filter.IncludeHidden {
+		conds = append(conds, "t.hidden = 0")
+	}
+	if filter.Topic != "" {
+		conds = append(conds, "t.topic = ?")
+		args = append(args, filter.Topic)
+	}
+	if filter.Chapter != "" {
+		conds = append(conds, "t.chapter = ?")
+		args = append(args, filter.Chapter)
+	}
+	if filter.QuestionID != "" {
+		conds = append(conds, "t.question_id = ?")
+		args = append(args, filter.QuestionID)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	total, err := r.db.GetValue(ctx, "SELECT COUNT(*) FROM discussion_threads t"+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := "SELECT " + threadColumns + " FROM discussion_threads t JOIN users u ON u.id = t.author_id" + where +
+		" ORDER BY t.last_post_at DESC, t.id DESC LIMIT ? OFFSET ?"
+	params := append([]interface{}{viewerID, viewerID}, args...)
+	params = append(params, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	records, err := r.db.GetAll(ctx, query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]discussion.Thread, 0, len(records))
+	for _, record := range records {
+		items = append(items, toThread(record))
+	}
+	return items, total.Int(), nil
+}
+
+// ListPosts 返回主题的全部帖子（含隐藏），按发布顺序排列。
+func (r *DiscussionRepository) ListPosts(ctx context.Context, threadID int64) ([]discussion.Post, error) {
+	records, err := r.db.GetAll(ctx,
+		"SELECT p.*, u.username AS author FROM discussion_posts p JOIN users u ON u.id = p.author_id WHERE p.thread_id = ? ORDER BY p.id", threadID)
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]discussion.Post, 0, len(records))
+	for _, record := range records {
+		posts = append(posts, toPost(record))
+	}
+	return posts, nil
+}
+
+// SetAccepted 设置主题的采纳回答。
+func (r *DiscussionRepository) SetAccepted(ctx context.Context, threadID, postID int64) error {
+	_, err := r.db.Exec(ctx, "UPDATE discussion_threads SET accepted_post_id = ? WHERE id = ?", postID, threadID)
+	return err
+}
+
+// SetPostHidden 隐藏或恢复帖子，首帖的状态同步到主题。
+func (r *DiscussionRepository) SetPostHidden(ctx context.Context, postID int64, hidden bool, operatorID int64) error {
+	return r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		var hiddenBy interface{}
+		if hidden {
+			hiddenBy = operatorID
+		}
+		if _, err := tx.Exec("UPDATE discussion_posts SET hidden = ?, hidden_by = ? WHERE id = ?", hidden, hiddenBy, postID); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE discussion_threads SET hidden = ? WHERE root_post_id = ?", hidden, postID)
+		return err
+	})
+}
+
+// MarkRead 记录已读位置，只会前进不会回退。
+func (r *DiscussionRepository) MarkRead(ctx context.Context, userID, threadID, lastPostID int64) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO discussion_reads (user_id, thread_id, last_post_id) VALUES (?, ?, ?)
+ON CONFLICT(user_id, thread_id) DO UPDATE SET last_post_id = MAX(last_post_id, excluded.last_post_id)`,
+		userID, threadID, lastPostID)
+	return err
+}
+
+// UnreadByChapter 统计各章节中他人发布、未隐藏且未读的帖子数，只返回未读数大于 0 的章节。
+func (r *DiscussionRepository) UnreadByChapter(ctx context.Context, userID int64, topic string) ([]discussion.ChapterUnread, error) {
+	query := `SELECT t.topic, t.chapter, COUNT(p.id) AS cnt
+FROM discussion_threads t
+JOIN discussion_posts p ON p.thread_id = t.id AND p.hidden = 0 AND p.author_id != ?
+LEFT JOIN discussion_reads r ON r.thread_id = t.id AND r.user_id = ?
+WHERE t.hidden = 0 AND p.id > COALESCE(r.last_post_id, 0)`
+	args := []interface{}{userID, userID}
+	if topic != "" {
+		query += " AND t.topic = ?"
+		args = append(args, topic)
+	}
+	query += " GROUP BY t.topic, t.chapter ORDER BY t.topic, t.chapter"
+	records, err := r.db.GetAll(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]discussion.ChapterUnread, 0, len(records))
+	for _, record := range records {
+		list = append(list, discussion.ChapterUnread{
+			Topic:   record["topic"].String(),
+			Chapter: record["chapter"].String(),
+			Count:   record["cnt"].Int(),
+		})
+	}
+	return list, nil
+}
+
+// FindUserIDs 按用户名（不区分大小写）查找启用的用户。
+func (r *DiscussionRepository) FindUserIDs(ctx context.Context, usernames []string) ([]int64, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(usernames)), ",")
+	args := make([]interface{}, 0, len(usernames))
+	for _, name := range usernames {
+		args = append(args, strings.ToLower(name))
+	}
+	records, err := r.db.GetAll(ctx,
+		"SELECT id FROM users WHERE LOWER(username) IN ("+placeholders+") AND status = 'active' ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record["id"].Int64())
+	}
+	return ids, nil
+}
+
+// FindActor 返回用户角色，用户不存在时返回 nil。
+func (r *DiscussionRepository) FindActor(ctx context.Context, userID int64) (*discussion.Actor, error) {
+	record, err := r.db.GetOne(ctx, "SELECT id, is_admin, is_teacher FROM users WHERE id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	if record.IsEmpty() {
+		return nil, nil
+	}
+	return &discussion.Actor{
+		ID:        record["id"].Int64(),
+		IsAdmin:   record["is_admin"].Bool(),
+		IsTeacher: record["is_teacher"].Bool(),
+	}, nil
+}
+
+// ListMentions 返回用户收到的提及，忽略已隐藏的帖子与主题。
+func (r *DiscussionRepository) ListMentions(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]discussion.Mention, error) {
+	query := `SELECT m.id, m.post_id, m.created_at, m.read_at, p.thread_id, t.topic, t.chapter, t.title, u.username AS author
+FROM discussion_mentions m
+JOIN discussion_posts p ON p.id = m.post_id
+JOIN discussion_threads t ON t.id = p.thread_id
+JOIN users u ON u.id = p.author_id
+WHERE m.user_id = ? AND p.hidden = 0 AND t.hidden = 0`
+	if unreadOnly {
+		query += " AND m.read_at IS NULL"
+	}
+	query += " ORDER BY m.id DESC LIMIT ?"
+	records, err := r.db.GetAll(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]discussion.Mention, 0, len(records))
+	for _, record := range records {
+		list = append(list, discussion.Mention{
+			ID:        record["id"].Int64(),
+			PostID:    record["post_id"].Int64(),
+			ThreadID:  record["thread_id"].Int64(),
+			Topic:     record["topic"].String(),
+			Chapter:   record["chapter"].String(),
+			Title:     record["title"].String(),
+			Author:    record["author"].String(),
+			Read:      !record["read_at"].IsNil() && record["read_at"].String() != "",
+			CreatedAt: record["created_at"].Time(),
+		})
+	}
+	return list, nil
+}
+
+// MarkMentionsRead 将用户的提及标记为已读，ids 为空表示全部。
+func (r *DiscussionRepository) MarkMentionsRead(ctx context.Context, userID int64, ids []int64) error {
+	query := "UPDATE discussion_mentions SET read_at = ? WHERE user_id = ? AND read_at IS NULL"
+	args := []interface{}{time.Now().UTC(), userID}
+	if len(ids) > 0 {
+		query += " AND id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	_, err := r.db.Exec(ctx, query, args...)
+	return err
+}
+
+func toThread(record gdb.Record) discussion.Thread {
+	return discussion.Thread{
+		ID:             record["id"].Int64(),
+		Topic:          record["topic"].String(),
+		Chapter:        record["chapter"].String(),
+		QuestionID:     record["question_id"].String(),
+		Title:          record["title"].String(),
+		AuthorID:       record["author_id"].Int64(),
+		Author:         record["author"].String(),
+		RootPostID:     record["root_post_id"].Int64(),
+		AcceptedPostID: record["accepted_post_id"].Int64(),
+		Hidden:         record["hidden"].Bool(),
+		ReplyCount:     record["reply_count"].Int(),
+		Unread:         record["unread"].Int(),
+		CreatedAt:      record["created_at"].Time(),
+		LastPostAt:     record["last_post_at"].Time(),
+	}
+}
+
+func toPost(record gdb.Record) discussion.Post {
+	return discussion.Post{
+		ID:        record["id"].Int64(),
+		ThreadID:  record["thread_id"].Int64(),
+		ParentID:  record["parent_id"].Int64(),
+		AuthorID:  record["author_id"].Int64(),
+		Author:    record["author"].String(),
+		Body:      record["body"].String(),
+		Hidden:    record["hidden"].Bool(),
+		CreatedAt: record["created_at"].Time(),
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/discussion"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestDiscussionRepository_ThreadsUnreadAndMentions(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	alice, _ := users.Create(ctx, &user.User{Username: "disc_alice", PasswordHash: "hash", Status: "active"})
+	bob, _ := users.Create(ctx, &user.User{Username: "Disc_Bob", PasswordHash: "hash", Status: "active"})
+
+	repo := NewDiscussionRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+	mapThread, mapRoot, err := repo.CreateThread(ctx,
+		&discussion.Thread{Topic: "types", Chapter: "map", Title: "遍历顺序", AuthorID: alice, CreatedAt: now, LastPostAt: now},
+		&discussion.Post{AuthorID: alice, Body: "为什么随机？@disc_bob", CreatedAt: now}, []int64{bob})
+	if err != nil {
+		t.Fatalf("创建主题失败: %v", err)
+	}
+	later := now.Add(time.Minute)
+	sliceThread, _, err := repo.CreateThread(ctx,
+		&discussion.Thread{Topic: "types", Chapter: "slice", QuestionID: "q-slice-1", Title: "扩容", AuthorID: bob, CreatedAt: later, LastPostAt: later},
+		&discussion.Post{AuthorID: bob, Body: "容量如何增长", CreatedAt: later}, nil)
+	if err != nil {
+		t.Fatalf("创建主题失败: %v", err)
+	}
+
+	ids, err := repo.FindUserIDs(ctx, []string{"disc_bob", "nobody"})
+	if err != nil || len(ids) != 1 || ids[0] != bob {
+		t.Fatalf("按用户名查找应不区分大小写: %v %v", ids, err)
+	}
+
+	reply, err := repo.CreatePost(ctx, &discussion.Post{ThreadID: mapThread, ParentID: mapRoot, AuthorID: bob, Body: "哈希种子", CreatedAt: later.Add(time.Minute)}, nil)
+	if err != nil {
+		t.Fatalf("回复失败: %v", err)
+	}
+
+	items, total, err := repo.ListThreads(ctx, alice, discussion.Filter{Topic: "types", Page: 1, PageSize: 10})
+	if err != nil || total != 2 || len(items) != 2 {
+		t.Fatalf("列出主题失败: %+v %d %v", items, total, err)
+	}
+	if items[0].ID != mapThread || items[0].ReplyCount != 1 || items[0].Unread != 1 || items[0].Author != "disc_alice" {
+		t.Fatalf("回复后主题应排在最前且 alice 有 1 条未读: %+v", items[0])
+	}
+	if items, _, _ := repo.ListThreads(ctx, alice, discussion.Filter{Topic: "types", QuestionID: "q-slice-1", Page: 1, PageSize: 10}); len(items) != 1 || items[0].ID != sliceThread {
+		t.Fatalf("按题目过滤失败: %+v", items)
+	}
+
+	unread, err := repo.UnreadByChapter(ctx, alice, "types")
+	if err != nil || len(unread) != 2 || unread[0].Chapter != "map" || unread[0].Count != 1 || unread[1].Count != 1 {
+		t.Fatalf("章节未读数不正确: %+v %v", unread, err)
+	}
+	if err := repo.MarkRead(ctx, alice, mapThread, reply); err != nil {
+		t.Fatalf("标记已读失败: %v", err)
+	}
+	if err := repo.MarkRead(ctx, alice, mapThread, mapRoot); err != nil {
+		t.Fatalf("标记已读失败: %v", err)
+	}
+	if unread, _ := repo.UnreadByChapter(ctx, alice, ""); len(unread) != 1 || unread[0].Chapter != "slice" {
+		t.Fatalf("已读位置不应回退: %+v", unread)
+	}
+
+	mentions, err := repo.ListMentions(ctx, bob, true, 10)
+	if err != nil || len(mentions) != 1 || mentions[0].ThreadID != mapThread || mentions[0].Author != "disc_alice" || mentions[0].Read {
+		t.Fatalf("提及不正确: %+v %v", mentions, err)
+	}
+	if err := repo.MarkMentionsRead(ctx, bob, nil); err != nil {
+		t.Fatalf("标记提及已读失败: %v", err)
+	}
+	if mentions, _ := repo.ListMentions(ctx, bob, true, 10); len(mentions) != 0 {
+		t.Fatalf("提及应已读: %+v", mentions)
+	}
+	if mentions, _ := repo.ListMentions(ctx, bob, false, 10); len(mentions) != 1 || !mentions[0].Read {
+		t.Fatalf("全部提及应包含已读项: %+v", mentions)
+	}
+
+	if err := repo.SetPostHidden(ctx, mapRoot, true, alice); err != nil {
+		t.Fatalf("隐藏首帖失败: %v", err)
+	}
+	thread, err := repo.FindThread(ctx, mapThread)
+	if err != nil || thread == nil || !thread.Hidden {
+		t.Fatalf("隐藏首帖应同步隐藏主题: %+v %v", thread, err)
+	}
+	if _, total, _ := repo.ListThreads(ctx, alice, discussion.Filter{Page: 1, PageSize: 10}); total != 1 {
+		t.Fatalf("默认不应列出隐藏主题, total=%d", total)
+	}
+	if _, total, _ := repo.ListThreads(ctx, alice, discussion.Filter{Page: 1, PageSize: 10, IncludeHidden: true}); total != 2 {
+		t.Fatalf("IncludeHidden 应列出隐藏主题, total=%d", total)
+	}
+	if mentions, _ := repo.ListMentions(ctx, bob, false, 10); len(mentions) != 0 {
+		t.Fatalf("隐藏帖子的提及不应返回: %+v", mentions)
+	}
+
+	if err := repo.SetAccepted(ctx, mapThread, reply); err != nil {
+		t.Fatalf("采纳失败: %v", err)
+	}
+	posts, err := repo.ListPosts(ctx, mapThread)
+	if err != nil || len(posts) != 2 || posts[1].ParentID != mapRoot || !posts[0].Hidden {
+		t.Fatalf("帖子列表不正确: %+v %v", posts, err)
+	}
+	if actor, _ := repo.FindActor(ctx, alice); actor == nil || actor.IsAdmin || actor.IsTeacher {
+		t.Fatalf("角色查询不正确: %+v", actor)
+	}
+	if missing, _ := repo.FindThread(ctx, 9999); missing != nil {
+		t.Fatalf("不存在的主题应返回 nil")
+	}
+}
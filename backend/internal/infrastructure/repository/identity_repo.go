@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// IdentityRepository 使用 GoFrame gdb 实现外部身份仓储。
+type IdentityRepository struct {
+	db gdb.DB
+}
+
+// NewIdentityRepository 创建外部身份仓储。
+func NewIdentityRepository(db gdb.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// FindIdentity 按提供方与 subject 查询绑定关系，不存在时返回 nil。
+func (r *IdentityRepository) FindIdentity(ctx context.Context, provider, subject string) (*user.ExternalIdentity, error) {
+	record, err := r.db.Model("user_identities").Where("provider = ? AND subject = ?", provider, subject).One(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	var entity user.ExternalIdentity
+	if err := record.Struct(&entity); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// CreateIdentity 新增外部身份绑定。
+func (r *IdentityRepository) CreateIdentity(ctx context.Context, identity user.ExternalIdentity) error {
+	_, err := r.db.Insert(ctx, "user_identities", map[string]interface{}{
+		"user_id":  identity.UserID,
+		"provider": identity.Provider,
+		"subject":  identity.Subject,
+		"email":    identity.Email,
+	})
+	return err
+}
+
+// TouchIdentity 更新最近登录时间与提供方返回的最新邮箱。
+func (r *IdentityRepository) TouchIdentity(ctx context.Context, id int64, email string) error {
+	_, err := r.db.Exec(ctx, "UPDATE user_identities SET email = ?, last_login_at = CURRENT_TIMESTAMP WHERE id = ?", email, id)
+	return err
+}
+
+// SaveLoginState 保存一次外部登录跳转的状态。
+func (r *IdentityRepository) SaveLoginState(ctx context.Context, state user.ExternalLoginState) error {
+	_, err := r.db.Insert(ctx, "external_login_states", map[string]interface{}{
+		"state_hash":    state.StateHash,
+		"provider":      state.Provider,
+		"nonce":         state.Nonce,
+		"code_verifier": state.CodeVerifier,
+		"expires_at":    state.ExpiresAt,
+	})
+	return err
+}
+
+// TakeLoginState 查询并删除登录状态；并发回调中只有删除成功的一方能取得状态。
+func (r *IdentityRepository) TakeLoginState(ctx context.Context, stateHash string) (*user.ExternalLoginState, error) {
+	record, err := r.db.Model("external_login_states").Where("state_hash = ?", stateHash).One(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	res, err := r.db.Exec(ctx, "DELETE FROM external_login_states WHERE id = ?", record["id"].Int64())
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return nil, err
+	}
+	var entity user.ExternalLoginState
+	if err := record.Struct(&entity); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestIdentityRepository_LinkAndLoginState(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	userID, err := NewUserRepository(db).Create(ctx, &user.User{Username: "oidc_repo", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	repo := NewIdentityRepository(db)
+
+	if err := repo.CreateIdentity(ctx, user.ExternalIdentity{UserID: userID, Provider: "corp", Subject: "sub-1", Email: "a@example.com"}); err != nil {
+		t.Fatalf("创建绑定失败: %v", err)
+	}
+	if err := repo.CreateIdentity(ctx, user.ExternalIdentity{UserID: userID, Provider: "corp", Subject: "sub-1"}); err == nil {
+		t.Fatalf("同一外部身份不应重复绑定")
+	}
+	identity, err := repo.FindIdentity(ctx, "corp", "sub-1")
+	if err != nil || identity == nil || identity.UserID != userID {
+		t.Fatalf("查询绑定失败: %+v, %v", identity, err)
+	}
+	if err := repo.TouchIdentity(ctx, identity.ID, "b@example.com"); err != nil {
+		t.Fatalf("更新绑定失败: %v", err)
+	}
+	identity, _ = repo.FindIdentity(ctx, "corp", "sub-1")
+	if identity.Email != "b@example.com" {
+		t.Fatalf("邮箱未更新: %s", identity.Email)
+	}
+	if other, _ := repo.FindIdentity(ctx, "other", "sub-1"); other != nil {
+		t.Fatalf("不同提供方的 subject 不应命中")
+	}
+
+	expires := time.Now().Add(time.Minute)
+	if err := repo.SaveLoginState(ctx, user.ExternalLoginState{StateHash: "s1", Provider: "corp", Nonce: "n", CodeVerifier: "v", ExpiresAt: expires}); err != nil {
+		t.Fatalf("保存登录状态失败: %v", err)
+	}
+	state, err := repo.TakeLoginState(ctx, "s1")
+	if err != nil || state == nil || state.CodeVerifier != "v" || state.ExpiresAt.Unix() != expires.Unix() {
+		t.Fatalf("读取登录状态失败: %+v, %v", state, err)
+	}
+	if again, _ := repo.TakeLoginState(ctx, "s1"); again != nil {
+		t.Fatalf("登录状态只能读取一次")
+	}
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// InviteRepository 使用 GoFrame gdb 实现邀请码仓储。
+type InviteRepository struct {
+	db gdb.DB
+}
+
+// NewInviteRepository 创建邀请码仓储。
+func NewInviteRepository(db gdb.DB) *InviteRepository {
+	return &InviteRepository{db: db}
+}
+
+// CreateInvite 保存邀请码哈希并返回自增 ID。
+func (r *InviteRepository) CreateInvite(ctx context.Context, invite *user.Invite) (int64, error) {
+	data := map[string]interface{}{
+		"code_hash":  invite.CodeHash,
+		"code_hint":  invite.CodeHint,
+		"role":       invite.Role,
+		"max_uses":   invite.MaxUses,
+		"note":       invite.Note,
+		"created_by": invite.CreatedBy,
+	}
+	if invite.ClassID > 0 {
+		data["class_id"] = invite.ClassID
+	}
+	if invite.ExpiresAt != nil {
+		data["expires_at"] = *invite.ExpiresAt
+	}
+	if !invite.CreatedAt.IsZero() {
+		data["created_at"] = invite.CreatedAt
+	}
+	res, err := r.db.Insert(ctx, "invites", data)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// FindInviteByHash 通过邀请码哈希查询，不存在时返回 nil。
+func (r *InviteRepository) FindInviteByHash(ctx context.Context, codeHash string) (*user.Invite, error) {
+	record, err := r.db.Model("invites").Where("code_hash = ?", codeHash).One(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toInvite(record), nil
+}
+
+// FindInviteByID 通过 ID 查询邀请码，不存在时返回 nil。
+func (r *InviteRepository) FindInviteByID(ctx context.Context, id int64) (*user.Invite, error) {
+	record, err := r.db.Model("invites").Where("id = ?", id).One(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toInvite(record), nil
+}
+
+// ListInvites 按创建时间倒序列出全部邀请码。
+func (r *InviteRepository) ListInvites(ctx context.Context) ([]user.Invite, error) {
+	records, err := r.db.Model("invites").OrderDesc("id").All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	invites := make([]user.Invite, 0, len(records))
+	for _, record := range records {
+		invites = append(invites, *toInvite(record))
+	}
+	return invites, nil
+}
+
+// RevokeInvite 标记邀请码已撤销，重复撤销保持首次时间。
+func (r *InviteRepository) RevokeInvite(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, "UPDATE invites SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL", id)
+	return err
+}
+
+// ConsumeInvite 在单条 UPDATE 中校验撤销、过期与名额并占用一次，避免并发超发。
+func (r *InviteRepository) ConsumeInvite(ctx context.Context, id int64, now time.Time) (bool, error) {
+	res, err := r.db.Exec(ctx, `
+UPDATE invites
+SET used_count = used_count + 1
+WHERE id = ?
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > ?)
+  AND used_count < max_uses`, id, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ReleaseInvite 注册失败时归还已占用的名额。
+func (r *InviteRepository) ReleaseInvite(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, "UPDATE invites SET used_count = used_count - 1 WHERE id = ? AND used_count > 0", id)
+	return err
+}
+
+// SaveRedemption 记录邀请码使用明细。
+func (r *InviteRepository) SaveRedemption(ctx context.Context, redemption user.InviteRedemption) error {
+	_, err := r.db.Insert(ctx, "invite_redemptions", map[string]interface{}{
+		"invite_id": redemption.InviteID,
+		"user_id":   redemption.UserID,
+		"username":  redemption.Username,
+	})
+	return err
+}
+
+// ListRedemptions 按时间顺序列出邀请码的使用记录。
+func (r *InviteRepository) ListRedemptions(ctx context.Context, inviteID int64) ([]user.InviteRedemption, error) {
+	records, err := r.db.Model("invite_redemptions").Where("invite_id = ?", inviteID).OrderAsc("id").All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	redemptions := make([]user.InviteRedemption, 0, len(records))
+	for _, record := range records {
+		redemptions = append(redemptions, user.InviteRedemption{
+			ID:        record["id"].Int64(),
+			InviteID:  record["invite_id"].Int64(),
+			UserID:    record["user_id"].Int64(),
+			Username:  record["username"].String(),
+			CreatedAt: record["created_at"].Time(),
+		})
+	}
+	return redemptions, nil
+}
+
+func toInvite(record gdb.Record) *user.Invite {
+	if record == nil || len(record.Map()) == 0 {
+		return nil
+	}
+	invite := &user.Invite{
+		ID:        record["id"].Int64(),
+		CodeHash:  record["code_hash"].String(),
+		CodeHint:  record["code_hint"].String(),
+		Role:      record["role"].String(),
+		ClassID:   record["class_id"].Int64(),
+		MaxUses:   record["max_uses"].Int(),
+		UsedCount: record["used_count"].Int(),
+		Note:      record["note"].String(),
+		CreatedBy: record["created_by"].Int64(),
+		CreatedAt: record["created_at"].Time(),
+	}
+	if !record["expires_at"].IsEmpty() {
+		expiresAt := record["expires_at"].Time()
+		invite.ExpiresAt = &expiresAt
+	}
+	if !record["revoked_at"].IsEmpty() {
+		revokedAt := record["revoked_at"].Time()
+		invite.RevokedAt = &revokedAt
+	}
+	return invite
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestInviteRepository_ConsumeRespectsLimits(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	adminID, err := NewUserRepository(db).Create(ctx, &user.User{Username: "invite_admin", PasswordHash: "hash", IsAdmin: true})
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	repo := NewInviteRepository(db)
+	now := time.Now()
+	future := now.Add(time.Hour)
+
+	id, err := repo.CreateInvite(ctx, &user.Invite{CodeHash: "h1", CodeHint: "ABCD", Role: user.RoleUser, MaxUses: 2, ExpiresAt: &future, CreatedBy: adminID})
+	if err != nil {
+		t.Fatalf("创建邀请码失败: %v", err)
+	}
+	found, err := repo.FindInviteByHash(ctx, "h1")
+	if err != nil || found == nil || found.ID != id || found.ExpiresAt == nil || found.ClassID != 0 {
+		t.Fatalf("按哈希查询邀请码失败: %+v, %v", found, err)
+	}
+	classInviteID, err := repo.CreateInvite(ctx, &user.Invite{CodeHash: "h2", CodeHint: "EFGH", Role: user.RoleUser, ClassID: 42, MaxUses: 1, CreatedBy: adminID})
+	if err != nil {
+		t.Fatalf("创建绑定班级的邀请码失败: %v", err)
+	}
+	if classInvite, _ := repo.FindInviteByID(ctx, classInviteID); classInvite == nil || classInvite.ClassID != 42 {
+		t.Fatalf("邀请码应保存绑定的班级: %+v", classInvite)
+	}
+
+	for i := 0; i < 2; i++ {
+		if ok, err := repo.ConsumeInvite(ctx, id, now); err != nil || !ok {
+			t.Fatalf("第 %d 次占用应成功: %v", i+1, err)
+		}
+	}
+	if ok, _ := repo.ConsumeInvite(ctx, id, now); ok {
+		t.Fatalf("名额用尽后不应继续占用")
+	}
+	if err := repo.ReleaseInvite(ctx, id); err != nil {
+		t.Fatalf("归还名额失败: %v", err)
+	}
+	if ok, _ := repo.ConsumeInvite(ctx, id+999, now); ok {
+		t.Fatalf("不存在的邀请码不应被占用")
+	}
+	if ok, _ := repo.ConsumeInvite(ctx, id, now.Add(2*time.Hour)); ok {
+		t.Fatalf("过期邀请码不应被占用")
+	}
+
+	if err := repo.RevokeInvite(ctx, id); err != nil {
+		t.Fatalf("撤销邀请码失败: %v", err)
+	}
+	if ok, _ := repo.ConsumeInvite(ctx, id, now); ok {
+		t.Fatalf("已撤销的邀请码不应被占用")
+	}
+	revoked, _ := repo.FindInviteByID(ctx, id)
+	if revoked == nil || revoked.RevokedAt == nil || revoked.UsedCount != 1 {
+		t.Fatalf("撤销状态或使用次数不正确: %+v", revoked)
+	}
+}
+
+func TestInviteRepository_Redemptions(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	adminID, _ := users.Create(ctx, &user.User{Username: "invite_owner", PasswordHash: "hash", IsAdmin: true})
+	memberID, _ := users.Create(ctx, &user.User{Username: "invite_member", PasswordHash: "hash"})
+	repo := NewInviteRepository(db)
+
+	id, err := repo.CreateInvite(ctx, &user.Invite{CodeHash: "h2", Role: user.RoleUser, MaxUses: 1, CreatedBy: adminID})
+	if err != nil {
+		t.Fatalf("创建邀请码失败: %v", err)
+	}
+	if err := repo.SaveRedemption(ctx, user.InviteRedemption{InviteID: id, UserID: memberID, Username: "invite_member"}); err != nil {
+		t.Fatalf("保存使用记录失败: %v", err)
+	}
+	redemptions, err := repo.ListRedemptions(ctx, id)
+	if err != nil || len(redemptions) != 1 || redemptions[0].UserID != memberID {
+		t.Fatalf("使用记录不正确: %+v, %v", redemptions, err)
+	}
+	invites, err := repo.ListInvites(ctx)
+	if err != nil || len(invites) != 1 || invites[0].ExpiresAt != nil {
+		t.Fatalf("邀请码列表不正确: %+v, %v", invites, err)
+	}
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-study2/internal/domain/jobs"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// JobRepository 使用 GoFrame gdb 实现后台任务调度状态与执行历史仓储。
+type JobRepository struct {
+	db gdb.DB
+}
+
+// NewJobRepository 创建后台任务仓储。
+func NewJobRepository(db gdb.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+const jobRunColumns = "id, job_name, trigger_type, status, instance, started_at, finished_at, duration_ms, result, error"
+
+// EnsureJob 插入任务，已存在且计划表达式变化时更新表达式与下次执行时间。
+func (r *JobRepository) EnsureJob(ctx context.Context, name, schedule string, next time.Time) error {
+	_, err := r.db.Exec(ctx, `
+INSERT INTO scheduled_jobs (name, schedule, next_run_at, updated_at) VALUES (?, ?, ?, ?)
+ON CONFLICT(name) DO UPDATE SET schedule = excluded.schedule, next_run_at = excluded.next_run_at, updated_at = excluded.updated_at
+WHERE scheduled_jobs.schedule <> excluded.schedule`, name, schedule, next, time.Now().UTC())
+	return err
+}
+
+// FindState 查询任务调度状态，不存在时返回 nil。
+func (r *JobRepository) FindState(ctx context.Context, name string) (*jobs.State, error) {
+	record, err := r.db.GetOne(ctx, `SELECT name, schedule, paused, next_run_at, lease_owner, lease_until, last_error, last_error_at
+FROM scheduled_jobs WHERE name = ?`, name)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	state := &jobs.State{
+		Name:       record["name"].String(),
+		Schedule:   record["schedule"].String(),
+		Paused:     record["paused"].Bool(),
+		NextRunAt:  record["next_run_at"].Time(),
+		LeaseOwner: record["lease_owner"].String(),
+		LastError:  record["last_error"].String(),
+	}
+	if !record["lease_until"].IsEmpty() {
+		until := record["lease_until"].Time()
+		state.LeaseUntil = &until
+	}
+	if !record["last_error_at"].IsEmpty() {
+		at := record["last_error_at"].Time()
+		state.LastErrorAt = &at
+	}
+	return state, nil
+}
+
+// SetPaused 更新暂停状态，next 非 nil 时同时更新下次执行时间。
+func (r *JobRepository) SetPaused(ctx context.Context, name string, paused bool, next *time.Time) error {
+	if next != nil {
+		_, err := r.db.Exec(ctx, "UPDATE scheduled_jobs SET paused = ?, next_run_at = ?, updated_at = ? WHERE name = ?",
+			paused, *next, time.Now().UTC(), name)
+		return err
+	}
+	_, err := r.db.Exec(ctx, "UPDATE scheduled_jobs SET paused = ?, updated_at = ? WHERE name = ?", paused, time.Now().UTC(), name)
+	return err
+}
+
+// Claim 以条件更新抢占租约，只有一个实例能在租约空闲时更新成功。
+func (r *JobRepository) Claim(ctx context.Context, name, owner string, now, until time.Time, dueOnly bool) (bool, error) {
+	query := "UPDATE scheduled_jobs SET lease_owner = ?, lease_until = ? WHERE name = ? AND (lease_until IS NULL OR lease_until <= ?)"
+	args := []interface{}{owner, until, name, now}
+	if dueOnly {
+		query += " AND paused = 0 AND next_run_at <= ?"
+		args = append(args, now)
+	}
+	res, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// Release 释放本实例持有的租约，并按需更新下次执行时间与最近错误。
+func (r *JobRepository) Release(ctx context.Context, name, owner string, next *time.Time, failure string, at time.Time) error {
+	query := "UPDATE scheduled_jobs SET lease_owner = '', lease_until = NULL, updated_at = ?"
+	args := []interface{}{at}
+	if next != nil {
+		query += ", next_run_at = ?"
+		args = append(args, *next)
+	}
+	if failure != "" {
+		query += ", last_error = ?, last_error_at = ?"
+		args = append(args, failure, at)
+	}
+	query += " WHERE name = ? AND lease_owner = ?"
+	args = append(args, name, owner)
+	_, err := r.db.Exec(ctx, query, args...)
+	return err
+}
+
+// CreateRun 写入执行记录。
+func (r *JobRepository) CreateRun(ctx context.Context, run *jobs.Run) (int64, error) {
+	res, err := r.db.Insert(ctx, "job_runs", map[string]interface{}{
+		"job_name":     run.JobName,
+		"trigger_type": run.Trigger,
+		"status":       run.Status,
+		"instance":     run.Instance,
+		"started_at":   run.StartedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// FinishRun 写入执行结果。
+func (r *JobRepository) FinishRun(ctx context.Context, run *jobs.Run) error {
+	var finishedAt interface{}
+	if run.FinishedAt != nil {
+		finishedAt = *run.FinishedAt
+	}
+	_, err := r.db.Exec(ctx, "UPDATE job_runs SET status = ?, finished_at = ?, duration_ms = ?, result = ?, error = ? WHERE id = ?",
+		run.Status, finishedAt, run.DurationMs, run.Result, run.Error, run.ID)
+	return err
+}
+
+// ListRuns 按 ID 倒序返回执行记录。
+func (r *JobRepository) ListRuns(ctx context.Context, name string, limit int) ([]jobs.Run, error) {
+	records, err := r.db.GetAll(ctx, "SELECT "+jobRunColumns+" FROM job_runs WHERE job_name = ? ORDER BY id DESC LIMIT ?", name, limit)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]jobs.Run, 0, len(records))
+	for _, record := range records {
+		run := jobs.Run{
+			ID:         record["id"].Int64(),
+			JobName:    record["job_name"].String(),
+			Trigger:    record["trigger_type"].String(),
+			Status:     record["status"].String(),
+			Instance:   record["instance"].String(),
+			StartedAt:  record["started_at"].Time(),
+			DurationMs: record["duration_ms"].Int64(),
+			Result:     record["result"].String(),
+			Error:      record["error"].String(),
+		}
+		if !record["finished_at"].IsEmpty() {
+			finishedAt := record["finished_at"].Time()
+			run.FinishedAt = &finishedAt
+		}
+		list = append(list, run)
+	}
+	return list, nil
+}
+
+// PruneRuns 删除超出保留条数的旧执行记录。
+func (r *JobRepository) PruneRuns(ctx context.Context, name string, keep int) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM job_runs WHERE job_name = ? AND id NOT IN (
+SELECT id FROM job_runs WHERE job_name = ? ORDER BY id DESC LIMIT ?)`, name, name, keep)
+	return err
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/jobs"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestJobRepository_LeaseAndRuns(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	repo := NewJobRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+	next := now.Add(time.Minute)
+
+	if err := repo.EnsureJob(ctx, "token_cleanup", "17 * * * *", next); err != nil {
+		t.Fatalf("创建任务失败: %v", err)
+	}
+	// 表达式未变化时保留已有的下次执行时间
+	if err := repo.EnsureJob(ctx, "token_cleanup", "17 * * * *", next.Add(time.Hour)); err != nil {
+		t.Fatalf("重复同步任务失败: %v", err)
+	}
+	state, err := repo.FindState(ctx, "token_cleanup")
+	if err != nil || state == nil || !state.NextRunAt.Equal(next) || state.Paused {
+		t.Fatalf("任务状态不正确: %+v %v", state, err)
+	}
+	if missing, _ := repo.FindState(ctx, "missing"); missing != nil {
+		t.Fatalf("不存在的任务应返回 nil")
+	}
+
+	if ok, _ := repo.Claim(ctx, "token_cleanup", "a", now, now.Add(time.Minute), true); ok {
+		t.Fatalf("未到期的任务不应被领取")
+	}
+	if ok, _ := repo.Claim(ctx, "token_cleanup", "a", next, next.Add(time.Minute), true); !ok {
+		t.Fatalf("到期任务应被领取")
+	}
+	if ok, _ := repo.Claim(ctx, "token_cleanup", "b", next, next.Add(time.Minute), false); ok {
+		t.Fatalf("租约未过期时其他实例不应领取")
+	}
+	// 非持有者释放无效
+	_ = repo.Release(ctx, "token_cleanup", "b", nil, "", next)
+	if state, _ = repo.FindState(ctx, "token_cleanup"); state.LeaseOwner != "a" || state.LeaseUntil == nil {
+		t.Fatalf("租约不应被其他实例释放: %+v", state)
+	}
+	later := next.Add(time.Hour)
+	if err := repo.Release(ctx, "token_cleanup", "a", &later, "数据库已锁定", next); err != nil {
+		t.Fatalf("释放租约失败: %v", err)
+	}
+	state, _ = repo.FindState(ctx, "token_cleanup")
+	if state.LeaseUntil != nil || !state.NextRunAt.Equal(later) || state.LastError != "数据库已锁定" || state.LastErrorAt == nil {
+		t.Fatalf("释放后状态不正确: %+v", state)
+	}
+
+	if err := repo.SetPaused(ctx, "token_cleanup", true, nil); err != nil {
+		t.Fatalf("暂停任务失败: %v", err)
+	}
+	if ok, _ := repo.Claim(ctx, "token_cleanup", "a", later, later.Add(time.Minute), true); ok {
+		t.Fatalf("暂停的任务不应按计划领取")
+	}
+	if ok, _ := repo.Claim(ctx, "token_cleanup", "a", later, later.Add(time.Minute), false); !ok {
+		t.Fatalf("暂停的任务可被手动领取")
+	}
+
+	for i := 0; i < 4; i++ {
+		run := &jobs.Run{JobName: "token_cleanup", Trigger: jobs.TriggerManual, Status: jobs.RunRunning, Instance: "a", StartedAt: now}
+		id, err := repo.CreateRun(ctx, run)
+		if err != nil {
+			t.Fatalf("写入执行记录失败: %v", err)
+		}
+		run.ID = id
+		finished := now.Add(time.Second)
+		run.Status, run.FinishedAt, run.DurationMs, run.Result = jobs.RunSucceeded, &finished, 1000, "ok"
+		if err := repo.FinishRun(ctx, run); err != nil {
+			t.Fatalf("更新执行记录失败: %v", err)
+		}
+	}
+	if err := repo.PruneRuns(ctx, "token_cleanup", 2); err != nil {
+		t.Fatalf("清理执行记录失败: %v", err)
+	}
+	runs, err := repo.ListRuns(ctx, "token_cleanup", 10)
+	if err != nil || len(runs) != 2 || runs[0].ID < runs[1].ID {
+		t.Fatalf("应按倒序保留最近 2 条记录: %+v %v", runs, err)
+	}
+	if runs[0].Status != jobs.RunSucceeded || runs[0].Trigger != jobs.TriggerManual || runs[0].FinishedAt == nil || runs[0].DurationMs != 1000 {
+		t.Fatalf("执行记录字段不正确: %+v", runs[0])
+	}
+}
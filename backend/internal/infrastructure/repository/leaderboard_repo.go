@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go-study2/internal/domain/leaderboard"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// LeaderboardRepository 基于测验汇总表计算排行榜。
+type LeaderboardRepository struct {
+	db gdb.DB
+}
+
+// NewLeaderboardRepository 创建排行榜仓储。
+func NewLeaderboardRepository(db gdb.DB) *LeaderboardRepository {
+	return &LeaderboardRepository{db: db}
+}
+
+const weekDayLayout = "2006-01-02"
+
+// scoresSQL 按查询生成 (user_id, value) 子查询。
+func scoresSQL(q leaderboard.Query) (string, []interface{}) {
+	weekStart := q.WeekStart.Format(weekDayLayout)
+	switch {
+	case q.Metric == leaderboard.MetricPoints && q.Window == leaderboard.WindowWeek:
+		return "SELECT user_id, SUM(ROUND(best_percent)) AS value FROM quiz_weekly_best WHERE week_start = ? GROUP BY user_id",
+			[]interface{}{weekStart}
+	case q.Metric == leaderboard.MetricPoints:
+		return "SELECT user_id, SUM(ROUND(best_percent)) AS value FROM quiz_chapter_best GROUP BY user_id", nil
+	case q.Metric == leaderboard.MetricMastered && q.Window == leaderboard.WindowWeek:
+		weekEnd := q.WeekStart.AddDate(0, 0, 7).Format(weekDayLayout)
+		return "SELECT user_id, COUNT(*) AS value FROM quiz_chapter_best WHERE mastered_at >= ? AND mastered_at < ? GROUP BY user_id",
+			[]interface{}{weekStart, weekEnd}
+	case q.Metric == leaderboard.MetricMastered:
+		return fmt.Sprintf("SELECT user_id, COUNT(*) AS value FROM quiz_chapter_best WHERE best_percent >= %d GROUP BY user_id", leaderboard.MasteryPercent), nil
+	case q.Window == leaderboard.WindowWeek:
+		return "SELECT user_id, best_percent AS value FROM quiz_weekly_best WHERE week_start = ? AND topic = ? AND chapter = ?",
+			[]interface{}{weekStart, q.Topic, q.Chapter}
+	default:
+		return "SELECT user_id, best_percent AS value FROM quiz_chapter_best WHERE topic = ? AND chapter = ?",
+			[]interface{}{q.Topic, q.Chapter}
+	}
+}
+
+// rankedFrom 拼接成绩子查询与用户、隐私设置及班级成员过滤。
+func rankedFrom(q leaderboard.Query) (string, []interface{}) {
+	scores, args := scoresSQL(q)
+	from := "FROM (" + scores + `) s
+JOIN users u ON u.id = s.user_id AND u.status = 'active'
+LEFT JOIN leaderboard_preferences p ON p.user_id = s.user_id`
+	if q.Scope == leaderboard.ScopeClass {
+		from += "\nJOIN class_members m ON m.user_id = s.user_id AND m.class_id = ? AND m.role = 'student'"
+		args = append(args, q.ClassID)
+	}
+	return from + "\nWHERE COALESCE(p.opt_out, 0) = 0 AND s.value > 0", args
+}
+
+// Top 按成绩降序返回前 limit 名，同分按用户 ID 排序。
+func (r *LeaderboardRepository) Top(ctx context.Context, q leaderboard.Query, limit int) ([]leaderboard.Row, error) {
+	from, args := rankedFrom(q)
+	records, err := r.db.GetAll(ctx,
+		"SELECT s.user_id, s.value, u.username, COALESCE(p.nickname, '') AS nickname "+from+" ORDER BY s.value DESC, s.user_id LIMIT ?",
+		append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]leaderboard.Row, 0, len(records))
+	for _, record := range records {
+		rows = append(rows, leaderboard.Row{
+			UserID:   record["user_id"].Int64(),
+			Username: record["username"].String(),
+			Nickname: record["nickname"].String(),
+			Value:    record["value"].Float64(),
+		})
+	}
+	return rows, nil
+}
+
+// Standing 返回用户的成绩与排名，班级范围内非学生成员视为无成绩。
+func (r *LeaderboardRepository) Standing(ctx context.Context, q leaderboard.Query, userID int64) (*leaderboard.Standing, error) {
+	scores, args := scoresSQL(q)
+	query := "SELECT s.value FROM (" + scores + ") s"
+	if q.Scope == leaderboard.ScopeClass {
+		query += " JOIN class_members m ON m.user_id = s.user_id AND m.class_id = ? AND m.role = 'student'"
+		args = append(args, q.ClassID)
+	}
+	record, err := r.db.GetOne(ctx, query+" WHERE s.user_id = ?", append(args, userID)...)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	value := record["value"].Float64()
+
+	from, rankArgs := rankedFrom(q)
+	above, err := r.db.GetValue(ctx, "SELECT COUNT(*) "+from+" AND s.value > ?", append(rankArgs, value)...)
+	if err != nil {
+		return nil, err
+	}
+	return &leaderboard.Standing{Value: value, Rank: above.Int() + 1}, nil
+}
+
+// FindPreferences 返回用户的排行榜设置，未设置时返回 nil。
+func (r *LeaderboardRepository) FindPreferences(ctx context.Context, userID int64) (*leaderboard.Preferences, error) {
+	record, err := r.db.GetOne(ctx, "SELECT opt_out, nickname FROM leaderboard_preferences WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	return &leaderboard.Preferences{
+		OptOut:   record["opt_out"].Bool(),
+		Nickname: record["nickname"].String(),
+	}, nil
+}
+
+// SavePreferences 写入或覆盖用户的排行榜设置。
+func (r *LeaderboardRepository) SavePreferences(ctx context.Context, userID int64, prefs leaderboard.Preferences) error {
+	_, err := r.db.Exec(ctx, `
+INSERT INTO leaderboard_preferences (user_id, opt_out, nickname, updated_at) VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (user_id) DO UPDATE SET opt_out = excluded.opt_out, nickname = excluded.nickname, updated_at = excluded.updated_at`,
+		userID, prefs.OptOut, prefs.Nickname)
+	return err
+}
+
+// NicknameTaken 昵称不区分大小写，不能与他人的昵称或用户名相同。
+func (r *LeaderboardRepository) NicknameTaken(ctx context.Context, nickname string, userID int64) (bool, error) {
+	count, err := r.db.GetValue(ctx, `
+SELECT (SELECT COUNT(*) FROM leaderboard_preferences WHERE nickname = ? COLLATE NOCASE AND user_id != ?)
+     + (SELECT COUNT(*) FROM users WHERE username = ? COLLATE NOCASE AND id != ?)`,
+		nickname, userID, nickname, userID)
+	if err != nil {
+		return false, err
+	}
+	return count.Int() > 0, nil
+}
+
+// ClassState 返回班级排行榜开关与用户是否为成员。
+func (r *LeaderboardRepository) ClassState(ctx context.Context, classID, userID int64) (*leaderboard.ClassState, error) {
+	record, err := r.db.GetOne(ctx, `
+SELECT c.leaderboard_enabled,
+       (SELECT COUNT(*) FROM class_members m WHERE m.class_id = c.id AND m.user_id = ?) AS member
+FROM classes c WHERE c.id = ?`, userID, classID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	return &leaderboard.ClassState{
+		Enabled: record["leaderboard_enabled"].Bool(),
+		Member:  record["member"].Int() > 0,
+	}, nil
+}
+
+// IsAdmin 判断用户是否为管理员。
+func (r *LeaderboardRepository) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	value, err := r.db.GetValue(ctx, "SELECT is_admin FROM users WHERE id = ?", userID)
+	if err != nil {
+		return false, err
+	}
+	return value.Bool(), nil
+}
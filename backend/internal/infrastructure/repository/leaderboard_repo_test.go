@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/classroom"
+	"go-study2/internal/domain/leaderboard"
+	"go-study2/internal/domain/quiz"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestLeaderboardRepository_AggregatesAndRanking(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	create := func(name string) int64 {
+		id, err := users.Create(ctx, &user.User{Username: name, PasswordHash: "hash"})
+		if err != nil {
+			t.Fatalf("创建用户失败: %v", err)
+		}
+		return id
+	}
+	alice, bob, carol := create("lb_alice"), create("lb_bob"), create("lb_carol")
+
+	quizRepo := NewQuizRepository(db)
+	save := func(uid int64, chapter string, score int) {
+		if _, err := quizRepo.SaveRecord(ctx, &quiz.Record{UserID: uid, Topic: "types", Chapter: chapter, Score: score, Total: 4, Answers: "[]"}); err != nil {
+			t.Fatalf("保存测验记录失败: %v", err)
+		}
+	}
+	save(alice, "map", 2)
+	save(alice, "map", 4)
+	save(alice, "map", 1)
+	save(alice, "slice", 3)
+	save(bob, "map", 4)
+	save(bob, "slice", 3)
+	save(carol, "map", 1)
+
+	best, err := db.GetOne(ctx, "SELECT best_percent, attempts, mastered_at FROM quiz_chapter_best WHERE user_id = ? AND chapter = 'map'", alice)
+	if err != nil || best["best_percent"].Float64() != 100 || best["attempts"].Int() != 3 || best["mastered_at"].IsEmpty() {
+		t.Fatalf("章节最佳汇总不正确: %v, %v", best, err)
+	}
+
+	repo := NewLeaderboardRepository(db)
+	points := leaderboard.Query{Metric: leaderboard.MetricPoints, Scope: leaderboard.ScopeGlobal, Window: leaderboard.WindowAll}
+	rows, err := repo.Top(ctx, points, 10)
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("积分榜查询失败: %+v, %v", rows, err)
+	}
+	if rows[0].UserID != alice || rows[0].Value != 175 || rows[1].UserID != bob || rows[1].Value != 175 || rows[2].Value != 25 {
+		t.Fatalf("积分应按章节最佳得分率累加: %+v", rows)
+	}
+
+	week := points
+	week.Window = leaderboard.WindowWeek
+	week.WeekStart = leaderboard.WeekStart(time.Now())
+	if weekly, _ := repo.Top(ctx, week, 10); len(weekly) != 3 || weekly[0].Value != 175 {
+		t.Fatalf("周积分不正确: %+v", weekly)
+	}
+	lastWeek := week
+	lastWeek.WeekStart = week.WeekStart.AddDate(0, 0, -7)
+	if none, _ := repo.Top(ctx, lastWeek, 10); len(none) != 0 {
+		t.Fatalf("上周不应有积分: %+v", none)
+	}
+
+	mastered := leaderboard.Query{Metric: leaderboard.MetricMastered, Scope: leaderboard.ScopeGlobal, Window: leaderboard.WindowWeek, WeekStart: week.WeekStart}
+	if rows, _ := repo.Top(ctx, mastered, 10); len(rows) != 2 || rows[0].Value != 1 {
+		t.Fatalf("本周掌握章节数不正确: %+v", rows)
+	}
+	chapter := leaderboard.Query{Metric: leaderboard.MetricChapter, Scope: leaderboard.ScopeGlobal, Window: leaderboard.WindowAll, Topic: "types", Chapter: "slice"}
+	if rows, _ := repo.Top(ctx, chapter, 10); len(rows) != 2 || rows[0].Value != 75 {
+		t.Fatalf("章节榜不正确: %+v", rows)
+	}
+
+	standing, err := repo.Standing(ctx, points, carol)
+	if err != nil || standing == nil || standing.Rank != 3 {
+		t.Fatalf("排名不正确: %+v, %v", standing, err)
+	}
+	if err := repo.SavePreferences(ctx, alice, leaderboard.Preferences{OptOut: true}); err != nil {
+		t.Fatalf("保存设置失败: %v", err)
+	}
+	if err := repo.SavePreferences(ctx, bob, leaderboard.Preferences{Nickname: "Bobcat"}); err != nil {
+		t.Fatalf("保存设置失败: %v", err)
+	}
+	rows, _ = repo.Top(ctx, points, 10)
+	if len(rows) != 2 || rows[0].Nickname != "Bobcat" {
+		t.Fatalf("退出者不应出现，昵称应返回: %+v", rows)
+	}
+	if standing, _ := repo.Standing(ctx, points, carol); standing.Rank != 2 {
+		t.Fatalf("退出者不应计入排名: %+v", standing)
+	}
+	if taken, _ := repo.NicknameTaken(ctx, "bobcat", carol); !taken {
+		t.Fatalf("昵称应不区分大小写判重")
+	}
+	if taken, _ := repo.NicknameTaken(ctx, "LB_ALICE", carol); !taken {
+		t.Fatalf("昵称不能与用户名相同")
+	}
+
+	classes := NewClassroomRepository(db)
+	teacher, _ := users.Create(ctx, &user.User{Username: "lb_teacher", PasswordHash: "hash", IsTeacher: true})
+	classID, _ := classes.CreateClass(ctx, &classroom.Class{Name: "排行班", JoinCode: "RANKS234", CreatedBy: teacher, LeaderboardEnabled: true})
+	_ = classes.AddMember(ctx, classroom.Member{ClassID: classID, UserID: carol, Role: classroom.RoleStudent})
+	classPoints := points
+	classPoints.Scope = leaderboard.ScopeClass
+	classPoints.ClassID = classID
+	if rows, _ := repo.Top(ctx, classPoints, 10); len(rows) != 1 || rows[0].UserID != carol {
+		t.Fatalf("班级榜只应包含班级学生: %+v", rows)
+	}
+	if standing, _ := repo.Standing(ctx, classPoints, bob); standing != nil {
+		t.Fatalf("非班级学生在班级榜中应无成绩: %+v", standing)
+	}
+	state, err := repo.ClassState(ctx, classID, carol)
+	if err != nil || state == nil || !state.Enabled || !state.Member {
+		t.Fatalf("班级状态不正确: %+v, %v", state, err)
+	}
+	_ = classes.UpdateLeaderboardEnabled(ctx, classID, false)
+	if state, _ := repo.ClassState(ctx, classID, bob); state.Enabled || state.Member {
+		t.Fatalf("班级排行榜应已关闭: %+v", state)
+	}
+}
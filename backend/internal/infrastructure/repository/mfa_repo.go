@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// MFARepository 使用 GoFrame gdb 实现两步验证仓储。
+type MFARepository struct {
+	db gdb.DB
+}
+
+// NewMFARepository 创建两步验证仓储。
+func NewMFARepository(db gdb.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// FindMFA 查询用户的 TOTP 配置，不存在时返回 nil。
+func (r *MFARepository) FindMFA(ctx context.Context, userID int64) (*user.MFASettings, error) {
+	record, err := r.db.Model("user_mfa").Where("user_id = ?", userID).One(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	settings := &user.MFASettings{
+		UserID:       record["user_id"].Int64(),
+		Secret:       record["secret"].String(),
+		Enabled:      record["enabled"].Bool(),
+		LastUsedStep: record["last_used_step"].Int64(),
+	}
+	if !record["confirmed_at"].IsEmpty() {
+		confirmed := record["confirmed_at"].Time()
+		settings.ConfirmedAt = &confirmed
+	}
+	return settings, nil
+}
+
+// SaveMFA 新增或覆盖用户的 TOTP 配置。
+func (r *MFARepository) SaveMFA(ctx context.Context, settings user.MFASettings) error {
+	_, err := r.db.Exec(ctx, `
+INSERT INTO user_mfa (user_id, secret, enabled, last_used_step, confirmed_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(user_id) DO UPDATE SET
+    secret = excluded.secret,
+    enabled = excluded.enabled,
+    last_used_step = excluded.last_used_step,
+    confirmed_at = excluded.confirmed_at,
+    updated_at = CURRENT_TIMESTAMP`,
+		settings.UserID, settings.Secret, settings.Enabled, settings.LastUsedStep, settings.ConfirmedAt)
+	return err
+}
+
+// DeleteMFA 删除用户的 TOTP 配置、恢复码与未完成的挑战。
+func (r *MFARepository) DeleteMFA(ctx context.Context, userID int64) error {
+	return r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if _, err := tx.Exec("DELETE FROM mfa_recovery_codes WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM mfa_challenges WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM user_mfa WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+// ReplaceRecoveryCodes 作废旧恢复码并写入新的哈希列表。
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	return r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if _, err := tx.Exec("DELETE FROM mfa_recovery_codes WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		for _, hash := range codeHashes {
+			if _, err := tx.Insert("mfa_recovery_codes", map[string]interface{}{
+				"user_id":   userID,
+				"code_hash": hash,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UseRecoveryCode 原子地标记恢复码已使用，返回是否命中未使用的恢复码。
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	res, err := r.db.Exec(ctx, `
+UPDATE mfa_recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// CountRecoveryCodes 统计用户剩余可用的恢复码。
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	return r.db.Model("mfa_recovery_codes").Where("user_id = ? AND used_at IS NULL", userID).Count(ctx)
+}
+
+// SaveMFAChallenge 持久化登录挑战哈希。
+func (r *MFARepository) SaveMFAChallenge(ctx context.Context, challenge user.MFAChallengeRecord) error {
+	_, err := r.db.Insert(ctx, "mfa_challenges", map[string]interface{}{
+		"user_id":    challenge.UserID,
+		"token_hash": challenge.TokenHash,
+		"expires_at": challenge.ExpiresAt,
+	})
+	return err
+}
+
+// FindMFAChallenge 通过哈希查询登录挑战。
+func (r *MFARepository) FindMFAChallenge(ctx context.Context, tokenHash string) (*user.MFAChallengeRecord, error) {
+	record, err := r.db.Model("mfa_challenges").Where("token_hash = ?", tokenHash).One(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Map()) == 0 {
+		return nil, nil
+	}
+	var entity user.MFAChallengeRecord
+	if err := record.Struct(&entity); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// IncrementMFAChallengeAttempts 记录一次失败的验证尝试。
+func (r *MFARepository) IncrementMFAChallengeAttempts(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, "UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = ?", id)
+	return err
+}
+
+// DeleteMFAChallenge 删除已使用或失效的登录挑战。
+func (r *MFARepository) DeleteMFAChallenge(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM mfa_challenges WHERE id = ?", id)
+	return err
+}
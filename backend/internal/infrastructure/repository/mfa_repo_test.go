@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestMFARepository_SettingsAndRecoveryCodes(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	userID, err := NewUserRepository(db).Create(ctx, &user.User{Username: "mfa_repo", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	repo := NewMFARepository(db)
+
+	if err := repo.SaveMFA(ctx, user.MFASettings{UserID: userID, Secret: "SECRET"}); err != nil {
+		t.Fatalf("保存 MFA 失败: %v", err)
+	}
+	confirmed := time.Now()
+	if err := repo.SaveMFA(ctx, user.MFASettings{UserID: userID, Secret: "SECRET", Enabled: true, LastUsedStep: 42, ConfirmedAt: &confirmed}); err != nil {
+		t.Fatalf("更新 MFA 失败: %v", err)
+	}
+	settings, err := repo.FindMFA(ctx, userID)
+	if err != nil || settings == nil {
+		t.Fatalf("查询 MFA 失败: %v", err)
+	}
+	if !settings.Enabled || settings.LastUsedStep != 42 || settings.ConfirmedAt == nil {
+		t.Fatalf("MFA 字段未正确更新: %+v", settings)
+	}
+
+	if err := repo.ReplaceRecoveryCodes(ctx, userID, []string{"h1", "h2"}); err != nil {
+		t.Fatalf("写入恢复码失败: %v", err)
+	}
+	used, err := repo.UseRecoveryCode(ctx, userID, "h1")
+	if err != nil || !used {
+		t.Fatalf("首次使用恢复码应成功: %v", err)
+	}
+	used, _ = repo.UseRecoveryCode(ctx, userID, "h1")
+	if used {
+		t.Fatalf("恢复码不可重复使用")
+	}
+	if count, _ := repo.CountRecoveryCodes(ctx, userID); count != 1 {
+		t.Fatalf("剩余恢复码数量应为 1，得到 %d", count)
+	}
+
+	if err := repo.DeleteMFA(ctx, userID); err != nil {
+		t.Fatalf("删除 MFA 失败: %v", err)
+	}
+	if settings, _ := repo.FindMFA(ctx, userID); settings != nil {
+		t.Fatalf("删除后 MFA 仍存在")
+	}
+	if count, _ := repo.CountRecoveryCodes(ctx, userID); count != 0 {
+		t.Fatalf("删除后恢复码应被清理")
+	}
+}
+
+func TestMFARepository_Challenges(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	userID, _ := NewUserRepository(db).Create(ctx, &user.User{Username: "mfa_challenge", PasswordHash: "hash"})
+	repo := NewMFARepository(db)
+
+	expires := time.Now().Add(time.Minute)
+	if err := repo.SaveMFAChallenge(ctx, user.MFAChallengeRecord{UserID: userID, TokenHash: "challenge-hash", ExpiresAt: expires}); err != nil {
+		t.Fatalf("保存挑战失败: %v", err)
+	}
+	found, err := repo.FindMFAChallenge(ctx, "challenge-hash")
+	if err != nil || found == nil || found.UserID != userID {
+		t.Fatalf("查询挑战失败: %v", err)
+	}
+	if err := repo.IncrementMFAChallengeAttempts(ctx, found.ID); err != nil {
+		t.Fatalf("累加尝试次数失败: %v", err)
+	}
+	found, _ = repo.FindMFAChallenge(ctx, "challenge-hash")
+	if found.Attempts != 1 {
+		t.Fatalf("尝试次数应为 1，得到 %d", found.Attempts)
+	}
+	if err := repo.DeleteMFAChallenge(ctx, found.ID); err != nil {
+		t.Fatalf("删除挑战失败: %v", err)
+	}
+	if found, _ := repo.FindMFAChallenge(ctx, "challenge-hash"); found != nil {
+		t.Fatalf("删除后挑战仍存在")
+	}
+}
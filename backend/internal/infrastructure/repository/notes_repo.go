@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go-study2/internal/domain/notes"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// NotesRepository 使用 GoFrame gdb 实现笔记仓储，并同步维护全文索引。
+type NotesRepository struct {
+	db gdb.DB
+}
+
+// NewNotesRepository 创建笔记仓储。
+func NewNotesRepository(db gdb.DB) *NotesRepository {
+	return &NotesRepository{db: db}
+}
+
+// noteColumns 查询笔记时以逗号拼接标签。
+const noteColumns = `n.id, n.user_id, n.kind, n.topic, n.chapter, n.anchor, n.title, n.body, n.created_at, n.updated_at,
+(SELECT GROUP_CONCAT(t.tag, ',') FROM note_tags t WHERE t.note_id = n.id) AS tags`
+
+// likeEscaper 转义 LIKE 通配符，使关键词按字面匹配。
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Create 在同一事务中保存笔记、标签与全文索引。
+func (r *NotesRepository) Create(ctx context.Context, note *notes.Note) (int64, error) {
+	var id int64
+	err := r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		res, err := tx.Insert("notes", map[string]interface{}{
+			"user_id":    note.UserID,
+			"kind":       note.Kind,
+			"topic":      note.Topic,
+			"chapter":    note.Chapter,
+			"anchor":     note.Anchor,
+			"title":      note.Title,
+			"body":       note.Body,
+			"created_at": note.CreatedAt,
+			"updated_at": note.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+		return writeNoteTagsAndIndex(tx, id, note)
+	})
+	return id, err
+}
+
+// Update 在同一事务中覆盖笔记、重写标签与全文索引。
+func (r *NotesRepository) Update(ctx context.Context, note *notes.Note) error {
+	return r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		_, err := tx.Exec("UPDATE notes SET topic = ?, chapter = ?, anchor = ?, title = ?, body = ?, updated_at = ? WHERE id = ? AND user_id = ?",
+			note.Topic, note.Chapter, note.Anchor, note.Title, note.Body, note.UpdatedAt, note.ID, note.UserID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM note_tags WHERE note_id = ?", note.ID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM notes_fts WHERE rowid = ?", note.ID); err != nil {
+			return err
+		}
+		return writeNoteTagsAndIndex(tx, note.ID, note)
+	})
+}
+
+func writeNoteTagsAndIndex(tx gdb.TX, id int64, note *notes.Note) error {
+	for _, tag := range note.Tags {
+		if _, err := tx.Insert("note_tags", map[string]interface{}{
+			"note_id": id,
+			"tag":     tag,
+		}); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec("INSERT INTO notes_fts (rowid, title, body, tags) VALUES (?, ?, ?, ?)",
+		id, note.Title, note.Body, strings.Join(note.Tags, " "))
+	return err
+}
+
+// Find 按主键查询用户的笔记，不存在时返回 nil。
+func (r *NotesRepository) Find(ctx context.Context, userID, id int64) (*notes.Note, error) {
+	record, err := r.db.GetOne(ctx, "SELECT "+noteColumns+" FROM notes n WHERE n.id = ? AND n.user_id = ?", id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toNote(record), nil
+}
+
+// List 按过滤条件查询，关键词通过全文索引匹配标题、正文与标签。
+func (r *NotesRepository) List(ctx context.Context, userID int64, filter notes.Filter) ([]notes.Note, error) {
+	conds := []string{"n.user_id = ?"}
+	args := []interface{}{userID}
+	if filter.Topic != "" {
+		conds = append(conds, "n.topic = ?")
+		args = append(args, filter.Topic)
+	}
+	if filter.Chapter != "" {
+		conds = append(conds, "n.chapter = ?")
+		args = append(args, filter.Chapter)
+	}
+	if filter.Anchor != "" {
+		conds = append(conds, "n.anchor = ?")
+		args = append(args, filter.Anchor)
+	}
+	if filter.Kind != "" {
+		conds = append(conds, "n.kind = ?")
+		args = append(args, filter.Kind)
+	}
+	if filter.Tag != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM note_tags t WHERE t.note_id = n.id AND t.tag = ?)")
+		args = append(args, filter.Tag)
+	}
+	if terms := strings.Fields(filter.Query); len(terms) > 0 {
+		match := make([]string, 0, len(terms))
+		for _, term := range terms {
+			match = append(match, `(f.title LIKE ? ESCAPE '\' OR f.body LIKE ? ESCAPE '\' OR f.tags LIKE ? ESCAPE '\')`)
+			pattern := "%" + likeEscaper.Replace(term) + "%"
+			args = append(args, pattern, pattern, pattern)
+		}
+		conds = append(conds, "n.id IN (SELECT f.rowid FROM notes_fts f WHERE "+strings.Join(match, " AND ")+")")
+	}
+	query := "SELECT " + noteColumns + " FROM notes n WHERE " + strings.Join(conds, " AND ") + " ORDER BY n.updated_at DESC, n.id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+	records, err := r.db.GetAll(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]notes.Note, 0, len(records))
+	for _, record := range records {
+		list = append(list, *toNote(record))
+	}
+	return list, nil
+}
+
+// Delete 删除用户的笔记、标签与全文索引。
+func (r *NotesRepository) Delete(ctx context.Context, userID, id int64) (bool, error) {
+	var deleted bool
+	err := r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		res, err := tx.Exec("DELETE FROM notes WHERE id = ? AND user_id = ?", id, userID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil || affected == 0 {
+			return err
+		}
+		deleted = true
+		if _, err := tx.Exec("DELETE FROM note_tags WHERE note_id = ?", id); err != nil {
+			return err
+		}
+		_, err = tx.Exec("DELETE FROM notes_fts WHERE rowid = ?", id)
+		return err
+	})
+	return deleted, err
+}
+
+// FindBookmark 查询用户在指定位置的书签，不存在时返回 nil。
+func (r *NotesRepository) FindBookmark(ctx context.Context, userID int64, topic, chapter, anchor string) (*notes.Note, error) {
+	record, err := r.db.GetOne(ctx,
+		"SELECT "+noteColumns+" FROM notes n WHERE n.user_id = ? AND n.kind = ? AND n.topic = ? AND n.chapter = ? AND n.anchor = ?",
+		userID, notes.KindBookmark, topic, chapter, anchor)
+	if err != nil {
+		return nil, err
+	}
+	return toNote(record), nil
+}
+
+func toNote(record gdb.Record) *notes.Note {
+	if record == nil || len(record.Map()) == 0 {
+		return nil
+	}
+	note := &notes.Note{
+		ID:        record["id"].Int64(),
+		UserID:    record["user_id"].Int64(),
+		Kind:      record["kind"].String(),
+		Topic:     record["topic"].String(),
+		Chapter:   record["chapter"].String(),
+		Anchor:    record["anchor"].String(),
+		Title:     record["title"].String(),
+		Body:      record["body"].String(),
+		CreatedAt: record["created_at"].Time(),
+		UpdatedAt: record["updated_at"].Time(),
+		Tags:      []string{},
+	}
+	if raw := record["tags"].String(); raw != "" {
+		note.Tags = strings.Split(raw, ",")
+		sort.Strings(note.Tags)
+	}
+	return note
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/notes"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestNotesRepository_CRUDAndSearch(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	alice, _ := users.Create(ctx, &user.User{Username: "note_alice", PasswordHash: "hash"})
+	bob, _ := users.Create(ctx, &user.User{Username: "note_bob", PasswordHash: "hash"})
+
+	repo := NewNotesRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+	create := func(uid int64, note notes.Note) int64 {
+		note.UserID = uid
+		note.CreatedAt, note.UpdatedAt = now, now
+		now = now.Add(time.Second)
+		id, err := repo.Create(ctx, &note)
+		if err != nil {
+			t.Fatalf("创建笔记失败: %v", err)
+		}
+		return id
+	}
+	sliceNote := create(alice, notes.Note{Kind: notes.KindNote, Topic: "types", Chapter: "slice", Anchor: "slice-append",
+		Title: "切片扩容", Body: "`append` 超过容量时会触发 growslice", Tags: []string{"runtime", "slice"}})
+	mapNote := create(alice, notes.Note{Kind: notes.KindNote, Topic: "types", Chapter: "map", Body: "map 遍历顺序随机，100% 不保证", Tags: []string{"map"}})
+	create(alice, notes.Note{Kind: notes.KindBookmark, Topic: "variables", Chapter: "storage", Anchor: "ex-1", Tags: []string{}})
+	create(bob, notes.Note{Kind: notes.KindNote, Topic: "types", Chapter: "slice", Body: "growslice 的源码", Tags: []string{}})
+
+	found, err := repo.Find(ctx, alice, sliceNote)
+	if err != nil || found == nil || found.Anchor != "slice-append" || len(found.Tags) != 2 || found.Tags[0] != "runtime" {
+		t.Fatalf("查询笔记失败: %+v, %v", found, err)
+	}
+	if other, _ := repo.Find(ctx, bob, sliceNote); other != nil {
+		t.Fatalf("不应查到他人笔记")
+	}
+
+	search := func(filter notes.Filter) []notes.Note {
+		list, err := repo.List(ctx, alice, filter)
+		if err != nil {
+			t.Fatalf("查询笔记列表失败: %v", err)
+		}
+		return list
+	}
+	if list := search(notes.Filter{Topic: "types"}); len(list) != 2 || list[0].ID != mapNote {
+		t.Fatalf("按主题查询应按更新时间倒序: %+v", list)
+	}
+	if list := search(notes.Filter{Query: "GROWSLICE"}); len(list) != 1 || list[0].ID != sliceNote {
+		t.Fatalf("全文检索应不区分大小写且只返回本人笔记: %+v", list)
+	}
+	if list := search(notes.Filter{Query: "扩容 runtime"}); len(list) != 1 {
+		t.Fatalf("多个关键词应同时命中标题与标签: %+v", list)
+	}
+	if list := search(notes.Filter{Query: "100%"}); len(list) != 1 || list[0].ID != mapNote {
+		t.Fatalf("通配符应按字面匹配: %+v", list)
+	}
+	if list := search(notes.Filter{Tag: "map"}); len(list) != 1 || list[0].ID != mapNote {
+		t.Fatalf("按标签查询失败: %+v", list)
+	}
+	if list := search(notes.Filter{Kind: notes.KindBookmark}); len(list) != 1 || list[0].Anchor != "ex-1" {
+		t.Fatalf("按类型查询失败: %+v", list)
+	}
+	if list := search(notes.Filter{Limit: 1, Offset: 1}); len(list) != 1 || list[0].ID != mapNote {
+		t.Fatalf("分页查询失败: %+v", list)
+	}
+	if bookmark, _ := repo.FindBookmark(ctx, alice, "variables", "storage", "ex-1"); bookmark == nil {
+		t.Fatalf("应查到同一位置的书签")
+	}
+
+	found.Body = "已改为讨论 copy"
+	found.Tags = []string{"copy"}
+	found.UpdatedAt = now
+	if err := repo.Update(ctx, found); err != nil {
+		t.Fatalf("更新笔记失败: %v", err)
+	}
+	if list := search(notes.Filter{Query: "growslice"}); len(list) != 0 {
+		t.Fatalf("更新后全文索引应同步: %+v", list)
+	}
+	if list := search(notes.Filter{Tag: "copy"}); len(list) != 1 {
+		t.Fatalf("更新后标签应重写: %+v", list)
+	}
+
+	if deleted, err := repo.Delete(ctx, bob, sliceNote); err != nil || deleted {
+		t.Fatalf("不应删除他人笔记: %v", err)
+	}
+	if deleted, err := repo.Delete(ctx, alice, sliceNote); err != nil || !deleted {
+		t.Fatalf("删除笔记失败: %v", err)
+	}
+	if list := search(notes.Filter{Query: "copy"}); len(list) != 0 {
+		t.Fatalf("删除后不应再检索到: %+v", list)
+	}
+}
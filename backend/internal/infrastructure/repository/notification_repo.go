@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-study2/internal/domain/notifications"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// NotificationRepository 使用 GoFrame gdb 实现通知收件箱与偏好仓储。
+type NotificationRepository struct {
+	db gdb.DB
+}
+
+// NewNotificationRepository 创建通知仓储。
+func NewNotificationRepository(db gdb.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+const notificationColumns = "id, user_id, type, actor_id, title, body, link, read_at, created_at"
+
+// Create 写入一条通知。
+func (r *NotificationRepository) Create(ctx context.Context, n *notifications.Notification) (int64, error) {
+	res, err := r.db.Insert(ctx, "notifications", map[string]interface{}{
+		"user_id":    n.UserID,
+		"type":       n.Type,
+		"actor_id":   n.ActorID,
+		"title":      n.Title,
+		"body":       n.Body,
+		"link":       n.Link,
+		"created_at": n.CreatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// List 按 ID 倒序分页返回通知。
+func (r *NotificationRepository) List(ctx context.Context, userID int64, unreadOnly bool, beforeID int64, limit int) ([]notifications.Notification, error) {
+	query := "SELECT " + notificationColumns + " FROM notifications WHERE user_id = ?"
+	args := []interface{}{userID}
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	if beforeID > 0 {
+		query += " AND id < ?"
+		args = append(args, beforeID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+	return r.query(ctx, query, args...)
+}
+
+// Since 按 ID 正序返回 afterID 之后的通知。
+func (r *NotificationRepository) Since(ctx context.Context, userID, afterID int64, limit int) ([]notifications.Notification, error) {
+	return r.query(ctx, "SELECT "+notificationColumns+" FROM notifications WHERE user_id = ? AND id > ? ORDER BY id LIMIT ?",
+		userID, afterID, limit)
+}
+
+// CountUnread 统计未读通知数。
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID int64) (int, error) {
+	count, err := r.db.GetValue(ctx, "SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL", userID)
+	if err != nil {
+		return 0, err
+	}
+	return count.Int(), nil
+}
+
+// MarkRead 将通知标记为已读，ids 为空表示全部。
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID int64, ids []int64) (int, error) {
+	query := "UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL"
+	args := []interface{}{time.Now().UTC(), userID}
+	if len(ids) > 0 {
+		query += " AND id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	res, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	return int(affected), err
+}
+
+// Preferences 返回用户显式设置过的接收开关。
+func (r *NotificationRepository) Preferences(ctx context.Context, userID int64) (map[string]bool, error) {
+	records, err := r.db.GetAll(ctx, "SELECT type, enabled FROM notification_preferences WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs := make(map[string]bool, len(records))
+	for _, record := range records {
+		prefs[record["type"].String()] = record["enabled"].Bool()
+	}
+	return prefs, nil
+}
+
+// SavePreferences 在同一事务中写入多个类型的开关。
+func (r *NotificationRepository) SavePreferences(ctx context.Context, userID int64, prefs map[string]bool) error {
+	now := time.Now().UTC()
+	return r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		for typ, enabled := range prefs {
+			if _, err := tx.Exec(`INSERT INTO notification_preferences (user_id, type, enabled, updated_at) VALUES (?, ?, ?, ?)
+ON CONFLICT(user_id, type) DO UPDATE SET enabled = excluded.enabled, updated_at = excluded.updated_at`,
+				userID, typ, enabled, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *NotificationRepository) query(ctx context.Context, query string, args ...interface{}) ([]notifications.Notification, error) {
+	records, err := r.db.GetAll(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]notifications.Notification, 0, len(records))
+	for _, record := range records {
+		list = append(list, notifications.Notification{
+			ID:        record["id"].Int64(),
+			UserID:    record["user_id"].Int64(),
+			Type:      record["type"].String(),
+			ActorID:   record["actor_id"].Int64(),
+			Title:     record["title"].String(),
+			Body:      record["body"].String(),
+			Link:      record["link"].String(),
+			Read:      record["read_at"].String() != "",
+			CreatedAt: record["created_at"].Time(),
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/notifications"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestNotificationRepository_InboxAndPreferences(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	alice, _ := users.Create(ctx, &user.User{Username: "notify_alice", PasswordHash: "hash"})
+	bob, _ := users.Create(ctx, &user.User{Username: "notify_bob", PasswordHash: "hash"})
+
+	repo := NewNotificationRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+	var ids []int64
+	for i, uid := range []int64{alice, bob, alice, alice} {
+		id, err := repo.Create(ctx, &notifications.Notification{UserID: uid, Type: "mention", Title: "提及", CreatedAt: now.Add(time.Duration(i) * time.Second)})
+		if err != nil {
+			t.Fatalf("写入通知失败: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	list, err := repo.List(ctx, alice, false, 0, 2)
+	if err != nil || len(list) != 2 || list[0].ID != ids[3] || list[1].ID != ids[2] {
+		t.Fatalf("收件箱应按 ID 倒序分页: %+v %v", list, err)
+	}
+	if older, _ := repo.List(ctx, alice, false, ids[2], 10); len(older) != 1 || older[0].ID != ids[0] {
+		t.Fatalf("beforeID 分页不正确: %+v", older)
+	}
+	if since, _ := repo.Since(ctx, alice, ids[0], 10); len(since) != 2 || since[0].ID != ids[2] {
+		t.Fatalf("续传应按 ID 正序且只含本人通知: %+v", since)
+	}
+
+	if updated, err := repo.MarkRead(ctx, alice, []int64{ids[0], ids[1]}); err != nil || updated != 1 {
+		t.Fatalf("只能标记本人通知: updated=%d %v", updated, err)
+	}
+	if unread, _ := repo.CountUnread(ctx, alice); unread != 2 {
+		t.Fatalf("未读数应为 2, got %d", unread)
+	}
+	if unread, _ := repo.List(ctx, alice, true, 0, 10); len(unread) != 2 || unread[0].Read {
+		t.Fatalf("未读过滤不正确: %+v", unread)
+	}
+	if updated, _ := repo.MarkRead(ctx, alice, nil); updated != 2 {
+		t.Fatalf("全部已读应更新 2 条, got %d", updated)
+	}
+	if read, _ := repo.List(ctx, alice, false, 0, 1); !read[0].Read {
+		t.Fatalf("通知应为已读: %+v", read[0])
+	}
+
+	if err := repo.SavePreferences(ctx, alice, map[string]bool{"mention": false, "assignment": true}); err != nil {
+		t.Fatalf("保存偏好失败: %v", err)
+	}
+	if err := repo.SavePreferences(ctx, alice, map[string]bool{"mention": true}); err != nil {
+		t.Fatalf("更新偏好失败: %v", err)
+	}
+	prefs, err := repo.Preferences(ctx, alice)
+	if err != nil || len(prefs) != 2 || !prefs["mention"] || !prefs["assignment"] {
+		t.Fatalf("偏好不正确: %+v %v", prefs, err)
+	}
+}
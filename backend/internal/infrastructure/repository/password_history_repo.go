@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// PasswordHistoryRepository 使用 GoFrame gdb 实现历史密码仓储。
+type PasswordHistoryRepository struct {
+	db gdb.DB
+}
+
+// NewPasswordHistoryRepository 创建历史密码仓储。
+func NewPasswordHistoryRepository(db gdb.DB) *PasswordHistoryRepository {
+	return &PasswordHistoryRepository{db: db}
+}
+
+// RecentPasswordHashes 按时间倒序返回最近 limit 个历史密码哈希。
+func (r *PasswordHistoryRepository) RecentPasswordHashes(ctx context.Context, userID int64, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	values, err := r.db.Model("password_history").Ctx(ctx).
+		Fields("password_hash").
+		Where("user_id", userID).
+		OrderDesc("id").
+		Limit(limit).
+		Array()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0, len(values))
+	for _, v := range values {
+		hashes = append(hashes, v.String())
+	}
+	return hashes, nil
+}
+
+// AddPasswordHistory 追加历史密码哈希，并删除超出保留数量的旧记录。
+func (r *PasswordHistoryRepository) AddPasswordHistory(ctx context.Context, userID int64, passwordHash string, keep int) error {
+	return r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if _, err := tx.Insert("password_history", map[string]interface{}{
+			"user_id":       userID,
+			"password_hash": passwordHash,
+		}); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+DELETE FROM password_history
+WHERE user_id = ? AND id NOT IN (
+    SELECT id FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?
+)`, userID, userID, keep)
+		return err
+	})
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestPasswordHistoryRepository_KeepsRecent(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	users := NewUserRepository(db)
+	userID, err := users.Create(ctx, &user.User{Username: "history_repo", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	otherID, _ := users.Create(ctx, &user.User{Username: "history_other", PasswordHash: "hash"})
+	repo := NewPasswordHistoryRepository(db)
+
+	for i := 1; i <= 4; i++ {
+		if err := repo.AddPasswordHistory(ctx, userID, fmt.Sprintf("hash-%d", i), 3); err != nil {
+			t.Fatalf("写入历史密码失败: %v", err)
+		}
+	}
+	if err := repo.AddPasswordHistory(ctx, otherID, "other-hash", 3); err != nil {
+		t.Fatalf("写入历史密码失败: %v", err)
+	}
+
+	hashes, err := repo.RecentPasswordHashes(ctx, userID, 10)
+	if err != nil {
+		t.Fatalf("查询历史密码失败: %v", err)
+	}
+	if len(hashes) != 3 || hashes[0] != "hash-4" || hashes[2] != "hash-2" {
+		t.Fatalf("应按倒序仅保留最近 3 条，得到: %v", hashes)
+	}
+	if limited, _ := repo.RecentPasswordHashes(ctx, userID, 1); len(limited) != 1 || limited[0] != "hash-4" {
+		t.Fatalf("limit 未生效: %v", limited)
+	}
+	if other, _ := repo.RecentPasswordHashes(ctx, otherID, 10); len(other) != 1 {
+		t.Fatalf("不应清理其他用户的历史: %v", other)
+	}
+}
+
+func TestUserRepository_PasswordChangedAt(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	repo := NewUserRepository(db)
+	userID, err := repo.Create(ctx, &user.User{Username: "aging_repo", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	created, _ := repo.FindByID(ctx, userID)
+	if created == nil || created.PasswordChangedAt.IsZero() {
+		t.Fatalf("创建用户时应记录密码设置时间: %+v", created)
+	}
+
+	if err := repo.MarkMustChangePassword(ctx, userID); err != nil {
+		t.Fatalf("设置需改密标记失败: %v", err)
+	}
+	marked, _ := repo.FindByID(ctx, userID)
+	if !marked.MustChangePassword || !marked.PasswordChangedAt.Equal(created.PasswordChangedAt) {
+		t.Fatalf("标记需改密不应改变密码设置时间: %+v", marked)
+	}
+}
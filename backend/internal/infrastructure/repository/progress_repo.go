@@ -46,6 +46,14 @@ func (r *ProgressRepository) ListByTopic(ctx context.Context, userID int64, topi
 	return r.query(ctx, r.db.Model("learning_progress").Where("user_id", userID).Where("topic", topic))
 }
 
+// ListIdle 返回指定状态且最近访问时间落在 [from, to) 内的进度，时间按 Upsert 写入时的本地时区比较。
+func (r *ProgressRepository) ListIdle(ctx context.Context, status string, from, to time.Time) ([]progress.Progress, error) {
+	return r.query(ctx, r.db.Model("learning_progress").
+		Where("status", status).
+		WhereGTE("last_visit", from.Local()).
+		WhereLT("last_visit", to.Local()))
+}
+
 func (r *ProgressRepository) query(ctx context.Context, model *gdb.Model) ([]progress.Progress, error) {
 	records, err := model.OrderDesc("last_visit").All(ctx)
 	if err != nil {
@@ -61,6 +61,19 @@ func TestProgressRepository_UpsertAndQuery(t *testing.T) {
 	if topicProgress[0].LastPosition == "" {
 		t.Fatalf("LastPosition 应被保存")
 	}
+
+	createTestUser(t, db, 2)
+	if err := repo.Upsert(ctx, &progress.Progress{UserID: 2, Topic: "constants", Chapter: "iota", Status: progress.StatusInProgress}); err != nil {
+		t.Fatalf("写入进度失败: %v", err)
+	}
+	now := time.Now()
+	idle, err := repo.ListIdle(ctx, progress.StatusInProgress, now.Add(-time.Hour), now.Add(time.Minute))
+	if err != nil || len(idle) != 1 || idle[0].UserID != 2 {
+		t.Fatalf("应只返回时间窗口内指定状态的进度: %+v %v", idle, err)
+	}
+	if idle, _ := repo.ListIdle(ctx, progress.StatusInProgress, now.Add(-2*time.Hour), now.Add(-time.Hour)); len(idle) != 0 {
+		t.Fatalf("窗口之外的进度不应返回: %+v", idle)
+	}
 }
 
 func setupProgressRepoDB(t *testing.T) gdb.DB {
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"go-study2/internal/domain/quiz"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// QuestionBankRepository 使用 GoFrame gdb 实现题库仓储。
+type QuestionBankRepository struct {
+	db gdb.DB
+}
+
+// NewQuestionBankRepository 创建题库仓储。
+func NewQuestionBankRepository(db gdb.DB) *QuestionBankRepository {
+	return &QuestionBankRepository{db: db}
+}
+
+// questionColumns 查询题目时以逗号拼接标签。
+const questionColumns = `q.id, q.question_id, q.topic, q.chapter, q.stem, q.options, q.answer, q.explanation,
+q.difficulty, q.rule_ref, q.status, q.revision, q.created_by, q.updated_by, q.created_at, q.updated_at,
+(SELECT GROUP_CONCAT(t.tag, ',') FROM quiz_question_tags t WHERE t.question_id = q.id) AS tags`
+
+// CreateQuestion 在同一事务中保存题目、标签与第 1 版修订。
+func (r *QuestionBankRepository) CreateQuestion(ctx context.Context, question *quiz.BankQuestion) (int64, error) {
+	options, err := json.Marshal(question.Options)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		res, err := tx.Insert("quiz_questions", map[string]interface{}{
+			"question_id": question.QuestionID,
+			"topic":       question.Topic,
+			"chapter":     question.Chapter,
+			"stem":        question.Stem,
+			"options":     string(options),
+			"answer":      question.Answer,
+			"explanation": question.Explanation,
+			"difficulty":  question.Difficulty,
+			"rule_ref":    question.RuleRef,
+			"status":      question.Status,
+			"revision":    question.Revision,
+			"created_by":  question.CreatedBy,
+			"updated_by":  question.UpdatedBy,
+		})
+		if err != nil {
+			return err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+		snapshot := *question
+		snapshot.ID = id
+		return writeQuestionTagsAndRevision(tx, &snapshot)
+	})
+	return id, err
+}
+
+// UpdateQuestion 在同一事务中覆盖题目、重写标签并追加修订。
+func (r *QuestionBankRepository) UpdateQuestion(ctx context.Context, question *quiz.BankQuestion) error {
+	options, err := json.Marshal(question.Options)
+	if err != nil {
+		return err
+	}
+	return r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		_, err := tx.Exec(`UPDATE quiz_questions SET topic = ?, chapter = ?, stem = ?, options = ?, answer = ?, explanation = ?,
+difficulty = ?, rule_ref = ?, status = ?, revision = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+			question.Topic, question.Chapter, question.Stem, string(options), question.Answer, question.Explanation,
+			question.Difficulty, question.RuleRef, question.Status, question.Revision, question.UpdatedBy, question.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM quiz_question_tags WHERE question_id = ?", question.ID); err != nil {
+			return err
+		}
+		return writeQuestionTagsAndRevision(tx, question)
+	})
+}
+
+func writeQuestionTagsAndRevision(tx gdb.TX, question *quiz.BankQuestion) error {
+	for _, tag := range question.Tags {
+		if _, err := tx.Insert("quiz_question_tags", map[string]interface{}{
+			"question_id": question.ID,
+			"tag":         tag,
+		}); err != nil {
+			return err
+		}
+	}
+	snapshot := *question
+	snapshot.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = snapshot.UpdatedAt
+	}
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Insert("quiz_question_revisions", map[string]interface{}{
+		"question_id": question.ID,
+		"revision":    question.Revision,
+		"editor_id":   question.UpdatedBy,
+		"snapshot":    string(raw),
+	})
+	return err
+}
+
+// FindQuestion 按主键查询题目，不存在时返回 nil。
+func (r *QuestionBankRepository) FindQuestion(ctx context.Context, id int64) (*quiz.BankQuestion, error) {
+	record, err := r.db.GetOne(ctx, "SELECT "+questionColumns+" FROM quiz_questions q WHERE q.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return toBankQuestion(record), nil
+}
+
+// FindQuestionByQuestionID 按测验题目 ID 查询，不存在时返回 nil。
+func (r *QuestionBankRepository) FindQuestionByQuestionID(ctx context.Context, questionID string) (*quiz.BankQuestion, error) {
+	record, err := r.db.GetOne(ctx, "SELECT "+questionColumns+" FROM quiz_questions q WHERE q.question_id = ?", questionID)
+	if err != nil {
+		return nil, err
+	}
+	return toBankQuestion(record), nil
+}
+
+// ListQuestions 按主题、章节与 ID 顺序列出符合条件的题目。
+func (r *QuestionBankRepository) ListQuestions(ctx context.Context, filter quiz.BankFilter) ([]quiz.BankQuestion, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if filter.Topic != "" {
+		conds = append(conds, "q.topic = ?")
+		args = append(args, filter.Topic)
+	}
+	if filter.Chapter != "" {
+		conds = append(conds, "q.chapter = ?")
+		args = append(args, filter.Chapter)
+	}
+	if filter.Status != "" {
+		conds = append(conds, "q.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Tag != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM quiz_question_tags t WHERE t.question_id = q.id AND t.tag = ?)")
+		args = append(args, filter.Tag)
+	}
+	query := "SELECT " + questionColumns + " FROM quiz_questions q"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	records, err := r.db.GetAll(ctx, query+" ORDER BY q.topic, q.chapter, q.id", args...)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]quiz.BankQuestion, 0, len(records))
+	for _, record := range records {
+		list = append(list, *toBankQuestion(record))
+	}
+	return list, nil
+}
+
+// DeleteQuestion 删除题目、标签与修订历史。
+func (r *QuestionBankRepository) DeleteQuestion(ctx context.Context, id int64) error {
+	return r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if _, err := tx.Exec("DELETE FROM quiz_question_revisions WHERE question_id = ?", id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM quiz_question_tags WHERE question_id = ?", id); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM quiz_questions WHERE id = ?", id)
+		return err
+	})
+}
+
+// ListRevisions 按版本号倒序返回题目的修订历史。
+func (r *QuestionBankRepository) ListRevisions(ctx context.Context, id int64) ([]quiz.BankRevision, error) {
+	records, err := r.db.GetAll(ctx,
+		"SELECT revision, editor_id, snapshot, created_at FROM quiz_question_revisions WHERE question_id = ? ORDER BY revision DESC", id)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]quiz.BankRevision, 0, len(records))
+	for _, record := range records {
+		revision := quiz.BankRevision{
+			Revision:  record["revision"].Int(),
+			EditorID:  record["editor_id"].Int64(),
+			CreatedAt: record["created_at"].Time(),
+		}
+		if err := json.Unmarshal([]byte(record["snapshot"].String()), &revision.Question); err != nil {
+			return nil, err
+		}
+		list = append(list, revision)
+	}
+	return list, nil
+}
+
+func toBankQuestion(record gdb.Record) *quiz.BankQuestion {
+	if record == nil || len(record.Map()) == 0 {
+		return nil
+	}
+	question := &quiz.BankQuestion{
+		ID:          record["id"].Int64(),
+		QuestionID:  record["question_id"].String(),
+		Topic:       record["topic"].String(),
+		Chapter:     record["chapter"].String(),
+		Stem:        record["stem"].String(),
+		Answer:      record["answer"].String(),
+		Explanation: record["explanation"].String(),
+		Difficulty:  record["difficulty"].String(),
+		RuleRef:     record["rule_ref"].String(),
+		Status:      record["status"].String(),
+		Revision:    record["revision"].Int(),
+		CreatedBy:   record["created_by"].Int64(),
+		UpdatedBy:   record["updated_by"].Int64(),
+		CreatedAt:   record["created_at"].Time(),
+		UpdatedAt:   record["updated_at"].Time(),
+		Tags:        []string{},
+	}
+	_ = json.Unmarshal([]byte(record["options"].String()), &question.Options)
+	if raw := record["tags"].String(); raw != "" {
+		question.Tags = strings.Split(raw, ",")
+		sort.Strings(question.Tags)
+	}
+	return question
+}
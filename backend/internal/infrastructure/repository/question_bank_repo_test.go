@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"testing"
+
+	"go-study2/internal/domain/quiz"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestQuestionBankRepository_CRUDAndRevisions(t *testing.T) {
+	ctx := gctx.New()
+	repo := NewQuestionBankRepository(setupRepoDB(t))
+
+	question := &quiz.BankQuestion{
+		QuestionID: "bank-repo-1",
+		Topic:      "types",
+		Chapter:    "map",
+		Stem:       "切片能否作为 map 的键？",
+		Options:    []string{"能", "不能"},
+		Answer:     "B",
+		Tags:       []string{"map", "comparable"},
+		Difficulty: quiz.DifficultyEasy,
+		RuleRef:    "TR-MAP-KEY",
+		Status:     quiz.BankStatusDraft,
+		Revision:   1,
+		CreatedBy:  3,
+		UpdatedBy:  3,
+	}
+	id, err := repo.CreateQuestion(ctx, question)
+	if err != nil {
+		t.Fatalf("创建题目失败: %v", err)
+	}
+	found, err := repo.FindQuestionByQuestionID(ctx, "bank-repo-1")
+	if err != nil || found == nil || found.ID != id || len(found.Options) != 2 || found.CreatedAt.IsZero() {
+		t.Fatalf("读取题目失败: %+v, %v", found, err)
+	}
+	if len(found.Tags) != 2 || found.Tags[0] != "comparable" {
+		t.Fatalf("标签读取不正确: %+v", found.Tags)
+	}
+
+	found.Status = quiz.BankStatusPublished
+	found.Tags = []string{"map"}
+	found.Revision = 2
+	found.UpdatedBy = 4
+	if err := repo.UpdateQuestion(ctx, found); err != nil {
+		t.Fatalf("更新题目失败: %v", err)
+	}
+	published, err := repo.ListQuestions(ctx, quiz.BankFilter{Topic: "types", Chapter: "map", Status: quiz.BankStatusPublished})
+	if err != nil || len(published) != 1 || published[0].Revision != 2 || len(published[0].Tags) != 1 {
+		t.Fatalf("按状态过滤失败: %+v, %v", published, err)
+	}
+	if byTag, _ := repo.ListQuestions(ctx, quiz.BankFilter{Tag: "comparable"}); len(byTag) != 0 {
+		t.Fatalf("旧标签应已移除: %+v", byTag)
+	}
+
+	revisions, err := repo.ListRevisions(ctx, id)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("修订历史不正确: %+v, %v", revisions, err)
+	}
+	if revisions[0].Revision != 2 || revisions[0].EditorID != 4 || revisions[0].Question.Status != quiz.BankStatusPublished ||
+		revisions[1].Question.Status != quiz.BankStatusDraft || len(revisions[1].Question.Tags) != 2 {
+		t.Fatalf("修订快照不正确: %+v", revisions)
+	}
+
+	if err := repo.DeleteQuestion(ctx, id); err != nil {
+		t.Fatalf("删除题目失败: %v", err)
+	}
+	if gone, _ := repo.FindQuestion(ctx, id); gone != nil {
+		t.Fatalf("题目应已删除")
+	}
+	if left, _ := repo.ListRevisions(ctx, id); len(left) != 0 {
+		t.Fatalf("修订历史应随题目删除")
+	}
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"go-study2/internal/domain/leaderboard"
 	"go-study2/internal/domain/quiz"
 
 	"github.com/gogf/gf/v2/database/gdb"
@@ -19,25 +21,58 @@ func NewQuizRepository(db gdb.DB) *QuizRepository {
 	return &QuizRepository{db: db}
 }
 
-// SaveRecord 保存测验记录。
+// SaveRecord 保存测验记录，并在同一事务中更新排行榜汇总。
 func (r *QuizRepository) SaveRecord(ctx context.Context, record *quiz.Record) (int64, error) {
-	result, err := r.db.Insert(ctx, "quiz_records", map[string]interface{}{
-		"user_id":     record.UserID,
-		"topic":       record.Topic,
-		"chapter":     nullableString(record.Chapter),
-		"score":       record.Score,
-		"total":       record.Total,
-		"duration_ms": record.DurationMs,
-		"answers":     record.Answers,
+	var id int64
+	err := r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		result, err := tx.Insert("quiz_records", map[string]interface{}{
+			"user_id":     record.UserID,
+			"topic":       record.Topic,
+			"chapter":     nullableString(record.Chapter),
+			"score":       record.Score,
+			"total":       record.Total,
+			"duration_ms": record.DurationMs,
+			"answers":     record.Answers,
+		})
+		if err != nil {
+			return err
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(upsertChapterBestSQL, id); err != nil {
+			return err
+		}
+		_, err = tx.Exec(upsertWeeklyBestSQL, id)
+		return err
 	})
 	if err != nil {
 		return 0, err
 	}
-	id, _ := result.LastInsertId()
 	record.ID = id
 	return id, nil
 }
 
+// upsertChapterBestSQL 以新记录更新章节最佳得分率、测验次数与首次掌握时间。
+var upsertChapterBestSQL = fmt.Sprintf(`
+INSERT INTO quiz_chapter_best (user_id, topic, chapter, best_percent, attempts, mastered_at, last_at)
+SELECT user_id, topic, COALESCE(chapter, ''), score * 100.0 / total, 1,
+       CASE WHEN score * 100.0 / total >= %[1]d THEN created_at END, created_at
+FROM quiz_records WHERE id = ? AND total > 0
+ON CONFLICT (user_id, topic, chapter) DO UPDATE SET
+    best_percent = MAX(best_percent, excluded.best_percent),
+    attempts = attempts + 1,
+    mastered_at = COALESCE(mastered_at, excluded.mastered_at),
+    last_at = excluded.last_at`, leaderboard.MasteryPercent)
+
+// upsertWeeklyBestSQL 以新记录更新所在自然周（周一开始）的章节最佳得分率。
+const upsertWeeklyBestSQL = `
+INSERT INTO quiz_weekly_best (user_id, week_start, topic, chapter, best_percent)
+SELECT user_id, date(created_at, 'weekday 0', '-6 days'), topic, COALESCE(chapter, ''), score * 100.0 / total
+FROM quiz_records WHERE id = ? AND total > 0
+ON CONFLICT (user_id, week_start, topic, chapter) DO UPDATE SET
+    best_percent = MAX(best_percent, excluded.best_percent)`
+
 // ListRecords 查询测验历史。
 func (r *QuizRepository) ListRecords(ctx context.Context, userID int64, topic string, from, to *time.Time) ([]quiz.Record, error) {
 	model := r.db.Model("quiz_records").Where("user_id", userID)
@@ -25,8 +25,10 @@ func (r *UserRepository) Create(ctx context.Context, entity *user.User) (int64,
 		"username":             entity.Username,
 		"password_hash":        entity.PasswordHash,
 		"is_admin":             entity.IsAdmin,
+		"is_teacher":           entity.IsTeacher,
 		"status":               chooseStatus(entity.Status),
 		"must_change_password": entity.MustChangePassword,
+		"password_changed_at":  gdb.Raw("CURRENT_TIMESTAMP"),
 	})
 	if err != nil {
 		return 0, err
@@ -90,11 +92,29 @@ func (r *UserRepository) DeleteRefreshTokensByUser(ctx context.Context, userID i
 func (r *UserRepository) UpdatePasswordAndFlag(ctx context.Context, userID int64, passwordHash string, mustChange bool) error {
 	_, err := r.db.Exec(ctx, `
 UPDATE users
-SET password_hash = ?, must_change_password = ?, updated_at = CURRENT_TIMESTAMP
+SET password_hash = ?, must_change_password = ?, password_changed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?`, passwordHash, mustChange, userID)
 	return err
 }
 
+// MarkMustChangePassword 设置需改密标记，用于密码过期等场景。
+func (r *UserRepository) MarkMustChangePassword(ctx context.Context, userID int64) error {
+	_, err := r.db.Exec(ctx, `
+UPDATE users
+SET must_change_password = 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?`, userID)
+	return err
+}
+
+// UpdateTeacherFlag 设置或取消教师角色。
+func (r *UserRepository) UpdateTeacherFlag(ctx context.Context, userID int64, isTeacher bool) error {
+	_, err := r.db.Exec(ctx, `
+UPDATE users
+SET is_teacher = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?`, isTeacher, userID)
+	return err
+}
+
 // FindRefreshToken 通过哈希查询刷新令牌记录。
 func (r *UserRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*user.RefreshToken, error) {
 	record, err := r.db.Model("refresh_tokens").Where("token_hash = ?", tokenHash).One(ctx)
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-study2/internal/domain/webhooks"
+
+	"github.com/gogf/gf/v2/database/gdb"
+)
+
+// WebhookRepository 使用 GoFrame gdb 实现 Webhook 接收地址与发件箱仓储。
+type WebhookRepository struct {
+	db gdb.DB
+}
+
+// NewWebhookRepository 创建 Webhook 仓储。
+func NewWebhookRepository(db gdb.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const (
+	webhookEndpointColumns = "id, name, url, secret, events, active, created_by, created_at, updated_at"
+	webhookDeliveryColumns = "id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_error, response_status, created_at, delivered_at"
+)
+
+// CreateEndpoint 写入接收地址。
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *webhooks.Endpoint) (int64, error) {
+	res, err := r.db.Insert(ctx, "webhook_endpoints", map[string]interface{}{
+		"name":       endpoint.Name,
+		"url":        endpoint.URL,
+		"secret":     endpoint.Secret,
+		"events":     strings.Join(endpoint.Events, " "),
+		"active":     endpoint.Active,
+		"created_by": endpoint.CreatedBy,
+		"created_at": endpoint.CreatedAt,
+		"updated_at": endpoint.UpdatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// UpdateEndpoint 更新名称、地址、订阅事件与启用状态。
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *webhooks.Endpoint) error {
+	_, err := r.db.Exec(ctx, "UPDATE webhook_endpoints SET name = ?, url = ?, events = ?, active = ?, updated_at = ? WHERE id = ?",
+		endpoint.Name, endpoint.URL, strings.Join(endpoint.Events, " "), endpoint.Active, endpoint.UpdatedAt, endpoint.ID)
+	return err
+}
+
+// DeleteEndpoint 在同一事务中删除接收地址及其投递记录。
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id int64) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if _, err := tx.Exec("DELETE FROM webhook_deliveries WHERE endpoint_id = ?", id); err != nil {
+			return err
+		}
+		res, err := tx.Exec("DELETE FROM webhook_endpoints WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		deleted = affected > 0
+		return err
+	})
+	return deleted, err
+}
+
+// FindEndpoint 按 ID 查询接收地址，未找到时返回 nil。
+func (r *WebhookRepository) FindEndpoint(ctx context.Context, id int64) (*webhooks.Endpoint, error) {
+	list, err := r.queryEndpoints(ctx, "SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE id = ?", id)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// ListEndpoints 按创建顺序返回全部接收地址。
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]webhooks.Endpoint, error) {
+	return r.queryEndpoints(ctx, "SELECT "+webhookEndpointColumns+" FROM webhook_endpoints ORDER BY id")
+}
+
+// ActiveEndpointsFor 返回订阅了该事件类型的启用地址。
+func (r *WebhookRepository) ActiveEndpointsFor(ctx context.Context, eventType string) ([]webhooks.Endpoint, error) {
+	return r.queryEndpoints(ctx, "SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE active = 1 AND (' ' || events || ' ') LIKE ? ORDER BY id",
+		"% "+eventType+" %")
+}
+
+// CreateDelivery 写入一条待投递记录。
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *webhooks.Delivery) (int64, error) {
+	res, err := r.db.Insert(ctx, "webhook_deliveries", map[string]interface{}{
+		"endpoint_id":     delivery.EndpointID,
+		"event_id":        delivery.EventID,
+		"event_type":      delivery.EventType,
+		"payload":         delivery.Payload,
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"created_at":      delivery.CreatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// FindDelivery 按 ID 查询投递记录，未找到时返回 nil。
+func (r *WebhookRepository) FindDelivery(ctx context.Context, id int64) (*webhooks.Delivery, error) {
+	list, err := r.queryDeliveries(ctx, "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = ?", id)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// ListDeliveries 按 ID 倒序返回接收地址的投递记录。
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID int64, status string, limit int) ([]webhooks.Delivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE endpoint_id = ?"
+	args := []interface{}{endpointID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+	return r.queryDeliveries(ctx, query, args...)
+}
+
+// DueDeliveries 按到期时间正序返回待投递记录。
+func (r *WebhookRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]webhooks.Delivery, error) {
+	return r.queryDeliveries(ctx, "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?",
+		webhooks.StatusPending, now, limit)
+}
+
+// ClaimDelivery 以条件更新领取到期的待投递记录。
+func (r *WebhookRepository) ClaimDelivery(ctx context.Context, id int64, now, until time.Time) (bool, error) {
+	res, err := r.db.Exec(ctx, "UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at <= ?",
+		until, id, webhooks.StatusPending, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// SaveAttempt 写入投递结果。
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, delivery *webhooks.Delivery) error {
+	var deliveredAt interface{}
+	if delivery.DeliveredAt != nil {
+		deliveredAt = *delivery.DeliveredAt
+	}
+	_, err := r.db.Exec(ctx, `UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?,
+response_status = ?, delivered_at = ? WHERE id = ?`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.ResponseStatus, deliveredAt, delivery.ID)
+	return err
+}
+
+func (r *WebhookRepository) queryEndpoints(ctx context.Context, query string, args ...interface{}) ([]webhooks.Endpoint, error) {
+	records, err := r.db.GetAll(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]webhooks.Endpoint, 0, len(records))
+	for _, record := range records {
+		list = append(list, webhooks.Endpoint{
+			ID:        record["id"].Int64(),
+			Name:      record["name"].String(),
+			URL:       record["url"].String(),
+			Secret:    record["secret"].String(),
+			Events:    strings.Fields(record["events"].String()),
+			Active:    record["active"].Bool(),
+			CreatedBy: record["created_by"].Int64(),
+			CreatedAt: record["created_at"].Time(),
+			UpdatedAt: record["updated_at"].Time(),
+		})
+	}
+	return list, nil
+}
+
+func (r *WebhookRepository) queryDeliveries(ctx context.Context, query string, args ...interface{}) ([]webhooks.Delivery, error) {
+	records, err := r.db.GetAll(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]webhooks.Delivery, 0, len(records))
+	for _, record := range records {
+		delivery := webhooks.Delivery{
+			ID:             record["id"].Int64(),
+			EndpointID:     record["endpoint_id"].Int64(),
+			EventID:        record["event_id"].String(),
+			EventType:      record["event_type"].String(),
+			Payload:        record["payload"].String(),
+			Status:         record["status"].String(),
+			Attempts:       record["attempts"].Int(),
+			NextAttemptAt:  record["next_attempt_at"].Time(),
+			LastError:      record["last_error"].String(),
+			ResponseStatus: record["response_status"].Int(),
+			CreatedAt:      record["created_at"].Time(),
+		}
+		if !record["delivered_at"].IsEmpty() {
+			deliveredAt := record["delivered_at"].Time()
+			delivery.DeliveredAt = &deliveredAt
+		}
+		list = append(list, delivery)
+	}
+	return list, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/webhooks"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestWebhookRepository_EndpointsAndOutbox(t *testing.T) {
+	ctx := gctx.New()
+	db := setupRepoDB(t)
+	repo := NewWebhookRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	quizID, err := repo.CreateEndpoint(ctx, &webhooks.Endpoint{Name: "lms", URL: "https://lms.example/hook", Secret: "whsec_a",
+		Events: []string{"quiz.submitted", "progress.done"}, Active: true, CreatedAt: now, UpdatedAt: now})
+	if err != nil {
+		t.Fatalf("创建接收地址失败: %v", err)
+	}
+	idleID, _ := repo.CreateEndpoint(ctx, &webhooks.Endpoint{Name: "idle", URL: "https://idle.example/hook", Secret: "whsec_b",
+		Events: []string{"quiz.submitted"}, Active: false, CreatedAt: now, UpdatedAt: now})
+
+	if active, _ := repo.ActiveEndpointsFor(ctx, "quiz.submitted"); len(active) != 1 || active[0].ID != quizID || active[0].Secret != "whsec_a" {
+		t.Fatalf("只应返回启用且订阅该事件的地址: %+v", active)
+	}
+	if active, _ := repo.ActiveEndpointsFor(ctx, "user.created"); len(active) != 0 {
+		t.Fatalf("未订阅的事件不应匹配: %+v", active)
+	}
+	if active, _ := repo.ActiveEndpointsFor(ctx, "progress"); len(active) != 0 {
+		t.Fatalf("事件类型需完整匹配: %+v", active)
+	}
+
+	endpoint, _ := repo.FindEndpoint(ctx, idleID)
+	endpoint.Active = true
+	endpoint.Events = []string{"user.created"}
+	if err := repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		t.Fatalf("更新接收地址失败: %v", err)
+	}
+	if found, _ := repo.FindEndpoint(ctx, idleID); !found.Active || len(found.Events) != 1 || found.Events[0] != "user.created" {
+		t.Fatalf("更新未生效: %+v", found)
+	}
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		id, err := repo.CreateDelivery(ctx, &webhooks.Delivery{EndpointID: quizID, EventID: "evt_1", EventType: "quiz.submitted",
+			Payload: "{}", Status: webhooks.StatusPending, NextAttemptAt: now.Add(time.Duration(i-1) * time.Minute), CreatedAt: now})
+		if err != nil {
+			t.Fatalf("写入投递失败: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	due, err := repo.DueDeliveries(ctx, now, 10)
+	if err != nil || len(due) != 2 || due[0].ID != ids[0] {
+		t.Fatalf("应按到期时间返回已到期记录: %+v %v", due, err)
+	}
+	if claimed, _ := repo.ClaimDelivery(ctx, ids[0], now, now.Add(time.Minute)); !claimed {
+		t.Fatalf("首次领取应成功")
+	}
+	if claimed, _ := repo.ClaimDelivery(ctx, ids[0], now, now.Add(time.Minute)); claimed {
+		t.Fatalf("已领取的记录不应被再次领取")
+	}
+
+	delivered := now
+	due[0].Status = webhooks.StatusDelivered
+	due[0].Attempts = 1
+	due[0].ResponseStatus = 204
+	due[0].DeliveredAt = &delivered
+	if err := repo.SaveAttempt(ctx, &due[0]); err != nil {
+		t.Fatalf("保存投递结果失败: %v", err)
+	}
+	found, _ := repo.FindDelivery(ctx, ids[0])
+	if found.Status != webhooks.StatusDelivered || found.Attempts != 1 || found.ResponseStatus != 204 || found.DeliveredAt == nil {
+		t.Fatalf("投递结果未保存: %+v", found)
+	}
+	if list, _ := repo.ListDeliveries(ctx, quizID, webhooks.StatusPending, 10); len(list) != 2 || list[0].ID != ids[2] {
+		t.Fatalf("投递记录应按状态过滤并倒序: %+v", list)
+	}
+
+	if deleted, err := repo.DeleteEndpoint(ctx, quizID); err != nil || !deleted {
+		t.Fatalf("删除接收地址失败: %v", err)
+	}
+	if found, _ := repo.FindDelivery(ctx, ids[1]); found != nil {
+		t.Fatalf("删除接收地址应一并删除投递记录")
+	}
+	if deleted, _ := repo.DeleteEndpoint(ctx, quizID); deleted {
+		t.Fatalf("重复删除应返回 false")
+	}
+}
@@ -0,0 +1,124 @@
+// Package tracing 初始化 OpenTelemetry 链路追踪：HTTP 请求、领域服务调用与数据库查询各生成一个 span，
+// 按配置导出到标准输出或 OTLP/HTTP 采集器。
+//
+// GoFrame 内置的 HTTP、gdb 与 gclient 追踪会把完整请求头（含 Authorization）和带实参的 SQL 写入 span，
+// 因此安装时屏蔽这些 tracer，改由 middleware.Tracing 与 database 包生成不含敏感信息的 span。
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go-study2/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// 导出方式。
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// instrumentationName 本项目创建 span 使用的 tracer 名称。
+const instrumentationName = "go-study2"
+
+// defaultServiceName 未配置 tracing.serviceName 时上报的服务名。
+const defaultServiceName = "go-study2"
+
+// goframePrefix GoFrame 内置组件 tracer 名称的前缀。
+const goframePrefix = "github.com/gogf/gf/"
+
+// Init 按配置创建 TracerProvider 并安装为全局 provider，返回的 shutdown 用于退出前刷新未导出的 span。
+// exporter 为空或 none 时不做任何事，GoFrame 默认 provider 仍只生成追踪 ID 而不采集。
+func Init(ctx context.Context, cfg config.TracingConfig) (shutdown func(context.Context) error, err error) {
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tp := NewProvider(exporter, cfg)
+	Install(tp)
+	return tp.Shutdown, nil
+}
+
+// NewProvider 创建批量导出到 exporter 的 TracerProvider，服务名与采样比例取自配置。
+func NewProvider(exporter sdktrace.SpanExporter, cfg config.TracingConfig) *sdktrace.TracerProvider {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	ratio := cfg.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+	root := sdktrace.TraceIDRatioBased(ratio)
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		// gctx.New 生成的上下文带有未采样的本地父 span，按根 span 重新采样，后台任务才能被追踪
+		sdktrace.WithSampler(sdktrace.ParentBased(root, sdktrace.WithLocalParentNotSampled(root))),
+	)
+}
+
+// Install 将 tp 安装为全局 TracerProvider，并使用 W3C Trace Context 与 Baggage 传播上下文。
+func Install(tp trace.TracerProvider) {
+	otel.SetTracerProvider(&filteredProvider{
+		tp:  tp,
+		ids: sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample())),
+	})
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Tracer 返回本项目使用的 tracer，始终取当前的全局 provider。
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 以本项目的 tracer 创建 span，调用方负责 End。
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// filteredProvider 屏蔽 GoFrame 内置组件的 tracer；gctx.New 使用的匿名 tracer 只分配追踪 ID，不采集 span。
+type filteredProvider struct {
+	embedded.TracerProvider
+	tp  trace.TracerProvider
+	ids trace.TracerProvider
+}
+
+func (p *filteredProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	switch {
+	case name == "":
+		return p.ids.Tracer(name, opts...)
+	case strings.HasPrefix(name, goframePrefix):
+		return noop.NewTracerProvider().Tracer(name, opts...)
+	default:
+		return p.tp.Tracer(name, opts...)
+	}
+}
@@ -0,0 +1,102 @@
+package tracing_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/tracing"
+
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/test/gtest"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// install 安装写入内存的 provider，返回读取已结束 span 的函数；测试结束后恢复原 provider。
+func install(t *testing.T) func() tracetest.SpanStubs {
+	previous, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracing.NewProvider(exporter, config.TracingConfig{})
+	tracing.Install(tp)
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return func() tracetest.SpanStubs {
+		_ = tp.ForceFlush(context.Background())
+		spans := exporter.GetSpans()
+		exporter.Reset()
+		return spans
+	}
+}
+
+func TestInstall(t *testing.T) {
+	spans := install(t)
+	gtest.C(t, func(t *gtest.T) {
+		// GoFrame 内置组件的 span 不导出
+		_, gf := otel.Tracer("github.com/gogf/gf/v2/database/gdb").Start(context.Background(), "gdb")
+		gf.End()
+
+		// gctx.New 仍分配追踪 ID，本身不导出，其下的业务 span 沿用同一追踪 ID
+		ctx := gctx.New()
+		traceID := gctx.CtxId(ctx)
+		t.AssertNE(traceID, "")
+		_, span := tracing.Start(ctx, "unit.Service.Do", attribute.String("k", "v"))
+		span.End()
+
+		got := spans()
+		t.Assert(len(got), 1)
+		t.Assert(got[0].Name, "unit.Service.Do")
+		t.Assert(got[0].SpanContext.TraceID().String(), traceID)
+		t.Assert(got[0].Attributes, []attribute.KeyValue{attribute.String("k", "v")})
+		service, _ := got[0].Resource.Set().Value("service.name")
+		t.Assert(service.AsString(), "go-study2")
+	})
+}
+
+func TestQuerySpans(t *testing.T) {
+	spans := install(t)
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		_ = os.MkdirAll("testdata", 0o755)
+		db, err := database.Init(ctx, config.DatabaseConfig{
+			Type: "sqlite3",
+			Path: filepath.ToSlash(filepath.Join("testdata", fmt.Sprintf("tracing_%d.db", time.Now().UnixNano()))),
+		})
+		t.AssertNil(err)
+		spans()
+
+		// 没有父 span 的查询不生成 span
+		_, err = db.GetAll(ctx, "SELECT id FROM users WHERE username = ?", "secret-name")
+		t.AssertNil(err)
+		t.Assert(len(spans()), 0)
+
+		parentCtx, parent := tracing.Start(ctx, "parent")
+		_, err = db.GetAll(parentCtx, "SELECT id FROM users WHERE username = ?", "secret-name")
+		t.AssertNil(err)
+		_, err = db.Exec(parentCtx, "SELECT * FROM missing_table")
+		t.AssertNE(err, nil)
+		parent.End()
+
+		got := spans()
+		t.Assert(len(got), 3)
+		query, failed := got[0], got[1]
+		t.Assert(query.Name, "db.query")
+		t.Assert(query.Parent.SpanID(), parent.SpanContext().SpanID())
+		attrs := attribute.NewSet(query.Attributes...)
+		statement, _ := attrs.Value("db.statement")
+		t.Assert(statement.AsString(), "SELECT id FROM users WHERE username = ?")
+		system, _ := attrs.Value("db.system")
+		t.Assert(system.AsString(), "sqlite")
+		t.Assert(failed.Name, "db.exec")
+		t.Assert(failed.Status.Code.String(), "Error")
+	})
+}
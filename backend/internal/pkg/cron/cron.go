@@ -0,0 +1,178 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidExpression 表示 cron 表达式无法解析。
+var ErrInvalidExpression = errors.New("cron 表达式无效")
+
+// macros 为常用别名，与标准 cron 保持一致。
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"分钟", 0, 59},
+	{"小时", 0, 23},
+	{"日", 1, 31},
+	{"月", 1, 12},
+	{"星期", 0, 7},
+}
+
+// searchLimit 为查找下次触发时间的上限，覆盖闰年 2 月 29 日等最稀疏的表达式。
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// Schedule 为解析后的调度规则。
+type Schedule struct {
+	expr   string
+	every  time.Duration
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// domAny、dowAny 记录日与星期是否为 *；两者都受限时按标准 cron 取并集。
+	domAny bool
+	dowAny bool
+}
+
+// Parse 解析五段式 cron 表达式（分 时 日 月 周），支持 *、列表、范围、步长，
+// 以及 @daily 等别名和 @every <Go duration>。
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("%w: @every 需要不小于 1s 的时长", ErrInvalidExpression)
+		}
+		return &Schedule{expr: expr, every: d}, nil
+	}
+	spec := expr
+	if alias, ok := macros[expr]; ok {
+		spec = alias
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("%w: 需要 5 个字段", ErrInvalidExpression)
+	}
+	bits := make([]uint64, len(fields))
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+	// 星期 7 与 0 都表示周日
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return &Schedule{
+		expr:   expr,
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+// String 返回原始表达式。
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next 返回严格晚于 t 的下一次触发时间（按 t 所在时区计算），找不到时返回零值。
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+	for next.Before(limit) {
+		switch {
+		case s.month&(1<<uint(next.Month())) == 0:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case s.hour&(1<<uint(next.Hour())) == 0:
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case s.minute&(1<<uint(next.Minute())) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domHit := s.dom&(1<<uint(t.Day())) != 0
+	dowHit := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domHit && dowHit
+	}
+	return domHit || dowHit
+}
+
+// parseField 把单个字段解析为位图。
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%w: %s字段步长无效: %s", ErrInvalidExpression, f.name, item)
+			}
+			rangePart, step = item[:i], n
+		}
+		lo, hi := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			a, errA := strconv.Atoi(bounds[0])
+			b, errB := strconv.Atoi(bounds[1])
+			if errA != nil || errB != nil || a > b {
+				return 0, fmt.Errorf("%w: %s字段范围无效: %s", ErrInvalidExpression, f.name, item)
+			}
+			lo, hi = a, b
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("%w: %s字段无效: %s", ErrInvalidExpression, f.name, item)
+			}
+			lo = n
+			// 单个数值带步长时表示从该值到上限
+			if step == 1 {
+				hi = n
+			}
+		}
+		if lo < f.min || hi > f.max {
+			return 0, fmt.Errorf("%w: %s字段超出范围 %d-%d: %s", ErrInvalidExpression, f.name, f.min, f.max, item)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
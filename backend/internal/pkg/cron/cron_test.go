@@ -0,0 +1,57 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 10ms", "@every soon"} {
+			_, err := Parse(expr)
+			t.Assert(errors.Is(err, ErrInvalidExpression), true)
+		}
+	})
+}
+
+func TestSchedule_Next(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		base := time.Date(2024, 2, 28, 10, 17, 30, 0, time.UTC)
+		cases := []struct {
+			expr string
+			want time.Time
+		}{
+			{"* * * * *", time.Date(2024, 2, 28, 10, 18, 0, 0, time.UTC)},
+			{"*/15 * * * *", time.Date(2024, 2, 28, 10, 30, 0, 0, time.UTC)},
+			{"17 * * * *", time.Date(2024, 2, 28, 11, 17, 0, 0, time.UTC)},
+			{"0 9 * * *", time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC)},
+			{"@daily", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+			{"30 3 1 * *", time.Date(2024, 3, 1, 3, 30, 0, 0, time.UTC)},
+			{"0 0 * * 7", time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+			{"0 8 * * 1-5", time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC)},
+			{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+			// 日与星期同时受限时取并集：下一个 15 日或周五
+			{"0 0 15 * 5", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{"@every 90m", base.Add(90 * time.Minute)},
+		}
+		for _, c := range cases {
+			s, err := Parse(c.expr)
+			t.AssertNil(err)
+			t.Assert(s.Next(base), c.want)
+			t.Assert(s.String(), c.expr)
+		}
+	})
+}
+
+func TestSchedule_NextFollowsLocation(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		loc := time.FixedZone("UTC+8", 8*3600)
+		s, err := Parse("0 9 * * *")
+		t.AssertNil(err)
+		next := s.Next(time.Date(2024, 5, 1, 8, 59, 0, 0, loc))
+		t.Assert(next.Equal(time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)), true)
+	})
+}
@@ -0,0 +1,208 @@
+// Package epub 生成 EPUB 3 电子书。输出只取决于 Book 的内容，相同输入得到逐字节相同的文件，便于在版本之间比对。
+package epub
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MediaType 为 EPUB 文件的媒体类型。
+const MediaType = "application/epub+zip"
+
+// Book 描述一本电子书。
+type Book struct {
+	Identifier string    // 唯一标识，同一本书的不同版本应保持不变
+	Title      string    // 书名
+	Language   string    // 语言，如 zh-CN
+	Creator    string    // 作者，可为空
+	Modified   time.Time // 最后修改时间，写入 dcterms:modified
+	TOCTitle   string    // 目录页标题，为空时使用 "Contents"
+	CSS        string    // 全书共用的样式表
+	Sections   []Section // 按阅读顺序排列的章节
+}
+
+// Section 为目录中的一项，对应一个 XHTML 文档；Children 为下一级目录，阅读顺序排在本节之后。
+type Section struct {
+	ID       string // 文件名，需在全书唯一，只能包含字母、数字、下划线与连字符
+	Title    string
+	Body     string // XHTML 正文片段，需为格式良好的 XML
+	Children []Section
+}
+
+var sectionID = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// ErrInvalidBook 表示书籍信息不完整或章节标识无效。
+var ErrInvalidBook = errors.New("电子书内容无效")
+
+// Write 将电子书写入 w。
+func (b *Book) Write(w io.Writer) error {
+	sections, err := b.flatten()
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	// mimetype 必须是第一个文件且不压缩
+	if err := writeFile(zw, "mimetype", MediaType, zip.Store); err != nil {
+		return err
+	}
+	files := []struct{ name, content string }{
+		{"META-INF/container.xml", containerXML},
+		{"OEBPS/content.opf", b.packageDocument(sections)},
+		{"OEBPS/nav.xhtml", b.navDocument()},
+		{"OEBPS/style.css", b.CSS},
+	}
+	for _, s := range sections {
+		files = append(files, struct{ name, content string }{"OEBPS/" + sectionPath(s.ID), b.xhtml(s.Title, s.Body, "../style.css")})
+	}
+	for _, f := range files {
+		if err := writeFile(zw, f.name, f.content, zip.Deflate); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// flatten 按阅读顺序展开章节并校验标识。
+func (b *Book) flatten() ([]Section, error) {
+	if b.Identifier == "" || b.Title == "" || b.Language == "" {
+		return nil, fmt.Errorf("%w: 缺少标识、书名或语言", ErrInvalidBook)
+	}
+	var out []Section
+	seen := make(map[string]bool)
+	var walk func([]Section) error
+	walk = func(list []Section) error {
+		for _, s := range list {
+			if !sectionID.MatchString(s.ID) {
+				return fmt.Errorf("%w: 章节标识 %q 无效", ErrInvalidBook, s.ID)
+			}
+			if seen[s.ID] {
+				return fmt.Errorf("%w: 章节标识 %q 重复", ErrInvalidBook, s.ID)
+			}
+			seen[s.ID] = true
+			out = append(out, s)
+			if err := walk(s.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(b.Sections); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: 没有章节", ErrInvalidBook)
+	}
+	return out, nil
+}
+
+// writeFile 写入一个 zip 条目；不设置修改时间，保证输出可重复。
+func writeFile(zw *zip.Writer, name, content string, method uint16) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+func sectionPath(id string) string {
+	return "text/" + id + ".xhtml"
+}
+
+// SectionHref 返回章节正文中链接到另一章节时使用的相对地址。
+func SectionHref(id string) string {
+	return id + ".xhtml"
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+func (b *Book) packageDocument(sections []Section) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&sb, `<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="bookid" xml:lang="%s">`+"\n", attr(b.Language))
+	sb.WriteString(`  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	fmt.Fprintf(&sb, "    <dc:identifier id=\"bookid\">%s</dc:identifier>\n", text(b.Identifier))
+	fmt.Fprintf(&sb, "    <dc:title>%s</dc:title>\n", text(b.Title))
+	fmt.Fprintf(&sb, "    <dc:language>%s</dc:language>\n", text(b.Language))
+	if b.Creator != "" {
+		fmt.Fprintf(&sb, "    <dc:creator>%s</dc:creator>\n", text(b.Creator))
+	}
+	fmt.Fprintf(&sb, "    <meta property=\"dcterms:modified\">%s</meta>\n", b.Modified.UTC().Format("2006-01-02T15:04:05Z"))
+	sb.WriteString("  </metadata>\n  <manifest>\n")
+	sb.WriteString(`    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` + "\n")
+	sb.WriteString(`    <item id="css" href="style.css" media-type="text/css"/>` + "\n")
+	for _, s := range sections {
+		fmt.Fprintf(&sb, "    <item id=\"s-%s\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", s.ID, sectionPath(s.ID))
+	}
+	sb.WriteString("  </manifest>\n  <spine>\n")
+	sb.WriteString(`    <itemref idref="nav"/>` + "\n")
+	for _, s := range sections {
+		fmt.Fprintf(&sb, "    <itemref idref=\"s-%s\"/>\n", s.ID)
+	}
+	sb.WriteString("  </spine>\n</package>\n")
+	return sb.String()
+}
+
+func (b *Book) navDocument() string {
+	title := b.TOCTitle
+	if title == "" {
+		title = "Contents"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<nav epub:type=\"toc\" id=\"toc\">\n<h1>%s</h1>\n", text(title))
+	writeNavList(&sb, b.Sections)
+	sb.WriteString("</nav>")
+	return b.xhtml(title, sb.String(), "style.css")
+}
+
+func writeNavList(sb *strings.Builder, sections []Section) {
+	sb.WriteString("<ol>\n")
+	for _, s := range sections {
+		fmt.Fprintf(sb, "<li><a href=\"%s\">%s</a>", sectionPath(s.ID), text(s.Title))
+		if len(s.Children) > 0 {
+			sb.WriteString("\n")
+			writeNavList(sb, s.Children)
+		}
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString("</ol>\n")
+}
+
+func (b *Book) xhtml(title, body, css string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="` + attr(b.Language) + `" lang="` + attr(b.Language) + `">
+<head>
+<meta charset="utf-8"/>
+<title>` + text(title) + `</title>
+<link rel="stylesheet" type="text/css" href="` + css + `"/>
+</head>
+<body>
+` + body + `
+</body>
+</html>
+`
+}
+
+var xmlText = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func text(s string) string {
+	return xmlText.Replace(s)
+}
+
+func attr(s string) string {
+	return html.EscapeString(s)
+}
@@ -0,0 +1,113 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func sampleBook() *Book {
+	return &Book{
+		Identifier: "urn:test:book",
+		Title:      "类型 & 变量",
+		Language:   "zh-CN",
+		Modified:   time.Date(2024, 5, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600)),
+		TOCTitle:   "目录",
+		CSS:        "body { margin: 0; }",
+		Sections: []Section{
+			{ID: "types", Title: "Types", Body: "<h1>Types</h1>", Children: []Section{
+				{ID: "types-slice", Title: "切片 <slice>", Body: "<h2>切片</h2>\n<pre>\nx := &lt;-ch\n</pre>"},
+			}},
+			{ID: "appendix", Title: "附录", Body: "<p>答案</p>"},
+		},
+	}
+}
+
+func readZip(t *gtest.T, data []byte) (*zip.Reader, map[string]string) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	t.AssertNil(err)
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		t.AssertNil(err)
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	return zr, files
+}
+
+func TestBook_Write(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var buf bytes.Buffer
+		t.AssertNil(sampleBook().Write(&buf))
+
+		zr, files := readZip(t, buf.Bytes())
+		t.Assert(zr.File[0].Name, "mimetype")
+		t.Assert(zr.File[0].Method, zip.Store)
+		t.Assert(files["mimetype"], MediaType)
+		t.AssertIN(`full-path="OEBPS/content.opf"`, files["META-INF/container.xml"])
+
+		opf := files["OEBPS/content.opf"]
+		t.AssertIN(`<meta property="dcterms:modified">2024-05-01T00:00:00Z</meta>`, opf)
+		t.AssertIN(`<dc:title>类型 &amp; 变量</dc:title>`, opf)
+		t.AssertIN(`properties="nav"`, opf)
+		// 子章节紧跟在父章节之后
+		t.Assert(strings.Index(opf, `idref="s-types"`) < strings.Index(opf, `idref="s-types-slice"`), true)
+		t.Assert(strings.Index(opf, `idref="s-types-slice"`) < strings.Index(opf, `idref="s-appendix"`), true)
+
+		nav := files["OEBPS/nav.xhtml"]
+		t.AssertIN(`epub:type="toc"`, nav)
+		t.AssertIN(`<a href="text/types-slice.xhtml">切片 &lt;slice&gt;</a>`, nav)
+		t.Assert(SectionHref("types-slice"), "types-slice.xhtml")
+		t.AssertIN(`<link rel="stylesheet" type="text/css" href="../style.css"/>`, files["OEBPS/text/types-slice.xhtml"])
+		t.Assert(files["OEBPS/style.css"], "body { margin: 0; }")
+
+		// 所有 XML 文档必须格式良好
+		for name, content := range files {
+			if !strings.HasSuffix(name, ".xhtml") && !strings.HasSuffix(name, ".xml") && !strings.HasSuffix(name, ".opf") {
+				continue
+			}
+			dec := xml.NewDecoder(strings.NewReader(content))
+			dec.Strict = true
+			dec.Entity = xml.HTMLEntity
+			for {
+				_, err := dec.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s 不是格式良好的 XML: %v", name, err)
+				}
+			}
+		}
+
+		// 相同输入逐字节相同
+		var again bytes.Buffer
+		t.AssertNil(sampleBook().Write(&again))
+		t.Assert(bytes.Equal(buf.Bytes(), again.Bytes()), true)
+	})
+}
+
+func TestBook_WriteInvalid(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		for _, mutate := range []func(*Book){
+			func(b *Book) { b.Identifier = "" },
+			func(b *Book) { b.Sections = nil },
+			func(b *Book) { b.Sections[1].ID = "types-slice" },
+			func(b *Book) { b.Sections[1].ID = "../escape" },
+		} {
+			b := sampleBook()
+			mutate(b)
+			err := b.Write(io.Discard)
+			t.Assert(errors.Is(err, ErrInvalidBook), true)
+		}
+	})
+}
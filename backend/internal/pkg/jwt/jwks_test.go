@@ -0,0 +1,84 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestPublicJWKS(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		t.AssertNil(err)
+		t.Setenv("JWKS_TEST_RSA", string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})))
+
+		edPub, _, err := ed25519.GenerateKey(rand.Reader)
+		t.AssertNil(err)
+		der, err := x509.MarshalPKIXPublicKey(edPub)
+		t.AssertNil(err)
+		t.Setenv("JWKS_TEST_ED_PUB", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+		t.Setenv("JWKS_TEST_HMAC", "hmac-secret-0123456789")
+
+		err = Configure(Options{
+			Keys: []KeyOptions{
+				{ID: "hs", Algorithm: AlgHS256, Env: "JWKS_TEST_HMAC"},
+				{ID: "rs", Algorithm: AlgRS256, Env: "JWKS_TEST_RSA"},
+				{ID: "ed-retired", Algorithm: AlgEdDSA, Env: "JWKS_TEST_ED_PUB", RetireAt: time.Now().Add(-time.Minute)},
+			},
+		})
+		t.AssertNil(err)
+
+		set := PublicJWKS()
+		t.Assert(len(set.Keys), 1)
+		t.Assert(set.Keys[0].Kid, "rs")
+		t.Assert(set.Keys[0].Kty, "RSA")
+		t.Assert(set.Keys[0].E, "AQAB")
+		t.AssertNE(set.Keys[0].N, "")
+	})
+}
+
+func TestConfigure_PublicOnlyKeyCannotSign(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		edPub, _, err := ed25519.GenerateKey(rand.Reader)
+		t.AssertNil(err)
+		der, err := x509.MarshalPKIXPublicKey(edPub)
+		t.AssertNil(err)
+		t.Setenv("JWKS_TEST_ED_ONLY", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+
+		err = Configure(Options{
+			ActiveKeyID: "ed",
+			Keys:        []KeyOptions{{ID: "ed", Algorithm: AlgEdDSA, Env: "JWKS_TEST_ED_ONLY"}},
+		})
+		t.AssertNE(err, nil)
+	})
+}
+
+func TestJWK_PublicKeyRoundTrip(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		t.AssertNil(err)
+		edPub, _, err := ed25519.GenerateKey(rand.Reader)
+		t.AssertNil(err)
+
+		rsaJWK, ok := toJWK(&Key{ID: "rs", Algorithm: AlgRS256, verifyKey: &rsaKey.PublicKey})
+		t.Assert(ok, true)
+		pub, err := rsaJWK.PublicKey()
+		t.AssertNil(err)
+		t.Assert(pub.(*rsa.PublicKey).Equal(&rsaKey.PublicKey), true)
+
+		edJWK, ok := toJWK(&Key{ID: "ed", Algorithm: AlgEdDSA, verifyKey: edPub})
+		t.Assert(ok, true)
+		pub, err = edJWK.PublicKey()
+		t.AssertNil(err)
+		t.Assert(pub.(ed25519.PublicKey).Equal(edPub), true)
+
+		_, err = JWK{Kty: "EC", Crv: "P-256"}.PublicKey()
+		t.AssertNE(err, nil)
+	})
+}
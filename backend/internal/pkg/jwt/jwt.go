@@ -2,6 +2,8 @@ package jwt
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	jwtlib "github.com/golang-jwt/jwt/v5"
@@ -15,28 +17,45 @@ type Claims struct {
 
 // Options 配置签名密钥与过期时间。
 type Options struct {
-	Secret             string
+	// Secret 旧版单一 HS256 密钥，仅在未配置 Keys 时使用，kid 固定为 LegacyKeyID。
+	Secret string
+	// Keys 密钥环配置，支持多把 HS256/RS256/EdDSA 密钥并存。
+	Keys []KeyOptions
+	// ActiveKeyID 当前用于签发的 kid；为空时使用 Keys 中最后一把可签发且未退役的密钥。
+	ActiveKeyID        string
 	Issuer             string
+	Audience           string
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
 }
 
+// LegacyKeyID 为通过 Options.Secret 配置的密钥分配的 kid，缺少 kid 的旧令牌也按该 kid 验签。
+const LegacyKeyID = "default"
+
 var (
-	secretKey       []byte
+	mu              sync.RWMutex
+	keyring         *Keyring
 	issuer          = "go-study2"
+	audience        string
 	accessTokenTTL  = 7 * 24 * time.Hour
 	refreshTokenTTL = 7 * 24 * time.Hour
+	nowFunc         = time.Now
 )
 
 // Configure 配置签名参数。
 func Configure(opts Options) error {
-	if len(opts.Secret) < 16 {
-		return errors.New("JWT 密钥长度至少 16 字符")
+	kr, err := buildKeyring(opts)
+	if err != nil {
+		return err
 	}
-	secretKey = []byte(opts.Secret)
+
+	mu.Lock()
+	defer mu.Unlock()
+	keyring = kr
 	if opts.Issuer != "" {
 		issuer = opts.Issuer
 	}
+	audience = opts.Audience
 	if opts.AccessTokenExpiry > 0 {
 		accessTokenTTL = opts.AccessTokenExpiry
 	}
@@ -46,6 +65,28 @@ func Configure(opts Options) error {
 	return nil
 }
 
+// Rotate 将签发密钥切换为已加载的 kid，旧密钥在退役前仍可验签。
+func Rotate(kid string) error {
+	kr := currentKeyring()
+	if kr == nil {
+		return errors.New("JWT 尚未配置密钥")
+	}
+	return kr.SetActive(kid, nowFunc())
+}
+
+// AddKey 向当前密钥环追加密钥，可在不重启的情况下预先发布新公钥。
+func AddKey(opts KeyOptions) error {
+	kr := currentKeyring()
+	if kr == nil {
+		return errors.New("JWT 尚未配置密钥")
+	}
+	key, err := LoadKey(opts)
+	if err != nil {
+		return err
+	}
+	return kr.Add(key)
+}
+
 // GenerateAccessToken 生成访问令牌。
 func GenerateAccessToken(userID int64) (string, error) {
 	return signToken(userID, accessTokenTTL)
@@ -56,15 +97,45 @@ func GenerateRefreshToken(userID int64) (string, error) {
 	return signToken(userID, refreshTokenTTL)
 }
 
-// VerifyToken 验证令牌并返回声明。
+// VerifyToken 验证令牌并返回声明，算法、kid、签发方与受众均严格校验。
 func VerifyToken(tokenString string) (*Claims, error) {
-	if len(secretKey) == 0 {
+	kr := currentKeyring()
+	if kr == nil {
 		return nil, errors.New("JWT 尚未配置密钥")
 	}
 
+	mu.RLock()
+	expectedIssuer, expectedAudience := issuer, audience
+	mu.RUnlock()
+
+	now := nowFunc()
+	parserOpts := []jwtlib.ParserOption{
+		jwtlib.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}),
+		jwtlib.WithIssuer(expectedIssuer),
+		jwtlib.WithExpirationRequired(),
+		jwtlib.WithIssuedAt(),
+		jwtlib.WithTimeFunc(func() time.Time { return now }),
+	}
+	if expectedAudience != "" {
+		parserOpts = append(parserOpts, jwtlib.WithAudience(expectedAudience))
+	}
+
 	token, err := jwtlib.ParseWithClaims(tokenString, &Claims{}, func(token *jwtlib.Token) (interface{}, error) {
-		return secretKey, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			// 引入密钥环之前签发的令牌没有 kid，按旧版单密钥验签，直至自然过期或该密钥退役。
+			kid = LegacyKeyID
+		}
+		key, ok := kr.Lookup(kid, now)
+		if !ok {
+			return nil, fmt.Errorf("未知或已退役的 kid: %s", kid)
+		}
+		// 按 kid 绑定算法，避免 HS256/RS256 混淆攻击。
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("令牌算法 %s 与密钥 %s 不匹配", token.Method.Alg(), kid)
+		}
+		return key.verifyKey, nil
+	}, parserOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -77,20 +148,79 @@ func VerifyToken(tokenString string) (*Claims, error) {
 }
 
 func signToken(userID int64, ttl time.Duration) (string, error) {
-	if len(secretKey) == 0 {
+	kr := currentKeyring()
+	if kr == nil {
 		return "", errors.New("JWT 尚未配置密钥")
 	}
-	now := time.Now()
+	key, err := kr.Active()
+	if err != nil {
+		return "", err
+	}
+	now := nowFunc()
+	if key.Retired(now) {
+		return "", fmt.Errorf("JWT 签发密钥 %s 已退役，请先切换签发密钥", key.ID)
+	}
+
+	mu.RLock()
+	tokenIssuer, tokenAudience := issuer, audience
+	mu.RUnlock()
+
 	claims := Claims{
 		UserID: userID,
 		RegisteredClaims: jwtlib.RegisteredClaims{
 			ExpiresAt: jwtlib.NewNumericDate(now.Add(ttl)),
 			IssuedAt:  jwtlib.NewNumericDate(now),
-			Issuer:    issuer,
+			Issuer:    tokenIssuer,
 		},
 	}
-	token := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims)
-	return token.SignedString(secretKey)
+	if tokenAudience != "" {
+		claims.Audience = jwtlib.ClaimStrings{tokenAudience}
+	}
+	token := jwtlib.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
+}
+
+func buildKeyring(opts Options) (*Keyring, error) {
+	kr := NewKeyring()
+	if len(opts.Keys) == 0 {
+		key, err := ParseKey(LegacyKeyID, AlgHS256, []byte(opts.Secret), time.Time{})
+		if err != nil {
+			return nil, errors.New("JWT 密钥长度至少 16 字符")
+		}
+		if err := kr.Add(key); err != nil {
+			return nil, err
+		}
+		return kr, kr.SetActive(LegacyKeyID, nowFunc())
+	}
+
+	now := nowFunc()
+	activeID := opts.ActiveKeyID
+	for _, keyOpts := range opts.Keys {
+		key, err := LoadKey(keyOpts)
+		if err != nil {
+			return nil, err
+		}
+		if err := kr.Add(key); err != nil {
+			return nil, err
+		}
+		if opts.ActiveKeyID == "" && key.CanSign() && !key.Retired(now) {
+			activeID = key.ID
+		}
+	}
+	if activeID == "" {
+		return nil, errors.New("JWT 密钥环中没有可用于签发的私钥")
+	}
+	if err := kr.SetActive(activeID, now); err != nil {
+		return nil, err
+	}
+	return kr, nil
+}
+
+func currentKeyring() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return keyring
 }
 
 // AccessTokenTTL 返回当前的访问令牌有效期。
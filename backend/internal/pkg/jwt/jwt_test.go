@@ -1,10 +1,18 @@
 package jwt
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/gogf/gf/v2/test/gtest"
+	jwtlib "github.com/golang-jwt/jwt/v5"
 )
 
 func TestGenerateAndVerify(t *testing.T) {
@@ -27,3 +35,226 @@ func TestGenerateAndVerify(t *testing.T) {
 		t.Assert(claims.Issuer, "test-issuer")
 	})
 }
+
+func TestVerifyToken_RejectsWrongIssuerAndAudience(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		err := Configure(Options{Secret: "0123456789abcdef", Issuer: "issuer-a", Audience: "svc-a"})
+		t.AssertNil(err)
+		token, err := GenerateAccessToken(7)
+		t.AssertNil(err)
+
+		claims, err := VerifyToken(token)
+		t.AssertNil(err)
+		t.Assert(claims.Audience, jwtlib.ClaimStrings{"svc-a"})
+
+		t.AssertNil(Configure(Options{Secret: "0123456789abcdef", Issuer: "issuer-b", Audience: "svc-a"}))
+		_, err = VerifyToken(token)
+		t.AssertNE(err, nil)
+
+		t.AssertNil(Configure(Options{Secret: "0123456789abcdef", Issuer: "issuer-a", Audience: "svc-b"}))
+		_, err = VerifyToken(token)
+		t.AssertNE(err, nil)
+	})
+}
+
+func TestVerifyToken_RejectsAlgorithmConfusion(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		t.AssertNil(err)
+		pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		t.AssertNil(err)
+		pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+		privPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+		t.Setenv("JWT_TEST_RSA", string(privPEM))
+		err = Configure(Options{
+			Issuer: "go-study2",
+			Keys:   []KeyOptions{{ID: "rsa-1", Algorithm: AlgRS256, Env: "JWT_TEST_RSA"}},
+		})
+		t.AssertNil(err)
+
+		// 以公钥作为 HMAC 密钥伪造令牌，必须被拒绝
+		forged := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, Claims{
+			UserID: 1,
+			RegisteredClaims: jwtlib.RegisteredClaims{
+				Issuer:    "go-study2",
+				IssuedAt:  jwtlib.NewNumericDate(time.Now()),
+				ExpiresAt: jwtlib.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		})
+		forged.Header["kid"] = "rsa-1"
+		signed, err := forged.SignedString(pubPEM)
+		t.AssertNil(err)
+		_, err = VerifyToken(signed)
+		t.AssertNE(err, nil)
+
+		// alg=none 同样拒绝
+		none := jwtlib.NewWithClaims(jwtlib.SigningMethodNone, Claims{UserID: 1})
+		none.Header["kid"] = "rsa-1"
+		unsigned, err := none.SignedString(jwtlib.UnsafeAllowNoneSignatureType)
+		t.AssertNil(err)
+		_, err = VerifyToken(unsigned)
+		t.AssertNE(err, nil)
+
+		// 正常签发的 RS256 令牌可通过验证
+		token, err := GenerateAccessToken(9)
+		t.AssertNil(err)
+		claims, err := VerifyToken(token)
+		t.AssertNil(err)
+		t.Assert(claims.UserID, int64(9))
+	})
+}
+
+func TestRotate_OldKeyVerifiesUntilRetired(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		dir := t.TempDir()
+		oldFile := filepath.Join(dir, "old.key")
+		t.AssertNil(os.WriteFile(oldFile, []byte("old-secret-0123456789"), 0o600))
+
+		_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+		t.AssertNil(err)
+		der, err := x509.MarshalPKCS8PrivateKey(edPriv)
+		t.AssertNil(err)
+		newFile := filepath.Join(dir, "new.pem")
+		t.AssertNil(os.WriteFile(newFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+		retireAt := time.Now().Add(10 * time.Minute)
+		err = Configure(Options{
+			Issuer:            "go-study2",
+			AccessTokenExpiry: time.Hour,
+			ActiveKeyID:       "old",
+			Keys: []KeyOptions{
+				{ID: "old", Algorithm: AlgHS256, File: oldFile, RetireAt: retireAt},
+				{ID: "new", Algorithm: AlgEdDSA, File: newFile},
+			},
+		})
+		t.AssertNil(err)
+
+		oldToken, err := GenerateAccessToken(1)
+		t.AssertNil(err)
+
+		t.AssertNil(Rotate("new"))
+		newToken, err := GenerateAccessToken(2)
+		t.AssertNil(err)
+
+		parsed, _, err := jwtlib.NewParser().ParseUnverified(newToken, &Claims{})
+		t.AssertNil(err)
+		t.Assert(parsed.Header["kid"], "new")
+		t.Assert(parsed.Header["alg"], AlgEdDSA)
+
+		_, err = VerifyToken(oldToken)
+		t.AssertNil(err)
+		_, err = VerifyToken(newToken)
+		t.AssertNil(err)
+
+		// 旧密钥退役后，其签发的令牌不再通过验证
+		nowFunc = func() time.Time { return retireAt.Add(time.Minute) }
+		defer func() { nowFunc = time.Now }()
+		_, err = VerifyToken(oldToken)
+		t.AssertNE(err, nil)
+		_, err = VerifyToken(newToken)
+		t.AssertNil(err)
+	})
+}
+
+func TestVerifyToken_MissingKidUsesLegacyKey(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		t.AssertNil(Configure(Options{Secret: "0123456789abcdef", Issuer: "go-study2"}))
+
+		claims := Claims{
+			UserID: 1,
+			RegisteredClaims: jwtlib.RegisteredClaims{
+				Issuer:    "go-study2",
+				IssuedAt:  jwtlib.NewNumericDate(time.Now()),
+				ExpiresAt: jwtlib.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+		// 引入 kid 之前签发的令牌按 default 密钥验签，已登录会话不受影响
+		noKid, err := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims).SignedString([]byte("0123456789abcdef"))
+		t.AssertNil(err)
+		parsed, err := VerifyToken(noKid)
+		t.AssertNil(err)
+		t.Assert(parsed.UserID, int64(1))
+
+		forged, err := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims).SignedString([]byte("fedcba9876543210"))
+		t.AssertNil(err)
+		_, err = VerifyToken(forged)
+		t.AssertNE(err, nil)
+
+		unknown := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims)
+		unknown.Header["kid"] = "missing"
+		signed, err := unknown.SignedString([]byte("0123456789abcdef"))
+		t.AssertNil(err)
+		_, err = VerifyToken(signed)
+		t.AssertNE(err, nil)
+
+		// 密钥环中没有 default 密钥时，缺少 kid 的令牌无从验签
+		dir := t.TempDir()
+		keyFile := filepath.Join(dir, "new.key")
+		t.AssertNil(os.WriteFile(keyFile, []byte("new-secret-0123456789"), 0o600))
+		t.AssertNil(Configure(Options{
+			Issuer: "go-study2",
+			Keys:   []KeyOptions{{ID: "new", Algorithm: AlgHS256, File: keyFile}},
+		}))
+		noKid, err = jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims).SignedString([]byte("new-secret-0123456789"))
+		t.AssertNil(err)
+		_, err = VerifyToken(noKid)
+		t.AssertNE(err, nil)
+	})
+}
+
+func TestRetiredKeyCannotSign(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		dir := t.TempDir()
+		oldFile := filepath.Join(dir, "old.key")
+		t.AssertNil(os.WriteFile(oldFile, []byte("old-secret-0123456789"), 0o600))
+		newFile := filepath.Join(dir, "new.key")
+		t.AssertNil(os.WriteFile(newFile, []byte("new-secret-0123456789"), 0o600))
+
+		past := time.Now().Add(-time.Minute)
+		retireAt := time.Now().Add(10 * time.Minute)
+
+		// 已过退役时间的密钥不能被配置为签发密钥
+		err := Configure(Options{
+			Issuer:      "go-study2",
+			ActiveKeyID: "old",
+			Keys:        []KeyOptions{{ID: "old", Algorithm: AlgHS256, File: oldFile, RetireAt: past}},
+		})
+		t.AssertNE(err, nil)
+
+		// 未指定 ActiveKeyID 时跳过已退役的密钥
+		err = Configure(Options{
+			Issuer: "go-study2",
+			Keys: []KeyOptions{
+				{ID: "new", Algorithm: AlgHS256, File: newFile},
+				{ID: "old", Algorithm: AlgHS256, File: oldFile, RetireAt: past},
+			},
+		})
+		t.AssertNil(err)
+		token, err := GenerateAccessToken(1)
+		t.AssertNil(err)
+		parsed, _, err := jwtlib.NewParser().ParseUnverified(token, &Claims{})
+		t.AssertNil(err)
+		t.Assert(parsed.Header["kid"], "new")
+
+		err = Configure(Options{
+			Issuer:      "go-study2",
+			ActiveKeyID: "old",
+			Keys: []KeyOptions{
+				{ID: "old", Algorithm: AlgHS256, File: oldFile, RetireAt: retireAt},
+				{ID: "new", Algorithm: AlgHS256, File: newFile},
+			},
+		})
+		t.AssertNil(err)
+
+		// 活动密钥到期后拒绝签发，也不能再切换回来
+		nowFunc = func() time.Time { return retireAt.Add(time.Minute) }
+		defer func() { nowFunc = time.Now }()
+		_, err = GenerateAccessToken(1)
+		t.AssertNE(err, nil)
+		t.AssertNil(Rotate("new"))
+		_, err = GenerateAccessToken(1)
+		t.AssertNil(err)
+		t.AssertNE(Rotate("old"), nil)
+	})
+}
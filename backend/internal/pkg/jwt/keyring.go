@@ -0,0 +1,253 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法。
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// KeyOptions 描述一把签名密钥的来源，密钥材料只允许来自文件或环境变量。
+type KeyOptions struct {
+	// ID 写入令牌头部的 kid，需在密钥环内唯一。
+	ID string
+	// Algorithm 取值 HS256 / RS256 / EdDSA。
+	Algorithm string
+	// File 密钥文件路径：HS256 为原始密钥，RS256/EdDSA 为 PEM 私钥或公钥。
+	File string
+	// Env 存放密钥材料的环境变量名，与 File 二选一。
+	Env string
+	// RetireAt 退役时间，到期后该密钥不再用于验签；零值表示永不退役。
+	RetireAt time.Time
+}
+
+// Key 表示密钥环中已加载的一把密钥。
+type Key struct {
+	ID        string
+	Algorithm string
+	RetireAt  time.Time
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign 判断该密钥是否持有私钥（或对称密钥），可用于签发令牌。
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// Retired 判断密钥在给定时间是否已退役。
+func (k *Key) Retired(now time.Time) bool {
+	return !k.RetireAt.IsZero() && !now.Before(k.RetireAt)
+}
+
+func (k *Key) method() jwtlib.SigningMethod {
+	return jwtlib.GetSigningMethod(k.Algorithm)
+}
+
+// Keyring 保存多把密钥：一把用于签发，其余在退役前继续用于验签。
+type Keyring struct {
+	mu       sync.RWMutex
+	keys     map[string]*Key
+	order    []string
+	activeID string
+}
+
+// NewKeyring 创建空密钥环。
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]*Key)}
+}
+
+// Add 加入一把已解析的密钥，kid 重复时返回错误。
+func (kr *Keyring) Add(key *Key) error {
+	if key == nil || key.ID == "" {
+		return errors.New("JWT 密钥缺少 kid")
+	}
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if _, exists := kr.keys[key.ID]; exists {
+		return fmt.Errorf("JWT 密钥 kid 重复: %s", key.ID)
+	}
+	kr.keys[key.ID] = key
+	kr.order = append(kr.order, key.ID)
+	return nil
+}
+
+// SetActive 切换签发密钥，新密钥立即生效，旧密钥保留用于验签；已退役的密钥不能设为签发密钥。
+func (kr *Keyring) SetActive(kid string, now time.Time) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	key, ok := kr.keys[kid]
+	if !ok {
+		return fmt.Errorf("JWT 签发密钥不存在: %s", kid)
+	}
+	if !key.CanSign() {
+		return fmt.Errorf("JWT 密钥 %s 仅包含公钥，无法用于签发", kid)
+	}
+	if key.Retired(now) {
+		return fmt.Errorf("JWT 密钥 %s 已于 %s 退役，无法用于签发", kid, key.RetireAt.Format(time.RFC3339))
+	}
+	kr.activeID = kid
+	return nil
+}
+
+// Active 返回当前签发密钥。
+func (kr *Keyring) Active() (*Key, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	key, ok := kr.keys[kr.activeID]
+	if !ok {
+		return nil, errors.New("JWT 尚未配置签发密钥")
+	}
+	return key, nil
+}
+
+// Lookup 根据 kid 返回可用于验签的密钥，已退役的密钥视为不存在。
+func (kr *Keyring) Lookup(kid string, now time.Time) (*Key, bool) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	key, ok := kr.keys[kid]
+	if !ok || key.Retired(now) {
+		return nil, false
+	}
+	return key, true
+}
+
+// Keys 按加入顺序返回全部密钥。
+func (kr *Keyring) Keys() []*Key {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	list := make([]*Key, 0, len(kr.order))
+	for _, kid := range kr.order {
+		list = append(list, kr.keys[kid])
+	}
+	return list
+}
+
+// LoadKey 按配置从文件或环境变量读取密钥材料并解析。
+func LoadKey(opts KeyOptions) (*Key, error) {
+	if strings.TrimSpace(opts.ID) == "" {
+		return nil, errors.New("JWT 密钥缺少 kid")
+	}
+	material, err := readKeyMaterial(opts)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKey(opts.ID, opts.Algorithm, material, opts.RetireAt)
+}
+
+// ParseKey 将原始密钥材料解析为指定算法的密钥。
+func ParseKey(kid, alg string, material []byte, retireAt time.Time) (*Key, error) {
+	key := &Key{ID: kid, Algorithm: alg, RetireAt: retireAt}
+	switch alg {
+	case AlgHS256:
+		secret := []byte(strings.TrimSpace(string(material)))
+		if len(secret) < 16 {
+			return nil, fmt.Errorf("JWT 密钥 %s 长度至少 16 字符", kid)
+		}
+		key.signKey = secret
+		key.verifyKey = secret
+	case AlgRS256:
+		priv, pub, err := parsePEMKey(material)
+		if err != nil {
+			return nil, fmt.Errorf("解析 JWT 密钥 %s 失败: %w", kid, err)
+		}
+		if priv != nil {
+			rsaKey, ok := priv.(*rsa.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("JWT 密钥 %s 不是 RSA 私钥", kid)
+			}
+			key.signKey = rsaKey
+			key.verifyKey = &rsaKey.PublicKey
+			break
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("JWT 密钥 %s 不是 RSA 公钥", kid)
+		}
+		key.verifyKey = rsaPub
+	case AlgEdDSA:
+		priv, pub, err := parsePEMKey(material)
+		if err != nil {
+			return nil, fmt.Errorf("解析 JWT 密钥 %s 失败: %w", kid, err)
+		}
+		if priv != nil {
+			edKey, ok := priv.(ed25519.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("JWT 密钥 %s 不是 Ed25519 私钥", kid)
+			}
+			key.signKey = edKey
+			key.verifyKey = edKey.Public()
+			break
+		}
+		edPub, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("JWT 密钥 %s 不是 Ed25519 公钥", kid)
+		}
+		key.verifyKey = edPub
+	default:
+		return nil, fmt.Errorf("不支持的 JWT 签名算法: %s", alg)
+	}
+	return key, nil
+}
+
+func readKeyMaterial(opts KeyOptions) ([]byte, error) {
+	switch {
+	case opts.File != "" && opts.Env != "":
+		return nil, fmt.Errorf("JWT 密钥 %s 只能配置 file 或 env 之一", opts.ID)
+	case opts.File != "":
+		data, err := os.ReadFile(opts.File)
+		if err != nil {
+			return nil, fmt.Errorf("读取 JWT 密钥文件失败 %s: %w", opts.File, err)
+		}
+		return data, nil
+	case opts.Env != "":
+		value, ok := os.LookupEnv(opts.Env)
+		if !ok || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("环境变量 %s 未设置 JWT 密钥", opts.Env)
+		}
+		return []byte(value), nil
+	default:
+		return nil, fmt.Errorf("JWT 密钥 %s 未配置 file 或 env", opts.ID)
+	}
+}
+
+// parsePEMKey 解析 PEM 编码的私钥（PKCS#1/PKCS#8）或公钥（PKIX）。
+func parsePEMKey(material []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+	block, _ := pem.Decode(material)
+	if block == nil {
+		return nil, nil, errors.New("不是有效的 PEM 数据")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		return priv, nil, err
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		return priv, nil, err
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		return nil, pub, err
+	case "RSA PUBLIC KEY":
+		pub, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		return nil, pub, err
+	default:
+		return nil, nil, fmt.Errorf("不支持的 PEM 类型: %s", block.Type)
+	}
+}
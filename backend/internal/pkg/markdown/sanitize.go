@@ -0,0 +1,206 @@
+// Package markdown 在服务端清理用户提交的 Markdown 源文，使其可以交给任意渲染器安全展示。
+//
+// 清理不渲染 HTML，只做三件事：转义代码之外的原始 HTML 标签、把不安全协议的链接与图片地址替换为 "#"、
+// 去除除换行与制表符之外的控制字符。围栏代码块与行内代码保持原样。
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// safeSchemes 为允许出现在链接与图片中的协议，相对地址不受限制。
+var safeSchemes = map[string]struct{}{
+	"http":   {},
+	"https":  {},
+	"mailto": {},
+}
+
+var (
+	fencePattern    = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	refDefPattern   = regexp.MustCompile(`^( {0,3}\[[^\]]+\]:[ \t]*)(\S+)(.*)$`)
+	refLabelPattern = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:[ \t]*$`)
+	leadDestPattern = regexp.MustCompile(`^([ \t]*)(\S+)(.*)$`)
+	tagStartPattern = regexp.MustCompile(`^<(/?[A-Za-z][A-Za-z0-9-]*|!|\?)`)
+	autolinkPattern = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}):[^<>\s]*>`)
+)
+
+// Sanitize 返回清理后的 Markdown 源文。
+func Sanitize(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = stripControl(src)
+
+	lines := strings.Split(src, "\n")
+	var fence string
+	// CommonMark 允许链接地址换到下一行：pendingInline 表示上一行以 "](" 结尾，pendingRef 表示上一行只有引用标签
+	var pendingInline, pendingRef bool
+	for i, line := range lines {
+		if fence != "" {
+			if m := fencePattern.FindStringSubmatch(line); m != nil && m[1][0] == fence[0] && len(m[1]) >= len(fence) &&
+				strings.TrimSpace(line[len(m[0]):]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		inline, ref := pendingInline, pendingRef
+		pendingInline, pendingRef = false, false
+		switch {
+		case strings.TrimSpace(line) == "":
+			// 空行结束段落，挂起的地址不再成立
+		case inline:
+			dest, rest := splitDestination(line)
+			lines[i], pendingInline = sanitizeInline(rest)
+			lines[i] = safeDestination(dest) + lines[i]
+			continue
+		case ref:
+			m := leadDestPattern.FindStringSubmatch(line)
+			lines[i], pendingInline = sanitizeInline(m[3])
+			lines[i] = m[1] + safeDestination(m[2]) + lines[i]
+			continue
+		}
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			continue
+		}
+		if m := refDefPattern.FindStringSubmatch(line); m != nil {
+			lines[i], pendingInline = sanitizeInline(m[3])
+			lines[i] = m[1] + safeDestination(m[2]) + lines[i]
+			continue
+		}
+		if refLabelPattern.MatchString(line) {
+			pendingRef = true
+			continue
+		}
+		lines[i], pendingInline = sanitizeInline(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sanitizeInline 处理一行正文：跳过行内代码，转义 HTML 标签并清理链接地址。
+// 行尾的 "](" 之后没有地址时返回 pending 为 true，地址需从下一行开头读取。
+func sanitizeInline(line string) (out string, pending bool) {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == '`':
+			run := countRun(line[i:], '`')
+			if end := strings.Index(line[i+run:], strings.Repeat("`", run)); end >= 0 {
+				next := i + run + end + run
+				b.WriteString(line[i:next])
+				i = next
+				continue
+			}
+			b.WriteString(line[i : i+run])
+			i += run
+		case c == '<':
+			if m := autolinkPattern.FindStringSubmatch(line[i:]); m != nil {
+				if isSafeScheme(m[1]) {
+					b.WriteString(m[0])
+				} else {
+					b.WriteString("&lt;" + m[0][1:])
+				}
+				i += len(m[0])
+				continue
+			}
+			if tagStartPattern.MatchString(line[i:]) {
+				b.WriteString("&lt;")
+			} else {
+				b.WriteByte(c)
+			}
+			i++
+		case c == ']' && i+1 < len(line) && line[i+1] == '(':
+			b.WriteString("](")
+			i += 2
+			dest, rest := splitDestination(line[i:])
+			if strings.TrimSpace(dest) == "" && strings.TrimSpace(rest) == "" {
+				b.WriteString(line[i:])
+				return b.String(), true
+			}
+			b.WriteString(safeDestination(dest))
+			i += len(line[i:]) - len(rest)
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), false
+}
+
+// splitDestination 从 "](" 之后拆出链接地址，返回地址（含可选尖括号）与剩余部分。
+func splitDestination(s string) (string, string) {
+	lead := len(s) - len(strings.TrimLeft(s, " \t"))
+	body := s[lead:]
+	if strings.HasPrefix(body, "<") {
+		if end := strings.IndexByte(body, '>'); end >= 0 {
+			return s[:lead+end+1], s[lead+end+1:]
+		}
+	}
+	// 地址中允许成对的括号，与 CommonMark 一致
+	depth, end := 0, 0
+	for ; end < len(body); end++ {
+		c := body[end]
+		if c == ' ' || c == '\t' || (c == ')' && depth == 0) {
+			break
+		}
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			depth--
+		}
+	}
+	return s[:lead+end], s[lead+end:]
+}
+
+// safeDestination 保留相对地址与白名单协议地址，其余替换为 "#"。
+// 渲染器会解码链接地址中的 HTML 实体，因此先完整解码并去除空白与控制字符再判断协议，
+// 协议不在白名单或无法解析时一律拒绝。
+func safeDestination(dest string) string {
+	trimmed := strings.TrimSpace(dest)
+	trimmed = strings.TrimSuffix(strings.TrimPrefix(trimmed, "<"), ">")
+	normalized := strings.ToLower(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(trimmed)))
+
+	colon := strings.IndexByte(normalized, ':')
+	if colon < 0 {
+		return dest
+	}
+	// 冒号出现在路径、查询或片段中时为相对地址
+	if cut := strings.IndexAny(normalized, "/?#"); cut >= 0 && cut < colon {
+		return dest
+	}
+	if isSafeScheme(normalized[:colon]) {
+		return dest
+	}
+	return strings.Replace(dest, trimmed, "#", 1)
+}
+
+func isSafeScheme(scheme string) bool {
+	_, ok := safeSchemes[strings.ToLower(scheme)]
+	return ok
+}
+
+func countRun(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
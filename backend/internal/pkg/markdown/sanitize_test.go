@@ -0,0 +1,54 @@
+package markdown
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "**粗体** 与 _斜体_", "**粗体** 与 _斜体_"},
+		{"html tag", "你好 <script>alert(1)</script>", "你好 &lt;script>alert(1)&lt;/script>"},
+		{"html comment", "<!-- x -->", "&lt;!-- x -->"},
+		{"channel operator", "ch <- v 与 a < b", "ch <- v 与 a < b"},
+		{"inline code", "使用 `<div>` 标签", "使用 `<div>` 标签"},
+		{"fenced code", "```go\nx := <-ch // <b>\n```\n<b>", "```go\nx := <-ch // <b>\n```\n&lt;b>"},
+		{"safe link", "[文档](https://go.dev/ref/spec)", "[文档](https://go.dev/ref/spec)"},
+		{"relative link", "[章节](/topic/types#map)", "[章节](/topic/types#map)"},
+		{"javascript link", "[点我](javascript:alert(1))", "[点我](#)"},
+		{"uppercase scheme", "[点我]( JaVaScRiPt:alert(1) )", "[点我]( # )"},
+		{"entity colon", "[点我](javascript&#58;alert(1))", "[点我](#)"},
+		{"entity scheme letter", "[x](&#106;avascript:alert(1))", "[x](#)"},
+		{"entity inside scheme", "[x](java&#115;cript:alert(1))", "[x](#)"},
+		{"hex entity scheme", "[x](&#x6A;avascript:alert(1))", "[x](#)"},
+		{"named tab entity", "[x](java&Tab;script:alert(1))", "[x](#)"},
+		{"newline entity", "[x](java&NewLine;script:alert(1))", "[x](#)"},
+		{"named colon entity", "[x](javascript&colon;alert(1))", "[x](#)"},
+		{"raw tab in scheme", "[x](<java\tscript:alert(1)>)", "[x](<#>)"},
+		{"unknown scheme", "[x](ftp://example.com/a)", "[x](#)"},
+		{"unparseable scheme", "[x](java$script:alert(1))", "[x](#)"},
+		{"mailto link", "[信](mailto:a@example.com)", "[信](mailto:a@example.com)"},
+		{"uppercase safe scheme", "[x](HTTPS://go.dev)", "[x](HTTPS://go.dev)"},
+		{"colon in path", "[x](./a:b)", "[x](./a:b)"},
+		{"colon in fragment", "[x](#a:b)", "[x](#a:b)"},
+		{"colon in query", "[x](?q=a:b)", "[x](?q=a:b)"},
+		{"image data", "![x](data:image/svg+xml;base64,AAAA)", "![x](#)"},
+		{"angle destination", "[x](<vbscript:msgbox>)", "[x](<#>)"},
+		{"autolink", "<https://go.dev> <javascript:alert(1)>", "<https://go.dev> &lt;javascript:alert(1)>"},
+		{"reference definition", "[a]: javascript:alert(1) \"t\"", "[a]: # \"t\""},
+		{"reference definition entity", "[a]: &#106;avascript:alert(1)", "[a]: #"},
+		{"destination on next line", "[x](\njavascript:alert(1))", "[x](\n#)"},
+		{"indented destination on next line", "[x]( \n  <javascript:alert(1)> \"t\")", "[x]( \n  <#> \"t\")"},
+		{"safe destination on next line", "[x](\nhttps://go.dev)", "[x](\nhttps://go.dev)"},
+		{"reference destination on next line", "[a]:\njavascript:alert(1)\n\n[a]", "[a]:\n#\n\n[a]"},
+		{"reference label before blank line", "[a]:\n\njavascript:alert(1)", "[a]:\n\njavascript:alert(1)"},
+		{"next line after code", "`x` [y](\njavascript:alert(1))", "`x` [y](\n#)"},
+		{"control chars", "a\x00b\r\nc\x1b", "ab\nc"},
+	}
+	for _, tc := range cases {
+		if got := Sanitize(tc.in); got != tc.want {
+			t.Errorf("%s: Sanitize(%q) = %q, want %q", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+// Package metrics 实现 Prometheus 文本格式（0.0.4）的指标注册与输出，
+// 支持计数器、仪表盘、直方图以及在采集时计算取值的函数型指标。
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType Prometheus 文本格式的媒体类型。
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets 以秒为单位的默认延迟分桶，与 Prometheus 客户端库一致。
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default 进程级默认注册表，/metrics 输出其中的全部指标。
+var Default = NewRegistry()
+
+// Sample 函数型指标在采集时返回的一个样本。
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// Registry 按注册顺序保存指标族。
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+	names    map[string]bool
+}
+
+// family 一个指标族：同名、同类型、同标签集的全部序列。
+type family interface {
+	write(w io.Writer) error
+}
+
+// NewRegistry 创建空注册表。
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic("metrics: 指标重复注册: " + name)
+	}
+	r.names[name] = true
+	r.families = append(r.families, f)
+}
+
+// WriteText 以 Prometheus 文本格式输出全部指标。
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]family(nil), r.families...)
+	r.mu.Unlock()
+	for _, f := range families {
+		if err := f.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// meta 指标族的名称、说明、类型与标签名。
+type meta struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (m meta) header(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, escapeHelp(m.help), m.name, m.typ)
+	return err
+}
+
+// sample 生成一条样本行，extra 为直方图的 le 等附加标签。
+func (m meta) sample(w io.Writer, suffix string, values []string, extra string, v float64) error {
+	var b strings.Builder
+	b.WriteString(m.name)
+	b.WriteString(suffix)
+	if len(m.labels) > 0 || extra != "" {
+		b.WriteByte('{')
+		for i, label := range m.labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(label)
+			b.WriteString(`="`)
+			b.WriteString(escapeLabel(values[i]))
+			b.WriteByte('"')
+		}
+		if extra != "" {
+			if len(m.labels) > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(extra)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(v))
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (m meta) check(values []string) {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: %s 需要 %d 个标签值，实际 %d 个", m.name, len(m.labels), len(values)))
+	}
+}
+
+// seriesKey 把标签值拼接为映射键，\xff 不会出现在合法 UTF-8 中。
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// vec 按标签值保存序列，输出时按标签值排序，保证结果稳定。
+type vec struct {
+	meta
+	mu     sync.Mutex
+	series map[string]*seriesEntry
+	create func() interface{}
+}
+
+type seriesEntry struct {
+	values []string
+	metric interface{}
+}
+
+func newVec(m meta, create func() interface{}) *vec {
+	return &vec{meta: m, series: make(map[string]*seriesEntry), create: create}
+}
+
+func (v *vec) with(values []string) interface{} {
+	v.check(values)
+	key := seriesKey(values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	entry, ok := v.series[key]
+	if !ok {
+		entry = &seriesEntry{values: append([]string(nil), values...), metric: v.create()}
+		v.series[key] = entry
+	}
+	return entry.metric
+}
+
+func (v *vec) each(fn func(values []string, metric interface{}) error) error {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	entries := make([]*seriesEntry, 0, len(keys))
+	sort.Strings(keys)
+	for _, key := range keys {
+		entries = append(entries, v.series[key])
+	}
+	v.mu.Unlock()
+	for _, entry := range entries {
+		if err := fn(entry.values, entry.metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Counter 单调递增的计数器。
+type Counter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// Inc 加一。
+func (c *Counter) Inc() { c.Add(1) }
+
+// Add 增加 v，v 必须非负。
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: 计数器不能减少")
+	}
+	c.mu.Lock()
+	c.value += v
+	c.mu.Unlock()
+}
+
+// Value 返回当前值。
+func (c *Counter) Value() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+// CounterVec 带标签的计数器族。
+type CounterVec struct{ v *vec }
+
+// NewCounter 注册计数器族，name 按惯例以 _total 结尾。
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{v: newVec(meta{name: name, help: help, typ: "counter", labels: labels}, func() interface{} { return &Counter{} })}
+	r.register(name, c)
+	return c
+}
+
+// With 返回标签值对应的计数器，不存在时创建。
+func (c *CounterVec) With(labelValues ...string) *Counter { return c.v.with(labelValues).(*Counter) }
+
+func (c *CounterVec) write(w io.Writer) error {
+	if err := c.v.header(w); err != nil {
+		return err
+	}
+	return c.v.each(func(values []string, metric interface{}) error {
+		return c.v.sample(w, "", values, "", metric.(*Counter).Value())
+	})
+}
+
+// Gauge 可增可减的仪表盘。
+type Gauge struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// Set 设置当前值。
+func (g *Gauge) Set(v float64) {
+	g.mu.Lock()
+	g.value = v
+	g.mu.Unlock()
+}
+
+// Add 增加 v，可为负。
+func (g *Gauge) Add(v float64) {
+	g.mu.Lock()
+	g.value += v
+	g.mu.Unlock()
+}
+
+// Value 返回当前值。
+func (g *Gauge) Value() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.value
+}
+
+// GaugeVec 带标签的仪表盘族。
+type GaugeVec struct{ v *vec }
+
+// NewGauge 注册仪表盘族。
+func (r *Registry) NewGauge(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{v: newVec(meta{name: name, help: help, typ: "gauge", labels: labels}, func() interface{} { return &Gauge{} })}
+	r.register(name, g)
+	return g
+}
+
+// With 返回标签值对应的仪表盘，不存在时创建。
+func (g *GaugeVec) With(labelValues ...string) *Gauge { return g.v.with(labelValues).(*Gauge) }
+
+func (g *GaugeVec) write(w io.Writer) error {
+	if err := g.v.header(w); err != nil {
+		return err
+	}
+	return g.v.each(func(values []string, metric interface{}) error {
+		return g.v.sample(w, "", values, "", metric.(*Gauge).Value())
+	})
+}
+
+// Histogram 累积分桶的直方图。
+type Histogram struct {
+	mu      sync.Mutex
+	bounds  []float64
+	buckets []uint64 // 各上界的非累积计数，输出时累加
+	count   uint64
+	sum     float64
+}
+
+// Observe 记录一个观测值。
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.mu.Lock()
+	if i < len(h.buckets) {
+		h.buckets[i]++
+	}
+	h.count++
+	h.sum += v
+	h.mu.Unlock()
+}
+
+// Count 返回观测次数。
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// HistogramVec 带标签的直方图族。
+type HistogramVec struct{ v *vec }
+
+// NewHistogram 注册直方图族，buckets 为升序的上界，+Inf 自动追加。
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+	h := &HistogramVec{v: newVec(meta{name: name, help: help, typ: "histogram", labels: labels}, func() interface{} {
+		return &Histogram{bounds: bounds, buckets: make([]uint64, len(bounds))}
+	})}
+	r.register(name, h)
+	return h
+}
+
+// With 返回标签值对应的直方图，不存在时创建。
+func (h *HistogramVec) With(labelValues ...string) *Histogram {
+	return h.v.with(labelValues).(*Histogram)
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	if err := h.v.header(w); err != nil {
+		return err
+	}
+	return h.v.each(func(values []string, metric interface{}) error {
+		s := metric.(*Histogram)
+		s.mu.Lock()
+		counts := append([]uint64(nil), s.buckets...)
+		count, sum := s.count, s.sum
+		s.mu.Unlock()
+
+		var cumulative uint64
+		for i, bound := range s.bounds {
+			cumulative += counts[i]
+			if err := h.v.sample(w, "_bucket", values, `le="`+formatFloat(bound)+`"`, float64(cumulative)); err != nil {
+				return err
+			}
+		}
+		if err := h.v.sample(w, "_bucket", values, `le="+Inf"`, float64(count)); err != nil {
+			return err
+		}
+		if err := h.v.sample(w, "_sum", values, "", sum); err != nil {
+			return err
+		}
+		return h.v.sample(w, "_count", values, "", float64(count))
+	})
+}
+
+// funcFamily 采集时调用 collect 计算样本的指标族，用于连接池状态等外部数据。
+type funcFamily struct {
+	meta
+	collect func() []Sample
+}
+
+// NewGaugeFunc 注册函数型仪表盘，collect 返回各标签值下的当前值。
+func (r *Registry) NewGaugeFunc(name, help string, collect func() []Sample, labels ...string) {
+	r.register(name, &funcFamily{meta: meta{name: name, help: help, typ: "gauge", labels: labels}, collect: collect})
+}
+
+// NewCounterFunc 注册函数型计数器，collect 返回的值必须单调递增。
+func (r *Registry) NewCounterFunc(name, help string, collect func() []Sample, labels ...string) {
+	r.register(name, &funcFamily{meta: meta{name: name, help: help, typ: "counter", labels: labels}, collect: collect})
+}
+
+func (f *funcFamily) write(w io.Writer) error {
+	if err := f.header(w); err != nil {
+		return err
+	}
+	samples := f.collect()
+	sort.Slice(samples, func(i, j int) bool {
+		return seriesKey(samples[i].LabelValues) < seriesKey(samples[j].LabelValues)
+	})
+	for _, s := range samples {
+		f.check(s.LabelValues)
+		if err := f.sample(w, "", s.LabelValues, "", s.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		r := NewRegistry()
+		requests := r.NewCounter("http_requests_total", "请求总数", "method", "status")
+		requests.With("POST", "500").Inc()
+		requests.With("GET", "200").Add(2)
+		requests.With("GET", "200").Inc()
+
+		latency := r.NewHistogram("http_request_duration_seconds", "请求耗时", []float64{0.5, 0.1}, "route")
+		latency.With("/a").Observe(0.05)
+		latency.With("/a").Observe(0.1)
+		latency.With("/a").Observe(3)
+
+		r.NewGauge("queue_depth", "队列长度\n第二行").With().Set(-1.5)
+		r.NewGaugeFunc("pool_connections", "连接数", func() []Sample {
+			return []Sample{{LabelValues: []string{"idle"}, Value: 2}, {LabelValues: []string{"in_use"}, Value: 1}}
+		}, "state")
+
+		var b strings.Builder
+		t.AssertNil(r.WriteText(&b))
+		t.Assert(b.String(), `# HELP http_requests_total 请求总数
+# TYPE http_requests_total counter
+http_requests_total{method="GET",status="200"} 3
+http_requests_total{method="POST",status="500"} 1
+# HELP http_request_duration_seconds 请求耗时
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/a",le="0.1"} 2
+http_request_duration_seconds_bucket{route="/a",le="0.5"} 2
+http_request_duration_seconds_bucket{route="/a",le="+Inf"} 3
+http_request_duration_seconds_sum{route="/a"} 3.15
+http_request_duration_seconds_count{route="/a"} 3
+# HELP queue_depth 队列长度\n第二行
+# TYPE queue_depth gauge
+queue_depth -1.5
+# HELP pool_connections 连接数
+# TYPE pool_connections gauge
+pool_connections{state="idle"} 2
+pool_connections{state="in_use"} 1
+`)
+	})
+}
+
+func TestRegistry_EscapesLabels(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		r := NewRegistry()
+		r.NewCounter("events_total", "事件", "name").With("a\"b\\c\nd").Inc()
+		var b strings.Builder
+		t.AssertNil(r.WriteText(&b))
+		t.AssertIN(`events_total{name="a\"b\\c\nd"} 1`, b.String())
+	})
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		r := NewRegistry()
+		c := r.NewCounter("dup_total", "重复")
+		panicked := func(fn func()) (ok bool) {
+			defer func() { ok = recover() != nil }()
+			fn()
+			return false
+		}
+		t.Assert(panicked(func() { r.NewGauge("dup_total", "重复") }), true)
+		t.Assert(panicked(func() { c.With("extra") }), true)
+		t.Assert(panicked(func() { c.With().Add(-1) }), true)
+	})
+}
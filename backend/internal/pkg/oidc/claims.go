@@ -0,0 +1,57 @@
+package oidc
+
+import "strings"
+
+// Claims 为已验证 ID Token 中的全部声明。
+type Claims map[string]interface{}
+
+// Subject 返回提供方内唯一且稳定的用户标识。
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// String 读取字符串声明，不存在或类型不符时返回空串。
+func (c Claims) String(name string) string {
+	v, _ := c[name].(string)
+	return v
+}
+
+// Strings 读取数组声明（如 groups、roles）；字符串声明按空白或逗号切分。
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return v
+	case string:
+		return strings.FieldsFunc(v, func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+	case bool:
+		if v {
+			return []string{"true"}
+		}
+	}
+	return nil
+}
+
+// HasAny 判断声明中是否包含任一期望值，用于将提供方角色映射为本地角色。
+func (c Claims) HasAny(name string, values []string) bool {
+	if name == "" || len(values) == 0 {
+		return false
+	}
+	for _, got := range c.Strings(name) {
+		for _, want := range values {
+			if got == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	appjwt "go-study2/internal/pkg/jwt"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrDiscovery     = errors.New("OIDC 提供方元数据获取失败")
+	ErrExchange      = errors.New("OIDC 授权码换取令牌失败")
+	ErrInvalidToken  = errors.New("OIDC ID Token 无效")
+	ErrNonceMismatch = errors.New("OIDC nonce 不匹配")
+)
+
+// 允许的时钟偏差，避免提供方与本机时间轻微不一致导致误判。
+const clockLeeway = time.Minute
+
+// Config 描述单个 OIDC 提供方的客户端参数。
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Scopes 为空时使用 openid profile email。
+	Scopes     []string
+	HTTPClient *http.Client
+}
+
+// Discovery 为 /.well-known/openid-configuration 中需要的字段。
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Token 为令牌端点的响应。
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Provider 是授权码 + PKCE 流程的依赖方客户端，元数据与公钥按需拉取并缓存。
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *Discovery
+	keys      map[string]interface{}
+}
+
+// NewProvider 创建提供方客户端，不会立即发起网络请求。
+func NewProvider(cfg Config) *Provider {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email"}
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+// AuthCodeURL 生成跳转到提供方的授权地址，携带 state、nonce 与 S256 code_challenge。
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallengeS256(codeVerifier))
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange 使用授权码与 code_verifier 换取令牌。
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var token Token
+	if err := p.doJSON(req, &token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: 响应缺少 id_token", ErrExchange)
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、iss、aud、exp 与 nonce，返回全部声明。
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (Claims, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwtlib.MapClaims{}
+	_, err = jwtlib.ParseWithClaims(rawIDToken, claims, func(token *jwtlib.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.lookupKey(ctx, kid)
+	},
+		jwtlib.WithValidMethods([]string{appjwt.AlgRS256, appjwt.AlgEdDSA}),
+		jwtlib.WithIssuer(d.Issuer),
+		jwtlib.WithAudience(p.cfg.ClientID),
+		jwtlib.WithExpirationRequired(),
+		jwtlib.WithIssuedAt(),
+		jwtlib.WithLeeway(clockLeeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	result := Claims(claims)
+	if aud, _ := claims.GetAudience(); len(aud) > 1 && result.String("azp") != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: azp 不匹配", ErrInvalidToken)
+	}
+	if result.Subject() == "" {
+		return nil, fmt.Errorf("%w: 缺少 sub", ErrInvalidToken)
+	}
+	if nonce == "" || result.String("nonce") != nonce {
+		return nil, ErrNonceMismatch
+	}
+	return result, nil
+}
+
+// Discover 拉取并缓存提供方元数据，issuer 必须与配置一致。
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	cached := p.discovery
+	p.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	issuer := strings.TrimSuffix(p.cfg.Issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var d Discovery
+	if err := p.doJSON(req, &d); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("%w: issuer 不一致 %s", ErrDiscovery, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: 缺少必需的端点", ErrDiscovery)
+	}
+
+	p.mu.Lock()
+	p.discovery = &d
+	p.mu.Unlock()
+	return &d, nil
+}
+
+// lookupKey 按 kid 查找公钥，未命中时刷新一次 JWKS 以适配提供方轮换密钥。
+func (p *Provider) lookupKey(ctx context.Context, kid string) (interface{}, error) {
+	if kid == "" {
+		return nil, errors.New("ID Token 缺少 kid")
+	}
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	key, ok = p.keys[kid]
+	p.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的 kid: %s", kid)
+	}
+	return key, nil
+}
+
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+	var set appjwt.JWKSet
+	if err := p.doJSON(req, &set); err != nil {
+		return fmt.Errorf("获取 JWKS 失败: %v", err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Provider) doJSON(req *http.Request, out interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// NewCodeVerifier 生成 RFC 7636 要求的高熵 code_verifier（43 字符）。
+func NewCodeVerifier() (string, error) {
+	return randomToken(32)
+}
+
+// NewNonce 生成绑定到 ID Token 的随机 nonce。
+func NewNonce() (string, error) {
+	return randomToken(16)
+}
+
+// CodeChallengeS256 计算 code_verifier 的 S256 challenge。
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
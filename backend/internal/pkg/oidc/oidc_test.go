@@ -0,0 +1,115 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"go-study2/internal/pkg/oidc"
+	"go-study2/internal/pkg/oidc/oidctest"
+)
+
+// authorize 模拟浏览器访问授权地址，返回提供方回调中的 code 与 state。
+func authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("访问授权地址失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("授权应重定向，得到 %d", resp.StatusCode)
+	}
+	loc, _ := url.Parse(resp.Header.Get("Location"))
+	return loc.Query().Get("code"), loc.Query().Get("state")
+}
+
+func TestProvider_AuthorizationCodeWithPKCE(t *testing.T) {
+	fake := oidctest.NewProvider("client-a", "secret-a")
+	defer fake.Close()
+	fake.SetUser(map[string]interface{}{"sub": "u-1", "preferred_username": "alice", "groups": []string{"staff", "admins"}})
+
+	ctx := context.Background()
+	p := oidc.NewProvider(oidc.Config{
+		Issuer:       fake.Issuer(),
+		ClientID:     "client-a",
+		ClientSecret: "secret-a",
+		RedirectURL:  "http://127.0.0.1/callback",
+	})
+	verifier, _ := oidc.NewCodeVerifier()
+	nonce, _ := oidc.NewNonce()
+	authURL, err := p.AuthCodeURL(ctx, "state-1", nonce, verifier)
+	if err != nil {
+		t.Fatalf("生成授权地址失败: %v", err)
+	}
+	code, state := authorize(t, authURL)
+	if state != "state-1" {
+		t.Fatalf("state 应原样回传，得到 %s", state)
+	}
+
+	if _, err := p.Exchange(ctx, code, "wrong-verifier-0000000000000000000000000000"); !errors.Is(err, oidc.ErrExchange) {
+		t.Fatalf("错误的 code_verifier 应被拒绝，得到: %v", err)
+	}
+
+	code, _ = authorize(t, authURL)
+	token, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("换取令牌失败: %v", err)
+	}
+	if _, err := p.VerifyIDToken(ctx, token.IDToken, "other-nonce"); !errors.Is(err, oidc.ErrNonceMismatch) {
+		t.Fatalf("nonce 不匹配应被拒绝，得到: %v", err)
+	}
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, nonce)
+	if err != nil {
+		t.Fatalf("校验 ID Token 失败: %v", err)
+	}
+	if claims.Subject() != "u-1" || claims.String("preferred_username") != "alice" {
+		t.Fatalf("声明解析不正确: %v", claims)
+	}
+	if !claims.HasAny("groups", []string{"admins"}) || claims.HasAny("groups", []string{"root"}) {
+		t.Fatalf("角色映射判断不正确")
+	}
+}
+
+func TestProvider_RejectsForeignAudience(t *testing.T) {
+	fake := oidctest.NewProvider("client-b", "secret-b")
+	defer fake.Close()
+
+	ctx := context.Background()
+	issuing := oidc.NewProvider(oidc.Config{Issuer: fake.Issuer(), ClientID: "client-b", ClientSecret: "secret-b", RedirectURL: "http://127.0.0.1/cb"})
+	verifier, _ := oidc.NewCodeVerifier()
+	authURL, _ := issuing.AuthCodeURL(ctx, "s", "n", verifier)
+	code, _ := authorize(t, authURL)
+	token, err := issuing.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("换取令牌失败: %v", err)
+	}
+
+	other := oidc.NewProvider(oidc.Config{Issuer: fake.Issuer(), ClientID: "client-other", RedirectURL: "http://127.0.0.1/cb"})
+	if _, err := other.VerifyIDToken(ctx, token.IDToken, "n"); !errors.Is(err, oidc.ErrInvalidToken) {
+		t.Fatalf("发给其他客户端的 ID Token 应被拒绝，得到: %v", err)
+	}
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	fake := oidctest.NewProvider("client-c", "")
+	defer fake.Close()
+
+	p := oidc.NewProvider(oidc.Config{Issuer: fake.Issuer() + "/tenant", ClientID: "client-c"})
+	if _, err := p.Discover(context.Background()); !errors.Is(err, oidc.ErrDiscovery) {
+		t.Fatalf("issuer 不一致应拒绝，得到: %v", err)
+	}
+}
+
+func TestClaims_Strings(t *testing.T) {
+	c := oidc.Claims{"roles": "admin, editor", "flag": true}
+	if got := c.Strings("roles"); len(got) != 2 || got[1] != "editor" {
+		t.Fatalf("逗号分隔的角色解析不正确: %v", got)
+	}
+	if !c.HasAny("flag", []string{"true"}) {
+		t.Fatalf("布尔声明应可映射")
+	}
+}
@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+type sampleAuthor struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type sampleBase struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type samplePost struct {
+	sampleBase
+	Title   string            `json:"title"`
+	Body    string            `json:"body,omitempty"`
+	Author  *sampleAuthor     `json:"author"`
+	Tags    []string          `json:"tags"`
+	Meta    map[string]string `json:"meta,omitempty"`
+	Replies []samplePost      `json:"replies,omitempty"`
+	Extra   interface{}       `json:"extra,omitempty"`
+	secret  string
+	Hidden  bool `json:"-"`
+}
+
+func TestGenerator_StructComponents(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		doc := NewDocument(Info{Title: "test", Version: "v1"})
+		gen := NewGenerator(doc.Components.Schemas)
+
+		ref := gen.Schema(samplePost{})
+		t.Assert(ref.Ref, "#/components/schemas/openapi.samplePost")
+
+		post := doc.Components.Schemas["openapi.samplePost"]
+		t.AssertNE(post, nil)
+		t.Assert(post.Required, []string{"createdAt", "title", "author", "tags"})
+		t.Assert(post.AdditionalProperties, false)
+		t.Assert(post.Properties["createdAt"].Format, "date-time")
+		t.Assert(len(post.Properties["author"].AnyOf), 2)
+		t.Assert(post.Properties["tags"].Type, TypeSet{"array", "null"})
+		t.Assert(post.Properties["replies"].Items.Ref, "#/components/schemas/openapi.samplePost")
+		_, hasHidden := post.Properties["Hidden"]
+		_, hasSecret := post.Properties["secret"]
+		t.Assert(hasHidden || hasSecret, false)
+		t.AssertNE(doc.Components.Schemas["openapi.sampleAuthor"], nil)
+
+		raw, err := json.Marshal(post.Properties["tags"])
+		t.AssertNil(err)
+		t.Assert(string(raw), `{"type":["array","null"],"items":{"type":"string"}}`)
+	})
+}
+
+func TestDocument_Validate(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		doc := NewDocument(Info{Title: "test", Version: "v1"})
+		schema := NewGenerator(doc.Components.Schemas).Schema(samplePost{})
+		decode := func(raw string) interface{} {
+			var v interface{}
+			if err := json.Unmarshal([]byte(raw), &v); err != nil {
+				panic(err)
+			}
+			return v
+		}
+
+		valid := `{"createdAt":"2024-05-01T10:00:00+08:00","title":"t","author":null,"tags":["a"],
+			"replies":[{"createdAt":"2024-05-01T02:00:00Z","title":"r","author":{"id":1,"name":"n"},"tags":null}]}`
+		t.AssertNil(doc.Validate(schema, decode(valid)))
+
+		for _, raw := range []string{
+			`{"createdAt":"2024-05-01T10:00:00Z","title":"t","tags":[]}`,
+			`{"createdAt":"yesterday","title":"t","author":null,"tags":[]}`,
+			`{"createdAt":"2024-05-01T10:00:00Z","title":1,"author":null,"tags":[]}`,
+			`{"createdAt":"2024-05-01T10:00:00Z","title":"t","author":{"id":1.5,"name":"n"},"tags":[]}`,
+			`{"createdAt":"2024-05-01T10:00:00Z","title":"t","author":null,"tags":[],"unknown":true}`,
+			`{"createdAt":"2024-05-01T10:00:00Z","title":"t","author":null,"tags":[],"meta":{"k":1}}`,
+		} {
+			t.AssertNE(doc.Validate(schema, decode(raw)), nil)
+		}
+
+		envelope := &Schema{
+			Type:       TypeSet{"object"},
+			Properties: map[string]*Schema{"code": {Type: TypeSet{"integer"}, Enum: []interface{}{40001, 40004}}},
+		}
+		t.AssertNil(doc.Validate(envelope, decode(`{"code":40004}`)))
+		t.AssertNE(doc.Validate(envelope, decode(`{"code":40099}`)), nil)
+		t.AssertNil(doc.Validate(&Schema{Const: 20000}, decode(`20000`)))
+		t.AssertNE(doc.Validate(&Schema{Const: 20000}, decode(`40001`)), nil)
+	})
+}
+
+func TestDocument_ValidateResponse(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		doc := NewDocument(Info{Title: "test", Version: "v1"})
+		item := &Schema{Type: TypeSet{"object"}, Properties: map[string]*Schema{"name": {Type: TypeSet{"string"}}}, Required: []string{"name"}}
+		jsonBody := func(s *Schema) map[string]MediaType {
+			return map[string]MediaType{"application/json": {Schema: s}}
+		}
+		doc.AddOperation("GET", "/types/{subtopic}", &Operation{Responses: map[string]*Response{
+			"200": {Description: "ok", Content: jsonBody(item)},
+		}})
+		doc.AddOperation("GET", "/types/outline", &Operation{Responses: map[string]*Response{
+			"200":     {Description: "ok", Content: jsonBody(&Schema{Type: TypeSet{"array"}})},
+			"302":     {Description: "redirect"},
+			"default": {Description: "error", Content: map[string]MediaType{"text/html": {}}},
+		}})
+
+		op, tpl := doc.FindOperation("GET", "/types/outline/?format=json")
+		t.Assert(tpl, "/types/outline")
+		t.AssertNE(op, nil)
+
+		t.AssertNil(doc.ValidateResponse("GET", "/types/boolean", 200, "application/json; charset=utf-8", []byte(`{"name":"b"}`)))
+		t.AssertNE(doc.ValidateResponse("GET", "/types/boolean", 200, "application/json", []byte(`{}`)), nil)
+		t.AssertNil(doc.ValidateResponse("GET", "/types/outline", 200, "application/json", []byte(`[]`)))
+		t.AssertNil(doc.ValidateResponse("GET", "/types/outline", 302, "", nil))
+		t.AssertNil(doc.ValidateResponse("GET", "/types/outline", 500, "text/html", []byte(`<p>oops</p>`)))
+
+		err := doc.ValidateResponse("POST", "/types/outline", 200, "application/json", []byte(`[]`))
+		t.Assert(errors.Is(err, ErrUndocumented), true)
+		err = doc.ValidateResponse("GET", "/types/boolean", 404, "application/json", []byte(`{}`))
+		t.Assert(errors.Is(err, ErrUndocumented), true)
+		err = doc.ValidateResponse("GET", "/types/outline", 200, "text/plain", []byte(`x`))
+		t.Assert(errors.Is(err, ErrUndocumented), true)
+	})
+}
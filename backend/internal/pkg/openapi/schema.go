@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema 为 JSON Schema（OpenAPI 3.1 方言）的子集。
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        TypeSet            `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	// AdditionalProperties 为 false 表示不允许未声明的字段，为 *Schema 时约束其余字段的值。
+	AdditionalProperties interface{}   `json:"additionalProperties,omitempty"`
+	Enum                 []interface{} `json:"enum,omitempty"`
+	Const                interface{}   `json:"const,omitempty"`
+	AnyOf                []*Schema     `json:"anyOf,omitempty"`
+}
+
+// TypeSet 为 type 关键字，只有一个类型时序列化为字符串，可空类型序列化为数组。
+type TypeSet []string
+
+// MarshalJSON 实现 json.Marshaler。
+func (t TypeSet) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Ref 返回引用 components 中结构的 Schema。
+func Ref(name string) *Schema {
+	return &Schema{Ref: refPrefix + name}
+}
+
+// Nullable 返回允许 null 的 Schema；引用类型包装为 anyOf，不限类型的 Schema 原样返回。
+func Nullable(s *Schema) *Schema {
+	switch {
+	case s.Ref != "" || len(s.AnyOf) > 0:
+		return &Schema{AnyOf: []*Schema{s, {Type: TypeSet{"null"}}}}
+	case len(s.Type) == 0:
+		return s
+	}
+	for _, t := range s.Type {
+		if t == "null" {
+			return s
+		}
+	}
+	copied := *s
+	copied.Type = append(append(TypeSet{}, s.Type...), "null")
+	return &copied
+}
+
+const refPrefix = "#/components/schemas/"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Generator 通过反射把 Go 类型转换为 Schema，具名结构体登记到 components 中并以 $ref 引用。
+// 字段名取 json 标签；未带 omitempty 的字段为必填；指针、切片与 map 可为 null；
+// 结构体不允许出现未声明的字段，便于发现处理器与文档不一致。
+type Generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// NewGenerator 创建写入 schemas 的生成器，schemas 通常为 Document.Components.Schemas。
+func NewGenerator(schemas map[string]*Schema) *Generator {
+	return &Generator{schemas: schemas, names: make(map[reflect.Type]string)}
+}
+
+// Schema 返回 v 的类型对应的 Schema；v 为 nil 时返回不限类型的 Schema。
+func (g *Generator) Schema(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return g.SchemaOf(reflect.TypeOf(v))
+}
+
+// SchemaOf 返回类型 t 对应的 Schema。
+func (g *Generator) SchemaOf(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: TypeSet{"string"}, Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return Nullable(g.SchemaOf(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: TypeSet{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeSet{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeSet{"number"}}
+	case reflect.String:
+		return &Schema{Type: TypeSet{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: TypeSet{"string"}, Format: "byte"}
+		}
+		items := g.SchemaOf(t.Elem())
+		if t.Kind() == reflect.Array {
+			return &Schema{Type: TypeSet{"array"}, Items: items}
+		}
+		return &Schema{Type: TypeSet{"array", "null"}, Items: items}
+	case reflect.Map:
+		return &Schema{Type: TypeSet{"object", "null"}, AdditionalProperties: g.SchemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return Ref(g.component(t))
+	default:
+		// interface{} 等无法静态确定的类型不做约束
+		return &Schema{}
+	}
+}
+
+// component 登记具名结构体并返回组件名，组件名为“包名.类型名”。
+func (g *Generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := path.Base(t.PkgPath()) + "." + t.Name()
+	g.names[t] = name
+	// 先占位再展开字段，支持自引用结构
+	placeholder := &Schema{}
+	g.schemas[name] = placeholder
+	*placeholder = *g.structSchema(t)
+	return name
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 TypeSet{"object"},
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields 按 encoding/json 的规则展开字段，匿名嵌入且未命名的结构体字段提升到外层。
+func (g *Generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.SchemaOf(f.Type)
+		if !hasOption(opts, "omitempty") && !hasOption(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasOption(opts, want string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == want {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerMethod(method string) string {
+	return strings.ToLower(method)
+}
@@ -0,0 +1,281 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUndocumented 表示请求的路径、方法、状态码或内容类型未在文档中声明。
+var ErrUndocumented = errors.New("接口未在文档中声明")
+
+// FindOperation 按路径模板查找操作，字面量段多的模板优先（/types/outline 优先于 /types/{subtopic}）。
+// path 可带查询串与末尾斜杠；返回值 template 为匹配到的路径模板。
+func (d *Document) FindOperation(method, path string) (op *Operation, template string) {
+	path, _, _ = strings.Cut(path, "?")
+	segments := splitPath(path)
+	best := -1
+	for tpl, item := range d.Paths {
+		candidate, ok := (*item)[lowerMethod(method)]
+		if !ok {
+			continue
+		}
+		literals, ok := matchTemplate(splitPath(tpl), segments)
+		if !ok || literals <= best {
+			continue
+		}
+		best, op, template = literals, candidate, tpl
+	}
+	return op, template
+}
+
+// ValidateResponse 校验一次响应是否与文档一致：操作、状态码（未列出时使用 default）与内容类型必须已声明，
+// JSON 响应体必须符合对应的 Schema。
+func (d *Document) ValidateResponse(method, path string, status int, contentType string, body []byte) error {
+	op, tpl := d.FindOperation(method, path)
+	if op == nil {
+		return fmt.Errorf("%w: %s %s", ErrUndocumented, method, path)
+	}
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if resp, ok = op.Responses["default"]; !ok {
+			return fmt.Errorf("%w: %s %s 状态码 %d", ErrUndocumented, method, tpl, status)
+		}
+	}
+	if len(resp.Content) == 0 {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return fmt.Errorf("%s %s 状态码 %d 不应有响应体", method, tpl, status)
+		}
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%s %s 内容类型无效: %q", method, tpl, contentType)
+	}
+	media, ok := resp.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("%w: %s %s 状态码 %d 内容类型 %s", ErrUndocumented, method, tpl, status, mediaType)
+	}
+	if media.Schema == nil || mediaType != "application/json" {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("%s %s 响应不是合法 JSON: %v", method, tpl, err)
+	}
+	if err := d.Validate(media.Schema, value); err != nil {
+		return fmt.Errorf("%s %s 状态码 %d: %w", method, tpl, status, err)
+	}
+	return nil
+}
+
+// Validate 校验 JSON 解码后的值（map[string]interface{}、[]interface{}、float64 等）是否符合 schema。
+func (d *Document) Validate(schema *Schema, value interface{}) error {
+	return d.check(schema, value, "$", 0)
+}
+
+// maxRefDepth 防止循环引用导致无限递归。
+const maxRefDepth = 64
+
+func (d *Document) check(s *Schema, v interface{}, at string, depth int) error {
+	if s.Ref != "" {
+		if depth > maxRefDepth {
+			return fmt.Errorf("%s: 引用层级过深", at)
+		}
+		target, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+		if !ok {
+			return fmt.Errorf("%s: 未定义的引用 %s", at, s.Ref)
+		}
+		return d.check(target, v, at, depth+1)
+	}
+	if len(s.AnyOf) > 0 {
+		var reasons []string
+		for _, candidate := range s.AnyOf {
+			err := d.check(candidate, v, at, depth)
+			if err == nil {
+				return nil
+			}
+			reasons = append(reasons, err.Error())
+		}
+		return fmt.Errorf("%s: 不符合任何候选结构（%s）", at, strings.Join(reasons, "；"))
+	}
+	if len(s.Type) > 0 && !matchesType(s.Type, v) {
+		return fmt.Errorf("%s: 期望类型 %s，实际为 %s", at, strings.Join(s.Type, "|"), typeName(v))
+	}
+	if s.Const != nil && !sameValue(s.Const, v) {
+		return fmt.Errorf("%s: 期望值 %v，实际为 %v", at, s.Const, v)
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		return fmt.Errorf("%s: 值 %v 不在允许范围内", at, v)
+	}
+	switch value := v.(type) {
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+				return fmt.Errorf("%s: %q 不是 RFC 3339 时间", at, value)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range value {
+				if err := d.check(s.Items, item, fmt.Sprintf("%s[%d]", at, i), depth); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		return d.checkObject(s, value, at, depth)
+	}
+	return nil
+}
+
+func (d *Document) checkObject(s *Schema, obj map[string]interface{}, at string, depth int) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return fmt.Errorf("%s: 缺少必填字段 %s", at, name)
+		}
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		child := at + "." + k
+		if prop, ok := s.Properties[k]; ok {
+			if err := d.check(prop, obj[k], child, depth); err != nil {
+				return err
+			}
+			continue
+		}
+		switch extra := s.AdditionalProperties.(type) {
+		case bool:
+			if !extra {
+				return fmt.Errorf("%s: 未声明的字段", child)
+			}
+		case *Schema:
+			if err := d.check(extra, obj[k], child, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func matchesType(types TypeSet, v interface{}) bool {
+	for _, t := range types {
+		switch value := v.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && value == math.Trunc(value)) {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// sameValue 比较 Schema 中的常量与解码后的 JSON 值，数字统一按 float64 比较。
+func sameValue(want, got interface{}) bool {
+	if n, ok := toFloat(want); ok {
+		g, ok := got.(float64)
+		return ok && g == n
+	}
+	return reflect.DeepEqual(want, got)
+}
+
+func inEnum(enum []interface{}, v interface{}) bool {
+	for _, candidate := range enum {
+		if sameValue(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// matchTemplate 判断路径段是否匹配模板，返回模板中字面量段的个数。
+func matchTemplate(tpl, segments []string) (int, bool) {
+	if len(tpl) != len(segments) {
+		return 0, false
+	}
+	literals := 0
+	for i, part := range tpl {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if part != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
@@ -0,0 +1,120 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsData string
+
+// commonPasswords 内置常见弱密码表（小写），比较时忽略大小写。
+var commonPasswords = parseCommonPasswords(commonPasswordsData)
+
+func parseCommonPasswords(data string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}
+
+func isCommon(raw string) bool {
+	_, ok := commonPasswords[strings.ToLower(raw)]
+	return ok
+}
+
+// BreachedList 离线泄露密码库，仅保存 SHA-1 哈希，不接触明文。
+//
+// 支持两种格式：
+//   - 单个文件：每行一个 40 位 SHA-1 十六进制哈希，可带 ":次数" 后缀，启动时全部载入内存；
+//   - 目录：按哈希前 5 位拆分的 k-匿名分片文件（如 21BD1.txt），每行为剩余 35 位后缀与可选次数，
+//     与 Have I Been Pwned range 接口返回格式一致，查询时只读取对应前缀的分片。
+type BreachedList struct {
+	dir    string
+	hashes map[string]struct{}
+}
+
+// LoadBreachedList 从文件或分片目录加载泄露密码库。
+func LoadBreachedList(path string) (*BreachedList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取泄露密码库失败: %w", err)
+	}
+	if info.IsDir() {
+		return &BreachedList{dir: path}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取泄露密码库失败: %w", err)
+	}
+	defer f.Close()
+
+	hashes := make(map[string]struct{})
+	err = scanHashLines(f, func(hash string) bool {
+		if len(hash) == sha1.Size*2 {
+			hashes[hash] = struct{}{}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("解析泄露密码库失败: %w", err)
+	}
+	return &BreachedList{hashes: hashes}, nil
+}
+
+// Contains 判断密码是否出现在泄露密码库中；分片缺失或读取失败时视为未命中。
+func (b *BreachedList) Contains(raw string) bool {
+	sum := sha1.Sum([]byte(raw))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if b.hashes != nil {
+		_, ok := b.hashes[hash]
+		return ok
+	}
+
+	prefix, suffix := hash[:5], hash[5:]
+	for _, name := range []string{prefix + ".txt", prefix} {
+		f, err := os.Open(filepath.Join(b.dir, name))
+		if err != nil {
+			continue
+		}
+		found := false
+		_ = scanHashLines(f, func(candidate string) bool {
+			found = candidate == suffix
+			return found
+		})
+		f.Close()
+		return found
+	}
+	return false
+}
+
+// scanHashLines 逐行读取哈希（去除次数后缀并转为大写），fn 返回 true 时提前结束。
+func scanHashLines(r io.Reader, fn func(hash string) bool) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if idx := strings.IndexByte(line, ':'); idx >= 0 {
+			line = line[:idx]
+		}
+		if fn(strings.ToUpper(line)) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -2,42 +2,28 @@ package password
 
 import (
 	"errors"
-	"regexp"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-var (
-	ErrPasswordEmpty      = errors.New("密码不能为空")
-	ErrPasswordTooShort   = errors.New("密码长度需至少 8 位")
-	ErrPasswordComplexity = errors.New("密码需包含大写、小写、数字和特殊字符")
-	lowerPattern          = regexp.MustCompile(`[a-z]`)
-	upperPattern          = regexp.MustCompile(`[A-Z]`)
-	digitPattern          = regexp.MustCompile(`[0-9]`)
-	specialCharPattern    = regexp.MustCompile(`[^A-Za-z0-9]`)
-)
-
+// Validate 按当前生效的策略校验密码，不满足时返回 *PolicyError。
 func Validate(raw string) error {
-	if raw == "" {
-		return ErrPasswordEmpty
-	}
-	if len([]rune(raw)) < 8 {
-		return ErrPasswordTooShort
-	}
-	if !lowerPattern.MatchString(raw) ||
-		!upperPattern.MatchString(raw) ||
-		!digitPattern.MatchString(raw) ||
-		!specialCharPattern.MatchString(raw) {
-		return ErrPasswordComplexity
-	}
-	return nil
+	return CurrentPolicy().Validate(raw)
 }
 
-// Hash 对密码进行 bcrypt 哈希。
+// Hash 校验密码策略后进行 bcrypt 哈希。
 func Hash(raw string) (string, error) {
 	if err := Validate(raw); err != nil {
 		return "", err
 	}
+	return HashUnchecked(raw)
+}
+
+// HashUnchecked 跳过策略校验直接哈希，仅用于系统预置且强制改密的账号。
+func HashUnchecked(raw string) (string, error) {
+	if raw == "" {
+		return "", NewPolicyError(Violation{Rule: RuleRequired, Message: "密码不能为空"})
+	}
 	hashed, err := bcrypt.GenerateFromPassword([]byte(raw), bcrypt.DefaultCost)
 	if err != nil {
 		return "", err
@@ -0,0 +1,169 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 违反规则标识，供前端按规则展示提示。
+const (
+	RuleRequired  = "required"
+	RuleMinLength = "min_length"
+	RuleLower     = "lower"
+	RuleUpper     = "upper"
+	RuleDigit     = "digit"
+	RuleSymbol    = "symbol"
+	RuleCommon    = "common"
+	RuleBreached  = "breached"
+	RuleReused    = "reused"
+)
+
+// 字符类别名称，与配置项 auth.password.requiredClasses 对应。
+const (
+	ClassLower  = "lower"
+	ClassUpper  = "upper"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol"
+)
+
+// DefaultMinLength 未配置时的最小密码长度。
+const DefaultMinLength = 8
+
+// ErrPolicyViolation 密码不满足策略，可通过 errors.As 取得 *PolicyError 查看具体规则。
+var ErrPolicyViolation = errors.New("密码不符合安全策略")
+
+// Policy 描述密码强度规则。
+type Policy struct {
+	MinLength     int
+	RequireLower  bool
+	RequireUpper  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// CheckCommon 为 true 时拒绝内置常见弱密码表中的密码。
+	CheckCommon bool
+	// Breached 离线泄露密码库，为 nil 时不检查。
+	Breached *BreachedList
+}
+
+// Violation 表示一条未满足的规则。
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PolicyError 汇总密码违反的全部规则。
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return strings.Join(messages, "；")
+}
+
+// Unwrap 使 errors.Is(err, ErrPolicyViolation) 成立。
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// NewPolicyError 以给定违规项构造错误，便于上层补充历史复用等规则。
+func NewPolicyError(violations ...Violation) *PolicyError {
+	return &PolicyError{Violations: violations}
+}
+
+var (
+	policyMu      sync.RWMutex
+	currentPolicy = DefaultPolicy()
+)
+
+// DefaultPolicy 返回默认策略：至少 8 位，包含大小写字母、数字与特殊字符，并拒绝常见弱密码。
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:     DefaultMinLength,
+		RequireLower:  true,
+		RequireUpper:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		CheckCommon:   true,
+	}
+}
+
+// Configure 设置全局密码策略，Validate 与 Hash 均按此策略校验。
+func Configure(policy Policy) {
+	if policy.MinLength <= 0 {
+		policy.MinLength = DefaultMinLength
+	}
+	policyMu.Lock()
+	currentPolicy = policy
+	policyMu.Unlock()
+}
+
+// CurrentPolicy 返回当前生效的密码策略。
+func CurrentPolicy() Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return currentPolicy
+}
+
+// Check 返回密码违反的全部规则，满足策略时返回空切片。
+func (p Policy) Check(raw string) []Violation {
+	if raw == "" {
+		return []Violation{{Rule: RuleRequired, Message: "密码不能为空"}}
+	}
+
+	var violations []Violation
+	minLength := p.MinLength
+	if minLength <= 0 {
+		minLength = DefaultMinLength
+	}
+	if len([]rune(raw)) < minLength {
+		violations = append(violations, Violation{Rule: RuleMinLength, Message: fmt.Sprintf("密码长度需至少 %d 位", minLength)})
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSymbol bool
+	for _, c := range raw {
+		switch {
+		case c >= 'a' && c <= 'z':
+			hasLower = true
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, Violation{Rule: RuleLower, Message: "密码需包含小写字母"})
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, Violation{Rule: RuleUpper, Message: "密码需包含大写字母"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, Violation{Rule: RuleDigit, Message: "密码需包含数字"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, Violation{Rule: RuleSymbol, Message: "密码需包含特殊字符"})
+	}
+
+	if p.CheckCommon && isCommon(raw) {
+		violations = append(violations, Violation{Rule: RuleCommon, Message: "密码过于常见，请更换"})
+	}
+	if p.Breached != nil && p.Breached.Contains(raw) {
+		violations = append(violations, Violation{Rule: RuleBreached, Message: "密码出现在已知泄露密码库中，请更换"})
+	}
+	return violations
+}
+
+// Validate 按策略校验密码，不满足时返回 *PolicyError。
+func (p Policy) Validate(raw string) error {
+	if violations := p.Check(raw); len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func sha1Upper(raw string) string {
+	sum := sha1.Sum([]byte(raw))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func rulesOf(violations []Violation) []string {
+	rules := make([]string, 0, len(violations))
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestPolicyCheck(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		policy := DefaultPolicy()
+		t.Assert(rulesOf(policy.Check("")), []string{RuleRequired})
+		t.Assert(rulesOf(policy.Check("abc")), []string{RuleMinLength, RuleUpper, RuleDigit, RuleSymbol})
+		t.Assert(rulesOf(policy.Check("P@ssw0rd")), []string{RuleCommon})
+		t.Assert(len(policy.Check("Study-Go-2026")), 0)
+
+		relaxed := Policy{MinLength: 12, RequireLower: true}
+		t.Assert(rulesOf(relaxed.Check("short")), []string{RuleMinLength})
+		t.Assert(len(relaxed.Check("long enough phrase")), 0)
+		t.Assert(len(relaxed.Check("p@ssw0rd1234")), 0)
+	})
+}
+
+func TestPolicyErrorUnwrap(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		err := DefaultPolicy().Validate("short")
+		t.Assert(errors.Is(err, ErrPolicyViolation), true)
+
+		var policyErr *PolicyError
+		t.Assert(errors.As(err, &policyErr), true)
+		t.AssertGT(len(policyErr.Violations), 1)
+	})
+}
+
+func TestConfigureAffectsValidate(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		defer Configure(DefaultPolicy())
+
+		Configure(Policy{MinLength: 4})
+		t.AssertNil(Validate("abcd"))
+		t.AssertNE(Validate("abc"), nil)
+
+		Configure(Policy{})
+		t.Assert(CurrentPolicy().MinLength, DefaultMinLength)
+	})
+}
+
+func TestBreachedListFile(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		path := filepath.Join(t.TempDir(), "breached.txt")
+		content := "# 示例\n" + sha1Upper("Leaked-Pass-1") + ":42\n" + strings.ToLower(sha1Upper("Leaked-Pass-2")) + "\n"
+		t.AssertNil(os.WriteFile(path, []byte(content), 0o600))
+
+		list, err := LoadBreachedList(path)
+		t.AssertNil(err)
+		t.Assert(list.Contains("Leaked-Pass-1"), true)
+		t.Assert(list.Contains("Leaked-Pass-2"), true)
+		t.Assert(list.Contains("Unique-Pass-3"), false)
+
+		policy := DefaultPolicy()
+		policy.Breached = list
+		t.Assert(rulesOf(policy.Check("Leaked-Pass-1")), []string{RuleBreached})
+	})
+}
+
+func TestBreachedListPrefixDirectory(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		dir := t.TempDir()
+		hash := sha1Upper("Leaked-Pass-1")
+		shard := "0000000000000000000000000000000000A:1\n" + hash[5:] + ":7\n"
+		t.AssertNil(os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(shard), 0o600))
+
+		list, err := LoadBreachedList(dir)
+		t.AssertNil(err)
+		t.Assert(list.Contains("Leaked-Pass-1"), true)
+		t.Assert(list.Contains("Unique-Pass-3"), false)
+
+		_, err = LoadBreachedList(filepath.Join(dir, "missing"))
+		t.AssertNE(err, nil)
+	})
+}
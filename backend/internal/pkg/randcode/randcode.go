@@ -0,0 +1,20 @@
+// Package randcode 生成便于人工输入的随机码，用于邀请码、班级加入码等需要口头或手动传递的场景。
+package randcode
+
+import "crypto/rand"
+
+// Alphabet 随机码使用的字符集，去除易混淆字符（0/O、1/I/L）。
+const Alphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// New 生成 length 位由 Alphabet 字符组成的随机码。
+func New(length int) (string, error) {
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := make([]byte, length)
+	for i, b := range buf {
+		code[i] = Alphabet[int(b)%len(Alphabet)]
+	}
+	return string(code), nil
+}
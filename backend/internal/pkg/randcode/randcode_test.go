@@ -0,0 +1,26 @@
+package randcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestNew(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		a, err := New(12)
+		t.AssertNil(err)
+		t.Assert(len(a), 12)
+		for _, c := range a {
+			t.Assert(strings.ContainsRune(Alphabet, c), true)
+		}
+		b, err := New(12)
+		t.AssertNil(err)
+		t.AssertNE(a, b)
+
+		for _, c := range "0O1IL" {
+			t.Assert(strings.ContainsRune(Alphabet, c), false)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+// Package ratelimit 实现令牌桶限流：每个键对应一个桶，按固定速率补充令牌，每次请求取走一个。
+// 桶状态保存在可替换的 Store 中，默认使用进程内存。
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval 内存存储清理已回满的桶的最小间隔
+const sweepInterval = time.Minute
+
+// Policy 令牌桶策略：桶容量 Burst，每 Period 补充 Requests 个令牌。
+type Policy struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// capacity 返回桶容量，Burst 未设置时等于 Requests。
+func (p Policy) capacity() float64 {
+	if p.Burst > 0 {
+		return float64(p.Burst)
+	}
+	return float64(p.Requests)
+}
+
+// interval 返回补充一个令牌所需的时间。
+func (p Policy) interval() time.Duration {
+	return p.Period / time.Duration(p.Requests)
+}
+
+// Valid 判断策略是否可用：每周期请求数与周期均需为正。
+func (p Policy) Valid() bool {
+	return p.Requests > 0 && p.Period > 0 && p.Burst >= 0
+}
+
+// Result 一次取令牌的结果，字段与 RateLimit-* 响应头对应。
+type Result struct {
+	Allowed    bool
+	Limit      int           // 桶容量
+	Remaining  int           // 本次之后剩余的令牌数
+	Reset      time.Duration // 桶回满所需时间
+	RetryAfter time.Duration // 被拒绝时距下一个令牌可用的时间
+}
+
+// Bucket 单个键的桶状态。
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// Take 按 policy 把桶补充到 now，再尝试取走一个令牌，返回更新后的桶与结果。
+// 存储实现应在持有该键的锁（或等价的原子操作）时调用。
+func (b Bucket) Take(policy Policy, now time.Time, exists bool) (Bucket, Result) {
+	capacity := policy.capacity()
+	interval := policy.interval()
+	if !exists {
+		b = Bucket{Tokens: capacity, Updated: now}
+	}
+	if elapsed := now.Sub(b.Updated); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+float64(elapsed)/float64(interval))
+		b.Updated = now
+	}
+
+	res := Result{Limit: int(capacity)}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.Tokens) * float64(interval))
+	}
+	res.Remaining = int(b.Tokens)
+	res.Reset = time.Duration((capacity - b.Tokens) * float64(interval))
+	return b, res
+}
+
+// Store 保存各键的桶状态。Take 必须对同一键原子执行，以便替换为多实例共享的外部存储。
+type Store interface {
+	Take(key string, policy Policy, now time.Time) Result
+}
+
+// MemoryStore 进程内存中的桶状态，适用于单实例部署。
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	Bucket
+	full time.Time // 桶回满的时间，之后可以安全删除
+}
+
+// NewMemoryStore 创建内存存储。
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]memoryBucket)}
+}
+
+// Take 实现 Store。
+func (s *MemoryStore) Take(key string, policy Policy, now time.Time) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	current, ok := s.buckets[key]
+	bucket, res := current.Bucket.Take(policy, now, ok)
+	s.buckets[key] = memoryBucket{Bucket: bucket, full: now.Add(res.Reset)}
+	return res
+}
+
+// Len 返回当前保存的桶数量。
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// sweep 删除已回满的桶：它们与新建的桶等价，避免长期运行时按 IP 无限增长。
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// Limiter 按策略对键限流。
+type Limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+// New 创建使用指定存储的限流器。
+func New(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// WithClock 注入时钟，便于测试令牌补充。
+func (l *Limiter) WithClock(now func() time.Time) *Limiter {
+	l.now = now
+	return l
+}
+
+// Allow 为 key 取走一个令牌，返回是否放行及限流状态。
+func (l *Limiter) Allow(key string, policy Policy) Result {
+	return l.store.Take(key, policy, l.now())
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// fakeClock 手动推进的时钟。
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+		limiter := New(NewMemoryStore()).WithClock(clock.Now)
+		policy := Policy{Requests: 6, Period: time.Minute, Burst: 3}
+
+		// 新桶满容量，可以连续取走 Burst 个令牌
+		for i := 2; i >= 0; i-- {
+			res := limiter.Allow("ip:1.2.3.4", policy)
+			t.Assert(res.Allowed, true)
+			t.Assert(res.Limit, 3)
+			t.Assert(res.Remaining, i)
+		}
+		res := limiter.Allow("ip:1.2.3.4", policy)
+		t.Assert(res.Allowed, false)
+		t.Assert(res.Remaining, 0)
+		t.Assert(res.RetryAfter, 10*time.Second)
+		t.Assert(res.Reset, 30*time.Second)
+
+		// 其他键互不影响
+		t.Assert(limiter.Allow("ip:5.6.7.8", policy).Allowed, true)
+
+		// 每 10 秒补充一个令牌
+		clock.Advance(4 * time.Second)
+		res = limiter.Allow("ip:1.2.3.4", policy)
+		t.Assert(res.Allowed, false)
+		t.Assert(res.RetryAfter, 6*time.Second)
+		clock.Advance(6 * time.Second)
+		res = limiter.Allow("ip:1.2.3.4", policy)
+		t.Assert(res.Allowed, true)
+		t.Assert(res.Remaining, 0)
+
+		// 补充不超过桶容量
+		clock.Advance(time.Hour)
+		res = limiter.Allow("ip:1.2.3.4", policy)
+		t.Assert(res.Remaining, 2)
+		t.Assert(res.Reset, 10*time.Second)
+	})
+}
+
+func TestPolicy_DefaultBurst(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		limiter := New(NewMemoryStore())
+		policy := Policy{Requests: 2, Period: time.Hour}
+		t.Assert(policy.Valid(), true)
+		t.Assert(limiter.Allow("k", policy).Limit, 2)
+		t.Assert(limiter.Allow("k", policy).Allowed, true)
+		t.Assert(limiter.Allow("k", policy).Allowed, false)
+
+		t.Assert(Policy{Period: time.Second}.Valid(), false)
+		t.Assert(Policy{Requests: 1}.Valid(), false)
+	})
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+		store := NewMemoryStore()
+		limiter := New(store).WithClock(clock.Now)
+		policy := Policy{Requests: 1, Period: time.Minute, Burst: 5}
+
+		limiter.Allow("a", policy)
+		clock.Advance(30 * time.Second)
+		for i := 0; i < 5; i++ {
+			limiter.Allow("b", policy)
+		}
+		t.Assert(store.Len(), 2)
+
+		// 清理时删除已回满的桶，未回满的桶保留
+		clock.Advance(90 * time.Second)
+		limiter.Allow("c", policy)
+		_, hasA := store.buckets["a"]
+		_, hasB := store.buckets["b"]
+		t.Assert(hasA, false)
+		t.Assert(hasB, true)
+		t.Assert(store.Len(), 2)
+	})
+}
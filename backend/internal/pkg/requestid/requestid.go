@@ -0,0 +1,55 @@
+// Package requestid 生成与传递请求 ID，用于关联同一请求的日志、审计事件与错误响应。
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header 携带请求 ID 的请求头与响应头。
+const Header = "X-Request-ID"
+
+// maxLength 接受的外部请求 ID 最大长度，超出或含非法字符时重新生成。
+const maxLength = 128
+
+type ctxKey string
+
+// CtxKey 请求 ID 在 context 中的键，可交给 glog.SetCtxKeys 输出到日志。
+const CtxKey ctxKey = "request_id"
+
+// New 生成 32 位十六进制的随机请求 ID。
+func New() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Valid 判断外部传入的请求 ID 是否可以沿用：长度 1-128，仅含字母、数字与 - _ . :。
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext 返回携带请求 ID 的 context。
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, CtxKey, id)
+}
+
+// FromContext 返回 context 中的请求 ID，不存在时为空字符串。
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(CtxKey).(string)
+	return id
+}
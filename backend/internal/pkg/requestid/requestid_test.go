@@ -0,0 +1,32 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func TestValid(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		for _, id := range []string{"abc", "0f3a-9b_c.d:e", strings.Repeat("a", 128)} {
+			t.Assert(Valid(id), true)
+		}
+		for _, id := range []string{"", strings.Repeat("a", 129), "has space", "换行\n", "a/b", "<script>"} {
+			t.Assert(Valid(id), false)
+		}
+	})
+}
+
+func TestNewAndContext(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		a, b := New(), New()
+		t.Assert(len(a), 32)
+		t.Assert(Valid(a), true)
+		t.AssertNE(a, b)
+
+		t.Assert(FromContext(context.Background()), "")
+		t.Assert(FromContext(NewContext(context.Background(), a)), a)
+	})
+}
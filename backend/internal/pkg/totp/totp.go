@@ -0,0 +1,108 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RFC 6238 默认参数：HMAC-SHA1、30 秒步长、6 位数字。
+const (
+	Period     = 30
+	Digits     = 6
+	secretSize = 20
+)
+
+var (
+	ErrInvalidSecret = errors.New("TOTP 密钥格式无效")
+	ErrInvalidCode   = errors.New("TOTP 验证码无效")
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret 生成 160 位随机密钥，返回无填充的 Base32 字符串。
+func GenerateSecret() (string, error) {
+	buf := make([]byte, secretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return b32.EncodeToString(buf), nil
+}
+
+// URI 生成认证器 App 可扫描的 otpauth:// 地址。
+func URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprintf("%d", Digits))
+	q.Set("period", fmt.Sprintf("%d", Period))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// Step 返回给定时间所在的时间步。
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// CodeAt 计算指定时间步的验证码。
+func CodeAt(secret string, step int64) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, bin%1000000), nil
+}
+
+// Generate 计算给定时间的验证码。
+func Generate(secret string, t time.Time) (string, error) {
+	return CodeAt(secret, Step(t))
+}
+
+// Validate 校验验证码，允许前后 skew 个时间步的时钟偏差，返回匹配到的时间步。
+// 调用方应记录返回的时间步并拒绝不大于它的后续验证码，防止重放。
+func Validate(secret, code string, t time.Time, skew int) (int64, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, ErrInvalidCode
+	}
+	current := Step(t)
+	for delta := -skew; delta <= skew; delta++ {
+		step := current + int64(delta)
+		expected, err := CodeAt(secret, step)
+		if err != nil {
+			return 0, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+	return 0, ErrInvalidCode
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	normalized = strings.TrimRight(normalized, "=")
+	key, err := b32.DecodeString(normalized)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
@@ -0,0 +1,65 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// RFC 6238 附录 B 中 SHA1 的测试向量（取末 6 位）。
+func TestGenerate_RFC6238Vectors(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+		cases := []struct {
+			unix int64
+			want string
+		}{
+			{unix: 59, want: "287082"},
+			{unix: 1111111109, want: "081804"},
+			{unix: 1111111111, want: "050471"},
+			{unix: 1234567890, want: "005924"},
+			{unix: 2000000000, want: "279037"},
+		}
+		for _, c := range cases {
+			code, err := Generate(secret, time.Unix(c.unix, 0))
+			t.AssertNil(err)
+			t.Assert(code, c.want)
+		}
+	})
+}
+
+func TestValidate_Skew(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		secret, err := GenerateSecret()
+		t.AssertNil(err)
+		now := time.Unix(1700000000, 0)
+
+		prev, err := Generate(secret, now.Add(-Period*time.Second))
+		t.AssertNil(err)
+		step, err := Validate(secret, prev, now, 1)
+		t.AssertNil(err)
+		t.Assert(step, Step(now)-1)
+
+		old, err := Generate(secret, now.Add(-3*Period*time.Second))
+		t.AssertNil(err)
+		_, err = Validate(secret, old, now, 1)
+		t.Assert(err, ErrInvalidCode)
+
+		_, err = Validate(secret, "12345", now, 1)
+		t.Assert(err, ErrInvalidCode)
+		_, err = Validate("!!!", "123456", now, 1)
+		t.Assert(err, ErrInvalidSecret)
+	})
+}
+
+func TestURI(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		uri := URI("go-study2", "admin", "JBSWY3DPEHPK3PXP")
+		t.Assert(strings.HasPrefix(uri, "otpauth://totp/go-study2:admin?"), true)
+		t.AssertIN("secret=JBSWY3DPEHPK3PXP", uri)
+		t.AssertIN("issuer=go-study2", uri)
+	})
+}
@@ -2,20 +2,27 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"github.com/gogf/gf/v2/os/gctx"
 	"go-study2/internal/app/constants"
 	"go-study2/internal/app/http_server"
+	"go-study2/internal/app/http_server/handler"
 	"go-study2/internal/app/lexical_elements"
+	"go-study2/internal/app/notebook"
 	"go-study2/internal/config"
+	"go-study2/internal/infrastructure/audit"
 	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/tracing"
 	appjwt "go-study2/internal/pkg/jwt"
 	typescli "go-study2/src/learning/types/cli"
 	varcli "go-study2/src/learning/variables/cli"
 	"io"
 	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -69,6 +76,14 @@ func NewApp(stdin io.Reader, stdout, stderr io.Writer) *App {
 				Description: "Types",
 				Action:      typescli.DisplayMenu,
 			},
+			"a": {
+				Description: "Add note",
+				Action:      notebook.AddNote,
+			},
+			"m": {
+				Description: "My notes",
+				Action:      notebook.MyNotes,
+			},
 			// Add new items here
 		},
 	}
@@ -119,8 +134,21 @@ func (a *App) Run() {
 func main() {
 	daemon := flag.Bool("d", false, "Run in daemon/HTTP mode")
 	flag.BoolVar(daemon, "daemon", false, "Run in daemon/HTTP mode")
+	verifyAudit := flag.Bool("verify-audit", false, "Verify the audit log hash chain and exit")
+	exportBook := flag.String("export-book", "", "Export the course as an e-book (.epub or .html) to the given path and exit")
+	bookQuiz := flag.Bool("book-quiz", false, "Include quizzes and an answer key appendix in the exported e-book")
 	flag.Parse()
 
+	if *verifyAudit {
+		os.Exit(runVerifyAudit(os.Stdout, os.Stderr))
+	}
+	if *exportBook != "" {
+		os.Exit(runExportBook(*exportBook, *bookQuiz, os.Stdout, os.Stderr))
+	}
+	if flag.Arg(0) == "build-site" {
+		os.Exit(runBuildSite(flag.Args()[1:], os.Stdout, os.Stderr))
+	}
+
 	if *daemon {
 		runHttpServer()
 	} else {
@@ -137,20 +165,25 @@ func runHttpServer() {
 		os.Exit(1)
 	}
 
-	// 初始化数据库
+	// 初始化链路追踪，需早于数据库与服务器以覆盖启动阶段的查询
 	ctx := gctx.New()
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to init tracing: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 初始化数据库
 	if _, err = database.Init(ctx, cfg.Database); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to init database: %v\n", err)
 		os.Exit(1)
 	}
 
 	// 配置 JWT
-	_ = appjwt.Configure(appjwt.Options{
-		Secret:             cfg.Jwt.Secret,
-		Issuer:             cfg.Jwt.Issuer,
-		AccessTokenExpiry:  time.Duration(cfg.Jwt.AccessTokenExpiry) * time.Second,
-		RefreshTokenExpiry: time.Duration(cfg.Jwt.RefreshTokenExpiry) * time.Second,
-	})
+	if err = appjwt.Configure(jwtOptions(cfg.Jwt)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to configure JWT: %v\n", err)
+		os.Exit(1)
+	}
 
 	// 初始化服务器
 	s, err := http_server.NewServer(cfg)
@@ -159,6 +192,132 @@ func runHttpServer() {
 		os.Exit(1)
 	}
 
+	// 收到停止信号后先摘流再关闭
+	http_server.DrainOnShutdown(time.Duration(cfg.Health.DrainSeconds) * time.Second)
+
 	// 启动服务器 (Run 会阻塞直到收到停止信号)
 	s.Run()
+
+	// 导出尚未发送的 span
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to flush traces: %v\n", err)
+	}
+}
+
+// runVerifyAudit 校验审计日志哈希链，完整时返回 0，发现篡改或出错时返回 1。
+func runVerifyAudit(stdout, stderr io.Writer) int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+	ctx := gctx.New()
+	if _, err = database.Init(ctx, cfg.Database); err != nil {
+		fmt.Fprintf(stderr, "Failed to init database: %v\n", err)
+		return 1
+	}
+
+	report, err := audit.Verify(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to verify audit log: %v\n", err)
+		return 1
+	}
+	if !report.OK {
+		fmt.Fprintf(stderr, "Audit chain BROKEN at id=%d (%s): %s\n", report.BrokenAt, report.Reason, report.Message)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Audit chain OK: %d sealed events verified, %d legacy events skipped\n", report.Checked, report.Legacy)
+	return 0
+}
+
+// runExportBook 按文件扩展名导出 EPUB 或单文件 HTML 课程电子书，成功返回 0。
+// 设置 SOURCE_DATE_EPOCH 时以其作为 EPUB 的修改时间，便于可重复构建。
+func runExportBook(path string, quiz bool, stdout, stderr io.Writer) int {
+	var format string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".epub":
+		format = "epub"
+	case ".html", ".htm":
+		format = "html"
+	default:
+		fmt.Fprintf(stderr, "Unsupported e-book extension %q, use .epub or .html\n", filepath.Ext(path))
+		return 1
+	}
+
+	opts := handler.BookOptions{Quiz: quiz}
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		sec, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			fmt.Fprintf(stderr, "Invalid SOURCE_DATE_EPOCH: %v\n", err)
+			return 1
+		}
+		opts.Modified = time.Unix(sec, 0)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to create %s: %v\n", path, err)
+		return 1
+	}
+	if err = handler.WriteBook(f, format, opts); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to export e-book: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "E-book written to %s\n", path)
+	return 0
+}
+
+// runBuildSite 执行 build-site 子命令，把课程渲染为静态站点，成功返回 0。
+func runBuildSite(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("build-site", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	out := fs.String("out", "site", "Output directory")
+	baseURL := fs.String("base-url", "", "Absolute URL the site is served from, used in sitemap.xml")
+	force := fs.Bool("force", false, "Re-render every page even if its content is unchanged")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	report, err := handler.BuildSite(*out, handler.SiteOptions{BaseURL: *baseURL, Force: *force})
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to build site: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Site built in %s: %d rendered, %d unchanged, %d removed\n", *out, report.Rendered, report.Unchanged, report.Removed)
+	return 0
+}
+
+// jwtOptions 将配置文件中的 JWT 配置转换为签名参数，密钥材料由 appjwt 从文件或环境变量加载。
+func jwtOptions(cfg config.JwtConfig) appjwt.Options {
+	keys := make([]appjwt.KeyOptions, 0, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		var retireAt time.Time
+		if k.RetireAt != "" {
+			// 格式已在 config.Validate 中校验
+			retireAt, _ = time.Parse(time.RFC3339, k.RetireAt)
+		}
+		keys = append(keys, appjwt.KeyOptions{
+			ID:        k.Id,
+			Algorithm: k.Algorithm,
+			File:      k.File,
+			Env:       k.Env,
+			RetireAt:  retireAt,
+		})
+	}
+	return appjwt.Options{
+		Secret:             cfg.Secret,
+		Keys:               keys,
+		ActiveKeyID:        cfg.ActiveKeyId,
+		Issuer:             cfg.Issuer,
+		Audience:           cfg.Audience,
+		AccessTokenExpiry:  time.Duration(cfg.AccessTokenExpiry) * time.Second,
+		RefreshTokenExpiry: time.Duration(cfg.RefreshTokenExpiry) * time.Second,
+	}
 }
@@ -267,3 +267,62 @@ type errorReader struct{}
 func (e *errorReader) Read(p []byte) (n int, err error) {
 	return 0, fmt.Errorf("simulated read error")
 }
+
+func TestRunExportBook(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	path := dir + "/course.epub"
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if code := runExportBook(path, true, stdout, stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read exported book: %v", err)
+	}
+	if !bytes.HasPrefix(first, []byte("PK")) || !bytes.Contains(first, []byte("application/epub+zip")) {
+		t.Errorf("expected an EPUB archive at %s", path)
+	}
+
+	// 相同内容与 SOURCE_DATE_EPOCH 应得到逐字节相同的文件
+	if code := runExportBook(path, true, stdout, stderr); code != 0 {
+		t.Fatalf("expected exit code 0 on second export, got %d", code)
+	}
+	second, _ := os.ReadFile(path)
+	if !bytes.Equal(first, second) {
+		t.Errorf("expected deterministic output between exports")
+	}
+
+	if code := runExportBook(dir+"/course.pdf", false, stdout, stderr); code != 1 {
+		t.Errorf("expected exit code 1 for unsupported extension, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "Unsupported e-book extension") {
+		t.Errorf("expected unsupported extension message, got %q", stderr.String())
+	}
+}
+
+func TestRunBuildSite(t *testing.T) {
+	dir := t.TempDir()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if code := runBuildSite([]string{"-out", dir, "-base-url", "https://go.example.com"}, stdout, stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	for _, name := range []string{"index.html", "search-index.json", "sitemap.xml", "topic/types/slice.html"} {
+		if _, err := os.Stat(dir + "/" + name); err != nil {
+			t.Errorf("expected %s to be generated: %v", name, err)
+		}
+	}
+
+	stdout.Reset()
+	if code := runBuildSite([]string{"-out", dir}, stdout, stderr); code != 0 {
+		t.Fatalf("expected exit code 0 on rebuild, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), " 0 rendered") {
+		t.Errorf("expected unchanged pages to be skipped, got %q", stdout.String())
+	}
+
+	if code := runBuildSite([]string{"-unknown"}, stdout, stderr); code != 1 {
+		t.Errorf("expected exit code 1 for unknown flag, got %d", code)
+	}
+}
@@ -225,6 +225,18 @@ func LoadQuiz(topic Topic) ([]QuizItem, error) {
 	return items, nil
 }
 
+// FindRule 按规则 ID 查找已注册的类型规则。
+func FindRule(ruleID string) (TypeRule, bool) {
+	for _, rules := range ruleRegistry {
+		for _, rule := range rules {
+			if rule.RuleID == ruleID {
+				return rule, true
+			}
+		}
+	}
+	return TypeRule{}, false
+}
+
 // EvaluateQuiz 根据答案计算得分。
 func EvaluateQuiz(topic Topic, answers map[string]string) (QuizResult, error) {
 	if len(answers) == 0 {
@@ -147,7 +147,7 @@ func startGenericServer(t *testing.T) (string, *http.Client, func()) {
 		t.Fatalf("配置 JWT 失败: %v", err)
 	}
 
-	server, err := http_server.NewServer(cfg, "contract-api")
+	server, err := http_server.NewServer(cfg, fmt.Sprintf("contract-api-%d", time.Now().UnixNano()))
 	if err != nil {
 		t.Fatalf("创建服务器失败: %v", err)
 	}
@@ -158,7 +158,7 @@ func startGenericServer(t *testing.T) (string, *http.Client, func()) {
 	time.Sleep(80 * time.Millisecond)
 	baseURL := fmt.Sprintf("http://127.0.0.1:%d", server.GetListenedPort())
 	jar, _ := cookiejar.New(nil)
-	client := &http.Client{Jar: jar}
+	client := &http.Client{Jar: jar, Transport: newSpecTransport(t)}
 
 	return baseURL, client, func() { server.Shutdown() }
 }
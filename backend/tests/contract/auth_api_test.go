@@ -151,7 +151,7 @@ func startContractServer(t *testing.T) (string, *http.Client, func()) {
 	baseURL := fmt.Sprintf("http://127.0.0.1:%d", server.GetListenedPort())
 
 	jar, _ := cookiejar.New(nil)
-	client := &http.Client{Jar: jar}
+	client := &http.Client{Jar: jar, Transport: newSpecTransport(t)}
 
 	cleanup := func() {
 		server.Shutdown()
@@ -2,12 +2,18 @@ package types_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"go-study2/internal/app/http_server/handler"
 	"go-study2/internal/app/http_server/middleware"
+	"go-study2/internal/config"
+	"go-study2/internal/domain/quiz"
+	"go-study2/internal/infrastructure/database"
+	"go-study2/internal/infrastructure/repository"
 
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
@@ -17,6 +23,8 @@ import (
 // T016: 测验提交契约测试
 func TestTypesQuizContract(t *testing.T) {
 	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+
 		s := g.Server("test-types-quiz")
 		s.SetPort(0)
 		s.SetAccessLogEnabled(false)
@@ -34,8 +42,29 @@ func TestTypesQuizContract(t *testing.T) {
 		client := g.Client()
 		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", port))
 
-		payload := []byte(`{"answers":[{"id":"q-all-1","choice":"A"},{"id":"q-all-2","choice":"B"},{"id":"q-all-3","choice":"A"},{"id":"q-all-4","choice":"A"},{"id":"q-all-5","choice":"A"}]}`)
-		resp, err := client.Post(nil, "/api/v1/topic/types/quiz/submit?format=json", bytes.NewReader(payload))
+		// 未初始化数据库时只按内置题目评分
+		resp, err := client.Post(nil, "/api/v1/topic/types/quiz/submit?format=json", bytes.NewReader([]byte(`{"answers":[{"id":"q-all-1","choice":"A"}]}`)))
+		t.AssertNil(err)
+		var builtin handler.Response
+		err = json.Unmarshal(resp.ReadAll(), &builtin)
+		resp.Close()
+		t.AssertNil(err)
+		t.Assert(builtin.Code, 20000)
+		t.Assert(builtin.Data.(map[string]interface{})["total"], 5)
+
+		dbPath := filepath.Join(t.TempDir(), "types_quiz.db")
+		db, err := database.Init(ctx, config.DatabaseConfig{Type: "sqlite3", Path: dbPath})
+		t.AssertNil(err)
+		// 题库中已发布的综合测验题目与内置题目一起评分
+		bankSvc := quiz.NewService(repository.NewQuizRepository(db)).WithBank(repository.NewQuestionBankRepository(db))
+		_, err = bankSvc.CreateBankQuestion(ctx, 1, quiz.BankQuestionInput{
+			QuestionID: "all-extra-1", Topic: "types", Chapter: quiz.TypesComprehensiveChapter, Stem: "nil map 可以写入吗？",
+			Options: []string{"可以", "会 panic"}, Answer: "B", Status: quiz.BankStatusPublished,
+		})
+		t.AssertNil(err)
+
+		payload := []byte(`{"answers":[{"id":"q-all-1","choice":"A"},{"id":"q-all-2","choice":"B"},{"id":"q-all-3","choice":"A"},{"id":"q-all-4","choice":"A"},{"id":"q-all-5","choice":"A"},{"id":"all-extra-1","choice":"B"}]}`)
+		resp, err = client.Post(nil, "/api/v1/topic/types/quiz/submit?format=json", bytes.NewReader(payload))
 		t.AssertNil(err)
 		defer resp.Close()
 		t.Assert(resp.StatusCode, 200)
@@ -45,6 +74,9 @@ func TestTypesQuizContract(t *testing.T) {
 		t.AssertNil(err)
 		t.Assert(result.Code, 20000)
 		data := result.Data.(map[string]interface{})
-		t.AssertGT(data["score"], 0)
+		t.Assert(data["score"], 6)
+		t.Assert(data["total"], 6)
+		details := data["details"].([]interface{})
+		t.Assert(details[5].(map[string]interface{})["id"], "all-extra-1")
 	})
 }
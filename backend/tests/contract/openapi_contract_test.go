@@ -0,0 +1,348 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go-study2/internal/app/http_server"
+	"go-study2/internal/app/http_server/handler"
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+	"go-study2/internal/pkg/requestid"
+
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// specTransport 在每次响应返回后按 OpenAPI 文档校验状态码、内容类型与 JSON 结构，
+// 使契约测试中的所有请求都顺带校验文档与实现是否一致。
+type specTransport struct {
+	t    *testing.T
+	base http.RoundTripper
+}
+
+func newSpecTransport(t *testing.T) http.RoundTripper {
+	return &specTransport{t: t, base: http.DefaultTransport}
+}
+
+func (s *specTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := s.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	contentType := resp.Header.Get("Content-Type")
+	var body []byte
+	// 事件流等非 JSON 响应不读取响应体，避免阻塞长连接
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/json" {
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if err := handler.OpenAPIDocument().ValidateResponse(req.Method, req.URL.Path, resp.StatusCode, contentType, body); err != nil {
+		s.t.Errorf("响应与 OpenAPI 文档不一致: %v\n响应体: %s", err, body)
+	}
+	// 每个响应都带请求 ID，错误响应体中的 requestId 与响应头一致
+	requestID := resp.Header.Get(requestid.Header)
+	if requestID == "" {
+		s.t.Errorf("%s %s 响应缺少 %s", req.Method, req.URL.Path, requestid.Header)
+	}
+	var envelope struct {
+		RequestID *string `json:"requestId"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.RequestID != nil && *envelope.RequestID != requestID {
+		s.t.Errorf("%s %s 响应体 requestId = %s，响应头为 %s", req.Method, req.URL.Path, *envelope.RequestID, requestID)
+	}
+	return resp, nil
+}
+
+var routeParam = regexp.MustCompile(`:([A-Za-z]+)`)
+
+// rootRoutes 为不带 /api/v1 前缀的运维接口。
+var rootRoutes = map[string]bool{"/metrics": true, "/healthz": true, "/readyz": true}
+
+// TestOpenAPI_RoutesDocumented 验证路由表与文档中的接口一一对应。
+func TestOpenAPI_RoutesDocumented(t *testing.T) {
+	ensureConfigPath()
+	cfg := &config.Config{
+		Server:   config.ServerConfig{Host: "127.0.0.1"},
+		Http:     config.HttpConfig{Port: 0},
+		Database: config.DatabaseConfig{Type: "sqlite3", Path: filepath.ToSlash(filepath.Join("testdata", "contract_openapi_routes.db"))},
+	}
+	server, err := http_server.NewServer(cfg, fmt.Sprintf("contract-openapi-routes-%d", time.Now().UnixNano()))
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	// 分组路由在启动时才完成注册
+	server.SetPort(0)
+	server.SetAccessLogEnabled(false)
+	server.Start()
+	defer server.Shutdown()
+	doc := handler.OpenAPIDocument()
+
+	registered := make(map[string]bool)
+	for _, item := range server.GetRoutes() {
+		if item.Type != ghttp.HandlerTypeHandler {
+			continue
+		}
+		if !strings.HasPrefix(item.Route, "/api/v1/") && !strings.HasPrefix(item.Route, "/.well-known/") && !rootRoutes[item.Route] {
+			continue
+		}
+		path := routeParam.ReplaceAllString(strings.TrimSuffix(item.Route, "/"), "{$1}")
+		pathItem, ok := doc.Paths[path]
+		if !ok {
+			t.Errorf("路由 %s %s 未写入文档", item.Method, path)
+			continue
+		}
+		if item.Method == "ALL" {
+			if len(*pathItem) == 0 {
+				t.Errorf("路由 %s 未声明任何方法", path)
+			}
+			for method := range *pathItem {
+				registered[strings.ToUpper(method)+" "+path] = true
+			}
+			continue
+		}
+		if _, ok := (*pathItem)[strings.ToLower(item.Method)]; !ok {
+			t.Errorf("路由 %s %s 未写入文档", item.Method, path)
+		}
+		registered[item.Method+" "+path] = true
+	}
+	for path, item := range doc.Paths {
+		for method := range *item {
+			if !registered[strings.ToUpper(method)+" "+path] {
+				t.Errorf("文档中的 %s %s 没有对应路由", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+// TestOpenAPI_Served 验证文档与文档页面可以直接访问。
+func TestOpenAPI_Served(t *testing.T) {
+	baseURL, client, shutdown := startGenericServer(t)
+	defer shutdown()
+
+	resp, err := client.Get(baseURL + "/api/v1/openapi.json")
+	if err != nil {
+		t.Fatalf("请求 openapi.json 失败: %v", err)
+	}
+	var spec struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					Enum []int `json:"enum"`
+				} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&spec)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("解析 openapi.json 失败: %v", err)
+	}
+	if spec.OpenAPI != "3.1.0" {
+		t.Fatalf("openapi 版本错误: %q", spec.OpenAPI)
+	}
+	for _, name := range []string{"quiz.Result", "handler.progressRequest", "Error"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Fatalf("文档缺少结构 %s", name)
+		}
+	}
+	if codes := spec.Components.Schemas["Error"].Properties["code"].Enum; len(codes) == 0 {
+		t.Fatalf("Error.code 应列出错误码")
+	}
+
+	resp, err = client.Get(baseURL + "/api/v1/docs")
+	if err != nil {
+		t.Fatalf("请求文档页面失败: %v", err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || !bytes.Contains(page, []byte("/api/v1/openapi.json")) {
+		t.Fatalf("文档页面内容异常: %s", resp.Header.Get("Content-Type"))
+	}
+}
+
+// TestOpenAPI_ResponsesMatchSpec 以管理员身份访问各类接口的成功与失败路径，由 specTransport 校验每个响应。
+func TestOpenAPI_ResponsesMatchSpec(t *testing.T) {
+	baseURL, client, shutdown := startGenericServer(t)
+	defer shutdown()
+
+	call := func(method, path, token, payload string) contractAPIResp {
+		t.Helper()
+		req := mustNewRequest(t, method, baseURL+"/api/v1"+path, payload)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("请求 %s %s 失败: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		var body contractAPIResp
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+			_ = json.NewDecoder(resp.Body).Decode(&body)
+		} else {
+			_, _ = io.Copy(io.Discard, resp.Body)
+		}
+		return body
+	}
+	dataID := func(r contractAPIResp) int64 {
+		var v struct {
+			ID int64 `json:"id"`
+		}
+		_ = json.Unmarshal(r.Data, &v)
+		return v.ID
+	}
+
+	login := call(http.MethodPost, "/auth/login", "", fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword))
+	var auth struct {
+		AccessToken        string `json:"accessToken"`
+		NeedPasswordChange bool   `json:"needPasswordChange"`
+	}
+	_ = json.Unmarshal(login.Data, &auth)
+	if auth.NeedPasswordChange {
+		if r := call(http.MethodPost, "/auth/change-password", auth.AccessToken, fmt.Sprintf(`{"oldPassword":"%s","newPassword":"OpenAPI123!"}`, user.DefaultAdminPassword)); r.Code != 20000 {
+			t.Fatalf("改密失败: %s", r.Message)
+		}
+		login = call(http.MethodPost, "/auth/login", "", `{"username":"`+user.DefaultAdminUsername+`","password":"OpenAPI123!"}`)
+		_ = json.Unmarshal(login.Data, &auth)
+	}
+	admin := auth.AccessToken
+	if admin == "" {
+		t.Fatalf("管理员登录失败: %s", login.Message)
+	}
+
+	// 认证与账号
+	call(http.MethodPost, "/auth/login", "", `{"username":"nobody","password":"x"}`)
+	call(http.MethodGet, "/auth/profile", "", "")
+	call(http.MethodGet, "/auth/profile", admin, "")
+	call(http.MethodGet, "/auth/export", admin, "")
+	call(http.MethodGet, "/auth/mfa", admin, "")
+	call(http.MethodGet, "/auth/oidc/providers", "", "")
+	call(http.MethodGet, "/auth/oidc/missing/login", "", "")
+	tokenResp := call(http.MethodPost, "/auth/tokens", admin, `{"name":"ci","scopes":["progress:read"],"expiresIn":3600}`)
+	call(http.MethodGet, "/auth/tokens", admin, "")
+	call(http.MethodDelete, fmt.Sprintf("/auth/tokens/%d", dataID(tokenResp)), admin, "")
+	call(http.MethodDelete, "/auth/tokens/999999", admin, "")
+	invite := call(http.MethodPost, "/admin/invites", admin, `{"role":"teacher"}`)
+	call(http.MethodGet, "/admin/invites", admin, "")
+	call(http.MethodGet, fmt.Sprintf("/admin/invites/%d/redemptions", dataID(invite)), admin, "")
+	call(http.MethodPost, "/auth/register", admin, `{"username":"openapi_student","password":"OpenApiPass123!"}`)
+
+	// 学习内容与测验
+	call(http.MethodGet, "/topics", "", "")
+	call(http.MethodGet, "/topic/lexical_elements", "", "")
+	call(http.MethodGet, "/topic/lexical_elements/comments", "", "")
+	call(http.MethodGet, "/topic/lexical_elements/comments?format=html", "", "")
+	call(http.MethodGet, "/topic/lexical_elements/missing", "", "")
+	call(http.MethodGet, "/topic/lexical_elements/missing?format=markdown", "", "")
+	call(http.MethodGet, "/topic/variables/storage?format=markdown", "", "")
+	call(http.MethodGet, "/topic/types/outline?format=text", "", "")
+	call(http.MethodGet, "/topic/types/missing?format=text", "", "")
+	call(http.MethodGet, "/topics?format=xml", "", "")
+	call(http.MethodGet, "/topic/constants/iota", "", "")
+	call(http.MethodGet, "/topic/variables/storage", "", "")
+	call(http.MethodGet, "/topic/types/slice", "", "")
+	call(http.MethodGet, "/topic/types/outline", "", "")
+	call(http.MethodGet, "/topic/types/search?keyword=slice", "", "")
+	// 学习内容带 ETag，条件请求命中时返回文档声明的 304
+	req := mustNewRequest(t, http.MethodGet, baseURL+"/api/v1/topic/types/slice", "")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("请求学习内容失败: %v", err)
+	}
+	resp.Body.Close()
+	req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+	if resp, err = client.Do(req); err != nil || resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("条件请求未返回 304: %v %v", resp, err)
+	}
+	resp.Body.Close()
+	call(http.MethodPost, "/topic/types/quiz/submit", "", `{"answers":[{"id":"q-slice-1","choice":"A"}]}`)
+	call(http.MethodGet, "/export/book", "", "")
+	call(http.MethodGet, "/export/book?format=html&quiz=true", "", "")
+	call(http.MethodGet, "/export/book?format=pdf", "", "")
+	// 指标端点位于 /api/v1 之外，未配置令牌时可直接抓取
+	if resp, err = client.Do(mustNewRequest(t, http.MethodGet, baseURL+"/metrics", "")); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("抓取指标失败: %v %v", resp, err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") || !strings.Contains(string(body), `gostudy_http_requests_total{method="GET",route="/api/v1/topics",status="200"}`) ||
+		!strings.Contains(string(body), `gostudy_db_query_duration_seconds_count{operation="query"}`) {
+		t.Fatalf("指标输出不符合预期: %s", body)
+	}
+	// 存活与就绪探针，测试服务已完成迁移与内容加载，均应返回 200
+	for _, probe := range []string{"/healthz", "/readyz"} {
+		if resp, err = client.Do(mustNewRequest(t, http.MethodGet, baseURL+probe, "")); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("探针 %s 未就绪: %v %v", probe, resp, err)
+		}
+		resp.Body.Close()
+	}
+	call(http.MethodGet, "/quiz/variables/storage", admin, "")
+	call(http.MethodPost, "/quiz/submit", admin, `{"topic":"variables","chapter":"storage","answers":[]}`)
+	call(http.MethodGet, "/quiz/history", admin, "")
+	call(http.MethodGet, "/quiz/history/variables", admin, "")
+	call(http.MethodPost, "/progress", admin, `{"topic":"variables","chapter":"storage","status":"done"}`)
+	call(http.MethodPost, "/progress", admin, `{}`)
+	call(http.MethodGet, "/progress", admin, "")
+	call(http.MethodGet, "/progress/variables", admin, "")
+
+	// 班级、作业与排行榜
+	class := call(http.MethodPost, "/classes", admin, `{"name":"OpenAPI 班"}`)
+	classID := dataID(class)
+	call(http.MethodGet, "/classes", admin, "")
+	call(http.MethodGet, fmt.Sprintf("/classes/%d", classID), admin, "")
+	call(http.MethodGet, "/classes/999999", admin, "")
+	call(http.MethodGet, fmt.Sprintf("/classes/%d/dashboard", classID), admin, "")
+	assignment := call(http.MethodPost, fmt.Sprintf("/classes/%d/assignments", classID), admin,
+		`{"title":"作业","topic":"variables","chapter":"storage","dueAt":"2099-01-01T00:00:00Z"}`)
+	call(http.MethodGet, fmt.Sprintf("/classes/%d/assignments", classID), admin, "")
+	call(http.MethodGet, fmt.Sprintf("/classes/%d/assignments/%d/report", classID, dataID(assignment)), admin, "")
+	call(http.MethodGet, fmt.Sprintf("/classes/%d/assignments/%d/report?export=csv", classID, dataID(assignment)), admin, "")
+	call(http.MethodGet, "/assignments", admin, "")
+	call(http.MethodGet, "/leaderboards?metric=mastered&window=week", admin, "")
+	call(http.MethodGet, "/leaderboards/preferences", admin, "")
+
+	// 题库、笔记、讨论与通知
+	call(http.MethodGet, "/admin/questions", admin, "")
+	call(http.MethodPost, "/admin/questions", admin, `{"questionId":"openapi-q","topic":"constants","chapter":"iota","stem":"iota 从几开始？","options":["0","1"],"answer":"A"}`)
+	call(http.MethodPost, "/admin/questions", admin, `{"topic":"constants"}`)
+	note := call(http.MethodPost, "/notes", admin, `{"topic":"variables","chapter":"storage","body":"笔记"}`)
+	call(http.MethodGet, "/notes", admin, "")
+	call(http.MethodGet, fmt.Sprintf("/notes/%d", dataID(note)), admin, "")
+	call(http.MethodGet, "/notes/999999", admin, "")
+	thread := call(http.MethodPost, "/discussions", admin, `{"topic":"types","chapter":"slice","title":"扩容","body":"为什么？"}`)
+	call(http.MethodGet, "/discussions?topic=types&chapter=slice", admin, "")
+	call(http.MethodGet, fmt.Sprintf("/discussions/%d", dataID(thread)), admin, "")
+	call(http.MethodPost, fmt.Sprintf("/discussions/%d/posts", dataID(thread)), admin, `{"body":"回复"}`)
+	call(http.MethodGet, "/mentions", admin, "")
+	call(http.MethodGet, "/notifications", admin, "")
+	call(http.MethodPost, "/notifications/read", admin, `{}`)
+	call(http.MethodGet, "/notifications/preferences", admin, "")
+
+	// 审计、Webhook 与定时任务
+	call(http.MethodGet, "/admin/audit", admin, "")
+	call(http.MethodGet, "/admin/audit?export=csv", admin, "")
+	call(http.MethodGet, "/admin/audit?export=jsonl", admin, "")
+	call(http.MethodGet, "/admin/audit/verify", admin, "")
+	call(http.MethodGet, "/admin/webhooks", admin, "")
+	call(http.MethodPost, "/admin/webhooks", admin, `{"url":"not a url"}`)
+	call(http.MethodGet, "/admin/webhooks/999999", admin, "")
+	call(http.MethodGet, "/admin/jobs", admin, "")
+	call(http.MethodGet, "/admin/jobs/token_cleanup/runs", admin, "")
+	call(http.MethodGet, "/admin/jobs/missing/runs", admin, "")
+	call(http.MethodGet, "/openapi.json", "", "")
+	call(http.MethodGet, "/admin/jobs", "", "")
+}
@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestAccessTokenFlow_ScopesAndRevocation(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_pat", nil)
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	type loginData struct {
+		AccessToken string `json:"accessToken"`
+	}
+	first := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword))
+	var firstData loginData
+	_ = json.Unmarshal(first.Data, &firstData)
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", firstData.AccessToken,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"TokenAdmin123!"}`, user.DefaultAdminPassword))
+	login := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"TokenAdmin123!"}`)
+	var session loginData
+	_ = json.Unmarshal(login.Data, &session)
+
+	invalid := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/tokens", session.AccessToken, `{"name":"ci","scopes":["root"]}`)
+	if invalid.Code != 40004 {
+		t.Fatalf("未知权限范围应返回 40004，得到 code=%d", invalid.Code)
+	}
+
+	created := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/tokens", session.AccessToken, `{"name":"ci","scopes":["progress:read","quiz:read"],"expiresIn":3600}`)
+	var tokenData struct {
+		Token       string `json:"token"`
+		AccessToken struct {
+			ID     int64    `json:"id"`
+			Prefix string   `json:"prefix"`
+			Scopes []string `json:"scopes"`
+		} `json:"accessToken"`
+	}
+	_ = json.Unmarshal(created.Data, &tokenData)
+	if created.Code != 20000 || !strings.HasPrefix(tokenData.Token, user.AccessTokenPrefix) {
+		t.Fatalf("创建访问令牌失败: code=%d %s", created.Code, created.Message)
+	}
+	pat := tokenData.Token
+
+	if read := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/progress", pat, ""); read.Code != 20000 {
+		t.Fatalf("具备 progress:read 的令牌应可读取进度，得到 code=%d", read.Code)
+	}
+	if write := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/quiz/submit", pat, `{}`); write.Code != 40021 {
+		t.Fatalf("缺少 quiz:write 应返回 40021，得到 code=%d", write.Code)
+	}
+	if manage := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/tokens", pat, ""); manage.Code != 40021 {
+		t.Fatalf("访问令牌不可管理令牌，得到 code=%d", manage.Code)
+	}
+
+	list := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/tokens", session.AccessToken, "")
+	var tokens []struct {
+		ID         int64  `json:"id"`
+		LastUsedIp string `json:"lastUsedIp"`
+		LastUsedAt string `json:"lastUsedAt"`
+	}
+	_ = json.Unmarshal(list.Data, &tokens)
+	if list.Code != 20000 || len(tokens) != 1 || tokens[0].LastUsedIp == "" || tokens[0].LastUsedAt == "" {
+		t.Fatalf("令牌列表应包含最近使用信息: %s", string(list.Data))
+	}
+	if strings.Contains(string(list.Data), pat) {
+		t.Fatalf("列表不应返回令牌明文")
+	}
+
+	revoke := doAuthed(t, client, http.MethodDelete, fmt.Sprintf("%s/api/v1/auth/tokens/%d", baseURL, tokenData.AccessToken.ID), session.AccessToken, "")
+	if revoke.Code != 20000 {
+		t.Fatalf("撤销令牌失败: code=%d", revoke.Code)
+	}
+	if after := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/progress", pat, ""); after.Code != 40002 {
+		t.Fatalf("撤销后的令牌应返回 40002，得到 code=%d", after.Code)
+	}
+	if missing := doAuthed(t, client, http.MethodDelete, fmt.Sprintf("%s/api/v1/auth/tokens/%d", baseURL, tokenData.AccessToken.ID), session.AccessToken, ""); missing.Code != 40022 {
+		t.Fatalf("重复撤销应返回 40022，得到 code=%d", missing.Code)
+	}
+}
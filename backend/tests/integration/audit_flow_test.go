@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestAuditFlow_QueryExportAndVerify(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_audit", nil)
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	type loginData struct {
+		AccessToken string `json:"accessToken"`
+	}
+	login := func(username, pwd string) string {
+		resp := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", fmt.Sprintf(`{"username":"%s","password":"%s"}`, username, pwd))
+		var data loginData
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("登录失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+
+	token := login(user.DefaultAdminUsername, user.DefaultAdminPassword)
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", token,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"AuditAdmin123!"}`, user.DefaultAdminPassword))
+	doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"WrongPass123!"}`)
+	token = login("admin", "AuditAdmin123!")
+
+	list := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/admin/audit?eventType=password_changed&limit=10", token, "")
+	var page struct {
+		Items []struct {
+			EventType string `json:"eventType"`
+			Metadata  struct {
+				RequestID string `json:"requestId"`
+				IP        string `json:"ip"`
+				UserAgent string `json:"userAgent"`
+			} `json:"metadata"`
+			Hash string `json:"hash"`
+		} `json:"items"`
+		NextCursor string `json:"nextCursor"`
+	}
+	_ = json.Unmarshal(list.Data, &page)
+	if list.Code != 20000 || len(page.Items) != 1 || page.Items[0].Hash == "" {
+		t.Fatalf("按事件类型查询失败: code=%d data=%s", list.Code, string(list.Data))
+	}
+	if meta := page.Items[0].Metadata; meta.IP == "" || meta.RequestID == "" || meta.UserAgent == "" {
+		t.Fatalf("元数据应包含请求 ID、IP 与 User-Agent: %+v", meta)
+	}
+
+	first := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/admin/audit?limit=1", token, "")
+	_ = json.Unmarshal(first.Data, &page)
+	if page.NextCursor == "" {
+		t.Fatalf("存在更多记录时应返回游标")
+	}
+	if bad := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/admin/audit?from=yesterday", token, ""); bad.Code != 40004 {
+		t.Fatalf("非法时间应返回 40004，得到 code=%d", bad.Code)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, baseURL+"/api/v1/admin/audit?export=csv", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("导出请求失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/csv") || !strings.Contains(resp.Header.Get("Content-Disposition"), "attachment") {
+		t.Fatalf("CSV 导出响应头不正确: %v", resp.Header)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+	if err != nil || len(rows) < 3 || rows[0][2] != "eventType" {
+		t.Fatalf("CSV 内容不正确: %v\n%s", err, string(body))
+	}
+
+	verify := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/admin/audit/verify", token, "")
+	var report struct {
+		OK      bool `json:"ok"`
+		Checked int  `json:"checked"`
+	}
+	_ = json.Unmarshal(verify.Data, &report)
+	if verify.Code != 20000 || !report.OK || report.Checked == 0 {
+		t.Fatalf("哈希链校验应通过: %s", string(verify.Data))
+	}
+
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/register", token, `{"username":"audit_student","password":"Student123!"}`)
+	studentToken := login("audit_student", "Student123!")
+	if denied := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/admin/audit", studentToken, ""); denied.Code != 40010 {
+		t.Fatalf("非管理员应返回 40010，得到 code=%d", denied.Code)
+	}
+}
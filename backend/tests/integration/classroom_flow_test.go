@@ -0,0 +1,292 @@
+package integration
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestClassroomFlow_EnrollmentDashboardAndAccess(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_classroom", func(cfg *config.Config) {
+		cfg.Auth.Registration.Open = true
+	})
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	type tokenData struct {
+		AccessToken string `json:"accessToken"`
+	}
+	tokenOf := func(resp apiResponse) string {
+		var data tokenData
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("获取令牌失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	signup := func(username, invite string) string {
+		return tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup",
+			fmt.Sprintf(`{"username":"%s","password":"Classroom123!","inviteCode":"%s"}`, username, invite)))
+	}
+
+	first := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login",
+		fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword)))
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", first,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"ClassAdmin123!"}`, user.DefaultAdminPassword))
+	admin := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"ClassAdmin123!"}`))
+
+	created := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/admin/invites", admin, `{"role":"teacher"}`)
+	var invite struct {
+		Code string `json:"code"`
+	}
+	_ = json.Unmarshal(created.Data, &invite)
+	if created.Code != 20000 || invite.Code == "" {
+		t.Fatalf("创建教师邀请码失败: code=%d %s", created.Code, created.Message)
+	}
+	teacher := signup("class_teacher", invite.Code)
+	alice := signup("class_alice", "")
+	signup("class_bob", "")
+	outsider := signup("class_outsider", "")
+	otherTeacher := signup("class_teacher2", "")
+
+	if denied := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes", alice, `{"name":"学生建班"}`); denied.Code != 40025 {
+		t.Fatalf("学生创建班级应返回 40025，得到 code=%d", denied.Code)
+	}
+
+	createdClass := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes", teacher, `{"name":"周三学习小组"}`)
+	var class struct {
+		ID       int64  `json:"id"`
+		JoinCode string `json:"joinCode"`
+	}
+	_ = json.Unmarshal(createdClass.Data, &class)
+	if createdClass.Code != 20000 || class.ID == 0 || class.JoinCode == "" {
+		t.Fatalf("教师创建班级失败: code=%d %s", createdClass.Code, createdClass.Message)
+	}
+	classURL := fmt.Sprintf("%s/api/v1/classes/%d", baseURL, class.ID)
+
+	if joined := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes/join", alice, fmt.Sprintf(`{"code":"%s"}`, class.JoinCode)); joined.Code != 20000 {
+		t.Fatalf("学生通过加入码加入失败: code=%d %s", joined.Code, joined.Message)
+	}
+	if added := doAuthed(t, client, http.MethodPost, classURL+"/members", teacher, `{"username":"class_bob"}`); added.Code != 20000 {
+		t.Fatalf("教师按用户名添加学生失败: code=%d %s", added.Code, added.Message)
+	}
+
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/progress", alice, `{"topic":"variables","chapter":"storage","status":"done"}`)
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/progress", outsider, `{"topic":"variables","chapter":"storage","status":"done"}`)
+
+	dashboard := doAuthed(t, client, http.MethodGet, classURL+"/dashboard", teacher, "")
+	var board struct {
+		Summary struct {
+			Students int `json:"students"`
+		} `json:"summary"`
+		Students []struct {
+			Username     string  `json:"username"`
+			ChaptersDone int     `json:"chaptersDone"`
+			LastActivity *string `json:"lastActivity"`
+		} `json:"students"`
+		Chapters []struct {
+			Chapter string `json:"chapter"`
+			Done    int    `json:"done"`
+		} `json:"chapters"`
+	}
+	_ = json.Unmarshal(dashboard.Data, &board)
+	if dashboard.Code != 20000 || board.Summary.Students != 2 || len(board.Chapters) != 1 || board.Chapters[0].Done != 1 {
+		t.Fatalf("看板数据不正确: code=%d data=%s", dashboard.Code, string(dashboard.Data))
+	}
+
+	var aliceID int64
+	members := doAuthed(t, client, http.MethodGet, classURL, teacher, "")
+	var detail struct {
+		Members []struct {
+			UserID   int64  `json:"userId"`
+			Username string `json:"username"`
+		} `json:"members"`
+	}
+	_ = json.Unmarshal(members.Data, &detail)
+	for _, m := range detail.Members {
+		if m.Username == "class_alice" {
+			aliceID = m.UserID
+		}
+	}
+	if len(detail.Members) != 3 || aliceID == 0 {
+		t.Fatalf("教师应看到全部成员: %s", string(members.Data))
+	}
+	outsiderProfile := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/profile", outsider, "")
+	var profile struct {
+		ID int64 `json:"id"`
+	}
+	_ = json.Unmarshal(outsiderProfile.Data, &profile)
+
+	cases := []struct {
+		name  string
+		token string
+		url   string
+		code  int
+	}{
+		{"学生查看看板", alice, classURL + "/dashboard", 40025},
+		{"非成员查看班级", outsider, classURL, 40024},
+		{"其他教师查看看板", otherTeacher, classURL + "/dashboard", 40024},
+		{"学生查看同学报告", alice, fmt.Sprintf("%s/students/%d", classURL, profile.ID), 40025},
+		{"教师查看班级外学生", teacher, fmt.Sprintf("%s/students/%d", classURL, profile.ID), 40029},
+		{"学生查看自己的报告", alice, fmt.Sprintf("%s/students/%d", classURL, aliceID), 20000},
+		{"教师查看班级学生报告", teacher, fmt.Sprintf("%s/students/%d", classURL, aliceID), 20000},
+		{"管理员查看看板", admin, classURL + "/dashboard", 20000},
+	}
+	for _, tc := range cases {
+		if resp := doAuthed(t, client, http.MethodGet, tc.url, tc.token, ""); resp.Code != tc.code {
+			t.Fatalf("%s: 期望 code=%d，得到 code=%d %s", tc.name, tc.code, resp.Code, resp.Message)
+		}
+	}
+
+	aliceClasses := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/classes", alice, "")
+	var list []struct {
+		JoinCode string `json:"joinCode"`
+		Role     string `json:"role"`
+	}
+	_ = json.Unmarshal(aliceClasses.Data, &list)
+	if len(list) != 1 || list[0].Role != "student" || list[0].JoinCode != "" {
+		t.Fatalf("学生只应看到自己的班级且看不到加入码: %s", string(aliceClasses.Data))
+	}
+	if outsiderClasses := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/classes", outsider, ""); string(outsiderClasses.Data) != "[]" {
+		t.Fatalf("非成员不应看到班级: %s", string(outsiderClasses.Data))
+	}
+
+	if denied := doAuthed(t, client, http.MethodPut, fmt.Sprintf("%s/api/v1/admin/users/%d/teacher", baseURL, profile.ID), teacher, `{"teacher":true}`); denied.Code != 40010 {
+		t.Fatalf("非管理员不能授予教师角色，得到 code=%d", denied.Code)
+	}
+	promoted := doAuthed(t, client, http.MethodPut, fmt.Sprintf("%s/api/v1/admin/users/%d/teacher", baseURL, profile.ID), admin, `{"teacher":true}`)
+	if promoted.Code != 20000 {
+		t.Fatalf("管理员授予教师角色失败: code=%d %s", promoted.Code, promoted.Message)
+	}
+	if ok := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes", outsider, `{"name":"新班级"}`); ok.Code != 20000 {
+		t.Fatalf("被授予教师角色后应能创建班级，得到 code=%d", ok.Code)
+	}
+}
+
+func TestClassroomFlow_AssignmentsAndCSVReport(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_assignment", func(cfg *config.Config) {
+		cfg.Auth.Registration.Open = true
+	})
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	tokenOf := func(resp apiResponse) string {
+		var data struct {
+			AccessToken string `json:"accessToken"`
+		}
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("获取令牌失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	first := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login",
+		fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword)))
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", first,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"HomeworkAdmin123!"}`, user.DefaultAdminPassword))
+	teacher := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"HomeworkAdmin123!"}`))
+	alice := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"hw_alice","password":"Homework123!"}`))
+	bob := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"hw_bob","password":"Homework123!"}`))
+
+	createdClass := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes", teacher, `{"name":"作业班"}`)
+	var class struct {
+		ID       int64  `json:"id"`
+		JoinCode string `json:"joinCode"`
+	}
+	_ = json.Unmarshal(createdClass.Data, &class)
+	for _, token := range []string{alice, bob} {
+		if joined := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes/join", token, fmt.Sprintf(`{"code":"%s"}`, class.JoinCode)); joined.Code != 20000 {
+			t.Fatalf("加入班级失败: code=%d %s", joined.Code, joined.Message)
+		}
+	}
+	assignmentsURL := fmt.Sprintf("%s/api/v1/classes/%d/assignments", baseURL, class.ID)
+
+	if bad := doAuthed(t, client, http.MethodPost, assignmentsURL, teacher, `{"topic":"variables","chapter":"storage","dueAt":"不是时间"}`); bad.Code != 40004 {
+		t.Fatalf("非法截止时间应返回 40004，得到 code=%d", bad.Code)
+	}
+	if denied := doAuthed(t, client, http.MethodPost, assignmentsURL, alice, `{"topic":"variables","chapter":"storage","dueAt":"2099-01-01"}`); denied.Code != 40025 {
+		t.Fatalf("学生布置作业应返回 40025，得到 code=%d", denied.Code)
+	}
+	created := doAuthed(t, client, http.MethodPost, assignmentsURL, teacher, `{"title":"存储作业","topic":"variables","chapter":"storage","dueAt":"2099-01-01T00:00:00Z"}`)
+	var assignment struct {
+		ID int64 `json:"id"`
+	}
+	_ = json.Unmarshal(created.Data, &assignment)
+	if created.Code != 20000 || assignment.ID == 0 {
+		t.Fatalf("布置作业失败: code=%d %s", created.Code, created.Message)
+	}
+	overdue := doAuthed(t, client, http.MethodPost, assignmentsURL, teacher, `{"topic":"constants","chapter":"iota","opensAt":"2020-01-01","dueAt":"2020-01-08"}`)
+	if overdue.Code != 20000 {
+		t.Fatalf("布置已截止作业失败: code=%d %s", overdue.Code, overdue.Message)
+	}
+
+	quizResp := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/quiz/variables/storage", alice, "")
+	var questions []struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(quizResp.Data, &questions)
+	if len(questions) == 0 {
+		t.Fatalf("题目列表为空: code=%d", quizResp.Code)
+	}
+	submit := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/quiz/submit", alice,
+		fmt.Sprintf(`{"topic":"variables","chapter":"storage","answers":[{"id":"%s","choices":["A"]}]}`, questions[0].ID))
+	if submit.Code != 20000 {
+		t.Fatalf("提交测验失败: code=%d %s", submit.Code, submit.Message)
+	}
+
+	type myAssignments struct {
+		Open      []struct{ Title string } `json:"open"`
+		Overdue   []struct{ Title string } `json:"overdue"`
+		Completed []struct {
+			Status string `json:"status"`
+		} `json:"completed"`
+	}
+	var aliceView, bobView myAssignments
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/assignments", alice, "").Data, &aliceView)
+	if len(aliceView.Completed) != 1 || aliceView.Completed[0].Status != "on_time" || len(aliceView.Overdue) != 1 {
+		t.Fatalf("alice 作业状态不正确: %+v", aliceView)
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/assignments", bob, "").Data, &bobView)
+	if len(bobView.Open) != 1 || bobView.Open[0].Title != "存储作业" || len(bobView.Overdue) != 1 {
+		t.Fatalf("bob 作业状态不正确: %+v", bobView)
+	}
+
+	reportURL := fmt.Sprintf("%s/%d/report", assignmentsURL, assignment.ID)
+	if denied := doAuthed(t, client, http.MethodGet, reportURL, alice, ""); denied.Code != 40025 {
+		t.Fatalf("学生查看作业报告应返回 40025，得到 code=%d", denied.Code)
+	}
+	if missing := doAuthed(t, client, http.MethodGet, fmt.Sprintf("%s/%d/report", assignmentsURL, assignment.ID+100), teacher, ""); missing.Code != 40030 {
+		t.Fatalf("不存在的作业应返回 40030，得到 code=%d", missing.Code)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, reportURL+"?export=csv", nil)
+	req.Header.Set("Authorization", "Bearer "+teacher)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("导出作业报告失败: %v", err)
+	}
+	defer resp.Body.Close()
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("解析 CSV 失败: %v", err)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Disposition"), "attachment;") || len(records) != 3 || records[0][0] != "userId" {
+		t.Fatalf("CSV 导出内容不正确: %v", records)
+	}
+	if records[1][1] != "hw_alice" || records[1][2] != "on_time" || records[1][3] == "" || records[2][1] != "hw_bob" || records[2][2] != "open" {
+		t.Fatalf("CSV 行内容不正确: %v", records)
+	}
+}
@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestDiscussionFlow_ThreadsAcceptHideAndMentions(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_discussion", func(cfg *config.Config) {
+		cfg.Auth.Registration.Open = true
+	})
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	tokenOf := func(resp apiResponse) string {
+		var data struct {
+			AccessToken string `json:"accessToken"`
+		}
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("获取令牌失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	signup := func(username, invite string) string {
+		return tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup",
+			fmt.Sprintf(`{"username":"%s","password":"Discuss123!","inviteCode":"%s"}`, username, invite)))
+	}
+
+	first := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login",
+		fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword)))
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", first,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"DiscussAdmin123!"}`, user.DefaultAdminPassword))
+	admin := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"DiscussAdmin123!"}`))
+	created := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/admin/invites", admin, `{"role":"teacher"}`)
+	var invite struct {
+		Code string `json:"code"`
+	}
+	_ = json.Unmarshal(created.Data, &invite)
+	teacher := signup("disc_teacher", invite.Code)
+	alice := signup("disc_alice", "")
+	bob := signup("disc_bob", "")
+
+	type post struct {
+		ID       int64  `json:"id"`
+		Body     string `json:"body"`
+		Hidden   bool   `json:"hidden"`
+		Accepted bool   `json:"accepted"`
+	}
+	type thread struct {
+		ID             int64  `json:"id"`
+		RootPostID     int64  `json:"rootPostId"`
+		AcceptedPostID int64  `json:"acceptedPostId"`
+		Unread         int    `json:"unread"`
+		Posts          []post `json:"posts"`
+	}
+
+	resp := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/discussions", alice,
+		`{"topic":"types","chapter":"slice","questionId":"q-slice-1","title":"扩容规则","body":"@disc_bob 为什么 <img src=x onerror=alert(1)> [看这里](javascript:alert(1))"}`)
+	var created1 thread
+	_ = json.Unmarshal(resp.Data, &created1)
+	if resp.Code != 20000 || created1.ID == 0 || len(created1.Posts) != 1 {
+		t.Fatalf("创建讨论失败: code=%d %s", resp.Code, resp.Message)
+	}
+	if body := created1.Posts[0].Body; strings.Contains(body, "<img") || strings.Contains(body, "javascript:") {
+		t.Fatalf("正文应在服务端清理: %q", body)
+	}
+	if bad := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/discussions", alice,
+		`{"topic":"types","chapter":"slice","questionId":"q-missing","title":"x","body":"y"}`); bad.Code != 40004 {
+		t.Fatalf("不存在的题目应返回 40004，得到 code=%d", bad.Code)
+	}
+
+	mentions := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/mentions?unread=true", bob, "")
+	var mentionList []struct {
+		ThreadID int64 `json:"threadId"`
+	}
+	_ = json.Unmarshal(mentions.Data, &mentionList)
+	if mentions.Code != 20000 || len(mentionList) != 1 || mentionList[0].ThreadID != created1.ID {
+		t.Fatalf("bob 应收到提及: code=%d %s", mentions.Code, string(mentions.Data))
+	}
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/mentions/read", bob, `{}`)
+
+	threadURL := fmt.Sprintf("%s/api/v1/discussions/%d", baseURL, created1.ID)
+	replyResp := doAuthed(t, client, http.MethodPost, threadURL+"/posts", bob,
+		fmt.Sprintf(`{"parentId":%d,"body":"容量不足时按倍数增长"}`, created1.RootPostID))
+	var reply post
+	_ = json.Unmarshal(replyResp.Data, &reply)
+	if replyResp.Code != 20000 || reply.ID == 0 {
+		t.Fatalf("回复失败: code=%d %s", replyResp.Code, replyResp.Message)
+	}
+
+	listResp := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/discussions?topic=types&chapter=slice&questionId=q-slice-1", alice, "")
+	var page struct {
+		Items  []thread `json:"items"`
+		Total  int      `json:"total"`
+		Unread []struct {
+			Chapter string `json:"chapter"`
+			Count   int    `json:"count"`
+		} `json:"unread"`
+	}
+	_ = json.Unmarshal(listResp.Data, &page)
+	if listResp.Code != 20000 || page.Total != 1 || page.Items[0].Unread != 1 || len(page.Unread) != 1 || page.Unread[0].Count != 1 {
+		t.Fatalf("alice 应看到 1 条未读: code=%d %s", listResp.Code, string(listResp.Data))
+	}
+	doAuthed(t, client, http.MethodGet, threadURL, alice, "")
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/discussions?topic=types", alice, "").Data, &page)
+	if len(page.Unread) != 0 {
+		t.Fatalf("查看后未读应清零: %+v", page.Unread)
+	}
+
+	acceptBody := fmt.Sprintf(`{"postId":%d}`, reply.ID)
+	if denied := doAuthed(t, client, http.MethodPut, threadURL+"/accepted", alice, acceptBody); denied.Code != 40040 {
+		t.Fatalf("学生采纳应返回 40040，得到 code=%d", denied.Code)
+	}
+	if accepted := doAuthed(t, client, http.MethodPut, threadURL+"/accepted", teacher, acceptBody); accepted.Code != 20000 {
+		t.Fatalf("教师采纳失败: code=%d %s", accepted.Code, accepted.Message)
+	}
+	var detail thread
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, threadURL, bob, "").Data, &detail)
+	if detail.AcceptedPostID != reply.ID || !detail.Posts[1].Accepted {
+		t.Fatalf("回答应被标记为采纳: %+v", detail)
+	}
+
+	hideURL := fmt.Sprintf("%s/api/v1/discussions/posts/%d/hidden", baseURL, created1.RootPostID)
+	if denied := doAuthed(t, client, http.MethodPut, hideURL, teacher, `{"hidden":true}`); denied.Code != 40040 {
+		t.Fatalf("教师隐藏应返回 40040，得到 code=%d", denied.Code)
+	}
+	if hidden := doAuthed(t, client, http.MethodPut, hideURL, admin, `{"hidden":true}`); hidden.Code != 20000 {
+		t.Fatalf("管理员隐藏失败: code=%d %s", hidden.Code, hidden.Message)
+	}
+	if missing := doAuthed(t, client, http.MethodGet, threadURL, alice, ""); missing.Code != 40038 {
+		t.Fatalf("隐藏主题对普通用户应返回 40038，得到 code=%d", missing.Code)
+	}
+	if visible := doAuthed(t, client, http.MethodGet, threadURL, admin, ""); visible.Code != 20000 {
+		t.Fatalf("管理员应能查看隐藏主题: code=%d", visible.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-study2/internal/app/http_server"
+	"go-study2/internal/config"
+	"go-study2/internal/infrastructure/database"
+	appjwt "go-study2/internal/pkg/jwt"
+)
+
+// startConfiguredServer 以临时数据库启动服务器，mutate 可在启动前调整配置。
+func startConfiguredServer(t *testing.T, ctx context.Context, name string, mutate func(cfg *config.Config)) (string, func()) {
+	t.Helper()
+	_ = os.MkdirAll("testdata", 0o755)
+	dbPath := filepath.ToSlash(filepath.Join("testdata", fmt.Sprintf("%s_%d.db", name, time.Now().UnixNano())))
+	cfg := defaultConfig(dbPath)
+	if mutate != nil {
+		mutate(cfg)
+	}
+
+	if _, err := database.Init(ctx, cfg.Database); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	if err := appjwt.Configure(appjwt.Options{
+		Secret:             cfg.Jwt.Secret,
+		Issuer:             cfg.Jwt.Issuer,
+		AccessTokenExpiry:  time.Duration(cfg.Jwt.AccessTokenExpiry) * time.Second,
+		RefreshTokenExpiry: time.Duration(cfg.Jwt.RefreshTokenExpiry) * time.Second,
+	}); err != nil {
+		t.Fatalf("配置 JWT 失败: %v", err)
+	}
+
+	server, err := http_server.NewServer(cfg, name)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	server.SetPort(0)
+	server.SetAccessLogEnabled(false)
+	server.Start()
+
+	time.Sleep(80 * time.Millisecond)
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", server.GetListenedPort())
+	return baseURL, func() { server.Shutdown() }
+}
+
+// doAuthed 以 Bearer 令牌发送请求并解析统一响应。
+func doAuthed(t *testing.T, client *http.Client, method, url, token, payload string) apiResponse {
+	t.Helper()
+	var body io.Reader
+	if payload != "" {
+		body = bytes.NewBufferString(payload)
+	}
+	req, _ := http.NewRequest(method, url, body)
+	if payload != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return doIntegrationRequest(t, client, req)
+}
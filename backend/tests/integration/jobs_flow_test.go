@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+	"time"
+
+	"go-study2/internal/domain/jobs"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestJobsFlow_ListPauseAndTrigger(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_jobs", nil)
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	tokenOf := func(resp apiResponse) string {
+		var data struct {
+			AccessToken string `json:"accessToken"`
+		}
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("获取令牌失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	first := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login",
+		fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword)))
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", first,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"JobsAdmin123!"}`, user.DefaultAdminPassword))
+	admin := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"JobsAdmin123!"}`))
+
+	jobsURL := baseURL + "/api/v1/admin/jobs"
+	var list []jobs.Job
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, jobsURL, admin, "").Data, &list)
+	names := make([]string, 0, len(list))
+	for _, job := range list {
+		names = append(names, job.Name)
+	}
+	if strings.Join(names, ",") != "token_cleanup,audit_retention,review_reminders" || list[0].NextRunAt == nil {
+		t.Fatalf("任务列表不正确: %+v", list)
+	}
+
+	if paused := doAuthed(t, client, http.MethodPut, jobsURL+"/token_cleanup/paused", admin, `{"paused":true}`); paused.Code != 20000 ||
+		!strings.Contains(string(paused.Data), `"paused":true`) {
+		t.Fatalf("暂停任务失败: code=%d %s", paused.Code, paused.Message)
+	}
+	if bad := doAuthed(t, client, http.MethodPut, jobsURL+"/token_cleanup/paused", admin, `{}`); bad.Code != 40004 {
+		t.Fatalf("缺少 paused 应返回 40004，得到 code=%d", bad.Code)
+	}
+
+	// 暂停中的任务仍可手动触发
+	triggered := doAuthed(t, client, http.MethodPost, jobsURL+"/token_cleanup/run", admin, "")
+	var run jobs.Run
+	_ = json.Unmarshal(triggered.Data, &run)
+	if triggered.Code != 20000 || run.ID == 0 || run.Trigger != jobs.TriggerManual {
+		t.Fatalf("手动触发失败: code=%d %s", triggered.Code, triggered.Message)
+	}
+	var runs []jobs.Run
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, jobsURL+"/token_cleanup/runs", admin, "").Data, &runs)
+		if len(runs) > 0 && runs[0].Status != jobs.RunRunning {
+			break
+		}
+	}
+	if len(runs) != 1 || runs[0].ID != run.ID || runs[0].Status != jobs.RunSucceeded || !strings.HasPrefix(runs[0].Result, "refresh_tokens=") {
+		t.Fatalf("执行记录不正确: %+v", runs)
+	}
+
+	if resumed := doAuthed(t, client, http.MethodPut, jobsURL+"/token_cleanup/paused", admin, `{"paused":false}`); resumed.Code != 20000 ||
+		!strings.Contains(string(resumed.Data), `"nextRunAt"`) {
+		t.Fatalf("恢复任务失败: code=%d %s", resumed.Code, resumed.Message)
+	}
+	if missing := doAuthed(t, client, http.MethodPost, jobsURL+"/missing/run", admin, ""); missing.Code != 40043 {
+		t.Fatalf("不存在的任务应返回 40043，得到 code=%d", missing.Code)
+	}
+
+	created := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/register", admin, `{"username":"jobs_student","password":"Jobs123!"}`)
+	if created.Code != 20000 {
+		t.Fatalf("创建学生失败: code=%d %s", created.Code, created.Message)
+	}
+	student := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"jobs_student","password":"Jobs123!"}`))
+	if denied := doAuthed(t, client, http.MethodGet, jobsURL, student, ""); denied.Code != 40010 {
+		t.Fatalf("非管理员访问应返回 40010，得到 code=%d", denied.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestLeaderboardFlow_PrivacyAndClassToggle(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_leaderboard", func(cfg *config.Config) {
+		cfg.Auth.Registration.Open = true
+	})
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	tokenOf := func(resp apiResponse) string {
+		var data struct {
+			AccessToken string `json:"accessToken"`
+		}
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("获取令牌失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	first := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login",
+		fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword)))
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", first,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"RankAdmin123!"}`, user.DefaultAdminPassword))
+	admin := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"RankAdmin123!"}`))
+	alice := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"rank_alice","password":"RankAlice123!"}`))
+	bob := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"rank_bob","password":"RankBob123!"}`))
+
+	quiz := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/quiz/variables/storage", alice, "")
+	var questions []struct {
+		ID     string   `json:"id"`
+		Answer []string `json:"answer"`
+	}
+	_ = json.Unmarshal(quiz.Data, &questions)
+	if len(questions) == 0 {
+		t.Fatalf("题目列表为空")
+	}
+	answers := ""
+	for i, q := range questions {
+		if i > 0 {
+			answers += ","
+		}
+		raw, _ := json.Marshal(q.Answer)
+		answers += fmt.Sprintf(`{"id":"%s","choices":%s}`, q.ID, raw)
+	}
+	submit := func(token, body string) {
+		if resp := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/quiz/submit", token, body); resp.Code != 20000 {
+			t.Fatalf("提交测验失败: code=%d %s", resp.Code, resp.Message)
+		}
+	}
+	submit(alice, fmt.Sprintf(`{"topic":"variables","chapter":"storage","answers":[%s]}`, answers))
+	submit(bob, `{"topic":"variables","chapter":"storage","answers":[{"id":"none","choices":["A"]}]}`)
+	submit(bob, fmt.Sprintf(`{"topic":"variables","chapter":"storage","answers":[%s]}`, answers))
+
+	type board struct {
+		Entries []struct {
+			Rank        int     `json:"rank"`
+			DisplayName string  `json:"displayName"`
+			Value       float64 `json:"value"`
+			IsMe        bool    `json:"isMe"`
+		} `json:"entries"`
+		Me struct {
+			Rank     int  `json:"rank"`
+			OptedOut bool `json:"optedOut"`
+		} `json:"me"`
+	}
+	var global board
+	resp := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/leaderboards?metric=mastered&window=week", alice, "")
+	_ = json.Unmarshal(resp.Data, &global)
+	if resp.Code != 20000 || len(global.Entries) != 2 || global.Entries[0].Rank != 1 || global.Entries[1].Rank != 1 || global.Me.Rank != 1 {
+		t.Fatalf("两人都掌握一个章节应并列第一: code=%d data=%s", resp.Code, string(resp.Data))
+	}
+
+	if taken := doAuthed(t, client, http.MethodPut, baseURL+"/api/v1/leaderboards/preferences", alice, `{"nickname":"rank_bob"}`); taken.Code != 40035 {
+		t.Fatalf("昵称与他人用户名相同应返回 40035，得到 code=%d", taken.Code)
+	}
+	doAuthed(t, client, http.MethodPut, baseURL+"/api/v1/leaderboards/preferences", alice, `{"nickname":"存储达人"}`)
+	doAuthed(t, client, http.MethodPut, baseURL+"/api/v1/leaderboards/preferences", bob, `{"optOut":true}`)
+
+	var after board
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/leaderboards", bob, "").Data, &after)
+	if len(after.Entries) != 1 || after.Entries[0].DisplayName != "存储达人" || !after.Me.OptedOut || after.Me.Rank != 0 {
+		t.Fatalf("隐私设置未生效: %+v", after)
+	}
+
+	createdClass := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes", admin, `{"name":"排行班"}`)
+	var class struct {
+		ID                 int64  `json:"id"`
+		JoinCode           string `json:"joinCode"`
+		LeaderboardEnabled bool   `json:"leaderboardEnabled"`
+	}
+	_ = json.Unmarshal(createdClass.Data, &class)
+	if !class.LeaderboardEnabled {
+		t.Fatalf("新班级默认应开启排行榜: %s", string(createdClass.Data))
+	}
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes/join", alice, fmt.Sprintf(`{"code":"%s"}`, class.JoinCode))
+
+	classBoard := fmt.Sprintf("%s/api/v1/leaderboards?classId=%d", baseURL, class.ID)
+	if ok := doAuthed(t, client, http.MethodGet, classBoard, alice, ""); ok.Code != 20000 {
+		t.Fatalf("班级成员应能查看班级排行榜: code=%d", ok.Code)
+	}
+	if hidden := doAuthed(t, client, http.MethodGet, classBoard, bob, ""); hidden.Code != 40024 {
+		t.Fatalf("非成员查看班级排行榜应返回 40024，得到 code=%d", hidden.Code)
+	}
+	toggleURL := fmt.Sprintf("%s/api/v1/classes/%d/leaderboard", baseURL, class.ID)
+	if denied := doAuthed(t, client, http.MethodPut, toggleURL, alice, `{"enabled":false}`); denied.Code != 40025 {
+		t.Fatalf("非管理员关闭排行榜应返回 40025，得到 code=%d", denied.Code)
+	}
+	if off := doAuthed(t, client, http.MethodPut, toggleURL, admin, `{"enabled":false}`); off.Code != 20000 {
+		t.Fatalf("管理员关闭排行榜失败: code=%d %s", off.Code, off.Message)
+	}
+	if disabled := doAuthed(t, client, http.MethodGet, classBoard, alice, ""); disabled.Code != 40034 {
+		t.Fatalf("关闭后应返回 40034，得到 code=%d", disabled.Code)
+	}
+}
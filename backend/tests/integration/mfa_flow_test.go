@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+	"go-study2/internal/pkg/totp"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestMFAFlow_AdminPolicyAndTwoStepLogin(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_mfa", func(cfg *config.Config) {
+		cfg.Auth.Mfa = config.MfaConfig{RequireForAdmin: true, ChallengeTtl: 300, Skew: 1}
+	})
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	type loginData struct {
+		AccessToken  string `json:"accessToken"`
+		NeedMfaSetup bool   `json:"needMfaSetup"`
+		MfaRequired  bool   `json:"mfaRequired"`
+		MfaToken     string `json:"mfaToken"`
+	}
+
+	// 默认管理员先完成改密
+	first := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword))
+	var firstData loginData
+	_ = json.Unmarshal(first.Data, &firstData)
+	changed := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", firstData.AccessToken,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"MfaAdmin123!"}`, user.DefaultAdminPassword))
+	if changed.Code != 20000 {
+		t.Fatalf("改密失败: %v", changed.Message)
+	}
+
+	login := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"MfaAdmin123!"}`)
+	var data loginData
+	_ = json.Unmarshal(login.Data, &data)
+	if login.Code != 20000 || !data.NeedMfaSetup {
+		t.Fatalf("策略开启时未绑定管理员应返回 needMfaSetup，得到 code=%d", login.Code)
+	}
+
+	blocked := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/progress", data.AccessToken, "")
+	if blocked.Code != 40012 {
+		t.Fatalf("未绑定两步验证的管理员应被阻断，得到 code=%d", blocked.Code)
+	}
+
+	setup := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/mfa/setup", data.AccessToken, `{}`)
+	var setupData struct {
+		Secret     string `json:"secret"`
+		OtpauthUri string `json:"otpauthUri"`
+	}
+	_ = json.Unmarshal(setup.Data, &setupData)
+	if setup.Code != 20000 || setupData.Secret == "" || setupData.OtpauthUri == "" {
+		t.Fatalf("开始绑定失败: %v", setup.Message)
+	}
+
+	code, _ := totp.Generate(setupData.Secret, time.Now())
+	confirm := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/mfa/confirm", data.AccessToken, fmt.Sprintf(`{"code":"%s"}`, code))
+	var confirmData struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	_ = json.Unmarshal(confirm.Data, &confirmData)
+	if confirm.Code != 20000 || len(confirmData.RecoveryCodes) == 0 {
+		t.Fatalf("确认绑定失败: %v", confirm.Message)
+	}
+
+	allowed := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/progress", data.AccessToken, "")
+	if allowed.Code != 20000 {
+		t.Fatalf("绑定后应可访问业务接口，得到 code=%d", allowed.Code)
+	}
+
+	// 重新登录进入两步流程
+	challenge := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"MfaAdmin123!"}`)
+	var challengeData loginData
+	_ = json.Unmarshal(challenge.Data, &challengeData)
+	if !challengeData.MfaRequired || challengeData.MfaToken == "" || challengeData.AccessToken != "" {
+		t.Fatalf("启用两步验证后登录应返回挑战而非令牌")
+	}
+
+	wrong := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login/mfa", fmt.Sprintf(`{"mfaToken":"%s","code":"abcdef"}`, challengeData.MfaToken))
+	if wrong.Code != 40013 {
+		t.Fatalf("错误验证码应返回 40013，得到 %d", wrong.Code)
+	}
+
+	verified := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login/mfa", fmt.Sprintf(`{"mfaToken":"%s","code":"%s"}`, challengeData.MfaToken, confirmData.RecoveryCodes[0]))
+	var verifiedData loginData
+	_ = json.Unmarshal(verified.Data, &verifiedData)
+	if verified.Code != 20000 || verifiedData.AccessToken == "" {
+		t.Fatalf("恢复码完成二次验证失败: %v", verified.Message)
+	}
+
+	profile := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/profile", verifiedData.AccessToken, "")
+	var profileData struct {
+		MfaEnabled bool `json:"mfaEnabled"`
+	}
+	_ = json.Unmarshal(profile.Data, &profileData)
+	if !profileData.MfaEnabled {
+		t.Fatalf("profile 应显示已启用两步验证")
+	}
+
+	if countAuditEvents(t, "login_mfa_success") == 0 {
+		t.Fatalf("两步登录成功应记录审计事件")
+	}
+}
@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+
+	"go-study2/internal/config"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestNotesFlow_CRUDSearchAndExport(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_notes", func(cfg *config.Config) {
+		cfg.Auth.Registration.Open = true
+	})
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	tokenOf := func(resp apiResponse) string {
+		var data struct {
+			AccessToken string `json:"accessToken"`
+		}
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("获取令牌失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	alice := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"note_alice","password":"NoteAlice123!"}`))
+	bob := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"note_bob","password":"NoteBob123!"}`))
+
+	type note struct {
+		ID     int64    `json:"id"`
+		Kind   string   `json:"kind"`
+		Anchor string   `json:"anchor"`
+		Body   string   `json:"body"`
+		Tags   []string `json:"tags"`
+	}
+	create := func(token, payload string) (apiResponse, note) {
+		resp := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/notes", token, payload)
+		var n note
+		_ = json.Unmarshal(resp.Data, &n)
+		return resp, n
+	}
+	resp, sliceNote := create(alice, `{"topic":"types","chapter":"slice","anchor":"slice-append","title":"扩容","body":"**append** 超过容量会触发 growslice","tags":["Runtime","slice"]}`)
+	if resp.Code != 20000 || sliceNote.ID == 0 || sliceNote.Kind != "note" || len(sliceNote.Tags) != 2 || sliceNote.Tags[0] != "runtime" {
+		t.Fatalf("创建笔记失败: code=%d %s", resp.Code, string(resp.Data))
+	}
+	create(alice, `{"topic":"variables","chapter":"storage","body":"变量是存储位置"}`)
+	if resp, _ := create(alice, `{"kind":"bookmark","topic":"types","chapter":"map","anchor":"map-iter"}`); resp.Code != 20000 {
+		t.Fatalf("创建书签失败: code=%d %s", resp.Code, resp.Message)
+	}
+	if dup, _ := create(alice, `{"kind":"bookmark","topic":"types","chapter":"map","anchor":"map-iter"}`); dup.Code != 40037 {
+		t.Fatalf("重复书签应返回 40037，得到 code=%d", dup.Code)
+	}
+	if invalid, _ := create(alice, `{"topic":"types","chapter":"slice"}`); invalid.Code != 40004 {
+		t.Fatalf("缺少正文应返回 40004，得到 code=%d", invalid.Code)
+	}
+
+	list := func(token, query string) []note {
+		resp := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/notes?"+query, token, "")
+		if resp.Code != 20000 {
+			t.Fatalf("查询笔记失败: code=%d %s", resp.Code, resp.Message)
+		}
+		var items []note
+		_ = json.Unmarshal(resp.Data, &items)
+		return items
+	}
+	if items := list(alice, "topic=types"); len(items) != 2 {
+		t.Fatalf("按主题查询应返回 2 条: %+v", items)
+	}
+	if items := list(alice, "q="+url.QueryEscape("GrowSlice")); len(items) != 1 || items[0].ID != sliceNote.ID {
+		t.Fatalf("全文检索结果不正确: %+v", items)
+	}
+	if items := list(bob, "q=growslice"); len(items) != 0 {
+		t.Fatalf("不应检索到他人笔记: %+v", items)
+	}
+
+	noteURL := fmt.Sprintf("%s/api/v1/notes/%d", baseURL, sliceNote.ID)
+	if hidden := doAuthed(t, client, http.MethodGet, noteURL, bob, ""); hidden.Code != 40036 {
+		t.Fatalf("他人笔记应返回 40036，得到 code=%d", hidden.Code)
+	}
+	updated := doAuthed(t, client, http.MethodPut, noteURL, alice, `{"topic":"types","chapter":"slice","anchor":"slice-copy","body":"copy 返回复制的元素个数","tags":["slice"]}`)
+	if updated.Code != 20000 {
+		t.Fatalf("更新笔记失败: code=%d %s", updated.Code, updated.Message)
+	}
+	if items := list(alice, "q=growslice"); len(items) != 0 {
+		t.Fatalf("更新后旧内容不应再被检索到: %+v", items)
+	}
+
+	export := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/export", alice, "")
+	var data struct {
+		Profile struct {
+			Username string `json:"username"`
+		} `json:"profile"`
+		Notes []note `json:"notes"`
+	}
+	_ = json.Unmarshal(export.Data, &data)
+	if export.Code != 20000 || data.Profile.Username != "note_alice" || len(data.Notes) != 3 {
+		t.Fatalf("个人数据导出应包含全部笔记: code=%d %s", export.Code, string(export.Data))
+	}
+
+	if deleted := doAuthed(t, client, http.MethodDelete, noteURL, alice, ""); deleted.Code != 20000 {
+		t.Fatalf("删除笔记失败: code=%d", deleted.Code)
+	}
+	if missing := doAuthed(t, client, http.MethodGet, noteURL, alice, ""); missing.Code != 40036 {
+		t.Fatalf("删除后应返回 40036，得到 code=%d", missing.Code)
+	}
+
+	created := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/tokens", alice, `{"name":"cli","scopes":["notes:read"]}`)
+	var tokenData struct {
+		Token string `json:"token"`
+	}
+	_ = json.Unmarshal(created.Data, &tokenData)
+	if items := list(tokenData.Token, "topic=variables"); len(items) != 1 {
+		t.Fatalf("具备 notes:read 的令牌应可读取笔记: %+v", items)
+	}
+	if write, _ := create(tokenData.Token, `{"topic":"types","chapter":"map","body":"x"}`); write.Code != 40021 {
+		t.Fatalf("缺少 notes:write 应返回 40021，得到 code=%d", write.Code)
+	}
+}
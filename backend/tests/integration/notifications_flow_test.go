@@ -0,0 +1,190 @@
+package integration
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+type sseEvent struct {
+	ID   string
+	Name string
+	Data string
+}
+
+// openStream 建立 SSE 连接，返回事件通道与关闭函数。
+func openStream(t *testing.T, url, lastEventID string) (<-chan sseEvent, func()) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("建立 SSE 连接失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		resp.Body.Close()
+		t.Fatalf("SSE 响应不正确: status=%d type=%s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var current sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if current.Data != "" {
+					events <- current
+				}
+				current = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				current.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				current.Name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				current.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return events, func() { resp.Body.Close() }
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatalf("SSE 连接意外关闭")
+		}
+		return ev
+	case <-time.After(3 * time.Second):
+		t.Fatalf("等待 SSE 事件超时")
+	}
+	return sseEvent{}
+}
+
+func TestNotificationsFlow_StreamResumeAndPreferences(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_notifications", func(cfg *config.Config) {
+		cfg.Auth.Registration.Open = true
+	})
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	tokenOf := func(resp apiResponse) string {
+		var data struct {
+			AccessToken string `json:"accessToken"`
+		}
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("获取令牌失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	first := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login",
+		fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword)))
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", first,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"NotifyAdmin123!"}`, user.DefaultAdminPassword))
+	admin := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"NotifyAdmin123!"}`))
+	var invite struct {
+		Code string `json:"code"`
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/admin/invites", admin, `{"role":"teacher"}`).Data, &invite)
+	teacher := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup",
+		fmt.Sprintf(`{"username":"notify_teacher","password":"Notify123!","inviteCode":"%s"}`, invite.Code)))
+	alice := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"notify_alice","password":"Notify123!"}`))
+
+	var class struct {
+		ID       int64  `json:"id"`
+		JoinCode string `json:"joinCode"`
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes", teacher, `{"name":"通知测试班"}`).Data, &class)
+	if joined := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/classes/join", alice, fmt.Sprintf(`{"code":"%s"}`, class.JoinCode)); joined.Code != 20000 {
+		t.Fatalf("加入班级失败: code=%d %s", joined.Code, joined.Message)
+	}
+	assignmentsURL := fmt.Sprintf("%s/api/v1/classes/%d/assignments", baseURL, class.ID)
+	assign := func(title string) {
+		resp := doAuthed(t, client, http.MethodPost, assignmentsURL, teacher,
+			fmt.Sprintf(`{"title":"%s","topic":"variables","chapter":"storage","dueAt":"2099-01-01T00:00:00Z"}`, title))
+		if resp.Code != 20000 {
+			t.Fatalf("布置作业失败: code=%d %s", resp.Code, resp.Message)
+		}
+	}
+
+	streamURL := baseURL + "/api/v1/notifications/stream?access_token=" + alice
+	if resp, err := http.Get(baseURL + "/api/v1/notifications/stream"); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("未认证的订阅应返回 401: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+
+	events, closeStream := openStream(t, streamURL, "")
+	assign("第一次作业")
+	ev := nextEvent(t, events)
+	var pushed struct {
+		ID    int64  `json:"id"`
+		Type  string `json:"type"`
+		Title string `json:"title"`
+	}
+	_ = json.Unmarshal([]byte(ev.Data), &pushed)
+	if ev.Name != "notification" || pushed.Type != "assignment" || !strings.Contains(pushed.Title, "第一次作业") || ev.ID != fmt.Sprint(pushed.ID) {
+		t.Fatalf("实时推送内容不正确: %+v", ev)
+	}
+	closeStream()
+
+	assign("离线期间的作业")
+	events, closeStream = openStream(t, streamURL, ev.ID)
+	missed := nextEvent(t, events)
+	if !strings.Contains(missed.Data, "离线期间的作业") {
+		t.Fatalf("携带 Last-Event-ID 重连应补发错过的通知: %+v", missed)
+	}
+	closeStream()
+
+	var inbox struct {
+		Items []struct {
+			ID   int64 `json:"id"`
+			Read bool  `json:"read"`
+		} `json:"items"`
+		Unread int `json:"unread"`
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/notifications", alice, "").Data, &inbox)
+	if len(inbox.Items) != 2 || inbox.Unread != 2 {
+		t.Fatalf("收件箱应有 2 条未读: %+v", inbox)
+	}
+	if marked := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/notifications/read", alice,
+		fmt.Sprintf(`{"ids":[%d]}`, inbox.Items[0].ID)); marked.Code != 20000 {
+		t.Fatalf("标记已读失败: code=%d %s", marked.Code, marked.Message)
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/notifications?unread=true", alice, "").Data, &inbox)
+	if len(inbox.Items) != 1 || inbox.Unread != 1 {
+		t.Fatalf("应剩 1 条未读: %+v", inbox)
+	}
+
+	if bad := doAuthed(t, client, http.MethodPut, baseURL+"/api/v1/notifications/preferences", alice,
+		`{"preferences":[{"type":"unknown","enabled":false}]}`); bad.Code != 40004 {
+		t.Fatalf("未知通知类型应返回 40004，得到 code=%d", bad.Code)
+	}
+	if updated := doAuthed(t, client, http.MethodPut, baseURL+"/api/v1/notifications/preferences", alice,
+		`{"preferences":[{"type":"assignment","enabled":false}]}`); updated.Code != 20000 {
+		t.Fatalf("更新通知偏好失败: code=%d %s", updated.Code, updated.Message)
+	}
+	assign("已关闭通知的作业")
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/notifications", alice, "").Data, &inbox)
+	if len(inbox.Items) != 2 {
+		t.Fatalf("关闭作业通知后不应再收到: %+v", inbox)
+	}
+}
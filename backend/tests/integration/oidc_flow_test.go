@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/pkg/oidc/oidctest"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestOIDCFlow_ProvisionAndRelogin(t *testing.T) {
+	fake := oidctest.NewProvider("go-study2", "oidc-secret")
+	defer fake.Close()
+
+	var cfgRef *config.Config
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_oidc", func(cfg *config.Config) {
+		cfg.Auth.Oidc.Providers = []config.OidcProviderConfig{
+			{
+				Name:          "corp",
+				DisplayName:   "Corp SSO",
+				Issuer:        fake.Issuer(),
+				ClientId:      "go-study2",
+				ClientSecret:  "oidc-secret",
+				UsernameClaim: "preferred_username",
+				RoleClaim:     "groups",
+				AdminValues:   []string{"go-study-admins"},
+				AutoProvision: true,
+			},
+			{
+				Name:         "partner",
+				Issuer:       fake.Issuer(),
+				ClientId:     "go-study2",
+				ClientSecret: "oidc-secret",
+			},
+		}
+		cfgRef = cfg
+	})
+	defer cleanup()
+	// 端口在启动后才确定；提供方客户端在首次请求时才按全局配置创建，此时回填回调地址
+	cfgRef.Auth.Oidc.Providers[0].RedirectUrl = baseURL + "/api/v1/auth/oidc/corp/callback"
+	cfgRef.Auth.Oidc.Providers[1].RedirectUrl = baseURL + "/api/v1/auth/oidc/partner/callback"
+
+	type loginData struct {
+		AccessToken string `json:"accessToken"`
+		IsAdmin     bool   `json:"isAdmin"`
+	}
+	type profileData struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	}
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	providers := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/oidc/providers", "", "")
+	var items []struct {
+		Name     string `json:"name"`
+		LoginUrl string `json:"loginUrl"`
+	}
+	_ = json.Unmarshal(providers.Data, &items)
+	if providers.Code != 20000 || len(items) != 2 || items[0].Name != "corp" {
+		t.Fatalf("提供方列表不正确: %s", string(providers.Data))
+	}
+
+	fake.SetUser(map[string]interface{}{
+		"sub":                "emp-001",
+		"preferred_username": "jane.doe",
+		"email":              "jane@example.com",
+		"groups":             []string{"go-study-admins"},
+	})
+	// 客户端自动跟随 登录 → 提供方授权 → 回调 的重定向链
+	first := doAuthed(t, client, http.MethodGet, baseURL+items[0].LoginUrl, "", "")
+	var firstData loginData
+	_ = json.Unmarshal(first.Data, &firstData)
+	if first.Code != 20000 || firstData.AccessToken == "" || !firstData.IsAdmin {
+		t.Fatalf("首次 OIDC 登录应开通管理员账号，得到 code=%d %s", first.Code, first.Message)
+	}
+	profile := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/profile", firstData.AccessToken, "")
+	var firstProfile profileData
+	_ = json.Unmarshal(profile.Data, &firstProfile)
+	if firstProfile.Username != "jane_doe" {
+		t.Fatalf("用户名应由 preferred_username 派生，得到 %s", firstProfile.Username)
+	}
+
+	second := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/oidc/corp/login", "", "")
+	var secondData loginData
+	_ = json.Unmarshal(second.Data, &secondData)
+	profile = doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/profile", secondData.AccessToken, "")
+	var secondProfile profileData
+	_ = json.Unmarshal(profile.Data, &secondProfile)
+	if second.Code != 20000 || secondProfile.ID != firstProfile.ID {
+		t.Fatalf("同一 subject 再次登录应复用已绑定用户")
+	}
+	if fake.TokensIssued() != 2 {
+		t.Fatalf("提供方应签发 2 次令牌，得到 %d", fake.TokensIssued())
+	}
+
+	replayed := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/oidc/corp/callback?code=forged&state=forged", "", "")
+	if replayed.Code != 40019 {
+		t.Fatalf("伪造的 state 应返回 40019，得到 code=%d", replayed.Code)
+	}
+
+	fake.SetUser(map[string]interface{}{"sub": "partner-007"})
+	unlinked := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/oidc/partner/login", "", "")
+	if unlinked.Code != 40020 {
+		t.Fatalf("关闭自动开通的提供方应拒绝未绑定账号，得到 code=%d", unlinked.Code)
+	}
+
+	unknown := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/oidc/nope/login", "", "")
+	if unknown.Code != 40004 {
+		t.Fatalf("未知提供方应返回 40004，得到 code=%d", unknown.Code)
+	}
+}
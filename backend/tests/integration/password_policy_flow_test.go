@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+	"go-study2/internal/pkg/password"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestPasswordPolicyFlow_StructuredViolations(t *testing.T) {
+	sum := sha1.Sum([]byte("Leaked-Pass-2026"))
+	breached := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(breached, []byte(hex.EncodeToString(sum[:])+":3\n"), 0o600); err != nil {
+		t.Fatalf("写入泄露密码库失败: %v", err)
+	}
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_password_policy", func(cfg *config.Config) {
+		cfg.Auth.Password = config.PasswordConfig{MinLength: 12, HistorySize: 2, BreachedList: breached}
+	})
+	defer cleanup()
+	defer password.Configure(password.DefaultPolicy())
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	type loginData struct {
+		AccessToken string `json:"accessToken"`
+	}
+	type violationData struct {
+		Violations []password.Violation `json:"violations"`
+	}
+	login := func(pwd string) string {
+		resp := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, pwd))
+		var data loginData
+		_ = json.Unmarshal(resp.Data, &data)
+		if resp.Code != 20000 || data.AccessToken == "" {
+			t.Fatalf("登录失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	change := func(token, oldPwd, newPwd string) (apiResponse, []string) {
+		resp := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", token,
+			fmt.Sprintf(`{"oldPassword":"%s","newPassword":"%s"}`, oldPwd, newPwd))
+		var data violationData
+		_ = json.Unmarshal(resp.Data, &data)
+		rules := make([]string, 0, len(data.Violations))
+		for _, v := range data.Violations {
+			rules = append(rules, v.Rule)
+		}
+		return resp, rules
+	}
+
+	// 默认管理员密码短于配置的最小长度，仍可创建与登录
+	token := login(user.DefaultAdminPassword)
+
+	resp, rules := change(token, user.DefaultAdminPassword, "Short1!")
+	if resp.Code != 40023 || len(rules) != 1 || rules[0] != password.RuleMinLength {
+		t.Fatalf("长度不足应返回 40023 与 min_length，得到 code=%d rules=%v", resp.Code, rules)
+	}
+	if _, rules = change(token, user.DefaultAdminPassword, "Leaked-Pass-2026"); len(rules) != 1 || rules[0] != password.RuleBreached {
+		t.Fatalf("泄露密码应返回 breached，得到 %v", rules)
+	}
+
+	if resp, _ = change(token, user.DefaultAdminPassword, "PolicyAdmin123!"); resp.Code != 20000 {
+		t.Fatalf("修改密码失败: code=%d %s", resp.Code, resp.Message)
+	}
+	token = login("PolicyAdmin123!")
+	if resp, rules = change(token, "PolicyAdmin123!", "PolicyAdmin123!"); resp.Code != 40023 || len(rules) != 1 || rules[0] != password.RuleReused {
+		t.Fatalf("当前密码不可复用，得到 code=%d rules=%v", resp.Code, rules)
+	}
+	if resp, rules = change(token, "PolicyAdmin123!", user.DefaultAdminPassword); resp.Code != 40023 || len(rules) != 2 {
+		t.Fatalf("历史密码应同时报告 min_length 与 reused，得到 code=%d rules=%v", resp.Code, rules)
+	}
+}
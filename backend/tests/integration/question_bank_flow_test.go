@@ -0,0 +1,123 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestQuestionBankFlow_AuthorPublishAndQuiz(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_question_bank", func(cfg *config.Config) {
+		cfg.Auth.Registration.Open = true
+	})
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	tokenOf := func(resp apiResponse) string {
+		var data struct {
+			AccessToken string `json:"accessToken"`
+		}
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("获取令牌失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	first := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login",
+		fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword)))
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", first,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"BankAdmin123!"}`, user.DefaultAdminPassword))
+	admin := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"BankAdmin123!"}`))
+	author := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"bank_author","password":"BankAuthor123!"}`))
+	student := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"bank_student","password":"BankStudent123!"}`))
+
+	questionsURL := baseURL + "/api/v1/admin/questions"
+	body := `{"questionId":"iota-start","topic":"constants","chapter":"iota","stem":"iota 在常量声明中从几开始？","options":["0","1"],"answer":"A","tags":["iota"]}`
+	if denied := doAuthed(t, client, http.MethodPost, questionsURL, author, body); denied.Code != 40031 {
+		t.Fatalf("非教师编写题目应返回 40031，得到 code=%d", denied.Code)
+	}
+
+	var profile struct {
+		ID int64 `json:"id"`
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/profile", author, "").Data, &profile)
+	if promoted := doAuthed(t, client, http.MethodPut, fmt.Sprintf("%s/api/v1/admin/users/%d/teacher", baseURL, profile.ID), admin, `{"teacher":true}`); promoted.Code != 20000 {
+		t.Fatalf("授予教师角色失败: code=%d %s", promoted.Code, promoted.Message)
+	}
+
+	invalid := doAuthed(t, client, http.MethodPost, questionsURL, author, `{"topic":"constants","chapter":"iota","stem":"题干","options":["唯一"],"answer":"B"}`)
+	var problems struct {
+		Problems []struct {
+			Field string `json:"field"`
+		} `json:"problems"`
+	}
+	_ = json.Unmarshal(invalid.Data, &problems)
+	if invalid.Code != 40033 || len(problems.Problems) != 2 {
+		t.Fatalf("非法题目应返回 40033 与校验问题: code=%d data=%s", invalid.Code, string(invalid.Data))
+	}
+
+	created := doAuthed(t, client, http.MethodPost, questionsURL, author, body)
+	var question struct {
+		ID     int64  `json:"id"`
+		Status string `json:"status"`
+	}
+	_ = json.Unmarshal(created.Data, &question)
+	if created.Code != 20000 || question.ID == 0 || question.Status != "draft" {
+		t.Fatalf("创建题目失败: code=%d %s", created.Code, created.Message)
+	}
+	if dup := doAuthed(t, client, http.MethodPost, questionsURL, author, body); dup.Code != 40033 {
+		t.Fatalf("重复题目 ID 应返回 40033，得到 code=%d", dup.Code)
+	}
+
+	quizURL := baseURL + "/api/v1/quiz/constants/iota"
+	if draft := doAuthed(t, client, http.MethodGet, quizURL, student, ""); string(draft.Data) != "[]" {
+		t.Fatalf("草稿题目不应出现在测验中: %s", string(draft.Data))
+	}
+
+	questionURL := fmt.Sprintf("%s/%d", questionsURL, question.ID)
+	published := doAuthed(t, client, http.MethodPut, questionURL, author,
+		`{"topic":"constants","chapter":"iota","stem":"iota 在常量声明中从几开始？","options":["0","1"],"answer":"A","tags":["iota"],"status":"published"}`)
+	if published.Code != 20000 {
+		t.Fatalf("发布题目失败: code=%d %s", published.Code, published.Message)
+	}
+
+	var items []struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, quizURL, student, "").Data, &items)
+	if len(items) != 1 || items[0].ID != "iota-start" {
+		t.Fatalf("已发布题目应出现在测验中: %+v", items)
+	}
+
+	var revisions []struct {
+		Revision int `json:"revision"`
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, questionURL+"/revisions", admin, "").Data, &revisions)
+	if len(revisions) != 2 || revisions[0].Revision != 2 {
+		t.Fatalf("修订历史不正确: %+v", revisions)
+	}
+
+	var listed []struct {
+		QuestionID string `json:"questionId"`
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, questionsURL+"?tag=iota&status=published", author, "").Data, &listed)
+	if len(listed) != 1 {
+		t.Fatalf("按标签过滤失败: %+v", listed)
+	}
+
+	if deleted := doAuthed(t, client, http.MethodDelete, questionURL, author, ""); deleted.Code != 20000 {
+		t.Fatalf("删除题目失败: code=%d", deleted.Code)
+	}
+	if missing := doAuthed(t, client, http.MethodGet, questionURL, author, ""); missing.Code != 40032 {
+		t.Fatalf("删除后应返回 40032，得到 code=%d", missing.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+func TestSignupFlow_InviteCode(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_signup", nil)
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	type loginData struct {
+		AccessToken string `json:"accessToken"`
+		IsAdmin     bool   `json:"isAdmin"`
+	}
+
+	closed := doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"no_invite","password":"Signup123!"}`)
+	if closed.Code != 40018 {
+		t.Fatalf("未开放注册时无邀请码应返回 40018，得到 code=%d", closed.Code)
+	}
+
+	first := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword))
+	var firstData loginData
+	_ = json.Unmarshal(first.Data, &firstData)
+	changed := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", firstData.AccessToken,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"InviteAdmin123!"}`, user.DefaultAdminPassword))
+	if changed.Code != 20000 {
+		t.Fatalf("改密失败: %v", changed.Message)
+	}
+	login := doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"InviteAdmin123!"}`)
+	var admin loginData
+	_ = json.Unmarshal(login.Data, &admin)
+
+	created := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/admin/invites", admin.AccessToken, `{"role":"user","maxUses":1,"expiresIn":3600,"note":"onboarding"}`)
+	var invite struct {
+		Code   string `json:"code"`
+		Invite struct {
+			ID int64 `json:"id"`
+		} `json:"invite"`
+	}
+	_ = json.Unmarshal(created.Data, &invite)
+	if created.Code != 20000 || invite.Code == "" {
+		t.Fatalf("创建邀请码失败: code=%d %s", created.Code, created.Message)
+	}
+
+	signup := doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", fmt.Sprintf(`{"username":"invited_user","password":"Signup123!","inviteCode":"%s"}`, invite.Code))
+	var signupData loginData
+	_ = json.Unmarshal(signup.Data, &signupData)
+	if signup.Code != 20000 || signupData.AccessToken == "" || signupData.IsAdmin {
+		t.Fatalf("邀请注册应返回普通用户令牌，得到 code=%d %s", signup.Code, signup.Message)
+	}
+
+	profile := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/auth/profile", signupData.AccessToken, "")
+	if profile.Code != 20000 {
+		t.Fatalf("注册后令牌应可访问受保护接口，得到 code=%d", profile.Code)
+	}
+
+	reused := doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", fmt.Sprintf(`{"username":"second_user","password":"Signup123!","inviteCode":"%s"}`, invite.Code))
+	if reused.Code != 40017 {
+		t.Fatalf("用尽的邀请码应返回 40017，得到 code=%d", reused.Code)
+	}
+
+	redemptions := doAuthed(t, client, http.MethodGet, fmt.Sprintf("%s/api/v1/admin/invites/%d/redemptions", baseURL, invite.Invite.ID), admin.AccessToken, "")
+	var records []struct {
+		Username string `json:"username"`
+	}
+	_ = json.Unmarshal(redemptions.Data, &records)
+	if redemptions.Code != 20000 || len(records) != 1 || records[0].Username != "invited_user" {
+		t.Fatalf("邀请码使用记录不正确: %s", string(redemptions.Data))
+	}
+
+	denied := doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/admin/invites", signupData.AccessToken, "")
+	if denied.Code != 40010 {
+		t.Fatalf("普通用户不可查看邀请码，得到 code=%d", denied.Code)
+	}
+}
+
+func TestSignupFlow_OpenRegistration(t *testing.T) {
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_signup_open", func(cfg *config.Config) {
+		cfg.Auth.Registration.Open = true
+	})
+	defer cleanup()
+
+	client := &http.Client{}
+	weak := doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"weak_user","password":"123"}`)
+	if weak.Code != 40023 || !strings.Contains(string(weak.Data), `"min_length"`) {
+		t.Fatalf("弱密码应返回 40023 及违规规则，得到 code=%d data=%s", weak.Code, string(weak.Data))
+	}
+	resp := doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"open_user","password":"Signup123!"}`)
+	if resp.Code != 20000 {
+		t.Fatalf("开放注册失败: code=%d %s", resp.Code, resp.Message)
+	}
+}
@@ -0,0 +1,190 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-study2/internal/config"
+	"go-study2/internal/domain/user"
+	"go-study2/internal/domain/webhooks"
+
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+type receivedHook struct {
+	Event     string
+	Delivery  string
+	Signature string
+	Body      []byte
+}
+
+func TestWebhooksFlow_SignedDeliveryRetryAndDeadLetter(t *testing.T) {
+	var failing atomic.Bool
+	received := make(chan receivedHook, 16)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedHook{
+			Event:     r.Header.Get(webhooks.HeaderEvent),
+			Delivery:  r.Header.Get(webhooks.HeaderDelivery),
+			Signature: r.Header.Get(webhooks.HeaderSignature),
+			Body:      body,
+		}
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	baseURL, cleanup := startConfiguredServer(t, gctx.New(), "integration_webhooks", func(cfg *config.Config) {
+		cfg.Auth.Registration.Open = true
+		cfg.Webhooks = config.WebhooksConfig{MaxAttempts: 2, RetryBaseSeconds: 1, PollIntervalSeconds: 1}
+	})
+	defer cleanup()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	tokenOf := func(resp apiResponse) string {
+		var data struct {
+			AccessToken string `json:"accessToken"`
+		}
+		_ = json.Unmarshal(resp.Data, &data)
+		if data.AccessToken == "" {
+			t.Fatalf("获取令牌失败: code=%d %s", resp.Code, resp.Message)
+		}
+		return data.AccessToken
+	}
+	first := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login",
+		fmt.Sprintf(`{"username":"%s","password":"%s"}`, user.DefaultAdminUsername, user.DefaultAdminPassword)))
+	doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/auth/change-password", first,
+		fmt.Sprintf(`{"oldPassword":"%s","newPassword":"HookAdmin123!"}`, user.DefaultAdminPassword))
+	admin := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/login", `{"username":"admin","password":"HookAdmin123!"}`))
+
+	hooksURL := baseURL + "/api/v1/admin/webhooks"
+	if bad := doAuthed(t, client, http.MethodPost, hooksURL, admin,
+		fmt.Sprintf(`{"name":"bad","url":"%s","events":["mention"]}`, receiver.URL)); bad.Code != 40004 {
+		t.Fatalf("不可订阅的事件应返回 40004，得到 code=%d", bad.Code)
+	}
+	created := doAuthed(t, client, http.MethodPost, hooksURL, admin,
+		fmt.Sprintf(`{"name":"lms","url":"%s","events":["user.created","quiz.submitted"]}`, receiver.URL))
+	var endpoint struct {
+		ID     int64  `json:"id"`
+		Secret string `json:"secret"`
+	}
+	_ = json.Unmarshal(created.Data, &endpoint)
+	if created.Code != 20000 || !strings.HasPrefix(endpoint.Secret, "whsec_") {
+		t.Fatalf("创建 Webhook 失败: code=%d %s", created.Code, created.Message)
+	}
+	if listed := doAuthed(t, client, http.MethodGet, hooksURL, admin, ""); strings.Contains(string(listed.Data), endpoint.Secret) {
+		t.Fatalf("列表不应返回签名密钥")
+	}
+
+	waitHook := func(event string) receivedHook {
+		t.Helper()
+		select {
+		case hook := <-received:
+			if hook.Event != event {
+				t.Fatalf("期望事件 %s，收到 %s: %s", event, hook.Event, hook.Body)
+			}
+			parts := strings.SplitN(strings.TrimPrefix(hook.Signature, "t="), ",", 2)
+			ts, _ := strconv.ParseInt(parts[0], 10, 64)
+			if hook.Signature != webhooks.Sign(endpoint.Secret, ts, hook.Body) {
+				t.Fatalf("签名校验失败: %s", hook.Signature)
+			}
+			return hook
+		case <-time.After(5 * time.Second):
+			t.Fatalf("等待 %s 投递超时", event)
+		}
+		return receivedHook{}
+	}
+
+	student := tokenOf(doIntegrationPost(t, client, baseURL+"/api/v1/auth/signup", `{"username":"hook_student","password":"Hook123!"}`))
+	var payload webhooks.Payload
+	_ = json.Unmarshal(waitHook("user.created").Body, &payload)
+	if payload.Data["username"] != "hook_student" || !strings.HasPrefix(payload.ID, "evt_") {
+		t.Fatalf("user.created 内容不正确: %+v", payload)
+	}
+	if denied := doAuthed(t, client, http.MethodGet, hooksURL, student, ""); denied.Code != 40010 {
+		t.Fatalf("非管理员访问应返回 40010，得到 code=%d", denied.Code)
+	}
+
+	if test := doAuthed(t, client, http.MethodPost, fmt.Sprintf("%s/%d/test", hooksURL, endpoint.ID), admin, ""); test.Code != 20000 {
+		t.Fatalf("发送测试事件失败: code=%d %s", test.Code, test.Message)
+	}
+	waitHook(webhooks.TestEventType)
+
+	// 接收方持续失败：重试一次后进入死信
+	failing.Store(true)
+	var questions []struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, baseURL+"/api/v1/quiz/variables/storage", student, "").Data, &questions)
+	if len(questions) == 0 {
+		t.Fatalf("题目列表为空")
+	}
+	if submit := doAuthed(t, client, http.MethodPost, baseURL+"/api/v1/quiz/submit", student,
+		fmt.Sprintf(`{"topic":"variables","chapter":"storage","answers":[{"id":"%s","choices":["A"]}]}`, questions[0].ID)); submit.Code != 20000 {
+		t.Fatalf("提交测验失败: code=%d %s", submit.Code, submit.Message)
+	}
+	firstTry := waitHook("quiz.submitted")
+	if retry := waitHook("quiz.submitted"); retry.Delivery != firstTry.Delivery {
+		t.Fatalf("重试应复用同一投递记录: %s != %s", retry.Delivery, firstTry.Delivery)
+	}
+
+	type deliveryView struct {
+		ID             int64  `json:"id"`
+		Status         string `json:"status"`
+		Attempts       int    `json:"attempts"`
+		ResponseStatus int    `json:"responseStatus"`
+	}
+	deliveriesURL := fmt.Sprintf("%s/%d/deliveries", hooksURL, endpoint.ID)
+	var dead []deliveryView
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, deliveriesURL+"?status=dead", admin, "").Data, &dead)
+		if len(dead) > 0 {
+			break
+		}
+	}
+	if len(dead) != 1 || dead[0].Attempts != 2 || dead[0].ResponseStatus != 500 || fmt.Sprint(dead[0].ID) != firstTry.Delivery {
+		t.Fatalf("两次失败后应进入死信: %+v", dead)
+	}
+
+	// 接收方恢复后手动重投死信
+	failing.Store(false)
+	if retried := doAuthed(t, client, http.MethodPost, fmt.Sprintf("%s/deliveries/%d/retry", hooksURL, dead[0].ID), admin, ""); retried.Code != 20000 {
+		t.Fatalf("重投死信失败: code=%d %s", retried.Code, retried.Message)
+	}
+	waitHook("quiz.submitted")
+	var all []deliveryView
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		_ = json.Unmarshal(doAuthed(t, client, http.MethodGet, deliveriesURL, admin, "").Data, &all)
+		if len(all) == 3 && all[0].Status == webhooks.StatusDelivered {
+			break
+		}
+	}
+	for _, d := range all {
+		if d.Status != webhooks.StatusDelivered {
+			t.Fatalf("全部投递最终应成功: %+v", all)
+		}
+	}
+
+	if missing := doAuthed(t, client, http.MethodGet, hooksURL+"/999", admin, ""); missing.Code != 40041 {
+		t.Fatalf("不存在的 Webhook 应返回 40041，得到 code=%d", missing.Code)
+	}
+	if missing := doAuthed(t, client, http.MethodPost, hooksURL+"/deliveries/999/retry", admin, ""); missing.Code != 40042 {
+		t.Fatalf("不存在的投递应返回 40042，得到 code=%d", missing.Code)
+	}
+	if deleted := doAuthed(t, client, http.MethodDelete, fmt.Sprintf("%s/%d", hooksURL, endpoint.ID), admin, ""); deleted.Code != 20000 {
+		t.Fatalf("删除 Webhook 失败: code=%d %s", deleted.Code, deleted.Message)
+	}
+}